@@ -2,15 +2,9 @@
 package main
 
 import (
-	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 
-	"github.com/spf13/cobra"
-	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
-	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
-	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"github.com/toyamagu-2021/k8s-manifest-diff/internal/cli"
 )
 
 var (
@@ -19,178 +13,7 @@ var (
 	date    = "unknown"
 )
 
-var (
-	excludeKinds         []string
-	labelSelectors       []string
-	annotationSelectors  []string
-	context              int
-	disableMaskingSecret bool
-	summary              bool
-	outputFormat         string
-)
-
-// Parse command specific variables
-var (
-	parseExcludeKinds         []string
-	parseLabelSelectors       []string
-	parseAnnotationSelectors  []string
-	parseDisableMaskingSecret bool
-)
-
-var rootCmd = &cobra.Command{
-	Use:   "k8s-manifest-diff",
-	Short: "Compare Kubernetes YAML manifests",
-	Long: `k8s-manifest-diff is a tool for comparing Kubernetes YAML manifests.
-It can filter out specific resources like hooks, secrets, or custom kinds,
-and use custom diff commands for comparison.`,
-}
-
-var diffCmd = &cobra.Command{
-	Use:   "diff [base-file] [head-file]",
-	Short: "Compare two Kubernetes YAML files",
-	Long: `Compare two Kubernetes YAML manifest files and show the differences.
-Supports filtering options to exclude specific resource types.`,
-	Args: cobra.ExactArgs(2),
-	RunE: func(_ *cobra.Command, args []string) error {
-		baseFile := args[0]
-		headFile := args[1]
-
-		// Sanitize file paths to prevent path traversal
-		baseFile = filepath.Clean(baseFile)
-		headFile = filepath.Clean(headFile)
-
-		// Read base file
-		baseReader, err := os.Open(baseFile) // #nosec G304 - file paths are CLI arguments and cleaned
-		if err != nil {
-			return fmt.Errorf("failed to open base file: %w", err)
-		}
-		defer func() {
-			if err := baseReader.Close(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to close base file: %v\n", err)
-			}
-		}()
-
-		baseObjs, err := parser.ParseYAML(baseReader)
-		if err != nil {
-			return fmt.Errorf("failed to parse base file: %w", err)
-		}
-
-		// Read head file
-		headReader, err := os.Open(headFile) // #nosec G304 - file paths are CLI arguments and cleaned
-		if err != nil {
-			return fmt.Errorf("failed to open head file: %w", err)
-		}
-		defer func() {
-			if err := headReader.Close(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to close head file: %v\n", err)
-			}
-		}()
-
-		headObjs, err := parser.ParseYAML(headReader)
-		if err != nil {
-			return fmt.Errorf("failed to parse head file: %w", err)
-		}
-
-		// Parse label selectors into map
-		labelSelectorMap := make(map[string]string)
-		for _, selector := range labelSelectors {
-			if strings.Contains(selector, "=") {
-				parts := strings.SplitN(selector, "=", 2)
-				if len(parts) == 2 {
-					labelSelectorMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-				}
-			}
-		}
-
-		// Parse annotation selectors into map
-		annotationSelectorMap := make(map[string]string)
-		for _, selector := range annotationSelectors {
-			if strings.Contains(selector, "=") {
-				parts := strings.SplitN(selector, "=", 2)
-				if len(parts) == 2 {
-					annotationSelectorMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-				}
-			}
-		}
-
-		// Validate output format
-		if outputFormat != "default" && outputFormat != "markdown" {
-			return fmt.Errorf("invalid output format: %s (supported formats: default, markdown)", outputFormat)
-		}
-
-		// Create diff options
-		opts := &diff.Options{
-			FilterOption: &filter.Option{
-				ExcludeKinds:       excludeKinds,
-				LabelSelector:      labelSelectorMap,
-				AnnotationSelector: annotationSelectorMap,
-			},
-			Context:               context,
-			DisableMaskingSecrets: disableMaskingSecret,
-		}
-
-		// Perform diff
-		results, err := diff.Objects(baseObjs, headObjs, opts)
-		if err != nil {
-			return fmt.Errorf("failed to diff objects: %w", err)
-		}
-
-		if results.HasChanges() {
-			if summary {
-				if outputFormat == "markdown" {
-					fmt.Print(results.StringSummaryMarkdown())
-				} else {
-					fmt.Print(results.StringSummary())
-				}
-			} else {
-				if outputFormat == "markdown" {
-					fmt.Print(results.StringDiffMarkdown())
-				} else {
-					fmt.Print(results.StringDiff())
-				}
-			}
-			os.Exit(1)
-		}
-		fmt.Println("No differences found")
-
-		return nil
-	},
-}
-
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print version information",
-	Run: func(_ *cobra.Command, _ []string) {
-		fmt.Printf("k8s-manifest-diff version %s\n", version)
-		fmt.Printf("commit: %s\n", commit)
-		fmt.Printf("date: %s\n", date)
-	},
-}
-
-func init() {
-	// Diff command flags
-	diffCmd.Flags().StringSliceVar(&excludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from diff")
-	diffCmd.Flags().StringSliceVar(&labelSelectors, "label", []string{}, "Label selector to filter resources (e.g., 'app=nginx', 'tier=frontend'). Can be specified multiple times.")
-	diffCmd.Flags().StringSliceVar(&annotationSelectors, "annotation", []string{}, "Annotation selector to filter resources (e.g., 'app.kubernetes.io/managed-by=helm', 'deployment.category=web'). Can be specified multiple times.")
-	diffCmd.Flags().IntVar(&context, "context", 3, "Number of context lines in diff output")
-	diffCmd.Flags().BoolVar(&disableMaskingSecret, "disable-masking-secret", false, "Disable masking of Secret data values in diff output")
-	diffCmd.Flags().BoolVar(&summary, "summary", false, "Output only the list of changed resources instead of full diff")
-	diffCmd.Flags().StringVar(&outputFormat, "output-format", "default", "Output format (default|markdown)")
-
-	// Parse command flags
-	parseCmd.Flags().StringSliceVar(&parseExcludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from parsing")
-	parseCmd.Flags().StringSliceVar(&parseLabelSelectors, "label", []string{}, "Label selector to filter resources (e.g., 'app=nginx', 'tier=frontend'). Can be specified multiple times.")
-	parseCmd.Flags().StringSliceVar(&parseAnnotationSelectors, "annotation", []string{}, "Annotation selector to filter resources (e.g., 'app.kubernetes.io/managed-by=helm', 'deployment.category=web'). Can be specified multiple times.")
-	parseCmd.Flags().BoolVar(&parseDisableMaskingSecret, "disable-masking-secret", false, "Disable masking of Secret data values in output")
-
-	rootCmd.AddCommand(diffCmd)
-	rootCmd.AddCommand(parseCmd)
-	rootCmd.AddCommand(versionCmd)
-}
-
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(2)
-	}
+	cli.SetVersionInfo(version, commit, date)
+	os.Exit(cli.Execute())
 }
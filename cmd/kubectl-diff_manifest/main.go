@@ -0,0 +1,20 @@
+// Package main provides the kubectl-diff_manifest binary, which exposes k8s-manifest-diff
+// as the kubectl plugin "kubectl diff-manifest".
+package main
+
+import (
+	"os"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/internal/cli"
+)
+
+var (
+	version = "1.0.0"
+	commit  = "none"
+	date    = "unknown"
+)
+
+func main() {
+	cli.SetVersionInfo(version, commit, date)
+	os.Exit(cli.ExecutePlugin(os.Args[1:]))
+}
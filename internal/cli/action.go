@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+// GitHub Actions environment variables used in action mode
+const (
+	actionInputBase          = "INPUT_BASE"
+	actionInputHead          = "INPUT_HEAD"
+	actionInputOptionsJSON   = "INPUT_OPTIONS_JSON"
+	actionInputFailOnChanges = "INPUT_FAIL_ON_CHANGES"
+	actionOutputFile         = "GITHUB_OUTPUT"
+	actionStepSummaryFile    = "GITHUB_STEP_SUMMARY"
+)
+
+// runAction runs the diff command with inputs read from GitHub Actions environment variables.
+// It prints the diff, appends a Markdown report to the step summary and writes change counts as step outputs.
+func runAction(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	baseFile := os.Getenv(actionInputBase)
+	headFile := os.Getenv(actionInputHead)
+	if baseFile == "" || headFile == "" {
+		return fmt.Errorf("%s and %s must be set in action mode", actionInputBase, actionInputHead)
+	}
+
+	if err := applyActionOptions(os.Getenv(actionInputOptionsJSON)); err != nil {
+		return err
+	}
+
+	baseObjs, headObjs, err := readManifestFiles(ctx, baseFile, headFile)
+	if err != nil {
+		return err
+	}
+
+	results, _, err := computeDiff(ctx, diffCmd, baseObjs, headObjs, nil)
+	if err != nil {
+		return err
+	}
+
+	output := "No differences found\n"
+	report := "# Kubernetes Manifest Diff\n\nNo differences found\n"
+	if results.HasChanges() {
+		if output, err = renderResults(results, diffRenderOptions(outputFormat)); err != nil {
+			return err
+		}
+		if report, err = renderResults(results, diffRenderOptions("markdown")); err != nil {
+			return err
+		}
+	}
+	fmt.Print(output)
+
+	if err := appendToEnvFile(actionStepSummaryFile, report+"\n"); err != nil {
+		return err
+	}
+	if err := appendToEnvFile(actionOutputFile, actionOutputs(results)); err != nil {
+		return err
+	}
+
+	if results.HasChanges() && os.Getenv(actionInputFailOnChanges) == "true" {
+		return changesFound(cmd)
+	}
+	return nil
+}
+
+// applyActionOptions sets diff command flags from a JSON object keyed by flag name,
+// e.g. {"summary": true, "exclude-kinds": ["Job"], "context": 5}
+func applyActionOptions(optionsJSON string) error {
+	if strings.TrimSpace(optionsJSON) == "" {
+		return nil
+	}
+
+	var options map[string]any
+	if err := json.Unmarshal([]byte(optionsJSON), &options); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", actionInputOptionsJSON, err)
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if diffCmd.Flags().Lookup(name) == nil {
+			return fmt.Errorf("unknown option %q in %s", name, actionInputOptionsJSON)
+		}
+
+		var values []any
+		switch value := options[name].(type) {
+		case []any:
+			values = value
+		case map[string]any:
+			return fmt.Errorf("option %q in %s must be a string, number, boolean or list", name, actionInputOptionsJSON)
+		default:
+			values = []any{value}
+		}
+		for _, value := range values {
+			if err := diffCmd.Flags().Set(name, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf("invalid option %q in %s: %w", name, actionInputOptionsJSON, err)
+			}
+		}
+	}
+	return nil
+}
+
+// actionOutputs returns step outputs describing the results in GITHUB_OUTPUT format
+func actionOutputs(results diff.Results) string {
+	stats := results.GetStatistics()
+	return fmt.Sprintf("has-changes=%t\nchanged=%d\ncreated=%d\ndeleted=%d\nunchanged=%d\n",
+		results.HasChanges(), stats.Changed, stats.Created, stats.Deleted, stats.Unchanged)
+}
+
+// appendToEnvFile appends content to the file named by the environment variable, if set
+func appendToEnvFile(envName, content string) error {
+	path := os.Getenv(envName)
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(filepath.Clean(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 - path is provided by the GitHub Actions runner
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", envName, err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write %s: %w", envName, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", envName, err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxArchiveDepth limits how deeply archives nested in archives are extracted
+const maxArchiveDepth = 3
+
+// isArchive reports whether the file is a gzipped tarball or zip archive by its extension
+func isArchive(file string) bool {
+	lower := strings.ToLower(file)
+	return strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".zip")
+}
+
+// readArchive parses the manifests in an archive in memory, in lexical order of their paths.
+// Archives inside the archive are read recursively. Paths matched by a .diffignore file at the
+// root of the archive or by excludeGlobs are skipped.
+func readArchive(name string, data []byte, excludeGlobs []string, depth int) ([]*unstructured.Unstructured, error) {
+	if depth >= maxArchiveDepth {
+		return nil, fmt.Errorf("archive %s is nested more than %d levels deep", name, maxArchiveDepth)
+	}
+
+	files, err := archiveFiles(name, data)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []string
+	if ignoreFile, ok := files[diffIgnoreFile]; ok {
+		patterns = strings.Split(string(ignoreFile), "\n")
+	}
+	rules, err := parseIgnoreRules(append(patterns, excludeGlobs...))
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var objs []*unstructured.Unstructured
+	for _, p := range paths {
+		if rules.ignored(p) {
+			continue
+		}
+		member := name + "/" + p
+		switch {
+		case isArchive(p):
+			nested, err := readArchive(member, files[p], nil, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, nested...)
+		case isManifestFile(p):
+			if reportAliases {
+				reportYAMLAliases(member, files[p])
+			}
+			fileObjs, err := parseManifestData(member, files[p])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse file %s: %w", member, err)
+			}
+			objs = append(objs, fileObjs...)
+		}
+	}
+	return objs, nil
+}
+
+// archiveFiles returns the contents of the regular files in an archive by slash-separated path
+func archiveFiles(name string, data []byte) (map[string][]byte, error) {
+	limits, err := inputLimits()
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	var extracted int64
+	add := func(entry string, r io.Reader) error {
+		p := strings.TrimPrefix(path.Clean("/"+entry), "/")
+		content, err := readInput(r)
+		if err != nil {
+			return fmt.Errorf("failed to read %s in archive %s: %w", entry, name, err)
+		}
+		// The contents are bounded as a whole too, so that many members cannot add up to an archive bomb
+		extracted += int64(len(content))
+		if limits.MaxInputSize > 0 && extracted > limits.MaxInputSize {
+			return fmt.Errorf("failed to read archive %s: %w: contents larger than --max-input-size %s", name, parser.ErrLimitExceeded, maxInputSize)
+		}
+		files[p] = content
+		return nil
+	}
+
+	if strings.HasSuffix(strings.ToLower(name), ".zip") {
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive %s: %w", name, err)
+		}
+		for _, entry := range archive.File {
+			if !entry.Mode().IsRegular() {
+				continue
+			}
+			r, err := entry.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s in archive %s: %w", entry.Name, name, err)
+			}
+			err = add(entry.Name, r)
+			_ = r.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return files, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", name, err)
+	}
+	defer func() { _ = gz.Close() }()
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", name, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := add(header.Name, archive); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
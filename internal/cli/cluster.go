@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/drift"
+)
+
+// Live cluster flags shared by the drift and snapshot commands, which list resources with kubectl get
+var (
+	liveResources      []string
+	liveNamespace      string
+	liveAllNamespaces  bool
+	liveSelector       string
+	kubeconfig         string
+	kubeContext        string
+	kubeAs             string
+	kubeAsGroups       []string
+	liveChunkSize      int64
+	liveRequestTimeout time.Duration
+)
+
+// kubectlFlags are the flags that only apply when resources are listed with --live-resources
+var kubectlFlags = []string{"live-namespace", "live-all-namespaces", "live-selector", "kube-context", "as", "as-group", "chunk-size", "request-timeout"}
+
+// addLiveClusterFlags registers the flags listing live resources with kubectl get on cmd.
+// They follow kubectl's names, except --kube-context, as --context is the number of diff context lines.
+func addLiveClusterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&liveResources, "live-resources", []string{}, "Resource types to list with 'kubectl get' as the live state, e.g. 'deploy,svc,cm'. Can be specified multiple times.")
+	cmd.Flags().StringVar(&liveNamespace, "live-namespace", "", "Namespace to list --live-resources in (default: the namespace of the kubeconfig context)")
+	cmd.Flags().BoolVar(&liveAllNamespaces, "live-all-namespaces", false, "List --live-resources in all namespaces")
+	cmd.Flags().StringVar(&liveSelector, "live-selector", "", "Label selector applied by the API server when listing --live-resources, e.g. 'app=web'")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to $KUBECONFIG or ~/.kube/config, then the in-cluster config, as kubectl does); exported as $KUBECONFIG to --live-command")
+	cmd.Flags().StringVar(&kubeContext, "kube-context", "", "kubeconfig context to list --live-resources with (default: the current context)")
+	cmd.Flags().StringVar(&kubeAs, "as", "", "User to impersonate when listing --live-resources")
+	cmd.Flags().StringSliceVar(&kubeAsGroups, "as-group", []string{}, "Group to impersonate when listing --live-resources, requires --as. Can be specified multiple times.")
+	cmd.Flags().Int64Var(&liveChunkSize, "chunk-size", 500, "Number of --live-resources fetched per list request, paginating large clusters")
+	cmd.Flags().DurationVar(&liveRequestTimeout, "request-timeout", 0, "Time to wait for a single API server request when listing --live-resources, e.g. '30s' (no timeout when 0)")
+}
+
+// liveSource returns the source of live resources given by exactly one of --live-file, --live-command
+// and --live-resources. Unless discover is nil, the kinds of the objects it loads are listed with kubectl get
+// in addition to --live-resources.
+func liveSource(cmd *cobra.Command, liveFile, liveCommand string, discover drift.Source) (drift.Source, error) {
+	useKubectl := len(liveResources) > 0 || discover != nil
+	given := 0
+	for _, set := range []bool{liveFile != "", liveCommand != "", useKubectl} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, errors.New("exactly one of --live-file, --live-command or --live-resources is required")
+	}
+
+	if !useKubectl {
+		for _, name := range kubectlFlags {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--%s only applies to --live-resources", name)
+			}
+		}
+	}
+	if liveFile != "" {
+		return drift.FileSource(liveFile), nil
+	}
+	if liveCommand != "" {
+		if kubeconfig != "" {
+			// kubectl and other clients run by the command read the kubeconfig from the environment
+			if err := os.Setenv("KUBECONFIG", kubeconfig); err != nil {
+				return nil, fmt.Errorf("failed to set KUBECONFIG: %w", err)
+			}
+		}
+		return drift.CommandSource(liveCommand), nil
+	}
+
+	kubectl := drift.Kubectl{
+		Resources:      liveResources,
+		Namespace:      liveNamespace,
+		AllNamespaces:  liveAllNamespaces,
+		Selector:       liveSelector,
+		Kubeconfig:     kubeconfig,
+		Context:        kubeContext,
+		As:             kubeAs,
+		AsGroups:       kubeAsGroups,
+		ChunkSize:      liveChunkSize,
+		RequestTimeout: liveRequestTimeout,
+	}
+	if err := kubectl.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid --live-resources: %w", err)
+	}
+	if discover != nil {
+		return kubectl.DiscoverSource(discover), nil
+	}
+	return kubectl.Source(), nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+// Full report modes of the comment command for reports longer than --max-length
+const (
+	fullReportSplit    = "split"
+	fullReportGist     = "gist"
+	fullReportArtifact = "artifact"
+)
+
+// noDifferencesReport is the Markdown report of results without changes
+const noDifferencesReport = "# Kubernetes Manifest Diff\n\nNo differences found"
+
+var commentCmd = &cobra.Command{
+	Use:   "comment [results-file]",
+	Short: "Render results saved with diff --save as pull request comments",
+	Long: `Render results saved with "diff --save" as Markdown comments no longer than --max-length
+(GitHub's 65536 character limit by default). A report that does not fit is either split into several
+comments, the summary first, or replaced by its summary linking to the full report, which is uploaded as
+a gist with the gh CLI or written for upload as a build artifact. With --output-dir the comments are
+written to comment-1.md, comment-2.md, ... for posting; otherwise they are printed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		results, err := loadResults(args[0])
+		if err != nil {
+			return err
+		}
+		if !commentAllowPotentialSecrets {
+			if err := checkPotentialSecrets(results); err != nil {
+				return err
+			}
+		}
+		comments, err := renderComments(results)
+		if err != nil {
+			return err
+		}
+		if commentOutputDir == "" {
+			fmt.Println(strings.Join(comments, "\n\n"))
+			return nil
+		}
+		for i, comment := range comments {
+			file := filepath.Join(commentOutputDir, fmt.Sprintf("comment-%d.md", i+1))
+			if err := os.WriteFile(file, []byte(comment+"\n"), 0o600); err != nil {
+				return fmt.Errorf("failed to write comment: %w", err)
+			}
+			fmt.Println(file)
+		}
+		return nil
+	},
+}
+
+// renderComments returns the comments reporting results according to --max-length and --full-report
+func renderComments(results diff.Results) ([]string, error) {
+	if !results.HasChanges() {
+		return []string{noDifferencesReport}, nil
+	}
+	report := results.StringDiffMarkdown()
+	if commentMaxLength <= 0 || len(report) <= commentMaxLength {
+		return []string{report}, nil
+	}
+
+	switch commentFullReport {
+	case fullReportSplit:
+		return results.MarkdownComments(commentMaxLength), nil
+	case fullReportGist:
+		url, err := createGist(report)
+		if err != nil {
+			return nil, err
+		}
+		return []string{results.MarkdownSummaryComment(commentMaxLength, url)}, nil
+	case fullReportArtifact:
+		if commentOutputDir == "" || commentReportURL == "" {
+			return nil, fmt.Errorf("--full-report %s requires --output-dir and --report-url", fullReportArtifact)
+		}
+		file := filepath.Join(commentOutputDir, "report.md")
+		if err := os.WriteFile(file, []byte(report+"\n"), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write full report: %w", err)
+		}
+		return []string{results.MarkdownSummaryComment(commentMaxLength, commentReportURL)}, nil
+	default:
+		return nil, fmt.Errorf("invalid --full-report: %s (supported: %s, %s, %s)", commentFullReport, fullReportSplit, fullReportGist, fullReportArtifact)
+	}
+}
+
+// createGist uploads the report as a secret gist with the gh CLI and returns its URL
+func createGist(report string) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", fmt.Errorf("failed to create gist: gh CLI not found in PATH (required for --full-report %s)", fullReportGist)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("gh", "gist", "create", "--filename", "k8s-manifest-diff.md", "-") // #nosec G204 - the command is fixed
+	cmd.Stdin = strings.NewReader(report)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create gist: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/drift"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/options"
+)
+
+var driftCmd = &cobra.Command{
+	Use:   "drift [manifests-file]",
+	Short: "Periodically compare live cluster state with manifests",
+	Long: `Periodically fetch live cluster state and compare it with the desired manifests.
+Live state is read from a file (--live-file), from the output of a command such as
+"kubectl get deploy,svc -n app -o yaml" (--live-command), or by listing resource types with
+kubectl get (--live-resources) with --kubeconfig, --kube-context, --as and --as-group.
+Server-populated fields (uid, resourceVersion, managedFields, status, ...) are removed from
+the live state before comparing, as in snapshots, and fields the API server defaults, such as
+revisionHistoryLimit or imagePullPolicy, are ignored unless --normalize-known-kinds=false.
+Drift is logged on every check, exported as Prometheus metrics with --metrics-addr, and
+posted to --notify-webhook when it appears or resolves. With --once, a single check is performed and the command exits with 1
+if drift is found.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		desired := drift.FileSource(args[0])
+		var discover drift.Source
+		if driftLiveDiscover {
+			if driftLiveFile != "" || driftLiveCommand != "" {
+				return errors.New("--live-discover cannot be combined with --live-file or --live-command")
+			}
+			discover = desired
+		}
+		live, err := liveSource(cmd, driftLiveFile, driftLiveCommand, discover)
+		if err != nil {
+			return err
+		}
+		if driftInterval <= 0 {
+			return fmt.Errorf("--interval must be positive, got %s", driftInterval)
+		}
+
+		opts, err := options.FromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		monitor := &drift.Monitor{
+			Live:    live,
+			Desired: desired,
+			Options: opts,
+		}
+		if driftNotifyWebhook != "" {
+			monitor.Notifier = &drift.WebhookNotifier{URL: driftNotifyWebhook, Client: &http.Client{Timeout: 30 * time.Second}}
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if driftOnce {
+			status, err := monitor.Check(ctx)
+			if err != nil {
+				return err
+			}
+			if len(status.Drifted) == 0 {
+				fmt.Println("No drift found")
+				return nil
+			}
+			fmt.Println(status.Results.StringSummary())
+			if candidates := status.PruneCandidates(); len(candidates) > 0 {
+				fmt.Println("\nPrune candidates (live but not in the manifests):")
+				for _, key := range candidates {
+					fmt.Printf("  %s\n", key)
+				}
+			}
+			return changesFound(cmd)
+		}
+
+		if driftMetricsAddr != "" {
+			monitor.Metrics = drift.NewMetrics()
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", monitor.Metrics)
+			server := &http.Server{Addr: driftMetricsAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					fmt.Fprintf(os.Stderr, "Error: metrics server: %v\n", err)
+					stop()
+				}
+			}()
+			defer func() { _ = server.Close() }()
+		}
+
+		monitor.Run(ctx, driftInterval, logDriftCheck)
+		return nil
+	},
+}
+
+// logDriftCheck prints a line describing the outcome of a drift check
+func logDriftCheck(status drift.Status, err error) {
+	timestamp := status.Time.Format(time.RFC3339)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "%s check failed: %v\n", timestamp, err)
+	case len(status.Drifted) == 0:
+		fmt.Printf("%s no drift\n", timestamp)
+	default:
+		fmt.Printf("%s %d resources drifted\n", timestamp, len(status.Drifted))
+		for _, key := range status.Drifted {
+			if status.Results[key].Type == diff.Deleted {
+				fmt.Printf("  %s (%s, prune candidate)\n", key, status.Results[key].Type)
+				continue
+			}
+			fmt.Printf("  %s (%s)\n", key, status.Results[key].Type)
+		}
+	}
+}
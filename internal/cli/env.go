@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envFlagPrefix prefixes the environment variables setting flags, e.g. K8S_MANIFEST_DIFF_EXCLUDE_KINDS for --exclude-kinds
+const envFlagPrefix = "K8S_MANIFEST_DIFF_"
+
+// envFlagName returns the environment variable setting a flag
+func envFlagName(flag string) string {
+	return envFlagPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// applyEnvFlags sets the flags of cmd that are not given on the command line from their environment variables,
+// so a pipeline can configure every run at once. Values are parsed as on the command line: list flags
+// split comma-separated values and booleans accept "true" and "false".
+func applyEnvFlags(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		name := envFlagName(flag.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid %s: %w", name, setErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/fixture"
+)
+
+var testingCmd = &cobra.Command{
+	Use:   "testing",
+	Short: "Tools for building test fixtures and demos",
+	Long: `Tools for building test fixtures and demos of k8s-manifest-diff.
+They are not needed to compare manifests.`,
+}
+
+var genFixtureCmd = &cobra.Command{
+	Use:   "gen-fixture [manifest]",
+	Short: "Write a mutated copy of a manifest",
+	Long: `Write a copy of a manifest with the mutations of a spec file applied: bumped or replaced
+images, changed replica counts and dropped resources. Diff the manifest against the copy to
+get a fixture or demo of typical changes. Directories, archives and URLs are read like the
+inputs of diff. Fails if a mutation changes no resource.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		spec, err := readConfigFile(genFixtureSpecFile, "fixture spec", fixture.ReadSpec)
+		if err != nil {
+			return err
+		}
+		workloadPolicy, err := loadWorkloadPolicy(genFixtureWorkloadConfig)
+		if err != nil {
+			return err
+		}
+		objs, err := readManifestFile(args[0])
+		if err != nil {
+			return err
+		}
+		mutated, err := fixture.Apply(objs, spec, workloadPolicy)
+		if err != nil {
+			return err
+		}
+
+		if genFixtureOutput == "" {
+			return fixture.Write(os.Stdout, mutated)
+		}
+		var buf bytes.Buffer
+		if err := fixture.Write(&buf, mutated); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Clean(genFixtureOutput), buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("failed to write fixture: %w", err)
+		}
+		return nil
+	},
+}
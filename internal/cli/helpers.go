@@ -0,0 +1,417 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// validateOutputFormat returns an error unless format is supported by the diff and show commands
+func validateOutputFormat(format string) error {
+	switch format {
+	case "default", "markdown", "plan", "inline", "report", "notes", "jsonl", "sarif", "rdjson":
+		return nil
+	default:
+		return fmt.Errorf("invalid output format: %s (supported formats: default, markdown, plan, inline, report, notes, jsonl, sarif, rdjson)", format)
+	}
+}
+
+// applyMaskKey sets the key of hash masks from $K8S_MANIFEST_DIFF_MASK_KEY, refusing the hash strategy without
+// one because unkeyed hash masks let anyone confirm a guess of a masked value
+func applyMaskKey(opts *diff.Options) error {
+	key := os.Getenv(envMaskKey)
+	if key == "" && opts.MaskStrategy == masking.StrategyHash && !opts.DisableMaskingSecrets {
+		return fmt.Errorf("--mask-strategy hash requires a secret key in %s", envMaskKey)
+	}
+	opts.WithMaskKey(key)
+	return nil
+}
+
+// validateFrontMatter parses the --front-matter format, which only applies to markdown output
+func validateFrontMatter(frontMatter, outputFormat string) (diff.FrontMatterFormat, error) {
+	format, err := diff.ParseFrontMatterFormat(frontMatter)
+	if err != nil {
+		return "", fmt.Errorf("invalid --front-matter: %w", err)
+	}
+	if outputFormat != "markdown" {
+		return "", fmt.Errorf("--front-matter requires --output-format markdown")
+	}
+	return format, nil
+}
+
+// validateGroupLinked checks --group-linked, which only groups markdown reports
+func validateGroupLinked(groupLinked bool, outputFormat string) error {
+	if groupLinked && outputFormat != "markdown" {
+		return fmt.Errorf("--group-linked requires --output-format markdown")
+	}
+	return nil
+}
+
+// validateMaxResourcesInReport checks --max-resources-in-report, which only applies to markdown output, and
+// --report-url, which is only linked from the note ending a limited report
+func validateMaxResourcesInReport(maxResources int, reportURL, outputFormat string) error {
+	if maxResources < 0 {
+		return fmt.Errorf("invalid --max-resources-in-report: %d (must be 0 or more)", maxResources)
+	}
+	if maxResources > 0 && outputFormat != "markdown" {
+		return fmt.Errorf("--max-resources-in-report requires --output-format markdown")
+	}
+	if reportURL != "" && maxResources == 0 {
+		return fmt.Errorf("--report-url requires --max-resources-in-report")
+	}
+	return nil
+}
+
+// diffStyleFor returns the diff style that renders changed resources for an output format
+func diffStyleFor(format string) diff.DiffStyle {
+	switch format {
+	case "inline":
+		return diff.DiffStyleInline
+	case "report":
+		return diff.DiffStyleReport
+	default:
+		return diff.DiffStyleUnified
+	}
+}
+
+// strictFlags makes malformed selector flags an error instead of being ignored or misread
+var strictFlags bool
+
+// selectorFlag is the values of a --label or --annotation style flag, checked by validateSelectorFlags
+type selectorFlag struct {
+	name   string
+	values []string
+	labels bool // values must be valid label values
+}
+
+// validateSelectorFlags returns an error for the first malformed selector if --strict-flags is set
+func validateSelectorFlags(flags ...selectorFlag) error {
+	if !strictFlags {
+		return nil
+	}
+	for _, flag := range flags {
+		for _, value := range flag.values {
+			if err := filter.ValidateSelector(value, flag.labels); err != nil {
+				return fmt.Errorf("invalid --%s: %w", flag.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readManifestFiles reads the base and head manifests, see readManifestFile, recording a span for the parse stage
+func readManifestFiles(ctx context.Context, baseFile, headFile string) (baseObjs, headObjs []*unstructured.Unstructured, err error) {
+	_, span := tracer.Start(ctx, "cli.parse", trace.WithAttributes(
+		attribute.String("k8s_manifest_diff.base.file", baseFile),
+		attribute.String("k8s_manifest_diff.head.file", headFile),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if baseObjs, err = readManifestFile(baseFile); err != nil {
+		return nil, nil, fmt.Errorf("failed to read base file: %w", err)
+	}
+	if headObjs, err = readManifestFile(headFile); err != nil {
+		return nil, nil, fmt.Errorf("failed to read head file: %w", err)
+	}
+	span.SetAttributes(
+		attribute.Int("k8s_manifest_diff.base.objects", len(baseObjs)),
+		attribute.Int("k8s_manifest_diff.head.objects", len(headObjs)),
+	)
+	return baseObjs, headObjs, nil
+}
+
+// readManifestFile opens and parses a YAML or JSON manifest file.
+// A directory is read as the concatenation of the manifest files below it, see manifestFiles,
+// and a .tgz, .tar.gz or .zip archive as the manifests it contains, see readArchive.
+// HTTP(S), s3:// and gs:// URLs are fetched, see readManifestURL.
+func readManifestFile(file string) ([]*unstructured.Unstructured, error) {
+	if isURL(file) {
+		return readManifestURL(file)
+	}
+
+	// Sanitize file path to prevent path traversal
+	file = filepath.Clean(file)
+
+	if isArchive(file) {
+		data, err := os.ReadFile(file) // #nosec G304 - file paths are CLI arguments and cleaned
+		if err != nil {
+			return nil, inputFileError(file, err)
+		}
+		return readArchive(file, data, excludeFileGlobs, 0)
+	}
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		files, err := manifestFiles(file, excludeFileGlobs, followSymlinks)
+		if err != nil {
+			return nil, err
+		}
+		var objs []*unstructured.Unstructured
+		for _, f := range files {
+			fileObjs, err := readManifestFile(f)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, fileObjs...)
+		}
+		return objs, nil
+	}
+
+	data, err := os.ReadFile(file) // #nosec G304 - file paths are CLI arguments and cleaned
+	if err != nil {
+		return nil, inputFileError(file, err)
+	}
+	if reportAliases {
+		reportYAMLAliases(file, data)
+	}
+
+	objs, err := parseManifestData(file, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file %s: %w", file, err)
+	}
+	return objs, nil
+}
+
+// defaultMaxInputSize bounds inputs unless --max-input-size is given, so that fetched URLs and archives cannot
+// exhaust memory by default
+const defaultMaxInputSize = "256Mi"
+
+// inputLimits returns the parse limits given by --max-input-size, --max-documents and --parse-timeout
+func inputLimits() (parser.Limits, error) {
+	limits := parser.DefaultLimits()
+	limits.MaxDocuments = maxDocuments
+	limits.Timeout = parseTimeout
+	if maxInputSize != "" {
+		size, err := resource.ParseQuantity(maxInputSize)
+		if err != nil {
+			return limits, fmt.Errorf("invalid --max-input-size: %w", err)
+		}
+		limits.MaxInputSize = size.Value()
+	}
+	return limits, nil
+}
+
+// parseManifestData parses YAML or JSON manifests named name within the input limits, decrypting them first
+// if --sops-decrypt is set
+func parseManifestData(name string, data []byte) ([]*unstructured.Unstructured, error) {
+	limits, err := inputLimits()
+	if err != nil {
+		return nil, err
+	}
+	data = decryptSOPSInput(name, data)
+	return parser.ParseYAMLWithLimits(bytes.NewReader(data), limits)
+}
+
+// readInput reads an input stream such as a fetched URL or an archive member, failing if it is larger than --max-input-size
+func readInput(r io.Reader) ([]byte, error) {
+	limits, err := inputLimits()
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaxInputSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxInputSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limits.MaxInputSize {
+		return nil, fmt.Errorf("%w: larger than --max-input-size %s", parser.ErrLimitExceeded, maxInputSize)
+	}
+	return data, nil
+}
+
+// reportYAMLAliases prints the anchors, aliases and merge keys used in a manifest to stderr for --report-aliases.
+// They are resolved when parsing, so this only makes their use visible.
+func reportYAMLAliases(name string, data []byte) {
+	uses, err := parser.FindAliases(bytes.NewReader(data))
+	if err != nil {
+		// Parsing the manifest reports the error
+		return
+	}
+	for _, use := range uses {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, use)
+	}
+}
+
+// checkPotentialSecrets returns an error listing resources whose rendered diff appears to contain secrets, checked
+// before the diff is printed or written anywhere
+func checkPotentialSecrets(results diff.Results) error {
+	found := results.PotentialSecrets()
+	if len(found) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(found))
+	for key, findings := range found {
+		rules := make([]string, 0, len(findings))
+		for _, finding := range findings {
+			rules = append(rules, fmt.Sprintf("%s (line %d)", finding.Rule, finding.Line))
+		}
+		lines = append(lines, fmt.Sprintf("  %s/%s/%s: %s", key.Kind, key.Namespace, key.Name, strings.Join(rules, ", ")))
+	}
+	sort.Strings(lines)
+
+	return fmt.Errorf("refusing to output diff: potential secrets detected in %d resource(s):\n%s\nuse --allow-potential-secrets to output the diff anyway", len(found), strings.Join(lines, "\n"))
+}
+
+// streamResultLines returns a function writing each result selected by the --filter-* flags of the diff command
+// to w as a JSON line, refusing results with potential secrets unless --allow-potential-secrets is set
+func streamResultLines(w io.Writer) diff.ResultFunc {
+	return func(key diff.ResourceKey, result diff.Result) error {
+		shown, err := filterResults(diff.Results{key: result}, diffResultFilter())
+		if err != nil || len(shown) == 0 {
+			return err
+		}
+		if !allowPotentialSecrets {
+			if err := checkPotentialSecrets(shown); err != nil {
+				return err
+			}
+		}
+		return diff.WriteResultLine(w, key, result)
+	}
+}
+
+// renderResultLines renders results as JSON Lines, as streamed by the diff command
+func renderResultLines(results diff.Results) (string, error) {
+	var b strings.Builder
+	if err := diff.WriteResultLines(&b, results); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// failedResourcesError returns an error listing the resources that failed to diff with --continue-on-error and
+// their errors, or nil if none failed
+func failedResourcesError(results diff.Results) error {
+	failed := results.FilterErrors().GetResourceKeys()
+	if len(failed) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(failed))
+	for _, key := range failed {
+		messages = append(messages, fmt.Sprintf("%s: %v", key.String(), results[key].Err))
+	}
+	sort.Strings(messages)
+	return fmt.Errorf("failed to diff %d resource(s): %s", len(failed), strings.Join(messages, "; "))
+}
+
+// writePruneScript writes kubectl delete commands for the deleted resources to a file
+func writePruneScript(file string, results diff.Results) error {
+	if err := os.WriteFile(filepath.Clean(file), []byte(results.PruneScript()), 0o600); err != nil {
+		return fmt.Errorf("failed to write prune script: %w", err)
+	}
+	return nil
+}
+
+// loadSeverityPolicy reads the severity policy file. When only a fail threshold is given,
+// an empty policy is used so that every resource gets the default severity.
+func loadSeverityPolicy(file, failThreshold string) (*diff.SeverityPolicy, error) {
+	if failThreshold != "" {
+		if _, err := diff.ParseSeverity(failThreshold); err != nil {
+			return nil, fmt.Errorf("invalid --fail-on-severity: %w", err)
+		}
+	}
+	if file == "" {
+		if failThreshold != "" {
+			return &diff.SeverityPolicy{}, nil
+		}
+		return nil, nil
+	}
+
+	return readConfigFile(file, "severity config", diff.ReadSeverityPolicy)
+}
+
+// loadOwnershipPolicy reads the ownership policy file, if given
+func loadOwnershipPolicy(file string) (*diff.OwnershipPolicy, error) {
+	if file == "" {
+		return nil, nil
+	}
+	return readConfigFile(file, "owners config", diff.ReadOwnershipPolicy)
+}
+
+// loadConversionPolicy reads the conversion policy file, if given
+func loadConversionPolicy(file string) (*diff.ConversionPolicy, error) {
+	if file == "" {
+		return nil, nil
+	}
+	return readConfigFile(file, "conversion config", diff.ReadConversionPolicy)
+}
+
+// loadWorkloadPolicy reads the workload policy file, if given
+func loadWorkloadPolicy(file string) (*workload.Policy, error) {
+	if file == "" {
+		return nil, nil
+	}
+	return readConfigFile(file, "workload config", workload.Read)
+}
+
+// readConfigFile opens a config file and parses it with read
+func readConfigFile[T any](file, description string, read func(io.Reader) (T, error)) (T, error) {
+	var zero T
+	if err := checkInputFile(filepath.Clean(file)); err != nil {
+		return zero, fmt.Errorf("failed to open %s: %w", description, err)
+	}
+	reader, err := os.Open(filepath.Clean(file)) // #nosec G304 - file path is a CLI argument and cleaned
+	if err != nil {
+		return zero, fmt.Errorf("failed to open %s %s: %w", description, file, err)
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", file, err)
+		}
+	}()
+	return read(reader)
+}
+
+// errChangesFound is returned by commands that found changes, so that Execute exits with 1 after deferred spans
+// have ended and tracing was flushed
+var errChangesFound = errors.New("changes found")
+
+// changesFound returns errChangesFound, keeping cobra from printing it as an error with usage
+func changesFound(cmd *cobra.Command) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return errChangesFound
+}
+
+// exceedsFailSeverity reports whether changes should fail the diff command.
+// Without --fail-on-severity any change fails.
+func exceedsFailSeverity(results diff.Results) bool {
+	if failOnSeverity == "" {
+		return true
+	}
+	threshold, err := diff.ParseSeverity(failOnSeverity)
+	if err != nil {
+		return true
+	}
+	return results.MaxSeverity() >= threshold
+}
+
+// optionsHeader returns the effective options as a comment preceding the output, see diff.EffectiveOptions.Header.
+// Markdown output gets an HTML comment, as a "#" line would render as a heading.
+func optionsHeader(opts *diff.Options, format string) (string, error) {
+	header, err := opts.Effective().Header()
+	if err != nil {
+		return "", err
+	}
+	if format == "markdown" || format == "notes" {
+		return "<!-- " + strings.TrimSuffix(strings.TrimPrefix(header, "# "), "\n") + " -->\n", nil
+	}
+	return header, nil
+}
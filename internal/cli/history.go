@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+const (
+	// pushgatewayJob is the job the statistics are grouped by when --stats-pushgateway has no job path
+	pushgatewayJob = "k8s-manifest-diff"
+	// pushgatewayTimeout bounds pushing the statistics, so that an unreachable gateway does not stall CI
+	pushgatewayTimeout = 10 * time.Second
+)
+
+// statisticsInputs returns the identifiers of the compared inputs recorded with their statistics
+func statisticsInputs(args []string) (string, string) {
+	if staged {
+		return "git:" + stagedAgainst, "git:staged"
+	}
+	return args[0], args[1]
+}
+
+// historyFormat returns the line format of a history file by its extension: CSV for .csv, JSON lines otherwise
+func historyFormat(file string) diff.HistoryFormat {
+	if strings.EqualFold(filepath.Ext(file), ".csv") {
+		return diff.HistoryFormatCSV
+	}
+	return diff.HistoryFormatJSON
+}
+
+// appendStatisticsHistory appends the record to the history file, creating it if it does not exist
+func appendStatisticsHistory(file string, record diff.StatisticsRecord) error {
+	f, err := os.OpenFile(filepath.Clean(file), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // #nosec G304 - file path is a CLI argument and cleaned
+	if err != nil {
+		return fmt.Errorf("failed to open statistics history: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to open statistics history: %w", err)
+	}
+	if err := record.AppendHistory(f, historyFormat(file), info.Size() == 0); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close statistics history: %w", err)
+	}
+	return nil
+}
+
+// pushgatewayURL returns the URL the statistics are pushed to, grouping them by the k8s-manifest-diff job
+// unless gateway already names a job
+func pushgatewayURL(gateway string) string {
+	if strings.Contains(gateway, "/metrics/job/") {
+		return gateway
+	}
+	return strings.TrimSuffix(gateway, "/") + "/metrics/job/" + pushgatewayJob
+}
+
+// pushStatistics replaces the metrics of the job on a Prometheus Pushgateway with the record
+func pushStatistics(gateway string, record diff.StatisticsRecord) error {
+	var body bytes.Buffer
+	if err := record.WriteMetrics(&body); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, pushgatewayURL(gateway), &body)
+	if err != nil {
+		return fmt.Errorf("failed to create pushgateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: pushgatewayTimeout}
+	resp, err := client.Do(req) // #nosec G107 - the pushgateway URL is provided by the user running the command
+	if err != nil {
+		return fmt.Errorf("failed to push statistics: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pushgateway returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// recordStatistics appends the statistics of the results to --stats-history and pushes them to
+// --stats-pushgateway. A failed push is only a warning, as the gateway is outside the run's control.
+func recordStatistics(args []string, results diff.Results) error {
+	if statsHistoryFile == "" && statsPushgateway == "" {
+		return nil
+	}
+	base, head := statisticsInputs(args)
+	record := results.StatisticsRecord(base, head, time.Now())
+	if statsHistoryFile != "" {
+		if err := appendStatisticsHistory(statsHistoryFile, record); err != nil {
+			return err
+		}
+	}
+	if statsPushgateway != "" {
+		if err := pushStatistics(statsPushgateway, record); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: statistics were not pushed: %v\n", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+// runResultHook runs a shell command given by --on-change-exec or --on-clean-exec with the results
+// as JSON, in the format of --save, on its standard input. Its output goes to stderr to keep the diff output intact.
+func runResultHook(flag, command string, results diff.Results) error {
+	var input bytes.Buffer
+	if err := diff.WriteResults(&input, results); err != nil {
+		return err
+	}
+	cmd := exec.Command("sh", "-c", command) // #nosec G204 - the command is provided by the user running the CLI
+	cmd.Stdin = &input
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s command %q failed: %w", flag, command, err)
+	}
+	return nil
+}
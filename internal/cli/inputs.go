@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+)
+
+// diffIgnoreFile lists glob patterns of paths to skip when a directory is given as input
+const diffIgnoreFile = ".diffignore"
+
+// ignoreRule is a single .diffignore or --exclude-file-glob pattern
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+// ignoreRules are matched in order, so the last matching rule decides whether a path is ignored
+type ignoreRules []ignoreRule
+
+// parseIgnoreRules parses .dockerignore-style patterns relative to the input directory.
+// Blank lines and lines starting with "#" are skipped, "**" matches any number of directories
+// and a leading "!" re-includes paths excluded by an earlier pattern.
+func parseIgnoreRules(patterns []string) (ignoreRules, error) {
+	var rules ignoreRules
+	for _, line := range patterns {
+		pattern := strings.TrimSpace(line)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		rule := ignoreRule{}
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+		pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", line, err)
+		}
+		rule.pattern = re
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// globToRegexp converts a glob pattern where "*" and "?" do not cross "/" and "**" matches across directories
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" also matches no directory at all
+					i++
+					expr.WriteString("(.*/)?")
+				} else {
+					expr.WriteString(".*")
+				}
+				continue
+			}
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated character class")
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// ignored reports whether the slash-separated path relative to the input directory is ignored. A rule matches
+// the path or any directory containing it, so that "!" rules re-include paths below excluded directories.
+func (r ignoreRules) ignored(rel string) bool {
+	segments := strings.Split(rel, "/")
+	ignored := false
+	for _, rule := range r {
+		for i := 1; i <= len(segments); i++ {
+			if rule.pattern.MatchString(strings.Join(segments[:i], "/")) {
+				ignored = !rule.negate
+				break
+			}
+		}
+	}
+	return ignored
+}
+
+// reincludes reports whether any rule re-includes paths, so that excluded directories must still be walked
+func (r ignoreRules) reincludes() bool {
+	for _, rule := range r {
+		if rule.negate {
+			return true
+		}
+	}
+	return false
+}
+
+// readIgnoreFile returns the lines of the .diffignore file in dir, or nil if there is none
+func readIgnoreFile(dir string) ([]string, error) {
+	file := filepath.Join(dir, diffIgnoreFile)
+	f, err := os.Open(file) // #nosec G304 - dir is a CLI argument and cleaned
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", file, err)
+		}
+	}()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return lines, nil
+}
+
+// manifestFiles returns the YAML and JSON files below dir in lexical order, skipping paths matched by
+// the .diffignore file in dir followed by the excludeGlobs patterns.
+// Symlinked files are read; symlinked directories are only descended into if followSymlinks is set.
+func manifestFiles(dir string, excludeGlobs []string, followSymlinks bool) ([]string, error) {
+	patterns, err := readIgnoreFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseIgnoreRules(append(patterns, excludeGlobs...))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &manifestWalk{rules: rules, followSymlinks: followSymlinks, visited: make(map[string]bool)}
+	if err := w.walk(dir, "."); err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	return w.files, nil
+}
+
+// manifestWalk collects the manifest files below an input directory
+type manifestWalk struct {
+	rules          ignoreRules
+	followSymlinks bool
+	visited        map[string]bool // Resolved directories already walked, to stop at symlink cycles
+	files          []string
+}
+
+// walk adds the manifest files below the directory at path, which is at rel relative to the input directory.
+// The directory is walked at its resolved location, but files are reported below path.
+func (w *manifestWalk) walk(path, rel string) error {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return inputFileError(path, err)
+	}
+	if w.visited[resolved] {
+		fmt.Fprintf(os.Stderr, "Warning: skipping %s: directory %s was already read\n", path, resolved)
+		return nil
+	}
+	w.visited[resolved] = true
+
+	return filepath.WalkDir(resolved, func(walked string, entry fs.DirEntry, err error) error {
+		sub, relErr := filepath.Rel(resolved, walked)
+		if relErr != nil {
+			return relErr
+		}
+		logical := filepath.Join(path, sub)
+		if err != nil {
+			return inputFileError(logical, err)
+		}
+		if sub == "." {
+			return nil
+		}
+		entryRel := filepath.ToSlash(filepath.Join(rel, sub))
+		if w.rules.ignored(entryRel) {
+			if entry.IsDir() && !w.rules.reincludes() {
+				return filepath.SkipDir
+			}
+			// Paths below the directory are tested on their own, as a later rule may re-include them
+			return nil
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			return w.symlink(logical, entryRel)
+		}
+		if !entry.IsDir() && isManifestFile(walked) {
+			w.files = append(w.files, logical)
+		}
+		return nil
+	})
+}
+
+// symlink adds the manifest files a symlink found while walking points to
+func (w *manifestWalk) symlink(path, rel string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Broken links are only an error where a manifest was expected
+		if isManifestFile(path) {
+			return inputFileError(path, err)
+		}
+		return nil
+	}
+	switch {
+	case !info.IsDir():
+		if isManifestFile(path) {
+			w.files = append(w.files, path)
+		}
+		return nil
+	case w.followSymlinks:
+		return w.walk(path, rel)
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: skipping symlinked directory %s (use --follow-symlinks to read it)\n", path)
+		return nil
+	}
+}
+
+// checkInputFile returns a descriptive error unless file exists and is not a directory
+func checkInputFile(file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return inputFileError(file, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, expected a file", file)
+	}
+	return nil
+}
+
+// inputFileError describes why file could not be read, telling missing files, broken symlinks,
+// directories and permission errors apart. err is wrapped so that errors.Is still matches it.
+func inputFileError(file string, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if target, linkErr := os.Readlink(file); linkErr == nil {
+			return fmt.Errorf("%s is a broken symlink: its target %s does not exist: %w", file, target, err)
+		}
+		return fmt.Errorf("%s does not exist: %w", file, err)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("permission denied reading %s; check that it and its parent directories are readable by the current user: %w", file, err)
+	case errors.Is(err, syscall.EISDIR):
+		return fmt.Errorf("%s is a directory, expected a file: %w", file, err)
+	default:
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/options"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+)
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory [file-or-dir] ...",
+	Short: "Summarize the resources of Kubernetes manifests",
+	Long: `Parse Kubernetes manifests and print an inventory of their resources: counts per kind
+and namespace, resources defined more than once, and documents that are not Kubernetes
+objects or lack a name. Use it to sanity-check inputs before diffing them.
+Directories are read like the inputs of diff. Exits with 1 if duplicates or warnings
+are found.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateSelectorFlags(
+			selectorFlag{name: "label", values: inventoryLabelSelectors, labels: true},
+			selectorFlag{name: "annotation", values: inventoryAnnotationSelectors},
+		); err != nil {
+			return err
+		}
+		filterOption, err := options.FilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		limits, err := inputLimits()
+		if err != nil {
+			return err
+		}
+		opts := &parser.Options{FilterOption: filterOption, Limits: limits}
+
+		inventory := parser.NewInventory()
+		for _, arg := range args {
+			files, err := inventoryFiles(arg)
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				fileInventory, err := readFileInventory(file, opts)
+				if err != nil {
+					return err
+				}
+				inventory.Add(fileInventory)
+			}
+		}
+
+		fmt.Print(inventory.String())
+		if inventory.HasProblems() {
+			return changesFound(cmd)
+		}
+		return nil
+	},
+}
+
+// inventoryFiles returns the manifest file at path, or the manifest files below it if it is a directory
+func inventoryFiles(path string) ([]string, error) {
+	if isURL(path) || isArchive(path) {
+		return nil, fmt.Errorf("%s: inventory reads local manifest files and directories only", path)
+	}
+	// Sanitize file path to prevent path traversal
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, inputFileError(path, err)
+	}
+	if info.IsDir() {
+		return manifestFiles(path, excludeFileGlobs, followSymlinks)
+	}
+	return []string{path}, nil
+}
+
+// readFileInventory returns the inventory of a manifest file
+func readFileInventory(file string, opts *parser.Options) (*parser.Inventory, error) {
+	reader, err := os.Open(file) // #nosec G304 - file paths are CLI arguments and cleaned
+	if err != nil {
+		return nil, inputFileError(file, err)
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", file, err)
+		}
+	}()
+	return parser.ReadInventory(file, reader, opts)
+}
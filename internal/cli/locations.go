@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+)
+
+// headSources locates the head resources of the diff command, annotating results with the lines of changed fields
+var headSources parser.SourceMap
+
+// isAnnotationFormat reports whether format reports changed fields at their lines: sarif for code scanning
+// and rdjson for reviewdog
+func isAnnotationFormat(format string) bool {
+	return format == "sarif" || format == "rdjson"
+}
+
+// needsHeadSources reports whether the output of the diff command carries field annotations: annotation
+// formats, JSON Lines and saved results
+func needsHeadSources() bool {
+	return isAnnotationFormat(outputFormat) || outputFormat == "jsonl" || saveFile != ""
+}
+
+// locateManifestFields locates the resources and fields of a manifest file, or of the manifest files below a
+// directory. URLs and archives have no lines to annotate and are not located.
+func locateManifestFields(file string) (parser.SourceMap, error) {
+	if isURL(file) || isArchive(filepath.Clean(file)) {
+		return nil, nil
+	}
+	file = filepath.Clean(file)
+
+	files := []string{file}
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		if files, err = manifestFiles(file, excludeFileGlobs, followSymlinks); err != nil {
+			return nil, err
+		}
+	}
+	sources := make(parser.SourceMap)
+	for _, f := range files {
+		if isArchive(f) {
+			continue
+		}
+		data, err := os.ReadFile(f) // #nosec G304 - file paths are CLI arguments and cleaned
+		if err != nil {
+			return nil, inputFileError(f, err)
+		}
+		located, err := parser.LocateFields(filepath.ToSlash(f), data)
+		if err != nil {
+			return nil, err
+		}
+		sources.Merge(located)
+	}
+	return sources, nil
+}
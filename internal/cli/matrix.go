@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/options"
+)
+
+var matrixCmd = &cobra.Command{
+	Use:   "matrix [env-file] [env-file] ...",
+	Short: "Compare multiple rendered environments against a reference",
+	Long: `Compare rendered manifests of multiple environments against a reference environment
+and show a matrix of which resources differ in which environment.
+Each argument is either a file path or name=path. Without an explicit name, the
+file name without its extension is used as the environment name. The first
+environment is the reference unless --reference is specified. With --n-way, all
+environments are compared with each other instead, and the matrix shows which
+environments hold equal objects.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if matrixOutputFormat != "default" && matrixOutputFormat != "markdown" {
+			return fmt.Errorf("invalid output format: %s (supported formats: default, markdown)", matrixOutputFormat)
+		}
+		if matrixNWay && matrixReference != "" {
+			return fmt.Errorf("--n-way compares all environments with each other and cannot be combined with --reference")
+		}
+
+		environments := make([]diff.Environment, 0, len(args))
+		for _, arg := range args {
+			name, file := parseEnvironmentArg(arg)
+			objs, err := readManifestFile(file)
+			if err != nil {
+				return err
+			}
+			environments = append(environments, diff.Environment{Name: name, Objects: objs})
+		}
+
+		if err := validateSelectorFlags(
+			selectorFlag{name: "label", values: matrixLabelSelectors, labels: true},
+			selectorFlag{name: "annotation", values: matrixAnnotationSelectors},
+			selectorFlag{name: "exclude-label", values: matrixExcludeLabels, labels: true},
+			selectorFlag{name: "exclude-annotation", values: matrixExcludeAnnotations},
+		); err != nil {
+			return err
+		}
+		opts, err := options.FromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if err := applyMaskKey(opts); err != nil {
+			return err
+		}
+
+		if matrixNWay {
+			return runNWay(cmd, environments, opts)
+		}
+
+		referenceName := matrixReference
+		if referenceName == "" {
+			referenceName = environments[0].Name
+		}
+
+		var reference *diff.Environment
+		others := make([]diff.Environment, 0, len(environments)-1)
+		for i := range environments {
+			if environments[i].Name == referenceName && reference == nil {
+				reference = &environments[i]
+				continue
+			}
+			others = append(others, environments[i])
+		}
+		if reference == nil {
+			return fmt.Errorf("reference environment not found: %s", referenceName)
+		}
+
+		matrix, err := diff.Matrix(*reference, others, opts)
+		if err != nil {
+			return fmt.Errorf("failed to compute matrix: %w", err)
+		}
+
+		if matrixOutputFormat == "markdown" {
+			fmt.Println(matrix.StringMatrixMarkdown())
+		} else {
+			fmt.Println(matrix.StringMatrix())
+		}
+
+		if matrix.HasChanges() {
+			return changesFound(cmd)
+		}
+		return nil
+	},
+}
+
+// runNWay compares all environments with each other and prints which hold equal objects, exiting with 1 if
+// any resource is missing from an environment or differs between environments
+func runNWay(cmd *cobra.Command, environments []diff.Environment, opts *diff.Options) error {
+	nway, err := diff.NWay(environments, opts)
+	if err != nil {
+		return fmt.Errorf("failed to compute n-way comparison: %w", err)
+	}
+	if matrixOutputFormat == "markdown" {
+		fmt.Println(nway.StringMatrixMarkdown())
+	} else {
+		fmt.Println(nway.StringMatrix())
+	}
+
+	if nway.HasChanges() {
+		return changesFound(cmd)
+	}
+	return nil
+}
+
+// parseEnvironmentArg splits a name=path argument into environment name and file path
+func parseEnvironmentArg(arg string) (string, string) {
+	if name, file, found := strings.Cut(arg, "="); found && name != "" {
+		return name, file
+	}
+	base := filepath.Base(arg)
+	return strings.TrimSuffix(base, filepath.Ext(base)), arg
+}
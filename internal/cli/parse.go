@@ -1,13 +1,12 @@
-package main
+package cli
 
 import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/options"
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
 )
 
@@ -19,42 +18,47 @@ This command processes one or more YAML files and outputs the manifests with
 secret data values masked for security purposes. Supports filtering options
 to exclude specific resource types or filter by labels/annotations.`,
 	Args: cobra.MinimumNArgs(1),
-	RunE: func(_ *cobra.Command, args []string) error {
-		// Parse label selectors into map
-		parseLabelSelectorMap := make(map[string]string)
-		for _, selector := range parseLabelSelectors {
-			if strings.Contains(selector, "=") {
-				parts := strings.SplitN(selector, "=", 2)
-				if len(parts) == 2 {
-					parseLabelSelectorMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-				}
-			}
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateSelectorFlags(
+			selectorFlag{name: "label", values: parseLabelSelectors, labels: true},
+			selectorFlag{name: "annotation", values: parseAnnotationSelectors},
+			selectorFlag{name: "exclude-label", values: parseExcludeLabels, labels: true},
+			selectorFlag{name: "exclude-annotation", values: parseExcludeAnnotations},
+		); err != nil {
+			return err
 		}
 
-		// Parse annotation selectors into map
-		parseAnnotationSelectorMap := make(map[string]string)
-		for _, selector := range parseAnnotationSelectors {
-			if strings.Contains(selector, "=") {
-				parts := strings.SplitN(selector, "=", 2)
-				if len(parts) == 2 {
-					parseAnnotationSelectorMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-				}
-			}
+		filterOption, err := options.FilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		limits, err := inputLimits()
+		if err != nil {
+			return err
 		}
 
 		// Create parser options
 		opts := &parser.Options{
-			FilterOption: &filter.Option{
-				ExcludeKinds:       parseExcludeKinds,
-				LabelSelector:      parseLabelSelectorMap,
-				AnnotationSelector: parseAnnotationSelectorMap,
-			},
+			FilterOption:          filterOption,
 			DisableMaskingSecrets: parseDisableMaskingSecret,
+			Limits:                limits,
 		}
 
 		for i, file := range args {
 			// Sanitize file path to prevent path traversal
 			file = filepath.Clean(file)
+			if err := checkInputFile(file); err != nil {
+				return err
+			}
+
+			if parseReportAliases {
+				data, err := os.ReadFile(file) // #nosec G304 - file paths are CLI arguments and cleaned
+				if err != nil {
+					return fmt.Errorf("failed to open file %s: %w", file, err)
+				}
+				reportYAMLAliases(file, data)
+			}
 
 			// Open and read the file
 			reader, err := os.Open(file) // #nosec G304 - file paths are CLI arguments and cleaned
@@ -63,7 +67,12 @@ to exclude specific resource types or filter by labels/annotations.`,
 			}
 
 			// Process the file with filtering and masking options
-			results, err := parser.Yaml(reader, opts)
+			var results fmt.Stringer
+			if parsePreserveComments {
+				results, err = parser.YamlDocuments(reader, opts)
+			} else {
+				results, err = parser.Yaml(reader, opts)
+			}
 			if err != nil {
 				if closeErr := reader.Close(); closeErr != nil {
 					fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", file, closeErr)
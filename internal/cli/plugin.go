@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	// pluginName is the plugin name kubectl derives from the kubectl-diff_manifest binary
+	pluginName = "diff-manifest"
+	// pluginDisplayName is how the plugin is invoked and shown in usage
+	pluginDisplayName = "kubectl diff-manifest"
+)
+
+// ExecutePlugin runs the commands as the kubectl plugin "kubectl diff-manifest" and returns the process exit code.
+// Invocations without a subcommand, such as "kubectl diff-manifest base.yaml head.yaml", run the diff command.
+func ExecutePlugin(args []string) int {
+	rootCmd.Use = pluginName
+	rootCmd.Annotations = map[string]string{cobra.CommandDisplayNameAnnotation: pluginDisplayName}
+	rootCmd.SetArgs(pluginArgs(args))
+	return Execute()
+}
+
+// pluginArgs prepends the diff subcommand unless args already start with a subcommand or help request
+func pluginArgs(args []string) []string {
+	if len(args) == 0 || isRootArg(args[0]) {
+		return args
+	}
+	return append([]string{diffCmd.Name()}, args...)
+}
+
+// isRootArg reports whether arg is handled by the root command itself rather than by diff
+func isRootArg(arg string) bool {
+	switch arg {
+	case "-h", "--help", "help", "completion":
+		return true
+	}
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == arg {
+			return true
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == arg {
+				return true
+			}
+		}
+	}
+	return false
+}
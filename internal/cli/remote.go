@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Environment variables holding credentials for URL inputs.
+// A bearer token takes precedence over basic auth.
+const (
+	envInputToken    = "K8S_MANIFEST_DIFF_INPUT_TOKEN"
+	envInputUsername = "K8S_MANIFEST_DIFF_INPUT_USERNAME"
+	envInputPassword = "K8S_MANIFEST_DIFF_INPUT_PASSWORD"
+)
+
+// remoteInputTimeout bounds how long fetching a URL or object storage input may take unless --parse-timeout is given
+const remoteInputTimeout = 60 * time.Second
+
+// fetchTimeout returns how long fetching a remote input may take
+func fetchTimeout() time.Duration {
+	if parseTimeout > 0 {
+		return parseTimeout
+	}
+	return remoteInputTimeout
+}
+
+// objectStorageCommands maps object storage URI schemes to the CLI printing an object to stdout.
+// The CLIs resolve credentials with the standard AWS and Google Cloud credential chains.
+var objectStorageCommands = map[string][]string{
+	"s3": {"aws", "s3", "cp"},
+	"gs": {"gcloud", "storage", "cat"},
+}
+
+// isURL reports whether the input is an HTTP(S), s3:// or gs:// URL rather than a local path
+func isURL(input string) bool {
+	for _, prefix := range []string{"https://", "http://", "s3://", "gs://"} {
+		if strings.HasPrefix(input, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readManifestURL fetches and parses a manifest file or archive from a URL
+func readManifestURL(rawURL string) ([]*unstructured.Unstructured, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+	// Query strings may carry signed tokens, so they are left out of names shown in errors
+	name := u.Scheme + "://" + u.Host + u.Path
+
+	fetch := fetchURL
+	if _, ok := objectStorageCommands[u.Scheme]; ok {
+		fetch = fetchObject
+	}
+	data, err := fetch(u, name)
+	if err != nil {
+		return nil, err
+	}
+	if isArchive(u.Path) {
+		return readArchive(name, data, excludeFileGlobs, 0)
+	}
+	if reportAliases {
+		reportYAMLAliases(name, data)
+	}
+	objs, err := parseManifestData(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return objs, nil
+}
+
+// fetchURL downloads u, authenticating with credentials from the environment if set
+func fetchURL(u *url.URL, name string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", name, err)
+	}
+	token, username := os.Getenv(envInputToken), os.Getenv(envInputUsername)
+	authenticated := token != "" || username != ""
+	if authenticated && u.Scheme != "https" {
+		return nil, fmt.Errorf("refusing to send credentials to %s over plain http: use an https URL", name)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if username != "" {
+		req.SetBasicAuth(username, os.Getenv(envInputPassword))
+	}
+
+	client := &http.Client{
+		Timeout: fetchTimeout(),
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+			// Credentials are forwarded on redirects to the same host, which must not downgrade to plain http
+			if authenticated && next.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow redirect to plain http with credentials")
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", name, resp.Status)
+	}
+
+	data, err := readInput(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// fetchObject downloads an s3:// or gs:// object with the aws or gcloud CLI
+func fetchObject(u *url.URL, name string) ([]byte, error) {
+	command := objectStorageCommands[u.Scheme]
+	args := append(append([]string{}, command[1:]...), u.String())
+	if u.Scheme == "s3" {
+		// "aws s3 cp" writes to stdout when the destination is "-"
+		args = append(args, "-")
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %s CLI not found in PATH (required for %s:// inputs)", name, command[0], u.Scheme)
+	}
+
+	timeout := fetchTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], args...) // #nosec G204 - the command is fixed and the URI is a CLI argument
+	cmd.Stderr = &stderr
+	// Children of the CLI may keep its output open after it is killed
+	cmd.WaitDelay = time.Second
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	data, readErr := readInput(stdout)
+	if readErr != nil {
+		// Stop the CLI rather than wait for it to write an input that is already too large
+		cancel()
+	}
+	err = cmd.Wait()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, fmt.Errorf("failed to fetch %s: timed out after %s", name, timeout)
+	case readErr != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", name, readErr)
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch %s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return data, nil
+}
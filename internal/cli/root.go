@@ -0,0 +1,906 @@
+// Package cli implements the k8s-manifest-diff commands shared by the standalone binary and the kubectl plugin.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/analyzer"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/options"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	version = "1.0.0"
+	commit  = "none"
+	date    = "unknown"
+)
+
+var (
+	excludeKinds            []string
+	labelSelectors          []string
+	annotationSelectors     []string
+	excludeLabels           []string
+	excludeAnnotations      []string
+	filterExpr              string
+	onChangeExec            string
+	onCleanExec             string
+	contextLines            int
+	disableMaskingSecret    bool
+	disableMaskingFor       []string
+	secretPolicies          []string
+	summary                 bool
+	outputFormat            string
+	disableIgnoreAnnotation bool
+	staged                  bool
+	stagedAgainst           string
+	cacheDir                string
+	minimumChangedLines     int
+	pruneTracking           []string
+	profile                 string
+	allowPotentialSecrets   bool
+	maskScope               string
+	maskStrategy            string
+	maskToken               string
+	maskMinLength           int
+	maskingAuditFile        string
+	splitScope              bool
+	showUnchanged           bool
+	groupLinked             bool
+	saveFile                string
+	onlyResources           []string
+	excludeFileGlobs        []string
+	followSymlinks          bool
+	pruneScriptFile         string
+	severityConfigFile      string
+	failOnSeverity          string
+	ownersConfigFile        string
+	frontMatter             string
+	maxResourcesInReport    int
+	reportURL               string
+	checks                  []string
+	lastApplied             bool
+	fieldManager            string
+	filterKinds             []string
+	filterNamespaces        []string
+	filterNames             []string
+	filterChangeTypes       []string
+	expandBelowLines        int
+	fullObjects             string
+	unnamedMatching         string
+	renameThreshold         float64
+	reportAliases           bool
+	conversionConfigFile    string
+	workloadConfigFile      string
+	printOptions            bool
+	normalizeKnownKinds     bool
+	ignoreEOL               bool
+	ignoreValueRegexes      []string
+	continueOnError         bool
+	sopsDecrypt             bool
+	summarizeStatus         bool
+	summarizeSealedSecrets  bool
+	failFast                bool
+	statsHistoryFile        string
+	statsPushgateway        string
+	stripNamePrefixes       []string
+	stripNameSuffixes       []string
+	mapNameRegexes          []string
+)
+
+// Root command variables
+var (
+	actionMode   bool
+	maxInputSize string
+	maxDocuments int
+	parseTimeout time.Duration
+)
+
+// Parse command specific variables
+var (
+	parseExcludeKinds            []string
+	parseLabelSelectors          []string
+	parseAnnotationSelectors     []string
+	parseExcludeLabels           []string
+	parseExcludeAnnotations      []string
+	parseFilterExpr              string
+	parseDisableMaskingSecret    bool
+	parseDisableIgnoreAnnotation bool
+	parsePreserveComments        bool
+	parseReportAliases           bool
+)
+
+// Inventory command specific variables
+var (
+	inventoryExcludeKinds        []string
+	inventoryLabelSelectors      []string
+	inventoryAnnotationSelectors []string
+	inventoryFilterExpr          string
+)
+
+// Matrix command specific variables
+var (
+	matrixReference               string
+	matrixExcludeKinds            []string
+	matrixLabelSelectors          []string
+	matrixAnnotationSelectors     []string
+	matrixExcludeLabels           []string
+	matrixExcludeAnnotations      []string
+	matrixFilterExpr              string
+	matrixDisableMaskingSecret    bool
+	matrixDisableMaskingFor       []string
+	matrixSecretPolicies          []string
+	matrixMaskScope               string
+	matrixMaskStrategy            string
+	matrixMaskToken               string
+	matrixMaskMinLength           int
+	matrixDisableIgnoreAnnotation bool
+	matrixOutputFormat            string
+	matrixNWay                    bool
+)
+
+// Show command specific variables
+var (
+	showOutputFormat          string
+	showSummary               bool
+	showSplitScope            bool
+	showShowUnchanged         bool
+	showGroupByOwner          bool
+	showGroupLinked           bool
+	showAllowPotentialSecrets bool
+	showFrontMatter           string
+	showMaxResourcesInReport  int
+	showReportURL             string
+	showKinds                 []string
+	showNamespaces            []string
+	showTypes                 []string
+	showCompare               string
+)
+
+// Comment command specific variables
+var (
+	commentMaxLength             int
+	commentFullReport            string
+	commentOutputDir             string
+	commentReportURL             string
+	commentAllowPotentialSecrets bool
+)
+
+// Drift command specific variables
+var (
+	driftLiveFile            string
+	driftLiveCommand         string
+	driftInterval            time.Duration
+	driftOnce                bool
+	driftMetricsAddr         string
+	driftNotifyWebhook       string
+	driftExcludeKinds        []string
+	driftLastApplied         bool
+	driftFieldManager        string
+	driftLiveDiscover        bool
+	driftNormalizeKnownKinds bool
+)
+
+// Snapshot command specific variables
+var (
+	snapshotLiveFile             string
+	snapshotLiveCommand          string
+	snapshotOutput               string
+	snapshotKeepStatus           bool
+	snapshotDisableMaskingSecret bool
+	snapshotExcludeKinds         []string
+	snapshotLabelSelectors       []string
+)
+
+// Gen-fixture command specific variables
+var (
+	genFixtureSpecFile       string
+	genFixtureOutput         string
+	genFixtureWorkloadConfig string
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "k8s-manifest-diff",
+	Short: "Compare Kubernetes YAML manifests",
+	Long: `k8s-manifest-diff is a tool for comparing Kubernetes YAML manifests.
+It can filter out specific resources like hooks, secrets, or custom kinds,
+and use custom diff commands for comparison.`,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		if err := applyEnvFlags(cmd); err != nil {
+			return err
+		}
+		return startTracing()
+	},
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if actionMode {
+			return runAction(cmd)
+		}
+		return cmd.Help()
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [base-file] [head-file] | diff --staged [file...]",
+	Short: "Compare two Kubernetes YAML files",
+	Long: `Compare two Kubernetes YAML manifest files and show the differences.
+Either argument may be a directory or a .tgz, .tar.gz or .zip archive, whose YAML and
+JSON files are read recursively except for paths matched by its .diffignore file or
+--exclude-file-glob.
+Arguments may also be HTTP(S) URLs, authenticated with K8S_MANIFEST_DIFF_INPUT_TOKEN
+(bearer) or K8S_MANIFEST_DIFF_INPUT_USERNAME and K8S_MANIFEST_DIFF_INPUT_PASSWORD (basic).
+s3:// and gs:// URIs are fetched with the aws and gcloud CLIs and their credential chains.
+Supports filtering options to exclude specific resource types.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if staged {
+			return nil
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		ctx, span := tracer.Start(cmd.Context(), "k8s-manifest-diff diff")
+		defer func() { endSpan(span, err) }()
+
+		if (outputFormat == "jsonl" || isAnnotationFormat(outputFormat)) && (summary || printOptions) {
+			return fmt.Errorf("--output-format %s cannot be combined with --summary or --print-options", outputFormat)
+		}
+		if failFast && (saveFile != "" || pruneScriptFile != "" || statsHistoryFile != "" || statsPushgateway != "") {
+			return fmt.Errorf("--fail-fast cannot be combined with --save, --emit-prune-script, --stats-history or --stats-pushgateway, whose results would be incomplete")
+		}
+		var baseObjs, headObjs []*unstructured.Unstructured
+		if staged {
+			baseObjs, headObjs, err = loadStagedObjects(args, stagedAgainst)
+			if err != nil {
+				return fmt.Errorf("failed to load staged manifests: %w", err)
+			}
+			// Staged mode is meant for quick local feedback, so only the summary is shown
+			summary = true
+		} else {
+			if baseObjs, headObjs, err = readManifestFiles(ctx, args[0], args[1]); err != nil {
+				return err
+			}
+			if needsHeadSources() {
+				if headSources, err = locateManifestFields(args[1]); err != nil {
+					// Results are still complete without annotations
+					fmt.Fprintf(os.Stderr, "Warning: changed fields are not annotated with their lines: %v\n", err)
+				}
+			}
+		}
+
+		if failFast {
+			// Resources are compared without diff text, so only the summary can be shown
+			summary = true
+		}
+
+		var onResult diff.ResultFunc
+		if outputFormat == "jsonl" {
+			onResult = streamResultLines(os.Stdout)
+		}
+		results, opts, err := computeDiff(ctx, cmd, baseObjs, headObjs, onResult)
+		if err != nil {
+			return err
+		}
+		// Saved results and the prune script cover everything; only the printed output is filtered
+		shown, err := filterResults(results, diffResultFilter())
+		if err != nil {
+			return err
+		}
+		analysis, err := runChecks(cmd, baseObjs, headObjs)
+		if err != nil {
+			return err
+		}
+
+		if saveFile != "" {
+			// Saved results contain the diff text of every resource, even with --summary
+			if !allowPotentialSecrets {
+				if err := checkPotentialSecrets(results); err != nil {
+					return err
+				}
+			}
+			if err := saveResults(saveFile, results, opts.Effective()); err != nil {
+				return err
+			}
+		}
+		if pruneScriptFile != "" {
+			if err := writePruneScript(pruneScriptFile, results); err != nil {
+				return err
+			}
+		}
+		if err := recordStatistics(args, results); err != nil {
+			return err
+		}
+		if !allowPotentialSecrets && (onChangeExec != "" || onCleanExec != "") {
+			// The JSON given to the hooks contains the diff text of the printed results, even with --summary
+			if err := checkPotentialSecrets(shown); err != nil {
+				return err
+			}
+		}
+		if shown.HasChanges() && onChangeExec != "" {
+			if err := runResultHook("--on-change-exec", onChangeExec, shown); err != nil {
+				return err
+			}
+		} else if !shown.HasChanges() && onCleanExec != "" {
+			if err := runResultHook("--on-clean-exec", onCleanExec, shown); err != nil {
+				return err
+			}
+		}
+
+		if outputFormat == "jsonl" {
+			// Results were printed as they were computed; the analysis goes to stderr so that stdout stays JSON Lines
+			fmt.Fprint(os.Stderr, analysis)
+			if err := failedResourcesError(results); err != nil {
+				return err
+			}
+			if !staged && shown.HasChanges() && exceedsFailSeverity(shown) {
+				return changesFound(cmd)
+			}
+			return nil
+		}
+		if isAnnotationFormat(outputFormat) {
+			// The log is printed even without changes so that annotations of earlier runs are cleared;
+			// the analysis goes to stderr so that stdout stays machine-readable
+			output, err := renderResults(shown, diffRenderOptions(outputFormat))
+			if err != nil {
+				return err
+			}
+			fmt.Print(output)
+			fmt.Fprint(os.Stderr, analysis)
+			if err := failedResourcesError(results); err != nil {
+				return err
+			}
+			if shown.HasChanges() && exceedsFailSeverity(shown) {
+				return changesFound(cmd)
+			}
+			return nil
+		}
+		if printOptions {
+			header, err := optionsHeader(opts, outputFormat)
+			if err != nil {
+				return err
+			}
+			fmt.Print(header)
+		}
+		if shown.HasChanges() || showUnchanged {
+			output, err := renderResults(shown, unchangedListing(diffRenderOptions(outputFormat), shown))
+			if err != nil {
+				return err
+			}
+			fmt.Print(output)
+			fmt.Print(analysis)
+			if err := failedResourcesError(results); err != nil {
+				return err
+			}
+			// Staged mode is informational and must not block commits
+			if !staged && shown.HasChanges() && exceedsFailSeverity(shown) {
+				return changesFound(cmd)
+			}
+			return nil
+		}
+		block, err := frontMatterBlock(shown, frontMatter, outputFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Print(block)
+		fmt.Println("No differences found")
+		fmt.Print(analysis)
+		if err := failedResourcesError(results); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+// envMaskKey holds the secret key of the hash mask strategy; it is not a flag so that it stays out of process lists
+const envMaskKey = "K8S_MANIFEST_DIFF_MASK_KEY"
+
+// computeDiff diffs the objects using the options given by the flags of the diff command cmd,
+// passing each result to onResult as it is computed if it is not nil
+func computeDiff(ctx context.Context, cmd *cobra.Command, baseObjs, headObjs []*unstructured.Unstructured, onResult diff.ResultFunc) (diff.Results, *diff.Options, error) {
+	// Validate output format
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return nil, nil, err
+	}
+
+	if frontMatter != "" {
+		if _, err := validateFrontMatter(frontMatter, outputFormat); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := validateMaxResourcesInReport(maxResourcesInReport, reportURL, outputFormat); err != nil {
+		return nil, nil, err
+	}
+	if err := validateGroupLinked(groupLinked, outputFormat); err != nil {
+		return nil, nil, err
+	}
+
+	severityPolicy, err := loadSeverityPolicy(severityConfigFile, failOnSeverity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ownersConfigFile != "" && splitScope {
+		return nil, nil, fmt.Errorf("--owners-config cannot be combined with --split-scope")
+	}
+	if groupLinked && (ownersConfigFile != "" || splitScope) {
+		return nil, nil, fmt.Errorf("--group-linked cannot be combined with --owners-config or --split-scope")
+	}
+	ownershipPolicy, err := loadOwnershipPolicy(ownersConfigFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	conversionPolicy, err := loadConversionPolicy(conversionConfigFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	workloadPolicy, err := loadWorkloadPolicy(workloadConfigFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateDiffSelectorFlags(); err != nil {
+		return nil, nil, err
+	}
+	opts, err := options.FromFlags(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.WithSeverity(severityPolicy).
+		WithOwners(ownershipPolicy).
+		WithConversions(conversionPolicy).
+		WithWorkloads(workloadPolicy).
+		WithDiffStyle(diffStyleFor(outputFormat)).
+		WithOnResult(onResult).
+		WithHeadSources(headSources)
+	if err := applyMaskKey(opts); err != nil {
+		return nil, nil, err
+	}
+
+	if maskingAuditFile != "" {
+		auditFile, err := os.OpenFile(filepath.Clean(maskingAuditFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 - file path is a CLI argument and cleaned
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create masking audit file: %w", err)
+		}
+		defer func() {
+			if err := auditFile.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to close masking audit file: %v\n", err)
+			}
+		}()
+		opts.MaskingAudit = auditFile
+	}
+
+	// Perform diff
+	results, err := diff.ObjectsContext(ctx, baseObjs, headObjs, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to diff objects: %w", err)
+	}
+	return results, opts, nil
+}
+
+// diffFilterOption returns the filter options given by the flags of the diff command cmd
+func diffFilterOption(cmd *cobra.Command) (*filter.Option, error) {
+	if err := validateDiffSelectorFlags(); err != nil {
+		return nil, err
+	}
+	return options.FilterFromFlags(cmd)
+}
+
+// validateDiffSelectorFlags checks the selector flags of the diff command if --strict-flags is set
+func validateDiffSelectorFlags() error {
+	return validateSelectorFlags(
+		selectorFlag{name: "label", values: labelSelectors, labels: true},
+		selectorFlag{name: "annotation", values: annotationSelectors},
+		selectorFlag{name: "exclude-label", values: excludeLabels, labels: true},
+		selectorFlag{name: "exclude-annotation", values: excludeAnnotations},
+		selectorFlag{name: "prune-tracking", values: pruneTracking},
+	)
+}
+
+// runChecks runs the analyzer checks given by --checks on the filtered objects and renders the report
+func runChecks(cmd *cobra.Command, baseObjs, headObjs []*unstructured.Unstructured) (string, error) {
+	if len(checks) == 0 {
+		return "", nil
+	}
+	parsed := make([]analyzer.Check, 0, len(checks))
+	for _, name := range checks {
+		check, err := analyzer.ParseCheck(name)
+		if err != nil {
+			return "", err
+		}
+		parsed = append(parsed, check)
+	}
+
+	filterOption, err := diffFilterOption(cmd)
+	if err != nil {
+		return "", err
+	}
+	workloadPolicy, err := loadWorkloadPolicy(workloadConfigFile)
+	if err != nil {
+		return "", err
+	}
+	baseObjs, err = filter.Select(baseObjs, filterOption)
+	if err != nil {
+		return "", err
+	}
+	headObjs, err = filter.Select(headObjs, filterOption)
+	if err != nil {
+		return "", err
+	}
+	report := analyzer.RunWithWorkloads(baseObjs, headObjs, parsed, workloadPolicy)
+	switch {
+	case report.IsEmpty():
+		return "", nil
+	case outputFormat == "markdown":
+		return "\n\n" + report.Markdown() + "\n", nil
+	default:
+		return report.String(), nil
+	}
+}
+
+// renderOptions controls how results are rendered
+type renderOptions struct {
+	format                string
+	summary               bool
+	splitScope            bool
+	showUnchanged         bool
+	byOwner               bool
+	byChangeSet           bool
+	allowPotentialSecrets bool
+	frontMatter           string
+	maxResources          int
+	reportURL             string
+}
+
+// diffRenderOptions returns render options from the diff command flags with the given format
+func diffRenderOptions(format string) renderOptions {
+	return renderOptions{
+		format:                format,
+		summary:               summary,
+		splitScope:            splitScope,
+		showUnchanged:         showUnchanged,
+		byOwner:               ownersConfigFile != "",
+		byChangeSet:           groupLinked,
+		allowPotentialSecrets: allowPotentialSecrets,
+		frontMatter:           frontMatter,
+		maxResources:          maxResourcesInReport,
+		reportURL:             reportURL,
+	}
+}
+
+// unchangedListing returns ro for rendering results, switched to a summary if ro lists unchanged resources and
+// the results have no changes, so that the examined resources are listed where there is no diff to show
+func unchangedListing(ro renderOptions, results diff.Results) renderOptions {
+	if ro.showUnchanged && !results.HasChanges() {
+		ro.summary = true
+	}
+	return ro
+}
+
+// renderResults renders results according to the render options
+func renderResults(results diff.Results, ro renderOptions) (string, error) {
+	if ro.frontMatter != "" {
+		return renderWithFrontMatter(results, ro)
+	}
+	// Release notes contain no diff content, so they are the same with --summary and need no secret check
+	if ro.format == "notes" {
+		return results.StringReleaseNotes() + "\n", nil
+	}
+	// SARIF and rdjson locate changed fields by path and line without their values
+	switch ro.format {
+	case "sarif":
+		data, err := results.SARIF()
+		return string(data), err
+	case "rdjson":
+		data, err := results.RDJSON()
+		return string(data), err
+	}
+	// Unchanged resources are counted in summaries without being listed; jsonl records do not list resources
+	if !ro.showUnchanged {
+		results = results.UnlistUnchanged()
+	}
+	if ro.format == "plan" && ro.summary {
+		return results.StringPlanSummary() + "\n", nil
+	}
+	if ro.summary {
+		switch {
+		case ro.format == "markdown" && ro.byOwner:
+			return results.StringSummaryMarkdownByOwner(), nil
+		case ro.format == "markdown" && ro.byChangeSet:
+			return results.StringSummaryMarkdownByChangeSet(), nil
+		case ro.format == "markdown" && ro.splitScope:
+			return results.StringSummaryMarkdownSplitScope(), nil
+		case ro.format == "markdown":
+			return results.StringSummaryMarkdown(), nil
+		case ro.splitScope:
+			return results.StringSummarySplitScope(), nil
+		default:
+			return results.StringSummary(), nil
+		}
+	}
+
+	if !ro.allowPotentialSecrets {
+		if err := checkPotentialSecrets(results); err != nil {
+			return "", err
+		}
+	}
+	if ro.format == "markdown" && ro.maxResources > 0 {
+		return renderLimitedMarkdown(results, ro)
+	}
+	switch {
+	case ro.format == "jsonl":
+		return renderResultLines(results)
+	case ro.format == "plan":
+		return results.StringPlan() + "\n", nil
+	case ro.format == "markdown" && ro.byOwner:
+		return results.StringDiffMarkdownByOwner(), nil
+	case ro.format == "markdown" && ro.byChangeSet:
+		return results.StringDiffMarkdownByChangeSet(), nil
+	case ro.format == "markdown" && ro.splitScope:
+		return results.StringDiffMarkdownSplitScope(), nil
+	case ro.format == "markdown":
+		return results.StringDiffMarkdown(), nil
+	case ro.splitScope:
+		return results.StringDiffSplitScope(), nil
+	default:
+		return results.StringDiff(), nil
+	}
+}
+
+// renderWithFrontMatter renders Markdown results preceded by their machine-readable metadata
+func renderWithFrontMatter(results diff.Results, ro renderOptions) (string, error) {
+	block, err := frontMatterBlock(results, ro.frontMatter, ro.format)
+	if err != nil {
+		return "", err
+	}
+	ro.frontMatter = ""
+	output, err := renderResults(results, ro)
+	if err != nil {
+		return "", err
+	}
+	return block + output, nil
+}
+
+// frontMatterBlock returns the --front-matter block of the results, or "" if it is disabled. It is printed even
+// without changes so that bots always find the metadata.
+func frontMatterBlock(results diff.Results, frontMatter, outputFormat string) (string, error) {
+	if frontMatter == "" {
+		return "", nil
+	}
+	format, err := validateFrontMatter(frontMatter, outputFormat)
+	if err != nil {
+		return "", err
+	}
+	return results.FrontMatter().Render(format)
+}
+
+// renderLimitedMarkdown renders the Markdown report with the diff sections of at most ro.maxResources resources,
+// ending with a note on the omitted resources that links to ro.reportURL
+func renderLimitedMarkdown(results diff.Results, ro renderOptions) (string, error) {
+	limited, omitted := results.LimitResourceSections(ro.maxResources)
+	ro.maxResources = 0
+	output, err := renderResults(limited, ro)
+	if err != nil {
+		return "", err
+	}
+	return output + diff.OmittedSectionsNote(omitted, ro.reportURL), nil
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version information",
+	Run: func(_ *cobra.Command, _ []string) {
+		fmt.Printf("k8s-manifest-diff version %s\n", version)
+		fmt.Printf("commit: %s\n", commit)
+		fmt.Printf("date: %s\n", date)
+	},
+}
+
+func init() {
+	// Root command flags
+	rootCmd.PersistentFlags().BoolVar(&strictFlags, "strict-flags", false, "Reject malformed --label, --annotation, --exclude-label and --exclude-annotation values with an error instead of ignoring them")
+	rootCmd.PersistentFlags().StringVar(&maxInputSize, "max-input-size", defaultMaxInputSize, "Reject input files, archive contents and fetched URLs larger than this size, e.g. '10Mi' (unlimited when 0)")
+	rootCmd.PersistentFlags().IntVar(&maxDocuments, "max-documents", 0, "Reject input files with more YAML documents or JSON objects than this (unlimited when 0)")
+	rootCmd.PersistentFlags().DurationVar(&parseTimeout, "parse-timeout", 0, "Fail when parsing an input file takes longer than this, e.g. '10s' (unlimited when 0); also bounds fetching URL and object storage inputs, 60s by default")
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", os.Getenv(envOTLPEndpoint), "OTLP/HTTP collector URL to export OpenTelemetry spans of the parse, filter, pair and render stages to, e.g. 'http://localhost:4318' (defaults to $OTEL_EXPORTER_OTLP_ENDPOINT; disabled when empty)")
+	rootCmd.Flags().BoolVar(&actionMode, "action", false, "Run as a GitHub Action: read INPUT_BASE, INPUT_HEAD and INPUT_OPTIONS_JSON, and write step outputs and a step summary")
+
+	// Diff command flags
+	diffCmd.Flags().StringSliceVar(&excludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from diff (Kind, Kind.group or group/Kind, e.g. Workflow.argoproj.io)")
+	diffCmd.Flags().StringSliceVar(&labelSelectors, "label", []string{}, "Label selector to filter resources (e.g., 'app=nginx', 'app=payments-*', 'app=~payments-(api|worker)', 'tier!=test', or 'app' for any value). Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&annotationSelectors, "annotation", []string{}, "Annotation selector to filter resources (e.g., 'app.kubernetes.io/managed-by=helm', 'deployment.category=web*', 'deployment.category!=batch', or 'fluxcd.io/*' and '~fluxcd\\.io/.*' for any fluxcd.io/ annotation). Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&excludeLabels, "exclude-label", []string{}, "Exclude resources with a label matching this selector, with the same syntax as --label. Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&excludeAnnotations, "exclude-annotation", []string{}, "Exclude resources with an annotation matching this selector, with the same syntax as --annotation. Can be specified multiple times.")
+	diffCmd.Flags().StringVar(&filterExpr, "filter-expr", "", "CEL expression objects must satisfy, with the object bound to 'object', e.g. \"object.kind == 'Deployment' && object.metadata.namespace.startsWith('team-a')\"")
+	diffCmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Descend into symlinked directories below a directory input (symlinked files are always read)")
+	diffCmd.Flags().StringSliceVar(&excludeFileGlobs, "exclude-file-glob", []string{}, "Skip files and directories matching this glob, relative to a directory input, in addition to its .diffignore (e.g. 'charts/**', '**/*_test.yaml'). Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&onlyResources, "only", []string{}, "Only diff resources matching Kind/namespace/name (or Kind/name) glob patterns, e.g. 'Deployment/default/web-*'. Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&stripNamePrefixes, "strip-name-prefix", []string{}, "Remove this prefix from resource names in base and head before pairing them, e.g. the namePrefix of a kustomize overlay. Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&stripNameSuffixes, "strip-name-suffix", []string{}, "Remove this suffix from resource names in base and head before pairing them, e.g. the nameSuffix of a kustomize overlay. Can be specified multiple times.")
+	diffCmd.Flags().StringArrayVar(&mapNameRegexes, "map-name-regex", []string{}, "Rewrite resource names in base and head before pairing them with a sed-style substitution, e.g. 's/^(staging|prod)-//'. Applied after --strip-name-prefix and --strip-name-suffix. Can be specified multiple times.")
+	diffCmd.Flags().BoolVar(&lastApplied, "last-applied", false, "Treat base as a live export and compare head against each resource's kubectl.kubernetes.io/last-applied-configuration instead of the full live object")
+	diffCmd.Flags().BoolVar(&summarizeStatus, "summarize-status", false, "Compare resources without their status (e.g. custom resources exported from a cluster) and print only condition transitions such as 'Ready: True -> False'")
+	diffCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop at the first created, changed or deleted resource and print only the summary of the resources compared so far, without diff text. Exits with 1 if a change was found")
+	diffCmd.Flags().BoolVar(&summarizeSealedSecrets, "summarize-sealed-secrets", false, "Compare Bitnami SealedSecrets without their encrypted values and print only which keys were added, removed or changed. Without it, encryptedData values are masked like Secret data")
+	diffCmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Report resources that fail to diff or mask, e.g. a malformed Secret, as failed and compare the others instead of aborting. Exits with 2 after printing the results if any failed")
+	diffCmd.Flags().BoolVar(&sopsDecrypt, "sops-decrypt", false, "Decrypt SOPS-encrypted manifests with the sops CLI before comparing them. Without it, or if decryption fails, encrypted resources are compared by their SOPS metadata (keys, lastmodified) with ciphertext masked")
+	diffCmd.Flags().StringArrayVar(&ignoreValueRegexes, "ignore-value-regex", []string{}, "Treat a base and a head string value at the same path as equal if both match this regular expression, e.g. '\\d{4}-\\d{2}-\\d{2}T' for generated timestamps. Can be specified multiple times.")
+	diffCmd.Flags().BoolVar(&ignoreEOL, "ignore-eol", false, "Treat CRLF and LF line endings inside string values (e.g. ConfigMap data generated from files edited on Windows) as equal")
+	diffCmd.Flags().BoolVar(&normalizeKnownKinds, "normalize-known-kinds", false, "Ignore common false positives of built-in kinds: server-assigned Service nodePorts and clusterIPs, default TCP port protocols, server-defaulted workload fields, serviceAccount aliasing serviceAccountName, the Deployment revision annotation and controller-added Job and pod-template-hash labels")
+	diffCmd.Flags().StringVar(&conversionConfigFile, "conversion-config", "", "YAML file of rules converting custom resources between API versions by moving fields, so base and head at different versions are compared at the same version")
+	diffCmd.Flags().BoolVar(&printOptions, "print-options", false, "Print the effective options (filters, masking, normalization) as a '# options:' JSON comment before the output; results saved with --save always record them")
+	diffCmd.Flags().StringVar(&workloadConfigFile, "workload-config", "", "YAML file of workload kinds and the paths of their pod spec and replica count, e.g. Argo Rollouts or Knative Services, covered by image changes and --checks in addition to the built-in kinds")
+	diffCmd.Flags().StringVar(&fieldManager, "field-manager", "", "Treat base as a live export and compare only fields owned by this manager in managedFields (e.g. 'argocd'), ignoring changes made by other controllers")
+	diffCmd.Flags().StringSliceVar(&filterKinds, "filter-kind", []string{}, "Only print results for these kinds; unlike --exclude-kinds, the diff is still computed and saved for all resources. Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&filterNamespaces, "filter-namespace", []string{}, "Only print results in these namespaces. Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&filterNames, "filter-name", []string{}, "Only print results for resources with these names. Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&filterChangeTypes, "filter-change-type", []string{}, "Only print results with these change types (created|changed|deleted|unchanged|error). Can be specified multiple times.")
+	diffCmd.Flags().IntVar(&contextLines, "context", 3, "Number of context lines in diff output")
+	diffCmd.Flags().StringVar(&profile, "profile", "", "Start from a bundle of option defaults: strict (compare everything as written), gitops (ignore server-assigned fields, status, SealedSecret ciphertexts and line endings) or audit (report every resource, with per-resource secret masks); flags that are set override it")
+	diffCmd.Flags().IntVar(&expandBelowLines, "expand-below-lines", 0, "Show changed resources whose YAML has fewer than N lines in full instead of as hunks (0 disables)")
+	diffCmd.Flags().StringVar(&fullObjects, "full-objects", "", "Print the complete masked base and head YAML of changed resources after the diff (append) or instead of it (only)")
+	diffCmd.Flags().StringVar(&unnamedMatching, "unnamed-matching", "index", "How objects without a name sharing a generateName are paired: by position (index), by content (similarity) or never (unmatched)")
+	diffCmd.Flags().Float64Var(&renameThreshold, "rename-threshold", 0, "Report a deleted and a created resource of the same kind whose content, ignoring name and namespace, is at least this similar (0-1, e.g. 0.8) as a rename (0 disables)")
+	diffCmd.Flags().BoolVar(&disableMaskingSecret, "disable-masking-secret", false, "Disable masking of Secret data values in diff output")
+	diffCmd.Flags().StringSliceVar(&disableMaskingFor, "disable-masking-for", []string{}, "Show Secret data values unmasked only for resources matching Kind/namespace/name glob patterns, e.g. 'Secret/default/public-*'. Can be specified multiple times.")
+	diffCmd.Flags().StringSliceVar(&secretPolicies, "secret-policy", []string{}, "Masking policy for a Secret type as type=policy (mask|certificate-metadata), e.g. 'kubernetes.io/tls=certificate-metadata'. Can be specified multiple times.")
+	diffCmd.Flags().BoolVar(&summary, "summary", false, "Output only the list of changed resources instead of full diff")
+	diffCmd.Flags().StringVar(&outputFormat, "output-format", "default", "Output format (default|markdown|plan|inline|report|notes|jsonl|sarif|rdjson)")
+	diffCmd.Flags().StringVar(&frontMatter, "front-matter", "", "Prepend counts, the highest severity and high-risk flags to markdown output as a json or yaml front-matter block for bots parsing the comment (disabled when empty)")
+	diffCmd.Flags().IntVar(&maxResourcesInReport, "max-resources-in-report", 0, "Show the diffs of at most N resources in markdown output, ending it with a note on how many more changed, so that PR comments stay readable for massive changes (0 disables)")
+	diffCmd.Flags().StringVar(&reportURL, "report-url", "", "URL of the full report, e.g. a build artifact, linked from the note ending markdown output limited by --max-resources-in-report")
+	diffCmd.Flags().BoolVar(&groupLinked, "group-linked", false, "Group the markdown report by change set: each changed workload together with the changed ConfigMaps and Secrets it mounts or reads into its environment")
+	diffCmd.Flags().BoolVar(&splitScope, "split-scope", false, "List cluster-scoped resources (CRDs, ClusterRoles, Namespaces, ...) separately from namespaced resources")
+	diffCmd.Flags().BoolVar(&showUnchanged, "show-unchanged", false, "List unchanged resources in summaries and reports, e.g. to confirm which resources were examined")
+	diffCmd.Flags().IntVar(&minimumChangedLines, "minimum-changed-lines", 0, "Fold changed resources with fewer changed lines into a trivial changes section (0 disables)")
+	diffCmd.Flags().StringSliceVar(&pruneTracking, "prune-tracking", []string{}, "Tracking label or annotation of a GitOps application (key=value, wildcards supported), e.g. 'app.kubernetes.io/instance=web'; deleted base resources carrying any of them are reported as would be pruned. Can be specified multiple times.")
+	diffCmd.Flags().StringVar(&severityConfigFile, "severity-config", "", "YAML file assigning severities (low|medium|high) to resources by kind and namespace; severities are shown in summaries")
+	diffCmd.Flags().StringVar(&failOnSeverity, "fail-on-severity", "", "Exit with 1 only if a changed resource has at least this severity (low|medium|high)")
+	diffCmd.Flags().StringVar(&ownersConfigFile, "owners-config", "", "YAML file mapping namespaces and labels to owning teams; the markdown report is grouped by owner with @-mentions")
+	diffCmd.Flags().StringSliceVar(&checks, "checks", []string{}, "Analyses to run on the manifests and report after the diff (quota|consistency). Can be specified multiple times.")
+	diffCmd.Flags().StringVar(&saveFile, "save", "", "Save the full results as JSON to this file for later rendering with the show command")
+	diffCmd.Flags().StringVar(&statsHistoryFile, "stats-history", "", "Append the timestamp, inputs and change counts of this run to this file, as a CSV row if it ends with .csv and a JSON line otherwise, to chart manifest churn over releases")
+	diffCmd.Flags().StringVar(&statsPushgateway, "stats-pushgateway", "", "Push the change counts of this run to this Prometheus Pushgateway URL, under the k8s-manifest-diff job unless the URL has a /metrics/job/ path; a failed push is a warning")
+	diffCmd.Flags().StringVar(&onChangeExec, "on-change-exec", "", "Shell command to run with the printed results as JSON (the --save format) on stdin when changes are detected")
+	diffCmd.Flags().StringVar(&onCleanExec, "on-clean-exec", "", "Shell command to run with the printed results as JSON (the --save format) on stdin when no changes are detected")
+	diffCmd.Flags().StringVar(&pruneScriptFile, "emit-prune-script", "", "Write a shell script of kubectl delete commands for resources deleted in head to this file")
+	diffCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory to cache rendered diffs between runs; unchanged inputs reuse previous diff text")
+	diffCmd.Flags().BoolVar(&staged, "staged", false, "Compare staged versions of the given manifest files (or all staged manifests) using git and print a summary. Always exits 0 unless an error occurs")
+	diffCmd.Flags().StringVar(&stagedAgainst, "staged-against", "head", "What staged versions are compared with in --staged mode (head|worktree)")
+	diffCmd.Flags().StringVar(&maskScope, "mask-scope", "global", "Scope within which identical secret values get identical masks (resource|operation|global)")
+	diffCmd.Flags().StringVar(&maskStrategy, "mask-strategy", "incremental", "How masked secret values are rendered: incremental ('++++...'), length ('<masked, 9-16 bytes>') or hash ('<masked:1a2b3c4d5e6f>', independent of processing order and keyed by $K8S_MANIFEST_DIFF_MASK_KEY, which is required)")
+	diffCmd.Flags().StringVar(&maskToken, "mask-token", "", "Token repeated by incremental masks instead of '+', e.g. '*' where downstream tools read runs of '+' as diff markers or formatting")
+	diffCmd.Flags().IntVar(&maskMinLength, "mask-min-length", 0, "Length of the first incremental mask, growing by one token for each further distinct value (16 when 0)")
+	diffCmd.Flags().StringVar(&maskingAuditFile, "masking-audit", "", "Write a JSON lines audit of masked values (resource, field, key and value hash) to this file")
+	diffCmd.Flags().BoolVar(&allowPotentialSecrets, "allow-potential-secrets", false, "Print the diff even if it appears to contain secrets such as private keys, tokens or high-entropy base64 strings")
+	diffCmd.Flags().BoolVar(&reportAliases, "report-aliases", false, "Print the YAML anchors, aliases and merge keys used in the input manifests to stderr; they are always resolved when parsing")
+	diffCmd.Flags().BoolVar(&disableIgnoreAnnotation, "disable-ignore-annotation", false, "Do not skip resources annotated with k8s-manifest-diff/ignore=true")
+
+	// Parse command flags
+	parseCmd.Flags().StringSliceVar(&parseExcludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from parsing (Kind, Kind.group or group/Kind, e.g. Workflow.argoproj.io)")
+	parseCmd.Flags().StringSliceVar(&parseLabelSelectors, "label", []string{}, "Label selector to filter resources (e.g., 'app=nginx', 'app=payments-*', 'app=~payments-(api|worker)', 'tier!=test', or 'app' for any value). Can be specified multiple times.")
+	parseCmd.Flags().StringSliceVar(&parseAnnotationSelectors, "annotation", []string{}, "Annotation selector to filter resources (e.g., 'app.kubernetes.io/managed-by=helm', 'deployment.category=web*', 'deployment.category!=batch', or 'fluxcd.io/*' and '~fluxcd\\.io/.*' for any fluxcd.io/ annotation). Can be specified multiple times.")
+	parseCmd.Flags().StringSliceVar(&parseExcludeLabels, "exclude-label", []string{}, "Exclude resources with a label matching this selector, with the same syntax as --label. Can be specified multiple times.")
+	parseCmd.Flags().StringSliceVar(&parseExcludeAnnotations, "exclude-annotation", []string{}, "Exclude resources with an annotation matching this selector, with the same syntax as --annotation. Can be specified multiple times.")
+	parseCmd.Flags().StringVar(&parseFilterExpr, "filter-expr", "", "CEL expression objects must satisfy, with the object bound to 'object', e.g. \"object.kind == 'Deployment' && object.metadata.namespace.startsWith('team-a')\"")
+	parseCmd.Flags().BoolVar(&parseDisableMaskingSecret, "disable-masking-secret", false, "Disable masking of Secret data values in output")
+	parseCmd.Flags().BoolVar(&parseDisableIgnoreAnnotation, "disable-ignore-annotation", false, "Do not skip resources annotated with k8s-manifest-diff/ignore=true")
+	parseCmd.Flags().BoolVar(&parsePreserveComments, "preserve-comments", false, "Keep comments and key order of the input manifests and output resources in input order")
+	inventoryCmd.Flags().StringSliceVar(&inventoryExcludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from the inventory (Kind, Kind.group or group/Kind, e.g. Workflow.argoproj.io); they are counted as excluded")
+	inventoryCmd.Flags().StringSliceVar(&inventoryLabelSelectors, "label", []string{}, "Label selector to filter resources (e.g., 'app=nginx', 'app=payments-*', 'tier!=test', or 'app' for any value). Can be specified multiple times.")
+	inventoryCmd.Flags().StringSliceVar(&inventoryAnnotationSelectors, "annotation", []string{}, "Annotation selector to filter resources (e.g., 'app.kubernetes.io/managed-by=helm' or 'fluxcd.io/*' and '~fluxcd\\.io/.*' for any fluxcd.io/ annotation). Can be specified multiple times.")
+	inventoryCmd.Flags().StringVar(&inventoryFilterExpr, "filter-expr", "", "CEL expression objects must satisfy, with the object bound to 'object'")
+	inventoryCmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Descend into symlinked directories below a directory input (symlinked files are always read)")
+	inventoryCmd.Flags().StringSliceVar(&excludeFileGlobs, "exclude-file-glob", []string{}, "Skip files and directories matching this glob, relative to a directory input, in addition to its .diffignore. Can be specified multiple times.")
+	parseCmd.Flags().BoolVar(&parseReportAliases, "report-aliases", false, "Print the YAML anchors, aliases and merge keys used in the input manifests to stderr; they are always resolved when parsing")
+
+	// Matrix command flags
+	matrixCmd.Flags().StringVar(&matrixReference, "reference", "", "Name of the reference environment (default: first environment)")
+	matrixCmd.Flags().StringSliceVar(&matrixExcludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from diff (Kind, Kind.group or group/Kind, e.g. Workflow.argoproj.io)")
+	matrixCmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Descend into symlinked directories below a directory input (symlinked files are always read)")
+	matrixCmd.Flags().StringSliceVar(&excludeFileGlobs, "exclude-file-glob", []string{}, "Skip files and directories matching this glob, relative to a directory input, in addition to its .diffignore. Can be specified multiple times.")
+	matrixCmd.Flags().StringSliceVar(&matrixLabelSelectors, "label", []string{}, "Label selector to filter resources (e.g., 'app=nginx', 'app=payments-*', 'app=~payments-(api|worker)', 'tier!=test', or 'app' for any value). Can be specified multiple times.")
+	matrixCmd.Flags().StringSliceVar(&matrixAnnotationSelectors, "annotation", []string{}, "Annotation selector to filter resources (e.g., 'app.kubernetes.io/managed-by=helm', 'deployment.category=web*', 'deployment.category!=batch', or 'fluxcd.io/*' and '~fluxcd\\.io/.*' for any fluxcd.io/ annotation). Can be specified multiple times.")
+	matrixCmd.Flags().StringSliceVar(&matrixExcludeLabels, "exclude-label", []string{}, "Exclude resources with a label matching this selector, with the same syntax as --label. Can be specified multiple times.")
+	matrixCmd.Flags().StringSliceVar(&matrixExcludeAnnotations, "exclude-annotation", []string{}, "Exclude resources with an annotation matching this selector, with the same syntax as --annotation. Can be specified multiple times.")
+	matrixCmd.Flags().StringVar(&matrixFilterExpr, "filter-expr", "", "CEL expression objects must satisfy, with the object bound to 'object', e.g. \"object.kind == 'Deployment' && object.metadata.namespace.startsWith('team-a')\"")
+	matrixCmd.Flags().BoolVar(&matrixDisableMaskingSecret, "disable-masking-secret", false, "Disable masking of Secret data values")
+	matrixCmd.Flags().StringSliceVar(&matrixDisableMaskingFor, "disable-masking-for", []string{}, "Show Secret data values unmasked only for resources matching Kind/namespace/name glob patterns, e.g. 'Secret/default/public-*'. Can be specified multiple times.")
+	matrixCmd.Flags().StringSliceVar(&matrixSecretPolicies, "secret-policy", []string{}, "Masking policy for a Secret type as type=policy (mask|certificate-metadata), e.g. 'kubernetes.io/tls=certificate-metadata'. Can be specified multiple times.")
+	matrixCmd.Flags().StringVar(&matrixMaskScope, "mask-scope", "global", "Scope within which identical secret values get identical masks (resource|operation|global)")
+	matrixCmd.Flags().StringVar(&matrixMaskStrategy, "mask-strategy", "incremental", "How masked secret values are rendered: incremental ('++++...'), length ('<masked, 9-16 bytes>') or hash ('<masked:1a2b3c4d5e6f>', keyed by $K8S_MANIFEST_DIFF_MASK_KEY, which is required)")
+	matrixCmd.Flags().StringVar(&matrixMaskToken, "mask-token", "", "Token repeated by incremental masks instead of '+'")
+	matrixCmd.Flags().IntVar(&matrixMaskMinLength, "mask-min-length", 0, "Length of the first incremental mask, growing by one token for each further distinct value (16 when 0)")
+	matrixCmd.Flags().BoolVar(&matrixDisableIgnoreAnnotation, "disable-ignore-annotation", false, "Do not skip resources annotated with k8s-manifest-diff/ignore=true")
+	matrixCmd.Flags().StringVar(&matrixOutputFormat, "output-format", "default", "Output format (default|markdown)")
+	matrixCmd.Flags().BoolVar(&matrixNWay, "n-way", false, "Compare all environments with each other instead of against a reference, showing which environments hold equal objects")
+
+	// Show command flags
+	showCmd.Flags().StringVar(&showOutputFormat, "output-format", "default", "Output format (default|markdown|plan|notes)")
+	showCmd.Flags().BoolVar(&showSummary, "summary", false, "Output only the list of changed resources instead of full diff")
+	showCmd.Flags().BoolVar(&showSplitScope, "split-scope", false, "List cluster-scoped resources separately from namespaced resources")
+	showCmd.Flags().BoolVar(&showShowUnchanged, "show-unchanged", false, "List unchanged resources in summaries and reports")
+	showCmd.Flags().BoolVar(&showGroupByOwner, "group-by-owner", false, "Group the markdown report by the owners saved with diff --owners-config")
+	showCmd.Flags().BoolVar(&showGroupLinked, "group-linked", false, "Group the markdown report by change set: each changed workload together with the changed ConfigMaps and Secrets it references")
+	showCmd.Flags().StringVar(&showFrontMatter, "front-matter", "", "Prepend counts, the highest severity and high-risk flags to markdown output as a json or yaml front-matter block (disabled when empty)")
+	showCmd.Flags().IntVar(&showMaxResourcesInReport, "max-resources-in-report", 0, "Show the diffs of at most N resources in markdown output, ending it with a note on how many more changed (0 disables)")
+	showCmd.Flags().StringVar(&showReportURL, "report-url", "", "URL of the full report linked from the note ending markdown output limited by --max-resources-in-report")
+	showCmd.Flags().BoolVar(&showAllowPotentialSecrets, "allow-potential-secrets", false, "Print the diff even if it appears to contain secrets")
+	showCmd.Flags().StringSliceVar(&showKinds, "kind", []string{}, "Only show resources of these kinds. Can be specified multiple times.")
+	showCmd.Flags().StringSliceVar(&showNamespaces, "namespace", []string{}, "Only show resources in these namespaces. Can be specified multiple times.")
+	showCmd.Flags().StringSliceVar(&showTypes, "type", []string{}, "Only show resources with these change types (created|changed|deleted|unchanged|error). Can be specified multiple times.")
+	showCmd.Flags().StringVar(&showCompare, "compare", "", "Compare with results saved from a previous run and list resources that started, stopped or changed differing")
+
+	// Comment command flags
+	commentCmd.Flags().IntVar(&commentMaxLength, "max-length", diff.DefaultCommentLimit, "Maximum length of a comment; longer reports are handled according to --full-report (unlimited when 0)")
+	commentCmd.Flags().StringVar(&commentFullReport, "full-report", fullReportSplit, "How reports longer than --max-length are posted: split into several comments, or a summary comment linking to the full report uploaded as a gist or an artifact (split|gist|artifact)")
+	commentCmd.Flags().StringVar(&commentOutputDir, "output-dir", "", "Directory to write comment-1.md, comment-2.md, ... to, and report.md with --full-report artifact (default: print the comments)")
+	commentCmd.Flags().StringVar(&commentReportURL, "report-url", "", "URL the full report is uploaded to with --full-report artifact, linked from the summary comment")
+	commentCmd.Flags().BoolVar(&commentAllowPotentialSecrets, "allow-potential-secrets", false, "Render the comments even if the diff appears to contain secrets")
+
+	// Drift command flags
+	driftCmd.Flags().StringVar(&driftLiveFile, "live-file", "", "File with the live cluster state, re-read on every check")
+	driftCmd.Flags().StringVar(&driftLiveCommand, "live-command", "", "Shell command printing the live cluster state as YAML, e.g. 'kubectl get deploy,svc -n app -o yaml'")
+	driftCmd.Flags().DurationVar(&driftInterval, "interval", 5*time.Minute, "Time between drift checks")
+	driftCmd.Flags().BoolVar(&driftOnce, "once", false, "Check once, print a summary and exit with 1 if drift is found")
+	driftCmd.Flags().StringVar(&driftMetricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on at /metrics, e.g. ':9090' (disabled when empty)")
+	driftCmd.Flags().StringVar(&driftNotifyWebhook, "notify-webhook", "", "URL to post a JSON notification to when drift appears or resolves")
+	driftCmd.Flags().StringSliceVar(&driftExcludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from drift checks (Kind, Kind.group or group/Kind, e.g. Workflow.argoproj.io)")
+	driftCmd.Flags().BoolVar(&driftLastApplied, "last-applied", false, "Compare manifests against each live resource's kubectl.kubernetes.io/last-applied-configuration")
+	driftCmd.Flags().StringVar(&driftFieldManager, "field-manager", "", "Compare only fields owned by this manager in managedFields")
+	driftCmd.Flags().BoolVar(&driftNormalizeKnownKinds, "normalize-known-kinds", true, "Ignore fields the API server assigns or defaults, such as Service clusterIPs, revisionHistoryLimit and imagePullPolicy, when they are missing from the manifests")
+	driftCmd.Flags().BoolVar(&driftLiveDiscover, "live-discover", false, "List the kinds of the manifests with 'kubectl get', in addition to --live-resources, so that live resources missing from the manifests are reported as deleted prune candidates. Narrow the listing with --live-selector and --live-namespace")
+	addLiveClusterFlags(driftCmd)
+
+	// Snapshot command flags
+	snapshotCmd.Flags().StringVar(&snapshotLiveFile, "live-file", "", "File with the live resources to snapshot")
+	snapshotCmd.Flags().StringVar(&snapshotLiveCommand, "live-command", "", "Shell command printing the live resources as YAML, e.g. 'kubectl get deploy,svc -n app -o yaml'")
+	snapshotCmd.Flags().StringVar(&snapshotOutput, "output", "", "File to write the snapshot to (default: standard output)")
+	snapshotCmd.Flags().BoolVar(&snapshotKeepStatus, "keep-status", false, "Keep the status of resources in the snapshot")
+	snapshotCmd.Flags().BoolVar(&snapshotDisableMaskingSecret, "disable-masking-secret", false, "Store Secret values instead of their hashes")
+	snapshotCmd.Flags().StringSliceVar(&snapshotExcludeKinds, "exclude-kinds", []string{}, "List of Kinds to exclude from the snapshot (Kind, Kind.group or group/Kind, e.g. Workflow.argoproj.io)")
+	snapshotCmd.Flags().StringSliceVar(&snapshotLabelSelectors, "label", []string{}, "Label selector to filter resources (e.g., 'app=nginx', 'app=payments-*', 'tier!=test'). Can be specified multiple times.")
+	addLiveClusterFlags(snapshotCmd)
+
+	// Gen-fixture command flags
+	genFixtureCmd.Flags().StringVar(&genFixtureSpecFile, "spec", "", "YAML file listing the mutations to apply (image|replicas|delete)")
+	genFixtureCmd.Flags().StringVar(&genFixtureOutput, "output", "", "File to write the mutated manifest to (default: standard output)")
+	genFixtureCmd.Flags().StringVar(&genFixtureWorkloadConfig, "workload-config", "", "YAML file describing the pod spec and replicas paths of custom workload kinds, as for diff")
+	_ = genFixtureCmd.MarkFlagRequired("spec")
+	testingCmd.AddCommand(genFixtureCmd)
+
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(parseCmd)
+	rootCmd.AddCommand(inventoryCmd)
+	rootCmd.AddCommand(matrixCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(commentCmd)
+	rootCmd.AddCommand(driftCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(testingCmd)
+	rootCmd.AddCommand(versionCmd)
+}
+
+// SetVersionInfo sets the build information printed by the version command
+func SetVersionInfo(v, c, d string) {
+	version = v
+	commit = c
+	date = d
+}
+
+// Execute runs the root command and returns the process exit code
+func Execute() int {
+	// shutdownTracing is resolved when Execute returns, as startTracing replaces it once the flags are parsed
+	defer func() { shutdownTracing() }()
+	if err := rootCmd.Execute(); err != nil {
+		if errors.Is(err, errChangesFound) {
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show [results-file]",
+	Short: "Render results saved with diff --save",
+	Long: `Render results saved with "diff --save" in any output format without recomputing the diff.
+Results can be narrowed down by kind, namespace and change type, or compared with
+another saved run using --compare to see which resources started or stopped differing.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(showOutputFormat); err != nil {
+			return err
+		}
+		if diffStyleFor(showOutputFormat) != diff.DiffStyleUnified {
+			return fmt.Errorf("%s output cannot be rendered from saved results; use diff --output-format %s", showOutputFormat, showOutputFormat)
+		}
+		if err := validateMaxResourcesInReport(showMaxResourcesInReport, showReportURL, showOutputFormat); err != nil {
+			return err
+		}
+		if err := validateGroupLinked(showGroupLinked, showOutputFormat); err != nil {
+			return err
+		}
+
+		results, err := loadResults(args[0])
+		if err != nil {
+			return err
+		}
+		if results, err = filterShownResults(results); err != nil {
+			return err
+		}
+
+		if showCompare != "" {
+			previous, err := loadResults(showCompare)
+			if err != nil {
+				return err
+			}
+			if previous, err = filterShownResults(previous); err != nil {
+				return err
+			}
+			comparison := diff.CompareRuns(previous, results)
+			fmt.Println(comparison.String())
+			if comparison.HasDifferences() {
+				return changesFound(cmd)
+			}
+			return nil
+		}
+
+		if !results.HasChanges() && !showShowUnchanged {
+			block, err := frontMatterBlock(results, showFrontMatter, showOutputFormat)
+			if err != nil {
+				return err
+			}
+			fmt.Print(block)
+			fmt.Println("No differences found")
+			return nil
+		}
+		output, err := renderResults(results, unchangedListing(renderOptions{
+			format:                showOutputFormat,
+			summary:               showSummary,
+			splitScope:            showSplitScope,
+			showUnchanged:         showShowUnchanged,
+			byOwner:               showGroupByOwner,
+			byChangeSet:           showGroupLinked,
+			allowPotentialSecrets: showAllowPotentialSecrets,
+			frontMatter:           showFrontMatter,
+			maxResources:          showMaxResourcesInReport,
+			reportURL:             showReportURL,
+		}, results))
+		if err != nil {
+			return err
+		}
+		fmt.Print(output)
+		if err := failedResourcesError(results); err != nil {
+			return err
+		}
+		if results.HasChanges() {
+			return changesFound(cmd)
+		}
+		return nil
+	},
+}
+
+// filterShownResults narrows results down using the show command filter flags
+func filterShownResults(results diff.Results) (diff.Results, error) {
+	return filterResults(results, resultFilter{kinds: showKinds, namespaces: showNamespaces, types: showTypes})
+}
+
+// resultFilter selects results by kind, namespace, name and change type.
+// Values of a single criterion are alternatives; empty criteria match everything.
+type resultFilter struct {
+	kinds      []string
+	namespaces []string
+	names      []string
+	types      []string
+}
+
+// diffResultFilter returns the filter given by the --filter-* flags of the diff command
+func diffResultFilter() resultFilter {
+	return resultFilter{
+		kinds:      filterKinds,
+		namespaces: filterNamespaces,
+		names:      filterNames,
+		types:      filterChangeTypes,
+	}
+}
+
+// filterResults returns the results matching every criterion of the filter. Workloads are only marked restart-only
+// if a ConfigMap or Secret change restarting them is among the returned results.
+func filterResults(results diff.Results, f resultFilter) (diff.Results, error) {
+	types := make(map[diff.ChangeType]bool, len(f.types))
+	for _, name := range f.types {
+		ct, err := diff.ParseChangeType(name)
+		if err != nil {
+			return nil, err
+		}
+		types[ct] = true
+	}
+
+	return results.Apply(func(key diff.ResourceKey, result diff.Result) bool {
+		if len(f.kinds) > 0 && !containsString(f.kinds, key.Kind) {
+			return false
+		}
+		if len(f.namespaces) > 0 && !containsString(f.namespaces, key.Namespace) {
+			return false
+		}
+		if len(f.names) > 0 && !containsString(f.names, key.Name) {
+			return false
+		}
+		if len(types) > 0 && !types[result.Type] {
+			return false
+		}
+		return true
+	}).RestartOnlyWithConfigs(), nil
+}
+
+// saveResults writes results as JSON to a file, together with the options they were computed with
+func saveResults(file string, results diff.Results, options *diff.EffectiveOptions) error {
+	f, err := os.OpenFile(filepath.Clean(file), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 - file path is a CLI argument and cleaned
+	if err != nil {
+		return fmt.Errorf("failed to create results file: %w", err)
+	}
+	if err := diff.WriteResultsWithOptions(f, results, options); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close results file: %w", err)
+	}
+	return nil
+}
+
+// loadResults reads results saved by saveResults
+func loadResults(file string) (diff.Results, error) {
+	if err := checkInputFile(filepath.Clean(file)); err != nil {
+		return nil, fmt.Errorf("failed to open results file: %w", err)
+	}
+	f, err := os.Open(filepath.Clean(file)) // #nosec G304 - file path is a CLI argument and cleaned
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file %s: %w", file, err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", file, err)
+		}
+	}()
+
+	results, err := diff.ReadResults(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results file %s: %w", file, err)
+	}
+	return results, nil
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
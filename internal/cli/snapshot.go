@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/options"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/snapshot"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Dump live resources to a normalized, masked YAML bundle",
+	Long: `Dump live resources to a normalized YAML bundle for before/after audits.
+Server-populated fields (uid, resourceVersion, managedFields, status, ...) are removed
+and Secret values are replaced with their HMAC-SHA256 keyed by $K8S_MANIFEST_DIFF_MASK_KEY.
+Compare two snapshots taken at different times with the same key with
+"k8s-manifest-diff diff before.yaml after.yaml".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := validateSelectorFlags(selectorFlag{name: "label", values: snapshotLabelSelectors, labels: true}); err != nil {
+			return err
+		}
+		snapshotOpts := &snapshot.Options{
+			KeepStatus:            snapshotKeepStatus,
+			DisableMaskingSecrets: snapshotDisableMaskingSecret,
+			HashKey:               []byte(os.Getenv(envMaskKey)),
+		}
+		if err := snapshotOpts.Validate(); err != nil {
+			return fmt.Errorf("%w: set %s or use --disable-masking-secret", err, envMaskKey)
+		}
+		source, err := liveSource(cmd, snapshotLiveFile, snapshotLiveCommand, nil)
+		if err != nil {
+			return err
+		}
+		objs, err := source(cmd.Context())
+		if err != nil {
+			return err
+		}
+		filterOption, err := options.FilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if err := filterOption.Validate(); err != nil {
+			return err
+		}
+		if objs, err = filter.Select(objs, filterOption); err != nil {
+			return err
+		}
+		resources, err := snapshot.Take(objs, snapshotOpts)
+		if err != nil {
+			return err
+		}
+
+		if snapshotOutput == "" {
+			return snapshot.Write(os.Stdout, resources, time.Now())
+		}
+		return writeSnapshotFile(snapshotOutput, func(w io.Writer) error {
+			return snapshot.Write(w, resources, time.Now())
+		})
+	},
+}
+
+// writeSnapshotFile creates file and writes the snapshot to it
+func writeSnapshotFile(file string, write func(io.Writer) error) error {
+	f, err := os.OpenFile(filepath.Clean(file), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 - file path is a CLI argument and cleaned
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %w", err)
+	}
+	return nil
+}
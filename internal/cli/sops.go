@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var (
+	// sopsMetadata matches the top-level sops key SOPS adds to encrypted YAML or JSON documents
+	sopsMetadata = regexp.MustCompile(`(?m)^(sops:|\s*"sops"\s*:)`)
+	// sopsCiphertext marks a value encrypted by SOPS
+	sopsCiphertext = []byte("ENC[AES256_GCM,")
+)
+
+// isSOPSEncrypted reports whether data looks like a manifest encrypted by SOPS
+func isSOPSEncrypted(data []byte) bool {
+	return bytes.Contains(data, sopsCiphertext) && sopsMetadata.Match(data)
+}
+
+// decryptSOPSInput decrypts a SOPS-encrypted manifest for --sops-decrypt. Manifests that are not encrypted are
+// returned as they are. If the sops CLI is missing or fails, a warning is printed and the encrypted manifest is
+// returned, whose resources are then compared by their SOPS metadata.
+func decryptSOPSInput(name string, data []byte) []byte {
+	if !sopsDecrypt || !isSOPSEncrypted(data) {
+		return data
+	}
+	decrypted, err := decryptSOPS(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: comparing %s encrypted: %v\n", name, err)
+		return data
+	}
+	return decrypted
+}
+
+// decryptSOPS decrypts data with the sops CLI, which reads the keys from its usual environment
+func decryptSOPS(data []byte) ([]byte, error) {
+	if _, err := exec.LookPath("sops"); err != nil {
+		return nil, fmt.Errorf("failed to decrypt: sops CLI not found in PATH (required for --sops-decrypt)")
+	}
+	format := "yaml"
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		format = "json"
+	}
+
+	// sops detects the input format by extension and cannot reliably read stdin, so decrypt a temporary copy
+	file, err := os.CreateTemp("", "k8s-manifest-diff-*."+format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("sops", "--decrypt", "--input-type", format, "--output-type", format, file.Name()) // #nosec G204 - the command is fixed and the file is a temporary copy
+	cmd.Stderr = &stderr
+	decrypted, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	stagedAgainstHead     = "head"
+	stagedAgainstWorktree = "worktree"
+)
+
+// manifestExtensions lists the file extensions treated as manifests in staged files and directory inputs
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// isManifestFile reports whether the file has a manifest extension
+func isManifestFile(file string) bool {
+	for _, ext := range manifestExtensions {
+		if strings.EqualFold(filepath.Ext(file), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadStagedObjects returns base and head objects for the given files using the git index.
+// When against is "head", base is the HEAD version and head is the staged version.
+// When against is "worktree", base is the staged version and head is the working tree version.
+// If no files are given, all staged manifest files are used.
+func loadStagedObjects(files []string, against string) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	if against != stagedAgainstHead && against != stagedAgainstWorktree {
+		return nil, nil, fmt.Errorf("invalid staged comparison target: %s (supported: %s, %s)", against, stagedAgainstHead, stagedAgainstWorktree)
+	}
+
+	if len(files) == 0 {
+		var err error
+		files, err = stagedManifestFiles()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var baseObjs, headObjs []*unstructured.Unstructured
+	for _, file := range files {
+		// Paths prefixed with ./ are resolved relative to the current directory by git
+		path := "./" + filepath.ToSlash(filepath.Clean(file))
+
+		var baseData, headData []byte
+		var err error
+		if against == stagedAgainstHead {
+			if baseData, err = gitShow("HEAD", path); err != nil {
+				return nil, nil, err
+			}
+			if headData, err = gitShow("", path); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			if baseData, err = gitShow("", path); err != nil {
+				return nil, nil, err
+			}
+			if headData, err = readWorktreeFile(file); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		objs, err := parseManifestData("base version of "+file, baseData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse base version of %s: %w", file, err)
+		}
+		baseObjs = append(baseObjs, objs...)
+
+		objs, err = parseManifestData("head version of "+file, headData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse head version of %s: %w", file, err)
+		}
+		headObjs = append(headObjs, objs...)
+	}
+	return baseObjs, headObjs, nil
+}
+
+// stagedManifestFiles lists staged manifest files relative to the current directory.
+// Renames are listed as a deleted and an added file, so that resources keep their base version.
+func stagedManifestFiles() ([]string, error) {
+	out, err := runGit("diff", "--cached", "--name-only", "--relative", "--no-renames", "--diff-filter=ACMD")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		if isManifestFile(line) {
+			files = append(files, filepath.FromSlash(line))
+		}
+	}
+	return files, nil
+}
+
+// gitShow returns the content of a file in a revision, or in the index if revision is empty, or nil if the file
+// does not exist there (e.g. newly added or deleted file). Other git failures, such as running outside a
+// repository, are returned as errors.
+func gitShow(revision, path string) ([]byte, error) {
+	exists, err := gitFileExists(revision, path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return runGit("show", revision+":"+path)
+}
+
+// gitFileExists reports whether a file exists in a revision, or in the index if revision is empty. A revision
+// that does not exist yet, such as HEAD before the first commit, holds no files.
+func gitFileExists(revision, path string) (bool, error) {
+	var out []byte
+	var err error
+	if revision == "" {
+		out, err = runGit("ls-files", "--cached", "--", path)
+	} else {
+		if _, err := runGit("rev-parse", "--verify", "--quiet", revision+"^{commit}"); err != nil {
+			// rev-parse --verify --quiet exits with 1 only if the revision does not exist
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+				return false, nil
+			}
+			return false, err
+		}
+		out, err = runGit("ls-tree", "--name-only", revision, "--", path)
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(bytes.TrimSpace(out)) > 0, nil
+}
+
+// readWorktreeFile reads a file from the working tree, returning nil if it has been removed
+func readWorktreeFile(file string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Clean(file)) // #nosec G304 - file paths are CLI arguments and cleaned
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", file, err)
+	}
+	return data, nil
+}
+
+// runGit runs a git command and returns its standard output
+func runGit(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...) // #nosec G204 - arguments are fixed git subcommands and file paths
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
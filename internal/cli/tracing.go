@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// envOTLPEndpoint is the standard OpenTelemetry variable read as the default of --otlp-endpoint
+const envOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// otlpEndpoint is the OTLP/HTTP collector URL spans are exported to; tracing is disabled when empty
+var otlpEndpoint string
+
+// tracer records spans of the CLI commands; it resolves the global provider lazily, so spans are
+// exported once startTracing registered one
+var tracer = otel.Tracer("github.com/toyamagu-2021/k8s-manifest-diff/internal/cli")
+
+// shutdownTracing flushes and stops the tracer provider registered by startTracing
+var shutdownTracing = func() {}
+
+// startTracing registers a tracer provider exporting spans to otlpEndpoint, if set.
+// Spans are exported in batches, which shutdownTracing flushes when Execute returns.
+func startTracing() error {
+	if otlpEndpoint == "" {
+		return nil
+	}
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(otlpEndpoint))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	shutdownTracing = func() {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to shut down tracing: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// endSpan marks span as failed if err is not nil and ends it; finding changes is not a failure
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, errChangesFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,162 @@
+// Package analyzer inspects base and head manifests for problems that a textual diff does not reveal.
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Check is the name of an analysis that can be run on manifests
+type Check string
+
+const (
+	// CheckQuota estimates request changes per namespace and compares them with ResourceQuotas
+	CheckQuota Check = "quota"
+	// CheckConsistency compares workload replicas with PodDisruptionBudgets and HorizontalPodAutoscalers
+	CheckConsistency Check = "consistency"
+)
+
+// allChecks lists the supported checks in the order they run
+var allChecks = []Check{CheckQuota, CheckConsistency}
+
+// ParseCheck parses a check name
+func ParseCheck(s string) (Check, error) {
+	for _, check := range allChecks {
+		if string(check) == s {
+			return check, nil
+		}
+	}
+	names := make([]string, len(allChecks))
+	for i, check := range allChecks {
+		names[i] = string(check)
+	}
+	return "", fmt.Errorf("unknown check: %s (supported: %s)", s, strings.Join(names, ", "))
+}
+
+// Warning is a problem found by a check
+type Warning struct {
+	Check    Check  // Check that found the problem
+	Resource string // Resource the problem is about, e.g. "ResourceQuota/team-a/compute"
+	Message  string // Description of the problem
+}
+
+// String returns the warning as "[check] resource: message"
+func (w Warning) String() string {
+	return fmt.Sprintf("[%s] %s: %s", w.Check, w.Resource, w.Message)
+}
+
+// Report holds the findings of the checks that were run
+type Report struct {
+	QuotaImpacts []QuotaImpact // Request changes per namespace (CheckQuota)
+	Warnings     []Warning     // Problems found by all checks
+}
+
+// Run runs the checks on base and head manifests
+func Run(base, head []*unstructured.Unstructured, checks []Check) *Report {
+	return RunWithWorkloads(base, head, checks, nil)
+}
+
+// RunWithWorkloads runs the checks on base and head manifests, analyzing the workload kinds in workloads,
+// e.g. read by workload.Read to cover Argo Rollouts. A nil policy analyzes the built-in kinds.
+func RunWithWorkloads(base, head []*unstructured.Unstructured, checks []Check, workloads *workload.Policy) *Report {
+	report := &Report{}
+	for _, check := range checks {
+		switch check {
+		case CheckQuota:
+			impacts, warnings := analyzeQuota(base, head, workloads)
+			report.QuotaImpacts = impacts
+			report.Warnings = append(report.Warnings, warnings...)
+		case CheckConsistency:
+			report.Warnings = append(report.Warnings, analyzeConsistency(base, head, workloads)...)
+		}
+	}
+	sort.SliceStable(report.Warnings, func(i, j int) bool {
+		if report.Warnings[i].Check != report.Warnings[j].Check {
+			return report.Warnings[i].Check < report.Warnings[j].Check
+		}
+		return report.Warnings[i].Resource < report.Warnings[j].Resource
+	})
+	return report
+}
+
+// IsEmpty returns true if the report has nothing to show
+func (r *Report) IsEmpty() bool {
+	return len(r.QuotaImpacts) == 0 && len(r.Warnings) == 0
+}
+
+// String returns the report as comment lines, matching the summary header of the diff output
+func (r *Report) String() string {
+	var result strings.Builder
+	if len(r.QuotaImpacts) > 0 {
+		result.WriteString(fmt.Sprintf("# Quota impact (%d namespaces):\n", len(r.QuotaImpacts)))
+		for _, impact := range r.QuotaImpacts {
+			result.WriteString(fmt.Sprintf("#   %s\n", impact))
+		}
+	}
+	for _, check := range allChecks {
+		if warnings := r.warningsOf(check); len(warnings) > 0 {
+			result.WriteString(fmt.Sprintf("# %s warnings (%d):\n", check.title(), len(warnings)))
+			for _, warning := range warnings {
+				result.WriteString(fmt.Sprintf("#   %s: %s\n", warning.Resource, warning.Message))
+			}
+		}
+	}
+	return result.String()
+}
+
+// Markdown returns the report as Markdown sections
+func (r *Report) Markdown() string {
+	var result strings.Builder
+	if len(r.QuotaImpacts) > 0 {
+		result.WriteString("## Quota Impact\n\n")
+		result.WriteString("| Namespace | CPU requests | Memory requests |\n")
+		result.WriteString("|---|---|---|\n")
+		for _, impact := range r.QuotaImpacts {
+			result.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", impact.Namespace,
+				formatChange(impact.Base.CPUMilli, impact.Head.CPUMilli, formatCPU),
+				formatChange(impact.Base.MemoryBytes, impact.Head.MemoryBytes, formatMemory)))
+		}
+		result.WriteString("\n")
+	}
+	for _, check := range allChecks {
+		if warnings := r.warningsOf(check); len(warnings) > 0 {
+			result.WriteString(fmt.Sprintf("## %s Warnings (%d)\n\n", check.title(), len(warnings)))
+			for _, warning := range warnings {
+				result.WriteString(fmt.Sprintf("- `%s`: %s\n", warning.Resource, warning.Message))
+			}
+			result.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// warningsOf returns the warnings found by a check
+func (r *Report) warningsOf(check Check) []Warning {
+	var warnings []Warning
+	for _, warning := range r.Warnings {
+		if warning.Check == check {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// title returns the check name capitalized for section headings
+func (c Check) title() string {
+	if c == "" {
+		return ""
+	}
+	return strings.ToUpper(string(c[:1])) + string(c[1:])
+}
+
+// resourceName returns "Kind/namespace/name", or "Kind/name" for resources without a namespace
+func resourceName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() != "" {
+		return fmt.Sprintf("%s/%s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+}
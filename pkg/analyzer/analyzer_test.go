@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+)
+
+func TestParseCheck(t *testing.T) {
+	check, err := ParseCheck("quota")
+	assert.NoError(t, err)
+	assert.Equal(t, CheckQuota, check)
+
+	_, err = ParseCheck("unknown")
+	assert.ErrorContains(t, err, "unknown check: unknown (supported: quota, consistency)")
+}
+
+func TestRun(t *testing.T) {
+	head := strings.Replace(quotaBase, "replicas: 2", "replicas: 3", 1)
+
+	t.Run("no checks", func(t *testing.T) {
+		report := Run(parseManifests(t, quotaBase), parseManifests(t, head), nil)
+		assert.True(t, report.IsEmpty())
+		assert.Equal(t, "", report.String())
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		report := Run(parseManifests(t, quotaBase), parseManifests(t, head), []Check{CheckQuota})
+		assert.False(t, report.IsEmpty())
+		assert.Equal(t, "# Quota impact (1 namespaces):\n"+
+			"#   team-a: cpu 2 -> 3 (+1), memory 768Mi -> 1152Mi (+384Mi)\n"+
+			"# Quota warnings (2):\n"+
+			"#   ResourceQuota/team-a/compute: requested cpu 3 exceeds requests.cpu 2\n"+
+			"#   ResourceQuota/team-a/compute: requested memory 1152Mi exceeds requests.memory 1Gi\n",
+			report.String())
+
+		markdown := report.Markdown()
+		assert.Contains(t, markdown, "## Quota Impact\n\n| Namespace | CPU requests | Memory requests |\n|---|---|---|\n| `team-a` | 2 -> 3 (+1) | 768Mi -> 1152Mi (+384Mi) |\n")
+		assert.Contains(t, markdown, "## Quota Warnings (2)\n\n- `ResourceQuota/team-a/compute`: requested cpu 3 exceeds requests.cpu 2\n")
+	})
+}
+
+func TestRunWithWorkloads(t *testing.T) {
+	rollout := strings.Replace(strings.Replace(consistencyBase, "apiVersion: apps/v1\nkind: Deployment", "apiVersion: argoproj.io/v1alpha1\nkind: Rollout", 1),
+		"        app: web\n", "        app: web\n    spec:\n      containers:\n      - name: web\n        resources:\n          requests:\n            cpu: 100m\n", 1)
+	head := strings.Replace(rollout, "replicas: 3", "replicas: 2", 1)
+	checks := []Check{CheckQuota, CheckConsistency}
+
+	report := Run(parseManifests(t, rollout), parseManifests(t, head), checks)
+	assert.True(t, report.IsEmpty(), "Rollouts are not built-in workloads")
+
+	policy, err := workload.Read(strings.NewReader("workloads:\n- group: argoproj.io\n  kind: Rollout\n  podSpec: spec.template.spec\n  replicas: spec.replicas\n  scalable: true\n"))
+	require.NoError(t, err)
+	report = RunWithWorkloads(parseManifests(t, rollout), parseManifests(t, head), checks, policy)
+	assert.Equal(t, "# Quota impact (1 namespaces):\n"+
+		"#   default: cpu 300m -> 200m (-100m), memory 0 -> 0 (+0)\n"+
+		"# Consistency warnings (1):\n"+
+		"#   Rollout/default/web: replicas 2 equals minAvailable 2 of PodDisruptionBudget/default/web; voluntary disruptions such as node drains will be blocked\n",
+		report.String())
+}
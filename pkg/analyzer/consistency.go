@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AnalyzeConsistency warns when the replicas of a workload conflict with a PodDisruptionBudget or
+// HorizontalPodAutoscaler in head, e.g. replicas dropped below minAvailable or outside the HPA range.
+// Only conflicts that do not already exist in base are reported. Workloads are the scalable built-in kinds,
+// Deployments, StatefulSets and ReplicaSets.
+func AnalyzeConsistency(base, head []*unstructured.Unstructured) []Warning {
+	return analyzeConsistency(base, head, nil)
+}
+
+// analyzeConsistency checks the scalable kinds of workloads, see AnalyzeConsistency
+func analyzeConsistency(base, head []*unstructured.Unstructured, workloads *workload.Policy) []Warning {
+	existing := make(map[Warning]bool)
+	for _, warning := range consistencyWarnings(base, workloads) {
+		existing[warning] = true
+	}
+
+	var warnings []Warning
+	for _, warning := range consistencyWarnings(head, workloads) {
+		if !existing[warning] {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// consistencyWarnings returns the conflicts between workloads, PDBs and HPAs in a set of manifests
+func consistencyWarnings(objs []*unstructured.Unstructured, kinds *workload.Policy) []Warning {
+	var workloads []scalableWorkload
+	var pdbs, hpas []*unstructured.Unstructured
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		kind, isWorkload := kinds.Lookup(gvk.Group, gvk.Kind)
+		switch {
+		case isWorkload && kind.Scalable:
+			workloads = append(workloads, scalableWorkload{obj: obj, kind: kind})
+		case obj.GetKind() == "PodDisruptionBudget":
+			pdbs = append(pdbs, obj)
+		case obj.GetKind() == "HorizontalPodAutoscaler":
+			hpas = append(hpas, obj)
+		}
+	}
+
+	var warnings []Warning
+	for _, scalable := range workloads {
+		workload := scalable.obj
+		replicas, hasReplicas := nestedInt(workload.Object, scalable.kind.ReplicasPath()...)
+		hpa := targetingHPA(workload, hpas)
+
+		minReplicas := replicas
+		if hpa != nil {
+			hpaMin, ok := nestedInt(hpa.Object, "spec", "minReplicas")
+			if !ok {
+				hpaMin = 1
+			}
+			hpaMax, _ := nestedInt(hpa.Object, "spec", "maxReplicas")
+			if hasReplicas && (replicas < hpaMin || replicas > hpaMax) {
+				warnings = append(warnings, consistencyWarning(workload,
+					fmt.Sprintf("replicas %d is outside the range %d-%d of %s; the autoscaler will override it", replicas, hpaMin, hpaMax, resourceName(hpa))))
+			}
+			minReplicas = hpaMin
+		} else if !hasReplicas {
+			minReplicas = 1
+		}
+
+		for _, pdb := range pdbs {
+			if !selectsWorkload(pdb, workload, scalable.kind.TemplateLabelsPath()) {
+				continue
+			}
+			warnings = append(warnings, pdbWarnings(workload, pdb, minReplicas, hpa != nil)...)
+		}
+	}
+	return warnings
+}
+
+// scalableWorkload is a workload of a scalable kind
+type scalableWorkload struct {
+	obj  *unstructured.Unstructured
+	kind workload.Kind
+}
+
+// pdbWarnings checks the minimum replicas of a workload against a PodDisruptionBudget selecting it
+func pdbWarnings(workload, pdb *unstructured.Unstructured, minReplicas int64, autoscaled bool) []Warning {
+	subject := fmt.Sprintf("replicas %d", minReplicas)
+	if autoscaled {
+		subject = fmt.Sprintf("autoscaler minReplicas %d", minReplicas)
+	}
+
+	if minAvailable, ok := nestedInt(pdb.Object, "spec", "minAvailable"); ok {
+		switch {
+		case minReplicas < minAvailable:
+			return []Warning{consistencyWarning(workload,
+				fmt.Sprintf("%s is below minAvailable %d of %s", subject, minAvailable, resourceName(pdb)))}
+		case minReplicas == minAvailable:
+			return []Warning{consistencyWarning(workload,
+				fmt.Sprintf("%s equals minAvailable %d of %s; voluntary disruptions such as node drains will be blocked", subject, minAvailable, resourceName(pdb)))}
+		}
+		return nil
+	}
+
+	minAvailable, _, _ := unstructured.NestedString(pdb.Object, "spec", "minAvailable")
+	maxUnavailable, isInt := nestedInt(pdb.Object, "spec", "maxUnavailable")
+	maxUnavailableString, _, _ := unstructured.NestedString(pdb.Object, "spec", "maxUnavailable")
+	if minAvailable == "100%" || (isInt && maxUnavailable == 0) || maxUnavailableString == "0%" {
+		return []Warning{consistencyWarning(workload,
+			fmt.Sprintf("%s allows no voluntary disruptions; node drains will be blocked", resourceName(pdb)))}
+	}
+	return nil
+}
+
+// targetingHPA returns the HorizontalPodAutoscaler scaling the workload, or nil
+func targetingHPA(workload *unstructured.Unstructured, hpas []*unstructured.Unstructured) *unstructured.Unstructured {
+	for _, hpa := range hpas {
+		kind, _, _ := unstructured.NestedString(hpa.Object, "spec", "scaleTargetRef", "kind")
+		name, _, _ := unstructured.NestedString(hpa.Object, "spec", "scaleTargetRef", "name")
+		if hpa.GetNamespace() == workload.GetNamespace() && kind == workload.GetKind() && name == workload.GetName() {
+			return hpa
+		}
+	}
+	return nil
+}
+
+// selectsWorkload reports whether the PDB selector matches the pod template labels of the workload.
+// Only matchLabels is evaluated; selectors using matchExpressions are not matched.
+func selectsWorkload(pdb, workload *unstructured.Unstructured, labelsPath []string) bool {
+	if pdb.GetNamespace() != workload.GetNamespace() {
+		return false
+	}
+	if _, hasExpressions, _ := unstructured.NestedFieldNoCopy(pdb.Object, "spec", "selector", "matchExpressions"); hasExpressions {
+		return false
+	}
+	selector, _, _ := unstructured.NestedMap(pdb.Object, "spec", "selector", "matchLabels")
+	if len(selector) == 0 {
+		return false
+	}
+	if labelsPath == nil {
+		return false
+	}
+	labels, _, _ := unstructured.NestedMap(workload.Object, labelsPath...)
+	for key, value := range selector {
+		if fmt.Sprint(labels[key]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// consistencyWarning returns a warning about a workload from the consistency check
+func consistencyWarning(workload *unstructured.Unstructured, message string) Warning {
+	return Warning{Check: CheckConsistency, Resource: resourceName(workload), Message: message}
+}
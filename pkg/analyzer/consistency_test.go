@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const consistencyBase = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+  namespace: default
+spec:
+  minAvailable: 2
+  selector:
+    matchLabels:
+      app: web
+`
+
+const consistencyHPA = `
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: default
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 2
+  maxReplicas: 5
+`
+
+func TestAnalyzeConsistency(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		head     string
+		expected []Warning
+	}{
+		{
+			name:     "no conflicts",
+			base:     consistencyBase,
+			head:     consistencyBase,
+			expected: nil,
+		},
+		{
+			name: "replicas dropped below minAvailable",
+			base: consistencyBase,
+			head: strings.Replace(consistencyBase, "replicas: 3", "replicas: 1", 1),
+			expected: []Warning{{Check: CheckConsistency, Resource: "Deployment/default/web",
+				Message: "replicas 1 is below minAvailable 2 of PodDisruptionBudget/default/web"}},
+		},
+		{
+			name: "replicas equal minAvailable",
+			base: consistencyBase,
+			head: strings.Replace(consistencyBase, "replicas: 3", "replicas: 2", 1),
+			expected: []Warning{{Check: CheckConsistency, Resource: "Deployment/default/web",
+				Message: "replicas 2 equals minAvailable 2 of PodDisruptionBudget/default/web; voluntary disruptions such as node drains will be blocked"}},
+		},
+		{
+			name: "existing conflicts are not reported",
+			base: strings.Replace(consistencyBase, "replicas: 3", "replicas: 1", 1),
+			head: strings.Replace(consistencyBase, "replicas: 3", "replicas: 1", 1),
+		},
+		{
+			name: "replicas outside HPA range",
+			base: consistencyBase + consistencyHPA,
+			head: strings.Replace(consistencyBase, "replicas: 3", "replicas: 8", 1) + consistencyHPA,
+			expected: []Warning{{Check: CheckConsistency, Resource: "Deployment/default/web",
+				Message: "replicas 8 is outside the range 2-5 of HorizontalPodAutoscaler/default/web; the autoscaler will override it"}},
+		},
+		{
+			name: "HPA minReplicas checked against PDB",
+			base: consistencyBase + consistencyHPA,
+			head: strings.Replace(consistencyBase, "minAvailable: 2", "minAvailable: 3", 1) + consistencyHPA,
+			expected: []Warning{{Check: CheckConsistency, Resource: "Deployment/default/web",
+				Message: "autoscaler minReplicas 2 is below minAvailable 3 of PodDisruptionBudget/default/web"}},
+		},
+		{
+			name: "PDB allowing no disruptions",
+			base: consistencyBase,
+			head: strings.Replace(consistencyBase, "minAvailable: 2", "maxUnavailable: 0", 1),
+			expected: []Warning{{Check: CheckConsistency, Resource: "Deployment/default/web",
+				Message: "PodDisruptionBudget/default/web allows no voluntary disruptions; node drains will be blocked"}},
+		},
+		{
+			name: "PDB in another namespace",
+			base: consistencyBase,
+			head: strings.Replace(strings.Replace(consistencyBase, "replicas: 3", "replicas: 1", 1), "name: web\n  namespace: default\nspec:\n  minAvailable", "name: web\n  namespace: other\nspec:\n  minAvailable", 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AnalyzeConsistency(parseManifests(t, tt.base), parseManifests(t, tt.head)))
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceRequests is an amount of requested CPU and memory
+type ResourceRequests struct {
+	CPUMilli    int64 // CPU in millicores
+	MemoryBytes int64 // Memory in bytes
+}
+
+// QuotaImpact describes how the requests of workloads in a namespace change
+type QuotaImpact struct {
+	Namespace string
+	Base      ResourceRequests
+	Head      ResourceRequests
+}
+
+// String returns the impact as "namespace: cpu 1 -> 1500m (+500m), memory 1Gi -> 1Gi (+0)"
+func (q QuotaImpact) String() string {
+	return fmt.Sprintf("%s: cpu %s, memory %s", q.Namespace,
+		formatChange(q.Base.CPUMilli, q.Head.CPUMilli, formatCPU),
+		formatChange(q.Base.MemoryBytes, q.Head.MemoryBytes, formatMemory))
+}
+
+// AnalyzeQuota estimates the CPU and memory requested by workloads in each namespace of base and head,
+// and warns if head requests exceed a ResourceQuota in head. Containers without requests get the
+// default request of a LimitRange in the same namespace. Only namespaces whose requests change are reported.
+// Workloads are the built-in kinds, see workload.Builtin.
+func AnalyzeQuota(base, head []*unstructured.Unstructured) ([]QuotaImpact, []Warning) {
+	return analyzeQuota(base, head, nil)
+}
+
+// analyzeQuota estimates the requests of the workload kinds in workloads, see AnalyzeQuota
+func analyzeQuota(base, head []*unstructured.Unstructured, workloads *workload.Policy) ([]QuotaImpact, []Warning) {
+	baseRequests := namespaceRequests(base, workloads)
+	headRequests := namespaceRequests(head, workloads)
+
+	namespaces := make(map[string]bool)
+	for namespace := range baseRequests {
+		namespaces[namespace] = true
+	}
+	for namespace := range headRequests {
+		namespaces[namespace] = true
+	}
+
+	var impacts []QuotaImpact
+	for namespace := range namespaces {
+		if baseRequests[namespace] != headRequests[namespace] {
+			impacts = append(impacts, QuotaImpact{Namespace: namespace, Base: baseRequests[namespace], Head: headRequests[namespace]})
+		}
+	}
+	sort.Slice(impacts, func(i, j int) bool { return impacts[i].Namespace < impacts[j].Namespace })
+
+	var warnings []Warning
+	for _, obj := range head {
+		if obj.GetKind() != "ResourceQuota" {
+			continue
+		}
+		hard := quantityMap(obj.Object, "spec", "hard")
+		requests := headRequests[obj.GetNamespace()]
+		for _, name := range []string{"requests.cpu", "cpu"} {
+			if limit, ok := parseQuantity(hard[name]); ok && requests.CPUMilli > limit.MilliValue() {
+				warnings = append(warnings, Warning{Check: CheckQuota, Resource: resourceName(obj),
+					Message: fmt.Sprintf("requested cpu %s exceeds %s %s", formatCPU(requests.CPUMilli), name, hard[name])})
+			}
+		}
+		for _, name := range []string{"requests.memory", "memory"} {
+			if limit, ok := parseQuantity(hard[name]); ok && requests.MemoryBytes > limit.Value() {
+				warnings = append(warnings, Warning{Check: CheckQuota, Resource: resourceName(obj),
+					Message: fmt.Sprintf("requested memory %s exceeds %s %s", formatMemory(requests.MemoryBytes), name, hard[name])})
+			}
+		}
+	}
+	return impacts, warnings
+}
+
+// NamespaceRequests returns the total CPU and memory requested by workloads in each namespace
+func NamespaceRequests(objs []*unstructured.Unstructured) map[string]ResourceRequests {
+	return namespaceRequests(objs, nil)
+}
+
+// namespaceRequests returns the requests of the workload kinds in workloads by namespace.
+// Kinds with a pod template but no replica count, such as DaemonSets and CronJobs, are not counted
+// as their number of pods depends on the cluster and schedule.
+func namespaceRequests(objs []*unstructured.Unstructured, workloads *workload.Policy) map[string]ResourceRequests {
+	defaults := limitRangeDefaults(objs)
+
+	totals := make(map[string]ResourceRequests)
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		kind, ok := workloads.Lookup(gvk.Group, gvk.Kind)
+		if !ok || (kind.ReplicasPath() == nil && kind.TemplateLabelsPath() != nil) {
+			continue
+		}
+		podSpec, found, _ := unstructured.NestedMap(obj.Object, kind.PodSpecPath()...)
+		if !found {
+			continue
+		}
+		replicas := int64(1)
+		if path := kind.ReplicasPath(); path != nil {
+			if value, ok := nestedInt(obj.Object, path...); ok {
+				replicas = value
+			}
+		}
+
+		pod := podRequests(podSpec, defaults[obj.GetNamespace()])
+		total := totals[obj.GetNamespace()]
+		total.CPUMilli += pod.CPUMilli * replicas
+		total.MemoryBytes += pod.MemoryBytes * replicas
+		totals[obj.GetNamespace()] = total
+	}
+	return totals
+}
+
+// podRequests returns the effective requests of a pod: the larger of the sum of its containers
+// and the largest init container, as the scheduler computes them
+func podRequests(podSpec map[string]any, defaults map[string]string) ResourceRequests {
+	var sum, initMax ResourceRequests
+	containers, _, _ := unstructured.NestedSlice(podSpec, "containers")
+	for _, container := range containers {
+		requests := containerRequests(container, defaults)
+		sum.CPUMilli += requests.CPUMilli
+		sum.MemoryBytes += requests.MemoryBytes
+	}
+	initContainers, _, _ := unstructured.NestedSlice(podSpec, "initContainers")
+	for _, container := range initContainers {
+		requests := containerRequests(container, defaults)
+		initMax.CPUMilli = max(initMax.CPUMilli, requests.CPUMilli)
+		initMax.MemoryBytes = max(initMax.MemoryBytes, requests.MemoryBytes)
+	}
+	return ResourceRequests{CPUMilli: max(sum.CPUMilli, initMax.CPUMilli), MemoryBytes: max(sum.MemoryBytes, initMax.MemoryBytes)}
+}
+
+// containerRequests returns the requests of a container, falling back to the LimitRange defaults
+func containerRequests(container any, defaults map[string]string) ResourceRequests {
+	var requests map[string]string
+	if c, ok := container.(map[string]any); ok {
+		requests = quantityMap(c, "resources", "requests")
+	}
+	lookup := func(name string) string {
+		if value, ok := requests[name]; ok {
+			return value
+		}
+		return defaults[name]
+	}
+
+	var result ResourceRequests
+	if cpu, ok := parseQuantity(lookup("cpu")); ok {
+		result.CPUMilli = cpu.MilliValue()
+	}
+	if memory, ok := parseQuantity(lookup("memory")); ok {
+		result.MemoryBytes = memory.Value()
+	}
+	return result
+}
+
+// limitRangeDefaults returns the default container requests of LimitRanges by namespace.
+// defaultRequest is used, or default (the limit) when no defaultRequest is set, as the API server does.
+func limitRangeDefaults(objs []*unstructured.Unstructured) map[string]map[string]string {
+	defaults := make(map[string]map[string]string)
+	for _, obj := range objs {
+		if obj.GetKind() != "LimitRange" {
+			continue
+		}
+		limits, _, _ := unstructured.NestedSlice(obj.Object, "spec", "limits")
+		for _, limit := range limits {
+			item, ok := limit.(map[string]any)
+			if !ok || item["type"] != "Container" {
+				continue
+			}
+			namespaceDefaults := defaults[obj.GetNamespace()]
+			if namespaceDefaults == nil {
+				namespaceDefaults = make(map[string]string)
+				defaults[obj.GetNamespace()] = namespaceDefaults
+			}
+			defaultLimits := quantityMap(item, "default")
+			defaultRequests := quantityMap(item, "defaultRequest")
+			for _, name := range []string{"cpu", "memory"} {
+				if value, ok := defaultRequests[name]; ok {
+					namespaceDefaults[name] = value
+				} else if value, ok := defaultLimits[name]; ok {
+					namespaceDefaults[name] = value
+				}
+			}
+		}
+	}
+	return defaults
+}
+
+// parseQuantity parses a resource quantity, returning false if it is empty or malformed
+func parseQuantity(s string) (resource.Quantity, bool) {
+	if s == "" {
+		return resource.Quantity{}, false
+	}
+	quantity, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}, false
+	}
+	return quantity, true
+}
+
+// quantityMap returns a map of resource quantities as strings, accepting quantities written as YAML numbers
+func quantityMap(obj map[string]any, fields ...string) map[string]string {
+	values, _, _ := unstructured.NestedMap(obj, fields...)
+	quantities := make(map[string]string, len(values))
+	for name, value := range values {
+		switch v := value.(type) {
+		case string:
+			quantities[name] = v
+		case int64, int, float64:
+			quantities[name] = fmt.Sprint(v)
+		}
+	}
+	return quantities
+}
+
+// nestedInt returns an integer field regardless of how the YAML decoder typed the number
+func nestedInt(obj map[string]any, fields ...string) (int64, bool) {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	if !found {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// formatCPU formats millicores as a Kubernetes quantity
+func formatCPU(milli int64) string {
+	if milli%1000 == 0 {
+		return fmt.Sprintf("%d", milli/1000)
+	}
+	return fmt.Sprintf("%dm", milli)
+}
+
+// formatMemory formats bytes as a Kubernetes quantity using the largest exact binary suffix
+func formatMemory(bytes int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10}} {
+		if bytes != 0 && bytes%unit.size == 0 {
+			return fmt.Sprintf("%d%s", bytes/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d", bytes)
+}
+
+// formatChange formats a change as "before -> after (+delta)"
+func formatChange(before, after int64, format func(int64) string) string {
+	delta := after - before
+	sign := "+"
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	return fmt.Sprintf("%s -> %s (%s%s)", format(before), format(after), sign, format(delta))
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func parseManifests(t *testing.T, manifests string) []*unstructured.Unstructured {
+	t.Helper()
+	objs, err := parser.ParseYAML(strings.NewReader(manifests))
+	require.NoError(t, err)
+	return objs
+}
+
+const quotaBase = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  replicas: 2
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        resources:
+          requests:
+            cpu: "1"
+      containers:
+      - name: web
+        resources:
+          requests:
+            cpu: 250m
+            memory: 256Mi
+      - name: sidecar
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: defaults
+  namespace: team-a
+spec:
+  limits:
+  - type: Container
+    defaultRequest:
+      cpu: 100m
+    default:
+      memory: 128Mi
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: compute
+  namespace: team-a
+spec:
+  hard:
+    requests.cpu: "2"
+    requests.memory: 1Gi
+    pods: 10
+`
+
+func TestNamespaceRequests(t *testing.T) {
+	requests := NamespaceRequests(parseManifests(t, quotaBase))
+
+	// Per pod: containers 250m + 100m (LimitRange default) = 350m < init container 1 CPU,
+	// memory 256Mi + 128Mi (LimitRange default limit) = 384Mi. Two replicas.
+	assert.Equal(t, map[string]ResourceRequests{
+		"team-a": {CPUMilli: 2000, MemoryBytes: 768 << 20},
+	}, requests)
+}
+
+func TestAnalyzeQuota(t *testing.T) {
+	t.Run("scale up exceeds quota", func(t *testing.T) {
+		head := strings.Replace(quotaBase, "replicas: 2", "replicas: 3", 1)
+
+		impacts, warnings := AnalyzeQuota(parseManifests(t, quotaBase), parseManifests(t, head))
+		require.Len(t, impacts, 1)
+		assert.Equal(t, "team-a: cpu 2 -> 3 (+1), memory 768Mi -> 1152Mi (+384Mi)", impacts[0].String())
+		assert.Equal(t, []Warning{
+			{Check: CheckQuota, Resource: "ResourceQuota/team-a/compute", Message: "requested cpu 3 exceeds requests.cpu 2"},
+			{Check: CheckQuota, Resource: "ResourceQuota/team-a/compute", Message: "requested memory 1152Mi exceeds requests.memory 1Gi"},
+		}, warnings)
+	})
+
+	t.Run("scale down within quota", func(t *testing.T) {
+		head := strings.Replace(quotaBase, "replicas: 2", "replicas: 1", 1)
+
+		impacts, warnings := AnalyzeQuota(parseManifests(t, quotaBase), parseManifests(t, head))
+		require.Len(t, impacts, 1)
+		assert.Equal(t, "team-a: cpu 2 -> 1 (-1), memory 768Mi -> 384Mi (-384Mi)", impacts[0].String())
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("unchanged requests are not reported", func(t *testing.T) {
+		impacts, warnings := AnalyzeQuota(parseManifests(t, quotaBase), parseManifests(t, quotaBase))
+		assert.Empty(t, impacts)
+		assert.Empty(t, warnings)
+	})
+}
@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Anchor returns a stable HTML anchor for the resource, e.g. "deployment-default-app-1a2b3c4d", with which
+// Markdown reports link to its diff. The readable part is followed by a hash of the full key, so that keys
+// with the same readable part, such as the same kind in different groups, get different anchors.
+func (k ResourceKey) Anchor() string {
+	var readable strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(strings.Join([]string{k.Kind, k.Namespace, k.Name}, "-")) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			readable.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			readable.WriteByte('-')
+			lastDash = true
+		}
+	}
+	if !lastDash {
+		readable.WriteByte('-')
+	}
+	sum := sha256.Sum256([]byte(k.String()))
+	return readable.String() + hex.EncodeToString(sum[:4])
+}
+
+// writeContentsMarkdown writes a table of contents linking to the diff of every resource shown by
+// writeDiffBodiesMarkdown, in key order
+func (dr Results) writeContentsMarkdown(result *strings.Builder) {
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+	var entries []string
+	for _, key := range keys {
+		if dr[key].Diff != "" && !dr[key].Trivial {
+			entries = append(entries, fmt.Sprintf("- [%s](#%s)\n", markdownCode(formatResourceKeyShort(key)), key.Anchor()))
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+	result.WriteString("## Contents\n\n")
+	for _, entry := range entries {
+		result.WriteString(entry)
+	}
+	result.WriteString("\n")
+}
@@ -0,0 +1,30 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceKey_Anchor(t *testing.T) {
+	key := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web.app"}
+	assert.Regexp(t, `^deployment-default-web-app-[0-9a-f]{8}$`, key.Anchor())
+	assert.Equal(t, key.Anchor(), key.Anchor(), "anchors are stable")
+
+	other := ResourceKey{Group: "example.com", Kind: "Deployment", Namespace: "default", Name: "web.app"}
+	assert.NotEqual(t, key.Anchor(), other.Anchor(), "keys differing in group get different anchors")
+	assert.Regexp(t, `^namespace-shared-[0-9a-f]{8}$`, ResourceKey{Kind: "Namespace", Name: "shared"}.Anchor())
+}
+
+func TestResults_StringDiffMarkdownContents(t *testing.T) {
+	key := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "app"}
+	results := Results{
+		key: {Type: Changed, Diff: "===== apps/Deployment default/app ======\n-a\n+b\n"},
+		{Kind: "ConfigMap", Namespace: "default", Name: "trivial"}: {Type: Changed, Diff: "===== /ConfigMap default/trivial ======\n-a\n+b\n", Trivial: true},
+	}
+
+	output := results.StringDiffMarkdown()
+	assert.Contains(t, output, "---\n\n## Contents\n\n- [`Deployment/default/app`](#"+key.Anchor()+")\n\n## Resource Changes\n\n")
+	assert.Contains(t, output, "<a id=\""+key.Anchor()+"\"></a>\n### apps/Deployment default/app\n```diff\n")
+	assert.NotContains(t, output, "(#configmap-default-trivial", "trivial changes have no diff to link to")
+}
@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FieldAnnotation locates a changed field of a resource in its head manifest file
+type FieldAnnotation struct {
+	Path string `json:"path"` // Path of the changed field, e.g. "spec.template.spec.containers[0].image", or "" for the whole resource
+	parser.Location
+}
+
+// ChangedFields returns the paths of the fields added, changed or removed from base to head, sorted, in the
+// notation of parser.FieldPath. Lists whose length changed are reported as a whole rather than by item, and
+// a created resource is reported as the path "".
+func ChangedFields(base, head *unstructured.Unstructured) []string {
+	if head == nil {
+		return nil
+	}
+	if base == nil {
+		return []string{""}
+	}
+	var paths []string
+	collectChangedFields("", base.Object, head.Object, &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+// collectChangedFields appends the paths of the fields that differ between the base and head values at path
+func collectChangedFields(path string, base, head any, paths *[]string) {
+	switch head := head.(type) {
+	case map[string]any:
+		base, ok := base.(map[string]any)
+		if !ok {
+			break
+		}
+		for key, headValue := range head {
+			baseValue, found := base[key]
+			if !found {
+				*paths = append(*paths, parser.FieldPath(path, key))
+				continue
+			}
+			collectChangedFields(parser.FieldPath(path, key), baseValue, headValue, paths)
+		}
+		for key := range base {
+			if _, found := head[key]; !found {
+				*paths = append(*paths, parser.FieldPath(path, key))
+			}
+		}
+		return
+	case []any:
+		base, ok := base.([]any)
+		if !ok || len(base) != len(head) {
+			break
+		}
+		for i := range head {
+			collectChangedFields(parser.IndexPath(path, i), base[i], head[i], paths)
+		}
+		return
+	}
+	if !reflect.DeepEqual(base, head) {
+		*paths = append(*paths, path)
+	}
+}
+
+// annotatedField is an annotation of a changed or created resource, as reported by annotation formats
+type annotatedField struct {
+	key        ResourceKey
+	result     Result
+	annotation FieldAnnotation
+}
+
+// annotatedFields returns the annotations of the changed and created results in resource key order
+func (dr Results) annotatedFields() []annotatedField {
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+	var fields []annotatedField
+	for _, key := range keys {
+		result := dr[key]
+		if result.Type != Changed && result.Type != Created {
+			continue
+		}
+		for _, annotation := range result.Annotations {
+			fields = append(fields, annotatedField{key: key, result: result, annotation: annotation})
+		}
+	}
+	return fields
+}
+
+// message describes the annotated field, e.g. "Deployment/prod/web: spec.replicas changed"
+func (f annotatedField) message() string {
+	if f.annotation.Path == "" {
+		return fmt.Sprintf("%s: %s", formatResourceKeyShort(f.key), f.result.Type)
+	}
+	return fmt.Sprintf("%s: %s changed", formatResourceKeyShort(f.key), f.annotation.Path)
+}
+
+// fieldAnnotations locates the changed fields of a created or changed resource in its head manifest files.
+// Fields missing from head, e.g. removed ones, are located at their closest ancestor in head, and fields
+// sharing a location are annotated once.
+func fieldAnnotations(k ResourceKey, base, head *unstructured.Unstructured, sources parser.SourceMap) []FieldAnnotation {
+	if sources == nil {
+		return nil
+	}
+	var annotations []FieldAnnotation
+	located := make(map[parser.Location]bool)
+	for _, path := range ChangedFields(base, head) {
+		location, ok := sources.Lookup(parser.ResourceKey(k), path)
+		if !ok || located[location] {
+			continue
+		}
+		located[location] = true
+		annotations = append(annotations, FieldAnnotation{Path: path, Location: location})
+	}
+	return annotations
+}
@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	baseAnnotated = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  annotations:
+    example.com/owner: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+	headAnnotated = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.26
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: prod
+data:
+  key: value
+`
+)
+
+func TestChangedFields(t *testing.T) {
+	base := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"labels": map[string]any{"app.kubernetes.io/name": "web"}},
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"ports":    []any{int64(80)},
+			"args":     []any{"--a", "--b"},
+		},
+	}}
+	head := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"labels": map[string]any{"app.kubernetes.io/name": "api"}},
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"ports":    []any{int64(80), int64(443)},
+			"args":     []any{"--a", "--c"},
+			"paused":   true,
+		},
+	}}
+
+	assert.Equal(t, []string{
+		`metadata.labels["app.kubernetes.io/name"]`,
+		"spec.args[1]",
+		"spec.paused",
+		"spec.ports",
+	}, ChangedFields(base, head))
+	assert.Equal(t, []string{""}, ChangedFields(nil, head))
+	assert.Nil(t, ChangedFields(base, nil))
+	assert.Empty(t, ChangedFields(base, base))
+}
+
+func TestYamlString_HeadSources(t *testing.T) {
+	sources, err := parser.LocateFields("deploy.yaml", []byte(headAnnotated))
+	require.NoError(t, err)
+
+	results, err := YamlString(baseAnnotated, headAnnotated, DefaultOptions().WithHeadSources(sources))
+	require.NoError(t, err)
+
+	deployment := results[ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "web"}]
+	assert.Equal(t, []FieldAnnotation{
+		{Path: `metadata.annotations`, Location: parser.Location{File: "deploy.yaml", StartLine: 3, EndLine: 5}},
+		{Path: "spec.replicas", Location: parser.Location{File: "deploy.yaml", StartLine: 7, EndLine: 7}},
+		{Path: "spec.template.spec.containers[0].image", Location: parser.Location{File: "deploy.yaml", StartLine: 12, EndLine: 12}},
+	}, deployment.Annotations, "removed fields are located at their parent")
+
+	configMap := results[ResourceKey{Kind: "ConfigMap", Namespace: "prod", Name: "config"}]
+	assert.Equal(t, []FieldAnnotation{
+		{Path: "", Location: parser.Location{File: "deploy.yaml", StartLine: 14, EndLine: 20}},
+	}, configMap.Annotations)
+
+	data, err := json.Marshal(results)
+	require.NoError(t, err)
+	var restored Results
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, deployment.Annotations, restored[ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "web"}].Annotations)
+}
+
+func TestYamlString_WithoutHeadSources(t *testing.T) {
+	results, err := YamlString(baseAnnotated, headAnnotated, DefaultOptions())
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.Nil(t, result.Annotations)
+	}
+}
@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// cacheFormatVersion is bumped whenever the rendered diff format changes to invalidate old entries
+const cacheFormatVersion = 5
+
+// diffCache stores rendered diff text on disk keyed by a content hash of the inputs and of the masks their secret
+// values get in the current run. Only rendered (masked) diff text is stored, never raw objects.
+type diffCache struct {
+	dir string
+}
+
+// cacheKeyInput holds everything that influences the rendered diff of a resource
+type cacheKeyInput struct {
+	Version               int
+	Key                   ResourceKey
+	RenamedFrom           *ResourceKey
+	Base                  *unstructured.Unstructured
+	Head                  *unstructured.Unstructured
+	MaskedBase            *unstructured.Unstructured
+	MaskedHead            *unstructured.Unstructured
+	Context               int
+	DisableMaskingSecrets bool
+	SecretPolicies        masking.PolicyTable
+	MaskScope             MaskScope
+	MaskStrategy          masking.Strategy
+	MaskKey               string
+	MaskToken             string
+	MaskMinLength         int
+	DiffStyle             DiffStyle
+	ExpandBelowLines      int
+	FullObjects           FullObjectsMode
+}
+
+// newDiffCache creates the cache directory if needed and returns a diffCache
+func newDiffCache(dir string) (*diffCache, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+	return &diffCache{dir: dir}, nil
+}
+
+// key returns the content hash identifying the diff of a resource pair under the given options. Masks depend on
+// the values masked before in the run, so the pair is identified by its masked objects too.
+func (c *diffCache) key(k ResourceKey, v objBaseHead, maskedBase, maskedHead *unstructured.Unstructured, opts *Options) (string, error) {
+	data, err := json.Marshal(cacheKeyInput{
+		Version:               cacheFormatVersion,
+		Key:                   k,
+		RenamedFrom:           v.renamedFrom,
+		Base:                  v.base,
+		Head:                  v.head,
+		MaskedBase:            maskedBase,
+		MaskedHead:            maskedHead,
+		Context:               opts.Context,
+		DisableMaskingSecrets: opts.DisableMaskingSecrets,
+		SecretPolicies:        opts.SecretPolicies,
+		MaskScope:             opts.MaskScope,
+		MaskStrategy:          opts.MaskStrategy,
+		MaskKey:               opts.MaskKey,
+		MaskToken:             opts.MaskToken,
+		MaskMinLength:         opts.MaskMinLength,
+		DiffStyle:             opts.DiffStyle,
+		ExpandBelowLines:      opts.ExpandBelowLines,
+		FullObjects:           opts.FullObjects,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute cache key for %s: %w", k, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// get returns the cached diff text for the key if present
+func (c *diffCache) get(key string) (string, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// put stores diff text for the key
+func (c *diffCache) put(key, diffText string) error {
+	if err := os.WriteFile(c.path(key), []byte(diffText), 0o600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// path returns the file path of a cache entry
+func (c *diffCache) path(key string) string {
+	return filepath.Join(c.dir, key+".diff")
+}
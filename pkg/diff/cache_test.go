@@ -0,0 +1,119 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjects_CacheDir(t *testing.T) {
+	base := []*unstructured.Unstructured{newConfigMap("app-config", "default", "v1")}
+	head := []*unstructured.Unstructured{newConfigMap("app-config", "default", "v2")}
+
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	opts := DefaultOptions()
+	opts.CacheDir = cacheDir
+
+	first, err := Objects(base, head, opts)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	t.Run("identical inputs reuse cached diff", func(t *testing.T) {
+		// Tamper with the cached entry to prove the cached text is returned
+		entryPath := filepath.Join(cacheDir, entries[0].Name())
+		require.NoError(t, os.WriteFile(entryPath, []byte("cached diff"), 0o600))
+
+		second, err := Objects(base, head, opts)
+		require.NoError(t, err)
+		key := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app-config"}
+		assert.Equal(t, "cached diff", second[key].Diff)
+		assert.Equal(t, Changed, second[key].Type)
+	})
+
+	t.Run("different options do not reuse cached diff", func(t *testing.T) {
+		contextOpts := DefaultOptions()
+		contextOpts.CacheDir = cacheDir
+		contextOpts.Context = 1
+
+		results, err := Objects(base, head, contextOpts)
+		require.NoError(t, err)
+		assert.NotContains(t, results.StringDiff(), "cached diff")
+		assert.NotEqual(t, first.StringDiff(), results.StringDiff())
+
+		entries, err := os.ReadDir(cacheDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("changed inputs do not reuse cached diff", func(t *testing.T) {
+		newHead := []*unstructured.Unstructured{newConfigMap("app-config", "default", "v3")}
+
+		results, err := Objects(base, newHead, opts)
+		require.NoError(t, err)
+		assert.Contains(t, results.StringDiff(), "v3")
+	})
+}
+
+func TestObjects_CacheDirUnchangedResources(t *testing.T) {
+	objs := []*unstructured.Unstructured{newConfigMap("app-config", "default", "v1")}
+
+	cacheDir := t.TempDir()
+	opts := DefaultOptions()
+	opts.CacheDir = cacheDir
+
+	results, err := Objects(objs, objs, opts)
+	require.NoError(t, err)
+	assert.False(t, results.HasChanges())
+
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "Unchanged resources should not be cached")
+}
+
+func TestObjects_CacheDirKeepsMasksConsistent(t *testing.T) {
+	secret := func(name, value string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": name, "namespace": "default"},
+			"stringData": map[string]any{"password": value},
+		}}
+	}
+	base := []*unstructured.Unstructured{secret("a", "a-old"), secret("b", "b-old")}
+	opts := DefaultOptions().WithMaskScope(MaskScopeOperation)
+	opts.CacheDir = t.TempDir()
+
+	_, err := Objects(base, []*unstructured.Unstructured{secret("a", "a-new"), secret("b", "b-new")}, opts)
+	require.NoError(t, err)
+
+	// The diff of a is cached; b is rendered again and must not reuse the masks of the values of a
+	results, err := Objects(base, []*unstructured.Unstructured{secret("a", "a-new"), secret("b", "b-newer")}, opts)
+	require.NoError(t, err)
+	uncachedOpts := DefaultOptions().WithMaskScope(MaskScopeOperation)
+	uncached, err := Objects(base, []*unstructured.Unstructured{secret("a", "a-new"), secret("b", "b-newer")}, uncachedOpts)
+	require.NoError(t, err)
+	for key, result := range uncached {
+		assert.Equal(t, result.Diff, results[key].Diff, "diff of %s", key)
+	}
+}
+
+func TestDiffCache_KeyIncludesRename(t *testing.T) {
+	cache, err := newDiffCache(t.TempDir())
+	require.NoError(t, err)
+	k := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app-config"}
+	v := objBaseHead{base: newConfigMap("app-config", "default", "v1"), head: newConfigMap("app-config", "default", "v2")}
+
+	plain, err := cache.key(k, v, v.base, v.head, DefaultOptions())
+	require.NoError(t, err)
+	v.renamedFrom = &ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "old-config"}
+	renamed, err := cache.key(k, v, v.base, v.head, DefaultOptions())
+	require.NoError(t, err)
+	assert.NotEqual(t, plain, renamed, "the cached text includes the renamed from heading")
+}
@@ -0,0 +1,161 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// tlsSecretType is the Secret type holding a certificate and its private key
+const tlsSecretType = "kubernetes.io/tls"
+
+// certificateChangesHeading introduces the certificate changes appended to the diff of a TLS Secret
+const certificateChangesHeading = "# Certificate changes:\n"
+
+// CertificateChanges describes semantic changes to the certificates of a kubernetes.io/tls Secret,
+// e.g. "data.tls.crt: notAfter 2026-01-01T00:00:00Z -> 2027-01-01T00:00:00Z".
+// Only the leaf (first) certificate of each value is compared. Private keys are never parsed; a changed key bundled
+// with a certificate in the same value is only reported as changed.
+func CertificateChanges(base, head *unstructured.Unstructured) []string {
+	if !isTLSSecret(base) || !isTLSSecret(head) {
+		return nil
+	}
+
+	var changes []string
+	for _, field := range []string{"data", "stringData"} {
+		baseValues, _, _ := unstructured.NestedStringMap(base.Object, field)
+		headValues, _, _ := unstructured.NestedStringMap(head.Object, field)
+		keys := make(map[string]bool, len(baseValues)+len(headValues))
+		for key := range baseValues {
+			keys[key] = true
+		}
+		for key := range headValues {
+			keys[key] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, key := range sortedKeys {
+			if baseValues[key] == headValues[key] {
+				continue
+			}
+			baseCert, baseOK := leafCertificate(baseValues[key], field == "data")
+			headCert, headOK := leafCertificate(headValues[key], field == "data")
+			changes = append(changes, compareCertificates(field+"."+key, baseCert, headCert, baseOK, headOK)...)
+			if baseOK && headOK {
+				changes = append(changes, compareBundledBlocks(field+"."+key, baseValues[key], headValues[key], field == "data")...)
+			}
+		}
+	}
+	return changes
+}
+
+// isTLSSecret reports whether obj is a Secret of type kubernetes.io/tls
+func isTLSSecret(obj *unstructured.Unstructured) bool {
+	if !masking.IsSecret(obj) {
+		return false
+	}
+	secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+	return secretType == tlsSecretType
+}
+
+// decodeSecretValue returns the plain bytes of a Secret value, decoding base64 data values
+func decodeSecretValue(value string, encoded bool) ([]byte, bool) {
+	if !encoded {
+		return []byte(value), true
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	return decoded, err == nil
+}
+
+// compareBundledBlocks describes a change to the private key or other PEM blocks stored with the certificates
+// of a value under path, without revealing them
+func compareBundledBlocks(path, base, head string, encoded bool) []string {
+	baseData, _ := decodeSecretValue(base, encoded)
+	headData, _ := decodeSecretValue(head, encoded)
+	baseTypes, baseBlocks := masking.OtherPEMBlocks(baseData)
+	headTypes, headBlocks := masking.OtherPEMBlocks(headData)
+	switch {
+	case bytes.Equal(baseBlocks, headBlocks):
+		return nil
+	case len(baseBlocks) == 0:
+		return []string{fmt.Sprintf("%s: %s added", path, strings.Join(headTypes, ", "))}
+	case len(headBlocks) == 0:
+		return []string{fmt.Sprintf("%s: %s removed", path, strings.Join(baseTypes, ", "))}
+	default:
+		return []string{fmt.Sprintf("%s: %s changed", path, strings.Join(headTypes, ", "))}
+	}
+}
+
+// leafCertificate returns the first certificate in a Secret value, decoding base64 data values first
+func leafCertificate(value string, encoded bool) (masking.CertificateInfo, bool) {
+	data, ok := decodeSecretValue(value, encoded)
+	if !ok {
+		return masking.CertificateInfo{}, false
+	}
+	certs, err := masking.ParseCertificates(data)
+	if err != nil || len(certs) == 0 {
+		return masking.CertificateInfo{}, false
+	}
+	return certs[0], true
+}
+
+// compareCertificates describes the differences between two certificates stored under path
+func compareCertificates(path string, base, head masking.CertificateInfo, baseOK, headOK bool) []string {
+	switch {
+	case !baseOK && !headOK:
+		return nil
+	case !baseOK:
+		return []string{fmt.Sprintf("%s: certificate added (subject %q, notAfter %s)", path, head.Subject, formatCertificateTime(head.NotAfter))}
+	case !headOK:
+		return []string{fmt.Sprintf("%s: certificate removed (subject %q, notAfter %s)", path, base.Subject, formatCertificateTime(base.NotAfter))}
+	}
+
+	var changes []string
+	if base.Subject != head.Subject {
+		changes = append(changes, fmt.Sprintf("%s: subject %q -> %q", path, base.Subject, head.Subject))
+	}
+	if base.Issuer != head.Issuer {
+		changes = append(changes, fmt.Sprintf("%s: issuer %q -> %q", path, base.Issuer, head.Issuer))
+	}
+	if baseSANs, headSANs := strings.Join(base.SANs, ","), strings.Join(head.SANs, ","); baseSANs != headSANs {
+		changes = append(changes, fmt.Sprintf("%s: SANs [%s] -> [%s]", path, baseSANs, headSANs))
+	}
+	if !base.NotBefore.Equal(head.NotBefore) {
+		changes = append(changes, fmt.Sprintf("%s: notBefore %s -> %s", path, formatCertificateTime(base.NotBefore), formatCertificateTime(head.NotBefore)))
+	}
+	if !base.NotAfter.Equal(head.NotAfter) {
+		changes = append(changes, fmt.Sprintf("%s: notAfter %s -> %s", path, formatCertificateTime(base.NotAfter), formatCertificateTime(head.NotAfter)))
+	}
+	if len(changes) == 0 && base.Fingerprint != head.Fingerprint {
+		changes = append(changes, fmt.Sprintf("%s: certificate reissued with the same subject, issuer, SANs and validity", path))
+	}
+	return changes
+}
+
+// formatCertificateTime formats certificate validity bounds in UTC
+func formatCertificateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatChangeComments renders semantic changes as YAML comments under heading, appended to the diff text
+func formatChangeComments(heading string, changes []string) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var result strings.Builder
+	result.WriteString(heading)
+	for _, change := range changes {
+		result.WriteString("#   " + change + "\n")
+	}
+	return result.String()
+}
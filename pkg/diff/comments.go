@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCommentLimit is the maximum length of a GitHub issue or pull request comment
+const DefaultCommentLimit = 65536
+
+const (
+	// commentPartReserve is the length kept free in each comment for its part footer
+	commentPartReserve = 64
+	// continuedHeading introduces the resource diffs of comments after the first
+	continuedHeading = "## Resource Changes (continued)\n\n"
+	// truncatedNote ends Markdown cut off at a comment limit
+	truncatedNote = "\n\n_(truncated)_"
+	// truncatedDiffNote ends a diff cut off at a comment limit, followed by the fence closing its code block
+	truncatedDiffNote = "\n... (truncated)\n"
+)
+
+// MarkdownComments returns the Markdown report of StringDiffMarkdown as comment bodies of at most limit bytes,
+// which never undercounts the characters GitHub limits comments by. A report that fits is returned as it is.
+// Otherwise the summary comes first and the diffs of resources follow in key order, each comment ending with
+// "Part i of n". A diff too long for a comment of its own is truncated. A limit of 0 or less disables splitting.
+func (dr Results) MarkdownComments(limit int) []string {
+	report := dr.StringDiffMarkdown()
+	if limit <= 0 || len(report) <= limit {
+		return []string{report}
+	}
+	budget := max(limit-commentPartReserve, len(continuedHeading)+len(truncatedDiffNote)+len("```\n\n")+1)
+
+	parts := []string{truncateMarkdown(dr.StringSummaryMarkdown(), budget)}
+	var current strings.Builder
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+	for _, key := range keys {
+		body := markdownDiffBody(key, dr[key])
+		if body == "" {
+			continue
+		}
+		body = truncateDiffBody(body, markdownFence(dr[key].Diff), budget-len(continuedHeading))
+		if current.Len() > 0 && current.Len()+len(body) > budget {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() == 0 {
+			current.WriteString(continuedHeading)
+		}
+		current.WriteString(body)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+
+	for i, part := range parts {
+		parts[i] = fmt.Sprintf("%s\n\n---\n_Part %d of %d_", strings.TrimRight(part, "\n"), i+1, len(parts))
+	}
+	return parts
+}
+
+// MarkdownSummaryComment returns the Markdown summary linking to the full report at reportURL, e.g. a gist or
+// a build artifact, truncated to limit bytes. A limit of 0 or less disables truncation.
+func (dr Results) MarkdownSummaryComment(limit int, reportURL string) string {
+	link := fmt.Sprintf("\n\n**Full report**: %s", reportURL)
+	if limit <= 0 {
+		return dr.StringSummaryMarkdown() + link
+	}
+	return truncateMarkdown(dr.StringSummaryMarkdown(), limit-len(link)) + link
+}
+
+// truncateMarkdown cuts text at the last line that fits in limit bytes together with a note that it was truncated
+func truncateMarkdown(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return cutAtLine(text, limit-len(truncatedNote)) + truncatedNote
+}
+
+// truncateDiffBody cuts a diff section of markdownDiffBody at the last line that fits in limit bytes,
+// closing its code block with fence
+func truncateDiffBody(body, fence string, limit int) string {
+	if len(body) <= limit {
+		return body
+	}
+	closing := truncatedDiffNote + fence + "\n\n"
+	return cutAtLine(body, limit-len(closing)) + closing
+}
+
+// cutAtLine returns the lines of text that fit in limit bytes, without the final newline
+func cutAtLine(text string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if i := strings.LastIndexByte(text[:min(limit+1, len(text))], '\n'); i >= 0 {
+		return text[:i]
+	}
+	return ""
+}
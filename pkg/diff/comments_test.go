@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commentResults(lines int) Results {
+	var diffText strings.Builder
+	diffText.WriteString("===== apps/Deployment default/app ======\n")
+	for i := 0; i < lines; i++ {
+		diffText.WriteString("+  replicas: 3\n")
+	}
+	return Results{
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "app"}: {Type: Changed, Diff: diffText.String()},
+		{Kind: "ConfigMap", Namespace: "default", Name: "config"}: {
+			Type: Changed,
+			Diff: "===== /ConfigMap default/config ======\n-  key: a\n+  key: b\n",
+		},
+	}
+}
+
+func TestResults_MarkdownComments(t *testing.T) {
+	t.Run("fits", func(t *testing.T) {
+		results := commentResults(1)
+		for _, limit := range []int{DefaultCommentLimit, 0} {
+			comments := results.MarkdownComments(limit)
+			require.Len(t, comments, 1)
+			assert.Len(t, comments[0], len(results.StringDiffMarkdown()))
+			assert.NotContains(t, comments[0], "Part 1 of")
+		}
+	})
+
+	t.Run("split", func(t *testing.T) {
+		results := commentResults(20)
+		comments := results.MarkdownComments(500)
+		require.Len(t, comments, 3)
+		assert.Contains(t, comments[0], "## Summary")
+		assert.True(t, strings.HasSuffix(comments[0], "_Part 1 of 3_"))
+		assert.Contains(t, comments[1], "## Resource Changes (continued)\n\n<a id=\"configmap-default-config-2714bff3\"></a>\n### /ConfigMap default/config")
+		assert.Contains(t, comments[2], "### apps/Deployment default/app")
+		for _, comment := range comments {
+			assert.LessOrEqual(t, len(comment), 500)
+		}
+	})
+
+	t.Run("truncated diff", func(t *testing.T) {
+		comments := commentResults(100).MarkdownComments(500)
+		last := comments[len(comments)-1]
+		assert.Contains(t, last, "+  replicas: 3\n... (truncated)\n```")
+		assert.LessOrEqual(t, len(last), 500)
+	})
+}
+
+func TestResults_MarkdownSummaryComment(t *testing.T) {
+	comment := commentResults(1).MarkdownSummaryComment(DefaultCommentLimit, "https://gist.github.com/abc")
+	assert.Contains(t, comment, "## Summary")
+	assert.True(t, strings.HasSuffix(comment, "**Full report**: https://gist.github.com/abc"))
+	assert.NotContains(t, comment, "```diff")
+
+	truncated := commentResults(1).MarkdownSummaryComment(150, "https://gist.github.com/abc")
+	assert.LessOrEqual(t, len(truncated), 150)
+	assert.Contains(t, truncated, "_(truncated)_")
+}
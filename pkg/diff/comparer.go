@@ -0,0 +1,53 @@
+package diff
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// comparisonHeading introduces the detail of a registered Comparer in diff output
+const comparisonHeading = "# Comparison:\n"
+
+// Comparer compares the base and head object of a resource existing on both sides semantically, e.g. a custom
+// resource whose fields are ordered or defaulted by its controller. equal reports whether they are the same;
+// otherwise detail, if not empty, describes the difference. Comparers must not modify the objects.
+type Comparer func(base, head *unstructured.Unstructured) (equal bool, detail string)
+
+var (
+	comparersMu sync.RWMutex
+	comparers   = map[schema.GroupKind]Comparer{}
+)
+
+// RegisterComparer registers the comparer deciding whether resources of gk changed, replacing any registered
+// before; a nil comparer removes it. Resources a comparer considers equal are Unchanged even if their YAML differs,
+// and the diff of the others ends with the detail. Comparers are process-wide and apply to every diff.
+func RegisterComparer(gk schema.GroupKind, comparer Comparer) {
+	comparersMu.Lock()
+	defer comparersMu.Unlock()
+	if comparer == nil {
+		delete(comparers, gk)
+		return
+	}
+	comparers[gk] = comparer
+}
+
+// compare returns the change type of a resource pair, consulting the comparer registered for its kind for
+// resources existing on both sides, together with the comparer's detail
+func compare(key ResourceKey, v objBaseHead) (ChangeType, string) {
+	changeType := determineChangeType(v.base, v.head)
+	if changeType != Changed {
+		return changeType, ""
+	}
+	comparersMu.RLock()
+	comparer, ok := comparers[schema.GroupKind{Group: key.Group, Kind: key.Kind}]
+	comparersMu.RUnlock()
+	if !ok {
+		return changeType, ""
+	}
+	if equal, detail := comparer(v.base, v.head); !equal {
+		return Changed, detail
+	}
+	return Unchanged, ""
+}
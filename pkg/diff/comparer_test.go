@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newComposition(name string, resources ...string) *unstructured.Unstructured {
+	items := make([]any, 0, len(resources))
+	for _, resource := range resources {
+		items = append(items, map[string]any{"name": resource})
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.crossplane.io/v1",
+		"kind":       "Composition",
+		"metadata":   map[string]any{"name": name},
+		"spec":       map[string]any{"resources": items},
+	}}
+}
+
+// compositionResources returns the names of the resources of a composition, ignoring their order
+func compositionResources(obj *unstructured.Unstructured) map[string]bool {
+	items, _, _ := unstructured.NestedSlice(obj.Object, "spec", "resources")
+	names := make(map[string]bool)
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			names[m["name"].(string)] = true
+		}
+	}
+	return names
+}
+
+func TestRegisterComparer(t *testing.T) {
+	gk := schema.GroupKind{Group: "apiextensions.crossplane.io", Kind: "Composition"}
+	RegisterComparer(gk, func(base, head *unstructured.Unstructured) (bool, string) {
+		baseNames, headNames := compositionResources(base), compositionResources(head)
+		if len(baseNames) != len(headNames) {
+			return false, "resource count changed"
+		}
+		for name := range baseNames {
+			if !headNames[name] {
+				return false, "resource " + name + " removed"
+			}
+		}
+		return true, ""
+	})
+	t.Cleanup(func() { RegisterComparer(gk, nil) })
+
+	base := []*unstructured.Unstructured{
+		newComposition("reordered", "bucket", "policy"),
+		newComposition("changed", "bucket", "policy"),
+	}
+	head := []*unstructured.Unstructured{
+		newComposition("reordered", "policy", "bucket"),
+		newComposition("changed", "bucket", "role"),
+	}
+	results, err := Objects(base, head, nil)
+	require.NoError(t, err)
+
+	reordered := results[ResourceKey{Group: gk.Group, Kind: gk.Kind, Name: "reordered"}]
+	assert.Equal(t, Unchanged, reordered.Type)
+	assert.Empty(t, reordered.Diff)
+
+	changed := results[ResourceKey{Group: gk.Group, Kind: gk.Kind, Name: "changed"}]
+	assert.Equal(t, Changed, changed.Type)
+	assert.Equal(t, "resource policy removed", changed.ComparisonDetail)
+	assert.Contains(t, changed.Diff, comparisonHeading+"#   resource policy removed\n")
+}
+
+func TestRegisterComparer_Removed(t *testing.T) {
+	gk := schema.GroupKind{Group: "apiextensions.crossplane.io", Kind: "Composition"}
+	RegisterComparer(gk, func(_, _ *unstructured.Unstructured) (bool, string) { return true, "" })
+	RegisterComparer(gk, nil)
+
+	results, err := Objects(
+		[]*unstructured.Unstructured{newComposition("reordered", "bucket", "policy")},
+		[]*unstructured.Unstructured{newComposition("reordered", "policy", "bucket")},
+		nil,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, Changed, results[ResourceKey{Group: gk.Group, Kind: gk.Kind, Name: "reordered"}].Type)
+}
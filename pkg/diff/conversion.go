@@ -0,0 +1,144 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FieldMapping moves the value at a dot-separated path, e.g. "spec.size", to another path
+type FieldMapping struct {
+	From string `yaml:"from"`         // Path of the field in the source version
+	To   string `yaml:"to,omitempty"` // Path of the field in the target version; the field is dropped when empty
+}
+
+// ConversionRule converts custom resources of a kind from one version to another by moving fields,
+// a best-effort replacement for the conversion webhook of a CRD
+type ConversionRule struct {
+	Group  string         `yaml:"group"`
+	Kind   string         `yaml:"kind"`
+	From   string         `yaml:"from"` // Source version, e.g. "v1alpha1"
+	To     string         `yaml:"to"`   // Target version, e.g. "v1"
+	Fields []FieldMapping `yaml:"fields,omitempty"`
+}
+
+// ConversionPolicy converts custom resources to a common version before comparison,
+// so that a version migration shows only real field changes
+type ConversionPolicy struct {
+	Rules []ConversionRule `yaml:"conversions"`
+}
+
+// ReadConversionPolicy reads a conversion policy from YAML, e.g.
+//
+//	conversions:
+//	- group: example.com
+//	  kind: Widget
+//	  from: v1alpha1
+//	  to: v1
+//	  fields:
+//	  - from: spec.size
+//	    to: spec.replicas
+//	  - from: spec.legacyMode
+func ReadConversionPolicy(r io.Reader) (*ConversionPolicy, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversion policy: %w", err)
+	}
+
+	var policy ConversionPolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse conversion policy: %w", err)
+	}
+	for i, rule := range policy.Rules {
+		if rule.Kind == "" || rule.From == "" || rule.To == "" {
+			return nil, fmt.Errorf("conversion policy rule %d: kind, from and to are required", i+1)
+		}
+		if rule.From == rule.To {
+			return nil, fmt.Errorf("conversion policy rule %d: from and to must differ", i+1)
+		}
+		for _, field := range rule.Fields {
+			if field.From == "" {
+				return nil, fmt.Errorf("conversion policy rule %d: field from is required", i+1)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// Convert returns a copy of obj converted by the rules matching its group, kind and version.
+// Rules are applied repeatedly, so v1alpha1 to v1beta1 and v1beta1 to v1 rules convert v1alpha1 objects to v1.
+// Objects matching no rule are returned as they are.
+func (p *ConversionPolicy) Convert(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if p == nil {
+		return obj, nil
+	}
+	converted := obj
+	// Each rule is applied at most once, which also stops cycles such as v1 to v2 and back
+	applied := make([]bool, len(p.Rules))
+	for {
+		gvk := converted.GroupVersionKind()
+		i := p.ruleFor(gvk, applied)
+		if i < 0 {
+			return converted, nil
+		}
+		applied[i] = true
+		if converted == obj {
+			converted = obj.DeepCopy()
+		}
+		if err := p.Rules[i].apply(converted); err != nil {
+			return nil, fmt.Errorf("failed to convert %s/%s to %s: %w", gvk.Kind, obj.GetName(), p.Rules[i].To, err)
+		}
+	}
+}
+
+// ruleFor returns the index of the first rule not yet applied converting gvk, or -1
+func (p *ConversionPolicy) ruleFor(gvk schema.GroupVersionKind, applied []bool) int {
+	for i, rule := range p.Rules {
+		if !applied[i] && rule.Group == gvk.Group && rule.Kind == gvk.Kind && rule.From == gvk.Version {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply moves the mapped fields of obj and sets its apiVersion to the target version.
+// Every move reads its field from the object as it was before the rule, so that fields can swap places.
+func (r ConversionRule) apply(obj *unstructured.Unstructured) error {
+	values := make([]any, len(r.Fields))
+	found := make([]bool, len(r.Fields))
+	for i, field := range r.Fields {
+		values[i], found[i], _ = unstructured.NestedFieldCopy(obj.Object, strings.Split(field.From, ".")...)
+	}
+	for i, field := range r.Fields {
+		if found[i] {
+			unstructured.RemoveNestedField(obj.Object, strings.Split(field.From, ".")...)
+		}
+	}
+	for i, field := range r.Fields {
+		if !found[i] || field.To == "" {
+			continue
+		}
+		if err := unstructured.SetNestedField(obj.Object, values[i], strings.Split(field.To, ".")...); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", field.From, field.To, err)
+		}
+	}
+	obj.SetAPIVersion(schema.GroupVersion{Group: r.Group, Version: r.To}.String())
+	return nil
+}
+
+// convertObjects converts each object with the policy
+func convertObjects(objs []*unstructured.Unstructured, policy *ConversionPolicy) ([]*unstructured.Unstructured, error) {
+	converted := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		convertedObj, err := policy.Convert(obj)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, convertedObj)
+	}
+	return converted, nil
+}
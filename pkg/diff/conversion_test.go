@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const widgetConversions = `conversions:
+- group: example.com
+  kind: Widget
+  from: v1alpha1
+  to: v1beta1
+  fields:
+  - from: spec.size
+    to: spec.replicas
+  - from: spec.legacyMode
+- group: example.com
+  kind: Widget
+  from: v1beta1
+  to: v1
+  fields:
+  - from: spec.color
+    to: spec.style.color
+`
+
+func TestObjects_Conversions(t *testing.T) {
+	base := `apiVersion: example.com/v1alpha1
+kind: Widget
+metadata:
+  name: demo
+  namespace: default
+spec:
+  size: 3
+  color: blue
+  legacyMode: true
+`
+	head := `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: demo
+  namespace: default
+spec:
+  replicas: 3
+  style:
+    color: blue
+`
+	key := ResourceKey{Group: "example.com", Kind: "Widget", Namespace: "default", Name: "demo"}
+	policy, err := ReadConversionPolicy(strings.NewReader(widgetConversions))
+	require.NoError(t, err)
+
+	t.Run("without conversion", func(t *testing.T) {
+		results, err := YamlString(base, head, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+	})
+
+	t.Run("converted through intermediate version", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Conversions = policy
+		results, err := YamlString(base, head, opts)
+		require.NoError(t, err)
+		assert.Equal(t, Unchanged, results[key].Type)
+	})
+
+	t.Run("real changes remain", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Conversions = policy
+		results, err := YamlString(base, strings.Replace(head, "replicas: 3", "replicas: 5", 1), opts)
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+		assert.Contains(t, results[key].Diff, "replicas: 5")
+		assert.NotContains(t, results[key].Diff, "-apiVersion")
+	})
+
+	t.Run("conflicting target path", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Conversions = &ConversionPolicy{Rules: []ConversionRule{{
+			Group: "example.com", Kind: "Widget", From: "v1alpha1", To: "v1",
+			Fields: []FieldMapping{{From: "spec.size", To: "spec.color.size"}},
+		}}}
+		_, err := YamlString(base, head, opts)
+		assert.ErrorContains(t, err, "failed to convert Widget/demo to v1")
+	})
+}
+
+func TestConversionPolicyConvert(t *testing.T) {
+	policy, err := ReadConversionPolicy(strings.NewReader(widgetConversions))
+	require.NoError(t, err)
+
+	t.Run("original is not modified", func(t *testing.T) {
+		obj := newConfigMap("a", "default", "x")
+		obj.SetAPIVersion("example.com/v1alpha1")
+		obj.SetKind("Widget")
+		converted, err := policy.Convert(obj)
+		require.NoError(t, err)
+		assert.Equal(t, "example.com/v1", converted.GetAPIVersion())
+		assert.Equal(t, "example.com/v1alpha1", obj.GetAPIVersion())
+	})
+
+	t.Run("other kinds are returned as they are", func(t *testing.T) {
+		obj := newConfigMap("a", "default", "x")
+		converted, err := policy.Convert(obj)
+		require.NoError(t, err)
+		assert.Same(t, obj, converted)
+	})
+
+	t.Run("fields swap places", func(t *testing.T) {
+		swap, err := ReadConversionPolicy(strings.NewReader(`conversions:
+- group: example.com
+  kind: Widget
+  from: v1alpha1
+  to: v1
+  fields:
+  - from: spec.primary
+    to: spec.secondary
+  - from: spec.secondary
+    to: spec.primary
+`))
+		require.NoError(t, err)
+		obj := newConfigMap("a", "default", "x")
+		obj.SetAPIVersion("example.com/v1alpha1")
+		obj.SetKind("Widget")
+		obj.Object["spec"] = map[string]any{"primary": "us-east", "secondary": "eu-west"}
+		converted, err := swap.Convert(obj)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"primary": "eu-west", "secondary": "us-east"}, converted.Object["spec"])
+	})
+
+	t.Run("nil policy", func(t *testing.T) {
+		obj := newConfigMap("a", "default", "x")
+		converted, err := (*ConversionPolicy)(nil).Convert(obj)
+		require.NoError(t, err)
+		assert.Same(t, obj, converted)
+	})
+}
+
+func TestReadConversionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{name: "missing version", input: "conversions:\n- kind: Widget\n  from: v1\n", expectedErr: "kind, from and to are required"},
+		{name: "same versions", input: "conversions:\n- kind: Widget\n  from: v1\n  to: v1\n", expectedErr: "from and to must differ"},
+		{name: "field without source", input: "conversions:\n- kind: Widget\n  from: v1\n  to: v2\n  fields:\n  - to: spec.a\n", expectedErr: "field from is required"},
+		{name: "unknown key", input: "conversions:\n- kind: Widget\n  from: v1\n  to: v2\n  webhook: true\n", expectedErr: "failed to parse conversion policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadConversionPolicy(strings.NewReader(tt.input))
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
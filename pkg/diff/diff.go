@@ -2,12 +2,16 @@
 package diff
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
 
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -20,48 +24,475 @@ func YamlString(baseYaml, headYaml string, opts *Options) (Results, error) {
 
 // Yaml compares YAML from two io.Reader sources and returns the diff
 func Yaml(baseReader, headReader io.Reader, opts *Options) (Results, error) {
-	baseObjects, err := parser.ParseYAML(baseReader)
+	return YamlContext(context.Background(), baseReader, headReader, opts)
+}
+
+// YamlContext compares YAML from two io.Reader sources like Yaml, recording OpenTelemetry spans
+// of the parse stage and the stages of ObjectsContext below the span in ctx
+func YamlContext(ctx context.Context, baseReader, headReader io.Reader, opts *Options) (Results, error) {
+	baseObjects, headObjects, err := parseYaml(ctx, baseReader, headReader)
+	if err != nil {
+		return nil, err
+	}
+	return ObjectsContext(ctx, baseObjects, headObjects, opts)
+}
+
+// parseYaml parses the base and head YAML, recording a span for the parse stage
+func parseYaml(ctx context.Context, baseReader, headReader io.Reader) (baseObjects, headObjects []*unstructured.Unstructured, err error) {
+	_, span := tracer.Start(ctx, "diff.parse")
+	defer func() { endSpan(span, err) }()
+
+	baseObjects, err = parser.ParseYAML(baseReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse base YAML: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse base YAML: %w", err)
 	}
 
-	headObjects, err := parser.ParseYAML(headReader)
+	headObjects, err = parser.ParseYAML(headReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse head YAML: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse head YAML: %w", err)
 	}
+	span.SetAttributes(
+		attribute.Int("k8s_manifest_diff.base.objects", len(baseObjects)),
+		attribute.Int("k8s_manifest_diff.head.objects", len(headObjects)),
+	)
+	return baseObjects, headObjects, nil
+}
 
-	return Objects(baseObjects, headObjects, opts)
+// HasChanges reports whether any resource was created, changed or deleted from base to head. Objects are
+// paired and compared as by Objects with opts, but only until the first change, without marshaling them or
+// generating diff text, for gating at a fraction of the cost of a full diff.
+func HasChanges(base, head []*unstructured.Unstructured, opts *Options) (bool, error) {
+	failFast := DefaultOptions()
+	if opts != nil {
+		copied := *opts
+		failFast = &copied
+	}
+	failFast.FailFast = true
+	results, err := Objects(base, head, failFast)
+	if err != nil {
+		return false, err
+	}
+	return results.HasChanges(), nil
 }
 
 // Objects compares two sets of Kubernetes objects and returns the diff
 func Objects(base, head []*unstructured.Unstructured, opts *Options) (Results, error) {
+	return ObjectsContext(context.Background(), base, head, opts)
+}
+
+// ObjectsContext compares two sets of Kubernetes objects like Objects, recording OpenTelemetry spans
+// of the filter, pair and render stages below the span in ctx
+func ObjectsContext(ctx context.Context, base, head []*unstructured.Unstructured, opts *Options) (results Results, err error) {
+	ctx, span := tracer.Start(ctx, "diff.Objects", trace.WithAttributes(
+		attribute.Int("k8s_manifest_diff.base.objects", len(base)),
+		attribute.Int("k8s_manifest_diff.head.objects", len(head)),
+	))
+	defer func() { endSpan(span, err) }()
+
 	if opts == nil {
 		opts = DefaultOptions()
 	}
 
-	base = filter.Resources(base, opts.FilterOption)
-	head = filter.Resources(head, opts.FilterOption)
-	objMap := parseObjsToMap(base, head)
-	results := make(Results)
+	masker, err := newScopedMasker(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.FilterOption.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateOnlyPatterns(opts.Only); err != nil {
+		return nil, err
+	}
+	if err := validateResourcePatterns(opts.DisableMaskingFor, "disable masking"); err != nil {
+		return nil, err
+	}
+	if err := opts.SecretPolicies.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.DiffStyle.validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.FullObjects.validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.UnnamedMatching.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateRenameThreshold(opts.RenameThreshold); err != nil {
+		return nil, err
+	}
+	if _, err := compileValueRegexes(opts.IgnoreValueRegexes); err != nil {
+		return nil, err
+	}
+	if opts.UseLastApplied && opts.FieldManager != "" {
+		return nil, fmt.Errorf("last-applied configuration and field manager restriction cannot be combined")
+	}
+	// Live objects are replaced before filtering so that server-populated fields never reach the diff
+	if opts.UseLastApplied {
+		if base, err = replaceWithLastApplied(base); err != nil {
+			return nil, err
+		}
+	}
+
+	// Transforms see the objects as compared, e.g. the last-applied configuration, but precede everything else
+	if len(opts.PreTransform) > 0 {
+		if base, err = transformObjects(base, opts.PreTransform); err != nil {
+			return nil, err
+		}
+		if head, err = transformObjects(head, opts.PreTransform); err != nil {
+			return nil, err
+		}
+	}
+
+	// Conversion precedes filtering so that filters see the version resources are compared at
+	if opts.Conversions != nil {
+		if base, err = convertObjects(base, opts.Conversions); err != nil {
+			return nil, err
+		}
+		if head, err = convertObjects(head, opts.Conversions); err != nil {
+			return nil, err
+		}
+	}
+
+	keyFunc := resolveKeyFunc(opts)
+	var baseOriginals, headOriginals map[ResourceKey]ResourceKey
+	if len(opts.NameMappings) > 0 {
+		base, baseOriginals = mapNames(base, opts.NameMappings, keyFunc, opts.Workloads)
+		head, headOriginals = mapNames(head, opts.NameMappings, keyFunc, opts.Workloads)
+	}
+
+	base, head, ignored, err := filterObjects(ctx, base, head, opts, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	objMap, err := pairObjects(ctx, base, head, ignored, opts, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.NameMappings) > 0 {
+		restoreOriginalKeys(objMap, baseOriginals, headOriginals)
+	}
+	return renderResults(ctx, objMap, opts, masker)
+}
+
+// filterObjects applies the filter options to base and head, returning the keys of resources ignored on either side
+func filterObjects(ctx context.Context, base, head []*unstructured.Unstructured, opts *Options, keyFunc KeyFunc) ([]*unstructured.Unstructured, []*unstructured.Unstructured, map[ResourceKey]bool, error) {
+	_, span := tracer.Start(ctx, "diff.filter")
+	defer span.End()
+
+	// Resources ignored on either side are dropped from both to avoid spurious creations or deletions
+	ignored := ignoredResourceKeys(base, head, opts.FilterOption, keyFunc)
+	base, err := filter.Select(base, opts.FilterOption)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to filter base: %w", err)
+	}
+	head, err = filter.Select(head, opts.FilterOption)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to filter head: %w", err)
+	}
+	span.SetAttributes(
+		attribute.Int("k8s_manifest_diff.base.objects", len(base)),
+		attribute.Int("k8s_manifest_diff.head.objects", len(head)),
+	)
+	return base, head, ignored, nil
+}
+
+// pairObjects pairs base and head objects by key, dropping ignored resources and those not selected by Options.Only
+func pairObjects(ctx context.Context, base, head []*unstructured.Unstructured, ignored map[ResourceKey]bool, opts *Options, keyFunc KeyFunc) (objMap map[ResourceKey]objBaseHead, err error) {
+	_, span := tracer.Start(ctx, "diff.pair")
+	defer func() { endSpan(span, err) }()
+
+	objMap = parseObjsToMap(base, head, keyFunc, opts.UnnamedMatching)
+	// Numbered keys of unnamed objects are matched by the key of their objects
+	for key, v := range objMap {
+		if ignored[key] || ignored[keyFunc(v.object())] {
+			delete(objMap, key)
+		}
+	}
+	if opts.RenameThreshold > 0 {
+		pairRenames(objMap, opts.RenameThreshold)
+	}
+	// Resources not selected by Only are skipped before any diff is computed
+	for key := range objMap {
+		if matched, _ := MatchesOnly(key, opts.Only); !matched {
+			delete(objMap, key)
+		}
+	}
+	if opts.FieldManager != "" {
+		for key, v := range objMap {
+			if objMap[key], err = restrictToFieldManager(v, opts.FieldManager); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if opts.KindNormalizers != nil {
+		for key, v := range objMap {
+			objMap[key] = opts.KindNormalizers.normalize(key, v)
+		}
+	}
+	if opts.IgnoreEOL {
+		for key, v := range objMap {
+			objMap[key] = normalizeLineEndings(v)
+		}
+	}
+	if len(opts.IgnoreValueRegexes) > 0 {
+		patterns, err := compileValueRegexes(opts.IgnoreValueRegexes)
+		if err != nil {
+			return nil, err
+		}
+		for key, v := range objMap {
+			objMap[key] = equalizeMatchingValues(v, patterns)
+		}
+	}
+	if opts.SummarizeStatus {
+		for key, v := range objMap {
+			objMap[key] = removeStatus(v)
+		}
+	}
+	if opts.SummarizeSealedSecrets {
+		for key, v := range objMap {
+			objMap[key] = summarizeSealedSecret(v)
+		}
+	}
+	for key, v := range objMap {
+		objMap[key] = normalizeSOPS(v)
+	}
+	span.SetAttributes(attribute.Int("k8s_manifest_diff.resources", len(objMap)))
+	return objMap, nil
+}
+
+// renderResults masks and diffs each resource pair, recording a span per resource
+func renderResults(ctx context.Context, objMap map[ResourceKey]objBaseHead, opts *Options, masker *masking.Masker) (results Results, err error) {
+	ctx, span := tracer.Start(ctx, "diff.render")
+	defer func() { endSpan(span, err) }()
+
+	results = make(Results)
+
+	var cache *diffCache
+	if opts.CacheDir != "" {
+		if cache, err = newDiffCache(opts.CacheDir); err != nil {
+			return nil, err
+		}
+	}
 
+	// Resources are rendered in the order of the keys they are reported under, so that results streamed to OnResult
+	// arrive in the order they are printed
+	pairingKeys := make(map[ResourceKey]ResourceKey, len(objMap))
+	keys := make([]ResourceKey, 0, len(objMap))
 	for k, v := range objMap {
-		changeType := determineChangeType(v.base, v.head)
+		pairingKeys[v.key(k)] = k
+		keys = append(keys, v.key(k))
+	}
+	sortResourceKeys(keys)
 
-		var diffStr string
-		// Generate diff output only for resources that need it
-		if needsDiff := requiresDiffOutput(changeType); needsDiff {
-			diffOutput, code, err := getDiffStr(k.Name, v.head, v.base, opts)
-			if code > 1 {
+	// Linked resources are found by the names references use, which are mapped like the names of the resources
+	linked := linkedConfigs(objMap, opts.Workloads)
+	restartOnly := restartOnlyWorkloads(objMap, linked, opts.Workloads)
+	changeSets := changeSetNames(reportedLinks(objMap, linked))
+	customClusterScoped := customClusterScopedKinds(objMap)
+	var auditRecords []masking.AuditRecord
+	for _, k := range keys {
+		v := objMap[pairingKeys[k]]
+		var result Result
+		var records []masking.AuditRecord
+		if opts.ClassifyOnly || opts.FailFast {
+			result = classifyResult(k, v, opts)
+		} else {
+			var renderErr error
+			result, records, renderErr = renderResult(ctx, k, v, opts, cache, masker)
+			if renderErr != nil {
+				if !opts.ContinueOnError {
+					return nil, renderErr
+				}
+				// The failure is recorded on the resource so that the other resources are still compared
+				result = Result{Type: Error, Err: renderErr}
+			}
+		}
+		result.RestartOnly = result.Type == Changed && restartOnly[pairingKeys[k]]
+		result.ChangeSet = changeSets[k]
+		result.ClusterScoped = customClusterScoped[ResourceKey{Group: k.Group, Kind: k.Kind}]
+		if opts.RetainObjects && result.Type != Error {
+			if result.Base, result.Head, err = retainObjects(k, v, opts, masker); err != nil {
+				return nil, err
+			}
+		}
+		results[k] = result
+		auditRecords = append(auditRecords, records...)
+		if opts.OnResult != nil {
+			if err := opts.OnResult(k, result); err != nil {
 				return nil, err
 			}
-			header := fmt.Sprintf("===== %s/%s %s/%s ======\n", k.Group, k.Kind, k.Namespace, k.Name)
-			diffStr = header + diffOutput
 		}
+		if opts.FailFast && result.Type != Unchanged {
+			break
+		}
+	}
 
-		results[k] = Result{
-			Type: changeType,
-			Diff: diffStr,
+	if opts.MaskingAudit != nil {
+		if err := writeMaskingAudit(opts.MaskingAudit, auditRecords); err != nil {
+			return nil, err
 		}
 	}
 	return results, nil
 }
+
+// renderResult masks and diffs a resource pair, returning its result and the masking audit records of its values
+func renderResult(ctx context.Context, k ResourceKey, v objBaseHead, opts *Options, cache *diffCache, masker *masking.Masker) (Result, []masking.AuditRecord, error) {
+	changeType, detail := compare(k, v)
+	if changeType == Unchanged {
+		// Resources a Comparer considers equal report no semantic changes either
+		v.head = v.base
+	}
+	changeType = summarizedChangeType(changeType, v)
+
+	var diffStr string
+	var auditRecords []masking.AuditRecord
+	// Generate diff output only for resources that need it
+	if needsDiff := requiresDiffOutput(changeType); needsDiff {
+		resourceOpts := resourceMaskingOptions(k, opts)
+		_, resourceSpan := tracer.Start(ctx, "diff.resource", trace.WithAttributes(
+			attribute.String("k8s_manifest_diff.resource", k.String()),
+			attribute.String("k8s_manifest_diff.change_type", changeType.String()),
+		))
+		var err error
+		diffStr, err = renderDiff(k, v, resourceOpts, cache, masker)
+		endSpan(resourceSpan, err)
+		if err != nil {
+			return Result{}, nil, err
+		}
+		if detail != "" {
+			diffStr += formatChangeComments(comparisonHeading, []string{detail})
+		}
+		diffStr += formatChangeComments(statusChangesHeading, v.statusChanges)
+		diffStr += formatChangeComments(sopsChangesHeading, v.sopsChanges)
+		diffStr += formatChangeComments(sealedSecretChangesHeading, v.sealedSecretChanges)
+		if opts.MaskingAudit != nil && !resourceOpts.DisableMaskingSecrets {
+			auditRecords = append(auditRecords, masking.AuditRecords(v.base, "base")...)
+			auditRecords = append(auditRecords, masking.AuditRecords(v.head, "head")...)
+		}
+	}
+
+	return Result{
+		Type:                changeType,
+		Diff:                diffStr,
+		Trivial:             changeType == Changed && opts.FullObjects != FullObjectsOnly && countChangedLines(diffStr) < opts.MinimumChangedLines,
+		WouldPrune:          changeType == Deleted && isTracked(v.base, opts.PruneTracking),
+		ImmutableChanges:    ImmutableFieldChanges(v.base, v.head),
+		CertificateChanges:  CertificateChanges(v.base, v.head),
+		RegistryChanges:     RegistryChanges(v.base, v.head),
+		SecretSourceChanges: SecretSourceChanges(v.base, v.head),
+		ImageChanges:        imageChanges(v.base, v.head, opts.Workloads),
+		ExposureChanges:     ExposureChanges(v.base, v.head),
+		App:                 resourceApp(v),
+		Severity:            opts.Severity.SeverityOf(k),
+		Owners:              opts.Owners.OwnersOf(k, resourceLabels(v)),
+		RenamedFrom:         v.renamedFrom,
+		ComparisonDetail:    detail,
+		StatusChanges:       v.statusChanges,
+		SOPSChanges:         v.sopsChanges,
+		SealedSecretChanges: v.sealedSecretChanges,
+		Annotations:         fieldAnnotations(k, v.base, v.head, opts.HeadSources),
+	}, auditRecords, nil
+}
+
+// resourceMaskingOptions returns the options with masking disabled if the resource matches Options.DisableMaskingFor
+func resourceMaskingOptions(k ResourceKey, opts *Options) *Options {
+	if unmasked, _ := matchesResourcePatterns(k, opts.DisableMaskingFor, "disable masking"); unmasked {
+		unmaskedOpts := *opts
+		unmaskedOpts.DisableMaskingSecrets = true
+		return &unmaskedOpts
+	}
+	return opts
+}
+
+// classifyResult returns the result of a resource pair with only its change type and severity, without
+// marshaling the objects or generating diff text
+func classifyResult(k ResourceKey, v objBaseHead, opts *Options) Result {
+	changeType, _ := compare(k, v)
+	changeType = summarizedChangeType(changeType, v)
+	return Result{
+		Type:       changeType,
+		WouldPrune: changeType == Deleted && isTracked(v.base, opts.PruneTracking),
+		Severity:   opts.Severity.SeverityOf(k),
+	}
+}
+
+// summarizedChangeType returns the change type of an unchanged resource as Changed if it has summarized changes.
+// Condition transitions, re-encryptions and changed keys are the only change shown for resources compared
+// without their status, SOPS metadata or sealed values.
+func summarizedChangeType(changeType ChangeType, v objBaseHead) ChangeType {
+	if changeType == Unchanged && len(v.statusChanges)+len(v.sopsChanges)+len(v.sealedSecretChanges) > 0 {
+		return Changed
+	}
+	return changeType
+}
+
+// renderDiff returns the diff text with header for a resource pair, reusing cached text when available
+func renderDiff(k ResourceKey, v objBaseHead, opts *Options, cache *diffCache, masker *masking.Masker) (string, error) {
+	if opts.MaskScope == MaskScopeResource {
+		var err error
+		if masker, err = newResourceMasker(k, opts); err != nil {
+			return "", err
+		}
+	}
+	var cacheKey string
+	if cache != nil {
+		// Masking registers the secret values with a shared masker in the order rendering does, so that a cache
+		// hit leaves the masks of the following resources unchanged, and keys the cached text by the masks
+		maskedHead, maskedBase, err := prepareObjectsForDiff(v.head, v.base, opts, masker)
+		if err != nil {
+			return "", err
+		}
+		if cacheKey, err = cache.key(k, v, maskedBase, maskedHead, opts); err != nil {
+			return "", err
+		}
+		if cached, ok := cache.get(cacheKey); ok {
+			return cached, nil
+		}
+	}
+	var diffOutput string
+	var err error
+	changed := determineChangeType(v.base, v.head) == Changed
+	switch {
+	case opts.FullObjects == FullObjectsOnly && changed:
+		if diffOutput, err = getFullObjectsStr(v.base, v.head, opts, masker); err != nil {
+			return "", err
+		}
+	case opts.DiffStyle == DiffStyleInline && changed:
+		if diffOutput, err = getInlineStr(v.base, v.head, opts, masker); err != nil {
+			return "", err
+		}
+	case opts.DiffStyle == DiffStyleReport && changed:
+		if diffOutput, err = getReportStr(v.base, v.head, opts, masker); err != nil {
+			return "", err
+		}
+	default:
+		var code int
+		diffOutput, code, err = getDiffStr(k.Name, v.head, v.base, opts, masker)
+		if code > 1 {
+			return "", err
+		}
+	}
+	diffOutput += formatChangeComments(certificateChangesHeading, CertificateChanges(v.base, v.head))
+	diffOutput += formatChangeComments(registryChangesHeading, RegistryChanges(v.base, v.head))
+	diffOutput += formatChangeComments(secretSourceChangesHeading, SecretSourceChanges(v.base, v.head))
+	if opts.FullObjects == FullObjectsAppend && changed {
+		fullObjects, err := getFullObjectsStr(v.base, v.head, opts, masker)
+		if err != nil {
+			return "", err
+		}
+		diffOutput += fullObjects
+	}
+	header := fmt.Sprintf("===== %s/%s %s/%s ======\n", k.Group, k.Kind, k.Namespace, k.Name)
+	if v.renamedFrom != nil {
+		header += renamedFromHeading + formatResourceKeyShort(*v.renamedFrom) + "\n"
+	}
+	diffStr := header + diffOutput
+
+	if cache != nil {
+		if err := cache.put(cacheKey, diffStr); err != nil {
+			return "", err
+		}
+	}
+	return diffStr, nil
+}
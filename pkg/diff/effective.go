@@ -0,0 +1,171 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+)
+
+// optionsHeading introduces the effective options in text output
+const optionsHeading = "# options: "
+
+// EffectiveOptions records the resolved options a diff was computed with, so that it is auditable which
+// filtering, masking and normalization rules produced a report. MaskKey and KeyFunc are only recorded as set,
+// the policies of Severity and Owners by their number of rules, and PreTransform by its number of transforms.
+// CacheDir, MaskingAudit, OnResult, HeadSources and RetainObjects do not change results and are not recorded.
+type EffectiveOptions struct {
+	ExcludeKinds              []string            `json:"excludeKinds,omitempty"`
+	LabelSelector             map[string]string   `json:"labelSelector,omitempty"`
+	AnnotationSelector        map[string]string   `json:"annotationSelector,omitempty"`
+	ExcludeLabelSelector      map[string][]string `json:"excludeLabelSelector,omitempty"`
+	ExcludeAnnotationSelector map[string][]string `json:"excludeAnnotationSelector,omitempty"`
+	FilterExpression          string              `json:"filterExpression,omitempty"`
+	DisableIgnoreAnnotation   bool                `json:"disableIgnoreAnnotation,omitempty"`
+	Context                   int                 `json:"context"`
+	DisableMaskingSecrets     bool                `json:"disableMaskingSecrets,omitempty"`
+	DisableMaskingFor         []string            `json:"disableMaskingFor,omitempty"`
+	SecretPolicies            masking.PolicyTable `json:"secretPolicies,omitempty"`
+	MaskScope                 MaskScope           `json:"maskScope,omitempty"`
+	MaskStrategy              masking.Strategy    `json:"maskStrategy,omitempty"`
+	MaskKeySet                bool                `json:"maskKeySet,omitempty"`
+	MaskToken                 string              `json:"maskToken,omitempty"`
+	MaskMinLength             int                 `json:"maskMinLength,omitempty"`
+	MinimumChangedLines       int                 `json:"minimumChangedLines,omitempty"`
+	CustomKeyFunc             bool                `json:"customKeyFunc,omitempty"`
+	Only                      []string            `json:"only,omitempty"`
+	SeverityRules             int                 `json:"severityRules,omitempty"`
+	OwnerRules                int                 `json:"ownerRules,omitempty"`
+	DiffStyle                 DiffStyle           `json:"diffStyle,omitempty"`
+	UseLastApplied            bool                `json:"useLastApplied,omitempty"`
+	FieldManager              string              `json:"fieldManager,omitempty"`
+	ExpandBelowLines          int                 `json:"expandBelowLines,omitempty"`
+	FullObjects               FullObjectsMode     `json:"fullObjects,omitempty"`
+	UnnamedMatching           UnnamedMatching     `json:"unnamedMatching,omitempty"`
+	RenameThreshold           float64             `json:"renameThreshold,omitempty"`
+	Conversions               []string            `json:"conversions,omitempty"`     // e.g. "example.com/Widget v1alpha1 -> v1 (spec.size -> spec.replicas, spec.legacy -> -)"
+	NormalizedKinds           []string            `json:"normalizedKinds,omitempty"` // "group/Kind" of KindNormalizers, sorted
+	NameMappings              []string            `json:"nameMappings,omitempty"`    // e.g. "s/^staging-//"
+	IgnoreEOL                 bool                `json:"ignoreEOL,omitempty"`
+	IgnoreValueRegexes        []string            `json:"ignoreValueRegexes,omitempty"`
+	SummarizeStatus           bool                `json:"summarizeStatus,omitempty"`
+	SummarizeSealedSecrets    bool                `json:"summarizeSealedSecrets,omitempty"`
+	Workloads                 []string            `json:"workloads,omitempty"` // "group/Kind" of configured workload kinds
+	ContinueOnError           bool                `json:"continueOnError,omitempty"`
+	ClassifyOnly              bool                `json:"classifyOnly,omitempty"`
+	FailFast                  bool                `json:"failFast,omitempty"`
+	PreTransforms             int                 `json:"preTransforms,omitempty"`
+	PruneTracking             map[string][]string `json:"pruneTracking,omitempty"`
+}
+
+// Effective returns the options as recorded in results, resolving unset options to their defaults
+func (o *Options) Effective() *EffectiveOptions {
+	if o == nil {
+		o = DefaultOptions()
+	}
+	filterOption := o.FilterOption
+	if filterOption == nil {
+		filterOption = filter.DefaultOption()
+	}
+	effective := &EffectiveOptions{
+		ExcludeKinds:              filterOption.ExcludeKinds,
+		LabelSelector:             filterOption.LabelSelector,
+		AnnotationSelector:        filterOption.AnnotationSelector,
+		ExcludeLabelSelector:      filterOption.ExcludeLabelSelector,
+		ExcludeAnnotationSelector: filterOption.ExcludeAnnotationSelector,
+		DisableIgnoreAnnotation:   filterOption.DisableIgnoreAnnotation,
+		Context:                   o.Context,
+		DisableMaskingSecrets:     o.DisableMaskingSecrets,
+		DisableMaskingFor:         o.DisableMaskingFor,
+		SecretPolicies:            o.SecretPolicies,
+		MaskScope:                 o.MaskScope,
+		MaskStrategy:              o.MaskStrategy,
+		MaskKeySet:                o.MaskKey != "",
+		MaskToken:                 o.MaskToken,
+		MaskMinLength:             o.MaskMinLength,
+		MinimumChangedLines:       o.MinimumChangedLines,
+		CustomKeyFunc:             o.KeyFunc != nil,
+		Only:                      o.Only,
+		DiffStyle:                 o.DiffStyle,
+		UseLastApplied:            o.UseLastApplied,
+		FieldManager:              o.FieldManager,
+		ExpandBelowLines:          o.ExpandBelowLines,
+		FullObjects:               o.FullObjects,
+		UnnamedMatching:           o.UnnamedMatching,
+		RenameThreshold:           o.RenameThreshold,
+		IgnoreEOL:                 o.IgnoreEOL,
+		IgnoreValueRegexes:        o.IgnoreValueRegexes,
+		SummarizeStatus:           o.SummarizeStatus,
+		SummarizeSealedSecrets:    o.SummarizeSealedSecrets,
+		ContinueOnError:           o.ContinueOnError,
+		ClassifyOnly:              o.ClassifyOnly,
+		FailFast:                  o.FailFast,
+		PreTransforms:             len(o.PreTransform),
+		PruneTracking:             o.PruneTracking,
+	}
+	if filterOption.Expression != nil {
+		effective.FilterExpression = filterOption.Expression.String()
+	}
+	if o.MaskScope == "" {
+		effective.MaskScope = MaskScopeGlobal
+	}
+	if o.MaskStrategy == "" {
+		effective.MaskStrategy = masking.StrategyIncremental
+	}
+	if o.DiffStyle == "" {
+		effective.DiffStyle = DiffStyleUnified
+	}
+	if o.Severity != nil {
+		effective.SeverityRules = len(o.Severity.Rules)
+	}
+	if o.Owners != nil {
+		effective.OwnerRules = len(o.Owners.Rules)
+	}
+	if o.Conversions != nil {
+		for _, rule := range o.Conversions.Rules {
+			effective.Conversions = append(effective.Conversions, effectiveConversion(rule))
+		}
+	}
+	for kind := range o.KindNormalizers {
+		effective.NormalizedKinds = append(effective.NormalizedKinds, kind)
+	}
+	sort.Strings(effective.NormalizedKinds)
+	for _, mapping := range o.NameMappings {
+		effective.NameMappings = append(effective.NameMappings, fmt.Sprintf("s/%s/%s/", mapping.Pattern, mapping.Replacement))
+	}
+	if o.Workloads != nil {
+		for _, kind := range o.Workloads.Kinds {
+			effective.Workloads = append(effective.Workloads, kind.Group+"/"+kind.Kind)
+		}
+	}
+	return effective
+}
+
+// effectiveConversion describes a conversion rule with its field moves, a dropped field moving to "-"
+func effectiveConversion(rule ConversionRule) string {
+	conversion := fmt.Sprintf("%s/%s %s -> %s", rule.Group, rule.Kind, rule.From, rule.To)
+	if len(rule.Fields) == 0 {
+		return conversion
+	}
+	moves := make([]string, 0, len(rule.Fields))
+	for _, field := range rule.Fields {
+		to := field.To
+		if to == "" {
+			to = "-"
+		}
+		moves = append(moves, field.From+" -> "+to)
+	}
+	return conversion + " (" + strings.Join(moves, ", ") + ")"
+}
+
+// Header returns the options as a "# options: {...}" comment line of compact JSON, preceding text output
+func (e *EffectiveOptions) Header() (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode options: %w", err)
+	}
+	return optionsHeading + string(data) + "\n", nil
+}
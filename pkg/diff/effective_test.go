@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+)
+
+func TestOptions_Effective(t *testing.T) {
+	opts := NewOptions().
+		WithExcludeKinds("Secret").
+		WithMaskStrategy(masking.StrategyHash).
+		WithMaskKey("s3cr3t").
+		WithKindNormalizers(KindNormalizers{"apps/Deployment": nil, "/Service": nil}).
+		WithNameMappings(StripNamePrefix("staging-")).
+		WithConversions(&ConversionPolicy{Rules: []ConversionRule{
+			{Group: "example.com", Kind: "Widget", From: "v1alpha1", To: "v1"},
+			{Group: "example.com", Kind: "Gadget", From: "v1beta1", To: "v1", Fields: []FieldMapping{{From: "spec.size", To: "spec.replicas"}, {From: "spec.legacy"}}},
+		}}).
+		WithFieldManager("argocd")
+
+	effective := opts.Effective()
+	assert.Equal(t, []string{"Secret"}, effective.ExcludeKinds)
+	assert.Equal(t, 3, effective.Context)
+	assert.Equal(t, masking.StrategyHash, effective.MaskStrategy)
+	assert.True(t, effective.MaskKeySet)
+	assert.Equal(t, []string{"/Service", "apps/Deployment"}, effective.NormalizedKinds)
+	assert.Equal(t, []string{"s/^staging-//"}, effective.NameMappings)
+	assert.Equal(t, []string{"example.com/Widget v1alpha1 -> v1", "example.com/Gadget v1beta1 -> v1 (spec.size -> spec.replicas, spec.legacy -> -)"}, effective.Conversions)
+	assert.Equal(t, "argocd", effective.FieldManager)
+
+	header, err := effective.Header()
+	require.NoError(t, err)
+	assert.Regexp(t, `^# options: \{"excludeKinds":\["Secret"\],"context":3,.*\}\n$`, header)
+	assert.NotContains(t, header, "s3cr3t")
+}
+
+func TestOptions_EffectiveDefaults(t *testing.T) {
+	var opts *Options
+	effective := opts.Effective()
+	assert.Equal(t, MaskScopeGlobal, effective.MaskScope)
+	assert.Equal(t, masking.StrategyIncremental, effective.MaskStrategy)
+	assert.Equal(t, DiffStyleUnified, effective.DiffStyle)
+	assert.False(t, effective.MaskKeySet)
+}
+
+func TestOptions_EffectiveRecordsEveryField(t *testing.T) {
+	// Options fields recorded under another name, and fields that do not change results
+	recordedAs := map[string]string{
+		"MaskKey":         "MaskKeySet",
+		"KeyFunc":         "CustomKeyFunc",
+		"Severity":        "SeverityRules",
+		"Owners":          "OwnerRules",
+		"KindNormalizers": "NormalizedKinds",
+		"PreTransform":    "PreTransforms",
+		"Expression":      "FilterExpression",
+	}
+	notRecorded := map[string]bool{
+		"FilterOption":  true, // flattened, see below
+		"CacheDir":      true,
+		"MaskingAudit":  true,
+		"OnResult":      true,
+		"HeadSources":   true,
+		"RetainObjects": true,
+	}
+
+	effectiveType := reflect.TypeOf(EffectiveOptions{})
+	for _, optionsType := range []reflect.Type{reflect.TypeOf(Options{}), reflect.TypeOf(filter.Option{})} {
+		for i := 0; i < optionsType.NumField(); i++ {
+			name := optionsType.Field(i).Name
+			if notRecorded[name] {
+				continue
+			}
+			if recorded, ok := recordedAs[name]; ok {
+				name = recorded
+			}
+			_, ok := effectiveType.FieldByName(name)
+			assert.True(t, ok, "%s.%s is not recorded in EffectiveOptions", optionsType.Name(), optionsType.Field(i).Name)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package diff
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// normalizeLineEndings returns copies of the base and head objects of a resource with CRLF line
+// endings in string values, e.g. ConfigMap data generated from files edited on Windows, replaced by LF
+func normalizeLineEndings(v objBaseHead) objBaseHead {
+	for _, obj := range []**unstructured.Unstructured{&v.base, &v.head} {
+		if *obj != nil {
+			normalized := (*obj).DeepCopy()
+			normalized.Object = normalizeValueLineEndings(normalized.Object).(map[string]any)
+			*obj = normalized
+		}
+	}
+	return v
+}
+
+// normalizeValueLineEndings replaces CRLF by LF in the strings of value, descending into maps and lists
+func normalizeValueLineEndings(value any) any {
+	switch value := value.(type) {
+	case string:
+		return strings.ReplaceAll(value, "\r\n", "\n")
+	case map[string]any:
+		for k, v := range value {
+			value[k] = normalizeValueLineEndings(v)
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = normalizeValueLineEndings(v)
+		}
+		return value
+	default:
+		return value
+	}
+}
@@ -0,0 +1,40 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjects_IgnoreEOL(t *testing.T) {
+	key := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "settings"}
+	base := []*unstructured.Unstructured{newConfigMap("settings", "default", "line one\nline two\n")}
+	head := []*unstructured.Unstructured{newConfigMap("settings", "default", "line one\r\nline two\r\n")}
+
+	t.Run("disabled", func(t *testing.T) {
+		results, err := Objects(base, head, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.IgnoreEOL = true
+		results, err := Objects(base, head, opts)
+		require.NoError(t, err)
+		assert.Equal(t, Unchanged, results[key].Type)
+		assert.Equal(t, "line one\r\nline two\r\n", head[0].Object["data"].(map[string]any)["key"])
+	})
+
+	t.Run("other changes are kept", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.IgnoreEOL = true
+		changed := []*unstructured.Unstructured{newConfigMap("settings", "default", "line one\r\nline 2\r\n")}
+		results, err := Objects(base, changed, opts)
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+		assert.Contains(t, results[key].Diff, "line 2")
+	})
+}
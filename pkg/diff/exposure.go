@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// exposedServiceTypes are the Service types reachable from outside the cluster
+var exposedServiceTypes = map[string]bool{"NodePort": true, "LoadBalancer": true}
+
+// hostPaths lists where the host names routed to a resource are, by "group/Kind"
+var hostPaths = map[string][]string{
+	"networking.k8s.io/Ingress":           {"spec", "rules", "host"},
+	"gateway.networking.k8s.io/HTTPRoute": {"spec", "hostnames"},
+	"gateway.networking.k8s.io/GRPCRoute": {"spec", "hostnames"},
+	"gateway.networking.k8s.io/TLSRoute":  {"spec", "hostnames"},
+	"route.openshift.io/Route":            {"spec", "host"},
+}
+
+// ExposureChanges describes changes to how a resource is exposed outside the cluster:
+// Service types becoming or ceasing to be NodePort or LoadBalancer, e.g. "service type ClusterIP -> LoadBalancer",
+// and host names routed by Ingresses, Gateway API routes and OpenShift Routes, e.g. "host www.example.com added".
+// base is nil for created and head for deleted resources.
+func ExposureChanges(base, head *unstructured.Unstructured) []string {
+	obj := head
+	if obj == nil {
+		obj = base
+	}
+	if obj == nil {
+		return nil
+	}
+	gvk := obj.GroupVersionKind()
+	kind := gvk.Group + "/" + gvk.Kind
+	if kind == "/Service" {
+		return serviceTypeChanges(base, head)
+	}
+	path, ok := hostPaths[kind]
+	if !ok {
+		return nil
+	}
+
+	baseHosts, headHosts := routedHosts(base, path), routedHosts(head, path)
+	hosts := make([]string, 0, len(baseHosts)+len(headHosts))
+	for host := range baseHosts {
+		if !headHosts[host] {
+			hosts = append(hosts, host)
+		}
+	}
+	for host := range headHosts {
+		if !baseHosts[host] {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	var changes []string
+	for _, host := range hosts {
+		if headHosts[host] {
+			changes = append(changes, fmt.Sprintf("host %s added", host))
+		} else {
+			changes = append(changes, fmt.Sprintf("host %s removed", host))
+		}
+	}
+	return changes
+}
+
+// serviceTypeChanges describes a Service type change from or to a type exposed outside the cluster
+func serviceTypeChanges(base, head *unstructured.Unstructured) []string {
+	baseType, headType := serviceType(base), serviceType(head)
+	if baseType == headType || (!exposedServiceTypes[baseType] && !exposedServiceTypes[headType]) {
+		return nil
+	}
+	switch {
+	case base == nil:
+		return []string{fmt.Sprintf("service type %s added", headType)}
+	case head == nil:
+		return []string{fmt.Sprintf("service type %s removed", baseType)}
+	default:
+		return []string{fmt.Sprintf("service type %s -> %s", baseType, headType)}
+	}
+}
+
+// serviceType returns the type of a Service, defaulting to ClusterIP, or "" if obj is nil
+func serviceType(obj *unstructured.Unstructured) string {
+	if obj == nil {
+		return ""
+	}
+	if serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type"); serviceType != "" {
+		return serviceType
+	}
+	return "ClusterIP"
+}
+
+// routedHosts returns the host names at path in obj, descending into lists along the way
+func routedHosts(obj *unstructured.Unstructured, path []string) map[string]bool {
+	hosts := make(map[string]bool)
+	if obj == nil {
+		return hosts
+	}
+	collectHosts(obj.Object, path, hosts)
+	return hosts
+}
+
+// collectHosts adds the strings at path in value to hosts
+func collectHosts(value any, path []string, hosts map[string]bool) {
+	switch value := value.(type) {
+	case []any:
+		for _, item := range value {
+			collectHosts(item, path, hosts)
+		}
+	case map[string]any:
+		if len(path) == 0 {
+			return
+		}
+		collectHosts(value[path[0]], path[1:], hosts)
+	case string:
+		if len(path) == 0 {
+			hosts[value] = true
+		}
+	}
+}
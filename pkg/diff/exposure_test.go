@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newService returns a Service of the given type, left unset when empty
+func newService(name, serviceType string) *unstructured.Unstructured {
+	spec := map[string]any{"ports": []any{map[string]any{"port": int64(80)}}}
+	if serviceType != "" {
+		spec["type"] = serviceType
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": name, "namespace": "default"},
+		"spec":       spec,
+	}}
+}
+
+// newIngress returns an Ingress with a rule for each of hosts
+func newIngress(name string, hosts ...string) *unstructured.Unstructured {
+	rules := make([]any, 0, len(hosts))
+	for _, host := range hosts {
+		rules = append(rules, map[string]any{"host": host})
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata":   map[string]any{"name": name, "namespace": "default"},
+		"spec":       map[string]any{"rules": rules},
+	}}
+}
+
+func TestExposureChanges(t *testing.T) {
+	httpRoute := func(hostnames ...any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "HTTPRoute",
+			"metadata":   map[string]any{"name": "web", "namespace": "default"},
+			"spec":       map[string]any{"hostnames": hostnames},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		base     *unstructured.Unstructured
+		head     *unstructured.Unstructured
+		expected []string
+	}{
+		{
+			name:     "service exposed",
+			base:     newService("web", ""),
+			head:     newService("web", "LoadBalancer"),
+			expected: []string{"service type ClusterIP -> LoadBalancer"},
+		},
+		{
+			name:     "internal service type change",
+			base:     newService("web", "ClusterIP"),
+			head:     newService("web", "ExternalName"),
+			expected: nil,
+		},
+		{
+			name:     "created node port service",
+			base:     nil,
+			head:     newService("web", "NodePort"),
+			expected: []string{"service type NodePort added"},
+		},
+		{
+			name:     "ingress hosts",
+			base:     newIngress("web", "old.example.com", "www.example.com"),
+			head:     newIngress("web", "www.example.com", "api.example.com"),
+			expected: []string{"host api.example.com added", "host old.example.com removed"},
+		},
+		{
+			name:     "deleted ingress",
+			base:     newIngress("web", "www.example.com"),
+			head:     nil,
+			expected: []string{"host www.example.com removed"},
+		},
+		{
+			name:     "http route hostnames",
+			base:     httpRoute("www.example.com"),
+			head:     httpRoute("www.example.com", "*.example.com"),
+			expected: []string{"host *.example.com added"},
+		},
+		{
+			name:     "other kinds",
+			base:     newConfigMap("web", "default", "a"),
+			head:     newConfigMap("web", "default", "b"),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExposureChanges(tt.base, tt.head))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// secretSourceChangesHeading introduces the changed secret sources of an ExternalSecret or Vault-injected workload
+const secretSourceChangesHeading = "# Secret source changes:\n"
+
+// vaultSecretAnnotationPrefix prefixes the Vault Agent Injector annotations naming the path of an injected secret
+const vaultSecretAnnotationPrefix = "vault.hashicorp.com/agent-inject-secret-"
+
+// vaultAnnotationPaths are the annotations of objects and their pod templates Vault Agent Injector annotations are read from
+var vaultAnnotationPaths = [][]string{
+	{"metadata", "annotations"},
+	{"spec", "template", "metadata", "annotations"},
+	{"spec", "jobTemplate", "spec", "template", "metadata", "annotations"},
+}
+
+// SecretSourceChanges describes changes to where secrets are fetched from, without any secret value: the store
+// and remote references of an ExternalSecret, e.g. "password: db/creds#password -> db/creds#password@2", and the
+// paths of secrets injected by Vault Agent Injector annotations, e.g. "vault db: database/creds/old -> database/creds/new".
+// It returns nil unless the resource exists on both sides.
+func SecretSourceChanges(base, head *unstructured.Unstructured) []string {
+	if base == nil || head == nil {
+		return nil
+	}
+	var changes []string
+	if masking.IsExternalSecret(base) && masking.IsExternalSecret(head) {
+		if from, to := externalSecretStore(base), externalSecretStore(head); from != to {
+			changes = append(changes, fmt.Sprintf("secret store: %s -> %s", from, to))
+		}
+		changes = append(changes, sourceChanges("", externalSecretSources(base), externalSecretSources(head))...)
+	}
+	return append(changes, sourceChanges("vault ", vaultSecretPaths(base), vaultSecretPaths(head))...)
+}
+
+// sourceChanges describes the sources added, removed or changed between base and head, sorted by name
+func sourceChanges(prefix string, base, head map[string]string) []string {
+	names := make([]string, 0, len(base)+len(head))
+	for name := range base {
+		names = append(names, name)
+	}
+	for name := range head {
+		if _, ok := base[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		from, inBase := base[name]
+		to, inHead := head[name]
+		switch {
+		case !inBase:
+			changes = append(changes, fmt.Sprintf("%s%s: added (%s)", prefix, name, to))
+		case !inHead:
+			changes = append(changes, fmt.Sprintf("%s%s: removed (%s)", prefix, name, from))
+		case from != to:
+			changes = append(changes, fmt.Sprintf("%s%s: %s -> %s", prefix, name, from, to))
+		}
+	}
+	return changes
+}
+
+// externalSecretStore returns the store an ExternalSecret fetches from as "Kind/name"
+func externalSecretStore(obj *unstructured.Unstructured) string {
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "secretStoreRef", "kind")
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "secretStoreRef", "name")
+	if kind == "" {
+		kind = "SecretStore"
+	}
+	return kind + "/" + name
+}
+
+// externalSecretSources returns the remote references of an ExternalSecret: those of data by secret key,
+// and those of dataFrom by position, e.g. "dataFrom[0]"
+func externalSecretSources(obj *unstructured.Unstructured) map[string]string {
+	sources := make(map[string]string)
+	data, _, _ := unstructured.NestedSlice(obj.Object, "spec", "data")
+	for _, item := range data {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		secretKey, _ := entry["secretKey"].(string)
+		remoteRef, _ := entry["remoteRef"].(map[string]any)
+		sources[secretKey] = remoteRefString(remoteRef)
+	}
+	dataFrom, _, _ := unstructured.NestedSlice(obj.Object, "spec", "dataFrom")
+	for i, item := range dataFrom {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name := fmt.Sprintf("dataFrom[%d]", i)
+		switch {
+		case entry["extract"] != nil:
+			extract, _ := entry["extract"].(map[string]any)
+			sources[name] = "extract " + remoteRefString(extract)
+		default:
+			// find and generator references are compared as compact JSON
+			encoded, _ := json.Marshal(entry)
+			sources[name] = string(encoded)
+		}
+	}
+	return sources
+}
+
+// remoteRefString formats a remote reference as "key#property@version", omitting unset parts
+func remoteRefString(ref map[string]any) string {
+	key, _ := ref["key"].(string)
+	var b strings.Builder
+	b.WriteString(key)
+	if property, _ := ref["property"].(string); property != "" {
+		b.WriteString("#" + property)
+	}
+	if version, _ := ref["version"].(string); version != "" {
+		b.WriteString("@" + version)
+	}
+	return b.String()
+}
+
+// vaultSecretPaths returns the paths of the secrets Vault Agent Injector annotations of obj inject, by secret name
+func vaultSecretPaths(obj *unstructured.Unstructured) map[string]string {
+	paths := make(map[string]string)
+	for _, path := range vaultAnnotationPaths {
+		annotations, _, _ := unstructured.NestedStringMap(obj.Object, path...)
+		for annotation, value := range annotations {
+			if name, ok := strings.CutPrefix(annotation, vaultSecretAnnotationPrefix); ok {
+				paths[name] = value
+			}
+		}
+	}
+	return paths
+}
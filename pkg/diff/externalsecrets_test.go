@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretSourceChanges_ExternalSecret(t *testing.T) {
+	base := `apiVersion: external-secrets.io/v1beta1
+kind: ExternalSecret
+metadata:
+  name: db
+  namespace: default
+spec:
+  secretStoreRef:
+    name: vault
+  target:
+    template:
+      data:
+        url: postgres://app:{{ .password }}@db:5432/app
+        apiKey: sk-live-1234
+  data:
+  - secretKey: password
+    remoteRef:
+      key: db/creds
+      property: password
+  - secretKey: user
+    remoteRef:
+      key: db/creds
+      property: user
+  dataFrom:
+  - extract:
+      key: db/extra
+`
+	head := `apiVersion: external-secrets.io/v1beta1
+kind: ExternalSecret
+metadata:
+  name: db
+  namespace: default
+spec:
+  secretStoreRef:
+    kind: ClusterSecretStore
+    name: vault
+  target:
+    template:
+      data:
+        url: postgres://app:{{ .password }}@db:5432/app
+        apiKey: sk-live-5678
+  data:
+  - secretKey: password
+    remoteRef:
+      key: db/creds
+      property: password
+      version: "2"
+  - secretKey: token
+    remoteRef:
+      key: api/token
+  dataFrom:
+  - extract:
+      key: db/extra
+`
+	results, err := YamlString(base, head, DefaultOptions())
+	require.NoError(t, err)
+	result := results[ResourceKey{Group: "external-secrets.io", Kind: "ExternalSecret", Namespace: "default", Name: "db"}]
+	assert.Equal(t, []string{
+		"secret store: SecretStore/vault -> ClusterSecretStore/vault",
+		"password: db/creds#password -> db/creds#password@2",
+		"token: added (api/token)",
+		"user: removed (db/creds#user)",
+	}, result.SecretSourceChanges)
+	assert.Contains(t, result.Diff, "# Secret source changes:\n#   secret store: SecretStore/vault -> ClusterSecretStore/vault\n")
+	assert.NotContains(t, result.Diff, "sk-live")
+	assert.Contains(t, result.Diff, "{{ .password }}", "templates are shown")
+}
+
+func TestSecretSourceChanges_Vault(t *testing.T) {
+	deployment := func(path string) string {
+		return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  template:
+    metadata:
+      annotations:
+        vault.hashicorp.com/agent-inject: "true"
+        vault.hashicorp.com/agent-inject-secret-db: ` + path + `
+`
+	}
+	results, err := YamlString(deployment("database/creds/old"), deployment("database/creds/new"), DefaultOptions())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vault db: database/creds/old -> database/creds/new"},
+		results[ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "app"}].SecretSourceChanges)
+}
@@ -0,0 +1,289 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fieldSet is a decoded FieldsV1 tree from metadata.managedFields, e.g. {"f:spec": {"f:replicas": {}}}
+type fieldSet map[string]any
+
+// managedFieldSets returns the fields owned by the given manager and the fields owned by every other manager.
+// The third return value is false when obj has no managedFields.
+func managedFieldSets(obj *unstructured.Unstructured, manager string) (fieldSet, fieldSet, bool) {
+	entries, found, err := unstructured.NestedSlice(obj.Object, "metadata", "managedFields")
+	if err != nil || !found || len(entries) == 0 {
+		return nil, nil, false
+	}
+	mine, others := fieldSet{}, fieldSet{}
+	for _, entry := range entries {
+		e, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		fields, ok := e["fieldsV1"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, _ := e["manager"].(string); name == manager {
+			mergeFieldSets(mine, fields)
+		} else {
+			mergeFieldSets(others, fields)
+		}
+	}
+	return mine, others, true
+}
+
+// mergeFieldSets adds every field in src to dst
+func mergeFieldSets(dst, src fieldSet) {
+	for k, v := range src {
+		child, _ := v.(map[string]any)
+		existing, ok := dst[k].(fieldSet)
+		if !ok {
+			existing = fieldSet{}
+			dst[k] = existing
+		}
+		mergeFieldSets(existing, child)
+	}
+}
+
+// isLeaf reports whether the field set owns a value as a whole rather than some of its children
+func (fs fieldSet) isLeaf() bool {
+	for k := range fs {
+		if k != "." {
+			return false
+		}
+	}
+	return true
+}
+
+// child returns the field set of a nested key, if present
+func (fs fieldSet) child(key string) (fieldSet, bool) {
+	if fs == nil {
+		return nil, false
+	}
+	c, ok := fs[key]
+	if !ok {
+		return nil, false
+	}
+	set, _ := c.(fieldSet)
+	if set == nil {
+		set = fieldSet{}
+	}
+	return set, true
+}
+
+// elementSet returns the field set of the list element at index, if any
+func (fs fieldSet) elementSet(elem any, index int) (fieldSet, bool) {
+	for key := range fs {
+		switch {
+		case strings.HasPrefix(key, "k:"):
+			var keys map[string]any
+			if err := json.Unmarshal([]byte(key[2:]), &keys); err != nil {
+				continue
+			}
+			if m, ok := elem.(map[string]any); ok && matchesElementKeys(m, keys) {
+				return fs.child(key)
+			}
+		case strings.HasPrefix(key, "v:"):
+			if jsonEqual(elem, key[2:]) {
+				return fs.child(key)
+			}
+		case strings.HasPrefix(key, "i:"):
+			if i, err := strconv.Atoi(key[2:]); err == nil && i == index {
+				return fs.child(key)
+			}
+		}
+	}
+	return nil, false
+}
+
+// matchesElementKeys reports whether a list element has all the given key field values
+func matchesElementKeys(elem, keys map[string]any) bool {
+	for k, v := range keys {
+		encoded, err := json.Marshal(v)
+		if err != nil || !jsonEqual(elem[k], string(encoded)) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual reports whether value encodes to the same JSON as the encoded text
+func jsonEqual(value any, encoded string) bool {
+	var want any
+	if err := json.Unmarshal([]byte(encoded), &want); err != nil {
+		return false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	var got any
+	if err := json.Unmarshal(data, &got); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// ownedValue returns the parts of value owned by the field set, and false if nothing is owned
+func ownedValue(value any, owned fieldSet) (any, bool) {
+	if owned.isLeaf() {
+		return value, true
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		result := map[string]any{}
+		for key, child := range v {
+			set, ok := owned.child("f:" + key)
+			if !ok {
+				continue
+			}
+			if pruned, ok := ownedValue(child, set); ok {
+				result[key] = pruned
+			}
+		}
+		return result, len(result) > 0 || owned["."] != nil
+	case []any:
+		var result []any
+		for i, elem := range v {
+			set, ok := owned.elementSet(elem, i)
+			if !ok {
+				continue
+			}
+			pruned, _ := ownedValue(elem, set)
+			// Associative list keys identify the element and are kept even when owned implicitly
+			if m, isMap := elem.(map[string]any); isMap {
+				if p, isPrunedMap := pruned.(map[string]any); isPrunedMap {
+					restoreElementKeys(p, m, owned)
+				}
+			}
+			result = append(result, pruned)
+		}
+		return result, len(result) > 0
+	default:
+		return value, true
+	}
+}
+
+// restoreElementKeys copies the identifying key fields of an associative list element into its pruned copy
+func restoreElementKeys(pruned, elem map[string]any, owned fieldSet) {
+	for key := range owned {
+		if !strings.HasPrefix(key, "k:") {
+			continue
+		}
+		var keys map[string]any
+		if err := json.Unmarshal([]byte(key[2:]), &keys); err != nil || !matchesElementKeys(elem, keys) {
+			continue
+		}
+		for k := range keys {
+			pruned[k] = elem[k]
+		}
+		return
+	}
+}
+
+// withoutForeignFields removes from value the fields owned by other managers that the given manager does not own
+func withoutForeignFields(value any, others, mine fieldSet) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, child := range v {
+			other, ok := others.child("f:" + key)
+			if !ok {
+				result[key] = child
+				continue
+			}
+			own, owned := mine.child("f:" + key)
+			switch {
+			case owned && own.isLeaf():
+				result[key] = child
+			case other.isLeaf() && !owned:
+				// Owned entirely by another manager
+			default:
+				if pruned := withoutForeignFields(child, other, own); !isEmptyValue(pruned) || isEmptyValue(child) {
+					result[key] = pruned
+				}
+			}
+		}
+		return result
+	case []any:
+		result := make([]any, 0, len(v))
+		for i, elem := range v {
+			other, ok := others.elementSet(elem, i)
+			if !ok {
+				result = append(result, elem)
+				continue
+			}
+			own, owned := mine.elementSet(elem, i)
+			switch {
+			case owned && own.isLeaf():
+				result = append(result, elem)
+			case other.isLeaf() && !owned:
+				// Element owned entirely by another manager
+			default:
+				if pruned := withoutForeignFields(elem, other, own); !isEmptyValue(pruned) || isEmptyValue(elem) {
+					result = append(result, pruned)
+				}
+			}
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// isEmptyValue reports whether value is an empty map or list
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// restrictToFieldManager limits a live base object to the fields owned by manager,
+// and removes from head the fields that only other managers own, so changes made
+// by other controllers do not show up as differences. Objects without managedFields are kept as they are.
+func restrictToFieldManager(v objBaseHead, manager string) (objBaseHead, error) {
+	if v.base == nil {
+		return v, nil
+	}
+	mine, others, ok := managedFieldSets(v.base, manager)
+	if !ok {
+		return v, nil
+	}
+
+	// An empty set would otherwise read as owning the whole object
+	ownedObj := map[string]any{}
+	if len(mine) > 0 {
+		owned, _ := ownedValue(v.base.DeepCopy().Object, mine)
+		if ownedObj, ok = owned.(map[string]any); !ok {
+			return v, fmt.Errorf("failed to restrict %s/%s to fields of manager %s", v.base.GetKind(), v.base.GetName(), manager)
+		}
+	}
+	base := &unstructured.Unstructured{Object: ownedObj}
+	// Identity fields are kept so that the diff header and pairing stay intact
+	base.SetAPIVersion(v.base.GetAPIVersion())
+	base.SetKind(v.base.GetKind())
+	base.SetName(v.base.GetName())
+	if ns := v.base.GetNamespace(); ns != "" {
+		base.SetNamespace(ns)
+	}
+	v.base = base
+
+	if v.head != nil {
+		if head, ok := withoutForeignFields(v.head.Object, others, mine).(map[string]any); ok {
+			v.head = &unstructured.Unstructured{Object: head}
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const managedDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  uid: 0c7c1f6e-1b0a-4b4e-9d7c-1f2a3b4c5d6e
+  managedFields:
+  - manager: argocd
+    operation: Apply
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:metadata:
+        f:labels:
+          f:app: {}
+      f:spec:
+        f:selector: {}
+        f:template:
+          f:metadata:
+            f:labels:
+              f:app: {}
+          f:spec:
+            f:containers:
+              k:{"name":"web"}:
+                .: {}
+                f:image: {}
+                f:name: {}
+  - manager: kube-controller-manager
+    operation: Update
+    subresource: scale
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:spec:
+        f:replicas: {}
+  - manager: kube-controller-manager
+    operation: Update
+    subresource: status
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:status:
+        f:readyReplicas: {}
+  labels:
+    app: web
+spec:
+  replicas: 5
+  progressDeadlineSeconds: 600
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+        imagePullPolicy: IfNotPresent
+status:
+  readyReplicas: 5
+`
+
+func TestObjects_FieldManager(t *testing.T) {
+	head := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: %s
+`
+	key := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}
+
+	tests := []struct {
+		name     string
+		manager  string
+		image    string
+		expected ChangeType
+	}{
+		{name: "without restriction", manager: "", image: "nginx:1.25", expected: Changed},
+		{name: "replicas owned by the autoscaler", manager: "argocd", image: "nginx:1.25", expected: Unchanged},
+		{name: "owned field changed", manager: "argocd", image: "nginx:1.26", expected: Changed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.FieldManager = tt.manager
+			results, err := YamlString(managedDeployment, fmt.Sprintf(head, tt.image), opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, results[key].Type, results[key].Diff)
+			if tt.manager != "" && tt.expected == Changed {
+				assert.Contains(t, results[key].Diff, "image: nginx:1.26")
+				assert.NotContains(t, results[key].Diff, "replicas")
+				assert.NotContains(t, results[key].Diff, "managedFields")
+			}
+		})
+	}
+
+	t.Run("fields of other managers are ignored", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.FieldManager = "helm"
+		results, err := YamlString(managedDeployment, fmt.Sprintf(head, "nginx:1.25"), opts)
+		require.NoError(t, err)
+		assert.Equal(t, Unchanged, results[key].Type, results[key].Diff)
+	})
+
+	t.Run("cannot be combined with last-applied", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.FieldManager = "argocd"
+		opts.UseLastApplied = true
+		_, err := YamlString(managedDeployment, fmt.Sprintf(head, "nginx:1.25"), opts)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FrontMatterFormat is the encoding of the metadata block prepended to Markdown output
+type FrontMatterFormat string
+
+const (
+	// FrontMatterJSON encodes the metadata as a JSON object
+	FrontMatterJSON FrontMatterFormat = "json"
+	// FrontMatterYAML encodes the metadata as YAML
+	FrontMatterYAML FrontMatterFormat = "yaml"
+)
+
+// frontMatterMarker opens the HTML comment holding the front matter, so that bots can find it and renderers hide it
+const frontMatterMarker = "<!-- k8s-manifest-diff"
+
+// ParseFrontMatterFormat parses a front-matter format name: json or yaml
+func ParseFrontMatterFormat(s string) (FrontMatterFormat, error) {
+	switch format := FrontMatterFormat(s); format {
+	case FrontMatterJSON, FrontMatterYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown front-matter format: %s (supported: json, yaml)", s)
+	}
+}
+
+// High-risk flags of the front matter
+const (
+	riskReplacement  = "replacement"   // a resource is replaced because an immutable field changed
+	riskDeletion     = "deletion"      // a resource is deleted
+	riskRBAC         = "rbac"          // a Role, ClusterRole or binding changed
+	riskExposure     = "exposure"      // a resource is exposed outside the cluster differently
+	riskHighSeverity = "high-severity" // a resource with severity high changed
+)
+
+// FrontMatter is the machine-readable metadata of a diff, for bots parsing the Markdown they posted
+type FrontMatter struct {
+	Total     int      `json:"total" yaml:"total"`
+	Changed   int      `json:"changed" yaml:"changed"`
+	Created   int      `json:"created" yaml:"created"`
+	Deleted   int      `json:"deleted" yaml:"deleted"`
+	Unchanged int      `json:"unchanged" yaml:"unchanged"`
+	Errors    int      `json:"errors,omitempty" yaml:"errors,omitempty"`   // Resources that failed to diff with Options.ContinueOnError
+	Severity  string   `json:"severity" yaml:"severity"`                   // Highest severity among changes ("none" when not assessed)
+	HighRisk  []string `json:"highRisk" yaml:"highRisk"`                   // Sorted high-risk flags: deletion, exposure, high-severity, rbac, replacement
+	Resources []string `json:"highRiskResources" yaml:"highRiskResources"` // Sorted resources raising a high-risk flag
+}
+
+// FrontMatter returns the metadata of the results
+func (dr Results) FrontMatter() FrontMatter {
+	stats := dr.GetStatistics()
+	fm := FrontMatter{
+		Total:     stats.Total,
+		Changed:   stats.Changed,
+		Created:   stats.Created,
+		Deleted:   stats.Deleted,
+		Unchanged: stats.Unchanged,
+		Errors:    stats.Errors,
+		Severity:  dr.MaxSeverity().String(),
+		HighRisk:  []string{},
+		Resources: []string{},
+	}
+
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+	flags := make(map[string]bool)
+	for _, key := range keys {
+		diffResult := dr[key]
+		if diffResult.Type == Unchanged {
+			continue
+		}
+		risks := diffResult.risks(key)
+		for _, risk := range risks {
+			flags[risk] = true
+		}
+		if len(risks) > 0 {
+			fm.Resources = append(fm.Resources, formatResourceKeyShort(key))
+		}
+	}
+	for flag := range flags {
+		fm.HighRisk = append(fm.HighRisk, flag)
+	}
+	sort.Strings(fm.HighRisk)
+	return fm
+}
+
+// risks returns the high-risk flags raised by a changed resource
+func (dr Result) risks(key ResourceKey) []string {
+	var risks []string
+	if len(dr.ImmutableChanges) > 0 {
+		risks = append(risks, riskReplacement)
+	}
+	if dr.Type == Deleted {
+		risks = append(risks, riskDeletion)
+	}
+	if rbacKinds[key.Group+"/"+key.Kind] {
+		risks = append(risks, riskRBAC)
+	}
+	if len(dr.ExposureChanges) > 0 {
+		risks = append(risks, riskExposure)
+	}
+	if dr.Severity == SeverityHigh {
+		risks = append(risks, riskHighSeverity)
+	}
+	return risks
+}
+
+// Render returns the front matter as a block to prepend to Markdown, ending with a blank line. The metadata is
+// written inside an HTML comment so that it is not shown when the Markdown is rendered.
+func (fm FrontMatter) Render(format FrontMatterFormat) (string, error) {
+	var data []byte
+	var err error
+	switch format {
+	case FrontMatterJSON:
+		data, err = json.MarshalIndent(fm, "", "  ")
+		data = append(data, '\n')
+	case FrontMatterYAML:
+		data, err = yaml.Marshal(fm)
+	default:
+		return "", fmt.Errorf("unknown front-matter format: %s (supported: json, yaml)", format)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to encode front matter: %w", err)
+	}
+	return frontMatterMarker + "\n" + string(data) + "-->\n\n", nil
+}
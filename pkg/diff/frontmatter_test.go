@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestResults_FrontMatter(t *testing.T) {
+	results := Results{
+		{Kind: "ConfigMap", Namespace: "default", Name: "settings"}:              {Type: Changed, Severity: SeverityLow},
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}:   {Type: Changed, Severity: SeverityMedium, ImmutableChanges: []string{"spec.selector"}},
+		{Kind: "Service", Namespace: "default", Name: "web"}:                     {Type: Changed, Severity: SeverityMedium, ExposureChanges: []string{"service type ClusterIP -> LoadBalancer"}},
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "admin"}: {Type: Created, Severity: SeverityHigh},
+		{Kind: "Secret", Namespace: "default", Name: "legacy"}:                   {Type: Deleted, Severity: SeverityMedium},
+		{Kind: "ConfigMap", Namespace: "default", Name: "static"}:                {Type: Unchanged, Severity: SeverityHigh},
+	}
+
+	fm := results.FrontMatter()
+	assert.Equal(t, FrontMatter{
+		Total:     6,
+		Changed:   3,
+		Created:   1,
+		Deleted:   1,
+		Unchanged: 1,
+		Severity:  "high",
+		HighRisk:  []string{"deletion", "exposure", "high-severity", "rbac", "replacement"},
+		Resources: []string{"ClusterRole/admin", "Deployment/default/web", "Secret/default/legacy", "Service/default/web"},
+	}, fm)
+
+	t.Run("json", func(t *testing.T) {
+		block, err := fm.Render(FrontMatterJSON)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(block, "<!-- k8s-manifest-diff\n{"))
+		require.True(t, strings.HasSuffix(block, "}\n-->\n\n"))
+		var decoded FrontMatter
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(block, "<!-- k8s-manifest-diff\n"), "-->\n\n")), &decoded))
+		assert.Equal(t, fm, decoded)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		block, err := fm.Render(FrontMatterYAML)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(block, "<!-- k8s-manifest-diff\n"))
+		require.True(t, strings.HasSuffix(block, "\n-->\n\n"))
+		var decoded FrontMatter
+		require.NoError(t, yaml.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(block, "<!-- k8s-manifest-diff\n"), "-->\n\n")), &decoded))
+		assert.Equal(t, fm, decoded)
+	})
+
+	t.Run("no high-risk changes", func(t *testing.T) {
+		block, err := Results{{Kind: "ConfigMap", Name: "settings"}: {Type: Changed}}.FrontMatter().Render(FrontMatterJSON)
+		require.NoError(t, err)
+		assert.Contains(t, block, `"severity": "none"`)
+		assert.Contains(t, block, `"highRisk": []`)
+	})
+}
+
+func TestParseFrontMatterFormat(t *testing.T) {
+	format, err := ParseFrontMatterFormat("yaml")
+	require.NoError(t, err)
+	assert.Equal(t, FrontMatterYAML, format)
+
+	_, err = ParseFrontMatterFormat("toml")
+	assert.ErrorContains(t, err, "unknown front-matter format: toml")
+}
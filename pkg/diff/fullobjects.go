@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FullObjectsMode determines whether the complete base and head YAML of changed resources is rendered
+type FullObjectsMode string
+
+const (
+	// FullObjectsNone renders only the diff (default)
+	FullObjectsNone FullObjectsMode = ""
+	// FullObjectsAppend renders the complete objects after the diff
+	FullObjectsAppend FullObjectsMode = "append"
+	// FullObjectsOnly renders the complete objects instead of the diff
+	FullObjectsOnly FullObjectsMode = "only"
+)
+
+// Headings introducing each object in full-object output
+const (
+	fullObjectsBaseHeading = "# base:\n"
+	fullObjectsHeadHeading = "# head:\n"
+)
+
+// validate returns an error if the mode is not supported
+func (m FullObjectsMode) validate() error {
+	switch m {
+	case FullObjectsNone, FullObjectsAppend, FullObjectsOnly:
+		return nil
+	default:
+		return fmt.Errorf("invalid full objects mode: %s (supported: %s, %s)", m, FullObjectsAppend, FullObjectsOnly)
+	}
+}
+
+// getFullObjectsStr returns the complete masked base and head YAML, indented under "# base:" and "# head:"
+// so that list items are not mistaken for diff lines
+func getFullObjectsStr(base, head *unstructured.Unstructured, opts *Options, masker *masking.Masker) (string, error) {
+	preparedBase, preparedHead, err := prepareObjectsForDiff(base, head, opts, masker)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	for _, section := range []struct {
+		heading string
+		obj     *unstructured.Unstructured
+	}{
+		{heading: fullObjectsBaseHeading, obj: preparedBase},
+		{heading: fullObjectsHeadHeading, obj: preparedHead},
+	} {
+		data, err := yaml.Marshal(section.obj.Object)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(section.heading)
+		for _, line := range strings.Split(strings.TrimRight(parser.UnescapeUnicode(string(data)), "\n"), "\n") {
+			result.WriteString("  " + line + "\n")
+		}
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYamlString_FullObjects(t *testing.T) {
+	baseYaml := `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+data:
+  password: cGFzc3dvcmQ=
+  user: YWRtaW4=
+`
+	headYaml := strings.Replace(baseYaml, "cGFzc3dvcmQ=", "bmV3cGFzc3dvcmQ=", 1)
+	key := ResourceKey{Kind: "Secret", Namespace: "default", Name: "db"}
+
+	tests := []struct {
+		name        string
+		mode        FullObjectsMode
+		expectDiff  bool
+		expectFull  bool
+		expectError bool
+	}{
+		{name: "none", mode: FullObjectsNone, expectDiff: true, expectFull: false},
+		{name: "append", mode: FullObjectsAppend, expectDiff: true, expectFull: true},
+		{name: "only", mode: FullObjectsOnly, expectDiff: false, expectFull: true},
+		{name: "invalid", mode: "both", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.FullObjects = tt.mode
+			opts.MaskScope = MaskScopeOperation
+			results, err := YamlString(baseYaml, headYaml, opts)
+			if tt.expectError {
+				assert.ErrorContains(t, err, "invalid full objects mode")
+				return
+			}
+			require.NoError(t, err)
+			diffText := results[key].Diff
+			assert.Equal(t, tt.expectDiff, strings.Contains(diffText, "@@"), diffText)
+			assert.Equal(t, tt.expectFull, strings.Contains(diffText, "# base:\n  apiVersion: v1\n"), diffText)
+			assert.Equal(t, tt.expectFull, strings.Contains(diffText, "# head:\n  apiVersion: v1\n"), diffText)
+			assert.NotContains(t, diffText, "cGFzc3dvcmQ=")
+			assert.NotContains(t, diffText, "bmV3cGFzc3dvcmQ=")
+		})
+	}
+
+	t.Run("object lines are not counted as changed lines", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.FullObjects = FullObjectsAppend
+		results, err := YamlString(baseYaml, headYaml, opts)
+		require.NoError(t, err)
+		withoutFull, err := YamlString(baseYaml, headYaml, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, countChangedLines(withoutFull[key].Diff), countChangedLines(results[key].Diff))
+	})
+}
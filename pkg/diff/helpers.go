@@ -2,18 +2,51 @@ package diff
 
 import (
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/pmezard/go-difflib/difflib"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
 	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type objBaseHead struct {
-	base *unstructured.Unstructured
-	head *unstructured.Unstructured
+	base                *unstructured.Unstructured
+	head                *unstructured.Unstructured
+	reportKey           *ResourceKey // Key the resource is reported under when it differs from its pairing key, see restoreOriginalKeys
+	renamedFrom         *ResourceKey // Key of base when it was paired with head as a rename, see pairRenames
+	statusChanges       []string     // Condition transitions of a resource compared without its status, see removeStatus
+	sopsChanges         []string     // Changes to the SOPS metadata of an encrypted resource, see normalizeSOPS
+	sealedSecretChanges []string     // Changed keys of a SealedSecret compared without its encrypted values, see summarizeSealedSecret
+}
+
+// key returns the key the resource is reported under, which is its pairing key k unless names were mapped
+func (v objBaseHead) key(k ResourceKey) ResourceKey {
+	if v.reportKey != nil {
+		return *v.reportKey
+	}
+	return k
+}
+
+// object returns the head object, or the base object if it was deleted
+func (v objBaseHead) object() *unstructured.Unstructured {
+	if v.head != nil {
+		return v.head
+	}
+	return v.base
+}
+
+// resourceLabels returns the labels of the head object, or of the base object if it was deleted
+func resourceLabels(v objBaseHead) map[string]string {
+	if obj := v.object(); obj != nil {
+		return obj.GetLabels()
+	}
+	return nil
 }
 
 // determineChangeType determines the type of change between base and head objects
@@ -39,9 +72,10 @@ func requiresDiffOutput(changeType ChangeType) bool {
 	return changeType != Unchanged
 }
 
-// getDiffStr generates diff string between live and target objects
-func getDiffStr(name string, live, target *unstructured.Unstructured, opts *Options) (string, int, error) {
-	preparedLive, preparedTarget, err := prepareObjectsForDiff(live, target, opts)
+// getDiffStr generates diff string between live and target objects.
+// A nil masker masks secrets with the process-wide default masker.
+func getDiffStr(name string, live, target *unstructured.Unstructured, opts *Options, masker *masking.Masker) (string, int, error) {
+	preparedLive, preparedTarget, err := prepareObjectsForDiff(live, target, opts, masker)
 	if err != nil {
 		return "", 99, err
 	}
@@ -56,7 +90,7 @@ func getDiffStr(name string, live, target *unstructured.Unstructured, opts *Opti
 		return "", 99, err
 	}
 
-	diffText, err := generateUnifiedDiff(name, liveData, targetData, opts.Context)
+	diffText, err := generateUnifiedDiff(name, liveData, targetData, diffContext(liveData, targetData, opts))
 	if err != nil {
 		return "", 99, err
 	}
@@ -66,18 +100,23 @@ func getDiffStr(name string, live, target *unstructured.Unstructured, opts *Opti
 }
 
 // prepareObjectsForDiff handles secret masking and returns prepared objects for diff
-func prepareObjectsForDiff(live, target *unstructured.Unstructured, opts *Options) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+func prepareObjectsForDiff(live, target *unstructured.Unstructured, opts *Options, masker *masking.Masker) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
 	preparedLive := live
 	preparedTarget := target
 
 	// Mask secrets if enabled
-	if !opts.DisableMaskingSecrets && (masking.IsSecret(live) || masking.IsSecret(target)) {
+	if !opts.DisableMaskingSecrets && (masking.HasSecretValues(live) || masking.HasSecretValues(target)) {
+		maskSecretData := masking.MaskSecretDataWithPolicies
+		if masker != nil {
+			maskSecretData = masker.MaskSecretDataWithPolicies
+		}
+
 		var err error
-		preparedLive, err = masking.MaskSecretData(live)
+		preparedLive, err = maskSecretData(live, opts.SecretPolicies)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to mask live secret: %w", err)
 		}
-		preparedTarget, err = masking.MaskSecretData(target)
+		preparedTarget, err = maskSecretData(target, opts.SecretPolicies)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to mask target secret: %w", err)
 		}
@@ -86,6 +125,62 @@ func prepareObjectsForDiff(live, target *unstructured.Unstructured, opts *Option
 	return preparedLive, preparedTarget, nil
 }
 
+// newScopedMasker returns the masker shared by an operation for the mask scope, strategy and token of opts.
+// It returns nil for the resource scope, where each resource gets its own masker, see newResourceMasker.
+func newScopedMasker(opts *Options) (*masking.Masker, error) {
+	strategy := opts.MaskStrategy
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := masking.ValidateMaskToken(opts.MaskToken, opts.MaskMinLength); err != nil {
+		return nil, err
+	}
+	switch scope := opts.MaskScope; scope {
+	case "", MaskScopeGlobal:
+		if strategy == masking.StrategyHash && opts.MaskKey != "" {
+			// Hash masks only depend on the key, so a keyed masker is consistent with every other one
+			return masking.NewMaskerWithKey(strategy, []byte(opts.MaskKey))
+		}
+		return masking.DefaultMaskerWithToken(strategy, opts.MaskToken, opts.MaskMinLength)
+	case MaskScopeOperation:
+		return masking.NewMaskerWithToken(strategy, []byte(opts.MaskKey), opts.MaskToken, opts.MaskMinLength)
+	case MaskScopeResource:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid mask scope: %s (supported: %s, %s, %s)", scope, MaskScopeGlobal, MaskScopeOperation, MaskScopeResource)
+	}
+}
+
+// newResourceMasker returns the masker of a resource with the resource mask scope.
+// Hash masks are keyed by the resource too, so that equal values of different resources get different masks.
+func newResourceMasker(k ResourceKey, opts *Options) (*masking.Masker, error) {
+	return masking.NewMaskerWithToken(opts.MaskStrategy, []byte(opts.MaskKey+"/"+k.String()), opts.MaskToken, opts.MaskMinLength)
+}
+
+// writeMaskingAudit writes audit records ordered by resource, side, field and key so output is stable across runs
+func writeMaskingAudit(w io.Writer, records []masking.AuditRecord) error {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Side != b.Side {
+			return a.Side < b.Side
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Key < b.Key
+	})
+	return masking.WriteAuditRecords(w, records)
+}
+
 // convertObjectToYAML converts an unstructured object to YAML string
 func convertObjectToYAML(obj *unstructured.Unstructured) (string, error) {
 	if obj == nil {
@@ -97,7 +192,17 @@ func convertObjectToYAML(obj *unstructured.Unstructured) (string, error) {
 		return "", err
 	}
 
-	return string(bytes), nil
+	return parser.UnescapeUnicode(string(bytes)), nil
+}
+
+// diffContext returns the number of context lines for a resource, covering the whole
+// object when it is smaller than Options.ExpandBelowLines
+func diffContext(liveData, targetData string, opts *Options) int {
+	lines := max(strings.Count(liveData, "\n"), strings.Count(targetData, "\n"))
+	if opts.ExpandBelowLines > 0 && lines < opts.ExpandBelowLines {
+		return max(lines, opts.Context)
+	}
+	return opts.Context
 }
 
 // generateUnifiedDiff creates a unified diff between two YAML strings
@@ -113,6 +218,21 @@ func generateUnifiedDiff(name, liveData, targetData string, context int) (string
 	return difflib.GetUnifiedDiffString(diff)
 }
 
+// countChangedLines returns the number of added and removed lines in a unified diff,
+// or of marked lines in inline output and changed paths in report output
+func countChangedLines(diffText string) int {
+	count := 0
+	for _, line := range strings.Split(diffText, "\n") {
+		if strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ") {
+			continue
+		}
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") || isInlineMarkerLine(line) || isReportMarkerLine(line) {
+			count++
+		}
+	}
+	return count
+}
+
 // determineDiffExitCode returns exit code based on diff presence
 func determineDiffExitCode(diffText string) int {
 	if strings.TrimSpace(diffText) != "" {
@@ -122,16 +242,27 @@ func determineDiffExitCode(diffText string) int {
 }
 
 // parseObjsToMap converts base and head unstructured arrays to a map
-// Key is Kubernetes identifier, values can be nil if only present in one side
-func parseObjsToMap(base, head []*unstructured.Unstructured) map[ResourceKey]objBaseHead {
+// Key is Kubernetes identifier, values can be nil if only present in one side.
+// Objects without a name are paired as determined by matching, see pairUnnamed.
+func parseObjsToMap(base, head []*unstructured.Unstructured, keyFunc KeyFunc, matching UnnamedMatching) map[ResourceKey]objBaseHead {
 	objMap := map[ResourceKey]objBaseHead{}
+	unnamedBase := map[ResourceKey][]*unstructured.Unstructured{}
+	unnamedHead := map[ResourceKey][]*unstructured.Unstructured{}
 	for _, obj := range base {
-		key := getResourceKeyFromObj(obj)
+		key := keyFunc(obj)
+		if obj.GetName() == "" {
+			unnamedBase[key] = append(unnamedBase[key], obj)
+			continue
+		}
 		objMap[key] = objBaseHead{base: obj, head: nil}
 	}
 
 	for _, obj := range head {
-		key := getResourceKeyFromObj(obj)
+		key := keyFunc(obj)
+		if obj.GetName() == "" {
+			unnamedHead[key] = append(unnamedHead[key], obj)
+			continue
+		}
 
 		if baseObj, ok := objMap[key]; ok {
 			baseObj.head = obj
@@ -140,9 +271,48 @@ func parseObjsToMap(base, head []*unstructured.Unstructured) map[ResourceKey]obj
 		}
 		objMap[key] = objBaseHead{base: nil, head: obj}
 	}
+
+	for key, objs := range unnamedBase {
+		pairUnnamed(objMap, key, objs, unnamedHead[key], matching)
+	}
+	for key, objs := range unnamedHead {
+		if _, ok := unnamedBase[key]; !ok {
+			pairUnnamed(objMap, key, nil, objs, matching)
+		}
+	}
 	return objMap
 }
 
+// ignoredResourceKeys returns the keys of resources that opt out via the ignore annotation on either side
+func ignoredResourceKeys(base, head []*unstructured.Unstructured, opts *filter.Option, keyFunc KeyFunc) map[ResourceKey]bool {
+	ignored := make(map[ResourceKey]bool)
+	if opts != nil && opts.DisableIgnoreAnnotation {
+		return ignored
+	}
+	for _, objs := range [][]*unstructured.Unstructured{base, head} {
+		for _, obj := range objs {
+			if filter.IsIgnored(obj) {
+				ignored[keyFunc(obj)] = true
+			}
+		}
+	}
+	return ignored
+}
+
+// DefaultKeyFunc identifies a resource by its group, kind, namespace and name.
+// generateName is used when name is empty; objects sharing it are told apart by Options.UnnamedMatching.
+func DefaultKeyFunc(obj *unstructured.Unstructured) ResourceKey {
+	return getResourceKeyFromObj(obj)
+}
+
+// resolveKeyFunc returns the key function from options, falling back to DefaultKeyFunc
+func resolveKeyFunc(opts *Options) KeyFunc {
+	if opts.KeyFunc != nil {
+		return opts.KeyFunc
+	}
+	return DefaultKeyFunc
+}
+
 // getResourceKeyFromObj extracts ResourceKey from unstructured object
 func getResourceKeyFromObj(obj *unstructured.Unstructured) ResourceKey {
 	name := obj.GetName()
@@ -156,3 +326,28 @@ func getResourceKeyFromObj(obj *unstructured.Unstructured) ResourceKey {
 		Kind:      obj.GroupVersionKind().Kind,
 	}
 }
+
+// sortResourceKeys sorts resource keys by Kind, Namespace, Name and Group for stable output
+func sortResourceKeys(keys []ResourceKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Group < b.Group
+	})
+}
+
+// formatResourceKeyShort formats a ResourceKey as Kind/Namespace/Name, omitting the namespace when empty
+func formatResourceKeyShort(key ResourceKey) string {
+	if key.Namespace != "" {
+		return fmt.Sprintf("%s/%s/%s", key.Kind, key.Namespace, key.Name)
+	}
+	return fmt.Sprintf("%s/%s", key.Kind, key.Name)
+}
@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryFormat is the line format of a statistics history file
+type HistoryFormat string
+
+const (
+	// HistoryFormatJSON appends each record as a JSON object on its own line
+	HistoryFormatJSON HistoryFormat = "json"
+	// HistoryFormatCSV appends each record as a CSV row, preceded by a header row in an empty file
+	HistoryFormatCSV HistoryFormat = "csv"
+)
+
+// statisticsColumns are the CSV columns of a StatisticsRecord, in order
+var statisticsColumns = []string{"timestamp", "base", "head", "total", "changed", "created", "deleted", "unchanged", "errors"}
+
+// StatisticsRecord is the statistics of one diff run with the inputs it compared, appended to a history file or
+// pushed to a Prometheus Pushgateway so that manifest churn can be charted over releases
+type StatisticsRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Base      string    `json:"base"`
+	Head      string    `json:"head"`
+	Total     int       `json:"total"`
+	Changed   int       `json:"changed"`
+	Created   int       `json:"created"`
+	Deleted   int       `json:"deleted"`
+	Unchanged int       `json:"unchanged"`
+	Errors    int       `json:"errors"`
+}
+
+// StatisticsRecord returns the statistics of the results as a record of comparing base with head at timestamp
+func (dr Results) StatisticsRecord(base, head string, timestamp time.Time) StatisticsRecord {
+	stats := dr.GetStatistics()
+	return StatisticsRecord{
+		Timestamp: timestamp.UTC(),
+		Base:      base,
+		Head:      head,
+		Total:     stats.Total,
+		Changed:   stats.Changed,
+		Created:   stats.Created,
+		Deleted:   stats.Deleted,
+		Unchanged: stats.Unchanged,
+		Errors:    stats.Errors,
+	}
+}
+
+// AppendHistory writes the record as a line in format to w, the end of a history file.
+// A CSV header row is written first if header is true, i.e. the history file is empty.
+func (r StatisticsRecord) AppendHistory(w io.Writer, format HistoryFormat, header bool) error {
+	switch format {
+	case HistoryFormatJSON:
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode statistics: %w", err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write statistics: %w", err)
+		}
+		return nil
+	case HistoryFormatCSV:
+		writer := csv.NewWriter(w)
+		if header {
+			_ = writer.Write(statisticsColumns)
+		}
+		_ = writer.Write([]string{
+			r.Timestamp.Format(time.RFC3339),
+			r.Base,
+			r.Head,
+			strconv.Itoa(r.Total),
+			strconv.Itoa(r.Changed),
+			strconv.Itoa(r.Created),
+			strconv.Itoa(r.Deleted),
+			strconv.Itoa(r.Unchanged),
+			strconv.Itoa(r.Errors),
+		})
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write statistics: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported history format %q (must be json or csv)", format)
+	}
+}
+
+// WriteMetrics writes the record in the Prometheus text exposition format, as pushed to a Pushgateway.
+// The inputs are labels of the resource counts, so that runs comparing different inputs are charted separately.
+func (r StatisticsRecord) WriteMetrics(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# HELP k8s_manifest_diff_resources Number of resources compared by the last diff, by change type.\n")
+	b.WriteString("# TYPE k8s_manifest_diff_resources gauge\n")
+	counts := []struct {
+		changeType string
+		count      int
+	}{
+		{"changed", r.Changed},
+		{"created", r.Created},
+		{"deleted", r.Deleted},
+		{"unchanged", r.Unchanged},
+		{"error", r.Errors},
+	}
+	for _, c := range counts {
+		fmt.Fprintf(&b, "k8s_manifest_diff_resources{base=%s,head=%s,type=%q} %d\n", metricLabel(r.Base), metricLabel(r.Head), c.changeType, c.count)
+	}
+	b.WriteString("# HELP k8s_manifest_diff_last_run_timestamp_seconds Unix time of the last diff.\n")
+	b.WriteString("# TYPE k8s_manifest_diff_last_run_timestamp_seconds gauge\n")
+	fmt.Fprintf(&b, "k8s_manifest_diff_last_run_timestamp_seconds %d\n", r.Timestamp.Unix())
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// metricLabel quotes a label value of the Prometheus text format, which escapes only backslashes,
+// double quotes and line feeds
+func metricLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
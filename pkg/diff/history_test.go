@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func historyResults() Results {
+	return Results{
+		{Kind: "ConfigMap", Namespace: "default", Name: "a"}: {Type: Changed},
+		{Kind: "ConfigMap", Namespace: "default", Name: "b"}: {Type: Created},
+		{Kind: "ConfigMap", Namespace: "default", Name: "c"}: {Type: Unchanged},
+		{Kind: "ConfigMap", Namespace: "default", Name: "d"}: {Type: Unchanged},
+	}
+}
+
+func TestResults_StatisticsRecord(t *testing.T) {
+	timestamp := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	record := historyResults().StatisticsRecord("base.yaml", "head.yaml", timestamp)
+
+	assert.Equal(t, StatisticsRecord{
+		Timestamp: timestamp.UTC(),
+		Base:      "base.yaml",
+		Head:      "head.yaml",
+		Total:     4,
+		Changed:   1,
+		Created:   1,
+		Unchanged: 2,
+	}, record)
+}
+
+func TestStatisticsRecord_AppendHistory(t *testing.T) {
+	record := historyResults().StatisticsRecord("base, v1.yaml", "head.yaml", time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC))
+
+	t.Run("json", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, record.AppendHistory(&b, HistoryFormatJSON, true))
+		require.NoError(t, record.AppendHistory(&b, HistoryFormatJSON, false))
+
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		require.Len(t, lines, 2, "JSON lines have no header")
+		var decoded StatisticsRecord
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &decoded))
+		assert.Equal(t, record, decoded)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, record.AppendHistory(&b, HistoryFormatCSV, true))
+		require.NoError(t, record.AppendHistory(&b, HistoryFormatCSV, false))
+
+		row := `2026-03-01T03:00:00Z,"base, v1.yaml",head.yaml,4,1,1,0,2,0`
+		assert.Equal(t, "timestamp,base,head,total,changed,created,deleted,unchanged,errors\n"+row+"\n"+row+"\n", b.String())
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		err := record.AppendHistory(&bytes.Buffer{}, "xml", false)
+		assert.ErrorContains(t, err, `unsupported history format "xml"`)
+	})
+}
+
+func TestStatisticsRecord_WriteMetrics(t *testing.T) {
+	record := historyResults().StatisticsRecord(`env/"prod"`, "head.yaml", time.Unix(1772334000, 0))
+
+	var b bytes.Buffer
+	require.NoError(t, record.WriteMetrics(&b))
+	out := b.String()
+
+	assert.Contains(t, out, "# TYPE k8s_manifest_diff_resources gauge\n")
+	assert.Contains(t, out, `k8s_manifest_diff_resources{base="env/\"prod\"",head="head.yaml",type="changed"} 1`+"\n")
+	assert.Contains(t, out, `k8s_manifest_diff_resources{base="env/\"prod\"",head="head.yaml",type="unchanged"} 2`+"\n")
+	assert.Contains(t, out, "k8s_manifest_diff_last_run_timestamp_seconds 1772334000\n")
+}
@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ImageChanges describes container image changes of a workload by container, e.g.
+// "container web: nginx 1.25.3 -> 1.27.0" for a version bump or
+// "container web: nginx:1.25 -> ghcr.io/acme/nginx:1.25" when the repository changes.
+// Workloads are the built-in kinds with a pod spec, see workload.Builtin; other resources have no image changes.
+func ImageChanges(base, head *unstructured.Unstructured) []string {
+	return imageChanges(base, head, nil)
+}
+
+// imageChanges describes container image changes of a workload of the kinds in workloads, see ImageChanges
+func imageChanges(base, head *unstructured.Unstructured, workloads *workload.Policy) []string {
+	if base == nil || head == nil {
+		return nil
+	}
+	baseImages, ok := containerImages(base, workloads)
+	if !ok {
+		return nil
+	}
+	headImages, _ := containerImages(head, workloads)
+
+	names := make([]string, 0, len(baseImages)+len(headImages))
+	for name := range baseImages {
+		names = append(names, name)
+	}
+	for name := range headImages {
+		if _, ok := baseImages[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		baseImage, inBase := baseImages[name]
+		headImage, inHead := headImages[name]
+		switch {
+		case !inBase:
+			changes = append(changes, fmt.Sprintf("container %s added: %s", name, headImage))
+		case !inHead:
+			changes = append(changes, fmt.Sprintf("container %s removed: %s", name, baseImage))
+		case baseImage != headImage:
+			changes = append(changes, fmt.Sprintf("container %s: %s", name, formatImageChange(baseImage, headImage)))
+		}
+	}
+	return changes
+}
+
+// containerImages returns the images of the init and regular containers of a workload by container name.
+// The second return value is false when obj is not one of the workload kinds.
+func containerImages(obj *unstructured.Unstructured, workloads *workload.Policy) (map[string]string, bool) {
+	gvk := obj.GroupVersionKind()
+	kind, ok := workloads.Lookup(gvk.Group, gvk.Kind)
+	if !ok {
+		return nil, false
+	}
+	path := kind.PodSpecPath()
+	images := make(map[string]string)
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(obj.Object, append(append([]string{}, path...), field)...)
+		for _, container := range containers {
+			container, ok := container.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			image, _ := container["image"].(string)
+			images[name] = image
+		}
+	}
+	return images, true
+}
+
+// formatImageChange formats an image change as "repository old -> new" when only the tag or digest changed
+func formatImageChange(baseImage, headImage string) string {
+	baseRepository, baseVersion := splitImage(baseImage)
+	headRepository, headVersion := splitImage(headImage)
+	if baseRepository == headRepository {
+		return fmt.Sprintf("%s %s -> %s", baseRepository, baseVersion, headVersion)
+	}
+	return fmt.Sprintf("%s -> %s", baseImage, headImage)
+}
+
+// splitImage splits an image reference into its repository and its tag or digest ("latest" when neither is set)
+func splitImage(image string) (repository, version string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i+1:]
+	}
+	// A colon before the last slash separates a registry port, not a tag
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
@@ -0,0 +1,113 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newDeployment returns a Deployment running containers with the given images by name
+func newDeployment(name string, images map[string]string) *unstructured.Unstructured {
+	containers := make([]any, 0, len(images))
+	for container, image := range images {
+		containers = append(containers, map[string]any{"name": container, "image": image})
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": name, "namespace": "default", "labels": map[string]any{"app": name}},
+		"spec": map[string]any{
+			"template": map[string]any{"spec": map[string]any{"containers": containers}},
+		},
+	}}
+}
+
+func TestImageChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     *unstructured.Unstructured
+		head     *unstructured.Unstructured
+		expected []string
+	}{
+		{
+			name:     "tag bump",
+			base:     newDeployment("web", map[string]string{"web": "nginx:1.25.3"}),
+			head:     newDeployment("web", map[string]string{"web": "nginx:1.27.0"}),
+			expected: []string{"container web: nginx 1.25.3 -> 1.27.0"},
+		},
+		{
+			name:     "registry port",
+			base:     newDeployment("web", map[string]string{"web": "registry.local:5000/web"}),
+			head:     newDeployment("web", map[string]string{"web": "registry.local:5000/web:v2"}),
+			expected: []string{"container web: registry.local:5000/web latest -> v2"},
+		},
+		{
+			name:     "digest",
+			base:     newDeployment("web", map[string]string{"web": "nginx@sha256:aaa"}),
+			head:     newDeployment("web", map[string]string{"web": "nginx@sha256:bbb"}),
+			expected: []string{"container web: nginx sha256:aaa -> sha256:bbb"},
+		},
+		{
+			name:     "repository change",
+			base:     newDeployment("web", map[string]string{"web": "nginx:1.25"}),
+			head:     newDeployment("web", map[string]string{"web": "ghcr.io/acme/nginx:1.25"}),
+			expected: []string{"container web: nginx:1.25 -> ghcr.io/acme/nginx:1.25"},
+		},
+		{
+			name:     "containers added and removed",
+			base:     newDeployment("web", map[string]string{"web": "nginx:1.25", "proxy": "envoy:1.30"}),
+			head:     newDeployment("web", map[string]string{"web": "nginx:1.25", "sidecar": "busybox:1.36"}),
+			expected: []string{"container proxy removed: envoy:1.30", "container sidecar added: busybox:1.36"},
+		},
+		{
+			name:     "unchanged",
+			base:     newDeployment("web", map[string]string{"web": "nginx:1.25"}),
+			head:     newDeployment("web", map[string]string{"web": "nginx:1.25"}),
+			expected: nil,
+		},
+		{
+			name:     "not a workload",
+			base:     newConfigMap("web", "default", "a"),
+			head:     newConfigMap("web", "default", "b"),
+			expected: nil,
+		},
+		{
+			name:     "created workload",
+			base:     nil,
+			head:     newDeployment("web", map[string]string{"web": "nginx:1.25"}),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ImageChanges(tt.base, tt.head))
+		})
+	}
+}
+
+func TestObjects_WorkloadImageChanges(t *testing.T) {
+	rollout := func(image string) *unstructured.Unstructured {
+		obj := newDeployment("web", map[string]string{"web": image})
+		obj.SetAPIVersion("argoproj.io/v1alpha1")
+		obj.SetKind("Rollout")
+		return obj
+	}
+	base := []*unstructured.Unstructured{rollout("nginx:1.25")}
+	head := []*unstructured.Unstructured{rollout("nginx:1.27")}
+	key := ResourceKey{Group: "argoproj.io", Kind: "Rollout", Namespace: "default", Name: "web"}
+
+	results, err := Objects(base, head, nil)
+	require.NoError(t, err)
+	assert.Empty(t, results[key].ImageChanges)
+
+	policy, err := workload.Read(strings.NewReader("workloads:\n- group: argoproj.io\n  kind: Rollout\n  podSpec: spec.template.spec\n"))
+	require.NoError(t, err)
+	results, err = Objects(base, head, NewOptions().WithWorkloads(policy))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container web: nginx 1.25 -> 1.27"}, results[key].ImageChanges)
+}
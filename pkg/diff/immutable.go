@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// immutableFields lists fields, by "group/Kind", that the API server rejects changes to.
+// Changing any of them requires deleting and recreating the resource.
+var immutableFields = map[string][][]string{
+	"apps/Deployment":  {{"spec", "selector"}},
+	"apps/ReplicaSet":  {{"spec", "selector"}},
+	"apps/DaemonSet":   {{"spec", "selector"}},
+	"apps/StatefulSet": {{"spec", "selector"}, {"spec", "serviceName"}, {"spec", "podManagementPolicy"}, {"spec", "volumeClaimTemplates"}},
+	"batch/Job":        {{"spec", "selector"}, {"spec", "template"}, {"spec", "completionMode"}},
+	"/Service":         {{"spec", "clusterIP"}, {"spec", "clusterIPs"}},
+	"/PersistentVolumeClaim": {
+		{"spec", "storageClassName"}, {"spec", "accessModes"}, {"spec", "selector"}, {"spec", "volumeName"}, {"spec", "volumeMode"},
+	},
+	"rbac.authorization.k8s.io/RoleBinding":        {{"roleRef"}},
+	"rbac.authorization.k8s.io/ClusterRoleBinding": {{"roleRef"}},
+	"storage.k8s.io/StorageClass":                  {{"provisioner"}, {"parameters"}, {"reclaimPolicy"}, {"volumeBindingMode"}},
+}
+
+// ImmutableFieldChanges returns the dotted paths of immutable fields that differ between base and head.
+// ConfigMaps and Secrets marked "immutable: true" in base treat their data as immutable.
+func ImmutableFieldChanges(base, head *unstructured.Unstructured) []string {
+	if base == nil || head == nil {
+		return nil
+	}
+
+	gvk := base.GroupVersionKind()
+	fields := immutableFields[gvk.Group+"/"+gvk.Kind]
+	if gvk.Group == "" && (gvk.Kind == "ConfigMap" || gvk.Kind == "Secret") {
+		if immutable, _, _ := unstructured.NestedBool(base.Object, "immutable"); immutable {
+			fields = [][]string{{"data"}, {"binaryData"}, {"stringData"}}
+		}
+	}
+
+	var changed []string
+	for _, path := range fields {
+		baseValue, _, _ := unstructured.NestedFieldNoCopy(base.Object, path...)
+		headValue, _, _ := unstructured.NestedFieldNoCopy(head.Object, path...)
+		if !reflect.DeepEqual(baseValue, headValue) {
+			changed = append(changed, strings.Join(path, "."))
+		}
+	}
+	return changed
+}
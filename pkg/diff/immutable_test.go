@@ -0,0 +1,46 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestImmutableFieldChanges(t *testing.T) {
+	newDeployment := func(app string, replicas int64) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]any{"name": "web", "namespace": "default"},
+			"spec": map[string]any{
+				"replicas": replicas,
+				"selector": map[string]any{"matchLabels": map[string]any{"app": app}},
+			},
+		}}
+	}
+	immutableConfigMap := func(value string) *unstructured.Unstructured {
+		obj := newConfigMap("app-config", "default", value)
+		obj.Object["immutable"] = true
+		return obj
+	}
+
+	tests := []struct {
+		name     string
+		base     *unstructured.Unstructured
+		head     *unstructured.Unstructured
+		expected []string
+	}{
+		{name: "mutable field", base: newDeployment("web", 1), head: newDeployment("web", 2), expected: nil},
+		{name: "selector", base: newDeployment("web", 1), head: newDeployment("frontend", 1), expected: []string{"spec.selector"}},
+		{name: "mutable configmap", base: newConfigMap("app-config", "default", "v1"), head: newConfigMap("app-config", "default", "v2"), expected: nil},
+		{name: "immutable configmap", base: immutableConfigMap("v1"), head: immutableConfigMap("v2"), expected: []string{"data"}},
+		{name: "created", base: nil, head: newDeployment("web", 1), expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ImmutableFieldChanges(tt.base, tt.head))
+		})
+	}
+}
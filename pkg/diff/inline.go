@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Markers appended to or inserted into head YAML lines by the inline diff style
+const (
+	inlineWasMarker     = "  # was: "
+	inlineAddedMarker   = "  # added"
+	inlineRemovedMarker = "# removed: "
+)
+
+// getInlineStr returns the head YAML with "# was: <old value>" comments on modified lines,
+// "# added" comments on added lines and "# removed: <old line>" comment lines for removed lines
+func getInlineStr(base, head *unstructured.Unstructured, opts *Options, masker *masking.Masker) (string, error) {
+	preparedHead, preparedBase, err := prepareObjectsForDiff(head, base, opts, masker)
+	if err != nil {
+		return "", err
+	}
+
+	baseData, err := yaml.Marshal(preparedBase.Object)
+	if err != nil {
+		return "", err
+	}
+	headData, err := yaml.Marshal(preparedHead.Object)
+	if err != nil {
+		return "", err
+	}
+	baseLines := strings.Split(strings.TrimRight(parser.UnescapeUnicode(string(baseData)), "\n"), "\n")
+	headLines := strings.Split(strings.TrimRight(parser.UnescapeUnicode(string(headData)), "\n"), "\n")
+
+	var result strings.Builder
+	matcher := difflib.NewMatcherWithJunk(baseLines, headLines, false, nil)
+	for _, op := range matcher.GetOpCodes() {
+		oldLines := baseLines[op.I1:op.I2]
+		newLines := headLines[op.J1:op.J2]
+		if op.Tag == 'e' {
+			for _, line := range newLines {
+				result.WriteString(line + "\n")
+			}
+			continue
+		}
+
+		for i, line := range newLines {
+			if i < len(oldLines) {
+				result.WriteString(line + inlineWasMarker + wasValue(oldLines[i], line) + "\n")
+			} else {
+				result.WriteString(line + inlineAddedMarker + "\n")
+			}
+		}
+		for _, line := range oldLines[min(len(newLines), len(oldLines)):] {
+			trimmed := strings.TrimLeft(line, " ")
+			result.WriteString(fmt.Sprintf("%s%s%s\n", line[:len(line)-len(trimmed)], inlineRemovedMarker, trimmed))
+		}
+	}
+	return result.String(), nil
+}
+
+// wasValue returns the old value to show for a modified line: only the value when the key is
+// unchanged, otherwise the whole old line
+func wasValue(oldLine, newLine string) string {
+	oldKey, oldValue, oldHasValue := strings.Cut(strings.TrimSpace(oldLine), ": ")
+	newKey, _, _ := strings.Cut(strings.TrimSpace(newLine), ": ")
+	if oldHasValue && oldKey == newKey {
+		return oldValue
+	}
+	return strings.TrimSpace(oldLine)
+}
+
+// isInlineMarkerLine reports whether a line of inline diff output marks a change
+func isInlineMarkerLine(line string) bool {
+	return strings.Contains(line, inlineWasMarker) || strings.HasSuffix(line, inlineAddedMarker) ||
+		strings.HasPrefix(strings.TrimLeft(line, " "), inlineRemovedMarker)
+}
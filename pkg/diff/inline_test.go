@@ -0,0 +1,61 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYamlString_DiffStyleInline(t *testing.T) {
+	base := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+    tier: frontend
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - image: nginx:1.25
+        name: web
+`
+	head := strings.NewReplacer("replicas: 2", "replicas: 3", "nginx:1.25", "nginx:1.27", "    tier: frontend\n", "").Replace(base) +
+		"  strategy:\n    type: Recreate\n"
+
+	opts := DefaultOptions()
+	opts.DiffStyle = DiffStyleInline
+	results, err := YamlString(base, head, opts)
+	require.NoError(t, err)
+
+	result := results[ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}]
+	assert.Equal(t, Changed, result.Type)
+	assert.Contains(t, result.Diff, "===== apps/Deployment default/web ======\napiVersion: apps/v1\n")
+	assert.Contains(t, result.Diff, "  replicas: 3  # was: 2\n")
+	assert.Contains(t, result.Diff, "      - image: nginx:1.27  # was: nginx:1.25\n")
+	assert.Contains(t, result.Diff, "    # removed: tier: frontend\n")
+	assert.Contains(t, result.Diff, "  strategy:  # added\n    type: Recreate  # added\n")
+	assert.NotContains(t, result.Diff, "@@")
+	assert.Equal(t, 5, countChangedLines(result.Diff))
+}
+
+func TestWasValue(t *testing.T) {
+	tests := []struct {
+		oldLine  string
+		newLine  string
+		expected string
+	}{
+		{oldLine: "  replicas: 2", newLine: "  replicas: 3", expected: "2"},
+		{oldLine: "  - image: a", newLine: "  - image: b", expected: "a"},
+		{oldLine: "  foo: 1", newLine: "  bar: 1", expected: "foo: 1"},
+		{oldLine: "  - a", newLine: "  - b", expected: "- a"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, wasValue(tt.oldLine, tt.newLine))
+	}
+}
@@ -1,10 +1,15 @@
 package diff
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -411,6 +416,25 @@ func TestObjects_Integration(t *testing.T) {
 	}
 }
 
+func TestObjects_OnResult(t *testing.T) {
+	base := []*unstructured.Unstructured{newConfigMap("b", "default", "1"), newConfigMap("a", "default", "1")}
+	head := []*unstructured.Unstructured{newConfigMap("a", "default", "2")}
+
+	var streamed []ResourceKey
+	opts := NewOptions().WithOnResult(func(key ResourceKey, result Result) error {
+		streamed = append(streamed, key)
+		return nil
+	})
+	results, err := Objects(base, head, opts)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, []ResourceKey{{Kind: "ConfigMap", Namespace: "default", Name: "a"}, {Kind: "ConfigMap", Namespace: "default", Name: "b"}}, streamed)
+
+	stop := errors.New("stop")
+	_, err = Objects(base, head, NewOptions().WithOnResult(func(ResourceKey, Result) error { return stop }))
+	assert.ErrorIs(t, err, stop)
+}
+
 func TestObjects_WithNilOptions(t *testing.T) {
 	obj := unstructured.Unstructured{
 		Object: map[string]any{
@@ -437,3 +461,630 @@ func TestObjects_WithNilOptions(t *testing.T) {
 	assert.Equal(t, 1, len(changedResourcesList))
 	AssertResourceChange(t, results, "ConfigMap/test/config", Created)
 }
+
+func TestYamlString_IgnoreAnnotation(t *testing.T) {
+	baseYaml := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored-config
+  namespace: default
+data:
+  key: old-value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tracked-config
+  namespace: default
+data:
+  key: old-value
+`
+
+	headYaml := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored-config
+  namespace: default
+  annotations:
+    k8s-manifest-diff/ignore: "true"
+data:
+  key: new-value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tracked-config
+  namespace: default
+data:
+  key: new-value
+`
+
+	t.Run("resource ignored on one side is dropped from both", func(t *testing.T) {
+		results, err := YamlString(baseYaml, headYaml, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, results.Count())
+		AssertResourceChange(t, results, "ConfigMap/default/tracked-config", Changed)
+		assert.NotContains(t, results.StringDiff(), "ignored-config")
+	})
+
+	t.Run("ignore annotation can be disabled", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.FilterOption.DisableIgnoreAnnotation = true
+
+		results, err := YamlString(baseYaml, headYaml, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, results.Count())
+		AssertResourceChange(t, results, "ConfigMap/default/ignored-config", Changed)
+	})
+}
+
+func TestYamlString_MinimumChangedLines(t *testing.T) {
+	baseYaml := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: small-change
+  namespace: default
+data:
+  key1: value1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: large-change
+  namespace: default
+data:
+  key1: value1
+  key2: value2
+  key3: value3
+`
+
+	headYaml := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: small-change
+  namespace: default
+data:
+  key1: changed1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: large-change
+  namespace: default
+data:
+  key1: changed1
+  key2: changed2
+  key3: changed3
+`
+
+	smallKey := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "small-change"}
+	largeKey := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "large-change"}
+
+	tests := []struct {
+		name            string
+		minimum         int
+		expectedTrivial map[ResourceKey]bool
+	}{
+		{
+			name:            "disabled by default",
+			minimum:         0,
+			expectedTrivial: map[ResourceKey]bool{smallKey: false, largeKey: false},
+		},
+		{
+			name:            "small change below threshold",
+			minimum:         3,
+			expectedTrivial: map[ResourceKey]bool{smallKey: true, largeKey: false},
+		},
+		{
+			name:            "both changes below threshold",
+			minimum:         10,
+			expectedTrivial: map[ResourceKey]bool{smallKey: true, largeKey: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.MinimumChangedLines = tt.minimum
+
+			results, err := YamlString(baseYaml, headYaml, opts)
+			assert.NoError(t, err)
+			for key, expected := range tt.expectedTrivial {
+				assert.Equal(t, Changed, results[key].Type)
+				assert.Equal(t, expected, results[key].Trivial, "Trivial flag for %s", key)
+			}
+		})
+	}
+
+	t.Run("created resources are never trivial", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.MinimumChangedLines = 100
+
+		results, err := YamlString("", headYaml, opts)
+		assert.NoError(t, err)
+		assert.Empty(t, results.FilterTrivial())
+	})
+}
+
+func TestYamlString_MaskScope(t *testing.T) {
+	headYaml := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret-a
+  namespace: default
+data:
+  password: dmFsdWUtYQ==
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret-b
+  namespace: default
+data:
+  password: dmFsdWUtYg==
+`
+	keyA := ResourceKey{Kind: "Secret", Namespace: "default", Name: "secret-a"}
+	keyB := ResourceKey{Kind: "Secret", Namespace: "default", Name: "secret-b"}
+	secondMask := strings.Repeat("+", 17)
+
+	t.Run("operation scope shares masks across resources", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.MaskScope = MaskScopeOperation
+
+		results, err := YamlString("", headYaml, opts)
+		assert.NoError(t, err)
+		withSecondMask := 0
+		for _, key := range []ResourceKey{keyA, keyB} {
+			if strings.Contains(results[key].Diff, secondMask) {
+				withSecondMask++
+			}
+		}
+		assert.Equal(t, 1, withSecondMask, "Exactly one resource should receive the second mask")
+	})
+
+	t.Run("resource scope starts fresh for each resource", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.MaskScope = MaskScopeResource
+
+		results, err := YamlString("", headYaml, opts)
+		assert.NoError(t, err)
+		for _, key := range []ResourceKey{keyA, keyB} {
+			assert.Contains(t, results[key].Diff, strings.Repeat("+", 16))
+			assert.NotContains(t, results[key].Diff, secondMask)
+		}
+	})
+
+	t.Run("custom token", func(t *testing.T) {
+		for _, scope := range []MaskScope{MaskScopeGlobal, MaskScopeOperation, MaskScopeResource} {
+			opts := NewOptions().WithMaskScope(scope).WithMaskToken("*").WithMaskMinLength(3)
+
+			results, err := YamlString("", headYaml, opts)
+			assert.NoError(t, err)
+			assert.Contains(t, results[keyA].Diff, "password: '***'", scope)
+			assert.NotContains(t, results[keyA].Diff, "++++", scope)
+		}
+
+		_, err := YamlString("", headYaml, NewOptions().WithMaskMinLength(-1))
+		assert.ErrorContains(t, err, "must not be negative")
+	})
+
+	t.Run("length strategy", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.MaskScope = MaskScopeResource
+		opts.MaskStrategy = masking.StrategyLength
+
+		results, err := YamlString("", headYaml, opts)
+		assert.NoError(t, err)
+		assert.Contains(t, results[keyA].Diff, "<masked, 1-8 bytes>")
+		assert.NotContains(t, results[keyA].Diff, strings.Repeat("+", 16))
+	})
+
+	t.Run("hash strategy does not depend on resource order", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.MaskScope = MaskScopeOperation
+		opts.MaskStrategy = masking.StrategyHash
+		opts.MaskKey = "key"
+
+		results, err := YamlString("", headYaml, opts)
+		assert.NoError(t, err)
+		documents := strings.Split(headYaml, "---")
+		reversed, err := YamlString("", documents[1]+"---"+documents[0], opts)
+		assert.NoError(t, err)
+		for _, key := range []ResourceKey{keyA, keyB} {
+			assert.Contains(t, results[key].Diff, "<masked:")
+			assert.Equal(t, results[key].Diff, reversed[key].Diff)
+		}
+	})
+
+	t.Run("hash strategy with resource scope", func(t *testing.T) {
+		sameValue := strings.Replace(headYaml, "dmFsdWUtYg==", "dmFsdWUtYQ==", 1)
+		opts := DefaultOptions()
+		opts.MaskStrategy = masking.StrategyHash
+		opts.MaskKey = "key"
+
+		results, err := YamlString("", sameValue, opts)
+		assert.NoError(t, err)
+		maskA := results[keyA].Diff[strings.Index(results[keyA].Diff, "<masked:"):]
+		assert.Contains(t, results[keyB].Diff, maskA[:len("<masked:1a2b3c4d5e6f>")], "Equal values share a mask in the global scope")
+
+		opts.MaskScope = MaskScopeResource
+		results, err = YamlString("", sameValue, opts)
+		assert.NoError(t, err)
+		maskA = results[keyA].Diff[strings.Index(results[keyA].Diff, "<masked:"):]
+		assert.NotContains(t, results[keyB].Diff, maskA[:len("<masked:1a2b3c4d5e6f>")], "Equal values of different resources get different masks")
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.MaskScope = "cluster"
+
+		_, err := YamlString("", headYaml, opts)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid mask scope")
+	})
+}
+
+func TestObjects_MaskingAudit(t *testing.T) {
+	baseYaml := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+data:
+  password: bXlwYXNzd29yZA==
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: unchanged
+  namespace: default
+data:
+  password: bXlwYXNzd29yZA==
+`
+	headYaml := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+data:
+  password: bmV3cGFzc3dvcmQ=
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: unchanged
+  namespace: default
+data:
+  password: bXlwYXNzd29yZA==
+`
+
+	t.Run("records masked values of rendered resources", func(t *testing.T) {
+		var audit strings.Builder
+		opts := DefaultOptions()
+		opts.MaskingAudit = &audit
+
+		_, err := YamlString(baseYaml, headYaml, opts)
+		assert.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"name":"db","side":"base","field":"data","key":"password"`)
+		assert.Contains(t, lines[1], `"name":"db","side":"head","field":"data","key":"password"`)
+		assert.NotContains(t, audit.String(), "bXlwYXNzd29yZA==")
+		assert.NotContains(t, audit.String(), "unchanged")
+	})
+
+	t.Run("nothing is recorded when masking is disabled", func(t *testing.T) {
+		var audit strings.Builder
+		opts := DefaultOptions()
+		opts.MaskingAudit = &audit
+		opts.DisableMaskingSecrets = true
+
+		_, err := YamlString(baseYaml, headYaml, opts)
+		assert.NoError(t, err)
+		assert.Empty(t, audit.String())
+	})
+}
+
+func TestWriteMaskingAudit(t *testing.T) {
+	records := []masking.AuditRecord{
+		{Kind: "Secret", Namespace: "default", Name: "db", Side: "head", Field: "data", Key: "password"},
+		{Kind: "SealedSecret", Namespace: "default", Name: "db", Side: "base", Field: "encryptedData", Key: "password"},
+		{Kind: "Secret", Namespace: "default", Name: "db", Side: "base", Field: "stringData", Key: "user"},
+		{Kind: "Secret", Namespace: "default", Name: "db", Side: "base", Field: "data", Key: "user"},
+		{Kind: "Secret", Namespace: "default", Name: "db", Side: "base", Field: "data", Key: "password"},
+	}
+
+	var audit strings.Builder
+	require.NoError(t, writeMaskingAudit(&audit, records))
+	var order []string
+	for _, line := range strings.Split(strings.TrimSpace(audit.String()), "\n") {
+		var record masking.AuditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		order = append(order, strings.Join([]string{record.Kind, record.Side, record.Field, record.Key}, " "))
+	}
+	assert.Equal(t, []string{
+		"SealedSecret base encryptedData password",
+		"Secret base data password",
+		"Secret base data user",
+		"Secret base stringData user",
+		"Secret head data password",
+	}, order)
+}
+
+func TestObjects_KeyFunc(t *testing.T) {
+	base := []*unstructured.Unstructured{newConfigMap("app-config", "team-a", "v1")}
+	head := []*unstructured.Unstructured{newConfigMap("app-config", "team-b", "v2")}
+
+	t.Run("default key function pairs by namespace", func(t *testing.T) {
+		results, err := Objects(base, head, DefaultOptions())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, results.CountByType(Created))
+		assert.Equal(t, 1, results.CountByType(Deleted))
+	})
+
+	t.Run("custom key function ignoring namespace", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.KeyFunc = func(obj *unstructured.Unstructured) ResourceKey {
+			key := DefaultKeyFunc(obj)
+			key.Namespace = ""
+			return key
+		}
+
+		results, err := Objects(base, head, opts)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		result := results[ResourceKey{Kind: "ConfigMap", Name: "app-config"}]
+		assert.Equal(t, Changed, result.Type)
+		assert.Contains(t, result.Diff, "namespace: team-a")
+		assert.Contains(t, result.Diff, "namespace: team-b")
+	})
+}
+
+func TestObjects_Only(t *testing.T) {
+	base := []*unstructured.Unstructured{
+		newConfigMap("app-config", "default", "v1"),
+		newConfigMap("db-config", "default", "v1"),
+	}
+	head := []*unstructured.Unstructured{
+		newConfigMap("app-config", "default", "v2"),
+		newConfigMap("db-config", "default", "v2"),
+	}
+
+	t.Run("only selected resources are diffed", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Only = []string{"ConfigMap/default/app-*"}
+
+		results, err := Objects(base, head, opts)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, Changed, results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app-config"}].Type)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Only = []string{"ConfigMap/[default"}
+
+		_, err := Objects(base, head, opts)
+		assert.ErrorContains(t, err, "invalid only pattern")
+	})
+}
+
+func TestYamlString_ExpandBelowLines(t *testing.T) {
+	var data strings.Builder
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		data.WriteString("  " + key + ": value-" + key + "\n")
+	}
+	baseYaml := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: default\ndata:\n" + data.String()
+	headYaml := strings.Replace(baseYaml, "e: value-e", "e: changed", 1)
+	key := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "config"}
+
+	tests := []struct {
+		name         string
+		expandBelow  int
+		expectedFull bool
+	}{
+		{name: "disabled", expandBelow: 0, expectedFull: false},
+		{name: "small resource is shown in full", expandBelow: 50, expectedFull: true},
+		{name: "large resource keeps hunks", expandBelow: 5, expectedFull: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Context = 1
+			opts.ExpandBelowLines = tt.expandBelow
+			results, err := YamlString(baseYaml, headYaml, opts)
+			assert.NoError(t, err)
+			diffText := results[key].Diff
+			assert.Contains(t, diffText, "e: changed")
+			assert.Equal(t, tt.expectedFull, strings.Contains(diffText, "apiVersion: v1"), diffText)
+			assert.Equal(t, tt.expectedFull, strings.Contains(diffText, "a: value-a"), diffText)
+		})
+	}
+}
+
+func TestYamlString_MultiByteCharacters(t *testing.T) {
+	baseYaml := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+  annotations:
+    description: "本番環境の設定 🚀"
+data:
+  message: こんにちは
+`
+	headYaml := strings.Replace(baseYaml, "こんにちは", "こんばんは 🌙", 1)
+	key := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "config"}
+
+	for _, style := range []DiffStyle{DiffStyleUnified, DiffStyleInline, DiffStyleReport} {
+		t.Run(string(style), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.DiffStyle = style
+			opts.FullObjects = FullObjectsAppend
+			results, err := YamlString(baseYaml, headYaml, opts)
+			assert.NoError(t, err)
+			diffText := results[key].Diff
+			assert.Contains(t, diffText, "こんばんは 🌙")
+			assert.Contains(t, diffText, "本番環境の設定 🚀")
+			assert.NotContains(t, diffText, `\U`)
+		})
+	}
+}
+
+func TestYamlString_FailFast(t *testing.T) {
+	baseYaml := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  key: same
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+data:
+  key: base
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+data:
+  key: base
+`
+	headYaml := strings.ReplaceAll(baseYaml, "key: base", "key: head")
+
+	var streamed []ResourceKey
+	opts := NewOptions().WithFailFast(true).WithOnResult(func(key ResourceKey, _ Result) error {
+		streamed = append(streamed, key)
+		return nil
+	})
+	results, err := YamlString(baseYaml, headYaml, opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, Results{
+		{Kind: "ConfigMap", Name: "a"}: {Type: Unchanged},
+		{Kind: "ConfigMap", Name: "b"}: {Type: Changed},
+	}, results, "classifying stops at the first change, without diff text")
+	assert.Equal(t, []ResourceKey{{Kind: "ConfigMap", Name: "a"}, {Kind: "ConfigMap", Name: "b"}}, streamed)
+	assert.True(t, results.HasChanges())
+
+	results, err = YamlString(baseYaml, headYaml, NewOptions().WithClassifyOnly(true))
+	require.NoError(t, err)
+	assert.Equal(t, Results{
+		{Kind: "ConfigMap", Name: "a"}: {Type: Unchanged},
+		{Kind: "ConfigMap", Name: "b"}: {Type: Changed},
+		{Kind: "ConfigMap", Name: "c"}: {Type: Changed},
+	}, results, "all resources are classified without diff text")
+}
+
+func TestHasChanges(t *testing.T) {
+	base, err := parser.ParseYAML(strings.NewReader(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: base
+`))
+	require.NoError(t, err)
+	head, err := parser.ParseYAML(strings.NewReader(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: head
+`))
+	require.NoError(t, err)
+
+	changed, err := HasChanges(base, head, nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = HasChanges(base, base, nil)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	opts := NewOptions().WithExcludeKinds("ConfigMap")
+	changed, err = HasChanges(base, head, opts)
+	require.NoError(t, err)
+	assert.False(t, changed, "options filter the compared resources")
+	assert.False(t, opts.FailFast, "the given options are not modified")
+}
+
+func TestYamlString_RetainObjects(t *testing.T) {
+	baseYaml := `apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  password: YmFzZQ==
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+data:
+  key: value
+`
+	headYaml := `apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  password: aGVhZA==
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added
+data:
+  key: value
+`
+
+	results, err := YamlString(baseYaml, headYaml, nil)
+	require.NoError(t, err)
+	for key, result := range results {
+		assert.Nil(t, result.Base, key.String())
+		assert.Nil(t, result.Head, key.String())
+	}
+
+	opts := NewOptions().WithRetainObjects(true)
+	results, err = YamlString(baseYaml, headYaml, opts)
+	require.NoError(t, err)
+
+	secret := results[ResourceKey{Kind: "Secret", Name: "credentials"}]
+	require.NotNil(t, secret.Base)
+	require.NotNil(t, secret.Head)
+	basePassword, _, _ := unstructured.NestedString(secret.Base.Object, "data", "password")
+	headPassword, _, _ := unstructured.NestedString(secret.Head.Object, "data", "password")
+	assert.NotContains(t, []string{"YmFzZQ==", "aGVhZA=="}, basePassword, "retained secrets are masked")
+	assert.NotEqual(t, basePassword, headPassword)
+	assert.Contains(t, secret.Diff, basePassword, "retained secrets are masked like the diff")
+
+	removed := results[ResourceKey{Kind: "ConfigMap", Name: "removed"}]
+	assert.Equal(t, "removed", removed.Base.GetName())
+	assert.Nil(t, removed.Head)
+	added := results[ResourceKey{Kind: "ConfigMap", Name: "added"}]
+	assert.Nil(t, added.Base)
+	assert.Equal(t, "added", added.Head.GetName())
+
+	// Retained objects are copies, so modifying them does not affect the inputs
+	headObjs, err := parser.ParseYAML(strings.NewReader(headYaml))
+	require.NoError(t, err)
+	results, err = Objects(nil, headObjs, opts.WithClassifyOnly(true))
+	require.NoError(t, err)
+	retained := results[ResourceKey{Kind: "ConfigMap", Name: "added"}].Head
+	require.NotNil(t, retained)
+	retained.SetName("modified")
+	assert.Equal(t, "added", headObjs[1].GetName())
+}
@@ -0,0 +1,42 @@
+package diff
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// LastAppliedAnnotation is the annotation kubectl apply uses to record the applied configuration
+const LastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// LastAppliedConfiguration returns the configuration recorded in the last-applied annotation of obj.
+// The second return value is false when obj has no such annotation.
+func LastAppliedConfiguration(obj *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	data, ok := obj.GetAnnotations()[LastAppliedAnnotation]
+	if !ok || data == "" {
+		return nil, false, nil
+	}
+	applied := &unstructured.Unstructured{}
+	if err := applied.UnmarshalJSON([]byte(data)); err != nil {
+		return nil, false, fmt.Errorf("failed to parse %s annotation of %s/%s: %w", LastAppliedAnnotation, obj.GetKind(), obj.GetName(), err)
+	}
+	return applied, true, nil
+}
+
+// replaceWithLastApplied replaces each object with its last-applied configuration,
+// keeping objects without the annotation as they are
+func replaceWithLastApplied(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	replaced := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		applied, ok, err := LastAppliedConfiguration(obj)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			replaced = append(replaced, obj)
+			continue
+		}
+		replaced = append(replaced, applied)
+	}
+	return replaced, nil
+}
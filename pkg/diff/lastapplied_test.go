@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const liveDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  uid: 0c7c1f6e-1b0a-4b4e-9d7c-1f2a3b4c5d6e
+  resourceVersion: "12345"
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: |
+      {"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"default"},"spec":{"replicas":2}}
+spec:
+  replicas: 2
+  progressDeadlineSeconds: 600
+status:
+  readyReplicas: 2
+`
+
+func TestObjects_UseLastApplied(t *testing.T) {
+	head := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 2
+`
+	key := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}
+
+	t.Run("full live object", func(t *testing.T) {
+		results, err := YamlString(liveDeployment, head, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+	})
+
+	t.Run("last-applied configuration", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.UseLastApplied = true
+		results, err := YamlString(liveDeployment, head, opts)
+		require.NoError(t, err)
+		assert.Equal(t, Unchanged, results[key].Type)
+	})
+
+	t.Run("changed against last-applied configuration", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.UseLastApplied = true
+		results, err := YamlString(liveDeployment, head+"  paused: true\n", opts)
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+		assert.Contains(t, results[key].Diff, "paused: true")
+		assert.NotContains(t, results[key].Diff, "progressDeadlineSeconds")
+	})
+}
+
+func TestLastAppliedConfiguration(t *testing.T) {
+	t.Run("without annotation", func(t *testing.T) {
+		obj := newConfigMap("config", "default", "a")
+		_, ok, err := LastAppliedConfiguration(obj)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("malformed annotation", func(t *testing.T) {
+		obj := newConfigMap("config", "default", "a")
+		obj.SetAnnotations(map[string]string{LastAppliedAnnotation: "{"})
+		_, _, err := LastAppliedConfiguration(obj)
+		assert.ErrorContains(t, err, "failed to parse")
+	})
+}
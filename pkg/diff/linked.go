@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// otherChangesTitle is the heading of resources without linked changes in reports grouped by change set
+const otherChangesTitle = "Other Resources"
+
+// linkedConfigs returns the changed ConfigMaps and Secrets each changed workload in objMap references, in base or
+// head, by workload. Workloads without such references are left out.
+func linkedConfigs(objMap map[ResourceKey]objBaseHead, workloads *workload.Policy) map[ResourceKey][]ResourceKey {
+	changeTypes := make(map[ResourceKey]ChangeType)
+	changed := func(key ResourceKey) bool {
+		v, ok := objMap[key]
+		if !ok {
+			return false
+		}
+		changeType, ok := changeTypes[key]
+		if !ok {
+			changeType, _ = compare(key, v)
+			changeType = summarizedChangeType(changeType, v)
+			changeTypes[key] = changeType
+		}
+		return changeType != Unchanged
+	}
+
+	linked := make(map[ResourceKey][]ResourceKey)
+	for key, v := range objMap {
+		seen := make(map[ResourceKey]bool)
+		for _, obj := range []*unstructured.Unstructured{v.base, v.head} {
+			if obj == nil {
+				continue
+			}
+			gvk := obj.GroupVersionKind()
+			kind, ok := workloads.Lookup(gvk.Group, gvk.Kind)
+			if !ok || !changed(key) {
+				continue
+			}
+			for _, config := range referencedConfigs(obj, kind) {
+				if !seen[config] && changed(config) {
+					seen[config] = true
+					linked[key] = append(linked[key], config)
+				}
+			}
+		}
+		sortResourceKeys(linked[key])
+	}
+	return linked
+}
+
+// reportedLinks returns linked, see linkedConfigs, with the keys the resources are reported under, see objBaseHead.key
+func reportedLinks(objMap map[ResourceKey]objBaseHead, linked map[ResourceKey][]ResourceKey) map[ResourceKey][]ResourceKey {
+	reported := make(map[ResourceKey][]ResourceKey, len(linked))
+	for key, configs := range linked {
+		reportedConfigs := make([]ResourceKey, 0, len(configs))
+		for _, config := range configs {
+			reportedConfigs = append(reportedConfigs, objMap[config].key(config))
+		}
+		reported[objMap[key].key(key)] = reportedConfigs
+	}
+	return reported
+}
+
+// changeSetNames returns the name of the change set of every linked resource: workloads and the ConfigMaps and
+// Secrets linked to them are joined, transitively through shared ConfigMaps and Secrets, and named after the
+// first workload of the set in key order
+func changeSetNames(linked map[ResourceKey][]ResourceKey) map[ResourceKey]string {
+	parent := make(map[ResourceKey]ResourceKey)
+	var find func(ResourceKey) ResourceKey
+	find = func(key ResourceKey) ResourceKey {
+		if p, ok := parent[key]; ok && p != key {
+			root := find(p)
+			parent[key] = root
+			return root
+		}
+		parent[key] = key
+		return key
+	}
+	workloadKeys := make([]ResourceKey, 0, len(linked))
+	for key, configs := range linked {
+		workloadKeys = append(workloadKeys, key)
+		for _, config := range configs {
+			parent[find(config)] = find(key)
+		}
+	}
+
+	// Sets are named after their first workload so that names do not depend on the order of the union
+	sortResourceKeys(workloadKeys)
+	names := make(map[ResourceKey]string)
+	for _, key := range workloadKeys {
+		if _, ok := names[find(key)]; !ok {
+			names[find(key)] = formatResourceKeyShort(key)
+		}
+	}
+	changeSets := make(map[ResourceKey]string, len(parent))
+	for key := range parent {
+		changeSets[key] = names[find(key)]
+	}
+	return changeSets
+}
+
+// StringSummaryMarkdownByChangeSet returns the summary like StringSummaryMarkdown, with workloads and their
+// linked ConfigMap and Secret changes grouped under a heading per change set, see Result.ChangeSet
+func (dr Results) StringSummaryMarkdownByChangeSet() string {
+	var result strings.Builder
+	dr.writeSummaryHeaderMarkdown(&result)
+	for _, group := range dr.listed().groupByChangeSet() {
+		result.WriteString("## " + group.title + "\n\n")
+		group.results.writeSummarySectionsMarkdown(&result, "###")
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// StringDiffMarkdownByChangeSet returns the diff like StringDiffMarkdown, with workloads and their linked
+// ConfigMap and Secret changes grouped under a heading per change set
+func (dr Results) StringDiffMarkdownByChangeSet() string {
+	var result strings.Builder
+
+	if dr.hasDiffContent() {
+		if summaryMarkdown := dr.StringSummaryMarkdownByChangeSet(); summaryMarkdown != "" {
+			result.WriteString(summaryMarkdown)
+			result.WriteString("\n\n---\n\n")
+			dr.writeContentsMarkdown(&result)
+		}
+	}
+
+	for _, group := range dr.groupByChangeSet() {
+		if !group.results.hasDiffContent() {
+			continue
+		}
+		result.WriteString("## " + group.changesTitle + "\n\n")
+		group.results.writeDiffBodiesMarkdown(&result)
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// groupByChangeSet returns results grouped by their change sets, sorted by name with other resources last
+func (dr Results) groupByChangeSet() []scopeSection {
+	groups := make(map[string]Results)
+	for key, diffResult := range dr {
+		if groups[diffResult.ChangeSet] == nil {
+			groups[diffResult.ChangeSet] = make(Results)
+		}
+		groups[diffResult.ChangeSet][key] = diffResult
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	sections := make([]scopeSection, 0, len(groups))
+	for _, name := range names {
+		title := "Change Set " + markdownCode(name)
+		sections = append(sections, scopeSection{title: title, changesTitle: title + " Changes", results: groups[name]})
+	}
+	if other, ok := groups[""]; ok {
+		sections = append(sections, scopeSection{title: otherChangesTitle, changesTitle: "Other Resource Changes", results: other})
+	}
+	return sections
+}
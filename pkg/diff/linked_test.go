@@ -0,0 +1,209 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const linkedBase = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  template:
+    spec:
+      containers:
+      - name: api
+        image: api:1.0.0
+        envFrom:
+        - configMapRef:
+            name: shared
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: shop
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: worker:1.0.0
+      volumes:
+      - name: shared
+        configMap:
+          name: shared
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+  namespace: shop
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: report
+            image: report:1.0.0
+            env:
+            - name: TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: report-token
+                  key: token
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shared
+  namespace: shop
+data:
+  mode: a
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: report-token
+  namespace: shop
+stringData:
+  token: old
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+  namespace: shop
+data:
+  mode: a
+`
+
+func linkedResults(t *testing.T) Results {
+	t.Helper()
+	head := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  template:
+    spec:
+      containers:
+      - name: api
+        image: api:1.1.0
+        envFrom:
+        - configMapRef:
+            name: shared
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: shop
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: worker:1.1.0
+      volumes:
+      - name: shared
+        configMap:
+          name: shared
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+  namespace: shop
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: report
+            image: report:1.1.0
+            env:
+            - name: TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: report-token
+                  key: token
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shared
+  namespace: shop
+data:
+  mode: b
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: report-token
+  namespace: shop
+stringData:
+  token: new
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+  namespace: shop
+data:
+  mode: b
+`
+	results, err := YamlString(linkedBase, head, DefaultOptions())
+	require.NoError(t, err)
+	return results
+}
+
+func TestYamlString_ChangeSets(t *testing.T) {
+	results := linkedResults(t)
+	changeSets := make(map[string]string)
+	for key, diffResult := range results {
+		changeSets[formatResourceKeyShort(key)] = diffResult.ChangeSet
+	}
+	assert.Equal(t, map[string]string{
+		"Deployment/shop/api":      "Deployment/shop/api",
+		"Deployment/shop/worker":   "Deployment/shop/api",
+		"ConfigMap/shop/shared":    "Deployment/shop/api",
+		"CronJob/shop/report":      "CronJob/shop/report",
+		"Secret/shop/report-token": "CronJob/shop/report",
+		"ConfigMap/shop/unrelated": "",
+	}, changeSets)
+
+	t.Run("unchanged configs are not linked", func(t *testing.T) {
+		results, err := YamlString(linkedBase, linkedBase, DefaultOptions())
+		require.NoError(t, err)
+		for _, diffResult := range results {
+			assert.Empty(t, diffResult.ChangeSet)
+		}
+	})
+
+	t.Run("saved results keep change sets", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteResults(&buf, results))
+		saved, err := ReadResults(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, "CronJob/shop/report", saved[ResourceKey{Kind: "Secret", Namespace: "shop", Name: "report-token"}].ChangeSet)
+	})
+}
+
+func TestResults_StringDiffMarkdownByChangeSet(t *testing.T) {
+	output := linkedResults(t).StringDiffMarkdownByChangeSet()
+	assert.Contains(t, output, "## Change Set `CronJob/shop/report`\n\n### Changed Resources (2)\n")
+	assert.Contains(t, output, "## Change Set `Deployment/shop/api`\n\n### Changed Resources (3)\n")
+	assert.Contains(t, output, "## Other Resources\n\n### Changed Resources (1)\n- `ConfigMap/shop/unrelated`\n")
+	assert.Contains(t, output, "## Change Set `Deployment/shop/api` Changes\n\n<a id=")
+	assert.Less(t, strings.Index(output, "## Change Set `CronJob/shop/report` Changes"), strings.Index(output, "## Other Resource Changes"))
+}
@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"strings"
+)
+
+// markdownTextEscaper backslash-escapes the characters that start Markdown or HTML syntax inline, and replaces
+// line breaks, which could end a list item or heading, with spaces
+var markdownTextEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `&`, `\&`,
+	"\r\n", " ", "\n", " ", "\r", " ",
+)
+
+// markdownText escapes s for Markdown text outside code, so that manifest content, e.g. quoted in error
+// messages, is shown literally instead of being rendered as Markdown or HTML
+func markdownText(s string) string {
+	return markdownTextEscaper.Replace(s)
+}
+
+// markdownCode returns s as a Markdown code span, delimited by more backticks than any run in s so that s
+// cannot end it. Line breaks are replaced with spaces to keep the span on one line.
+func markdownCode(s string) string {
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	delimiter := strings.Repeat("`", longestBacktickRun(s)+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		// A space on both sides is stripped when rendering, and keeps the backticks of s apart from the delimiter
+		s = " " + s + " "
+	}
+	return delimiter + s + delimiter
+}
+
+// markdownFence returns a code fence longer than any run of backticks in content, and at least three backticks
+// long, so that content cannot close the code block it is written in
+func markdownFence(content string) string {
+	return strings.Repeat("`", max(3, longestBacktickRun(content)+1))
+}
+
+// longestBacktickRun returns the length of the longest run of consecutive backticks in s
+func longestBacktickRun(s string) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
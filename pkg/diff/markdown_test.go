@@ -0,0 +1,41 @@
+package diff
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownEscaping(t *testing.T) {
+	assert.Equal(t, "```", markdownFence("no backticks"))
+	assert.Equal(t, "````", markdownFence("+  script: |\n+    ```\n+    echo `x`"))
+	assert.Equal(t, "`Secret/default/db`", markdownCode("Secret/default/db"))
+	assert.Equal(t, "`` `tick` ``", markdownCode("`tick`"))
+	assert.Equal(t, `\<script\>alert(1)\</script\> \*a\* b`, markdownText("<script>alert(1)</script>\n*a* b"))
+}
+
+func TestResults_StringDiffMarkdownUntrustedContent(t *testing.T) {
+	key := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "scripts"}
+	failed := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "broken"}
+	results := Results{
+		key:    {Type: Changed, Diff: "===== /ConfigMap default/scripts ======\n-  run: echo\n+  run: |\n+    ```\n+    <img src=x onerror=alert(1)>\n"},
+		failed: {Type: Error, Err: errors.New("invalid value \"<b>x</b>\"")},
+	}
+
+	output := results.StringDiffMarkdown()
+	assert.Contains(t, output, "### /ConfigMap default/scripts\n````diff\n-  run: echo\n+  run: |\n+    ```\n+    <img src=x onerror=alert(1)>\n\n````")
+	assert.Contains(t, output, "- `ConfigMap/default/broken`: invalid value \"\\<b\\>x\\</b\\>\"\n")
+
+	t.Run("truncated diff closes the longer fence", func(t *testing.T) {
+		var body string
+		for i := 0; i < 50; i++ {
+			body += "+    ```\n"
+		}
+		truncated := Results{key: {Type: Changed, Diff: "===== /ConfigMap default/scripts ======\n" + body}}
+		comments := truncated.MarkdownComments(300)
+		last := comments[len(comments)-1]
+		assert.Contains(t, last, "+    ```\n... (truncated)\n````")
+		assert.LessOrEqual(t, len(last), 300)
+	})
+}
@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Environment represents a named set of rendered Kubernetes objects
+type Environment struct {
+	Name    string
+	Objects []*unstructured.Unstructured
+}
+
+// MatrixResults holds the Results of diffing each environment against a reference environment
+type MatrixResults struct {
+	Reference    string             // Name of the reference environment
+	Environments []string           // Names of the compared environments in input order
+	Results      map[string]Results // Results keyed by environment name (base: reference, head: environment)
+}
+
+// Matrix compares each environment against the reference environment and returns the aggregated results
+func Matrix(reference Environment, environments []Environment, opts *Options) (*MatrixResults, error) {
+	matrix := &MatrixResults{
+		Reference:    reference.Name,
+		Environments: make([]string, 0, len(environments)),
+		Results:      make(map[string]Results, len(environments)),
+	}
+
+	for _, env := range environments {
+		if _, exists := matrix.Results[env.Name]; exists || env.Name == reference.Name {
+			return nil, fmt.Errorf("duplicate environment name: %s", env.Name)
+		}
+
+		results, err := Objects(reference.Objects, env.Objects, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff environment %s against %s: %w", env.Name, reference.Name, err)
+		}
+		matrix.Environments = append(matrix.Environments, env.Name)
+		matrix.Results[env.Name] = results
+	}
+	return matrix, nil
+}
+
+// GetResourceKeys returns the sorted union of resource keys across all environments
+func (m *MatrixResults) GetResourceKeys() []ResourceKey {
+	seen := make(map[ResourceKey]bool)
+	keys := make([]ResourceKey, 0)
+	for _, results := range m.Results {
+		for key := range results {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sortResourceKeys(keys)
+	return keys
+}
+
+// ChangeType returns the change type of a resource in the given environment relative to the reference.
+// The second return value is false if the resource exists in neither the reference nor the environment.
+func (m *MatrixResults) ChangeType(env string, key ResourceKey) (ChangeType, bool) {
+	result, ok := m.Results[env][key]
+	return result.Type, ok
+}
+
+// DifferingResourceKeys returns the sorted resource keys that differ from the reference in at least one environment
+func (m *MatrixResults) DifferingResourceKeys() []ResourceKey {
+	keys := make([]ResourceKey, 0)
+	for _, key := range m.GetResourceKeys() {
+		for _, env := range m.Environments {
+			if changeType, ok := m.ChangeType(env, key); ok && changeType != Unchanged {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// HasChanges returns true if any environment differs from the reference
+func (m *MatrixResults) HasChanges() bool {
+	for _, results := range m.Results {
+		if results.HasChanges() {
+			return true
+		}
+	}
+	return false
+}
+
+// StringMatrix returns a plain text table showing the change type of each resource per environment
+func (m *MatrixResults) StringMatrix() string {
+	var result strings.Builder
+
+	keys := m.GetResourceKeys()
+	result.WriteString(fmt.Sprintf("# Matrix: reference %s, %d environments, %d resources, %d differing\n",
+		m.Reference, len(m.Environments), len(keys), len(m.DifferingResourceKeys())))
+	result.WriteString("#\n")
+
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "RESOURCE\t%s\n", strings.Join(m.Environments, "\t"))
+	for _, key := range keys {
+		cells := make([]string, 0, len(m.Environments))
+		for _, env := range m.Environments {
+			cells = append(cells, m.cell(env, key))
+		}
+		fmt.Fprintf(w, "%s\t%s\n", formatResourceKeyShort(key), strings.Join(cells, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Sprintf("Error rendering matrix: %v", err)
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// StringMatrixMarkdown returns a Markdown table showing the change type of each resource per environment
+func (m *MatrixResults) StringMatrixMarkdown() string {
+	var result strings.Builder
+
+	keys := m.GetResourceKeys()
+	result.WriteString("# Kubernetes Manifest Matrix\n\n")
+	result.WriteString(fmt.Sprintf("**Reference**: %s  \n", markdownCode(m.Reference)))
+	result.WriteString(fmt.Sprintf("**Environments**: %d | **Resources**: %d | **Differing**: %d\n\n",
+		len(m.Environments), len(keys), len(m.DifferingResourceKeys())))
+
+	if len(keys) == 0 {
+		return strings.TrimRight(result.String(), "\n")
+	}
+
+	result.WriteString("| Resource |")
+	for _, env := range m.Environments {
+		result.WriteString(fmt.Sprintf(" %s |", env))
+	}
+	result.WriteString("\n| --- |")
+	result.WriteString(strings.Repeat(" --- |", len(m.Environments)))
+	result.WriteString("\n")
+
+	for _, key := range keys {
+		result.WriteString(fmt.Sprintf("| %s |", markdownCode(formatResourceKeyShort(key))))
+		for _, env := range m.Environments {
+			result.WriteString(fmt.Sprintf(" %s |", m.cell(env, key)))
+		}
+		result.WriteString("\n")
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// cell returns the table cell text for a resource in an environment
+func (m *MatrixResults) cell(env string, key ResourceKey) string {
+	changeType, ok := m.ChangeType(env, key)
+	if !ok {
+		return "-"
+	}
+	return changeType.String()
+}
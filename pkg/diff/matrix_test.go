@@ -0,0 +1,135 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newConfigMap(name, namespace, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"data": map[string]any{
+				"key": value,
+			},
+		},
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	reference := Environment{
+		Name: "prod",
+		Objects: []*unstructured.Unstructured{
+			newConfigMap("app-config", "default", "v1"),
+			newConfigMap("shared", "default", "same"),
+		},
+	}
+	environments := []Environment{
+		{
+			Name: "dev",
+			Objects: []*unstructured.Unstructured{
+				newConfigMap("app-config", "default", "v2"),
+				newConfigMap("shared", "default", "same"),
+				newConfigMap("dev-only", "default", "x"),
+			},
+		},
+		{
+			Name: "stg",
+			Objects: []*unstructured.Unstructured{
+				newConfigMap("app-config", "default", "v1"),
+			},
+		},
+	}
+
+	matrix, err := Matrix(reference, environments, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", matrix.Reference)
+	assert.Equal(t, []string{"dev", "stg"}, matrix.Environments)
+	assert.True(t, matrix.HasChanges())
+
+	appConfig := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app-config"}
+	shared := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "shared"}
+	devOnly := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "dev-only"}
+
+	assert.Equal(t, []ResourceKey{appConfig, devOnly, shared}, matrix.GetResourceKeys())
+	assert.Equal(t, []ResourceKey{appConfig, devOnly, shared}, matrix.DifferingResourceKeys())
+
+	tests := []struct {
+		env      string
+		key      ResourceKey
+		expected ChangeType
+		found    bool
+	}{
+		{env: "dev", key: appConfig, expected: Changed, found: true},
+		{env: "dev", key: shared, expected: Unchanged, found: true},
+		{env: "dev", key: devOnly, expected: Created, found: true},
+		{env: "stg", key: appConfig, expected: Unchanged, found: true},
+		{env: "stg", key: shared, expected: Deleted, found: true},
+		{env: "stg", key: devOnly, found: false},
+	}
+	for _, tt := range tests {
+		changeType, found := matrix.ChangeType(tt.env, tt.key)
+		assert.Equal(t, tt.found, found, "%s in %s", tt.key, tt.env)
+		if tt.found {
+			assert.Equal(t, tt.expected, changeType, "%s in %s", tt.key, tt.env)
+		}
+	}
+
+	t.Run("text output", func(t *testing.T) {
+		output := matrix.StringMatrix()
+		assert.Contains(t, output, "# Matrix: reference prod, 2 environments, 3 resources, 3 differing")
+		assert.Contains(t, output, "RESOURCE")
+		assert.Contains(t, output, "ConfigMap/default/app-config")
+		assert.Contains(t, output, "changed")
+		assert.Contains(t, output, "deleted")
+	})
+
+	t.Run("markdown output", func(t *testing.T) {
+		output := matrix.StringMatrixMarkdown()
+		assert.Contains(t, output, "# Kubernetes Manifest Matrix")
+		assert.Contains(t, output, "**Reference**: `prod`")
+		assert.Contains(t, output, "| Resource | dev | stg |")
+		assert.Contains(t, output, "| `ConfigMap/default/dev-only` | created | - |")
+		assert.Contains(t, output, "| `ConfigMap/default/shared` | unchanged | deleted |")
+	})
+}
+
+func TestMatrix_MarkdownBackticks(t *testing.T) {
+	reference := Environment{Name: "prod`eu", Objects: []*unstructured.Unstructured{newConfigMap("app`config", "default", "v1")}}
+	matrix, err := Matrix(reference, []Environment{{Name: "dev"}}, nil)
+	require.NoError(t, err)
+
+	output := matrix.StringMatrixMarkdown()
+	assert.Contains(t, output, "**Reference**: ``prod`eu``")
+	assert.Contains(t, output, "| ``ConfigMap/default/app`config`` | deleted |")
+}
+
+func TestMatrix_NoDifferences(t *testing.T) {
+	objs := []*unstructured.Unstructured{newConfigMap("app-config", "default", "v1")}
+
+	matrix, err := Matrix(Environment{Name: "prod", Objects: objs}, []Environment{{Name: "dev", Objects: objs}}, nil)
+	require.NoError(t, err)
+
+	assert.False(t, matrix.HasChanges())
+	assert.Empty(t, matrix.DifferingResourceKeys())
+	assert.Len(t, matrix.GetResourceKeys(), 1)
+}
+
+func TestMatrix_DuplicateEnvironment(t *testing.T) {
+	objs := []*unstructured.Unstructured{newConfigMap("app-config", "default", "v1")}
+
+	_, err := Matrix(Environment{Name: "prod", Objects: objs}, []Environment{{Name: "dev", Objects: objs}, {Name: "dev", Objects: objs}}, nil)
+	assert.Error(t, err)
+
+	_, err = Matrix(Environment{Name: "prod", Objects: objs}, []Environment{{Name: "prod", Objects: objs}}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NameMapping rewrites resource names before base and head are paired, e.g. to undo the namePrefix
+// of a kustomize overlay so that its resources are compared with those of the base
+type NameMapping struct {
+	Pattern     *regexp.Regexp
+	Replacement string // Replacement of matches, which may refer to submatches as in regexp.Regexp.ReplaceAllString
+}
+
+// StripNamePrefix returns a mapping removing prefix from the start of names
+func StripNamePrefix(prefix string) NameMapping {
+	return NameMapping{Pattern: regexp.MustCompile("^" + regexp.QuoteMeta(prefix)), Replacement: ""}
+}
+
+// StripNameSuffix returns a mapping removing suffix from the end of names
+func StripNameSuffix(suffix string) NameMapping {
+	return NameMapping{Pattern: regexp.MustCompile(regexp.QuoteMeta(suffix) + "$"), Replacement: ""}
+}
+
+// ParseNameMapping parses a sed-style substitution "s/pattern/replacement/". Any character following
+// the "s" may be used as delimiter and escaped with a backslash inside the pattern and replacement.
+func ParseNameMapping(expr string) (NameMapping, error) {
+	if len(expr) < 2 || expr[0] != 's' {
+		return NameMapping{}, fmt.Errorf("invalid name mapping %q: expected s/pattern/replacement/", expr)
+	}
+	delimiter := expr[1]
+	parts := splitUnescaped(expr[2:], delimiter)
+	if len(parts) != 3 || parts[2] != "" {
+		return NameMapping{}, fmt.Errorf("invalid name mapping %q: expected s/pattern/replacement/", expr)
+	}
+	pattern, err := regexp.Compile(parts[0])
+	if err != nil {
+		return NameMapping{}, fmt.Errorf("invalid name mapping %q: %w", expr, err)
+	}
+	return NameMapping{Pattern: pattern, Replacement: parts[1]}, nil
+}
+
+// splitUnescaped splits s at each delimiter not preceded by a backslash, removing the escaping backslashes
+func splitUnescaped(s string, delimiter byte) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == delimiter:
+			current.WriteByte(delimiter)
+			i++
+		case s[i] == delimiter:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(s[i])
+		}
+	}
+	return append(parts, current.String())
+}
+
+// mapNames returns objs with the mappings applied in order to their names and to the names of the ConfigMaps,
+// Secrets and ServiceAccounts the pods of workloads reference, so that references match the mapped names too.
+// Mapped objects are copies; the others are returned as they are. The mapped names only pair base and head, so
+// it also returns the original key of each renamed object by its mapped key, see restoreOriginalKeys.
+func mapNames(objs []*unstructured.Unstructured, mappings []NameMapping, keyFunc KeyFunc, workloads *workload.Policy) ([]*unstructured.Unstructured, map[ResourceKey]ResourceKey) {
+	mapName := func(name string) string {
+		for _, mapping := range mappings {
+			name = mapping.Pattern.ReplaceAllString(name, mapping.Replacement)
+		}
+		return name
+	}
+	mapped := make([]*unstructured.Unstructured, 0, len(objs))
+	originals := make(map[ResourceKey]ResourceKey)
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if _, isWorkload := workloads.Lookup(gvk.Group, gvk.Kind); !isWorkload && mapName(obj.GetName()) == obj.GetName() {
+			mapped = append(mapped, obj)
+			continue
+		}
+		original := obj
+		obj = obj.DeepCopy()
+		changed := mapReferences(obj, mapName, workloads)
+		if name := mapName(obj.GetName()); name != obj.GetName() {
+			obj.SetName(name)
+			originals[keyFunc(obj)] = keyFunc(original)
+			changed = true
+		}
+		if !changed {
+			obj = original
+		}
+		mapped = append(mapped, obj)
+	}
+	return mapped, originals
+}
+
+// mapReferences applies mapName to the names of the ConfigMaps, Secrets, ServiceAccount and image pull Secrets
+// the pod spec of a workload references, reporting whether any changed
+func mapReferences(obj *unstructured.Unstructured, mapName func(string) string, workloads *workload.Policy) bool {
+	gvk := obj.GroupVersionKind()
+	kind, ok := workloads.Lookup(gvk.Group, gvk.Kind)
+	if !ok {
+		return false
+	}
+	value, found, _ := unstructured.NestedFieldNoCopy(obj.Object, kind.PodSpecPath()...)
+	podSpec, ok := value.(map[string]any)
+	if !found || !ok {
+		return false
+	}
+
+	changed := false
+	rename := func(ref map[string]any, field string) {
+		if name, ok := ref[field].(string); ok && name != "" {
+			if mappedName := mapName(name); mappedName != name {
+				ref[field] = mappedName
+				changed = true
+			}
+		}
+	}
+	visitConfigReferences(podSpec, func(_ string, ref map[string]any, field string) {
+		rename(ref, field)
+	})
+	rename(podSpec, "serviceAccountName")
+	rename(podSpec, "serviceAccount")
+	pullSecrets, _ := podSpec["imagePullSecrets"].([]any)
+	for _, pullSecret := range pullSecrets {
+		if pullSecret, ok := pullSecret.(map[string]any); ok {
+			rename(pullSecret, "name")
+		}
+	}
+	return changed
+}
+
+// restoreOriginalKeys reports each resource of objMap paired by mapped names, see mapNames, under the original key
+// of its head, or of its base if it was deleted. A pair whose original keys differ records the original base key
+// as renamed from, so that results, reports and prune scripts only show names found in the manifests.
+func restoreOriginalKeys(objMap map[ResourceKey]objBaseHead, baseOriginals, headOriginals map[ResourceKey]ResourceKey) {
+	for key, v := range objMap {
+		headKey := key
+		if original, ok := headOriginals[key]; ok && v.head != nil {
+			headKey = original
+		}
+		baseKey := key
+		if v.renamedFrom != nil {
+			baseKey = *v.renamedFrom
+		}
+		if original, ok := baseOriginals[baseKey]; ok && v.base != nil {
+			baseKey = original
+		}
+
+		reportKey := baseKey
+		if v.head != nil {
+			reportKey = headKey
+		}
+		if reportKey != key {
+			v.reportKey = &reportKey
+		}
+		if v.base != nil && v.head != nil {
+			v.renamedFrom = nil
+			if baseKey != headKey {
+				v.renamedFrom = &baseKey
+			}
+		}
+		objMap[key] = v
+	}
+}
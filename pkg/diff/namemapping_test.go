@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseNameMapping(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{name: "prefix", expr: "s/^prod-//", input: "prod-web", expected: "web"},
+		{name: "submatch", expr: "s/^(.*)-v[0-9]+$/$1/", input: "web-v2", expected: "web"},
+		{name: "other delimiter", expr: "s|-canary$||", input: "web-canary", expected: "web"},
+		{name: "escaped delimiter", expr: `s/a\/b/c/`, input: "a/b", expected: "c"},
+		{name: "no match", expr: "s/^prod-//", input: "staging-web", expected: "staging-web"},
+		{name: "not a substitution", expr: "^prod-", expectedErr: "expected s/pattern/replacement/"},
+		{name: "missing trailing delimiter", expr: "s/a/b", expectedErr: "expected s/pattern/replacement/"},
+		{name: "trailing text", expr: "s/a/b/g", expectedErr: "expected s/pattern/replacement/"},
+		{name: "invalid pattern", expr: "s/(/x/", expectedErr: "invalid name mapping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, err := ParseNameMapping(tt.expr)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mapping.Pattern.ReplaceAllString(tt.input, mapping.Replacement))
+		})
+	}
+}
+
+func TestObjects_NameMappings(t *testing.T) {
+	base := []*unstructured.Unstructured{newConfigMap("settings", "default", "a"), newConfigMap("feature-flags", "default", "a")}
+	head := []*unstructured.Unstructured{newConfigMap("prod-settings-v2", "default", "b"), newConfigMap("prod-feature-flags", "default", "a")}
+
+	t.Run("without mappings", func(t *testing.T) {
+		results, err := Objects(base, head, DefaultOptions())
+		require.NoError(t, err)
+		assert.Len(t, results.FilterCreated(), 2)
+		assert.Len(t, results.FilterDeleted(), 2)
+	})
+
+	t.Run("prefix and regex mappings", func(t *testing.T) {
+		suffix, err := ParseNameMapping("s/-v[0-9]+$//")
+		require.NoError(t, err)
+		opts := DefaultOptions()
+		opts.NameMappings = []NameMapping{StripNamePrefix("prod-"), suffix}
+		results, err := Objects(base, head, opts)
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+		// Results keep the names of the manifests; the mapped names only pair base and head
+		settings := results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "prod-settings-v2"}]
+		assert.Equal(t, Changed, settings.Type)
+		assert.Equal(t, &ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "settings"}, settings.RenamedFrom)
+		assert.Contains(t, settings.Diff, "ConfigMap default/prod-settings-v2")
+		assert.Equal(t, Unchanged, results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "prod-feature-flags"}].Type)
+		assert.Equal(t, "prod-settings-v2", head[0].GetName())
+	})
+
+	t.Run("suffix", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.NameMappings = []NameMapping{StripNameSuffix("-v2"), StripNamePrefix("prod-")}
+		results, err := Objects(base[:1], head[:1], opts)
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "prod-settings-v2"}].Type)
+	})
+
+	t.Run("deleted resources keep their names for pruning", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.NameMappings = []NameMapping{StripNamePrefix("staging-")}
+		stagingBase := []*unstructured.Unstructured{newConfigMap("staging-legacy", "default", "a")}
+		results, err := Objects(stagingBase, nil, opts)
+		require.NoError(t, err)
+		assert.Equal(t, Deleted, results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "staging-legacy"}].Type)
+		assert.Contains(t, results.PruneScript(), "staging-legacy")
+	})
+}
+
+func TestObjects_NameMappingsRemapReferences(t *testing.T) {
+	deployment := func(prefix string) string {
+		return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ` + prefix + `web
+  namespace: default
+spec:
+  template:
+    spec:
+      serviceAccountName: ` + prefix + `web
+      imagePullSecrets:
+      - name: ` + prefix + `registry
+      containers:
+      - name: web
+        image: nginx:1.25
+        envFrom:
+        - configMapRef:
+            name: ` + prefix + `settings
+        env:
+        - name: PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: ` + prefix + `credentials
+              key: password
+      volumes:
+      - name: config
+        configMap:
+          name: ` + prefix + `settings
+`
+	}
+
+	opts := DefaultOptions()
+	opts.NameMappings = []NameMapping{StripNamePrefix("prod-")}
+	results, err := YamlString(deployment(""), deployment("prod-"), opts)
+	require.NoError(t, err)
+	web := results[ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "prod-web"}]
+	assert.Equal(t, Unchanged, web.Type, web.Diff)
+}
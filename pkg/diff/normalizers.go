@@ -0,0 +1,374 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// deploymentRevisionAnnotation is set by the Deployment controller and increases with every rollout
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// jobTrackingAnnotation is set by the Job controller on Jobs tracked with finalizers
+const jobTrackingAnnotation = "batch.kubernetes.io/job-tracking"
+
+// podTemplateHashLabel is added by the Deployment controller to its ReplicaSets and their pods
+const podTemplateHashLabel = "pod-template-hash"
+
+// jobControllerLabels are added by the Job controller to the pod template and the generated selector
+var jobControllerLabels = []string{"controller-uid", "batch.kubernetes.io/controller-uid", "job-name", "batch.kubernetes.io/job-name"}
+
+// podSpecDefaults are the pod spec fields the API server sets to these values when they are omitted
+var podSpecDefaults = map[string]any{
+	"dnsPolicy":                     "ClusterFirst",
+	"restartPolicy":                 "Always",
+	"schedulerName":                 "default-scheduler",
+	"securityContext":               map[string]any{},
+	"terminationGracePeriodSeconds": int64(30),
+}
+
+// workloadSpecDefaults are the spec fields the API server sets to these values when they are omitted, by "group/Kind"
+var workloadSpecDefaults = map[string]map[string]any{
+	"apps/Deployment": {
+		"progressDeadlineSeconds": int64(600),
+		"revisionHistoryLimit":    int64(10),
+		"strategy": map[string]any{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]any{"maxSurge": "25%", "maxUnavailable": "25%"},
+		},
+	},
+	"apps/StatefulSet": {
+		"podManagementPolicy":  "OrderedReady",
+		"revisionHistoryLimit": int64(10),
+		"updateStrategy": map[string]any{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]any{"partition": int64(0)},
+		},
+		"persistentVolumeClaimRetentionPolicy": map[string]any{"whenDeleted": "Retain", "whenScaled": "Retain"},
+	},
+	"apps/DaemonSet": {
+		"revisionHistoryLimit": int64(10),
+		"updateStrategy": map[string]any{
+			"type":          "RollingUpdate",
+			"rollingUpdate": map[string]any{"maxSurge": int64(0), "maxUnavailable": int64(1)},
+		},
+	},
+}
+
+// KindNormalizer rewrites fields of the base and head object of a resource that are equivalent or
+// assigned by the API server into the same form before they are compared.
+// base is nil for created and head for deleted resources; both are copies the normalizer may modify.
+type KindNormalizer func(base, head *unstructured.Unstructured)
+
+// KindNormalizers maps "group/Kind", e.g. "apps/Deployment" or "/Service", to the normalizer of that kind
+type KindNormalizers map[string]KindNormalizer
+
+// DefaultKindNormalizers returns the built-in normalizers removing the most common false positives:
+//   - Services: nodePort and clusterIP values the API server assigned, i.e. set on only one side
+//     ("clusterIP: None" of headless Services is kept), and the default port protocol TCP
+//   - Workloads: the deprecated serviceAccount alias of serviceAccountName, the default container port protocol TCP,
+//     and pod spec and container fields holding the value the API server defaults them to, set on only one side
+//   - Deployments, StatefulSets and DaemonSets: spec fields such as revisionHistoryLimit holding their default,
+//     set on only one side
+//   - Deployments: the deployment.kubernetes.io/revision annotation
+//   - Jobs: the controller-uid and job-name labels of the pod template and generated selector,
+//     and the batch.kubernetes.io/job-tracking annotation
+//   - ReplicaSets and Pods: the pod-template-hash label
+func DefaultKindNormalizers() KindNormalizers {
+	normalizers := KindNormalizers{"/Service": normalizeService}
+	for _, kind := range workload.Builtin().Kinds {
+		normalizers[kind.Group+"/"+kind.Kind] = chainNormalizers(podSpecNormalizer(kind.PodSpecPath()), podSpecDefaultsNormalizer(kind.PodSpecPath()))
+	}
+	for kind, defaults := range workloadSpecDefaults {
+		normalizers[kind] = chainNormalizers(normalizers[kind], defaultsNormalizer(defaults, "spec"))
+	}
+	normalizers["apps/Deployment"] = chainNormalizers(normalizers["apps/Deployment"], eachObject(normalizeDeployment))
+	normalizers["batch/Job"] = chainNormalizers(normalizers["batch/Job"], eachObject(normalizeJob))
+	normalizers["apps/ReplicaSet"] = chainNormalizers(normalizers["apps/ReplicaSet"], eachObject(normalizeReplicaSet))
+	normalizers["/Pod"] = chainNormalizers(normalizers["/Pod"], eachObject(func(obj *unstructured.Unstructured) {
+		removeNestedKeys(obj.Object, []string{podTemplateHashLabel}, "metadata", "labels")
+	}))
+	return normalizers
+}
+
+// chainNormalizers returns a normalizer applying each of normalizers in turn
+func chainNormalizers(normalizers ...KindNormalizer) KindNormalizer {
+	return func(base, head *unstructured.Unstructured) {
+		for _, normalizer := range normalizers {
+			normalizer(base, head)
+		}
+	}
+}
+
+// eachObject returns a normalizer applying normalize to base and head independently
+func eachObject(normalize func(obj *unstructured.Unstructured)) KindNormalizer {
+	return func(base, head *unstructured.Unstructured) {
+		for _, obj := range []*unstructured.Unstructured{base, head} {
+			if obj != nil {
+				normalize(obj)
+			}
+		}
+	}
+}
+
+// normalizeDeployment drops the revision annotation of the Deployment controller
+func normalizeDeployment(obj *unstructured.Unstructured) {
+	removeNestedKeys(obj.Object, []string{deploymentRevisionAnnotation}, "metadata", "annotations")
+}
+
+// normalizeJob drops the labels and annotations the Job controller adds.
+// The selector is dropped when nothing but the controller labels remain, as when it was generated.
+func normalizeJob(obj *unstructured.Unstructured) {
+	removeNestedKeys(obj.Object, []string{jobTrackingAnnotation}, "metadata", "annotations")
+	removeNestedKeys(obj.Object, jobControllerLabels, "metadata", "labels")
+	removeNestedKeys(obj.Object, jobControllerLabels, "spec", "template", "metadata", "labels")
+	removeNestedKeys(obj.Object, jobControllerLabels, "spec", "selector", "matchLabels")
+	if selector, found, _ := unstructured.NestedMap(obj.Object, "spec", "selector"); found && len(selector) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "spec", "selector")
+	}
+}
+
+// normalizeReplicaSet drops the pod-template-hash label the Deployment controller adds
+func normalizeReplicaSet(obj *unstructured.Unstructured) {
+	hash := []string{podTemplateHashLabel}
+	removeNestedKeys(obj.Object, hash, "metadata", "labels")
+	removeNestedKeys(obj.Object, hash, "spec", "selector", "matchLabels")
+	removeNestedKeys(obj.Object, hash, "spec", "template", "metadata", "labels")
+}
+
+// normalize applies the normalizer of the resource kind to copies of its base and head objects
+func (n KindNormalizers) normalize(key ResourceKey, v objBaseHead) objBaseHead {
+	normalizer, ok := n[key.Group+"/"+key.Kind]
+	if !ok {
+		return v
+	}
+	if v.base != nil {
+		v.base = v.base.DeepCopy()
+	}
+	if v.head != nil {
+		v.head = v.head.DeepCopy()
+	}
+	normalizer(v.base, v.head)
+	return v
+}
+
+// normalizeService drops server-assigned cluster IPs and node ports and defaults port protocols
+func normalizeService(base, head *unstructured.Unstructured) {
+	for _, obj := range []*unstructured.Unstructured{base, head} {
+		if obj != nil {
+			defaultPortProtocols(obj.Object, "spec", "ports")
+		}
+	}
+	if base == nil || head == nil {
+		return
+	}
+	for _, pair := range [][2]*unstructured.Unstructured{{base, head}, {head, base}} {
+		obj, other := pair[0], pair[1]
+		if _, set, _ := unstructured.NestedFieldNoCopy(other.Object, "spec", "clusterIP"); !set {
+			if clusterIP, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterIP"); clusterIP != "None" {
+				unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+				unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+			}
+		}
+	}
+	clearAssignedNodePorts(base, head)
+	clearAssignedNodePorts(head, base)
+}
+
+// clearAssignedNodePorts removes the nodePort of ports of obj whose port in other, matched by name, has none
+func clearAssignedNodePorts(obj, other *unstructured.Unstructured) {
+	otherPorts, _, _ := unstructured.NestedSlice(other.Object, "spec", "ports")
+	explicit := make(map[string]bool)
+	for _, port := range otherPorts {
+		if port, ok := port.(map[string]any); ok {
+			if _, set := port["nodePort"]; set {
+				name, _ := port["name"].(string)
+				explicit[name] = true
+			}
+		}
+	}
+	ports, _, _ := unstructured.NestedSlice(obj.Object, "spec", "ports")
+	for _, port := range ports {
+		if port, ok := port.(map[string]any); ok {
+			if name, _ := port["name"].(string); !explicit[name] {
+				delete(port, "nodePort")
+			}
+		}
+	}
+	if ports != nil {
+		_ = unstructured.SetNestedSlice(obj.Object, ports, "spec", "ports")
+	}
+}
+
+// podSpecNormalizer returns a normalizer for a workload kind whose pod spec is at path
+func podSpecNormalizer(path []string) KindNormalizer {
+	return func(base, head *unstructured.Unstructured) {
+		for _, obj := range []*unstructured.Unstructured{base, head} {
+			if obj == nil {
+				continue
+			}
+			podSpec, found, _ := unstructured.NestedMap(obj.Object, path...)
+			if !found {
+				continue
+			}
+			normalizePodSpec(podSpec)
+			_ = unstructured.SetNestedMap(obj.Object, podSpec, path...)
+		}
+	}
+}
+
+// normalizePodSpec replaces the deprecated serviceAccount with serviceAccountName and defaults container port protocols
+func normalizePodSpec(podSpec map[string]any) {
+	if serviceAccount, ok := podSpec["serviceAccount"].(string); ok {
+		if _, set := podSpec["serviceAccountName"]; !set {
+			podSpec["serviceAccountName"] = serviceAccount
+		}
+		if podSpec["serviceAccountName"] == serviceAccount {
+			delete(podSpec, "serviceAccount")
+		}
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(podSpec, field)
+		for _, container := range containers {
+			if container, ok := container.(map[string]any); ok {
+				defaultPortProtocols(container, "ports")
+			}
+		}
+		if containers != nil {
+			podSpec[field] = containers
+		}
+	}
+}
+
+// podSpecDefaultsNormalizer returns a normalizer dropping defaulted fields of the pod spec at path and its containers,
+// see dropOneSidedDefaults, and the empty creationTimestamp of its pod template
+func podSpecDefaultsNormalizer(path []string) KindNormalizer {
+	return func(base, head *unstructured.Unstructured) {
+		if base == nil || head == nil {
+			return
+		}
+		defaultsNormalizer(podSpecDefaults, path...)(base, head)
+		for _, field := range []string{"initContainers", "containers"} {
+			containersPath := append(append([]string{}, path...), field)
+			baseContainers, _, _ := unstructured.NestedFieldNoCopy(base.Object, containersPath...)
+			headContainers, _, _ := unstructured.NestedFieldNoCopy(head.Object, containersPath...)
+			dropContainerDefaults(baseContainers, headContainers)
+			dropContainerDefaults(headContainers, baseContainers)
+		}
+		if len(path) > 1 {
+			// kubectl get prints "creationTimestamp: null" in the metadata of pod templates
+			metadata := append(append([]string{}, path[:len(path)-1]...), "metadata")
+			removeNestedKeys(base.Object, []string{"creationTimestamp"}, metadata...)
+			removeNestedKeys(head.Object, []string{"creationTimestamp"}, metadata...)
+		}
+	}
+}
+
+// defaultsNormalizer returns a normalizer dropping the fields of the maps at path that hold their value in defaults,
+// see dropOneSidedDefaults
+func defaultsNormalizer(defaults map[string]any, path ...string) KindNormalizer {
+	return func(base, head *unstructured.Unstructured) {
+		if base == nil || head == nil {
+			return
+		}
+		baseValue, _, _ := unstructured.NestedFieldNoCopy(base.Object, path...)
+		headValue, _, _ := unstructured.NestedFieldNoCopy(head.Object, path...)
+		baseMap, baseOK := baseValue.(map[string]any)
+		headMap, headOK := headValue.(map[string]any)
+		if baseOK && headOK {
+			dropOneSidedDefaults(baseMap, headMap, defaults)
+			dropOneSidedDefaults(headMap, baseMap, defaults)
+		}
+	}
+}
+
+// dropContainerDefaults drops the defaulted fields of each container of containers, see dropOneSidedDefaults,
+// compared with the container of the same name in others
+func dropContainerDefaults(containers, others any) {
+	byName := make(map[string]map[string]any)
+	otherList, _ := others.([]any)
+	for _, other := range otherList {
+		if other, ok := other.(map[string]any); ok {
+			name, _ := other["name"].(string)
+			byName[name] = other
+		}
+	}
+	list, _ := containers.([]any)
+	for _, container := range list {
+		container, ok := container.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := container["name"].(string)
+		if other, found := byName[name]; found {
+			image, _ := container["image"].(string)
+			dropOneSidedDefaults(container, other, containerDefaults(image))
+		}
+	}
+}
+
+// containerDefaults returns the container fields the API server sets to these values when they are omitted
+func containerDefaults(image string) map[string]any {
+	return map[string]any{
+		"imagePullPolicy":          defaultImagePullPolicy(image),
+		"resources":                map[string]any{},
+		"terminationMessagePath":   "/dev/termination-log",
+		"terminationMessagePolicy": "File",
+	}
+}
+
+// defaultImagePullPolicy returns the pull policy the API server defaults to for image: Always for the latest or
+// a missing tag, and IfNotPresent for other tags and digests
+func defaultImagePullPolicy(image string) string {
+	if strings.Contains(image, "@") {
+		return "IfNotPresent"
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, found := strings.Cut(name, ":"); !found || tag == "latest" {
+		return "Always"
+	}
+	return "IfNotPresent"
+}
+
+// dropOneSidedDefaults removes the fields of obj that hold their value in defaults while other, the same map of
+// the other object, does not set them, as when the API server filled in the default on one side only
+func dropOneSidedDefaults(obj, other, defaults map[string]any) {
+	for field, value := range defaults {
+		if _, set := other[field]; !set && reflect.DeepEqual(obj[field], value) {
+			delete(obj, field)
+		}
+	}
+}
+
+// defaultPortProtocols sets the protocol of the ports at path in obj to TCP where it is unset
+func defaultPortProtocols(obj map[string]any, path ...string) {
+	ports, found, _ := unstructured.NestedSlice(obj, path...)
+	if !found {
+		return
+	}
+	for _, port := range ports {
+		if port, ok := port.(map[string]any); ok {
+			if _, set := port["protocol"]; !set {
+				port["protocol"] = "TCP"
+			}
+		}
+	}
+	_ = unstructured.SetNestedSlice(obj, ports, path...)
+}
+
+// removeNestedKeys removes keys from the map at path in obj, and the map itself if it is left empty
+func removeNestedKeys(obj map[string]any, keys []string, path ...string) {
+	values, found, _ := unstructured.NestedFieldNoCopy(obj, path...)
+	m, ok := values.(map[string]any)
+	if !found || !ok {
+		return
+	}
+	for _, key := range keys {
+		delete(m, key)
+	}
+	if len(m) == 0 {
+		unstructured.RemoveNestedField(obj, path...)
+	}
+}
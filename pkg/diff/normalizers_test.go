@@ -0,0 +1,401 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjects_KindNormalizers(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      ResourceKey
+		base     string
+		head     string
+		expected ChangeType
+	}{
+		{
+			name: "assigned node port and cluster IP",
+			key:  ResourceKey{Kind: "Service", Namespace: "default", Name: "web"},
+			base: `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+spec:
+  type: NodePort
+  clusterIP: 10.96.12.34
+  clusterIPs: [10.96.12.34]
+  ports:
+  - name: http
+    port: 80
+    protocol: TCP
+    nodePort: 31234
+`,
+			head: `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+spec:
+  type: NodePort
+  ports:
+  - name: http
+    port: 80
+`,
+			expected: Unchanged,
+		},
+		{
+			name: "explicit node port change",
+			key:  ResourceKey{Kind: "Service", Namespace: "default", Name: "web"},
+			base: `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 31234
+`,
+			head: `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 31235
+`,
+			expected: Changed,
+		},
+		{
+			name: "headless service",
+			key:  ResourceKey{Kind: "Service", Namespace: "default", Name: "db"},
+			base: `apiVersion: v1
+kind: Service
+metadata:
+  name: db
+  namespace: default
+spec:
+  ports:
+  - port: 5432
+`,
+			head: `apiVersion: v1
+kind: Service
+metadata:
+  name: db
+  namespace: default
+spec:
+  clusterIP: None
+  ports:
+  - port: 5432
+`,
+			expected: Changed,
+		},
+		{
+			name: "deployment revision, service account alias and container port protocol",
+			key:  ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"},
+			base: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    deployment.kubernetes.io/revision: "7"
+spec:
+  template:
+    spec:
+      serviceAccount: web
+      serviceAccountName: web
+      containers:
+      - name: web
+        image: nginx
+        ports:
+        - containerPort: 80
+          protocol: TCP
+`,
+			head: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  template:
+    spec:
+      serviceAccount: web
+      containers:
+      - name: web
+        image: nginx
+        ports:
+        - containerPort: 80
+`,
+			expected: Unchanged,
+		},
+		{
+			name: "cron job service account",
+			key:  ResourceKey{Group: "batch", Kind: "CronJob", Namespace: "default", Name: "backup"},
+			base: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          serviceAccount: backup
+`,
+			head: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          serviceAccountName: backup
+`,
+			expected: Unchanged,
+		},
+		{
+			name: "job controller labels and annotations",
+			key:  ResourceKey{Group: "batch", Kind: "Job", Namespace: "default", Name: "migrate"},
+			base: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: default
+  annotations:
+    batch.kubernetes.io/job-tracking: ""
+  labels:
+    app: migrate
+    batch.kubernetes.io/controller-uid: 5d3c0a7e-8f1b-4c2d-9e6f-0a1b2c3d4e5f
+    batch.kubernetes.io/job-name: migrate
+    controller-uid: 5d3c0a7e-8f1b-4c2d-9e6f-0a1b2c3d4e5f
+    job-name: migrate
+spec:
+  selector:
+    matchLabels:
+      batch.kubernetes.io/controller-uid: 5d3c0a7e-8f1b-4c2d-9e6f-0a1b2c3d4e5f
+  template:
+    metadata:
+      labels:
+        app: migrate
+        batch.kubernetes.io/controller-uid: 5d3c0a7e-8f1b-4c2d-9e6f-0a1b2c3d4e5f
+        batch.kubernetes.io/job-name: migrate
+        controller-uid: 5d3c0a7e-8f1b-4c2d-9e6f-0a1b2c3d4e5f
+        job-name: migrate
+    spec:
+      restartPolicy: Never
+`,
+			head: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: default
+  labels:
+    app: migrate
+spec:
+  template:
+    metadata:
+      labels:
+        app: migrate
+    spec:
+      restartPolicy: Never
+`,
+			expected: Unchanged,
+		},
+		{
+			name: "replica set pod template hash",
+			key:  ResourceKey{Group: "apps", Kind: "ReplicaSet", Namespace: "default", Name: "web"},
+			base: `apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+    pod-template-hash: 7d4b9c8f6
+spec:
+  selector:
+    matchLabels:
+      app: web
+      pod-template-hash: 7d4b9c8f6
+  template:
+    metadata:
+      labels:
+        app: web
+        pod-template-hash: 7d4b9c8f6
+`,
+			head: `apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+`,
+			expected: Unchanged,
+		},
+		{
+			name: "server-defaulted workload fields",
+			key:  ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"},
+			base: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  progressDeadlineSeconds: 600
+  revisionHistoryLimit: 10
+  strategy:
+    rollingUpdate:
+      maxSurge: 25%
+      maxUnavailable: 25%
+    type: RollingUpdate
+  template:
+    metadata:
+      creationTimestamp: null
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.26
+        imagePullPolicy: IfNotPresent
+        resources: {}
+        terminationMessagePath: /dev/termination-log
+        terminationMessagePolicy: File
+      - name: sidecar
+        image: envoy
+        imagePullPolicy: Always
+      dnsPolicy: ClusterFirst
+      restartPolicy: Always
+      schedulerName: default-scheduler
+      securityContext: {}
+      terminationGracePeriodSeconds: 30
+`,
+			head: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.26
+      - name: sidecar
+        image: envoy
+      restartPolicy: Always
+`,
+			expected: Unchanged,
+		},
+		{
+			name: "change of a defaulted field",
+			key:  ResourceKey{Group: "apps", Kind: "StatefulSet", Namespace: "default", Name: "db"},
+			base: `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: default
+spec:
+  revisionHistoryLimit: 10
+  template:
+    spec:
+      containers:
+      - name: db
+        image: postgres:16
+        imagePullPolicy: IfNotPresent
+`,
+			head: `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: default
+spec:
+  revisionHistoryLimit: 3
+  template:
+    spec:
+      containers:
+      - name: db
+        image: postgres:16
+`,
+			expected: Changed,
+		},
+		{
+			name: "pull policy differing from the default",
+			key:  ResourceKey{Group: "apps", Kind: "DaemonSet", Namespace: "default", Name: "agent"},
+			base: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: agent
+        image: agent:latest
+        imagePullPolicy: IfNotPresent
+`,
+			head: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: agent
+        image: agent:latest
+`,
+			expected: Changed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.KindNormalizers = DefaultKindNormalizers()
+			results, err := YamlString(tt.base, tt.head, opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, results[tt.key].Type)
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		results, err := YamlString(tests[0].base, tests[0].head, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[tests[0].key].Type)
+	})
+
+	t.Run("input objects are not modified", func(t *testing.T) {
+		base := newConfigMap("settings", "default", "a")
+		opts := DefaultOptions()
+		opts.KindNormalizers = KindNormalizers{"/ConfigMap": func(base, _ *unstructured.Unstructured) {
+			unstructured.RemoveNestedField(base.Object, "data")
+		}}
+		results, err := Objects([]*unstructured.Unstructured{base}, []*unstructured.Unstructured{newConfigMap("settings", "default", "b")}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "settings"}].Type)
+		assert.Contains(t, base.Object, "data")
+	})
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// appLabels are the labels naming the application a resource belongs to, in order of precedence
+var appLabels = []string{"app.kubernetes.io/name", "app", "k8s-app"}
+
+// rbacKinds are the "group/Kind" of resources granting permissions, called out in release notes
+var rbacKinds = map[string]bool{
+	"rbac.authorization.k8s.io/Role":               true,
+	"rbac.authorization.k8s.io/ClusterRole":        true,
+	"rbac.authorization.k8s.io/RoleBinding":        true,
+	"rbac.authorization.k8s.io/ClusterRoleBinding": true,
+}
+
+// otherResourcesHeading groups the resources of release notes without an application label
+const otherResourcesHeading = "Other Resources"
+
+// resourceApp returns the application of a resource from its labels, or "" if it has none
+func resourceApp(v objBaseHead) string {
+	labels := resourceLabels(v)
+	for _, label := range appLabels {
+		if app := labels[label]; app != "" {
+			return app
+		}
+	}
+	return ""
+}
+
+// noteVerbs describe a change type in release notes
+var noteVerbs = map[ChangeType]string{
+	Created: "Added",
+	Changed: "Updated",
+	Deleted: "Removed",
+}
+
+// StringReleaseNotes returns the changes as release notes for change-management tickets: callouts for
+// RBAC and exposure changes, followed by the changed resources grouped by application with their image bumps.
+// Diff content is not included.
+func (dr Results) StringReleaseNotes() string {
+	if !dr.HasChanges() {
+		return "No changes."
+	}
+
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+
+	var rbacCallouts, exposureCallouts, errorNotes []string
+	groups := make(map[string][]string)
+	for _, key := range keys {
+		diffResult := dr[key]
+		if diffResult.Type == Error {
+			errorNotes = append(errorNotes, fmt.Sprintf("- %s%s", formatResourceKeyShort(key), errorSuffix(diffResult.Err, ": %v")))
+			continue
+		}
+		verb, ok := noteVerbs[diffResult.Type]
+		if !ok {
+			continue
+		}
+		resource := formatResourceKeyShort(key)
+		if rbacKinds[key.Group+"/"+key.Kind] {
+			rbacCallouts = append(rbacCallouts, fmt.Sprintf("- RBAC: %s %s", verb, resource))
+		}
+		for _, change := range diffResult.ExposureChanges {
+			exposureCallouts = append(exposureCallouts, fmt.Sprintf("- Exposure: %s: %s", resource, change))
+		}
+
+		note := fmt.Sprintf("- %s %s%s", verb, resource, renamedFromSuffix(diffResult.RenamedFrom, " (renamed from %s)"))
+		if len(diffResult.ImmutableChanges) > 0 {
+			note += " (replaced)"
+		}
+		if diffResult.RestartOnly {
+			note += " (" + restartOnlyNote + ")"
+		}
+		for _, change := range diffResult.ImageChanges {
+			note += "\n  - " + change
+		}
+		groups[diffResult.App] = append(groups[diffResult.App], note)
+	}
+
+	apps := make([]string, 0, len(groups))
+	for app := range groups {
+		if app != "" {
+			apps = append(apps, app)
+		}
+	}
+	sort.Strings(apps)
+	if _, ok := groups[""]; ok {
+		apps = append(apps, "")
+	}
+
+	stats := dr.GetStatistics()
+	var result strings.Builder
+	result.WriteString("# Release Notes\n\n")
+	result.WriteString(fmt.Sprintf("%d added, %d updated, %d removed\n", stats.Created, stats.Changed, stats.Deleted))
+	// RBAC callouts come first, as they are the changes most likely to need a security review
+	if callouts := append(rbacCallouts, exposureCallouts...); len(callouts) > 0 {
+		result.WriteString("\n## Callouts\n")
+		result.WriteString(strings.Join(callouts, "\n") + "\n")
+	}
+	for _, app := range apps {
+		heading := app
+		if heading == "" {
+			heading = otherResourcesHeading
+		}
+		result.WriteString(fmt.Sprintf("\n## %s\n", heading))
+		result.WriteString(strings.Join(groups[app], "\n") + "\n")
+	}
+	if len(errorNotes) > 0 {
+		result.WriteString("\n## Errors\n")
+		result.WriteString(strings.Join(errorNotes, "\n") + "\n")
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
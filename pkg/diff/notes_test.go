@@ -0,0 +1,61 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestResults_StringReleaseNotes(t *testing.T) {
+	role := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "Role",
+		"metadata":   map[string]any{"name": "web-reader", "namespace": "default"},
+	}}
+	ingress := newIngress("web", "www.example.com")
+	ingress.SetLabels(map[string]string{"app.kubernetes.io/name": "web"})
+
+	base := []*unstructured.Unstructured{
+		newDeployment("web", map[string]string{"web": "nginx:1.25.3"}),
+		newConfigMap("settings", "default", "a"),
+	}
+	head := []*unstructured.Unstructured{
+		newDeployment("web", map[string]string{"web": "nginx:1.27.0"}),
+		newConfigMap("settings", "default", "a"),
+		ingress,
+		role,
+	}
+	results, err := Objects(base, head, DefaultOptions())
+	require.NoError(t, err)
+
+	expected := `# Release Notes
+
+2 added, 1 updated, 0 removed
+
+## Callouts
+- RBAC: Added Role/default/web-reader
+- Exposure: Ingress/default/web: host www.example.com added
+
+## web
+- Updated Deployment/default/web
+  - container web: nginx 1.25.3 -> 1.27.0
+- Added Ingress/default/web
+
+## Other Resources
+- Added Role/default/web-reader`
+	assert.Equal(t, expected, results.StringReleaseNotes())
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Equal(t, "No changes.", results.FilterUnchanged().StringReleaseNotes())
+	})
+
+	t.Run("saved results", func(t *testing.T) {
+		data, err := results.MarshalJSON()
+		require.NoError(t, err)
+		var loaded Results
+		require.NoError(t, loaded.UnmarshalJSON(data))
+		assert.Equal(t, expected, loaded.StringReleaseNotes())
+	})
+}
@@ -0,0 +1,222 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// NWayResults holds the presence and equality of resources across named states, e.g. git, cluster-a and cluster-b
+type NWayResults struct {
+	States    []string                     // Names of the states, in the order they were given
+	Resources map[ResourceKey]NWayResource // Presence and equality of each resource found in any state
+}
+
+// NWayResource is the presence and equality of a resource across states
+type NWayResource struct {
+	// Groups maps each state containing the resource to its equality group. States whose objects are equal
+	// share a group; groups are numbered from 1 in the order of the states.
+	Groups map[string]int
+}
+
+// Present reports whether the resource exists in the state
+func (r NWayResource) Present(state string) bool {
+	_, ok := r.Groups[state]
+	return ok
+}
+
+// NWay compares each pair of named states and returns the presence and equality of every resource across them.
+// Objects are paired, filtered and normalized by opts as by Objects, but only classified, without diff text.
+// At least two states with distinct names are required; they are reported in the order given.
+func NWay(states []Environment, opts *Options) (*NWayResults, error) {
+	if len(states) < 2 {
+		return nil, fmt.Errorf("n-way comparison requires at least two states, got %d", len(states))
+	}
+	names := make([]string, 0, len(states))
+	seen := make(map[string]bool, len(states))
+	for _, state := range states {
+		if seen[state.Name] {
+			return nil, fmt.Errorf("duplicate state name: %s", state.Name)
+		}
+		seen[state.Name] = true
+		names = append(names, state.Name)
+	}
+
+	classifyOnly := DefaultOptions()
+	if opts != nil {
+		copied := *opts
+		classifyOnly = &copied
+	}
+	classifyOnly.ClassifyOnly = true
+	classifyOnly.OnResult = nil
+	classifyOnly.MaskingAudit = nil
+
+	// equal[key][{i, j}] records that the resource is equal in states i and j; present[key][i] that it exists in state i
+	present := make(map[ResourceKey]map[int]bool)
+	equal := make(map[ResourceKey]map[[2]int]bool)
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			results, err := Objects(states[i].Objects, states[j].Objects, classifyOnly)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare %s with %s: %w", names[i], names[j], err)
+			}
+			for key, result := range results {
+				if present[key] == nil {
+					present[key] = make(map[int]bool)
+					equal[key] = make(map[[2]int]bool)
+				}
+				switch result.Type {
+				case Created:
+					present[key][j] = true
+				case Deleted:
+					present[key][i] = true
+				case Unchanged:
+					equal[key][[2]int{i, j}] = true
+					present[key][i], present[key][j] = true, true
+				default:
+					present[key][i], present[key][j] = true, true
+				}
+			}
+		}
+	}
+
+	nway := &NWayResults{States: names, Resources: make(map[ResourceKey]NWayResource, len(present))}
+	for key, inStates := range present {
+		groups := make(map[string]int, len(inStates))
+		next := 1
+		for j := range names {
+			if !inStates[j] {
+				continue
+			}
+			for i := 0; i < j; i++ {
+				if equal[key][[2]int{i, j}] {
+					groups[names[j]] = groups[names[i]]
+					break
+				}
+			}
+			if groups[names[j]] == 0 {
+				groups[names[j]] = next
+				next++
+			}
+		}
+		nway.Resources[key] = NWayResource{Groups: groups}
+	}
+	return nway, nil
+}
+
+// GetResourceKeys returns the sorted keys of the resources found in any state
+func (n *NWayResults) GetResourceKeys() []ResourceKey {
+	keys := make([]ResourceKey, 0, len(n.Resources))
+	for key := range n.Resources {
+		keys = append(keys, key)
+	}
+	sortResourceKeys(keys)
+	return keys
+}
+
+// DifferingResourceKeys returns the sorted keys of the resources missing from a state or differing between states
+func (n *NWayResults) DifferingResourceKeys() []ResourceKey {
+	keys := make([]ResourceKey, 0)
+	for _, key := range n.GetResourceKeys() {
+		if n.differs(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// HasChanges returns true if any resource is missing from a state or differs between states
+func (n *NWayResults) HasChanges() bool {
+	for key := range n.Resources {
+		if n.differs(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// differs reports whether the resource is missing from a state or differs between states
+func (n *NWayResults) differs(key ResourceKey) bool {
+	groups := n.Resources[key].Groups
+	if len(groups) != len(n.States) {
+		return true
+	}
+	for _, group := range groups {
+		if group != 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// StringMatrix returns a plain text table of the equality group of each resource per state.
+// States sharing a letter hold equal objects, and "-" marks states without the resource.
+func (n *NWayResults) StringMatrix() string {
+	var result strings.Builder
+
+	keys := n.GetResourceKeys()
+	result.WriteString(fmt.Sprintf("# N-way: %d states, %d resources, %d differing\n",
+		len(n.States), len(keys), len(n.DifferingResourceKeys())))
+	result.WriteString("#\n")
+
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "RESOURCE\t%s\n", strings.Join(n.States, "\t"))
+	for _, key := range keys {
+		cells := make([]string, 0, len(n.States))
+		for _, state := range n.States {
+			cells = append(cells, n.cell(state, key))
+		}
+		fmt.Fprintf(w, "%s\t%s\n", formatResourceKeyShort(key), strings.Join(cells, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Sprintf("Error rendering matrix: %v", err)
+	}
+
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// StringMatrixMarkdown returns a Markdown table of the equality group of each resource per state
+func (n *NWayResults) StringMatrixMarkdown() string {
+	var result strings.Builder
+
+	keys := n.GetResourceKeys()
+	result.WriteString("# Kubernetes Manifest N-Way Comparison\n\n")
+	result.WriteString(fmt.Sprintf("**States**: %d | **Resources**: %d | **Differing**: %d\n\n",
+		len(n.States), len(keys), len(n.DifferingResourceKeys())))
+
+	if len(keys) == 0 {
+		return strings.TrimRight(result.String(), "\n")
+	}
+
+	result.WriteString("| Resource |")
+	for _, state := range n.States {
+		result.WriteString(fmt.Sprintf(" %s |", state))
+	}
+	result.WriteString("\n| --- |")
+	result.WriteString(strings.Repeat(" --- |", len(n.States)))
+	result.WriteString("\n")
+
+	for _, key := range keys {
+		result.WriteString(fmt.Sprintf("| %s |", markdownCode(formatResourceKeyShort(key))))
+		for _, state := range n.States {
+			result.WriteString(fmt.Sprintf(" %s |", n.cell(state, key)))
+		}
+		result.WriteString("\n")
+	}
+	result.WriteString("\nStates sharing a letter hold equal objects; `-` marks states without the resource.")
+
+	return result.String()
+}
+
+// cell returns the table cell text for a resource in a state: its group as a letter, or "-" if absent
+func (n *NWayResults) cell(state string, key ResourceKey) string {
+	group, ok := n.Resources[key].Groups[state]
+	if !ok {
+		return "-"
+	}
+	if group <= 26 {
+		return string(rune('A' + group - 1))
+	}
+	return strconv.Itoa(group)
+}
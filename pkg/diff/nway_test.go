@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNWay(t *testing.T) {
+	states := []Environment{
+		{Name: "git", Objects: []*unstructured.Unstructured{
+			newConfigMap("app-config", "default", "v1"),
+			newConfigMap("shared", "default", "same"),
+		}},
+		{Name: "cluster-a", Objects: []*unstructured.Unstructured{
+			newConfigMap("app-config", "default", "v1"),
+			newConfigMap("shared", "default", "same"),
+			newConfigMap("extra", "default", "only-a"),
+		}},
+		{Name: "cluster-b", Objects: []*unstructured.Unstructured{
+			newConfigMap("app-config", "default", "v2"),
+			newConfigMap("shared", "default", "same"),
+		}},
+	}
+
+	nway, err := NWay(states, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"git", "cluster-a", "cluster-b"}, nway.States)
+
+	appConfig := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app-config"}
+	shared := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "shared"}
+	extra := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "extra"}
+	assert.Equal(t, map[string]int{"git": 1, "cluster-a": 1, "cluster-b": 2}, nway.Resources[appConfig].Groups)
+	assert.Equal(t, map[string]int{"cluster-a": 1, "cluster-b": 1, "git": 1}, nway.Resources[shared].Groups)
+	assert.Equal(t, map[string]int{"cluster-a": 1}, nway.Resources[extra].Groups)
+	assert.True(t, nway.Resources[extra].Present("cluster-a"))
+	assert.False(t, nway.Resources[extra].Present("git"))
+
+	assert.Equal(t, []ResourceKey{appConfig, extra}, nway.DifferingResourceKeys())
+	assert.True(t, nway.HasChanges())
+
+	matrix := nway.StringMatrix()
+	assert.Contains(t, matrix, "# N-way: 3 states, 3 resources, 2 differing")
+	assert.Regexp(t, `RESOURCE\s+git\s+cluster-a\s+cluster-b\n`, matrix)
+	assert.Regexp(t, `ConfigMap/default/app-config\s+A\s+A\s+B`, matrix)
+	assert.Regexp(t, `ConfigMap/default/extra\s+-\s+A\s+-`, matrix)
+
+	markdown := nway.StringMatrixMarkdown()
+	assert.Contains(t, markdown, "| Resource | git | cluster-a | cluster-b |")
+	assert.Contains(t, markdown, "| `ConfigMap/default/shared` | A | A | A |")
+}
+
+func TestNWay_MarkdownBackticks(t *testing.T) {
+	nway, err := NWay([]Environment{{Name: "git", Objects: []*unstructured.Unstructured{newConfigMap("app`config", "default", "v1")}}, {Name: "cluster"}}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, nway.StringMatrixMarkdown(), "| ``ConfigMap/default/app`config`` | A | - |")
+}
+
+func TestNWay_Equal(t *testing.T) {
+	objects := []*unstructured.Unstructured{newConfigMap("app-config", "default", "v1")}
+	nway, err := NWay([]Environment{{Name: "a", Objects: objects}, {Name: "b", Objects: objects}}, NewOptions())
+	require.NoError(t, err)
+	assert.False(t, nway.HasChanges())
+	assert.Empty(t, nway.DifferingResourceKeys())
+}
+
+func TestNWay_TooFewStates(t *testing.T) {
+	_, err := NWay([]Environment{{Name: "git"}}, nil)
+	assert.ErrorContains(t, err, "requires at least two states")
+}
+
+func TestNWay_DuplicateStates(t *testing.T) {
+	_, err := NWay([]Environment{{Name: "git"}, {Name: "git"}}, nil)
+	assert.ErrorContains(t, err, "duplicate state name: git")
+}
@@ -0,0 +1,48 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+)
+
+// MatchesOnly reports whether the resource key matches any of the glob patterns.
+// Patterns are matched against "Kind/namespace/name", or "Kind/name" for resources without a namespace,
+// using path.Match syntax, e.g. "Deployment/default/*" or "ConfigMap/*/app-*".
+// An empty pattern list matches every resource.
+func MatchesOnly(key ResourceKey, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+	return matchesResourcePatterns(key, patterns, "only")
+}
+
+// matchesResourcePatterns reports whether the resource key matches any of the glob patterns in the MatchesOnly syntax.
+// kind names the option the patterns come from in error messages.
+func matchesResourcePatterns(key ResourceKey, patterns []string, kind string) (bool, error) {
+	target := formatResourceKeyShort(key)
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, target)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s pattern %q: %w", kind, pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// validateOnlyPatterns returns an error if any pattern is malformed
+func validateOnlyPatterns(patterns []string) error {
+	return validateResourcePatterns(patterns, "only")
+}
+
+// validateResourcePatterns returns an error if any pattern is malformed
+func validateResourcePatterns(patterns []string, kind string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid %s pattern %q: %w", kind, pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesOnly(t *testing.T) {
+	deployment := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}
+	namespace := ResourceKey{Kind: "Namespace", Name: "team-a"}
+
+	tests := []struct {
+		name     string
+		key      ResourceKey
+		patterns []string
+		expected bool
+	}{
+		{name: "no patterns", key: deployment, patterns: nil, expected: true},
+		{name: "exact match", key: deployment, patterns: []string{"Deployment/default/web"}, expected: true},
+		{name: "glob name", key: deployment, patterns: []string{"Deployment/default/w*"}, expected: true},
+		{name: "glob namespace", key: deployment, patterns: []string{"Deployment/*/web"}, expected: true},
+		{name: "any of several", key: deployment, patterns: []string{"ConfigMap/*/*", "Deployment/*/*"}, expected: true},
+		{name: "kind mismatch", key: deployment, patterns: []string{"StatefulSet/default/web"}, expected: false},
+		{name: "star does not cross segments", key: deployment, patterns: []string{"Deployment/*"}, expected: false},
+		{name: "cluster-scoped", key: namespace, patterns: []string{"Namespace/team-*"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := MatchesOnly(tt.key, tt.patterns)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := MatchesOnly(deployment, []string{"Deployment/[default"})
+		assert.ErrorContains(t, err, "invalid only pattern")
+	})
+}
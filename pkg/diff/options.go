@@ -0,0 +1,287 @@
+package diff
+
+import (
+	"io"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+)
+
+// NewOptions returns the default diff options for building with the With methods, e.g.
+//
+//	opts := diff.NewOptions().WithContext(5).WithExcludeKinds("Secret")
+//
+// Every field of Options has a With method, which sets it and returns the options.
+func NewOptions() *Options {
+	return DefaultOptions()
+}
+
+// filterOption returns the filter options, creating them if unset
+func (o *Options) filterOption() *filter.Option {
+	if o.FilterOption == nil {
+		o.FilterOption = filter.DefaultOption()
+	}
+	return o.FilterOption
+}
+
+// WithFilterOption sets the filtering options
+func (o *Options) WithFilterOption(option *filter.Option) *Options {
+	o.FilterOption = option
+	return o
+}
+
+// WithExcludeKinds sets the kinds excluded by the filtering options
+func (o *Options) WithExcludeKinds(kinds ...string) *Options {
+	o.filterOption().ExcludeKinds = kinds
+	return o
+}
+
+// WithLabelSelector sets the label selector of the filtering options
+func (o *Options) WithLabelSelector(selector map[string]string) *Options {
+	o.filterOption().LabelSelector = selector
+	return o
+}
+
+// WithAnnotationSelector sets the annotation selector of the filtering options
+func (o *Options) WithAnnotationSelector(selector map[string]string) *Options {
+	o.filterOption().AnnotationSelector = selector
+	return o
+}
+
+// WithContext sets the number of context lines in diff output
+func (o *Options) WithContext(lines int) *Options {
+	o.Context = lines
+	return o
+}
+
+// WithDisableMaskingSecrets sets whether masking of secret values is disabled
+func (o *Options) WithDisableMaskingSecrets(disable bool) *Options {
+	o.DisableMaskingSecrets = disable
+	return o
+}
+
+// WithDisableMaskingFor sets the glob patterns of resources whose secret values are shown unmasked
+func (o *Options) WithDisableMaskingFor(patterns ...string) *Options {
+	o.DisableMaskingFor = patterns
+	return o
+}
+
+// WithSecretPolicies sets the masking policies by Secret type
+func (o *Options) WithSecretPolicies(policies masking.PolicyTable) *Options {
+	o.SecretPolicies = policies
+	return o
+}
+
+// WithCacheDir sets the directory rendered diffs are cached in
+func (o *Options) WithCacheDir(dir string) *Options {
+	o.CacheDir = dir
+	return o
+}
+
+// WithMinimumChangedLines sets the number of changed lines below which changes are marked Trivial
+func (o *Options) WithMinimumChangedLines(lines int) *Options {
+	o.MinimumChangedLines = lines
+	return o
+}
+
+// WithMaskScope sets the scope within which secret masks are consistent
+func (o *Options) WithMaskScope(scope MaskScope) *Options {
+	o.MaskScope = scope
+	return o
+}
+
+// WithMaskStrategy sets how masked values are rendered
+func (o *Options) WithMaskStrategy(strategy masking.Strategy) *Options {
+	o.MaskStrategy = strategy
+	return o
+}
+
+// WithMaskToken sets the token repeated by masks of the incremental strategy
+func (o *Options) WithMaskToken(token string) *Options {
+	o.MaskToken = token
+	return o
+}
+
+// WithMaskMinLength sets the length of the first mask of the incremental strategy
+func (o *Options) WithMaskMinLength(length int) *Options {
+	o.MaskMinLength = length
+	return o
+}
+
+// WithMaskKey sets the secret key of masks rendered by the hash strategy
+func (o *Options) WithMaskKey(key string) *Options {
+	o.MaskKey = key
+	return o
+}
+
+// WithMaskingAudit sets the writer receiving the masking audit
+func (o *Options) WithMaskingAudit(audit io.Writer) *Options {
+	o.MaskingAudit = audit
+	return o
+}
+
+// WithKeyFunc sets how resource identity is derived for pairing
+func (o *Options) WithKeyFunc(keyFunc KeyFunc) *Options {
+	o.KeyFunc = keyFunc
+	return o
+}
+
+// WithOnly sets the glob patterns selecting the only resources to diff
+func (o *Options) WithOnly(patterns ...string) *Options {
+	o.Only = patterns
+	return o
+}
+
+// WithSeverity sets the policy assigning severities to results
+func (o *Options) WithSeverity(policy *SeverityPolicy) *Options {
+	o.Severity = policy
+	return o
+}
+
+// WithOwners sets the policy assigning owning teams to results
+func (o *Options) WithOwners(policy *OwnershipPolicy) *Options {
+	o.Owners = policy
+	return o
+}
+
+// WithDiffStyle sets how changed resources are rendered
+func (o *Options) WithDiffStyle(style DiffStyle) *Options {
+	o.DiffStyle = style
+	return o
+}
+
+// WithUseLastApplied sets whether head is compared against the last-applied configuration of base objects
+func (o *Options) WithUseLastApplied(use bool) *Options {
+	o.UseLastApplied = use
+	return o
+}
+
+// WithFieldManager sets the manager whose fields of live base objects are compared
+func (o *Options) WithFieldManager(manager string) *Options {
+	o.FieldManager = manager
+	return o
+}
+
+// WithExpandBelowLines sets the number of lines below which changed resources are shown in full
+func (o *Options) WithExpandBelowLines(lines int) *Options {
+	o.ExpandBelowLines = lines
+	return o
+}
+
+// WithFullObjects sets whether the complete YAML of changed resources is rendered
+func (o *Options) WithFullObjects(mode FullObjectsMode) *Options {
+	o.FullObjects = mode
+	return o
+}
+
+// WithUnnamedMatching sets how objects without a name sharing a generateName are paired
+func (o *Options) WithUnnamedMatching(matching UnnamedMatching) *Options {
+	o.UnnamedMatching = matching
+	return o
+}
+
+// WithRenameThreshold sets the similarity above which deleted and created resources are paired as renames
+func (o *Options) WithRenameThreshold(threshold float64) *Options {
+	o.RenameThreshold = threshold
+	return o
+}
+
+// WithConversions sets the policy converting custom resources to a common version
+func (o *Options) WithConversions(policy *ConversionPolicy) *Options {
+	o.Conversions = policy
+	return o
+}
+
+// WithKindNormalizers sets the normalizers applied by kind before comparison
+func (o *Options) WithKindNormalizers(normalizers KindNormalizers) *Options {
+	o.KindNormalizers = normalizers
+	return o
+}
+
+// WithNameMappings sets the mappings rewriting resource names before pairing
+func (o *Options) WithNameMappings(mappings ...NameMapping) *Options {
+	o.NameMappings = mappings
+	return o
+}
+
+// WithIgnoreEOL sets whether CRLF and LF line endings in string values are treated as equal
+func (o *Options) WithIgnoreEOL(ignore bool) *Options {
+	o.IgnoreEOL = ignore
+	return o
+}
+
+// WithIgnoreValueRegexes sets the regular expressions whose matching base and head string values are treated as equal
+func (o *Options) WithIgnoreValueRegexes(patterns ...string) *Options {
+	o.IgnoreValueRegexes = patterns
+	return o
+}
+
+// WithSummarizeStatus sets whether resources are compared without their status, summarizing condition transitions
+func (o *Options) WithSummarizeStatus(summarize bool) *Options {
+	o.SummarizeStatus = summarize
+	return o
+}
+
+// WithSummarizeSealedSecrets sets whether SealedSecrets are compared without their encrypted values, reporting
+// only which keys changed
+func (o *Options) WithSummarizeSealedSecrets(summarize bool) *Options {
+	o.SummarizeSealedSecrets = summarize
+	return o
+}
+
+// WithWorkloads sets the workload kinds whose container images are compared
+func (o *Options) WithWorkloads(policy *workload.Policy) *Options {
+	o.Workloads = policy
+	return o
+}
+
+// WithClassifyOnly sets whether resources are only classified by change type, without diff text
+func (o *Options) WithClassifyOnly(classifyOnly bool) *Options {
+	o.ClassifyOnly = classifyOnly
+	return o
+}
+
+// WithFailFast sets whether diffing stops at the first changed resource, classifying resources without diff text
+func (o *Options) WithFailFast(failFast bool) *Options {
+	o.FailFast = failFast
+	return o
+}
+
+// WithHeadSources sets the locations of head resources in their manifest files, annotating results with the
+// lines of changed fields
+func (o *Options) WithHeadSources(sources parser.SourceMap) *Options {
+	o.HeadSources = sources
+	return o
+}
+
+// WithRetainObjects sets whether the compared base and head objects are retained in results
+func (o *Options) WithRetainObjects(retain bool) *Options {
+	o.RetainObjects = retain
+	return o
+}
+
+// WithPruneTracking sets the tracking labels or annotations of a GitOps application marking deleted resources WouldPrune
+func (o *Options) WithPruneTracking(selector map[string][]string) *Options {
+	o.PruneTracking = selector
+	return o
+}
+
+// WithOnResult sets the function receiving each result as soon as it is computed
+func (o *Options) WithOnResult(onResult ResultFunc) *Options {
+	o.OnResult = onResult
+	return o
+}
+
+// WithPreTransform sets the transforms applied to base and head objects before pairing
+func (o *Options) WithPreTransform(transforms ...Transform) *Options {
+	o.PreTransform = transforms
+	return o
+}
+
+// WithContinueOnError sets whether resources that fail to diff are recorded as Error results instead of aborting the diff
+func (o *Options) WithContinueOnError(continueOnError bool) *Options {
+	o.ContinueOnError = continueOnError
+	return o
+}
@@ -0,0 +1,44 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+)
+
+func TestNewOptions(t *testing.T) {
+	opts := NewOptions().
+		WithContext(5).
+		WithExcludeKinds("Secret", "ConfigMap").
+		WithLabelSelector(map[string]string{"app": "web"}).
+		WithMaskStrategy(masking.StrategyLength).
+		WithDiffStyle(DiffStyleInline).
+		WithIgnoreEOL(true)
+
+	expected := DefaultOptions()
+	expected.Context = 5
+	expected.FilterOption.ExcludeKinds = []string{"Secret", "ConfigMap"}
+	expected.FilterOption.LabelSelector = map[string]string{"app": "web"}
+	expected.MaskStrategy = masking.StrategyLength
+	expected.DiffStyle = DiffStyleInline
+	expected.IgnoreEOL = true
+	assert.Equal(t, expected, opts)
+}
+
+func TestOptions_WithExcludeKindsCreatesFilterOption(t *testing.T) {
+	opts := (&Options{}).WithExcludeKinds("Secret")
+	assert.Equal(t, []string{"Secret"}, opts.FilterOption.ExcludeKinds)
+}
+
+func TestOptions_WithMethodForEveryField(t *testing.T) {
+	optionsType := reflect.TypeOf(&Options{})
+	for i := 0; i < optionsType.Elem().NumField(); i++ {
+		field := optionsType.Elem().Field(i)
+		method, ok := optionsType.MethodByName("With" + field.Name)
+		if assert.True(t, ok, "Options.%s has no With%s method", field.Name, field.Name) {
+			assert.Equal(t, optionsType, method.Type.Out(0), "With%s must return *Options", field.Name)
+		}
+	}
+}
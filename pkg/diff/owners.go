@@ -0,0 +1,149 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// unownedTitle is the heading of resources without owners in reports grouped by owner
+const unownedTitle = "Unowned Resources"
+
+// OwnerRule maps resources to owning teams like a CODEOWNERS entry. Empty fields match anything.
+type OwnerRule struct {
+	Namespace string            `yaml:"namespace,omitempty"` // Namespace glob pattern, e.g. "payments-*"
+	Labels    map[string]string `yaml:"labels,omitempty"`    // Labels the resource must have (exact match)
+	Owners    []string          `yaml:"owners"`              // Team names or handles, e.g. "@acme/payments"
+}
+
+// OwnershipPolicy assigns owners to resources. As in CODEOWNERS, the last matching rule wins.
+type OwnershipPolicy struct {
+	Rules []OwnerRule `yaml:"rules"`
+}
+
+// ReadOwnershipPolicy reads an ownership policy from YAML, e.g.
+//
+//	rules:
+//	- owners: ["@acme/platform"]
+//	- namespace: payments-*
+//	  owners: ["@acme/payments"]
+//	- labels:
+//	    team: search
+//	  owners: ["@acme/search"]
+func ReadOwnershipPolicy(r io.Reader) (*OwnershipPolicy, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ownership policy: %w", err)
+	}
+
+	var policy OwnershipPolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse ownership policy: %w", err)
+	}
+	for i, rule := range policy.Rules {
+		if len(rule.Owners) == 0 {
+			return nil, fmt.Errorf("ownership policy rule %d: owners are required", i+1)
+		}
+		if _, err := path.Match(rule.Namespace, ""); err != nil {
+			return nil, fmt.Errorf("ownership policy rule %d: invalid namespace pattern %q: %w", i+1, rule.Namespace, err)
+		}
+	}
+	return &policy, nil
+}
+
+// OwnersOf returns the owners of a resource with the given labels, or nil if no rule matches
+func (p *OwnershipPolicy) OwnersOf(key ResourceKey, labels map[string]string) []string {
+	if p == nil {
+		return nil
+	}
+	var owners []string
+	for _, rule := range p.Rules {
+		if rule.matches(key, labels) {
+			owners = rule.Owners
+		}
+	}
+	return owners
+}
+
+// matches reports whether the rule applies to a resource
+func (r OwnerRule) matches(key ResourceKey, labels map[string]string) bool {
+	if r.Namespace != "" {
+		if matched, _ := path.Match(r.Namespace, key.Namespace); !matched {
+			return false
+		}
+	}
+	for name, value := range r.Labels {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// StringSummaryMarkdownByOwner returns the summary like StringSummaryMarkdown, with resources
+// grouped under a heading per owning team so that the owners are mentioned
+func (dr Results) StringSummaryMarkdownByOwner() string {
+	var result strings.Builder
+	dr.writeSummaryHeaderMarkdown(&result)
+	for _, group := range dr.listed().groupByOwner() {
+		result.WriteString("## " + group.title + "\n\n")
+		group.results.writeSummarySectionsMarkdown(&result, "###")
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// StringDiffMarkdownByOwner returns the diff like StringDiffMarkdown, with resources
+// grouped under a heading per owning team
+func (dr Results) StringDiffMarkdownByOwner() string {
+	var result strings.Builder
+
+	if dr.hasDiffContent() {
+		if summaryMarkdown := dr.StringSummaryMarkdownByOwner(); summaryMarkdown != "" {
+			result.WriteString(summaryMarkdown)
+			result.WriteString("\n\n---\n\n")
+			dr.writeContentsMarkdown(&result)
+		}
+	}
+
+	for _, group := range dr.groupByOwner() {
+		if !group.results.hasDiffContent() {
+			continue
+		}
+		result.WriteString("## " + group.changesTitle + "\n\n")
+		group.results.writeDiffBodiesMarkdown(&result)
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// groupByOwner returns results grouped by their owners, sorted by owners with unowned resources last
+func (dr Results) groupByOwner() []scopeSection {
+	groups := make(map[string]Results)
+	for key, diffResult := range dr {
+		owners := strings.Join(diffResult.Owners, " ")
+		if groups[owners] == nil {
+			groups[owners] = make(Results)
+		}
+		groups[owners][key] = diffResult
+	}
+
+	names := make([]string, 0, len(groups))
+	for owners := range groups {
+		if owners != "" {
+			names = append(names, owners)
+		}
+	}
+	sort.Strings(names)
+
+	sections := make([]scopeSection, 0, len(groups))
+	for _, owners := range names {
+		sections = append(sections, scopeSection{title: owners, changesTitle: "Changes for " + owners, results: groups[owners]})
+	}
+	if unowned, ok := groups[""]; ok {
+		sections = append(sections, scopeSection{title: unownedTitle, changesTitle: "Unowned Resource Changes", results: unowned})
+	}
+	return sections
+}
@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOwnershipPolicy(t *testing.T) {
+	t.Run("valid policy", func(t *testing.T) {
+		policy, err := ReadOwnershipPolicy(strings.NewReader(`
+rules:
+- owners: ["@acme/platform"]
+- namespace: payments-*
+  owners: ["@acme/payments"]
+- labels:
+    team: search
+  owners: ["@acme/search", "@alice"]
+`))
+		require.NoError(t, err)
+		assert.Len(t, policy.Rules, 3)
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "missing owners", input: "rules:\n- namespace: payments\n", expected: "owners are required"},
+		{name: "invalid pattern", input: "rules:\n- namespace: \"[payments\"\n  owners: [\"@acme/payments\"]\n", expected: "invalid namespace pattern"},
+		{name: "unknown field", input: "rules:\n- team: payments\n  owners: [\"@acme/payments\"]\n", expected: "failed to parse ownership policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadOwnershipPolicy(strings.NewReader(tt.input))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestOwnershipPolicy_OwnersOf(t *testing.T) {
+	policy := &OwnershipPolicy{Rules: []OwnerRule{
+		{Owners: []string{"@acme/platform"}},
+		{Namespace: "payments-*", Owners: []string{"@acme/payments"}},
+		{Labels: map[string]string{"team": "search"}, Owners: []string{"@acme/search"}},
+	}}
+
+	tests := []struct {
+		name     string
+		policy   *OwnershipPolicy
+		key      ResourceKey
+		labels   map[string]string
+		expected []string
+	}{
+		{name: "nil policy", policy: nil, key: ResourceKey{Kind: "ConfigMap", Namespace: "payments-api", Name: "app"}, expected: nil},
+		{name: "catch-all rule", policy: policy, key: ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app"}, expected: []string{"@acme/platform"}},
+		{name: "namespace glob", policy: policy, key: ResourceKey{Kind: "ConfigMap", Namespace: "payments-api", Name: "app"}, expected: []string{"@acme/payments"}},
+		{name: "last matching rule wins", policy: policy, key: ResourceKey{Kind: "ConfigMap", Namespace: "payments-api", Name: "app"}, labels: map[string]string{"team": "search"}, expected: []string{"@acme/search"}},
+		{name: "no match", policy: &OwnershipPolicy{Rules: policy.Rules[1:]}, key: ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app"}, expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.policy.OwnersOf(tt.key, tt.labels))
+		})
+	}
+}
+
+func TestResults_MarkdownByOwner(t *testing.T) {
+	results := Results{
+		{Kind: "ConfigMap", Namespace: "payments", Name: "app"}: {Type: Changed, Diff: "===== /ConfigMap payments/app ======\n-a\n+b\n", Owners: []string{"@acme/payments"}},
+		{Kind: "ConfigMap", Namespace: "search", Name: "app"}:   {Type: Created, Diff: "===== /ConfigMap search/app ======\n+a\n", Owners: []string{"@acme/search", "@alice"}},
+		{Kind: "Namespace", Name: "shared"}:                     {Type: Deleted, Diff: "===== /Namespace /shared ======\n-a\n"},
+	}
+
+	summary := results.StringSummaryMarkdownByOwner()
+	assert.Contains(t, summary, "## @acme/payments\n\n### Changed Resources (1)\n- `ConfigMap/payments/app`\n")
+	assert.Contains(t, summary, "## @acme/search @alice\n\n### Created Resources (1)\n")
+	assert.Contains(t, summary, "## Unowned Resources\n\n### Deleted Resources (1)\n- `Namespace/shared`")
+	assert.Less(t, strings.Index(summary, "@acme/payments"), strings.Index(summary, "@acme/search"))
+	assert.Less(t, strings.Index(summary, "@acme/search"), strings.Index(summary, "Unowned Resources"))
+
+	diffOutput := results.StringDiffMarkdownByOwner()
+	assert.Contains(t, diffOutput, "## Changes for @acme/payments\n\n<a id=\"configmap-payments-app-7e7cd79f\"></a>\n### /ConfigMap payments/app\n```diff\n")
+	assert.Contains(t, diffOutput, "## Unowned Resource Changes\n\n<a id=\"namespace-shared-a4fa38ea\"></a>\n### /Namespace shared\n")
+}
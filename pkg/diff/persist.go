@@ -0,0 +1,348 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// resultsFormatVersion is the version of the serialized Results format
+const resultsFormatVersion = 1
+
+// serializedResults is the JSON representation of Results
+type serializedResults struct {
+	Version   int                  `json:"version"`
+	Options   *EffectiveOptions    `json:"options,omitempty"`
+	Resources []serializedResource `json:"resources"`
+}
+
+// serializedResource is the JSON representation of a single resource result
+type serializedResource struct {
+	Group     string            `json:"group,omitempty"`
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace,omitempty"`
+	Name      string            `json:"name"`
+	Type      ChangeType        `json:"type"`
+	Diff      string            `json:"diff,omitempty"`
+	Trivial   bool              `json:"trivial,omitempty"`
+	Cluster   bool              `json:"clusterScoped,omitempty"`
+	Prune     bool              `json:"wouldPrune,omitempty"`
+	Restart   bool              `json:"restartOnly,omitempty"`
+	Immutable []string          `json:"immutableChanges,omitempty"`
+	Certs     []string          `json:"certificateChanges,omitempty"`
+	Registry  []string          `json:"registryChanges,omitempty"`
+	Sources   []string          `json:"secretSourceChanges,omitempty"`
+	Images    []string          `json:"imageChanges,omitempty"`
+	Exposure  []string          `json:"exposureChanges,omitempty"`
+	App       string            `json:"app,omitempty"`
+	Severity  Severity          `json:"severity,omitempty"`
+	Owners    []string          `json:"owners,omitempty"`
+	ChangeSet string            `json:"changeSet,omitempty"`
+	Renamed   *serializedKey    `json:"renamedFrom,omitempty"`
+	Detail    string            `json:"comparisonDetail,omitempty"`
+	Status    []string          `json:"statusChanges,omitempty"`
+	SOPS      []string          `json:"sopsChanges,omitempty"`
+	Sealed    []string          `json:"sealedSecretChanges,omitempty"`
+	Lines     []FieldAnnotation `json:"annotations,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// serializedKey is the JSON representation of a ResourceKey referenced by a resource result
+type serializedKey struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// newSerializedKey returns the JSON representation of key, or nil if key is nil
+func newSerializedKey(key *ResourceKey) *serializedKey {
+	if key == nil {
+		return nil
+	}
+	return &serializedKey{Group: key.Group, Kind: key.Kind, Namespace: key.Namespace, Name: key.Name}
+}
+
+// resourceKey returns the ResourceKey represented by k, or nil if k is nil
+func (k *serializedKey) resourceKey() *ResourceKey {
+	if k == nil {
+		return nil
+	}
+	return &ResourceKey{Group: k.Group, Kind: k.Kind, Namespace: k.Namespace, Name: k.Name}
+}
+
+// MarshalText returns the string representation of ChangeType
+func (ct ChangeType) MarshalText() ([]byte, error) {
+	if ct < Unchanged || ct > Error {
+		return nil, fmt.Errorf("unknown change type: %d", int(ct))
+	}
+	return []byte(ct.String()), nil
+}
+
+// UnmarshalText parses the string representation of ChangeType
+func (ct *ChangeType) UnmarshalText(text []byte) error {
+	parsed, err := ParseChangeType(string(text))
+	if err != nil {
+		return err
+	}
+	*ct = parsed
+	return nil
+}
+
+// ParseChangeType parses a change type name such as "changed" (case-insensitive)
+func ParseChangeType(s string) (ChangeType, error) {
+	for _, ct := range []ChangeType{Unchanged, Changed, Created, Deleted, Error} {
+		if strings.EqualFold(s, ct.String()) {
+			return ct, nil
+		}
+	}
+	return Unchanged, fmt.Errorf("unknown change type: %s (supported: unchanged, changed, created, deleted, error)", s)
+}
+
+// MarshalJSON encodes Results as a versioned list of resources sorted by Kind, Namespace and Name
+func (dr Results) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dr.serialize())
+}
+
+// serialize returns the JSON representation of Results
+func (dr Results) serialize() serializedResults {
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+
+	out := serializedResults{Version: resultsFormatVersion, Resources: make([]serializedResource, 0, len(keys))}
+	for _, key := range keys {
+		out.Resources = append(out.Resources, newSerializedResource(key, dr[key]))
+	}
+	return out
+}
+
+// newSerializedResource returns the JSON representation of the result of a resource
+func newSerializedResource(key ResourceKey, result Result) serializedResource {
+	return serializedResource{
+		Group:     key.Group,
+		Kind:      key.Kind,
+		Namespace: key.Namespace,
+		Name:      key.Name,
+		Type:      result.Type,
+		Diff:      result.Diff,
+		Trivial:   result.Trivial,
+		Cluster:   result.ClusterScoped,
+		Prune:     result.WouldPrune,
+		Restart:   result.RestartOnly,
+		Immutable: result.ImmutableChanges,
+		Certs:     result.CertificateChanges,
+		Registry:  result.RegistryChanges,
+		Sources:   result.SecretSourceChanges,
+		Images:    result.ImageChanges,
+		Exposure:  result.ExposureChanges,
+		App:       result.App,
+		Severity:  result.Severity,
+		Owners:    result.Owners,
+		ChangeSet: result.ChangeSet,
+		Renamed:   newSerializedKey(result.RenamedFrom),
+		Detail:    result.ComparisonDetail,
+		Status:    result.StatusChanges,
+		SOPS:      result.SOPSChanges,
+		Sealed:    result.SealedSecretChanges,
+		Lines:     result.Annotations,
+		Error:     errorMessage(result.Err),
+	}
+}
+
+// UnmarshalJSON decodes Results encoded by MarshalJSON
+func (dr *Results) UnmarshalJSON(data []byte) error {
+	var in serializedResults
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	results, err := in.results()
+	if err != nil {
+		return err
+	}
+	*dr = results
+	return nil
+}
+
+// results returns the Results represented by in
+func (in serializedResults) results() (Results, error) {
+	if in.Version != resultsFormatVersion {
+		return nil, fmt.Errorf("unsupported results format version: %d", in.Version)
+	}
+
+	results := make(Results, len(in.Resources))
+	for _, resource := range in.Resources {
+		key := ResourceKey{Group: resource.Group, Kind: resource.Kind, Namespace: resource.Namespace, Name: resource.Name}
+		results[key] = Result{
+			Type:                resource.Type,
+			Diff:                resource.Diff,
+			Trivial:             resource.Trivial,
+			ClusterScoped:       resource.Cluster,
+			WouldPrune:          resource.Prune,
+			RestartOnly:         resource.Restart,
+			ImmutableChanges:    resource.Immutable,
+			CertificateChanges:  resource.Certs,
+			RegistryChanges:     resource.Registry,
+			SecretSourceChanges: resource.Sources,
+			ImageChanges:        resource.Images,
+			ExposureChanges:     resource.Exposure,
+			App:                 resource.App,
+			Severity:            resource.Severity,
+			Owners:              resource.Owners,
+			ChangeSet:           resource.ChangeSet,
+			RenamedFrom:         resource.Renamed.resourceKey(),
+			ComparisonDetail:    resource.Detail,
+			StatusChanges:       resource.Status,
+			SOPSChanges:         resource.SOPS,
+			SealedSecretChanges: resource.Sealed,
+			Annotations:         resource.Lines,
+			Err:                 resultError(resource.Error),
+		}
+	}
+	return results, nil
+}
+
+// errorMessage returns the message of err, or "" if err is nil
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// resultError returns the error of a result read with the message, or nil if the message is empty
+func resultError(message string) error {
+	if message == "" {
+		return nil
+	}
+	return errors.New(message)
+}
+
+// WriteResults writes results as JSON so they can be rendered later without recomputing the diff
+func WriteResults(w io.Writer, results Results) error {
+	return WriteResultsWithOptions(w, results, nil)
+}
+
+// WriteResultsWithOptions writes results as JSON together with the options they were computed with,
+// see Options.Effective. The options are omitted when nil.
+func WriteResultsWithOptions(w io.Writer, results Results, options *EffectiveOptions) error {
+	out := results.serialize()
+	out.Options = options
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// WriteResultLine writes the result of a resource as a single line of JSON (JSON Lines), with the fields of a
+// resource written by WriteResults, so that results can be streamed to other tools as they are computed
+func WriteResultLine(w io.Writer, key ResourceKey, result Result) error {
+	if err := json.NewEncoder(w).Encode(newSerializedResource(key, result)); err != nil {
+		return fmt.Errorf("failed to write result of %s: %w", key, err)
+	}
+	return nil
+}
+
+// WriteResultLines writes results sorted by Kind, Namespace and Name with WriteResultLine
+func WriteResultLines(w io.Writer, results Results) error {
+	keys := results.GetResourceKeys()
+	sortResourceKeys(keys)
+	for _, key := range keys {
+		if err := WriteResultLine(w, key, results[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadResults reads results written by WriteResults or WriteResultsWithOptions
+func ReadResults(r io.Reader) (Results, error) {
+	results, _, err := ReadResultsWithOptions(r)
+	return results, err
+}
+
+// ReadResultsWithOptions reads results and the options they were computed with, which are nil if none were written
+func ReadResultsWithOptions(r io.Reader) (Results, *EffectiveOptions, error) {
+	var in serializedResults
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	results, err := in.results()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	return results, in.Options, nil
+}
+
+// RunComparison describes how the results of two diff runs differ
+type RunComparison struct {
+	Added    []ResourceKey // Resources with changes only in the current run
+	Removed  []ResourceKey // Resources with changes only in the previous run
+	Modified []ResourceKey // Resources with changes in both runs whose change type or diff differs
+}
+
+// CompareRuns compares the results of a previous diff run with the current one
+func CompareRuns(previous, current Results) RunComparison {
+	var comparison RunComparison
+
+	keys := make(map[ResourceKey]bool)
+	for key := range previous {
+		keys[key] = true
+	}
+	for key := range current {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		before, inPrevious := previous[key]
+		after, inCurrent := current[key]
+		changedBefore := inPrevious && before.Type != Unchanged
+		changedAfter := inCurrent && after.Type != Unchanged
+
+		switch {
+		case changedAfter && !changedBefore:
+			comparison.Added = append(comparison.Added, key)
+		case changedBefore && !changedAfter:
+			comparison.Removed = append(comparison.Removed, key)
+		case changedBefore && changedAfter && (before.Type != after.Type || before.Diff != after.Diff):
+			comparison.Modified = append(comparison.Modified, key)
+		}
+	}
+
+	sortResourceKeys(comparison.Added)
+	sortResourceKeys(comparison.Removed)
+	sortResourceKeys(comparison.Modified)
+	return comparison
+}
+
+// HasDifferences returns true if the two runs differ
+func (c RunComparison) HasDifferences() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0 || len(c.Modified) > 0
+}
+
+// String returns the comparison as sections of resources
+func (c RunComparison) String() string {
+	if !c.HasDifferences() {
+		return "# Runs are identical"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# Run comparison: %d added, %d removed, %d modified\n",
+		len(c.Added), len(c.Removed), len(c.Modified)))
+	writeSection := func(title string, keys []ResourceKey) {
+		if len(keys) == 0 {
+			return
+		}
+		result.WriteString(fmt.Sprintf("%s (%d):\n", title, len(keys)))
+		for _, key := range keys {
+			result.WriteString(fmt.Sprintf("  %s\n", formatResourceKeyShort(key)))
+		}
+	}
+	writeSection("Added", c.Added)
+	writeSection("Removed", c.Removed)
+	writeSection("Modified", c.Modified)
+	return strings.TrimRight(result.String(), "\n")
+}
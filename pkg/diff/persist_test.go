@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResults_JSONRoundTrip(t *testing.T) {
+	results := Results{
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}: {Type: Changed, Diff: "===== apps/Deployment default/web ======\n-a\n+b\n", Trivial: true, ImmutableChanges: []string{"spec.selector"}, Severity: SeverityHigh, Owners: []string{"@acme/web"}, RenamedFrom: &ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web-old"}, ComparisonDetail: "replicas differ", StatusChanges: []string{"Ready: True -> False"}},
+		{Kind: "Namespace", Name: "team-a"}:                                    {Type: Created, Diff: "===== /Namespace /team-a ======\n+x\n"},
+		{Kind: "ConfigMap", Namespace: "default", Name: "config"}:              {Type: Unchanged},
+		{Kind: "Secret", Namespace: "default", Name: "malformed"}:              {Type: Error, Err: errors.New("secret validation failed")},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteResults(&buf, results))
+	assert.Contains(t, buf.String(), `"version": 1`)
+	assert.Contains(t, buf.String(), `"type": "changed"`)
+	assert.Contains(t, buf.String(), `"name": "web-old"`)
+
+	loaded, err := ReadResults(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, results, loaded)
+}
+
+func TestWriteResultsWithOptions(t *testing.T) {
+	results := Results{{Kind: "ConfigMap", Namespace: "default", Name: "config"}: {Type: Changed, Diff: "-a\n+b\n"}}
+	options := NewOptions().WithExcludeKinds("Secret").WithMaskKey("s3cr3t").Effective()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteResultsWithOptions(&buf, results, options))
+	assert.Contains(t, buf.String(), `"excludeKinds": [`)
+	assert.Contains(t, buf.String(), `"maskKeySet": true`)
+	assert.NotContains(t, buf.String(), "s3cr3t")
+
+	loaded, loadedOptions, err := ReadResultsWithOptions(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, results, loaded)
+	assert.Equal(t, options, loadedOptions)
+
+	buf.Reset()
+	require.NoError(t, WriteResults(&buf, results))
+	_, loadedOptions, err = ReadResultsWithOptions(&buf)
+	require.NoError(t, err)
+	assert.Nil(t, loadedOptions)
+}
+
+func TestWriteResultLines(t *testing.T) {
+	results := Results{
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}: {Type: Changed, Diff: "-a\n+b\n", Severity: SeverityHigh},
+		{Kind: "ConfigMap", Namespace: "default", Name: "config"}:              {Type: Unchanged},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteResultLines(&buf, results))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"kind":"ConfigMap","namespace":"default","name":"config","type":"unchanged"}`, lines[0])
+	assert.JSONEq(t, `{"group":"apps","kind":"Deployment","namespace":"default","name":"web","type":"changed","diff":"-a\n+b\n","severity":"high"}`, lines[1])
+}
+
+func TestResults_UnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unsupported version", data: `{"version": 99, "resources": []}`},
+		{name: "unknown change type", data: `{"version": 1, "resources": [{"kind": "ConfigMap", "name": "a", "type": "renamed"}]}`},
+		{name: "invalid json", data: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results Results
+			assert.Error(t, json.Unmarshal([]byte(tt.data), &results))
+		})
+	}
+}
+
+func TestParseChangeType(t *testing.T) {
+	ct, err := ParseChangeType("Deleted")
+	require.NoError(t, err)
+	assert.Equal(t, Deleted, ct)
+
+	_, err = ParseChangeType("moved")
+	assert.Error(t, err)
+}
+
+func TestCompareRuns(t *testing.T) {
+	stable := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "stable"}
+	fixed := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "fixed"}
+	flapping := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "flapping"}
+	newlyChanged := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "new"}
+
+	previous := Results{
+		stable:   {Type: Changed, Diff: "same"},
+		fixed:    {Type: Changed, Diff: "old"},
+		flapping: {Type: Changed, Diff: "v1"},
+	}
+	current := Results{
+		stable:       {Type: Changed, Diff: "same"},
+		fixed:        {Type: Unchanged},
+		flapping:     {Type: Changed, Diff: "v2"},
+		newlyChanged: {Type: Created, Diff: "created"},
+	}
+
+	comparison := CompareRuns(previous, current)
+	assert.Equal(t, []ResourceKey{newlyChanged}, comparison.Added)
+	assert.Equal(t, []ResourceKey{fixed}, comparison.Removed)
+	assert.Equal(t, []ResourceKey{flapping}, comparison.Modified)
+	assert.True(t, comparison.HasDifferences())
+
+	output := comparison.String()
+	assert.True(t, strings.HasPrefix(output, "# Run comparison: 1 added, 1 removed, 1 modified"))
+	assert.Contains(t, output, "Modified (1):\n  ConfigMap/default/flapping")
+
+	identical := CompareRuns(previous, previous)
+	assert.False(t, identical.HasDifferences())
+	assert.Equal(t, "# Runs are identical", identical.String())
+}
@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// planAction is the action applying a change would take on a resource
+type planAction struct {
+	symbol string
+	verb   string
+}
+
+var (
+	planCreate  = planAction{symbol: "+", verb: "create"}
+	planUpdate  = planAction{symbol: "~", verb: "update"}
+	planReplace = planAction{symbol: "-/+", verb: "replace"}
+	planRename  = planAction{symbol: "-/+", verb: "rename"}
+	planDestroy = planAction{symbol: "-", verb: "destroy"}
+)
+
+// StringPlan returns the results as a terraform-style plan listing the action for each resource followed by its diff
+func (dr Results) StringPlan() string {
+	return dr.stringPlan(true)
+}
+
+// StringPlanSummary returns the results as a terraform-style plan without diff content
+func (dr Results) StringPlanSummary() string {
+	return dr.stringPlan(false)
+}
+
+// stringPlan renders the plan, including diff bodies of non-trivial changes if withDiff is set
+func (dr Results) stringPlan(withDiff bool) string {
+	if !dr.HasChanges() {
+		return "No changes. Manifests are up-to-date."
+	}
+
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+
+	var result strings.Builder
+	result.WriteString("Resource actions are indicated with the following symbols:\n")
+	result.WriteString("  + create\n  ~ update in-place\n-/+ destroy and then create replacement\n-/+ rename (destroy the original and create under the new name)\n  - destroy\n\n")
+	result.WriteString("k8s-manifest-diff will perform the following actions:\n\n")
+
+	counts := make(map[planAction]int)
+	failed := 0
+	for _, key := range keys {
+		diffResult := dr[key]
+		if diffResult.Type == Error {
+			failed++
+			result.WriteString(fmt.Sprintf("%3s error %s/%s %s/%s%s\n\n", "!", key.Group, key.Kind, key.Namespace, key.Name, errorSuffix(diffResult.Err, ": %v")))
+			continue
+		}
+		action, ok := diffResult.planAction()
+		if !ok {
+			continue
+		}
+		counts[action]++
+
+		line := fmt.Sprintf("%3s %s %s/%s %s/%s", action.symbol, action.verb, key.Group, key.Kind, key.Namespace, key.Name)
+		line += renamedFromSuffix(diffResult.RenamedFrom, " (from %s)")
+		if diffResult.WouldPrune {
+			line += " (would be pruned)"
+		}
+		if diffResult.RestartOnly {
+			line += " (" + restartOnlyNote + ")"
+		}
+		if action == planReplace {
+			line += fmt.Sprintf(" (forces replacement: %s)", strings.Join(diffResult.ImmutableChanges, ", "))
+		}
+		result.WriteString(line + "\n")
+
+		if withDiff && !diffResult.Trivial {
+			writePlanDiffBody(&result, diffResult.Diff)
+		}
+		result.WriteString("\n")
+	}
+
+	result.WriteString(fmt.Sprintf("Plan: %d to create, %d to update, %d to replace, %d to destroy.",
+		counts[planCreate], counts[planUpdate], counts[planReplace], counts[planDestroy]))
+	if counts[planRename] > 0 {
+		result.WriteString(fmt.Sprintf(" %d to rename.", counts[planRename]))
+	}
+	if failed > 0 {
+		result.WriteString(fmt.Sprintf(" %d failed to diff.", failed))
+	}
+	return result.String()
+}
+
+// planAction returns the plan action for the result, or false if it needs no action
+func (dr Result) planAction() (planAction, bool) {
+	switch dr.Type {
+	case Created:
+		return planCreate, true
+	case Deleted:
+		return planDestroy, true
+	case Changed:
+		// Kubernetes cannot rename a resource in place, so applying a rename replaces the original
+		if dr.RenamedFrom != nil {
+			return planRename, true
+		}
+		if len(dr.ImmutableChanges) > 0 {
+			return planReplace, true
+		}
+		return planUpdate, true
+	default:
+		return planAction{}, false
+	}
+}
+
+// writePlanDiffBody writes the hunks of a diff indented below the plan action, dropping the resource and file headers
+func writePlanDiffBody(result *strings.Builder, diffText string) {
+	for _, line := range strings.Split(strings.TrimRight(diffText, "\n"), "\n") {
+		if line == "" || strings.HasPrefix(line, "=====") || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+			continue
+		}
+		result.WriteString("      " + line + "\n")
+	}
+}
@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResults_StringPlan(t *testing.T) {
+	results := Results{
+		{Kind: "ConfigMap", Namespace: "default", Name: "app"}: {
+			Type: Changed,
+			Diff: "===== /ConfigMap default/app ======\n--- app-live.yaml\n+++ app.yaml\n@@ -1 +1 @@\n-  key: v1\n+  key: v2\n",
+		},
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}: {
+			Type:             Changed,
+			Diff:             "===== apps/Deployment default/web ======\n-      app: web\n+      app: frontend\n",
+			ImmutableChanges: []string{"spec.selector"},
+		},
+		{Kind: "Secret", Namespace: "default", Name: "old"}:         {Type: Deleted, Diff: "===== /Secret default/old ======\n-data\n"},
+		{Kind: "Service", Namespace: "default", Name: "web"}:        {Type: Created, Diff: "===== /Service default/web ======\n+spec\n"},
+		{Kind: "ServiceAccount", Namespace: "default", Name: "web"}: {Type: Unchanged},
+	}
+
+	t.Run("with diff", func(t *testing.T) {
+		plan := results.StringPlan()
+		assert.Contains(t, plan, "  ~ update /ConfigMap default/app\n      @@ -1 +1 @@\n      -  key: v1\n      +  key: v2\n")
+		assert.Contains(t, plan, "-/+ replace apps/Deployment default/web (forces replacement: spec.selector)\n")
+		assert.Contains(t, plan, "  - destroy /Secret default/old\n")
+		assert.Contains(t, plan, "  + create /Service default/web\n")
+		assert.NotContains(t, plan, "ServiceAccount")
+		assert.NotContains(t, plan, "app-live.yaml")
+		assert.Contains(t, plan, "Plan: 1 to create, 1 to update, 1 to replace, 1 to destroy.")
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		plan := results.StringPlanSummary()
+		assert.Contains(t, plan, "  ~ update /ConfigMap default/app\n\n")
+		assert.NotContains(t, plan, "key: v1")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		unchanged := Results{{Kind: "ConfigMap", Namespace: "default", Name: "app"}: {Type: Unchanged}}
+		assert.Equal(t, "No changes. Manifests are up-to-date.", unchanged.StringPlan())
+	})
+}
+
+func TestResults_StringPlanRenames(t *testing.T) {
+	results := Results{
+		{Kind: "ConfigMap", Namespace: "default", Name: "app-v2"}: {
+			Type:        Changed,
+			Diff:        "===== /ConfigMap default/app-v2 ======\n-  key: v1\n+  key: v2\n",
+			RenamedFrom: &ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app-v1"},
+		},
+	}
+
+	plan := results.StringPlan()
+	assert.Contains(t, plan, "-/+ rename /ConfigMap default/app-v2 (from ConfigMap/default/app-v1)\n")
+	assert.NotContains(t, plan, "~ update /ConfigMap")
+	assert.Contains(t, plan, "Plan: 0 to create, 0 to update, 0 to replace, 0 to destroy. 1 to rename.")
+}
+
+func TestResults_StringPlanErrors(t *testing.T) {
+	results := Results{
+		{Kind: "ConfigMap", Namespace: "default", Name: "app"}:    {Type: Created, Diff: "+data\n"},
+		{Kind: "Secret", Namespace: "default", Name: "malformed"}: {Type: Error, Err: errors.New("secret validation failed")},
+	}
+
+	plan := results.StringPlan()
+	assert.Contains(t, plan, "  ! error /Secret default/malformed: secret validation failed\n")
+	assert.Contains(t, plan, "Plan: 1 to create, 0 to update, 0 to replace, 0 to destroy. 1 failed to diff.")
+	assert.Contains(t, results.StringReleaseNotes(), "## Errors\n- Secret/default/malformed: secret validation failed")
+}
@@ -0,0 +1,64 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the option profiles returned by Profile
+const (
+	// ProfileStrict compares every resource exactly as written: resources annotated with the ignore annotation
+	// are not skipped and nothing is normalized
+	ProfileStrict = "strict"
+	// ProfileGitOps ignores the differences GitOps controllers and clusters introduce without a manifest change:
+	// known server-assigned fields, status, SealedSecret ciphertexts and line endings
+	ProfileGitOps = "gitops"
+	// ProfileAudit reports every resource, including those that fail to diff and those annotated with the ignore
+	// annotation, with secret masks that don't reveal values shared between resources
+	ProfileAudit = "audit"
+)
+
+// profiles maps profile names to the changes they make to the default options
+var profiles = map[string]func(*Options){
+	ProfileStrict: func(o *Options) {
+		o.filterOption().DisableIgnoreAnnotation = true
+	},
+	ProfileGitOps: func(o *Options) {
+		o.WithKindNormalizers(DefaultKindNormalizers()).
+			WithSummarizeStatus(true).
+			WithSummarizeSealedSecrets(true).
+			WithIgnoreEOL(true)
+	},
+	ProfileAudit: func(o *Options) {
+		o.filterOption().DisableIgnoreAnnotation = true
+		o.WithContinueOnError(true).
+			WithMaskScope(MaskScopeResource)
+	},
+}
+
+// Profiles returns the names of the option profiles, sorted
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the default options with the defaults of the named profile applied, so that common use cases
+// get useful output without assembling many options. The empty name returns the default options.
+// Options can be changed further with the With methods.
+func Profile(name string) (*Options, error) {
+	opts := DefaultOptions()
+	if name == "" {
+		return opts, nil
+	}
+	apply, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (%s)", name, strings.Join(Profiles(), "|"))
+	}
+	apply(opts)
+	return opts, nil
+}
@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	t.Run("no profile", func(t *testing.T) {
+		opts, err := Profile("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultOptions(), opts)
+	})
+
+	t.Run("gitops", func(t *testing.T) {
+		opts, err := Profile(ProfileGitOps)
+		require.NoError(t, err)
+		assert.NotNil(t, opts.KindNormalizers)
+		assert.True(t, opts.SummarizeStatus)
+		assert.True(t, opts.SummarizeSealedSecrets)
+		assert.True(t, opts.IgnoreEOL)
+		assert.False(t, opts.FilterOption.DisableIgnoreAnnotation)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		opts, err := Profile(ProfileStrict)
+		require.NoError(t, err)
+		assert.True(t, opts.FilterOption.DisableIgnoreAnnotation)
+		assert.Nil(t, opts.KindNormalizers)
+		assert.False(t, opts.ContinueOnError)
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		opts, err := Profile(ProfileAudit)
+		require.NoError(t, err)
+		assert.True(t, opts.FilterOption.DisableIgnoreAnnotation)
+		assert.True(t, opts.ContinueOnError)
+		assert.Equal(t, MaskScopeResource, opts.MaskScope)
+	})
+
+	t.Run("profiles are independent", func(t *testing.T) {
+		first, err := Profile(ProfileStrict)
+		require.NoError(t, err)
+		first.FilterOption.DisableIgnoreAnnotation = false
+		second, err := Profile(ProfileStrict)
+		require.NoError(t, err)
+		assert.True(t, second.FilterOption.DisableIgnoreAnnotation)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := Profile("lenient")
+		assert.EqualError(t, err, `unknown profile "lenient" (audit|gitops|strict)`)
+	})
+}
+
+func TestYamlString_GitOpsProfile(t *testing.T) {
+	base := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: default\ndata:\n  script: \"echo\\r\\n\"\n"
+	head := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: default\ndata:\n  script: \"echo\\n\"\n"
+
+	opts, err := Profile(ProfileGitOps)
+	require.NoError(t, err)
+	results, err := YamlString(base, head, opts)
+	require.NoError(t, err)
+	assert.False(t, results.HasChanges())
+}
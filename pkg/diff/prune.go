@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// shellSafe matches arguments that can be written to a shell script without quoting
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9._/:=-]+$`)
+
+// PruneScript returns a shell script of kubectl delete commands for the Deleted resources.
+// Namespaced resources are deleted before cluster-scoped ones so that Namespaces and CRDs go last.
+func (dr Results) PruneScript() string {
+	var namespaced, clusterScoped []ResourceKey
+	for key, result := range dr {
+		if result.Type != Deleted {
+			continue
+		}
+		if dr.isClusterScoped(key) {
+			clusterScoped = append(clusterScoped, key)
+		} else {
+			namespaced = append(namespaced, key)
+		}
+	}
+	sortResourceKeys(namespaced)
+	sortResourceKeys(clusterScoped)
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString("# Deletes resources that exist in base but not in head\n")
+	script.WriteString("set -e\n")
+	if len(namespaced)+len(clusterScoped) == 0 {
+		script.WriteString("# No resources to delete\n")
+		return script.String()
+	}
+	for _, key := range append(namespaced, clusterScoped...) {
+		script.WriteString(pruneCommand(key))
+		script.WriteString("\n")
+	}
+	return script.String()
+}
+
+// isTracked reports whether a label or annotation of obj matches any key of selector and one of its values, the
+// tracking labels and annotations of a GitOps application. Nothing is tracked by an empty selector.
+func isTracked(obj *unstructured.Unstructured, selector map[string][]string) bool {
+	if obj == nil {
+		return false
+	}
+	for key, values := range selector {
+		for _, value := range values {
+			entry := map[string]string{key: value}
+			if filter.MatchesSelector(entry, obj.GetLabels()) || filter.MatchesSelector(entry, obj.GetAnnotations()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterWouldPrune returns a new Results containing only deleted resources a GitOps controller would prune,
+// see Options.PruneTracking
+func (dr Results) FilterWouldPrune() Results {
+	return dr.Apply(func(_ ResourceKey, diffResult Result) bool {
+		return diffResult.WouldPrune
+	})
+}
+
+// FilterNotPruned returns a new Results excluding deleted resources a GitOps controller would prune
+func (dr Results) FilterNotPruned() Results {
+	return dr.Apply(func(_ ResourceKey, diffResult Result) bool {
+		return !diffResult.WouldPrune
+	})
+}
+
+// pruneCommand returns the kubectl delete command for a resource
+func pruneCommand(key ResourceKey) string {
+	resource := strings.ToLower(key.Kind)
+	if key.Group != "" {
+		resource += "." + key.Group
+	}
+	args := []string{"kubectl", "delete", shellQuote(resource), shellQuote(key.Name)}
+	if key.Namespace != "" {
+		args = append(args, "--namespace", shellQuote(key.Namespace))
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for a POSIX shell if needed
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", `'\''`))
+}
@@ -0,0 +1,135 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResults_PruneScript(t *testing.T) {
+	t.Run("deleted resources", func(t *testing.T) {
+		results := Results{
+			{Kind: "Namespace", Name: "team-a"}:                                     {Type: Deleted},
+			{Group: "apps", Kind: "Deployment", Namespace: "team-a", Name: "web"}:   {Type: Deleted},
+			{Kind: "ConfigMap", Namespace: "team-a", Name: "web-config"}:            {Type: Deleted},
+			{Group: "apps", Kind: "Deployment", Namespace: "team-a", Name: "api"}:   {Type: Changed},
+			{Group: "apps", Kind: "Deployment", Namespace: "team-a", Name: "batch"}: {Type: Created},
+		}
+
+		expected := "#!/bin/sh\n" +
+			"# Deletes resources that exist in base but not in head\n" +
+			"set -e\n" +
+			"kubectl delete configmap web-config --namespace team-a\n" +
+			"kubectl delete deployment.apps web --namespace team-a\n" +
+			"kubectl delete namespace team-a\n"
+		assert.Equal(t, expected, results.PruneScript())
+	})
+
+	t.Run("no deleted resources", func(t *testing.T) {
+		results := Results{{Kind: "ConfigMap", Namespace: "default", Name: "app"}: {Type: Changed}}
+		assert.Contains(t, results.PruneScript(), "# No resources to delete\n")
+	})
+
+	t.Run("unsafe names are quoted", func(t *testing.T) {
+		results := Results{{Kind: "ConfigMap", Namespace: "default", Name: "it's $HOME"}: {Type: Deleted}}
+		assert.Contains(t, results.PruneScript(), `kubectl delete configmap 'it'\''s $HOME' --namespace default`)
+	})
+}
+
+func TestYamlString_PruneTracking(t *testing.T) {
+	baseYaml := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: labeled
+  namespace: default
+  labels:
+    app.kubernetes.io/instance: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: annotated
+  namespace: default
+  annotations:
+    argocd.argoproj.io/tracking-id: "web:/ConfigMap:default/annotated"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: untracked
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: default
+  labels:
+    app.kubernetes.io/instance: web
+data:
+  key: value
+`
+	headYaml := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: default
+  labels:
+    app.kubernetes.io/instance: web
+data:
+  key: changed
+`
+	labeled := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "labeled"}
+	annotated := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "annotated"}
+	untracked := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "untracked"}
+	kept := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "kept"}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		results, err := YamlString(baseYaml, headYaml, NewOptions())
+		require.NoError(t, err)
+		assert.Empty(t, results.FilterWouldPrune())
+	})
+
+	tests := []struct {
+		name string
+		opts *Options
+	}{
+		{name: "diff", opts: NewOptions()},
+		{name: "fail-fast", opts: NewOptions().WithFailFast(true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts.WithPruneTracking(map[string][]string{
+				"app.kubernetes.io/instance":     {"api", "web"},
+				"argocd.argoproj.io/tracking-id": {"web:*"},
+			})
+			results, err := YamlString(baseYaml, headYaml, opts)
+			require.NoError(t, err)
+			if tt.opts.FailFast {
+				// Comparison stops at the first deleted resource, "annotated"
+				assert.True(t, results[annotated].WouldPrune)
+				return
+			}
+			assert.ElementsMatch(t, []ResourceKey{annotated, labeled}, results.FilterWouldPrune().GetResourceKeys())
+			assert.False(t, results[untracked].WouldPrune, "untracked resources are not pruned")
+			assert.False(t, results[kept].WouldPrune, "changed resources are not pruned")
+			assert.Equal(t, Deleted, results[labeled].Type)
+		})
+	}
+}
+
+func TestResults_WouldPruneSummary(t *testing.T) {
+	results := Results{
+		{Kind: "ConfigMap", Namespace: "default", Name: "tracked"}:   {Type: Deleted, WouldPrune: true},
+		{Kind: "ConfigMap", Namespace: "default", Name: "untracked"}: {Type: Deleted},
+	}
+
+	summary := results.StringSummary()
+	assert.Contains(t, summary, "Delete (1):\n  ConfigMap/default/untracked\n")
+	assert.Contains(t, summary, "Prune (1):\n  ConfigMap/default/tracked")
+
+	markdown := results.StringSummaryMarkdown()
+	assert.Contains(t, markdown, "Resources That Would Be Pruned")
+	assert.Contains(t, results.StringPlanSummary(), "(would be pruned)")
+}
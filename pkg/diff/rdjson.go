@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// rdjsonCodes are the codes of Reviewdog diagnostics by change type, matching the SARIF rules
+var rdjsonCodes = map[ChangeType]string{
+	Changed: "resource-changed",
+	Created: "resource-created",
+}
+
+type rdjsonResult struct {
+	Source      rdjsonSource       `json:"source"`
+	Diagnostics []rdjsonDiagnostic `json:"diagnostics"`
+}
+
+type rdjsonSource struct {
+	Name string `json:"name"`
+}
+
+type rdjsonDiagnostic struct {
+	Message  string         `json:"message"`
+	Location rdjsonLocation `json:"location"`
+	Severity string         `json:"severity"`
+	Source   rdjsonSource   `json:"source"`
+	Code     rdjsonCode     `json:"code"`
+}
+
+type rdjsonLocation struct {
+	Path  string      `json:"path"`
+	Range rdjsonRange `json:"range"`
+}
+
+type rdjsonRange struct {
+	Start rdjsonPosition `json:"start"`
+	End   rdjsonPosition `json:"end"`
+}
+
+type rdjsonPosition struct {
+	Line int `json:"line"`
+}
+
+type rdjsonCode struct {
+	Value string `json:"value"`
+}
+
+// RDJSON returns the results in the Reviewdog Diagnostic Format (rdjson), with a diagnostic per annotated
+// changed field at its lines in the head manifest files, e.g. for inline pull request review comments posted
+// by reviewdog -f=rdjson. Like SARIF, results without Annotations are omitted, and the severity of a
+// diagnostic follows the Severity of its resource: high is ERROR, medium WARNING, and otherwise INFO.
+func (dr Results) RDJSON() ([]byte, error) {
+	source := rdjsonSource{Name: annotationToolName}
+	out := rdjsonResult{Source: source, Diagnostics: []rdjsonDiagnostic{}}
+	for _, field := range dr.annotatedFields() {
+		out.Diagnostics = append(out.Diagnostics, rdjsonDiagnostic{
+			Message: field.message(),
+			Location: rdjsonLocation{
+				Path: filepath.ToSlash(field.annotation.File),
+				Range: rdjsonRange{
+					Start: rdjsonPosition{Line: field.annotation.StartLine},
+					End:   rdjsonPosition{Line: field.annotation.EndLine},
+				},
+			},
+			Severity: rdjsonSeverity(field.result.Severity),
+			Source:   source,
+			Code:     rdjsonCode{Value: rdjsonCodes[field.result.Type]},
+		})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rdjson: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// rdjsonSeverity returns the Reviewdog severity of diagnostics of a severity
+func rdjsonSeverity(severity Severity) string {
+	switch severity {
+	case SeverityHigh:
+		return "ERROR"
+	case SeverityMedium:
+		return "WARNING"
+	default:
+		return "INFO"
+	}
+}
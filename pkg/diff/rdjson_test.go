@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+)
+
+func TestResults_RDJSON(t *testing.T) {
+	results := Results{
+		{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "web"}: {
+			Type:     Changed,
+			Severity: SeverityMedium,
+			Annotations: []FieldAnnotation{
+				{Path: "spec.template.spec.containers[0].image", Location: parser.Location{File: "manifests/web.yaml", StartLine: 12, EndLine: 12}},
+			},
+		},
+		{Kind: "ConfigMap", Namespace: "prod", Name: "config"}: {
+			Type:        Created,
+			Annotations: []FieldAnnotation{{Location: parser.Location{File: "manifests/config.yaml", StartLine: 1, EndLine: 6}}},
+		},
+		{Kind: "Secret", Namespace: "prod", Name: "unlocated"}: {Type: Changed},
+	}
+
+	data, err := results.RDJSON()
+	require.NoError(t, err)
+
+	var out rdjsonResult
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "k8s-manifest-diff", out.Source.Name)
+	assert.Equal(t, []rdjsonDiagnostic{
+		{
+			Message: "ConfigMap/prod/config: created",
+			Location: rdjsonLocation{Path: "manifests/config.yaml", Range: rdjsonRange{
+				Start: rdjsonPosition{Line: 1},
+				End:   rdjsonPosition{Line: 6},
+			}},
+			Severity: "INFO",
+			Source:   rdjsonSource{Name: "k8s-manifest-diff"},
+			Code:     rdjsonCode{Value: "resource-created"},
+		},
+		{
+			Message: "Deployment/prod/web: spec.template.spec.containers[0].image changed",
+			Location: rdjsonLocation{Path: "manifests/web.yaml", Range: rdjsonRange{
+				Start: rdjsonPosition{Line: 12},
+				End:   rdjsonPosition{Line: 12},
+			}},
+			Severity: "WARNING",
+			Source:   rdjsonSource{Name: "k8s-manifest-diff"},
+			Code:     rdjsonCode{Value: "resource-changed"},
+		},
+	}, out.Diagnostics)
+}
+
+func TestResults_RDJSON_Empty(t *testing.T) {
+	data, err := Results{}.RDJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"diagnostics": []`)
+}
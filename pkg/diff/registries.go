@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// dockerConfigKeys maps Docker config Secret types to the key holding the config
+var dockerConfigKeys = map[string]string{
+	"kubernetes.io/dockerconfigjson": ".dockerconfigjson",
+	"kubernetes.io/dockercfg":        ".dockercfg",
+}
+
+// registryChangesHeading introduces the registry changes appended to the diff of a Docker config Secret
+const registryChangesHeading = "# Registry changes:\n"
+
+// RegistryChanges describes registry hosts added to, removed from or with changed credentials in
+// a kubernetes.io/dockerconfigjson or kubernetes.io/dockercfg Secret, e.g. "registry ghcr.io added".
+// Credentials are compared but never included.
+func RegistryChanges(base, head *unstructured.Unstructured) []string {
+	baseAuths, baseOK := dockerConfigAuths(base)
+	headAuths, headOK := dockerConfigAuths(head)
+	if !baseOK || !headOK {
+		return nil
+	}
+
+	hosts := make([]string, 0, len(baseAuths)+len(headAuths))
+	for host := range baseAuths {
+		hosts = append(hosts, host)
+	}
+	for host := range headAuths {
+		if _, ok := baseAuths[host]; !ok {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	var changes []string
+	for _, host := range hosts {
+		baseEntry, inBase := baseAuths[host]
+		headEntry, inHead := headAuths[host]
+		switch {
+		case !inBase:
+			changes = append(changes, fmt.Sprintf("registry %s added", host))
+		case !inHead:
+			changes = append(changes, fmt.Sprintf("registry %s removed", host))
+		case !reflect.DeepEqual(baseEntry, headEntry):
+			changes = append(changes, fmt.Sprintf("registry %s credentials changed", host))
+		}
+	}
+	return changes
+}
+
+// dockerConfigAuths returns the registry entries of a Docker config Secret by host.
+// It reports false when obj is not a Docker config Secret or the config cannot be parsed.
+func dockerConfigAuths(obj *unstructured.Unstructured) (map[string]any, bool) {
+	if !masking.IsSecret(obj) {
+		return nil, false
+	}
+	secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+	key, ok := dockerConfigKeys[secretType]
+	if !ok {
+		return nil, false
+	}
+
+	var config []byte
+	if value, found, _ := unstructured.NestedString(obj.Object, "stringData", key); found {
+		config = []byte(value)
+	} else if value, found, _ := unstructured.NestedString(obj.Object, "data", key); found {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, false
+		}
+		config = decoded
+	} else {
+		return map[string]any{}, true
+	}
+
+	var parsed map[string]any
+	if err := json.Unmarshal(config, &parsed); err != nil {
+		return nil, false
+	}
+	// The legacy .dockercfg format is the auths map itself
+	if secretType == "kubernetes.io/dockercfg" {
+		return parsed, true
+	}
+	auths, _ := parsed["auths"].(map[string]any)
+	if auths == nil {
+		auths = map[string]any{}
+	}
+	return auths, true
+}
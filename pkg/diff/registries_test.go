@@ -0,0 +1,89 @@
+package diff
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newDockerConfigSecret returns a Secret of the given type holding config under key in data
+func newDockerConfigSecret(secretType, key, config string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "pull-secret", "namespace": "default"},
+		"type":       secretType,
+		"data":       map[string]any{key: base64.StdEncoding.EncodeToString([]byte(config))},
+	}}
+}
+
+func TestRegistryChanges(t *testing.T) {
+	const dockerConfigJSON = "kubernetes.io/dockerconfigjson"
+	base := `{"auths":{"docker.io":{"auth":"dXNlcjpvbGQ="},"quay.io":{"auth":"dXNlcjpxdWF5"}}}`
+
+	tests := []struct {
+		name     string
+		base     *unstructured.Unstructured
+		head     *unstructured.Unstructured
+		expected []string
+	}{
+		{
+			name:     "registries added, removed and changed",
+			base:     newDockerConfigSecret(dockerConfigJSON, ".dockerconfigjson", base),
+			head:     newDockerConfigSecret(dockerConfigJSON, ".dockerconfigjson", `{"auths":{"docker.io":{"auth":"dXNlcjpuZXc="},"ghcr.io":{"auth":"dXNlcjpnaA=="}}}`),
+			expected: []string{"registry docker.io credentials changed", "registry ghcr.io added", "registry quay.io removed"},
+		},
+		{
+			name:     "unchanged registries",
+			base:     newDockerConfigSecret(dockerConfigJSON, ".dockerconfigjson", base),
+			head:     newDockerConfigSecret(dockerConfigJSON, ".dockerconfigjson", `{"auths": {"quay.io": {"auth": "dXNlcjpxdWF5"}, "docker.io": {"auth": "dXNlcjpvbGQ="}}}`),
+			expected: nil,
+		},
+		{
+			name:     "legacy dockercfg",
+			base:     newDockerConfigSecret("kubernetes.io/dockercfg", ".dockercfg", `{"docker.io":{"auth":"dXNlcjpvbGQ="}}`),
+			head:     newDockerConfigSecret("kubernetes.io/dockercfg", ".dockercfg", `{"docker.io":{"auth":"dXNlcjpvbGQ="},"ghcr.io":{"auth":"dXNlcjpnaA=="}}`),
+			expected: []string{"registry ghcr.io added"},
+		},
+		{
+			name:     "invalid json",
+			base:     newDockerConfigSecret(dockerConfigJSON, ".dockerconfigjson", base),
+			head:     newDockerConfigSecret(dockerConfigJSON, ".dockerconfigjson", "{"),
+			expected: nil,
+		},
+		{
+			name:     "opaque secrets are not inspected",
+			base:     newDockerConfigSecret("Opaque", ".dockerconfigjson", base),
+			head:     newDockerConfigSecret("Opaque", ".dockerconfigjson", `{"auths":{}}`),
+			expected: nil,
+		},
+		{
+			name:     "created secret",
+			base:     nil,
+			head:     newDockerConfigSecret(dockerConfigJSON, ".dockerconfigjson", base),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RegistryChanges(tt.base, tt.head))
+		})
+	}
+}
+
+func TestObjects_RegistryChanges(t *testing.T) {
+	base := newDockerConfigSecret("kubernetes.io/dockerconfigjson", ".dockerconfigjson", `{"auths":{"docker.io":{"auth":"dXNlcjpvbGQ="}}}`)
+	head := newDockerConfigSecret("kubernetes.io/dockerconfigjson", ".dockerconfigjson", `{"auths":{"docker.io":{"auth":"dXNlcjpvbGQ="},"ghcr.io":{"auth":"dXNlcjpnaA=="}}}`)
+
+	results, err := Objects([]*unstructured.Unstructured{base}, []*unstructured.Unstructured{head}, DefaultOptions())
+	require.NoError(t, err)
+
+	result := results[ResourceKey{Kind: "Secret", Namespace: "default", Name: "pull-secret"}]
+	assert.Equal(t, []string{"registry ghcr.io added"}, result.RegistryChanges)
+	assert.Contains(t, result.Diff, "# Registry changes:\n#   registry ghcr.io added\n")
+	assert.NotContains(t, result.Diff, "dXNlcjpnaA==")
+}
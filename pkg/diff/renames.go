@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// renamedFromHeading introduces the original resource of a renamed resource in diff output
+const renamedFromHeading = "# Renamed from: "
+
+// validateRenameThreshold returns an error if the threshold is not a similarity ratio
+func validateRenameThreshold(threshold float64) error {
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("invalid rename threshold: %g (must be between 0 and 1)", threshold)
+	}
+	return nil
+}
+
+// renamedFromSuffix formats the original resource of a renamed resource with format, or returns "" if it was not renamed
+func renamedFromSuffix(from *ResourceKey, format string) string {
+	if from == nil {
+		return ""
+	}
+	return fmt.Sprintf(format, formatResourceKeyShort(*from))
+}
+
+// markdownRenamedFromSuffix formats the original resource of a renamed resource as a Markdown code span,
+// or returns "" if it was not renamed
+func markdownRenamedFromSuffix(from *ResourceKey) string {
+	if from == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (renamed from %s)", markdownCode(formatResourceKeyShort(*from)))
+}
+
+// pairRenames merges deleted and created resources of the same group and kind whose content, ignoring
+// name and namespace, is at least threshold similar into a single entry keyed by the created resource.
+// The merged entry records the key of the deleted resource in renamedFrom.
+func pairRenames(objMap map[ResourceKey]objBaseHead, threshold float64) {
+	type groupKind struct{ group, kind string }
+	deleted := make(map[groupKind][]ResourceKey)
+	created := make(map[groupKind][]ResourceKey)
+	for key, v := range objMap {
+		gk := groupKind{group: key.Group, kind: key.Kind}
+		switch determineChangeType(v.base, v.head) {
+		case Deleted:
+			deleted[gk] = append(deleted[gk], key)
+		case Created:
+			created[gk] = append(created[gk], key)
+		}
+	}
+
+	for gk, deletedKeys := range deleted {
+		createdKeys := created[gk]
+		if len(createdKeys) == 0 {
+			continue
+		}
+		// Sorted keys keep the greedy pairing stable across runs
+		sortResourceKeys(deletedKeys)
+		sortResourceKeys(createdKeys)
+
+		base := make([]*unstructured.Unstructured, len(deletedKeys))
+		baseKeys := make(map[*unstructured.Unstructured]ResourceKey, len(deletedKeys))
+		for i, key := range deletedKeys {
+			base[i] = objMap[key].base
+			baseKeys[base[i]] = key
+		}
+		head := make([]*unstructured.Unstructured, len(createdKeys))
+		headKeys := make(map[*unstructured.Unstructured]ResourceKey, len(createdKeys))
+		for i, key := range createdKeys {
+			head[i] = objMap[key].head
+			headKeys[head[i]] = key
+		}
+
+		for _, pair := range pairBySimilarity(base, head, threshold) {
+			if pair.base == nil || pair.head == nil {
+				continue
+			}
+			from := baseKeys[pair.base]
+			delete(objMap, from)
+			objMap[headKeys[pair.head]] = objBaseHead{base: pair.base, head: pair.head, renamedFrom: &from}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYamlString_RenameThreshold(t *testing.T) {
+	deployment := func(name, image string) string {
+		return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ` + name + `
+  namespace: default
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: ` + image + `
+        ports:
+        - containerPort: 8080
+`
+	}
+	configMap := func(name, data string) string {
+		return `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ` + name + `
+  namespace: default
+data:
+  ` + data + `
+`
+	}
+	baseYaml := deployment("old-release-web", "nginx:1.25") + "---\n" + configMap("old-release-config", "log-level: debug")
+	headYaml := deployment("new-release-web", "nginx:1.26") + "---\n" + deployment("new-release-worker", "busybox:1.36") + "---\n" + configMap("dashboards", "grafana.json: '{\"panels\": []}'")
+
+	oldWeb := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "old-release-web"}
+	newWeb := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "new-release-web"}
+	worker := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "new-release-worker"}
+
+	t.Run("disabled", func(t *testing.T) {
+		results, err := YamlString(baseYaml, headYaml, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Deleted, results[oldWeb].Type)
+		assert.Equal(t, Created, results[newWeb].Type)
+	})
+
+	t.Run("pairs similar resources of the same kind", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.RenameThreshold = 0.8
+		results, err := YamlString(baseYaml, headYaml, opts)
+		require.NoError(t, err)
+
+		assert.NotContains(t, results, oldWeb)
+		require.Contains(t, results, newWeb)
+		assert.Equal(t, Changed, results[newWeb].Type)
+		assert.Equal(t, &oldWeb, results[newWeb].RenamedFrom)
+		assert.Contains(t, results[newWeb].Diff, "# Renamed from: Deployment/default/old-release-web\n")
+		assert.Contains(t, results[newWeb].Diff, "image: nginx:1.26")
+
+		// Dissimilar resources and resources of other kinds stay created and deleted
+		assert.Equal(t, Created, results[worker].Type)
+		assert.Nil(t, results[worker].RenamedFrom)
+		assert.Equal(t, Deleted, results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "old-release-config"}].Type)
+
+		summary := results.StringSummary()
+		assert.Contains(t, summary, "Deployment/default/new-release-web (renamed from Deployment/default/old-release-web)")
+		assert.True(t, strings.Contains(results.StringPlan(), "-/+ rename apps/Deployment default/new-release-web (from Deployment/default/old-release-web)"), results.StringPlan())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.RenameThreshold = 80
+		_, err := YamlString(baseYaml, headYaml, opts)
+		assert.ErrorContains(t, err, "invalid rename threshold")
+	})
+}
@@ -0,0 +1,220 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Headings of entries in the structural report
+const (
+	reportValueChange = "  ± value change"
+	reportAdded       = "  + added"
+	reportRemoved     = "  - removed"
+)
+
+// validate returns an error if the diff style is unknown
+func (s DiffStyle) validate() error {
+	switch s {
+	case "", DiffStyleUnified, DiffStyleInline, DiffStyleReport:
+		return nil
+	default:
+		return fmt.Errorf("invalid diff style: %s (supported: %s, %s, %s)", s, DiffStyleUnified, DiffStyleInline, DiffStyleReport)
+	}
+}
+
+// reportEntry is a single changed path in the structural report
+type reportEntry struct {
+	path     string
+	heading  string
+	oldValue any
+	newValue any
+}
+
+// getReportStr returns a dyff-style structural report of the differences between base and head,
+// listing each changed path with its old ("-") and new ("+") values
+func getReportStr(base, head *unstructured.Unstructured, opts *Options, masker *masking.Masker) (string, error) {
+	preparedHead, preparedBase, err := prepareObjectsForDiff(head, base, opts, masker)
+	if err != nil {
+		return "", err
+	}
+
+	var entries []reportEntry
+	compareStructure("", preparedBase.Object, preparedHead.Object, &entries)
+
+	var result strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		result.WriteString(entry.path + "\n")
+		result.WriteString(entry.heading + "\n")
+		if entry.heading != reportAdded {
+			if err := writeReportValue(&result, "-", entry.oldValue); err != nil {
+				return "", err
+			}
+		}
+		if entry.heading != reportRemoved {
+			if err := writeReportValue(&result, "+", entry.newValue); err != nil {
+				return "", err
+			}
+		}
+	}
+	return result.String(), nil
+}
+
+// compareStructure appends an entry for every path whose value differs between oldValue and newValue.
+// Lists of maps with unique names are matched by name, other lists by index.
+func compareStructure(path string, oldValue, newValue any, entries *[]reportEntry) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+	if oldIsMap && newIsMap {
+		keys := make(map[string]bool)
+		for key := range oldMap {
+			keys[key] = true
+		}
+		for key := range newMap {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			oldChild, inOld := oldMap[key]
+			newChild, inNew := newMap[key]
+			childPath := joinReportPath(path, key)
+			switch {
+			case !inOld:
+				*entries = append(*entries, reportEntry{path: childPath, heading: reportAdded, newValue: newChild})
+			case !inNew:
+				*entries = append(*entries, reportEntry{path: childPath, heading: reportRemoved, oldValue: oldChild})
+			default:
+				compareStructure(childPath, oldChild, newChild, entries)
+			}
+		}
+		return
+	}
+
+	oldList, oldIsList := oldValue.([]any)
+	newList, newIsList := newValue.([]any)
+	if oldIsList && newIsList {
+		compareLists(path, oldList, newList, entries)
+		return
+	}
+
+	*entries = append(*entries, reportEntry{path: path, heading: reportValueChange, oldValue: oldValue, newValue: newValue})
+}
+
+// compareLists compares list elements by name when all elements are maps with unique names, otherwise by index
+func compareLists(path string, oldList, newList []any, entries *[]reportEntry) {
+	oldNames, oldNamed := namedElements(oldList)
+	newNames, newNamed := namedElements(newList)
+	if oldNamed && newNamed {
+		for _, name := range elementNames(oldList) {
+			childPath := fmt.Sprintf("%s[name=%s]", path, name)
+			if newElement, ok := newNames[name]; ok {
+				compareStructure(childPath, oldNames[name], newElement, entries)
+			} else {
+				*entries = append(*entries, reportEntry{path: childPath, heading: reportRemoved, oldValue: oldNames[name]})
+			}
+		}
+		for _, name := range elementNames(newList) {
+			if _, ok := oldNames[name]; !ok {
+				*entries = append(*entries, reportEntry{path: fmt.Sprintf("%s[name=%s]", path, name), heading: reportAdded, newValue: newNames[name]})
+			}
+		}
+		return
+	}
+
+	for i := 0; i < max(len(oldList), len(newList)); i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(oldList):
+			*entries = append(*entries, reportEntry{path: childPath, heading: reportAdded, newValue: newList[i]})
+		case i >= len(newList):
+			*entries = append(*entries, reportEntry{path: childPath, heading: reportRemoved, oldValue: oldList[i]})
+		default:
+			compareStructure(childPath, oldList[i], newList[i], entries)
+		}
+	}
+}
+
+// namedElements indexes list elements by their "name" field, returning false unless every element is a map with a unique name
+func namedElements(list []any) (map[string]any, bool) {
+	named := make(map[string]any, len(list))
+	for _, element := range list {
+		m, ok := element.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		if _, duplicate := named[name]; duplicate {
+			return nil, false
+		}
+		named[name] = element
+	}
+	return named, true
+}
+
+// elementNames returns the names of named list elements in order
+func elementNames(list []any) []string {
+	names := make([]string, 0, len(list))
+	for _, element := range list {
+		names = append(names, element.(map[string]any)["name"].(string))
+	}
+	return names
+}
+
+// joinReportPath appends a map key to a path, quoting keys that contain dots
+func joinReportPath(path, key string) string {
+	if strings.Contains(key, ".") {
+		key = fmt.Sprintf("[%q]", key)
+		return path + key
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// writeReportValue writes a value below a report entry, prefixing each line with the marker
+func writeReportValue(result *strings.Builder, marker string, value any) error {
+	var text string
+	switch v := value.(type) {
+	case map[string]any, []any:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		text = strings.TrimRight(parser.UnescapeUnicode(string(data)), "\n")
+	case nil:
+		text = "null"
+	default:
+		text = fmt.Sprint(v)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		result.WriteString(fmt.Sprintf("    %s %s\n", marker, line))
+	}
+	return nil
+}
+
+// isReportMarkerLine reports whether a line of the structural report is the heading of a changed path
+func isReportMarkerLine(line string) bool {
+	return line == reportValueChange || line == reportAdded || line == reportRemoved
+}
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYamlString_DiffStyleReport(t *testing.T) {
+	base := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app.kubernetes.io/name: web
+    tier: frontend
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`
+	head := strings.NewReplacer(
+		"replicas: 2", "replicas: 3",
+		"nginx:1.25", "nginx:1.27",
+		"    tier: frontend\n", "",
+		"app.kubernetes.io/name: web", "app.kubernetes.io/name: frontend",
+	).Replace(base) + "  strategy:\n    type: Recreate\n"
+	head = strings.Replace(head, "      - name: sidecar\n        image: envoy:1.30\n", "", 1)
+
+	opts := DefaultOptions()
+	opts.DiffStyle = DiffStyleReport
+	results, err := YamlString(base, head, opts)
+	require.NoError(t, err)
+
+	result := results[ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}]
+	expected := `===== apps/Deployment default/web ======
+metadata.labels["app.kubernetes.io/name"]
+  ± value change
+    - web
+    + frontend
+
+metadata.labels.tier
+  - removed
+    - frontend
+
+spec.replicas
+  ± value change
+    - 2
+    + 3
+
+spec.strategy
+  + added
+    + type: Recreate
+
+spec.template.spec.containers[name=web].image
+  ± value change
+    - nginx:1.25
+    + nginx:1.27
+
+spec.template.spec.containers[name=sidecar]
+  - removed
+    - image: envoy:1.30
+    - name: sidecar
+`
+	assert.Equal(t, expected, result.Diff)
+	assert.Equal(t, 6, countChangedLines(result.Diff))
+}
+
+func TestCompareLists(t *testing.T) {
+	var entries []reportEntry
+	compareStructure("args", []any{"a", "b"}, []any{"a", "c", "d"}, &entries)
+	assert.Equal(t, []reportEntry{
+		{path: "args[1]", heading: reportValueChange, oldValue: "b", newValue: "c"},
+		{path: "args[2]", heading: reportAdded, newValue: "d"},
+	}, entries)
+}
+
+func TestObjects_InvalidDiffStyle(t *testing.T) {
+	opts := DefaultOptions()
+	opts.DiffStyle = "side-by-side"
+	_, err := YamlString("", "", opts)
+	assert.ErrorContains(t, err, "invalid diff style: side-by-side")
+}
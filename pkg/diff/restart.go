@@ -0,0 +1,167 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// checksumAnnotationPrefix starts the pod template annotations Helm charts set to a hash of the configuration
+// a workload mounts, e.g. checksum/config, so that configuration changes restart its pods
+const checksumAnnotationPrefix = "checksum/"
+
+// restartOnlyNote describes workload changes marked RestartOnly in summaries and plans
+const restartOnlyNote = "restart-only (config checksum)"
+
+// restartOnlyWorkloads returns the keys of the workloads with linked ConfigMap or Secret changes, see
+// linkedConfigs, whose only change is a checksum/ pod template annotation, so that the workload change merely
+// restarts its pods to pick up the linked changes
+func restartOnlyWorkloads(objMap map[ResourceKey]objBaseHead, linked map[ResourceKey][]ResourceKey, workloads *workload.Policy) map[ResourceKey]bool {
+	restartOnly := make(map[ResourceKey]bool)
+	for key := range linked {
+		v := objMap[key]
+		if v.base == nil || v.head == nil {
+			continue
+		}
+		gvk := v.head.GroupVersionKind()
+		kind, ok := workloads.Lookup(gvk.Group, gvk.Kind)
+		if ok && kind.TemplateLabelsPath() != nil && onlyChecksumsChanged(v.base, v.head, kind) {
+			restartOnly[key] = true
+		}
+	}
+	return restartOnly
+}
+
+// onlyChecksumsChanged reports whether base and head differ, but only in checksum/ pod template annotations
+func onlyChecksumsChanged(base, head *unstructured.Unstructured, kind workload.Kind) bool {
+	if reflect.DeepEqual(base.Object, head.Object) {
+		return false
+	}
+	return reflect.DeepEqual(withoutChecksums(base, kind).Object, withoutChecksums(head, kind).Object)
+}
+
+// withoutChecksums returns a copy of a workload without its checksum/ pod template annotations
+func withoutChecksums(obj *unstructured.Unstructured, kind workload.Kind) *unstructured.Unstructured {
+	labelsPath := kind.TemplateLabelsPath()
+	annotationsPath := append(append([]string{}, labelsPath[:len(labelsPath)-1]...), "annotations")
+	stripped := obj.DeepCopy()
+	annotations, found, _ := unstructured.NestedStringMap(stripped.Object, annotationsPath...)
+	if !found {
+		return stripped
+	}
+	for name := range annotations {
+		if strings.HasPrefix(name, checksumAnnotationPrefix) {
+			delete(annotations, name)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(stripped.Object, annotationsPath...)
+	} else {
+		_ = unstructured.SetNestedStringMap(stripped.Object, annotations, annotationsPath...)
+	}
+	return stripped
+}
+
+// referencedConfigs returns the keys of the ConfigMaps and Secrets a workload mounts as volumes or reads into
+// the environment of its containers, in its namespace
+func referencedConfigs(obj *unstructured.Unstructured, kind workload.Kind) []ResourceKey {
+	podSpec, _, _ := unstructured.NestedMap(obj.Object, kind.PodSpecPath()...)
+	var keys []ResourceKey
+	visitConfigReferences(podSpec, func(configKind string, ref map[string]any, field string) {
+		if name, ok := ref[field].(string); ok && name != "" {
+			keys = append(keys, ResourceKey{Kind: configKind, Namespace: obj.GetNamespace(), Name: name})
+		}
+	})
+	return keys
+}
+
+// visitConfigReferences calls visit for each reference of a pod spec to a ConfigMap or Secret in a volume or the
+// environment of a container, with the referencing map and the field holding the name
+func visitConfigReferences(podSpec map[string]any, visit func(configKind string, ref map[string]any, field string)) {
+	add := func(configKind string, value any, field string) {
+		if ref, ok := value.(map[string]any); ok {
+			visit(configKind, ref, field)
+		}
+	}
+
+	// The maps are visited in place so that visit may rewrite references
+	volumes, _ := podSpec["volumes"].([]any)
+	for _, volume := range volumes {
+		volume, ok := volume.(map[string]any)
+		if !ok {
+			continue
+		}
+		add("ConfigMap", volume["configMap"], "name")
+		add("Secret", volume["secret"], "secretName")
+		projected, _ := volume["projected"].(map[string]any)
+		sources, _ := projected["sources"].([]any)
+		for _, source := range sources {
+			if source, ok := source.(map[string]any); ok {
+				add("ConfigMap", source["configMap"], "name")
+				add("Secret", source["secret"], "name")
+			}
+		}
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[field].([]any)
+		for _, container := range containers {
+			container, ok := container.(map[string]any)
+			if !ok {
+				continue
+			}
+			envFrom, _ := container["envFrom"].([]any)
+			for _, source := range envFrom {
+				if source, ok := source.(map[string]any); ok {
+					add("ConfigMap", source["configMapRef"], "name")
+					add("Secret", source["secretRef"], "name")
+				}
+			}
+			env, _ := container["env"].([]any)
+			for _, variable := range env {
+				if variable, ok := variable.(map[string]any); ok {
+					valueFrom, _ := variable["valueFrom"].(map[string]any)
+					add("ConfigMap", valueFrom["configMapKeyRef"], "name")
+					add("Secret", valueFrom["secretKeyRef"], "name")
+				}
+			}
+		}
+	}
+}
+
+// RestartOnlyWithConfigs returns a copy of the results in which workloads stay marked RestartOnly only if a changed
+// ConfigMap or Secret of their change set is among the results. Results narrowed down, e.g. to one kind, would
+// otherwise describe a workload change as a restart for configuration changes they do not show.
+func (dr Results) RestartOnlyWithConfigs() Results {
+	withConfigs := make(map[string]bool)
+	for key, diffResult := range dr {
+		if key.Group == "" && (key.Kind == "ConfigMap" || key.Kind == "Secret") && diffResult.Type != Unchanged {
+			withConfigs[diffResult.ChangeSet] = true
+		}
+	}
+	confirmed := make(Results, len(dr))
+	for key, diffResult := range dr {
+		if diffResult.RestartOnly && (diffResult.ChangeSet == "" || !withConfigs[diffResult.ChangeSet]) {
+			diffResult.RestartOnly = false
+		}
+		confirmed[key] = diffResult
+	}
+	return confirmed
+}
+
+// FilterRestartOnly returns a new Results containing only workloads whose change merely restarts their pods for
+// a reported ConfigMap or Secret change, see Result.RestartOnly
+func (dr Results) FilterRestartOnly() Results {
+	return dr.Apply(func(_ ResourceKey, diffResult Result) bool {
+		return diffResult.RestartOnly
+	})
+}
+
+// FilterNotRestartOnly returns a new Results excluding workloads whose change merely restarts their pods
+func (dr Results) FilterNotRestartOnly() Results {
+	return dr.Apply(func(_ ResourceKey, diffResult Result) bool {
+		return !diffResult.RestartOnly
+	})
+}
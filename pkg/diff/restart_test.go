@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+)
+
+const restartDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: %REPLICAS%
+  template:
+    metadata:
+      annotations:
+        checksum/config: %CHECKSUM%
+        prometheus.io/scrape: "true"
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25.3
+        envFrom:
+        - configMapRef:
+            name: web-config
+`
+
+const restartConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: default
+data:
+  LOG_LEVEL: %LEVEL%
+`
+
+func restartManifests(replicas, checksum, level string) string {
+	deployment := strings.NewReplacer("%REPLICAS%", replicas, "%CHECKSUM%", checksum).Replace(restartDeployment)
+	return deployment + "---\n" + strings.ReplaceAll(restartConfigMap, "%LEVEL%", level)
+}
+
+func TestYamlString_RestartOnly(t *testing.T) {
+	deployment := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}
+	configMap := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "web-config"}
+	base := restartManifests("2", "aaa111", "info")
+
+	t.Run("checksum bump for a changed config", func(t *testing.T) {
+		results, err := YamlString(base, restartManifests("2", "bbb222", "debug"), DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[deployment].Type)
+		assert.True(t, results[deployment].RestartOnly)
+		assert.False(t, results[configMap].RestartOnly)
+		assert.Equal(t, []ResourceKey{deployment}, results.FilterRestartOnly().GetResourceKeys())
+
+		summary := results.StringSummary()
+		assert.Contains(t, summary, "Changed (1):\n  ConfigMap/default/web-config\n")
+		assert.Contains(t, summary, "Restart-only (config checksum) (1):\n  Deployment/default/web")
+		assert.Contains(t, results.StringSummaryMarkdown(), "## Restart-Only Changes (Config Checksum) (1)\n- `Deployment/default/web`")
+		assert.Contains(t, results.StringPlanSummary(), "~ update apps/Deployment default/web (restart-only (config checksum))")
+	})
+
+	t.Run("checksum bump without a config change", func(t *testing.T) {
+		results, err := YamlString(base, restartManifests("2", "bbb222", "info"), DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[deployment].Type)
+		assert.False(t, results[deployment].RestartOnly)
+	})
+
+	t.Run("other workload changes", func(t *testing.T) {
+		results, err := YamlString(base, restartManifests("3", "bbb222", "debug"), DefaultOptions())
+		require.NoError(t, err)
+		assert.False(t, results[deployment].RestartOnly)
+	})
+
+	t.Run("config change not among the results", func(t *testing.T) {
+		results, err := YamlString(base, restartManifests("2", "bbb222", "debug"), DefaultOptions())
+		require.NoError(t, err)
+		assert.True(t, results.RestartOnlyWithConfigs()[deployment].RestartOnly)
+		workloads := results.Apply(func(key ResourceKey, _ Result) bool { return key.Kind == "Deployment" })
+		assert.False(t, workloads.RestartOnlyWithConfigs()[deployment].RestartOnly)
+		assert.True(t, workloads[deployment].RestartOnly, "the results are not modified")
+	})
+
+	t.Run("names mapped before pairing", func(t *testing.T) {
+		// References use the names in each input, which are mapped like the names of the resources
+		prefixed := func(manifests, prefix string) string {
+			return strings.NewReplacer("\n  name: web\n", "\n  name: "+prefix+"web\n", "name: web-config", "name: "+prefix+"web-config").Replace(manifests)
+		}
+		staging := prefixed(restartManifests("2", "aaa111", "info"), "staging-")
+		production := prefixed(restartManifests("2", "bbb222", "debug"), "prod-")
+		opts := DefaultOptions().WithNameMappings(StripNamePrefix("staging-"), StripNamePrefix("prod-"))
+		results, err := YamlString(staging, production, opts)
+		require.NoError(t, err)
+		restarted := results.FilterRestartOnly().GetResourceKeys()
+		require.Len(t, restarted, 1)
+		assert.Equal(t, "Deployment", restarted[0].Kind)
+		configs := results.Apply(func(key ResourceKey, _ Result) bool { return key.Kind == "ConfigMap" })
+		require.Len(t, configs, 1)
+		for _, config := range configs {
+			assert.Equal(t, Changed, config.Type)
+			assert.Equal(t, results[restarted[0]].ChangeSet, config.ChangeSet)
+		}
+	})
+
+	t.Run("config change not reported", func(t *testing.T) {
+		results, err := YamlString(base, restartManifests("2", "bbb222", "debug"), DefaultOptions().WithExcludeKinds("ConfigMap"))
+		require.NoError(t, err)
+		assert.False(t, results[deployment].RestartOnly)
+	})
+}
+
+func TestReferencedConfigs(t *testing.T) {
+	objs, err := parser.ParseYAML(strings.NewReader(`apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+  namespace: jobs
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          volumes:
+          - name: config
+            configMap:
+              name: report-config
+          - name: tls
+            secret:
+              secretName: report-tls
+          - name: bundle
+            projected:
+              sources:
+              - secret:
+                  name: report-token
+          containers:
+          - name: report
+            env:
+            - name: PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: report-db
+                  key: password
+`))
+	require.NoError(t, err)
+	kind, ok := workload.Builtin().Lookup("batch", "CronJob")
+	require.True(t, ok)
+	assert.Equal(t, []ResourceKey{
+		{Kind: "ConfigMap", Namespace: "jobs", Name: "report-config"},
+		{Kind: "Secret", Namespace: "jobs", Name: "report-tls"},
+		{Kind: "Secret", Namespace: "jobs", Name: "report-token"},
+		{Kind: "Secret", Namespace: "jobs", Name: "report-db"},
+	}, referencedConfigs(objs[0], kind))
+}
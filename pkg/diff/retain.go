@@ -0,0 +1,24 @@
+package diff
+
+import (
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// retainObjects returns copies of the compared base and head objects of a resource pair for Result.Base and
+// Result.Head, masked like its diff. The objects are retained as compared, i.e. after transforms, conversions,
+// normalization and the removal of summarized fields such as status.
+func retainObjects(k ResourceKey, v objBaseHead, opts *Options, masker *masking.Masker) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	if opts.MaskScope == MaskScopeResource {
+		var err error
+		if masker, err = newResourceMasker(k, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	base, head, err := prepareObjectsForDiff(v.base, v.head, resourceMaskingOptions(k, opts), masker)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Unmasked objects are the compared objects themselves, which must not be shared with the caller
+	return base.DeepCopy(), head.DeepCopy(), nil
+}
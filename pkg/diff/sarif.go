@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// annotationToolName names the tool producing SARIF logs and Reviewdog diagnostics
+	annotationToolName = "k8s-manifest-diff"
+)
+
+// sarifRules describes the rules of SARIF results by change type
+var sarifRules = map[ChangeType]sarifRule{
+	Changed: {ID: "resource-changed", ShortDescription: sarifMessage{Text: "A field of a Kubernetes resource changed"}},
+	Created: {ID: "resource-created", ShortDescription: sarifMessage{Text: "A Kubernetes resource was created"}},
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+// SARIF returns the results as a SARIF 2.1.0 log with a result per annotated changed field, located at its
+// lines in the head manifest files, e.g. for inline pull request annotations by code scanning. Results
+// without Annotations, e.g. computed without Options.HeadSources, are omitted. The level of a result follows
+// the Severity of its resource: high is "error", medium "warning", and otherwise "note".
+func (dr Results) SARIF() ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:  annotationToolName,
+			Rules: []sarifRule{sarifRules[Changed], sarifRules[Created]},
+		}},
+		Results: []sarifResult{},
+	}
+	for _, field := range dr.annotatedFields() {
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  sarifRules[field.result.Type].ID,
+			Level:   sarifLevel(field.result.Severity),
+			Message: sarifMessage{Text: field.message()},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(field.annotation.File)},
+				Region:           sarifRegion{StartLine: field.annotation.StartLine, EndLine: field.annotation.EndLine},
+			}}},
+		})
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// sarifLevel returns the SARIF level of results of a severity
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
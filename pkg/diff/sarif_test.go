@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+)
+
+func TestResults_SARIF(t *testing.T) {
+	results := Results{
+		{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "web"}: {
+			Type:     Changed,
+			Severity: SeverityHigh,
+			Annotations: []FieldAnnotation{
+				{Path: "spec.replicas", Location: parser.Location{File: "manifests/web.yaml", StartLine: 7, EndLine: 7}},
+			},
+		},
+		{Kind: "ConfigMap", Namespace: "prod", Name: "config"}: {
+			Type:        Created,
+			Annotations: []FieldAnnotation{{Location: parser.Location{File: "manifests/config.yaml", StartLine: 1, EndLine: 6}}},
+		},
+		{Kind: "Secret", Namespace: "prod", Name: "unlocated"}: {Type: Changed},
+		{Kind: "Service", Namespace: "prod", Name: "old"}:      {Type: Deleted},
+	}
+
+	data, err := results.SARIF()
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(data, &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	assert.Equal(t, "k8s-manifest-diff", log.Runs[0].Tool.Driver.Name)
+	assert.Equal(t, []sarifResult{
+		{
+			RuleID:  "resource-created",
+			Level:   "note",
+			Message: sarifMessage{Text: "ConfigMap/prod/config: created"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "manifests/config.yaml"},
+				Region:           sarifRegion{StartLine: 1, EndLine: 6},
+			}}},
+		},
+		{
+			RuleID:  "resource-changed",
+			Level:   "error",
+			Message: sarifMessage{Text: "Deployment/prod/web: spec.replicas changed"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "manifests/web.yaml"},
+				Region:           sarifRegion{StartLine: 7, EndLine: 7},
+			}}},
+		},
+	}, log.Runs[0].Results)
+}
+
+func TestResults_SARIF_Empty(t *testing.T) {
+	data, err := Results{}.SARIF()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"results": []`)
+}
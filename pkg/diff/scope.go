@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// clusterScopedKinds lists built-in kinds that are not namespaced
+var clusterScopedKinds = map[string]bool{
+	"APIService":                       true,
+	"CertificateSigningRequest":        true,
+	"ClusterRole":                      true,
+	"ClusterRoleBinding":               true,
+	"ClusterTrustBundle":               true,
+	"ComponentStatus":                  true,
+	"CSIDriver":                        true,
+	"CSINode":                          true,
+	"CustomResourceDefinition":         true,
+	"DeviceClass":                      true,
+	"FlowSchema":                       true,
+	"IngressClass":                     true,
+	"IPAddress":                        true,
+	"MutatingAdmissionPolicy":          true,
+	"MutatingAdmissionPolicyBinding":   true,
+	"MutatingWebhookConfiguration":     true,
+	"Namespace":                        true,
+	"Node":                             true,
+	"PersistentVolume":                 true,
+	"PodSecurityPolicy":                true,
+	"PriorityClass":                    true,
+	"PriorityLevelConfiguration":       true,
+	"ResourceSlice":                    true,
+	"RuntimeClass":                     true,
+	"SelfSubjectAccessReview":          true,
+	"SelfSubjectReview":                true,
+	"SelfSubjectRulesReview":           true,
+	"ServiceCIDR":                      true,
+	"StorageClass":                     true,
+	"StorageVersion":                   true,
+	"StorageVersionMigration":          true,
+	"SubjectAccessReview":              true,
+	"TokenReview":                      true,
+	"ValidatingAdmissionPolicy":        true,
+	"ValidatingAdmissionPolicyBinding": true,
+	"ValidatingWebhookConfiguration":   true,
+	"VolumeAttachment":                 true,
+	"VolumeAttributesClass":            true,
+	"VolumeSnapshotClass":              true,
+}
+
+// IsClusterScoped reports whether the resource is of a built-in cluster-scoped kind. Custom resources are
+// cluster-scoped by the scope of their CustomResourceDefinition, see Result.ClusterScoped.
+func IsClusterScoped(key ResourceKey) bool {
+	return clusterScopedKinds[key.Kind]
+}
+
+// isClusterScoped reports whether the resource of a result is cluster-scoped, by its kind or by the
+// CustomResourceDefinition it was compared with
+func (dr Results) isClusterScoped(key ResourceKey) bool {
+	return IsClusterScoped(key) || dr[key].ClusterScoped
+}
+
+// FilterClusterScoped returns a new Results containing only cluster-scoped resources
+func (dr Results) FilterClusterScoped() Results {
+	return dr.Apply(func(key ResourceKey, _ Result) bool {
+		return dr.isClusterScoped(key)
+	})
+}
+
+// FilterNamespaced returns a new Results containing only namespaced resources
+func (dr Results) FilterNamespaced() Results {
+	return dr.Apply(func(key ResourceKey, _ Result) bool {
+		return !dr.isClusterScoped(key)
+	})
+}
+
+// customClusterScopedKinds returns the keys, with only their group and kind set, of the custom resources defined
+// as cluster-scoped by the CustomResourceDefinitions in base or head
+func customClusterScopedKinds(objMap map[ResourceKey]objBaseHead) map[ResourceKey]bool {
+	kinds := make(map[ResourceKey]bool)
+	for _, v := range objMap {
+		for _, obj := range []*unstructured.Unstructured{v.base, v.head} {
+			if obj == nil || obj.GetKind() != "CustomResourceDefinition" {
+				continue
+			}
+			group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+			scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+			if kind != "" && scope == "Cluster" {
+				kinds[ResourceKey{Group: group, Kind: kind}] = true
+			}
+		}
+	}
+	return kinds
+}
+
+// StringSummarySplitScope returns the summary like StringSummary, with cluster-scoped
+// and namespaced resources listed under separate headings
+func (dr Results) StringSummarySplitScope() string {
+	var result strings.Builder
+	dr.writeSummaryHeader(&result)
+	for _, scope := range dr.listed().splitScope() {
+		result.WriteString("# " + scope.title + "\n")
+		result.WriteString("#\n")
+		scope.results.writeSummarySections(&result)
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// StringSummaryMarkdownSplitScope returns the summary like StringSummaryMarkdown, with cluster-scoped
+// and namespaced resources listed under separate headings
+func (dr Results) StringSummaryMarkdownSplitScope() string {
+	var result strings.Builder
+	dr.writeSummaryHeaderMarkdown(&result)
+	for _, scope := range dr.listed().splitScope() {
+		result.WriteString("## " + scope.title + "\n\n")
+		scope.results.writeSummarySectionsMarkdown(&result, "###")
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// StringDiffSplitScope returns the diff like StringDiff, with cluster-scoped resources
+// listed and shown before namespaced ones
+func (dr Results) StringDiffSplitScope() string {
+	var result strings.Builder
+
+	if dr.hasDiffContent() {
+		if summaryComments := asComments(dr.StringSummarySplitScope()); summaryComments != "" {
+			result.WriteString(summaryComments)
+			result.WriteString("#\n")
+		}
+	}
+
+	for _, scope := range dr.splitScope() {
+		scope.results.writeDiffBodies(&result)
+	}
+	dr.writeTrivialList(&result)
+	return result.String()
+}
+
+// StringDiffMarkdownSplitScope returns the diff like StringDiffMarkdown, with cluster-scoped
+// and namespaced resources under separate headings
+func (dr Results) StringDiffMarkdownSplitScope() string {
+	var result strings.Builder
+
+	if dr.hasDiffContent() {
+		if summaryMarkdown := dr.StringSummaryMarkdownSplitScope(); summaryMarkdown != "" {
+			result.WriteString(summaryMarkdown)
+			result.WriteString("\n\n---\n\n")
+			dr.writeContentsMarkdown(&result)
+		}
+	}
+
+	for _, scope := range dr.splitScope() {
+		if !scope.results.hasDiffContent() {
+			continue
+		}
+		result.WriteString("## " + scope.changesTitle + "\n\n")
+		scope.results.writeDiffBodiesMarkdown(&result)
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
+
+// scopeSection is a titled subset of results sharing the same scope
+type scopeSection struct {
+	title        string
+	changesTitle string
+	results      Results
+}
+
+// splitScope returns the non-empty cluster-scoped and namespaced subsets, cluster-scoped first
+func (dr Results) splitScope() []scopeSection {
+	var sections []scopeSection
+	if cluster := dr.FilterClusterScoped(); len(cluster) > 0 {
+		sections = append(sections, scopeSection{title: "Cluster-scoped Resources", changesTitle: "Cluster-scoped Resource Changes", results: cluster})
+	}
+	if namespaced := dr.FilterNamespaced(); len(namespaced) > 0 {
+		sections = append(sections, scopeSection{title: "Namespaced Resources", changesTitle: "Namespaced Resource Changes", results: namespaced})
+	}
+	return sections
+}
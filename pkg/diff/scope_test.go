@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsClusterScoped(t *testing.T) {
+	tests := []struct {
+		kind     string
+		expected bool
+	}{
+		{kind: "Namespace", expected: true},
+		{kind: "CustomResourceDefinition", expected: true},
+		{kind: "ClusterRole", expected: true},
+		{kind: "ClusterRoleBinding", expected: true},
+		{kind: "StorageClass", expected: true},
+		{kind: "ClusterIssuer", expected: false},
+		{kind: "ClusterPolicyReport", expected: false},
+		{kind: "Deployment", expected: false},
+		{kind: "Role", expected: false},
+		{kind: "ConfigMap", expected: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, IsClusterScoped(ResourceKey{Kind: tt.kind, Name: "test"}), "kind %s", tt.kind)
+	}
+}
+
+func TestYamlString_CustomResourceScope(t *testing.T) {
+	headYaml := `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusterissuers.cert-manager.io
+spec:
+  group: cert-manager.io
+  scope: Cluster
+  names:
+    kind: ClusterIssuer
+    plural: clusterissuers
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusterreports.example.com
+spec:
+  group: example.com
+  scope: Namespaced
+  names:
+    kind: ClusterReport
+    plural: clusterreports
+---
+apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: letsencrypt
+spec:
+  acme: {}
+---
+apiVersion: example.com/v1
+kind: ClusterReport
+metadata:
+  name: weekly
+  namespace: team-a
+`
+	results, err := YamlString("", headYaml, nil)
+	require.NoError(t, err)
+
+	clusterScoped := results.FilterClusterScoped()
+	assert.Contains(t, clusterScoped, ResourceKey{Group: "cert-manager.io", Kind: "ClusterIssuer", Name: "letsencrypt"})
+	assert.Contains(t, clusterScoped, ResourceKey{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition", Name: "clusterreports.example.com"})
+	assert.Contains(t, results.FilterNamespaced(), ResourceKey{Group: "example.com", Kind: "ClusterReport", Namespace: "team-a", Name: "weekly"})
+}
+
+func TestResults_SplitScope(t *testing.T) {
+	clusterRole := ResourceKey{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "admin"}
+	namespace := ResourceKey{Kind: "Namespace", Name: "team-a"}
+	deployment := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "team-a", Name: "web"}
+	results := Results{
+		clusterRole: {Type: Changed, Diff: "===== rbac.authorization.k8s.io/ClusterRole /admin ======\n-  verbs: get\n+  verbs: list\n"},
+		namespace:   {Type: Unchanged},
+		deployment:  {Type: Created, Diff: "===== apps/Deployment team-a/web ======\n+  replicas: 1\n"},
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		assert.Len(t, results.FilterClusterScoped(), 2)
+		assert.Contains(t, results.FilterNamespaced(), deployment)
+	})
+
+	t.Run("text summary", func(t *testing.T) {
+		summary := results.StringSummarySplitScope()
+		assert.True(t, strings.HasPrefix(summary, "# Summary: 3 total, 1 changed, 1 created, 0 deleted, 1 unchanged"))
+
+		clusterIndex := strings.Index(summary, "# Cluster-scoped Resources")
+		namespacedIndex := strings.Index(summary, "# Namespaced Resources")
+		assert.True(t, clusterIndex >= 0 && namespacedIndex > clusterIndex)
+		assert.True(t, strings.Index(summary, "ClusterRole/admin") < namespacedIndex)
+		assert.True(t, strings.Index(summary, "Deployment/team-a/web") > namespacedIndex)
+	})
+
+	t.Run("markdown summary", func(t *testing.T) {
+		summary := results.StringSummaryMarkdownSplitScope()
+		assert.Contains(t, summary, "## Cluster-scoped Resources\n\n### Changed Resources (1)\n- `ClusterRole/admin`")
+		assert.Contains(t, summary, "## Namespaced Resources\n\n### Created Resources (1)\n- `Deployment/team-a/web`")
+	})
+
+	t.Run("markdown diff", func(t *testing.T) {
+		output := results.StringDiffMarkdownSplitScope()
+		clusterIndex := strings.Index(output, "## Cluster-scoped Resource Changes")
+		namespacedIndex := strings.Index(output, "## Namespaced Resource Changes")
+		assert.True(t, clusterIndex >= 0 && namespacedIndex > clusterIndex)
+		assert.True(t, strings.Index(output, "### rbac.authorization.k8s.io/ClusterRole admin") > clusterIndex)
+		assert.True(t, strings.Index(output, "### apps/Deployment team-a/web") > namespacedIndex)
+	})
+
+	t.Run("text diff", func(t *testing.T) {
+		output := results.StringDiffSplitScope()
+		assert.Contains(t, output, "# # Cluster-scoped Resources")
+		assert.True(t, strings.Index(output, "===== rbac.authorization.k8s.io/ClusterRole") < strings.Index(output, "===== apps/Deployment"))
+	})
+
+	t.Run("only namespaced resources", func(t *testing.T) {
+		summary := Results{deployment: results[deployment]}.StringSummaryMarkdownSplitScope()
+		assert.NotContains(t, summary, "Cluster-scoped")
+		assert.Contains(t, summary, "## Namespaced Resources")
+	})
+}
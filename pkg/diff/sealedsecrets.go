@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sealedSecretChangesHeading introduces the changed keys of a SealedSecret compared without its encrypted values
+const sealedSecretChangesHeading = "# SealedSecret changes:\n"
+
+// SealedSecretKeyChanges returns the keys of spec.encryptedData added, removed or changed between base and head,
+// sorted by key, e.g. "key added: password". Every sealing produces new ciphertext, so resealing an unchanged
+// value is reported as changed too.
+func SealedSecretKeyChanges(base, head *unstructured.Unstructured) []string {
+	baseData, headData := sealedSecretData(base), sealedSecretData(head)
+	actions := make(map[string]string)
+	for key, value := range headData {
+		from, ok := baseData[key]
+		switch {
+		case !ok:
+			actions[key] = "added"
+		case from != value:
+			actions[key] = "changed"
+		}
+	}
+	for key := range baseData {
+		if _, ok := headData[key]; !ok {
+			actions[key] = "removed"
+		}
+	}
+
+	keys := make([]string, 0, len(actions))
+	for key := range actions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	changes := make([]string, 0, len(keys))
+	for _, key := range keys {
+		changes = append(changes, fmt.Sprintf("key %s: %s", actions[key], key))
+	}
+	return changes
+}
+
+// sealedSecretData returns the encrypted values of a SealedSecret by key
+func sealedSecretData(obj *unstructured.Unstructured) map[string]any {
+	if obj == nil {
+		return nil
+	}
+	data, _, _ := unstructured.NestedMap(obj.Object, "spec", "encryptedData")
+	return data
+}
+
+// summarizeSealedSecret returns copies of the base and head objects of a SealedSecret without their encrypted
+// values, recording which keys changed. Keys of created or deleted SealedSecrets are all added or removed.
+// Other objects are returned as they are.
+func summarizeSealedSecret(v objBaseHead) objBaseHead {
+	if !masking.IsSealedSecret(v.base) && !masking.IsSealedSecret(v.head) {
+		return v
+	}
+	v.sealedSecretChanges = SealedSecretKeyChanges(v.base, v.head)
+	for _, obj := range []**unstructured.Unstructured{&v.base, &v.head} {
+		if masking.IsSealedSecret(*obj) {
+			stripped := (*obj).DeepCopy()
+			unstructured.RemoveNestedField(stripped.Object, "spec", "encryptedData")
+			*obj = stripped
+		}
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	baseSealedSecret = `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: db
+  namespace: default
+spec:
+  encryptedData:
+    password: AgBy3i4OJSWKbase
+    user: AgCx9kQ2unchanged
+    token: AgDremoved
+`
+	headSealedSecret = `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: db
+  namespace: default
+spec:
+  encryptedData:
+    password: AgBy3i4OJSWKhead
+    user: AgCx9kQ2unchanged
+    apiKey: AgEadded
+`
+)
+
+func TestYamlString_SealedSecret(t *testing.T) {
+	key := ResourceKey{Group: "bitnami.com", Kind: "SealedSecret", Namespace: "default", Name: "db"}
+
+	t.Run("masked", func(t *testing.T) {
+		results, err := YamlString(baseSealedSecret, headSealedSecret, DefaultOptions())
+		require.NoError(t, err)
+		result := results[key]
+		assert.Equal(t, Changed, result.Type)
+		assert.NotContains(t, result.Diff, "AgBy3i4OJSWK")
+		assert.Contains(t, result.Diff, "password: ++++")
+		assert.Empty(t, result.SealedSecretChanges)
+	})
+
+	t.Run("summarized", func(t *testing.T) {
+		results, err := YamlString(baseSealedSecret, headSealedSecret, DefaultOptions().WithSummarizeSealedSecrets(true))
+		require.NoError(t, err)
+		result := results[key]
+		assert.Equal(t, Changed, result.Type)
+		assert.Equal(t, []string{"key added: apiKey", "key changed: password", "key removed: token"}, result.SealedSecretChanges)
+		assert.Contains(t, result.Diff, "# SealedSecret changes:\n#   key added: apiKey\n")
+		assert.NotContains(t, result.Diff, "encryptedData")
+	})
+
+	t.Run("created", func(t *testing.T) {
+		results, err := YamlString("", headSealedSecret, DefaultOptions().WithSummarizeSealedSecrets(true))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key added: apiKey", "key added: password", "key added: user"}, results[key].SealedSecretChanges)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -507,3 +508,97 @@ func TestSecretMaskingEdgeCases(t *testing.T) {
 		assert.True(t, masking.IsSecret(secret))
 	})
 }
+
+func TestYamlString_ContinueOnError(t *testing.T) {
+	baseYaml := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+data:
+  key: old
+`
+	headYaml := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+data:
+  key: new
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: malformed
+  namespace: default
+data:
+  count: 42
+`
+	_, err := YamlString(baseYaml, headYaml, DefaultOptions())
+	assert.ErrorContains(t, err, "key 'count' has non-string value")
+
+	results, err := YamlString(baseYaml, headYaml, NewOptions().WithContinueOnError(true))
+	require.NoError(t, err)
+	secret := results[ResourceKey{Kind: "Secret", Namespace: "default", Name: "malformed"}]
+	assert.Equal(t, Error, secret.Type)
+	assert.ErrorContains(t, secret.Err, "key 'count' has non-string value")
+	assert.Equal(t, Changed, results[ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "config"}].Type)
+
+	assert.Equal(t, 1, results.GetStatistics().Errors)
+	assert.Contains(t, results.StringSummary(), "# Summary: 2 total, 1 changed, 0 created, 0 deleted, 0 unchanged, 1 failed")
+	assert.Contains(t, results.StringSummary(), "Errors (1):\n  Secret/default/malformed: ")
+	assert.Contains(t, results.StringDiff(), "# Errors (1):\n#   Secret/default/malformed: ")
+	assert.Contains(t, results.StringSummaryMarkdown(), "## Errors (1)\n- `Secret/default/malformed`: ")
+	assert.Equal(t, 1, results.FrontMatter().Errors)
+}
+
+func TestYamlString_DisableMaskingFor(t *testing.T) {
+	baseYaml := `apiVersion: v1
+kind: Secret
+metadata:
+  name: public-cert
+  namespace: default
+data:
+  tls.crt: Y2VydC12MQ==
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+data:
+  password: cGFzc3dvcmQ=
+`
+	headYaml := strings.NewReplacer("Y2VydC12MQ==", "Y2VydC12Mg==", "cGFzc3dvcmQ=", "bmV3cGFzc3dvcmQ=").Replace(baseYaml)
+	cert := ResourceKey{Kind: "Secret", Namespace: "default", Name: "public-cert"}
+	db := ResourceKey{Kind: "Secret", Namespace: "default", Name: "db"}
+
+	tests := []struct {
+		name           string
+		patterns       []string
+		certUnmasked   bool
+		dbUnmasked     bool
+		expectedErrMsg string
+	}{
+		{name: "no patterns", patterns: nil},
+		{name: "exact resource", patterns: []string{"Secret/default/public-cert"}, certUnmasked: true},
+		{name: "glob", patterns: []string{"Secret/*/public-*"}, certUnmasked: true},
+		{name: "several patterns", patterns: []string{"Secret/default/public-cert", "Secret/default/db"}, certUnmasked: true, dbUnmasked: true},
+		{name: "invalid pattern", patterns: []string{"Secret/["}, expectedErrMsg: "invalid disable masking pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.DisableMaskingFor = tt.patterns
+			results, err := YamlString(baseYaml, headYaml, opts)
+			if tt.expectedErrMsg != "" {
+				assert.ErrorContains(t, err, tt.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.certUnmasked, strings.Contains(results[cert].Diff, "Y2VydC12Mg=="), results[cert].Diff)
+			assert.Equal(t, tt.dbUnmasked, strings.Contains(results[db].Diff, "bmV3cGFzc3dvcmQ="), results[db].Diff)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LimitResourceSections returns a copy of the results in which only the first maxResources resources with a diff
+// section in Markdown reports, in key order, keep their diff text, and the number of resources whose sections
+// were left out. Summaries still list every resource. A maxResources of 0 or less disables the limit.
+func (dr Results) LimitResourceSections(maxResources int) (Results, int) {
+	if maxResources <= 0 {
+		return dr, 0
+	}
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+	limited := make(Results, len(dr))
+	shown, omitted := 0, 0
+	for _, key := range keys {
+		diffResult := dr[key]
+		if diffResult.Diff != "" && !diffResult.Trivial {
+			if shown < maxResources {
+				shown++
+			} else {
+				diffResult.Diff = ""
+				omitted++
+			}
+		}
+		limited[key] = diffResult
+	}
+	return limited, omitted
+}
+
+// OmittedSectionsNote returns the Markdown note ending a report whose diff sections were limited with
+// LimitResourceSections, linking to the full report at reportURL if it is not empty, or "" if none were omitted
+func OmittedSectionsNote(omitted int, reportURL string) string {
+	if omitted <= 0 {
+		return ""
+	}
+	resources := "resources"
+	if omitted == 1 {
+		resources = "resource"
+	}
+	if reportURL == "" {
+		return fmt.Sprintf("\n\n_... and %d more %s not shown_", omitted, resources)
+	}
+	return fmt.Sprintf("\n\n_... and %d more %s; see the [full report](%s)_", omitted, resources, markdownLinkDestination(reportURL))
+}
+
+// linkDestinationEscaper percent-encodes the characters that would end a link destination in angle brackets
+var linkDestinationEscaper = strings.NewReplacer("<", "%3C", ">", "%3E", "\n", "%0A", "\r", "%0D")
+
+// markdownLinkDestination returns url as a Markdown link destination in angle brackets, so that spaces,
+// parentheses and underscores in it do not end the link or the emphasis around it
+func markdownLinkDestination(url string) string {
+	return "<" + linkDestinationEscaper.Replace(url) + ">"
+}
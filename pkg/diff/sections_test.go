@@ -0,0 +1,46 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResults_LimitResourceSections(t *testing.T) {
+	first := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "a"}
+	second := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "b"}
+	third := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "c"}
+	trivial := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "0-trivial"}
+	results := Results{
+		first:   {Type: Changed, Diff: "===== /ConfigMap default/a ======\n-a\n+b\n"},
+		second:  {Type: Changed, Diff: "===== /ConfigMap default/b ======\n-a\n+b\n"},
+		third:   {Type: Created, Diff: "===== /ConfigMap default/c ======\n+c\n"},
+		trivial: {Type: Changed, Diff: "===== /ConfigMap default/0-trivial ======\n-a\n+b\n", Trivial: true},
+	}
+
+	limited, omitted := results.LimitResourceSections(1)
+	assert.Equal(t, 2, omitted)
+	assert.Len(t, limited, 4)
+	assert.NotEmpty(t, limited[first].Diff, "the first resource in key order keeps its section")
+	assert.Empty(t, limited[second].Diff)
+	assert.Empty(t, limited[third].Diff)
+	assert.NotEmpty(t, limited[trivial].Diff, "trivial changes have no section to count")
+	assert.NotEmpty(t, results[second].Diff, "the results are not modified")
+
+	output := limited.StringDiffMarkdown()
+	assert.Contains(t, output, "## Changed Resources (2)\n- `ConfigMap/default/a`\n- `ConfigMap/default/b`\n", "summaries list every resource")
+	assert.Contains(t, output, "### /ConfigMap default/a\n")
+	assert.NotContains(t, output, "### /ConfigMap default/b\n")
+
+	unlimited, omitted := results.LimitResourceSections(0)
+	assert.Equal(t, 0, omitted)
+	assert.Equal(t, results, unlimited)
+}
+
+func TestOmittedSectionsNote(t *testing.T) {
+	assert.Empty(t, OmittedSectionsNote(0, "https://example.com/report.md"))
+	assert.Equal(t, "\n\n_... and 1 more resource not shown_", OmittedSectionsNote(1, ""))
+	assert.Equal(t, "\n\n_... and 3 more resources; see the [full report](<https://example.com/report.md>)_", OmittedSectionsNote(3, "https://example.com/report.md"))
+	assert.Equal(t, "\n\n_... and 2 more resources; see the [full report](<https://example.com/a (1)_b.md?q=%3Cx%3E>)_",
+		OmittedSectionsNote(2, "https://example.com/a (1)_b.md?q=<x>"))
+}
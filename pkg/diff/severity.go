@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Severity is how risky a change to a resource is considered
+type Severity int
+
+const (
+	// SeverityNone means severity was not assessed
+	SeverityNone Severity = iota
+	// SeverityLow marks changes that should not block
+	SeverityLow
+	// SeverityMedium is the default severity of resources without a matching rule
+	SeverityMedium
+	// SeverityHigh marks risky changes
+	SeverityHigh
+)
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// ParseSeverity parses a severity name: low, medium or high (case-insensitive)
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range []Severity{SeverityLow, SeverityMedium, SeverityHigh} {
+		if strings.EqualFold(s, severity.String()) {
+			return severity, nil
+		}
+	}
+	return SeverityNone, fmt.Errorf("unknown severity: %s (supported: low, medium, high)", s)
+}
+
+// MarshalText returns the name of the severity
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses the name of a severity
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// SeverityRule assigns a severity to resources matching its kind and namespace. Empty fields match anything.
+type SeverityRule struct {
+	Kind      string   `yaml:"kind,omitempty"`
+	Namespace string   `yaml:"namespace,omitempty"`
+	Severity  Severity `yaml:"severity"`
+}
+
+// SeverityPolicy assigns severities to resources. The first matching rule wins;
+// resources matching no rule get Default, or SeverityMedium when Default is unset.
+type SeverityPolicy struct {
+	Default Severity       `yaml:"default,omitempty"`
+	Rules   []SeverityRule `yaml:"rules"`
+}
+
+// ReadSeverityPolicy reads a severity policy from YAML, e.g.
+//
+//	default: medium
+//	rules:
+//	- kind: CustomResourceDefinition
+//	  severity: high
+//	- namespace: kube-system
+//	  severity: high
+//	- kind: ConfigMap
+//	  severity: low
+func ReadSeverityPolicy(r io.Reader) (*SeverityPolicy, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read severity policy: %w", err)
+	}
+
+	var policy SeverityPolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse severity policy: %w", err)
+	}
+	for i, rule := range policy.Rules {
+		if rule.Severity == SeverityNone {
+			return nil, fmt.Errorf("severity policy rule %d: severity is required", i+1)
+		}
+	}
+	return &policy, nil
+}
+
+// SeverityOf returns the severity of a resource under the policy
+func (p *SeverityPolicy) SeverityOf(key ResourceKey) Severity {
+	if p == nil {
+		return SeverityNone
+	}
+	for _, rule := range p.Rules {
+		if (rule.Kind == "" || rule.Kind == key.Kind) && (rule.Namespace == "" || rule.Namespace == key.Namespace) {
+			return rule.Severity
+		}
+	}
+	if p.Default != SeverityNone {
+		return p.Default
+	}
+	return SeverityMedium
+}
+
+// MaxSeverity returns the highest severity among resources with changes
+func (dr Results) MaxSeverity() Severity {
+	maxSeverity := SeverityNone
+	for _, diffResult := range dr {
+		if diffResult.Type != Unchanged && diffResult.Severity > maxSeverity {
+			maxSeverity = diffResult.Severity
+		}
+	}
+	return maxSeverity
+}
+
+// severitySuffix returns the severity label appended to summary lines, if severity was assessed
+func severitySuffix(severity Severity, format string) string {
+	if severity == SeverityNone {
+		return ""
+	}
+	return fmt.Sprintf(format, severity)
+}
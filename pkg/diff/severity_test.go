@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := ParseSeverity("HIGH")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityHigh, severity)
+
+	_, err = ParseSeverity("critical")
+	assert.ErrorContains(t, err, "unknown severity: critical")
+}
+
+func TestReadSeverityPolicy(t *testing.T) {
+	t.Run("valid policy", func(t *testing.T) {
+		policy, err := ReadSeverityPolicy(strings.NewReader(`
+default: low
+rules:
+- kind: CustomResourceDefinition
+  severity: high
+- namespace: kube-system
+  severity: high
+- kind: ConfigMap
+  severity: low
+`))
+		require.NoError(t, err)
+		assert.Equal(t, SeverityLow, policy.Default)
+		assert.Len(t, policy.Rules, 3)
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "unknown severity", input: "rules:\n- kind: ConfigMap\n  severity: critical\n", expected: "unknown severity"},
+		{name: "missing severity", input: "rules:\n- kind: ConfigMap\n", expected: "severity is required"},
+		{name: "unknown field", input: "rules:\n- kinds: ConfigMap\n  severity: low\n", expected: "failed to parse severity policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSeverityPolicy(strings.NewReader(tt.input))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestSeverityPolicy_SeverityOf(t *testing.T) {
+	policy := &SeverityPolicy{Rules: []SeverityRule{
+		{Namespace: "kube-system", Severity: SeverityHigh},
+		{Kind: "ConfigMap", Severity: SeverityLow},
+	}}
+
+	tests := []struct {
+		name     string
+		policy   *SeverityPolicy
+		key      ResourceKey
+		expected Severity
+	}{
+		{name: "nil policy", policy: nil, key: ResourceKey{Kind: "ConfigMap", Name: "app"}, expected: SeverityNone},
+		{name: "first matching rule wins", policy: policy, key: ResourceKey{Kind: "ConfigMap", Namespace: "kube-system", Name: "app"}, expected: SeverityHigh},
+		{name: "kind rule", policy: policy, key: ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "app"}, expected: SeverityLow},
+		{name: "default medium", policy: policy, key: ResourceKey{Kind: "Service", Namespace: "default", Name: "app"}, expected: SeverityMedium},
+		{name: "custom default", policy: &SeverityPolicy{Default: SeverityLow}, key: ResourceKey{Kind: "Service", Name: "app"}, expected: SeverityLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.policy.SeverityOf(tt.key))
+		})
+	}
+}
+
+func TestResults_Severity(t *testing.T) {
+	results := Results{
+		{Kind: "ConfigMap", Namespace: "default", Name: "app"}:        {Type: Changed, Diff: "===== /ConfigMap default/app ======\n-a\n+b\n", Severity: SeverityLow},
+		{Kind: "Secret", Namespace: "default", Name: "app"}:           {Type: Unchanged, Severity: SeverityHigh},
+		{Kind: "Service", Namespace: "default", Name: "app"}:          {Type: Created, Diff: "===== /Service default/app ======\n+a\n", Severity: SeverityMedium},
+		{Kind: "ServiceAccount", Namespace: "default", Name: "other"}: {Type: Created, Diff: "===== /ServiceAccount default/other ======\n+a\n"},
+	}
+
+	assert.Equal(t, SeverityMedium, results.MaxSeverity(), "unchanged resources do not count")
+	assert.Contains(t, results.StringSummary(), "  ConfigMap/default/app [low]\n")
+	assert.Contains(t, results.StringSummary(), "  ServiceAccount/default/other")
+	assert.NotContains(t, results.StringSummary(), "ServiceAccount/default/other [")
+	assert.Contains(t, results.StringSummaryMarkdown(), "- `Service/default/app` (**medium**)\n")
+}
@@ -0,0 +1,180 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sopsChangesHeading introduces the changes to the SOPS encryption metadata of a resource
+const sopsChangesHeading = "# SOPS changes:\n"
+
+// sopsField is the top-level field SOPS stores its encryption metadata in
+const sopsField = "sops"
+
+// sopsEncryptedValue matches a value encrypted by SOPS, capturing its type,
+// e.g. "ENC[AES256_GCM,data:...,iv:...,tag:...,type:str]"
+var sopsEncryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:[^,]*,iv:[^,]*,tag:[^,]*,type:([a-z]+)\]$`)
+
+// sopsKeyTypes are the SOPS master key types with the fields identifying a key, joined by "/"
+var sopsKeyTypes = []struct {
+	name   string
+	fields []string
+}{
+	{name: "age", fields: []string{"recipient"}},
+	{name: "pgp", fields: []string{"fp"}},
+	{name: "kms", fields: []string{"arn"}},
+	{name: "gcp_kms", fields: []string{"resource_id"}},
+	{name: "azure_kv", fields: []string{"vault_url", "name"}},
+	{name: "hc_vault", fields: []string{"vault_address", "engine_path", "key_name"}},
+}
+
+// sopsSettings are the metadata fields whose values are compared as they are
+var sopsSettings = []string{
+	"lastmodified", "version",
+	"encrypted_regex", "unencrypted_regex", "encrypted_suffix", "unencrypted_suffix", "encrypted_comment_regex",
+}
+
+// IsSOPSEncrypted reports whether obj was encrypted by SOPS, i.e. has SOPS metadata with a MAC
+func IsSOPSEncrypted(obj *unstructured.Unstructured) bool {
+	if obj == nil {
+		return false
+	}
+	_, found, _ := unstructured.NestedFieldNoCopy(obj.Object, sopsField, "mac")
+	return found
+}
+
+// SOPSChanges returns the changes to the SOPS metadata between base and head, e.g. "age key added: age1..." or
+// "lastmodified: 2025-01-01T00:00:00Z -> 2025-02-01T00:00:00Z". Without the keys, encrypted values cannot be
+// compared, so these changes tell re-encryptions from edits of unencrypted fields.
+func SOPSChanges(base, head *unstructured.Unstructured) []string {
+	baseEncrypted, headEncrypted := IsSOPSEncrypted(base), IsSOPSEncrypted(head)
+	switch {
+	case !baseEncrypted && !headEncrypted:
+		return nil
+	case !baseEncrypted:
+		return []string{"encrypted with SOPS"}
+	case !headEncrypted:
+		return []string{"no longer encrypted with SOPS"}
+	}
+
+	var changes []string
+	baseKeys, headKeys := sopsKeys(base), sopsKeys(head)
+	for key := range headKeys {
+		if !baseKeys[key] {
+			changes = append(changes, fmt.Sprintf("%s key added: %s", key.keyType, key.id))
+		}
+	}
+	for key := range baseKeys {
+		if !headKeys[key] {
+			changes = append(changes, fmt.Sprintf("%s key removed: %s", key.keyType, key.id))
+		}
+	}
+	sort.Strings(changes)
+
+	for _, setting := range sopsSettings {
+		from := sopsSetting(base, setting)
+		to := sopsSetting(head, setting)
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", setting, from, to))
+		}
+	}
+	return changes
+}
+
+// sopsKey is a master key data keys are encrypted with, e.g. an age recipient
+type sopsKey struct {
+	keyType string
+	id      string
+}
+
+// sopsKeys returns the master keys of the SOPS metadata of obj, including those of key groups
+func sopsKeys(obj *unstructured.Unstructured) map[sopsKey]bool {
+	keys := make(map[sopsKey]bool)
+	metadata, _, _ := unstructured.NestedMap(obj.Object, sopsField)
+	groups := []map[string]any{metadata}
+	if keyGroups, ok := metadata["key_groups"].([]any); ok {
+		for _, group := range keyGroups {
+			if group, ok := group.(map[string]any); ok {
+				groups = append(groups, group)
+			}
+		}
+	}
+	for _, group := range groups {
+		for _, keyType := range sopsKeyTypes {
+			entries, _ := group[keyType.name].([]any)
+			for _, entry := range entries {
+				entry, ok := entry.(map[string]any)
+				if !ok {
+					continue
+				}
+				ids := make([]string, 0, len(keyType.fields))
+				for _, field := range keyType.fields {
+					id, _ := entry[field].(string)
+					ids = append(ids, id)
+				}
+				keys[sopsKey{keyType: keyType.name, id: strings.Join(ids, "/")}] = true
+			}
+		}
+	}
+	return keys
+}
+
+// sopsUnset stands for a metadata field missing on one side of a change
+const sopsUnset = "(none)"
+
+// sopsSetting returns a metadata field of obj as a string, or "(none)" if it is not set
+func sopsSetting(obj *unstructured.Unstructured, field string) string {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj.Object, sopsField, field)
+	if !found {
+		return sopsUnset
+	}
+	return fmt.Sprint(value)
+}
+
+// normalizeSOPS returns copies of the base and head objects of a resource encrypted by SOPS without its metadata
+// and with encrypted values replaced by their type, recording the changes to the metadata. Encrypted values change
+// on every encryption, so comparing them would only show noise.
+func normalizeSOPS(v objBaseHead) objBaseHead {
+	if !IsSOPSEncrypted(v.base) && !IsSOPSEncrypted(v.head) {
+		return v
+	}
+	if v.base != nil && v.head != nil {
+		v.sopsChanges = SOPSChanges(v.base, v.head)
+	}
+	for _, obj := range []**unstructured.Unstructured{&v.base, &v.head} {
+		if IsSOPSEncrypted(*obj) {
+			stripped := (*obj).DeepCopy()
+			unstructured.RemoveNestedField(stripped.Object, sopsField)
+			stripped.Object = maskSOPSValues(stripped.Object).(map[string]any)
+			*obj = stripped
+		}
+	}
+	return v
+}
+
+// maskSOPSValues replaces the encrypted values in value by "ENC[type]", keeping the type of the plaintext
+func maskSOPSValues(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for k, v := range value {
+			value[k] = maskSOPSValues(v)
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = maskSOPSValues(v)
+		}
+		return value
+	case string:
+		if match := sopsEncryptedValue.FindStringSubmatch(value); match != nil {
+			return "ENC[" + match[1] + "]"
+		}
+		return value
+	default:
+		return value
+	}
+}
@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sopsSecret returns a SOPS-encrypted Secret with the given ciphertext, age recipients and modification time
+func sopsSecret(ciphertext, lastModified string, recipients ...string) string {
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+stringData:
+  password: ENC[AES256_GCM,data:` + ciphertext + `,iv:aXY=,tag:dGFn,type:str]
+sops:
+  age:
+`
+	for _, recipient := range recipients {
+		manifest += "  - recipient: " + recipient + "\n    enc: ENCRYPTED\n"
+	}
+	return manifest + `  lastmodified: "` + lastModified + `"
+  mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+  version: 3.9.0
+`
+}
+
+func TestYamlString_SOPS(t *testing.T) {
+	key := ResourceKey{Kind: "Secret", Namespace: "default", Name: "db"}
+
+	t.Run("re-encrypted", func(t *testing.T) {
+		results, err := YamlString(
+			sopsSecret("Y2lwaGVyMQ==", "2025-01-01T00:00:00Z", "age1alice"),
+			sopsSecret("Y2lwaGVyMg==", "2025-02-01T00:00:00Z", "age1alice", "age1bob"),
+			DefaultOptions())
+		require.NoError(t, err)
+		result := results[key]
+		assert.Equal(t, Changed, result.Type)
+		assert.Equal(t, []string{"age key added: age1bob", "lastmodified: 2025-01-01T00:00:00Z -> 2025-02-01T00:00:00Z"}, result.SOPSChanges)
+		assert.Contains(t, result.Diff, "# SOPS changes:\n#   age key added: age1bob\n")
+		assert.NotContains(t, result.Diff, "Y2lwaGVy")
+		assert.NotContains(t, result.Diff, "mac:")
+	})
+
+	t.Run("identical metadata", func(t *testing.T) {
+		manifest := sopsSecret("Y2lwaGVyMQ==", "2025-01-01T00:00:00Z", "age1alice")
+		results, err := YamlString(manifest, manifest, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Unchanged, results[key].Type)
+	})
+
+	t.Run("encrypted data", func(t *testing.T) {
+		// Encrypted values under data are not base64, and must not fail Secret validation
+		base := strings.Replace(sopsSecret("Y2lwaGVyMQ==", "2025-01-01T00:00:00Z", "age1alice"), "stringData:", "data:", 1)
+		head := strings.Replace(sopsSecret("Y2lwaGVyMg==", "2025-02-01T00:00:00Z", "age1alice"), "stringData:", "data:", 1)
+		results, err := YamlString(base, head, DefaultOptions())
+		require.NoError(t, err)
+		result := results[key]
+		assert.Equal(t, Changed, result.Type)
+		assert.Equal(t, []string{"lastmodified: 2025-01-01T00:00:00Z -> 2025-02-01T00:00:00Z"}, result.SOPSChanges)
+		assert.NotContains(t, result.Diff, "Y2lwaGVy")
+	})
+
+	t.Run("encryption added", func(t *testing.T) {
+		plain := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  namespace: default\nstringData:\n  password: hunter2\n"
+		results, err := YamlString(plain, sopsSecret("Y2lwaGVyMQ==", "2025-01-01T00:00:00Z", "age1alice"), DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"encrypted with SOPS"}, results[key].SOPSChanges)
+	})
+}
+
+func TestMaskSOPSValues(t *testing.T) {
+	masked := maskSOPSValues(map[string]any{
+		"count":  "ENC[AES256_GCM,data:MQ==,iv:aXY=,tag:dGFn,type:int]",
+		"list":   []any{"ENC[AES256_GCM,data:YQ==,iv:aXY=,tag:dGFn,type:str]", "plain"},
+		"nested": map[string]any{"enabled": true},
+	})
+	assert.Equal(t, map[string]any{
+		"count":  "ENC[int]",
+		"list":   []any{"ENC[str]", "plain"},
+		"nested": map[string]any{"enabled": true},
+	}, masked)
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// statusChangesHeading introduces the condition transitions of a resource compared without its status
+const statusChangesHeading = "# Status changes:\n"
+
+// noCondition stands for a condition missing on one side of a transition
+const noCondition = "(none)"
+
+// StatusConditionChanges returns the transitions of status.conditions between base and head, sorted by
+// condition type, e.g. "Ready: True -> False". Conditions only on one side transition from or to "(none)".
+func StatusConditionChanges(base, head *unstructured.Unstructured) []string {
+	baseConditions, headConditions := statusConditions(base), statusConditions(head)
+	types := make(map[string]bool)
+	for conditionType := range baseConditions {
+		types[conditionType] = true
+	}
+	for conditionType := range headConditions {
+		types[conditionType] = true
+	}
+
+	var changes []string
+	for conditionType := range types {
+		from, inBase := baseConditions[conditionType]
+		to, inHead := headConditions[conditionType]
+		if inBase && inHead && from == to {
+			continue
+		}
+		if !inBase {
+			from = noCondition
+		}
+		if !inHead {
+			to = noCondition
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", conditionType, from, to))
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// statusConditions returns the status of each condition in status.conditions by type
+func statusConditions(obj *unstructured.Unstructured) map[string]string {
+	conditions := make(map[string]string)
+	if obj == nil {
+		return conditions
+	}
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range items {
+		condition, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		if conditionType == "" {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		conditions[conditionType] = status
+	}
+	return conditions
+}
+
+// removeStatus returns copies of the base and head objects of a resource without their status,
+// recording the transitions of its conditions if it exists on both sides
+func removeStatus(v objBaseHead) objBaseHead {
+	if v.base != nil && v.head != nil {
+		v.statusChanges = StatusConditionChanges(v.base, v.head)
+	}
+	for _, obj := range []**unstructured.Unstructured{&v.base, &v.head} {
+		if *obj != nil {
+			stripped := (*obj).DeepCopy()
+			unstructured.RemoveNestedField(stripped.Object, "status")
+			*obj = stripped
+		}
+	}
+	return v
+}
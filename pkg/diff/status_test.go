@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newCertificate(name string, revisionHistoryLimit int64, conditions map[string]string) *unstructured.Unstructured {
+	items := make([]any, 0, len(conditions))
+	for conditionType, status := range conditions {
+		items = append(items, map[string]any{"type": conditionType, "status": status, "reason": "Reconciled"})
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]any{"name": name, "namespace": "default"},
+		"spec":       map[string]any{"secretName": name, "revisionHistoryLimit": revisionHistoryLimit},
+		"status":     map[string]any{"conditions": items, "observedGeneration": int64(1)},
+	}}
+}
+
+func TestStatusConditionChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     map[string]string
+		head     map[string]string
+		expected []string
+	}{
+		{name: "unchanged", base: map[string]string{"Ready": "True"}, head: map[string]string{"Ready": "True"}},
+		{name: "transition", base: map[string]string{"Ready": "True"}, head: map[string]string{"Ready": "False"}, expected: []string{"Ready: True -> False"}},
+		{
+			name:     "added and removed",
+			base:     map[string]string{"Issuing": "True"},
+			head:     map[string]string{"Ready": "True"},
+			expected: []string{"Issuing: True -> (none)", "Ready: (none) -> True"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := StatusConditionChanges(newCertificate("web", 1, tt.base), newCertificate("web", 1, tt.head))
+			assert.Equal(t, tt.expected, changes)
+		})
+	}
+}
+
+func TestObjects_SummarizeStatus(t *testing.T) {
+	base := []*unstructured.Unstructured{
+		newCertificate("transition", 1, map[string]string{"Ready": "True"}),
+		newCertificate("status-only", 1, map[string]string{"Ready": "True"}),
+		newCertificate("spec-change", 1, map[string]string{"Ready": "True"}),
+	}
+	head := []*unstructured.Unstructured{
+		newCertificate("transition", 1, map[string]string{"Ready": "False"}),
+		newCertificate("status-only", 1, map[string]string{"Ready": "True"}),
+		newCertificate("spec-change", 2, map[string]string{"Ready": "True"}),
+	}
+	require.NoError(t, unstructured.SetNestedField(head[1].Object, int64(2), "status", "observedGeneration"))
+
+	opts := NewOptions().WithSummarizeStatus(true)
+	results, err := Objects(base, head, opts)
+	require.NoError(t, err)
+
+	key := func(name string) ResourceKey {
+		return ResourceKey{Group: "cert-manager.io", Kind: "Certificate", Namespace: "default", Name: name}
+	}
+	transition := results[key("transition")]
+	assert.Equal(t, Changed, transition.Type)
+	assert.Equal(t, []string{"Ready: True -> False"}, transition.StatusChanges)
+	assert.Contains(t, transition.Diff, statusChangesHeading+"#   Ready: True -> False\n")
+	assert.NotContains(t, transition.Diff, "observedGeneration")
+
+	assert.Equal(t, Unchanged, results[key("status-only")].Type)
+
+	specChange := results[key("spec-change")]
+	assert.Equal(t, Changed, specChange.Type)
+	assert.Empty(t, specChange.StatusChanges)
+	assert.NotContains(t, specChange.Diff, "conditions")
+
+	results, err = Objects(base, head, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Changed, results[key("status-only")].Type)
+}
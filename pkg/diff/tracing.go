@@ -0,0 +1,19 @@
+package diff
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records spans of the diff stages with the globally registered tracer provider (a no-op unless one is set)
+var tracer = otel.Tracer("github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff")
+
+// endSpan marks span as failed if err is not nil and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
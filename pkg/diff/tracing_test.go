@@ -0,0 +1,48 @@
+package diff
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestYamlContextSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	spanNames := func() []string {
+		var names []string
+		for _, span := range recorder.Ended() {
+			names = append(names, span.Name())
+		}
+		return names
+	}
+
+	t.Run("stages", func(t *testing.T) {
+		base := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  key: a\n"
+		head := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  key: b\n"
+		_, err := YamlContext(context.Background(), strings.NewReader(base), strings.NewReader(head), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"diff.parse", "diff.filter", "diff.pair", "diff.resource", "diff.render", "diff.Objects"}, spanNames())
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.RenameThreshold = 2
+		_, err := ObjectsContext(context.Background(), nil, nil, opts)
+		require.Error(t, err)
+		spans := recorder.Ended()
+		last := spans[len(spans)-1]
+		assert.Equal(t, "diff.Objects", last.Name())
+		assert.Equal(t, codes.Error, last.Status().Code)
+	})
+}
@@ -0,0 +1,37 @@
+package diff
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Transform rewrites an object before comparison, e.g. to decrypt SOPS-encrypted values or rewrite image registries.
+// It receives a copy of the object, which it may modify and return. Returning nil drops the object.
+type Transform func(*unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+// transformObjects applies the transforms in order to copies of objs, dropping objects a transform returns nil for
+func transformObjects(objs []*unstructured.Unstructured, transforms []Transform) ([]*unstructured.Unstructured, error) {
+	transformed := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if obj == nil {
+			transformed = append(transformed, obj)
+			continue
+		}
+		key := DefaultKeyFunc(obj)
+		obj = obj.DeepCopy()
+		for _, transform := range transforms {
+			var err error
+			if obj, err = transform(obj); err != nil {
+				return nil, fmt.Errorf("failed to transform %s: %w", key, err)
+			}
+			if obj == nil {
+				break
+			}
+		}
+		if obj != nil {
+			transformed = append(transformed, obj)
+		}
+	}
+	return transformed, nil
+}
@@ -0,0 +1,43 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjects_PreTransform(t *testing.T) {
+	base := []*unstructured.Unstructured{newConfigMap("app", "default", "v1"), newConfigMap("scratch", "default", "x")}
+	head := []*unstructured.Unstructured{newConfigMap("app", "default", "ENC[v1]")}
+
+	decrypt := func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		value, _, _ := unstructured.NestedString(obj.Object, "data", "key")
+		if strings.HasPrefix(value, "ENC[") {
+			err := unstructured.SetNestedField(obj.Object, strings.TrimSuffix(strings.TrimPrefix(value, "ENC["), "]"), "data", "key")
+			return obj, err
+		}
+		return obj, nil
+	}
+	dropScratch := func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		if obj.GetName() == "scratch" {
+			return nil, nil
+		}
+		return obj, nil
+	}
+
+	results, err := Objects(base, head, NewOptions().WithPreTransform(decrypt, dropScratch))
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.False(t, results.HasChanges())
+
+	value, _, _ := unstructured.NestedString(head[0].Object, "data", "key")
+	assert.Equal(t, "ENC[v1]", value, "transforms receive copies")
+
+	failing := func(*unstructured.Unstructured) (*unstructured.Unstructured, error) { return nil, errors.New("no key") }
+	_, err = Objects(base, head, NewOptions().WithPreTransform(failing))
+	assert.ErrorContains(t, err, "failed to transform /ConfigMap/default/app: no key")
+}
@@ -2,9 +2,14 @@ package diff
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // ResourceKey uniquely identifies a Kubernetes resource
@@ -35,6 +40,9 @@ const (
 	Created
 	// Deleted indicates that a resource exists only in base (deleted)
 	Deleted
+	// Error indicates that diffing or masking the resource failed, with the cause in Result.Err.
+	// It is only reported with Options.ContinueOnError; otherwise the failure aborts the diff.
+	Error
 )
 
 // String returns the string representation of ChangeType
@@ -48,6 +56,8 @@ func (ct ChangeType) String() string {
 		return "created"
 	case Deleted:
 		return "deleted"
+	case Error:
+		return "error"
 	default:
 		return "unknown"
 	}
@@ -55,8 +65,32 @@ func (ct ChangeType) String() string {
 
 // Result represents the result of a diff operation for a resource
 type Result struct {
-	Type ChangeType // Type of change (Created, Changed, Deleted, Unchanged)
-	Diff string     // Diff string representation
+	Type                ChangeType                 // Type of change (Created, Changed, Deleted, Unchanged, Error)
+	Diff                string                     // Diff string representation
+	Trivial             bool                       // True if the change is below Options.MinimumChangedLines
+	ClusterScoped       bool                       // True if the resource is a custom resource defined as cluster-scoped by a CustomResourceDefinition in base or head, see IsClusterScoped for built-in kinds
+	WouldPrune          bool                       // True if the resource is deleted but carries the tracking label or annotation of Options.PruneTracking, so a GitOps controller with auto-prune would delete it
+	Unlisted            bool                       // True if the resource is counted in summary statistics but not listed in summaries, see Results.UnlistUnchanged
+	RestartOnly         bool                       // True if a workload changed only in checksum/ pod template annotations and a ConfigMap or Secret it references changed too, so the change merely restarts its pods
+	ImmutableChanges    []string                   // Immutable fields that changed, forcing the resource to be replaced
+	CertificateChanges  []string                   // Semantic changes to the certificates of a kubernetes.io/tls Secret
+	RegistryChanges     []string                   // Registries added, removed or with changed credentials in a Docker config Secret
+	SecretSourceChanges []string                   // Changed stores and remote references of an ExternalSecret and Vault Agent Injector secret paths
+	ImageChanges        []string                   // Container image changes of a workload
+	ExposureChanges     []string                   // Changes to how the resource is exposed outside the cluster (Service types and routed hosts)
+	App                 string                     // Application the resource belongs to by its app.kubernetes.io/name, app or k8s-app label
+	Severity            Severity                   // Severity assigned by Options.Severity (SeverityNone when not assessed)
+	Owners              []string                   // Owning teams assigned by Options.Owners
+	ChangeSet           string                     // Changed workload ("Kind/namespace/name") whose change set the resource belongs to, together with the changed ConfigMaps and Secrets it references (empty when not linked)
+	RenamedFrom         *ResourceKey               // Deleted resource paired with this one as a probable rename by Options.RenameThreshold (nil otherwise)
+	ComparisonDetail    string                     // Difference described by the Comparer registered for the kind, see RegisterComparer
+	StatusChanges       []string                   // Condition transitions of a resource compared without its status by Options.SummarizeStatus
+	SOPSChanges         []string                   // Changes to the SOPS metadata of an encrypted resource, whose encrypted values are compared by type only
+	SealedSecretChanges []string                   // Added, removed and changed keys of a SealedSecret compared without its encrypted values by Options.SummarizeSealedSecrets
+	Annotations         []FieldAnnotation          // Head file line ranges of the changed fields, located by Options.HeadSources
+	Base                *unstructured.Unstructured // Compared base object, masked and normalized, retained by Options.RetainObjects (nil otherwise or when created)
+	Head                *unstructured.Unstructured // Compared head object, masked and normalized, retained by Options.RetainObjects (nil otherwise or when deleted)
+	Err                 error                      // Why diffing or masking the resource failed, for Error results
 }
 
 // String returns the string representation of Result
@@ -74,23 +108,15 @@ type Statistics struct {
 	Created   int
 	Deleted   int
 	Unchanged int
+	Errors    int
 }
 
 // StringDiff returns a concatenated string of all diff results with summary header
 func (dr Results) StringDiff() string {
 	var result strings.Builder
 
-	// Check if there are any changes that need diff output
-	hasDiffContent := false
-	for _, diffResult := range dr {
-		if diffResult.Diff != "" {
-			hasDiffContent = true
-			break
-		}
-	}
-
 	// Add summary content as comment header only if there are changes
-	if hasDiffContent {
+	if dr.hasDiffContent() {
 		summaryComments := dr.StringSummaryAsComments()
 		if summaryComments != "" {
 			result.WriteString(summaryComments)
@@ -98,77 +124,70 @@ func (dr Results) StringDiff() string {
 		}
 	}
 
-	// Add diff content
-	for _, diffResult := range dr {
-		if diffResult.Diff != "" {
-			result.WriteString(diffResult.Diff)
-		}
-	}
+	dr.writeDiffBodies(&result)
+	dr.writeTrivialList(&result)
+	dr.writeErrorList(&result)
 	return result.String()
 }
 
 // StringSummary returns a summary string organized by change types: Unchanged, Changed, Create, Delete
 func (dr Results) StringSummary() string {
 	var result strings.Builder
+	dr.writeSummaryHeader(&result)
+	dr.writeSummarySections(&result)
+	return strings.TrimRight(result.String(), "\n")
+}
 
-	// Helper function to format ResourceKey as string
-	formatResourceKey := func(key ResourceKey) string {
-		if key.Namespace != "" {
-			return fmt.Sprintf("%s/%s/%s", key.Kind, key.Namespace, key.Name)
-		}
-		return fmt.Sprintf("%s/%s", key.Kind, key.Name)
-	}
+// StringSummaryAsComments returns the summary content formatted as comment lines
+func (dr Results) StringSummaryAsComments() string {
+	return asComments(dr.StringSummary())
+}
 
-	// Helper function to write a section with count and header comment
-	writeSection := func(title string, keys []ResourceKey) {
-		if len(keys) > 0 {
-			// Add section header comment
-			result.WriteString(fmt.Sprintf("# %s: %d resources\n", title, len(keys)))
-			result.WriteString(fmt.Sprintf("%s (%d):\n", title, len(keys)))
-			for _, key := range keys {
-				result.WriteString(fmt.Sprintf("  %s\n", formatResourceKey(key)))
-			}
-			result.WriteString("\n")
-		}
-	}
+// StringSummaryMarkdown returns a summary string in Markdown format
+func (dr Results) StringSummaryMarkdown() string {
+	var result strings.Builder
+	dr.writeSummaryHeaderMarkdown(&result)
+	dr.writeSummarySectionsMarkdown(&result, "##")
+	return strings.TrimRight(result.String(), "\n")
+}
 
-	// Get sections
-	unchangedKeys := dr.FilterUnchanged().GetResourceKeys()
-	changedKeys := dr.FilterChanged().GetResourceKeys()
-	createdKeys := dr.FilterCreated().GetResourceKeys()
-	deletedKeys := dr.FilterDeleted().GetResourceKeys()
+// StringDiffMarkdown returns a concatenated string of all diff results with markdown formatting
+func (dr Results) StringDiffMarkdown() string {
+	var result strings.Builder
 
-	// Only add comment header if there are any resources
-	stats := dr.GetStatistics()
-	if stats.Total > 0 {
-		result.WriteString(fmt.Sprintf("# Summary: %d total, %d changed, %d created, %d deleted, %d unchanged\n",
-			stats.Total, stats.Changed, stats.Created, stats.Deleted, stats.Unchanged))
-		result.WriteString("#\n")
+	// Add summary content as markdown header only if there are changes
+	if dr.hasDiffContent() {
+		summaryMarkdown := dr.StringSummaryMarkdown()
+		if summaryMarkdown != "" {
+			result.WriteString(summaryMarkdown)
+			result.WriteString("\n\n---\n\n")
+			dr.writeContentsMarkdown(&result)
+			result.WriteString("## Resource Changes\n\n")
+		}
 	}
 
-	// Use filtering methods to organize resources by change type
-	writeSection("Unchanged", unchangedKeys)
-	writeSection("Changed", changedKeys)
-	writeSection("Create", createdKeys)
-	writeSection("Delete", deletedKeys)
-
+	dr.writeDiffBodiesMarkdown(&result)
 	return strings.TrimRight(result.String(), "\n")
 }
 
-// StringSummaryAsComments returns the summary content formatted as comment lines
-func (dr Results) StringSummaryAsComments() string {
-	summaryContent := dr.StringSummary()
-	if summaryContent == "" {
-		return ""
+// hasDiffContent reports whether any result has diff output or failed
+func (dr Results) hasDiffContent() bool {
+	for _, diffResult := range dr {
+		if diffResult.Diff != "" || diffResult.Type == Error {
+			return true
+		}
 	}
+	return false
+}
 
-	var result strings.Builder
-	lines := strings.Split(summaryContent, "\n")
-	if lines == nil {
+// asComments prefixes every line of content with "# "
+func asComments(content string) string {
+	if content == "" {
 		return ""
 	}
 
-	for _, line := range lines {
+	var result strings.Builder
+	for _, line := range strings.Split(content, "\n") {
 		if line != "" {
 			result.WriteString(fmt.Sprintf("# %s\n", line))
 		} else {
@@ -178,108 +197,194 @@ func (dr Results) StringSummaryAsComments() string {
 	return result.String()
 }
 
-// StringSummaryMarkdown returns a summary string in Markdown format
-func (dr Results) StringSummaryMarkdown() string {
-	var result strings.Builder
+// writeDiffBodies writes the diff text of all non-trivial results
+func (dr Results) writeDiffBodies(result *strings.Builder) {
+	for _, diffResult := range dr {
+		if diffResult.Diff != "" && !diffResult.Trivial {
+			result.WriteString(diffResult.Diff)
+		}
+	}
+}
+
+// writeTrivialList lists trivial changes without their diff content
+func (dr Results) writeTrivialList(result *strings.Builder) {
+	if trivialKeys := dr.FilterTrivial().GetResourceKeys(); len(trivialKeys) > 0 {
+		sortResourceKeys(trivialKeys)
+		result.WriteString(fmt.Sprintf("# Trivial changes (%d):\n", len(trivialKeys)))
+		for _, key := range trivialKeys {
+			result.WriteString(fmt.Sprintf("#   %s\n", formatResourceKeyShort(key)))
+		}
+	}
+}
+
+// writeErrorList lists the resources that failed to diff with their errors
+func (dr Results) writeErrorList(result *strings.Builder) {
+	if errorKeys := dr.FilterErrors().GetResourceKeys(); len(errorKeys) > 0 {
+		sortResourceKeys(errorKeys)
+		result.WriteString(fmt.Sprintf("# Errors (%d):\n", len(errorKeys)))
+		for _, key := range errorKeys {
+			result.WriteString(fmt.Sprintf("#   %s%s\n", formatResourceKeyShort(key), errorSuffix(dr[key].Err, ": %v")))
+		}
+	}
+}
+
+// markdownErrorSuffix formats the error of a failed result as escaped Markdown text, or returns "" if it has none
+func markdownErrorSuffix(err error) string {
+	if err == nil {
+		return ""
+	}
+	return ": " + markdownText(err.Error())
+}
+
+// errorSuffix formats the error of a failed result, or returns "" if it has none
+func errorSuffix(err error, format string) string {
+	if err == nil {
+		return ""
+	}
+	return fmt.Sprintf(format, err)
+}
 
+// writeSummaryHeader writes the statistics comment line of the text summary
+func (dr Results) writeSummaryHeader(result *strings.Builder) {
+	// Only add comment header if there are any resources
+	stats := dr.GetStatistics()
+	if stats.Total > 0 {
+		result.WriteString(fmt.Sprintf("# Summary: %d total, %d changed, %d created, %d deleted, %d unchanged",
+			stats.Total, stats.Changed, stats.Created, stats.Deleted, stats.Unchanged))
+		if stats.Errors > 0 {
+			result.WriteString(fmt.Sprintf(", %d failed", stats.Errors))
+		}
+		result.WriteString("\n#\n")
+	}
+}
+
+// writeSummarySections writes the resources of the text summary grouped by change type
+func (dr Results) writeSummarySections(result *strings.Builder) {
 	// Helper function to format ResourceKey as string
 	formatResourceKey := func(key ResourceKey) string {
 		if key.Namespace != "" {
-			return fmt.Sprintf("`%s/%s/%s`", key.Kind, key.Namespace, key.Name)
+			return fmt.Sprintf("%s/%s/%s", key.Kind, key.Namespace, key.Name)
 		}
-		return fmt.Sprintf("`%s/%s`", key.Kind, key.Name)
+		return fmt.Sprintf("%s/%s", key.Kind, key.Name)
 	}
 
-	// Helper function to write a section with count and header
+	// Helper function to write a section with count and header comment
 	writeSection := func(title string, keys []ResourceKey) {
 		if len(keys) > 0 {
-			result.WriteString(fmt.Sprintf("## %s (%d)\n", title, len(keys)))
+			sortResourceKeys(keys)
+			// Add section header comment
+			result.WriteString(fmt.Sprintf("# %s: %d resources\n", title, len(keys)))
+			result.WriteString(fmt.Sprintf("%s (%d):\n", title, len(keys)))
 			for _, key := range keys {
-				result.WriteString(fmt.Sprintf("- %s\n", formatResourceKey(key)))
+				result.WriteString(fmt.Sprintf("  %s%s%s%s\n", formatResourceKey(key), renamedFromSuffix(dr[key].RenamedFrom, " (renamed from %s)"), severitySuffix(dr[key].Severity, " [%s]"), errorSuffix(dr[key].Err, ": %v")))
 			}
 			result.WriteString("\n")
 		}
 	}
 
-	// Get sections
-	unchangedKeys := dr.FilterUnchanged().GetResourceKeys()
-	changedKeys := dr.FilterChanged().GetResourceKeys()
-	createdKeys := dr.FilterCreated().GetResourceKeys()
-	deletedKeys := dr.FilterDeleted().GetResourceKeys()
+	// Use filtering methods to organize resources by change type
+	writeSection("Unchanged", dr.FilterUnchanged().listed().GetResourceKeys())
+	writeSection("Changed", dr.FilterSubstantial().FilterChanged().FilterNotRestartOnly().GetResourceKeys())
+	writeSection("Restart-only (config checksum)", dr.FilterSubstantial().FilterRestartOnly().GetResourceKeys())
+	writeSection("Trivial", dr.FilterTrivial().GetResourceKeys())
+	writeSection("Create", dr.FilterCreated().GetResourceKeys())
+	writeSection("Delete", dr.FilterDeleted().FilterNotPruned().GetResourceKeys())
+	writeSection("Prune", dr.FilterWouldPrune().GetResourceKeys())
+	writeSection("Errors", dr.FilterErrors().GetResourceKeys())
+}
 
+// writeSummaryHeaderMarkdown writes the title and statistics of the Markdown summary
+func (dr Results) writeSummaryHeaderMarkdown(result *strings.Builder) {
 	// Only add header if there are any resources
 	stats := dr.GetStatistics()
 	if stats.Total > 0 {
 		result.WriteString("# Kubernetes Manifest Diff\n\n")
 		result.WriteString("## Summary\n")
 		result.WriteString(fmt.Sprintf("**Total Resources**: %d  \n", stats.Total))
-		result.WriteString(fmt.Sprintf("**Changed**: %d | **Created**: %d | **Deleted**: %d | **Unchanged**: %d\n\n",
+		result.WriteString(fmt.Sprintf("**Changed**: %d | **Created**: %d | **Deleted**: %d | **Unchanged**: %d",
 			stats.Changed, stats.Created, stats.Deleted, stats.Unchanged))
+		if stats.Errors > 0 {
+			result.WriteString(fmt.Sprintf(" | **Failed**: %d", stats.Errors))
+		}
+		result.WriteString("\n\n")
 	}
-
-	// Use filtering methods to organize resources by change type
-	writeSection("Created Resources", createdKeys)
-	writeSection("Changed Resources", changedKeys)
-	writeSection("Deleted Resources", deletedKeys)
-	writeSection("Unchanged Resources", unchangedKeys)
-
-	return strings.TrimRight(result.String(), "\n")
 }
 
-// StringDiffMarkdown returns a concatenated string of all diff results with markdown formatting
-func (dr Results) StringDiffMarkdown() string {
-	var result strings.Builder
-
-	// Check if there are any changes that need diff output
-	hasDiffContent := false
-	for _, diffResult := range dr {
-		if diffResult.Diff != "" {
-			hasDiffContent = true
-			break
-		}
+// writeSummarySectionsMarkdown writes the resources of the Markdown summary grouped by change type
+// using the given heading marker for section titles
+func (dr Results) writeSummarySectionsMarkdown(result *strings.Builder, heading string) {
+	// Helper function to format ResourceKey as string
+	formatResourceKey := func(key ResourceKey) string {
+		return markdownCode(formatResourceKeyShort(key))
 	}
 
-	// Add summary content as markdown header only if there are changes
-	if hasDiffContent {
-		summaryMarkdown := dr.StringSummaryMarkdown()
-		if summaryMarkdown != "" {
-			result.WriteString(summaryMarkdown)
-			result.WriteString("\n\n---\n\n")
-			result.WriteString("## Resource Changes\n\n")
+	// Helper function to write a section with count and header
+	writeSection := func(title string, keys []ResourceKey) {
+		if len(keys) > 0 {
+			sortResourceKeys(keys)
+			result.WriteString(fmt.Sprintf("%s %s (%d)\n", heading, title, len(keys)))
+			for _, key := range keys {
+				result.WriteString(fmt.Sprintf("- %s%s%s%s\n", formatResourceKey(key), markdownRenamedFromSuffix(dr[key].RenamedFrom), severitySuffix(dr[key].Severity, " (**%s**)"), markdownErrorSuffix(dr[key].Err)))
+			}
+			result.WriteString("\n")
 		}
 	}
 
-	// Add diff content with markdown formatting
-	for key, diffResult := range dr {
-		if diffResult.Diff != "" {
-			// Extract the original diff content without the header
-			lines := strings.Split(diffResult.Diff, "\n")
-			var diffLines []string
-			headerFound := false
-			for _, line := range lines {
-				if strings.HasPrefix(line, "===== ") && strings.HasSuffix(line, " ======") {
-					headerFound = true
-					continue
-				}
-				if headerFound {
-					diffLines = append(diffLines, line)
-				}
-			}
+	// Use filtering methods to organize resources by change type
+	writeSection("Created Resources", dr.FilterCreated().GetResourceKeys())
+	writeSection("Changed Resources", dr.FilterSubstantial().FilterChanged().FilterNotRestartOnly().GetResourceKeys())
+	writeSection("Restart-Only Changes (Config Checksum)", dr.FilterSubstantial().FilterRestartOnly().GetResourceKeys())
+	writeSection("Trivial Changes", dr.FilterTrivial().GetResourceKeys())
+	writeSection("Deleted Resources", dr.FilterDeleted().FilterNotPruned().GetResourceKeys())
+	writeSection("Resources That Would Be Pruned", dr.FilterWouldPrune().GetResourceKeys())
+	writeSection("Errors", dr.FilterErrors().GetResourceKeys())
+	writeSection("Unchanged Resources", dr.FilterUnchanged().listed().GetResourceKeys())
+}
 
-			// Format resource header in markdown
-			if key.Namespace != "" {
-				result.WriteString(fmt.Sprintf("### %s/%s %s/%s\n", key.Group, key.Kind, key.Namespace, key.Name))
-			} else {
-				result.WriteString(fmt.Sprintf("### %s/%s %s\n", key.Group, key.Kind, key.Name))
-			}
+// writeDiffBodiesMarkdown writes the diff text of all non-trivial results as Markdown code blocks
+func (dr Results) writeDiffBodiesMarkdown(result *strings.Builder) {
+	keys := dr.GetResourceKeys()
+	sortResourceKeys(keys)
+	for _, key := range keys {
+		result.WriteString(markdownDiffBody(key, dr[key]))
+	}
+}
 
-			// Add diff content in code block
-			result.WriteString("```diff\n")
-			result.WriteString(strings.Join(diffLines, "\n"))
-			result.WriteString("\n```\n\n")
+// markdownDiffBody returns the diff text of a non-trivial result as a Markdown section with a code block,
+// or "" if there is nothing to show
+func markdownDiffBody(key ResourceKey, diffResult Result) string {
+	if diffResult.Diff == "" || diffResult.Trivial {
+		return ""
+	}
+	// Extract the original diff content without the header
+	lines := strings.Split(diffResult.Diff, "\n")
+	var diffLines []string
+	headerFound := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "===== ") && strings.HasSuffix(line, " ======") {
+			headerFound = true
+			continue
+		}
+		if headerFound {
+			diffLines = append(diffLines, line)
 		}
 	}
-	return strings.TrimRight(result.String(), "\n")
+
+	var result strings.Builder
+	// Format resource header in markdown, with an anchor linked to by writeContentsMarkdown
+	result.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n", key.Anchor()))
+	if key.Namespace != "" {
+		result.WriteString(fmt.Sprintf("### %s/%s %s/%s\n", markdownText(key.Group), markdownText(key.Kind), markdownText(key.Namespace), markdownText(key.Name)))
+	} else {
+		result.WriteString(fmt.Sprintf("### %s/%s %s\n", markdownText(key.Group), markdownText(key.Kind), markdownText(key.Name)))
+	}
+
+	// Add diff content in a code block whose fence the content cannot close
+	fence := markdownFence(diffResult.Diff)
+	result.WriteString(fence + "diff\n")
+	result.WriteString(strings.Join(diffLines, "\n"))
+	result.WriteString("\n" + fence + "\n\n")
+	return result.String()
 }
 
 // FilterByType returns a new Results containing only resources with the specified change type
@@ -308,11 +413,48 @@ func (dr Results) FilterDeleted() Results {
 	return dr.FilterByType(Deleted)
 }
 
+// FilterErrors returns a new Results containing only resources that failed to diff
+func (dr Results) FilterErrors() Results {
+	return dr.FilterByType(Error)
+}
+
 // FilterUnchanged returns a new Results containing only unchanged resources
 func (dr Results) FilterUnchanged() Results {
 	return dr.FilterByType(Unchanged)
 }
 
+// UnlistUnchanged returns a copy of the results in which unchanged resources are marked Unlisted, so that
+// summaries count them in their statistics without listing them
+func (dr Results) UnlistUnchanged() Results {
+	unlisted := make(Results, len(dr))
+	for key, diffResult := range dr {
+		diffResult.Unlisted = diffResult.Type == Unchanged
+		unlisted[key] = diffResult
+	}
+	return unlisted
+}
+
+// listed returns the results that summaries list, i.e. without those marked Unlisted
+func (dr Results) listed() Results {
+	return dr.Apply(func(_ ResourceKey, diffResult Result) bool {
+		return !diffResult.Unlisted
+	})
+}
+
+// FilterTrivial returns a new Results containing only changes below Options.MinimumChangedLines
+func (dr Results) FilterTrivial() Results {
+	return dr.Apply(func(_ ResourceKey, diffResult Result) bool {
+		return diffResult.Trivial
+	})
+}
+
+// FilterSubstantial returns a new Results excluding changes below Options.MinimumChangedLines
+func (dr Results) FilterSubstantial() Results {
+	return dr.Apply(func(_ ResourceKey, diffResult Result) bool {
+		return !diffResult.Trivial
+	})
+}
+
 // FilterByKind returns a new Results containing only resources with the specified kind
 func (dr Results) FilterByKind(kind string) Results {
 	result := make(Results)
@@ -367,6 +509,18 @@ func (dr Results) HasChanges() bool {
 	return false
 }
 
+// PotentialSecrets scans rendered diffs for strings that look like secrets or keys and returns the findings by resource.
+// It is meant as a last line of defense against leaking values when masking is disabled or does not cover a resource.
+func (dr Results) PotentialSecrets() map[ResourceKey][]masking.Finding {
+	found := make(map[ResourceKey][]masking.Finding)
+	for key, diffResult := range dr {
+		if findings := masking.FindPotentialSecrets(diffResult.Diff); len(findings) > 0 {
+			found[key] = findings
+		}
+	}
+	return found
+}
+
 // IsEmpty returns true if the Results contains no resources
 func (dr Results) IsEmpty() bool {
 	return len(dr) == 0
@@ -424,17 +578,89 @@ func (dr Results) GetStatistics() Statistics {
 			stats.Deleted++
 		case Unchanged:
 			stats.Unchanged++
+		case Error:
+			stats.Errors++
 		}
 	}
 
 	return stats
 }
 
+// KeyFunc derives the identity used to pair base and head resources.
+// Resources with equal keys are compared with each other.
+type KeyFunc func(*unstructured.Unstructured) ResourceKey
+
+// ResultFunc receives the result of a resource as soon as it is computed, before the remaining resources are diffed.
+// Returning an error stops the diff with that error.
+type ResultFunc func(ResourceKey, Result) error
+
+// MaskScope controls across which resources identical secret values receive identical masks
+type MaskScope string
+
+const (
+	// MaskScopeGlobal shares masks across all operations in the process (default)
+	MaskScopeGlobal MaskScope = "global"
+	// MaskScopeOperation shares masks only within a single diff operation
+	MaskScopeOperation MaskScope = "operation"
+	// MaskScopeResource shares masks only between the base and head of the same resource,
+	// so identical values in different resources cannot be correlated
+	MaskScopeResource MaskScope = "resource"
+)
+
+// DiffStyle selects how the diff of a changed resource is rendered
+type DiffStyle string
+
+const (
+	// DiffStyleUnified renders a unified diff of the YAML (default)
+	DiffStyleUnified DiffStyle = "unified"
+	// DiffStyleInline renders the head YAML with "# was: <old value>" comments on changed lines
+	DiffStyleInline DiffStyle = "inline"
+	// DiffStyleReport renders a structural report listing each changed path with its old and new values
+	DiffStyleReport DiffStyle = "report"
+)
+
 // Options controls the diff behavior with filtering and masking options
 type Options struct {
-	FilterOption          *filter.Option // Filtering options
-	Context               int            // Number of context lines in diff output
-	DisableMaskingSecrets bool           // Disable masking of secret values (default: false)
+	FilterOption           *filter.Option      // Filtering options
+	Context                int                 // Number of context lines in diff output
+	DisableMaskingSecrets  bool                // Disable masking of secret values (default: false)
+	DisableMaskingFor      []string            // Glob patterns ("Kind/namespace/name") selecting resources whose secret values are shown unmasked (none when empty)
+	SecretPolicies         masking.PolicyTable // Masking policies by Secret type, e.g. certificate metadata for kubernetes.io/tls (every value masked when nil)
+	CacheDir               string              // Directory to cache rendered diffs between runs (disabled when empty)
+	MinimumChangedLines    int                 // Changes with fewer changed lines are marked Trivial (disabled when 0)
+	MaskScope              MaskScope           // Scope within which secret masks are consistent (default: global)
+	MaskStrategy           masking.Strategy    // How masked values are rendered (default: incremental)
+	MaskKey                string              // Secret key of masks rendered by the hash strategy, making them reproducible across runs without revealing guessable values (default: empty)
+	MaskToken              string              // Token repeated by masks of the incremental strategy, e.g. "*" where runs of "+" are misread as diff markers (default: "+")
+	MaskMinLength          int                 // Length of the first mask of the incremental strategy, growing by one token for each further value (default: 16 when 0)
+	MaskingAudit           io.Writer           // Receives JSON lines describing each masked value by hash (disabled when nil)
+	KeyFunc                KeyFunc             // Derives resource identity for pairing (default: DefaultKeyFunc)
+	Only                   []string            // Glob patterns ("Kind/namespace/name") selecting the only resources to diff (all when empty)
+	Severity               *SeverityPolicy     // Assigns severities to results (disabled when nil)
+	Owners                 *OwnershipPolicy    // Assigns owning teams to results (disabled when nil)
+	DiffStyle              DiffStyle           // How changed resources are rendered; created and deleted resources are always unified (default: unified)
+	UseLastApplied         bool                // Compare head against the last-applied configuration recorded on base objects, e.g. a live export (default: false)
+	FieldManager           string              // Restrict comparison to fields of live base objects owned by this manager in managedFields (disabled when empty)
+	ExpandBelowLines       int                 // Changed resources whose YAML has fewer lines are shown in full instead of as hunks (disabled when 0)
+	FullObjects            FullObjectsMode     // Render the complete base and head YAML of changed resources after or instead of the diff (default: none)
+	UnnamedMatching        UnnamedMatching     // How objects without a name sharing a generateName are paired (default: index)
+	RenameThreshold        float64             // Pair deleted and created resources of the same kind at least this similar (0-1) as renames (disabled when 0)
+	Conversions            *ConversionPolicy   // Converts custom resources to a common version before comparison (disabled when nil)
+	KindNormalizers        KindNormalizers     // Normalize equivalent or server-assigned fields by kind before comparison, e.g. DefaultKindNormalizers() (disabled when nil)
+	NameMappings           []NameMapping       // Rewrite the names of base and head resources in order before pairing, e.g. to strip a kustomize namePrefix (none when empty)
+	IgnoreEOL              bool                // Treat CRLF and LF line endings in string values as equal (default: false)
+	IgnoreValueRegexes     []string            // Treat base and head string values at the same path matching the same regular expression as equal, e.g. generated timestamps (none when empty)
+	SummarizeStatus        bool                // Compare resources without their status, summarizing condition transitions instead, e.g. "Ready: True -> False" (default: false)
+	SummarizeSealedSecrets bool                // Compare SealedSecrets without their encrypted values, reporting only which keys were added, removed or changed (default: false)
+	Workloads              *workload.Policy    // Workload kinds whose container images are compared, e.g. Argo Rollouts (built-in kinds when nil)
+	OnResult               ResultFunc          // Receives each result as it is computed, in resource key order, e.g. to stream results (disabled when nil)
+	ContinueOnError        bool                // Record resources that fail to diff or mask, e.g. a malformed Secret, as Error results instead of aborting (default: false)
+	PreTransform           []Transform         // Applied in order to base and head objects before conversion, name mapping, filtering and pairing (none when empty)
+	ClassifyOnly           bool                // Classify resources by change type without marshaling them or generating diff text, leaving Result.Diff empty (default: false)
+	FailFast               bool                // Stop at the first created, changed or deleted resource in key order, implying ClassifyOnly (default: false)
+	HeadSources            parser.SourceMap    // Locations of head resources in their manifest files, annotating results with the lines of changed fields (disabled when nil)
+	PruneTracking          map[string][]string // Tracking labels or annotations of a GitOps application and their values, e.g. {"app.kubernetes.io/instance": {"web"}}; deleted base resources matching any key and one of its values are marked WouldPrune (disabled when empty)
+	RetainObjects          bool                // Retain the compared base and head objects, masked and normalized, in Result.Base and Result.Head for custom analysis (default: false)
 }
 
 // DefaultOptions returns the default diff options
@@ -443,5 +669,11 @@ func DefaultOptions() *Options {
 		FilterOption:          filter.DefaultOption(),
 		Context:               3,
 		DisableMaskingSecrets: false,
+		CacheDir:              "",
+		MinimumChangedLines:   0,
+		MaskScope:             MaskScopeGlobal,
+		MaskStrategy:          masking.StrategyIncremental,
+		DiffStyle:             DiffStyleUnified,
+		UnnamedMatching:       UnnamedMatchingIndex,
 	}
 }
@@ -337,6 +337,11 @@ func TestResults_StringSummary(t *testing.T) {
 		ResourceKey{Kind: "Secret", Namespace: "default", Name: "secret1"}: {Type: Unchanged, Diff: ""},
 	}
 
+	trivialResults := Results{
+		ResourceKey{Kind: "Deployment", Namespace: "default", Name: "app1"}: {Type: Changed, Diff: "diff1"},
+		ResourceKey{Kind: "Service", Namespace: "default", Name: "svc1"}:    {Type: Changed, Diff: "diff2", Trivial: true},
+	}
+
 	emptyResults := Results{}
 
 	tests := []struct {
@@ -346,6 +351,19 @@ func TestResults_StringSummary(t *testing.T) {
 		shouldNotContain []string
 		expectEmpty      bool
 	}{
+		{
+			name:    "trivial changes summary",
+			results: trivialResults,
+			shouldContain: []string{
+				"Changed (1):", "Trivial (1):",
+				"Deployment/default/app1",
+				"Service/default/svc1",
+			},
+			shouldNotContain: []string{
+				"Changed (2):",
+			},
+			expectEmpty: false,
+		},
 		{
 			name:    "mixed results summary",
 			results: results,
@@ -372,6 +390,18 @@ func TestResults_StringSummary(t *testing.T) {
 			},
 			expectEmpty: false,
 		},
+		{
+			name:    "unlisted unchanged resources are counted",
+			results: results.UnlistUnchanged(),
+			shouldContain: []string{
+				"# Summary: 5 total, 2 changed, 1 created, 1 deleted, 1 unchanged",
+				"Changed (2):",
+			},
+			shouldNotContain: []string{
+				"Unchanged (1):", "Secret/default/secret1",
+			},
+			expectEmpty: false,
+		},
 		{
 			name:        "empty results summary",
 			results:     emptyResults,
@@ -560,3 +590,33 @@ func TestResults_StringDiffMarkdown(t *testing.T) {
 		})
 	}
 }
+
+func TestResults_TrivialChanges(t *testing.T) {
+	results := Results{
+		ResourceKey{Kind: "Deployment", Namespace: "default", Name: "app1"}: {Type: Changed, Diff: "===== apps/Deployment default/app1 ======\nsubstantial diff\n"},
+		ResourceKey{Kind: "Service", Namespace: "default", Name: "svc1"}:    {Type: Changed, Diff: "===== /Service default/svc1 ======\ntrivial diff\n", Trivial: true},
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		assert.Equal(t, 1, results.FilterTrivial().Count())
+		assert.Contains(t, results.FilterTrivial(), ResourceKey{Kind: "Service", Namespace: "default", Name: "svc1"})
+		assert.Equal(t, 1, results.FilterSubstantial().Count())
+		assert.Contains(t, results.FilterSubstantial(), ResourceKey{Kind: "Deployment", Namespace: "default", Name: "app1"})
+		assert.Equal(t, 2, results.GetStatistics().Changed, "Trivial changes are still counted as changed")
+	})
+
+	t.Run("StringDiff folds trivial changes", func(t *testing.T) {
+		output := results.StringDiff()
+		assert.Contains(t, output, "substantial diff")
+		assert.NotContains(t, output, "trivial diff")
+		assert.Contains(t, output, "# Trivial changes (1):\n#   Service/default/svc1\n")
+	})
+
+	t.Run("StringDiffMarkdown folds trivial changes", func(t *testing.T) {
+		output := results.StringDiffMarkdown()
+		assert.Contains(t, output, "substantial diff")
+		assert.NotContains(t, output, "trivial diff")
+		assert.Contains(t, output, "## Trivial Changes (1)")
+		assert.Contains(t, output, "## Changed Resources (1)")
+	})
+}
@@ -0,0 +1,144 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// UnnamedMatching determines how objects without metadata.name, e.g. those using generateName,
+// are paired when several of them share a key
+type UnnamedMatching string
+
+const (
+	// UnnamedMatchingIndex pairs the n-th unnamed base object with the n-th unnamed head object of the same key (default)
+	UnnamedMatchingIndex UnnamedMatching = "index"
+	// UnnamedMatchingSimilarity pairs unnamed objects of the same key by the similarity of their YAML
+	UnnamedMatchingSimilarity UnnamedMatching = "similarity"
+	// UnnamedMatchingNone never pairs unnamed objects, reporting every base object as deleted and every head object as created
+	UnnamedMatchingNone UnnamedMatching = "unmatched"
+)
+
+// minUnnamedSimilarity is the similarity ratio below which unnamed objects are not paired
+const minUnnamedSimilarity = 0.5
+
+// validate returns an error if the mode is not supported
+func (m UnnamedMatching) validate() error {
+	switch m {
+	case "", UnnamedMatchingIndex, UnnamedMatchingSimilarity, UnnamedMatchingNone:
+		return nil
+	default:
+		return fmt.Errorf("invalid unnamed matching: %s (supported: %s, %s, %s)",
+			m, UnnamedMatchingIndex, UnnamedMatchingSimilarity, UnnamedMatchingNone)
+	}
+}
+
+// pairUnnamed adds the unnamed base and head objects sharing key to objMap.
+// Unless a single object on each side is paired, keys are made unique by appending "#<n>" to the name.
+func pairUnnamed(objMap map[ResourceKey]objBaseHead, key ResourceKey, base, head []*unstructured.Unstructured, matching UnnamedMatching) {
+	var pairs []objBaseHead
+	switch matching {
+	case UnnamedMatchingNone:
+		for _, obj := range base {
+			pairs = append(pairs, objBaseHead{base: obj})
+		}
+		for _, obj := range head {
+			pairs = append(pairs, objBaseHead{head: obj})
+		}
+	case UnnamedMatchingSimilarity:
+		pairs = pairBySimilarity(base, head, minUnnamedSimilarity)
+	default:
+		pairs = pairByIndex(base, head)
+	}
+
+	if len(pairs) == 1 {
+		objMap[key] = pairs[0]
+		return
+	}
+	for i, pair := range pairs {
+		numbered := key
+		numbered.Name = fmt.Sprintf("%s#%d", key.Name, i+1)
+		objMap[numbered] = pair
+	}
+}
+
+// pairByIndex pairs base and head objects by their position
+func pairByIndex(base, head []*unstructured.Unstructured) []objBaseHead {
+	pairs := make([]objBaseHead, max(len(base), len(head)))
+	for i, obj := range base {
+		pairs[i].base = obj
+	}
+	for i, obj := range head {
+		pairs[i].head = obj
+	}
+	return pairs
+}
+
+// pairBySimilarity greedily pairs the most similar base and head objects, leaving objects unpaired
+// when no counterpart reaches threshold. Pairs follow the base order, then unpaired head objects.
+func pairBySimilarity(base, head []*unstructured.Unstructured, threshold float64) []objBaseHead {
+	type candidate struct {
+		baseIdx, headIdx int
+		ratio            float64
+	}
+	headTokens := make([][]string, len(head))
+	for j, obj := range head {
+		headTokens[j] = objectTokens(obj)
+	}
+	var candidates []candidate
+	for i, obj := range base {
+		baseTokens := objectTokens(obj)
+		for j := range head {
+			ratio := difflib.NewMatcherWithJunk(baseTokens, headTokens[j], false, nil).Ratio()
+			if ratio >= threshold {
+				candidates = append(candidates, candidate{baseIdx: i, headIdx: j, ratio: ratio})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].ratio > candidates[b].ratio })
+
+	headFor := make(map[int]int)
+	pairedHead := make(map[int]bool)
+	for _, c := range candidates {
+		if _, ok := headFor[c.baseIdx]; ok || pairedHead[c.headIdx] {
+			continue
+		}
+		headFor[c.baseIdx] = c.headIdx
+		pairedHead[c.headIdx] = true
+	}
+
+	pairs := make([]objBaseHead, 0, len(base)+len(head))
+	for i, obj := range base {
+		pair := objBaseHead{base: obj}
+		if j, ok := headFor[i]; ok {
+			pair.head = head[j]
+		}
+		pairs = append(pairs, pair)
+	}
+	for j, obj := range head {
+		if !pairedHead[j] {
+			pairs = append(pairs, objBaseHead{head: obj})
+		}
+	}
+	return pairs
+}
+
+// objectTokens returns the words of the YAML of obj without its name and namespace for similarity comparison.
+// Words rather than lines are compared so that partially changed values, e.g. image tags, still count as similar.
+func objectTokens(obj *unstructured.Unstructured) []string {
+	normalized := obj.DeepCopy()
+	normalized.SetName("")
+	normalized.SetNamespace("")
+	data, err := yaml.Marshal(normalized.Object)
+	if err != nil {
+		return nil
+	}
+	return strings.FieldsFunc(string(data), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
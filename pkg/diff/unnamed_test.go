@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYamlString_UnnamedMatching(t *testing.T) {
+	job := func(image string) string {
+		return `apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: migrate
+        image: ` + image + `
+        args: ["--step", "1"]
+        env:
+        - name: LOG_LEVEL
+          value: info
+      restartPolicy: Never
+`
+	}
+	baseYaml := job("db:1.0") + "---\n" + job("cache:1.0")
+	headYaml := job("cache:1.1") + "---\n" + job("db:1.1")
+
+	jobKey := func(name string) ResourceKey {
+		return ResourceKey{Group: "batch", Kind: "Job", Namespace: "default", Name: name}
+	}
+
+	tests := []struct {
+		name     string
+		matching UnnamedMatching
+		expected map[ResourceKey]ChangeType
+		contains map[ResourceKey][]string
+	}{
+		{
+			name:     "index",
+			matching: UnnamedMatchingIndex,
+			expected: map[ResourceKey]ChangeType{jobKey("migrate-#1"): Changed, jobKey("migrate-#2"): Changed},
+			contains: map[ResourceKey][]string{
+				jobKey("migrate-#1"): {"image: db:1.0", "image: cache:1.1"},
+			},
+		},
+		{
+			name:     "similarity",
+			matching: UnnamedMatchingSimilarity,
+			expected: map[ResourceKey]ChangeType{jobKey("migrate-#1"): Changed, jobKey("migrate-#2"): Changed},
+			contains: map[ResourceKey][]string{
+				jobKey("migrate-#1"): {"image: db:1.0", "image: db:1.1"},
+				jobKey("migrate-#2"): {"image: cache:1.0", "image: cache:1.1"},
+			},
+		},
+		{
+			name:     "unmatched",
+			matching: UnnamedMatchingNone,
+			expected: map[ResourceKey]ChangeType{
+				jobKey("migrate-#1"): Deleted, jobKey("migrate-#2"): Deleted,
+				jobKey("migrate-#3"): Created, jobKey("migrate-#4"): Created,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.UnnamedMatching = tt.matching
+			results, err := YamlString(baseYaml, headYaml, opts)
+			require.NoError(t, err)
+			require.Len(t, results, len(tt.expected))
+			for key, changeType := range tt.expected {
+				assert.Equal(t, changeType, results[key].Type, key.String())
+			}
+			for key, lines := range tt.contains {
+				for _, line := range lines {
+					assert.Contains(t, results[key].Diff, line)
+				}
+			}
+		})
+	}
+
+	t.Run("single unnamed object keeps its generateName", func(t *testing.T) {
+		results, err := YamlString(job("db:1.0"), job("db:1.1"), DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[jobKey("migrate-")].Type)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.UnnamedMatching = "name"
+		_, err := YamlString(baseYaml, headYaml, opts)
+		assert.ErrorContains(t, err, "invalid unnamed matching")
+	})
+}
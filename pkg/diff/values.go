@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// compileValueRegexes compiles the patterns of Options.IgnoreValueRegexes
+func compileValueRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore value regex %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// equalizeMatchingValues returns a copy of the head object of a resource in which every string value that
+// matches one of the patterns, as does the base value at the same path, is replaced by the base value, so that
+// generated values like timestamps, build IDs and checksums are compared as equal
+func equalizeMatchingValues(v objBaseHead, patterns []*regexp.Regexp) objBaseHead {
+	if v.base == nil || v.head == nil {
+		return v
+	}
+	head := v.head.DeepCopy()
+	head.Object = equalizeValue(v.base.Object, head.Object, patterns).(map[string]any)
+	v.head = head
+	return v
+}
+
+// equalizeValue returns head with the strings matching the same pattern as base at the same path replaced by
+// those of base, descending into maps and lists
+func equalizeValue(base, head any, patterns []*regexp.Regexp) any {
+	switch head := head.(type) {
+	case string:
+		base, ok := base.(string)
+		if !ok || base == head {
+			return head
+		}
+		for _, re := range patterns {
+			if re.MatchString(base) && re.MatchString(head) {
+				return base
+			}
+		}
+		return head
+	case map[string]any:
+		base, ok := base.(map[string]any)
+		if !ok {
+			return head
+		}
+		for k, v := range head {
+			if b, ok := base[k]; ok {
+				head[k] = equalizeValue(b, v, patterns)
+			}
+		}
+		return head
+	case []any:
+		base, ok := base.([]any)
+		if !ok {
+			return head
+		}
+		for i := range min(len(base), len(head)) {
+			head[i] = equalizeValue(base[i], head[i], patterns)
+		}
+		return head
+	default:
+		return head
+	}
+}
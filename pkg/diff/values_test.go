@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjects_IgnoreValueRegexes(t *testing.T) {
+	key := ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "settings"}
+	timestamp := `\d{4}-\d{2}-\d{2}T`
+	base := []*unstructured.Unstructured{newConfigMap("settings", "default", "2024-01-01T00:00:00Z")}
+	head := []*unstructured.Unstructured{newConfigMap("settings", "default", "2024-06-30T12:34:56Z")}
+
+	t.Run("disabled", func(t *testing.T) {
+		results, err := Objects(base, head, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+	})
+
+	t.Run("values matching the same pattern are equal", func(t *testing.T) {
+		results, err := Objects(base, head, DefaultOptions().WithIgnoreValueRegexes(`^build-\d+$`, timestamp))
+		require.NoError(t, err)
+		assert.Equal(t, Unchanged, results[key].Type)
+		assert.Equal(t, "2024-06-30T12:34:56Z", head[0].Object["data"].(map[string]any)["key"], "the input is not modified")
+	})
+
+	t.Run("values matching only on one side differ", func(t *testing.T) {
+		changed := []*unstructured.Unstructured{newConfigMap("settings", "default", "never")}
+		results, err := Objects(base, changed, DefaultOptions().WithIgnoreValueRegexes(timestamp))
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+		assert.Contains(t, results[key].Diff, "key: never")
+	})
+
+	t.Run("lists are compared by position", func(t *testing.T) {
+		withArgs := func(args ...any) []*unstructured.Unstructured {
+			obj := newConfigMap("settings", "default", "")
+			obj.Object["args"] = args
+			return []*unstructured.Unstructured{obj}
+		}
+		results, err := Objects(withArgs("--build=build-41", "--verbose"), withArgs("--build=build-42", "--quiet"), DefaultOptions().WithIgnoreValueRegexes(`^--build=build-\d+$`))
+		require.NoError(t, err)
+		assert.Equal(t, Changed, results[key].Type)
+		assert.NotContains(t, results[key].Diff, "build-42")
+		assert.Contains(t, results[key].Diff, "- --quiet")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := Objects(base, head, DefaultOptions().WithIgnoreValueRegexes("("))
+		assert.ErrorContains(t, err, `invalid ignore value regex "("`)
+	})
+}
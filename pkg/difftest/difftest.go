@@ -0,0 +1,115 @@
+// Package difftest compares diff results with golden files, so that projects embedding the library can
+// snapshot-test their diff pipelines. To write the golden files instead, define the -update flag in the test
+// package and run the tests with it:
+//
+//	var _ = flag.Bool(difftest.UpdateFlag, false, "write golden files")
+//
+//	func TestPipeline(t *testing.T) {
+//		results, err := diff.Objects(base, head, opts)
+//		require.NoError(t, err)
+//		difftest.AssertJSON(t, results, "testdata/pipeline.json")
+//		difftest.AssertMarkdown(t, results, "testdata/pipeline.md")
+//	}
+//
+//	go test ./... -update
+package difftest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+// UpdateFlag is the name of the test flag that makes the assertions write golden files instead of comparing.
+// The package does not register it, so that importing it adds no flag to binaries and does not conflict with a
+// test package defining -update; the test package defines it.
+const UpdateFlag = "update"
+
+// T is the part of testing.TB the assertions use
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Updating reports whether the tests run with -update. The flag is looked up when the assertions run, after the
+// test package has defined it, and is false if it is not defined.
+func Updating() bool {
+	f := flag.Lookup(UpdateFlag)
+	return f != nil && f.Value.String() == "true"
+}
+
+// AssertJSON asserts that results, written as JSON by diff.WriteResults, equal the golden file. Resources are
+// written sorted by Kind, Namespace and Name, so the file does not depend on the order of the inputs.
+func AssertJSON(t T, results diff.Results, golden string) bool {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := diff.WriteResults(&buf, results); err != nil {
+		t.Errorf("difftest: %v", err)
+		return false
+	}
+	return AssertGolden(t, buf.Bytes(), golden)
+}
+
+// AssertMarkdown asserts that the Markdown report of results, see diff.Results.StringDiffMarkdown, equals the
+// golden file
+func AssertMarkdown(t T, results diff.Results, golden string) bool {
+	t.Helper()
+	return AssertGolden(t, []byte(results.StringDiffMarkdown()), golden)
+}
+
+// AssertGolden asserts that actual equals the content of the golden file, reporting a unified diff if it does not.
+// With -update, the golden file and its directory are written instead.
+func AssertGolden(t T, actual []byte, golden string) bool {
+	t.Helper()
+	golden = filepath.Clean(golden)
+	if Updating() {
+		if err := writeGolden(golden, actual); err != nil {
+			t.Errorf("difftest: %v", err)
+			return false
+		}
+		return true
+	}
+
+	expected, err := os.ReadFile(golden) // #nosec G304 - golden file paths are given by the test
+	if errors.Is(err, os.ErrNotExist) {
+		t.Errorf("difftest: golden file %s does not exist; run the tests with -%s to create it", golden, UpdateFlag)
+		return false
+	}
+	if err != nil {
+		t.Errorf("difftest: failed to read golden file: %v", err)
+		return false
+	}
+	if bytes.Equal(expected, actual) {
+		return true
+	}
+
+	unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: golden,
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		unified = fmt.Sprintf("failed to diff: %v", err)
+	}
+	t.Errorf("difftest: output differs from golden file %s (run the tests with -%s to accept it):\n%s", golden, UpdateFlag, unified)
+	return false
+}
+
+// writeGolden writes the golden file, creating its directory
+func writeGolden(golden string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(golden), 0o750); err != nil {
+		return fmt.Errorf("failed to create golden file directory: %w", err)
+	}
+	if err := os.WriteFile(golden, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write golden file: %w", err)
+	}
+	return nil
+}
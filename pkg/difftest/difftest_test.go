@@ -0,0 +1,100 @@
+package difftest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+// The test package defines -update, as packages using the assertions do
+var _ = flag.Bool(UpdateFlag, false, "write golden files")
+
+// recorder is a T recording the reported errors
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func setUpdate(t *testing.T, update bool) {
+	t.Helper()
+	previous := Updating()
+	require.NoError(t, flag.Set(UpdateFlag, fmt.Sprint(update)))
+	t.Cleanup(func() { _ = flag.Set(UpdateFlag, fmt.Sprint(previous)) })
+}
+
+func testResults(t *testing.T) diff.Results {
+	t.Helper()
+	base := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: default\ndata:\n  key: v1\n"
+	head := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: default\ndata:\n  key: v2\n"
+	results, err := diff.YamlString(base, head, diff.NewOptions())
+	require.NoError(t, err)
+	return results
+}
+
+func TestAssertJSON(t *testing.T) {
+	results := testResults(t)
+	golden := filepath.Join(t.TempDir(), "testdata", "results.json")
+
+	t.Run("missing golden file", func(t *testing.T) {
+		setUpdate(t, false)
+		r := &recorder{}
+		assert.False(t, AssertJSON(r, results, golden))
+		require.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "run the tests with -update to create it")
+	})
+
+	t.Run("update writes the golden file", func(t *testing.T) {
+		setUpdate(t, true)
+		r := &recorder{}
+		assert.True(t, AssertJSON(r, results, golden))
+		assert.Empty(t, r.errors)
+		assert.FileExists(t, golden)
+	})
+
+	t.Run("matching results", func(t *testing.T) {
+		setUpdate(t, false)
+		r := &recorder{}
+		assert.True(t, AssertJSON(r, results, golden))
+		assert.Empty(t, r.errors)
+
+		read, err := os.Open(golden) // #nosec G304 - test file in a temp dir
+		require.NoError(t, err)
+		defer func() { _ = read.Close() }()
+		saved, err := diff.ReadResults(read)
+		require.NoError(t, err)
+		assert.Equal(t, results.GetResourceKeys(), saved.GetResourceKeys())
+	})
+
+	t.Run("differing results", func(t *testing.T) {
+		key := diff.ResourceKey{Kind: "ConfigMap", Namespace: "default", Name: "other"}
+		changed := diff.Results{key: {Type: diff.Created}}
+		setUpdate(t, false)
+		r := &recorder{}
+		assert.False(t, AssertJSON(r, changed, golden))
+		require.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "output differs from golden file")
+		assert.Contains(t, r.errors[0], `+      "name": "other",`)
+	})
+}
+
+func TestAssertMarkdown(t *testing.T) {
+	results := testResults(t)
+	golden := filepath.Join(t.TempDir(), "results.md")
+	setUpdate(t, true)
+	require.True(t, AssertMarkdown(t, results, golden))
+
+	data, err := os.ReadFile(golden) // #nosec G304 - test file in a temp dir
+	require.NoError(t, err)
+	assert.Equal(t, results.StringDiffMarkdown(), string(data))
+}
@@ -0,0 +1,259 @@
+// Package drift periodically compares live cluster state with desired manifests and reports when they diverge.
+package drift
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/snapshot"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Source loads a set of Kubernetes objects
+type Source func(ctx context.Context) ([]*unstructured.Unstructured, error)
+
+// FileSource returns a Source that reads a manifest file on every call
+func FileSource(file string) Source {
+	return func(_ context.Context) ([]*unstructured.Unstructured, error) {
+		f, err := os.Open(filepath.Clean(file)) // #nosec G304 - file path is provided by the caller and cleaned
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		defer func() { _ = f.Close() }()
+		return parseObjects(f, file)
+	}
+}
+
+// CommandSource returns a Source that runs a shell command, e.g. "kubectl get deploy -n app -o yaml",
+// and parses its standard output
+func CommandSource(command string) Source {
+	return func(ctx context.Context) ([]*unstructured.Unstructured, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", command) // #nosec G204 - the command is provided by the user running the daemon
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to run %q: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+		}
+		return parseObjects(&stdout, command)
+	}
+}
+
+// parseObjects parses manifests, expanding List objects such as the output of kubectl get -o yaml
+func parseObjects(r io.Reader, name string) ([]*unstructured.Unstructured, error) {
+	objs, err := parser.ParseYAML(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	var expanded []*unstructured.Unstructured
+	for _, obj := range objs {
+		items, isList := obj.Object["items"].([]any)
+		if !isList || !strings.HasSuffix(obj.GetKind(), "List") {
+			expanded = append(expanded, obj)
+			continue
+		}
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				expanded = append(expanded, &unstructured.Unstructured{Object: m})
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// EventType describes a change in drift state between two checks
+type EventType string
+
+const (
+	// EventDetected is emitted when resources start drifting
+	EventDetected EventType = "detected"
+	// EventResolved is emitted when all drifted resources match the desired state again
+	EventResolved EventType = "resolved"
+)
+
+// Event is a change in drift state reported to a Notifier
+type Event struct {
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	Resources []string  `json:"resources"` // Resources as "group/Kind/namespace/name"
+	Summary   string    `json:"summary"`
+}
+
+// Notifier delivers drift events
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Status is the outcome of a single drift check
+type Status struct {
+	Time    time.Time
+	Results diff.Results
+	Drifted []diff.ResourceKey // Resources that differ from the desired state, sorted
+}
+
+// PruneCandidates returns the drifted resources that are live but missing from the desired state, sorted.
+// Live state is the diff base, so they are the Deleted results.
+func (s Status) PruneCandidates() []diff.ResourceKey {
+	var candidates []diff.ResourceKey
+	for _, key := range s.Drifted {
+		if s.Results[key].Type == diff.Deleted {
+			candidates = append(candidates, key)
+		}
+	}
+	return candidates
+}
+
+// Monitor compares live state with desired manifests
+type Monitor struct {
+	Live     Source        // Current cluster state, used as diff base
+	Desired  Source        // Desired manifests, used as diff head
+	Options  *diff.Options // Diff options (diff.DefaultOptions when nil)
+	Notifier Notifier      // Receives events when drift appears or resolves (disabled when nil)
+	Metrics  *Metrics      // Records check outcomes (disabled when nil)
+
+	drifted []diff.ResourceKey
+}
+
+// Check compares live and desired state once and notifies when the set of drifted resources changes
+func (m *Monitor) Check(ctx context.Context) (Status, error) {
+	status, err := m.check(ctx)
+	if m.Metrics != nil {
+		m.Metrics.record(status, err)
+	}
+	if err != nil {
+		return status, err
+	}
+
+	event, changed := m.transition(status)
+	if changed && m.Notifier != nil {
+		if err := m.Notifier.Notify(ctx, event); err != nil {
+			// The state is kept so that the next check notifies the transition again
+			return status, fmt.Errorf("failed to send %s notification: %w", event.Type, err)
+		}
+	}
+	m.drifted = status.Drifted
+	return status, nil
+}
+
+// check computes the drift status without side effects
+func (m *Monitor) check(ctx context.Context) (Status, error) {
+	status := Status{Time: time.Now()}
+	live, err := m.Live(ctx)
+	if err != nil {
+		return status, fmt.Errorf("failed to load live state: %w", err)
+	}
+	desired, err := m.Desired(ctx)
+	if err != nil {
+		return status, fmt.Errorf("failed to load desired state: %w", err)
+	}
+	if live, err = normalizeLive(live, m.Options); err != nil {
+		return status, err
+	}
+	if status.Results, err = diff.Objects(live, desired, m.Options); err != nil {
+		return status, err
+	}
+	for key, result := range status.Results {
+		if result.Type != diff.Unchanged {
+			status.Drifted = append(status.Drifted, key)
+		}
+	}
+	sort.Slice(status.Drifted, func(i, j int) bool { return status.Drifted[i].String() < status.Drifted[j].String() })
+	return status, nil
+}
+
+// normalizeLive removes the fields the API server populates from live objects, as snapshots do, so that they
+// are not reported as drift. Managed fields and the last-applied configuration are kept when opts compares them.
+func normalizeLive(objs []*unstructured.Unstructured, opts *diff.Options) ([]*unstructured.Unstructured, error) {
+	if opts == nil {
+		opts = diff.DefaultOptions()
+	}
+	snapshotOpts := &snapshot.Options{
+		DisableMaskingSecrets: true,
+		KeepManagedFields:     opts.FieldManager != "",
+		KeepLastApplied:       opts.UseLastApplied,
+	}
+	normalized := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		n, err := snapshot.Normalize(obj, snapshotOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to normalize live state: %w", err)
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized, nil
+}
+
+// transition returns the event describing the change from the previous check, if any
+func (m *Monitor) transition(status Status) (Event, bool) {
+	switch {
+	case len(status.Drifted) > 0 && !sameKeys(m.drifted, status.Drifted):
+		return Event{
+			Type:      EventDetected,
+			Time:      status.Time,
+			Resources: keyStrings(status.Drifted),
+			Summary:   fmt.Sprintf("%d resources drifted from the desired state", len(status.Drifted)),
+		}, true
+	case len(status.Drifted) == 0 && len(m.drifted) > 0:
+		return Event{
+			Type:      EventResolved,
+			Time:      status.Time,
+			Resources: keyStrings(m.drifted),
+			Summary:   "All resources match the desired state",
+		}, true
+	default:
+		return Event{}, false
+	}
+}
+
+// Run checks for drift every interval until ctx is cancelled.
+// The outcome of each check, including errors, is passed to onCheck; errors do not stop the loop.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration, onCheck func(Status, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := m.Check(ctx)
+		if onCheck != nil {
+			onCheck(status, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sameKeys reports whether two sorted key lists are equal
+func sameKeys(a, b []diff.ResourceKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyStrings formats resource keys for events
+func keyStrings(keys []diff.ResourceKey) []string {
+	result := make([]string, len(keys))
+	for i, key := range keys {
+		result[i] = key.String()
+	}
+	return result
+}
@@ -0,0 +1,256 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const desiredConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: default
+data:
+  level: info
+`
+
+// staticSource returns a Source that parses the current value of *manifest on every call
+func staticSource(t *testing.T, manifest *string) Source {
+	return func(_ context.Context) ([]*unstructured.Unstructured, error) {
+		objs, err := parser.ParseYAML(strings.NewReader(*manifest))
+		require.NoError(t, err)
+		return objs, nil
+	}
+}
+
+type recordingNotifier struct {
+	events []Event
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestMonitor_Check(t *testing.T) {
+	live := desiredConfigMap
+	desired := desiredConfigMap
+	notifier := &recordingNotifier{}
+	metrics := NewMetrics()
+	monitor := &Monitor{
+		Live:     staticSource(t, &live),
+		Desired:  staticSource(t, &desired),
+		Notifier: notifier,
+		Metrics:  metrics,
+	}
+	ctx := context.Background()
+
+	status, err := monitor.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, status.Drifted)
+	assert.Empty(t, notifier.events, "no event without drift")
+
+	live = strings.Replace(desiredConfigMap, "info", "debug", 1)
+	status, err = monitor.Check(ctx)
+	require.NoError(t, err)
+	assert.Len(t, status.Drifted, 1)
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, EventDetected, notifier.events[0].Type)
+	assert.Equal(t, []string{"/ConfigMap/default/app"}, notifier.events[0].Resources)
+
+	_, err = monitor.Check(ctx)
+	require.NoError(t, err)
+	assert.Len(t, notifier.events, 1, "unchanged drift is not notified again")
+
+	live = desiredConfigMap
+	_, err = monitor.Check(ctx)
+	require.NoError(t, err)
+	require.Len(t, notifier.events, 2)
+	assert.Equal(t, EventResolved, notifier.events[1].Type)
+	assert.Equal(t, []string{"/ConfigMap/default/app"}, notifier.events[1].Resources)
+
+	var buf bytes.Buffer
+	_, err = metrics.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "k8s_manifest_diff_drift_checks_total 4\n")
+	assert.Contains(t, buf.String(), "k8s_manifest_diff_drifted_resources 0\n")
+	assert.Contains(t, buf.String(), "# TYPE k8s_manifest_diff_drift_check_errors_total counter\n")
+}
+
+// kubectlExport is the desired ConfigMap as exported with kubectl get -o yaml, with the fields the API server
+// and controllers populate
+const kubectlExport = `apiVersion: v1
+items:
+- apiVersion: v1
+  data:
+    level: info
+  kind: ConfigMap
+  metadata:
+    annotations:
+      kubectl.kubernetes.io/last-applied-configuration: |
+        {"apiVersion":"v1","data":{"level":"info"},"kind":"ConfigMap","metadata":{"name":"app","namespace":"default"}}
+    creationTimestamp: "2026-05-04T08:15:30Z"
+    managedFields:
+    - apiVersion: v1
+      fieldsType: FieldsV1
+      fieldsV1:
+        f:data:
+          .: {}
+          f:level: {}
+      manager: kubectl-client-side-apply
+      operation: Update
+      time: "2026-05-04T08:15:30Z"
+    name: app
+    namespace: default
+    resourceVersion: "48213"
+    uid: 3f6c2a9e-8d41-4b7a-9c55-1e0f2d3b4a6c
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    annotations:
+      deployment.kubernetes.io/revision: "4"
+    creationTimestamp: "2026-05-04T08:15:31Z"
+    generation: 4
+    name: web
+    namespace: default
+    resourceVersion: "48377"
+    uid: 9a1b7c3d-2e4f-4a6b-8c0d-5e7f9a1b3c5d
+  spec:
+    replicas: 2
+  status:
+    availableReplicas: 2
+    observedGeneration: 4
+    readyReplicas: 2
+kind: List
+metadata:
+  resourceVersion: ""
+`
+
+func TestMonitor_CheckIgnoresServerFields(t *testing.T) {
+	desired := desiredConfigMap + `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 2
+`
+	monitor := &Monitor{
+		Live:    CommandSource("cat <<'EOF'\n" + kubectlExport + "EOF"),
+		Desired: staticSource(t, &desired),
+	}
+	status, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, status.Drifted)
+
+	monitor.Options = diff.DefaultOptions().WithUseLastApplied(true)
+	status, err = monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, status.Drifted, "the last-applied configuration is kept when it is compared")
+}
+
+type failingNotifier struct {
+	fail   bool
+	events []Event
+}
+
+func (n *failingNotifier) Notify(_ context.Context, event Event) error {
+	if n.fail {
+		return errors.New("webhook unavailable")
+	}
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestMonitor_CheckRetriesFailedNotification(t *testing.T) {
+	live := strings.Replace(desiredConfigMap, "info", "debug", 1)
+	desired := desiredConfigMap
+	notifier := &failingNotifier{fail: true}
+	monitor := &Monitor{Live: staticSource(t, &live), Desired: staticSource(t, &desired), Notifier: notifier}
+
+	_, err := monitor.Check(context.Background())
+	assert.ErrorContains(t, err, "failed to send detected notification")
+
+	notifier.fail = false
+	_, err = monitor.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, notifier.events, 1, "the detection is notified once the notifier recovers")
+	assert.Equal(t, EventDetected, notifier.events[0].Type)
+}
+
+func TestStatus_PruneCandidates(t *testing.T) {
+	live := desiredConfigMap + "---\n" + strings.Replace(desiredConfigMap, "name: app", "name: orphan", 1)
+	desired := strings.Replace(desiredConfigMap, "info", "debug", 1)
+	monitor := &Monitor{Live: staticSource(t, &live), Desired: staticSource(t, &desired)}
+
+	status, err := monitor.Check(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, status.Drifted, 2)
+	assert.Equal(t, []diff.ResourceKey{{Kind: "ConfigMap", Namespace: "default", Name: "orphan"}}, status.PruneCandidates())
+}
+
+func TestMonitor_CheckError(t *testing.T) {
+	metrics := NewMetrics()
+	monitor := &Monitor{
+		Live: func(context.Context) ([]*unstructured.Unstructured, error) {
+			return nil, errors.New("connection refused")
+		},
+		Desired: func(context.Context) ([]*unstructured.Unstructured, error) { return nil, nil },
+		Metrics: metrics,
+	}
+	_, err := monitor.Check(context.Background())
+	assert.ErrorContains(t, err, "failed to load live state")
+
+	var buf bytes.Buffer
+	_, err = metrics.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "k8s_manifest_diff_drift_check_errors_total 1\n")
+}
+
+func TestCommandSource_ExpandsLists(t *testing.T) {
+	source := CommandSource(`printf 'apiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: a\n- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: b\n'`)
+	objs, err := source(context.Background())
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	assert.Equal(t, "a", objs[0].GetName())
+	assert.Equal(t, "b", objs[1].GetName())
+
+	_, err = CommandSource("exit 3")(context.Background())
+	assert.ErrorContains(t, err, "failed to run")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{URL: server.URL}
+	event := Event{Type: EventDetected, Resources: []string{"apps/Deployment/default/web"}, Summary: "1 resources drifted from the desired state"}
+	require.NoError(t, notifier.Notify(context.Background(), event))
+	assert.Equal(t, "detected", received["type"])
+	assert.Equal(t, "Drift detected: 1 resources drifted from the desired state\n- apps/Deployment/default/web", received["text"])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	err := (&WebhookNotifier{URL: failing.URL}).Notify(context.Background(), event)
+	assert.ErrorContains(t, err, "webhook returned status")
+}
@@ -0,0 +1,140 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Kubectl lists live resources with "kubectl get", connecting to the cluster like kubectl does: with the
+// kubeconfig from Kubeconfig, $KUBECONFIG or ~/.kube/config, or with the in-cluster service account config
+// when running in a pod without one. Client-side rate limiting is left to kubectl.
+type Kubectl struct {
+	Command        string        // kubectl executable (default: "kubectl" on PATH)
+	Resources      []string      // Resource types to list, e.g. "deploy", "svc" or "widgets.example.com"
+	Namespace      string        // Namespace to list resources in (default: the namespace of the context)
+	AllNamespaces  bool          // List resources in all namespaces, ignoring Namespace
+	Selector       string        // Label selector passed to the API server, e.g. "app=web" (all when empty)
+	Kubeconfig     string        // Path to the kubeconfig file (default: kubectl's resolution)
+	Context        string        // kubeconfig context to use (default: the current context)
+	As             string        // User to impersonate (disabled when empty)
+	AsGroups       []string      // Groups to impersonate, requiring As
+	ChunkSize      int64         // Objects fetched per list request for pagination (default: kubectl's 500)
+	RequestTimeout time.Duration // Time to wait for a single request to the API server (default: no timeout)
+}
+
+// Args returns the arguments of the kubectl get command printing the resources as YAML
+func (k Kubectl) Args() []string {
+	args := []string{"get", strings.Join(k.Resources, ","), "--output", "yaml"}
+	switch {
+	case k.AllNamespaces:
+		args = append(args, "--all-namespaces")
+	case k.Namespace != "":
+		args = append(args, "--namespace", k.Namespace)
+	}
+	if k.Selector != "" {
+		args = append(args, "--selector", k.Selector)
+	}
+	if k.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", k.Kubeconfig)
+	}
+	if k.Context != "" {
+		args = append(args, "--context", k.Context)
+	}
+	if k.As != "" {
+		args = append(args, "--as", k.As)
+	}
+	for _, group := range k.AsGroups {
+		args = append(args, "--as-group", group)
+	}
+	if k.ChunkSize > 0 {
+		args = append(args, "--chunk-size", strconv.FormatInt(k.ChunkSize, 10))
+	}
+	if k.RequestTimeout > 0 {
+		args = append(args, "--request-timeout", k.RequestTimeout.String())
+	}
+	return args
+}
+
+// Validate checks that impersonated groups have an impersonated user and the chunk size is not negative
+func (k Kubectl) Validate() error {
+	if len(k.AsGroups) > 0 && k.As == "" {
+		return fmt.Errorf("impersonating groups requires a user to impersonate")
+	}
+	if k.ChunkSize < 0 {
+		return fmt.Errorf("chunk size must not be negative, got %d", k.ChunkSize)
+	}
+	return nil
+}
+
+// Source returns a Source that runs kubectl get on every call and parses the listed resources.
+// kubectl is run directly rather than through a shell, so resource names and selectors need no quoting.
+func (k Kubectl) Source() Source {
+	return func(ctx context.Context) ([]*unstructured.Unstructured, error) {
+		if len(k.Resources) == 0 {
+			return nil, fmt.Errorf("no resource types to list")
+		}
+		command := k.Command
+		if command == "" {
+			command = "kubectl"
+		}
+		args := k.Args()
+		name := command + " " + strings.Join(args, " ")
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, command, args...) // #nosec G204 - the arguments are provided by the user running the command
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to run %q: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+		}
+		return parseObjects(&stdout, name)
+	}
+}
+
+// DiscoverSource returns a Source that lists the kinds of the desired objects with kubectl get on every call,
+// in addition to k.Resources. Live resources of those kinds that are missing from the desired objects are
+// then found and reported as Deleted, i.e. as prune candidates; k.Selector and k.Namespace should narrow the
+// listing to the resources the manifests manage.
+func (k Kubectl) DiscoverSource(desired Source) Source {
+	return func(ctx context.Context) ([]*unstructured.Unstructured, error) {
+		objs, err := desired(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover kinds: %w", err)
+		}
+		discovered := k
+		discovered.Resources = append(append([]string{}, k.Resources...), ResourceTypes(objs)...)
+		return discovered.Source()(ctx)
+	}
+}
+
+// ResourceTypes returns the distinct kinds of objs as kubectl resource types, sorted: "Kind.group" for kinds of
+// API groups, e.g. "Deployment.apps", and "Kind" for core kinds. Versions are left out, so that resources are
+// listed at the version preferred by the API server.
+func ResourceTypes(objs []*unstructured.Unstructured) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Kind == "" {
+			continue
+		}
+		resourceType := gvk.Kind
+		if gvk.Group != "" {
+			resourceType += "." + gvk.Group
+		}
+		if !seen[resourceType] {
+			seen[resourceType] = true
+			types = append(types, resourceType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
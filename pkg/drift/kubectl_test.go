@@ -0,0 +1,106 @@
+package drift
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestKubectl_Args(t *testing.T) {
+	k := Kubectl{
+		Resources:      []string{"deploy", "svc"},
+		Namespace:      "app",
+		Selector:       "tier=web",
+		Kubeconfig:     "/etc/kube/config",
+		Context:        "prod",
+		As:             "auditor",
+		AsGroups:       []string{"viewers", "auditors"},
+		ChunkSize:      100,
+		RequestTimeout: 30 * time.Second,
+	}
+	assert.Equal(t, []string{
+		"get", "deploy,svc", "--output", "yaml",
+		"--namespace", "app",
+		"--selector", "tier=web",
+		"--kubeconfig", "/etc/kube/config",
+		"--context", "prod",
+		"--as", "auditor",
+		"--as-group", "viewers", "--as-group", "auditors",
+		"--chunk-size", "100",
+		"--request-timeout", "30s",
+	}, k.Args())
+
+	k = Kubectl{Resources: []string{"deploy"}, Namespace: "app", AllNamespaces: true}
+	assert.Equal(t, []string{"get", "deploy", "--output", "yaml", "--all-namespaces"}, k.Args())
+}
+
+func TestKubectl_Validate(t *testing.T) {
+	assert.NoError(t, Kubectl{Resources: []string{"deploy"}}.Validate())
+	assert.ErrorContains(t, Kubectl{Resources: []string{"deploy"}, AsGroups: []string{"viewers"}}.Validate(), "requires a user to impersonate")
+	assert.ErrorContains(t, Kubectl{Resources: []string{"deploy"}, ChunkSize: -1}.Validate(), "must not be negative")
+}
+
+func TestKubectl_Source(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	// The fake kubectl prints a List whose ConfigMap is named after its arguments
+	kubectl := filepath.Join(t.TempDir(), "kubectl")
+	script := "#!/bin/sh\n" +
+		"printf 'apiVersion: v1\\nkind: List\\nitems:\\n- apiVersion: v1\\n  kind: ConfigMap\\n  metadata:\\n    name: \"%s\"\\n' \"$*\"\n"
+	require.NoError(t, os.WriteFile(kubectl, []byte(script), 0o755)) // #nosec G306 - test script must be executable
+
+	objs, err := Kubectl{Command: kubectl, Resources: []string{"cm"}, Context: "prod"}.Source()(context.Background())
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	assert.Equal(t, "get cm --output yaml --context prod", objs[0].GetName())
+
+	_, err = Kubectl{Command: filepath.Join(t.TempDir(), "missing"), Resources: []string{"cm"}}.Source()(context.Background())
+	assert.ErrorContains(t, err, "failed to run")
+
+	_, err = Kubectl{Command: kubectl}.Source()(context.Background())
+	assert.ErrorContains(t, err, "no resource types to list")
+
+	// Discovery lists the kinds of the desired objects in addition to the given resources
+	desired, err := parser.ParseYAML(strings.NewReader(desiredConfigMap + "\n---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n"))
+	require.NoError(t, err)
+	desiredSource := func(context.Context) ([]*unstructured.Unstructured, error) { return desired, nil }
+	objs, err = Kubectl{Command: kubectl, Resources: []string{"svc"}}.DiscoverSource(desiredSource)(context.Background())
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	assert.Equal(t, "get svc,ConfigMap,Deployment.apps --output yaml", objs[0].GetName())
+}
+
+func TestResourceTypes(t *testing.T) {
+	objs, err := parser.ParseYAML(strings.NewReader(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: example.com/v1alpha1
+kind: Widget
+metadata:
+  name: w
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deployment.apps", "Service", "Widget.example.com"}, ResourceTypes(objs))
+}
@@ -0,0 +1,90 @@
+package drift
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metrics records drift check outcomes and exposes them in the Prometheus text format
+type Metrics struct {
+	mu              sync.Mutex
+	checks          int
+	checkErrors     int
+	drifted         int
+	driftedByKind   map[string]int
+	lastCheckSecond float64
+	lastSuccessful  float64
+}
+
+// NewMetrics returns empty Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{driftedByKind: map[string]int{}}
+}
+
+// record updates the metrics with the outcome of a check
+func (m *Metrics) record(status Status, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checks++
+	m.lastCheckSecond = float64(status.Time.Unix())
+	if err != nil {
+		m.checkErrors++
+		return
+	}
+	m.lastSuccessful = m.lastCheckSecond
+	m.drifted = len(status.Drifted)
+	m.driftedByKind = map[string]int{}
+	for _, key := range status.Drifted {
+		m.driftedByKind[key.Kind]++
+	}
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	writeMetric(&b, "k8s_manifest_diff_drift_checks_total", "counter", "Number of drift checks performed.", float64(m.checks))
+	writeMetric(&b, "k8s_manifest_diff_drift_check_errors_total", "counter", "Number of drift checks that failed.", float64(m.checkErrors))
+	writeMetric(&b, "k8s_manifest_diff_drifted_resources", "gauge", "Number of resources that differ from the desired state.", float64(m.drifted))
+	if len(m.driftedByKind) > 0 {
+		b.WriteString("# HELP k8s_manifest_diff_drifted_resources_by_kind Number of drifted resources per kind.\n")
+		b.WriteString("# TYPE k8s_manifest_diff_drifted_resources_by_kind gauge\n")
+		for _, kind := range sortedKinds(m.driftedByKind) {
+			fmt.Fprintf(&b, "k8s_manifest_diff_drifted_resources_by_kind{kind=%q} %d\n", kind, m.driftedByKind[kind])
+		}
+	}
+	writeMetric(&b, "k8s_manifest_diff_drift_last_check_timestamp_seconds", "gauge", "Unix time of the last drift check.", m.lastCheckSecond)
+	writeMetric(&b, "k8s_manifest_diff_drift_last_success_timestamp_seconds", "gauge", "Unix time of the last successful drift check.", m.lastSuccessful)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics for Prometheus scraping
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+// writeMetric writes a single unlabelled metric with its metadata
+func writeMetric(b *strings.Builder, name, metricType, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, metricType, name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// sortedKinds returns the kinds in alphabetical order
+func sortedKinds(counts map[string]int) []string {
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
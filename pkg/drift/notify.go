@@ -0,0 +1,67 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WebhookNotifier posts events as JSON to a URL, e.g. a chat incoming webhook or an alert receiver
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client // HTTP client to use (http.DefaultClient when nil)
+}
+
+// webhookPayload is the JSON body posted by WebhookNotifier.
+// The text field makes the payload readable by Slack-compatible incoming webhooks.
+type webhookPayload struct {
+	Event
+	Text string `json:"text"`
+}
+
+// Notify posts the event to the webhook URL
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(webhookPayload{Event: event, Text: eventText(event)})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) // #nosec G107 - the webhook URL is provided by the user running the daemon
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// eventText returns a human readable description of an event
+func eventText(event Event) string {
+	var b bytes.Buffer
+	switch event.Type {
+	case EventDetected:
+		b.WriteString("Drift detected: ")
+	case EventResolved:
+		b.WriteString("Drift resolved: ")
+	}
+	b.WriteString(event.Summary)
+	for _, resource := range event.Resources {
+		fmt.Fprintf(&b, "\n- %s", resource)
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// expressionVariable is the name under which the evaluated object is available in expressions
+const expressionVariable = "object"
+
+// Expression is a compiled CEL expression selecting objects, e.g.
+// "object.kind == 'Deployment' && object.metadata.namespace.startsWith('team-a')"
+type Expression struct {
+	source  string
+	program cel.Program
+}
+
+// NewExpression compiles a CEL expression that evaluates to a bool for the object bound to "object"
+func NewExpression(source string) (*Expression, error) {
+	env, err := cel.NewEnv(cel.Variable(expressionVariable, cel.DynType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expression environment: %w", err)
+	}
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid filter expression %q: %w", source, issues.Err())
+	}
+	if outputType := ast.OutputType(); !outputType.IsExactType(cel.BoolType) && !outputType.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("invalid filter expression %q: must evaluate to bool, not %s", source, outputType)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter expression %q: %w", source, err)
+	}
+	return &Expression{source: source, program: program}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Matches evaluates the expression for obj.
+// Evaluation fails, e.g., when the expression accesses a field obj does not have; guard such fields with has().
+func (e *Expression) Matches(obj *unstructured.Unstructured) (bool, error) {
+	out, _, err := e.program.Eval(map[string]any{expressionVariable: obj.Object})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate filter expression %q: %w", e.source, err)
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("filter expression %q evaluated to %v, not a bool", e.source, out.Value())
+	}
+	return matched, nil
+}
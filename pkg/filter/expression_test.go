@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExpression(t *testing.T) {
+	deployment := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]any{
+				"name":      "web",
+				"namespace": "team-a-prod",
+				"labels":    map[string]any{"app": "web"},
+			},
+			"spec": map[string]any{"replicas": int64(3)},
+		},
+	}
+	namespace := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]any{"name": "team-a-prod"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   bool
+		expectErr  bool
+	}{
+		{name: "kind and namespace", expression: "object.kind == 'Deployment' && object.metadata.namespace.startsWith('team-a')", expected: true},
+		{name: "numeric field", expression: "object.spec.replicas > 5", expected: false},
+		{name: "label", expression: "object.metadata.labels.app in ['web', 'api']", expected: true},
+		{name: "missing field guarded by has", expression: "has(object.metadata.annotations) && object.metadata.annotations.owner == 'x'", expected: false},
+		{name: "missing field", expression: "object.metadata.annotations.owner == 'x'", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := NewExpression(tt.expression)
+			require.NoError(t, err)
+			matched, err := expression.Matches(deployment)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+
+	t.Run("compile errors", func(t *testing.T) {
+		_, err := NewExpression("object.kind ==")
+		assert.ErrorContains(t, err, "invalid filter expression")
+		_, err = NewExpression("'Deployment'")
+		assert.ErrorContains(t, err, "must evaluate to bool")
+		_, err = NewExpression("kind == 'Deployment'")
+		assert.ErrorContains(t, err, "undeclared reference")
+	})
+
+	t.Run("resources failing to evaluate are excluded", func(t *testing.T) {
+		expression, err := NewExpression("object.metadata.namespace.startsWith('team-a')")
+		require.NoError(t, err)
+		filtered := Resources([]*unstructured.Unstructured{deployment, namespace}, &Option{Expression: expression})
+		require.Len(t, filtered, 1)
+		assert.Equal(t, "Deployment", filtered[0].GetKind())
+	})
+
+	t.Run("select reports resources failing to evaluate", func(t *testing.T) {
+		expression, err := NewExpression("object.metadata.namespace.startsWith('team-a')")
+		require.NoError(t, err)
+		_, err = Select([]*unstructured.Unstructured{deployment, namespace}, &Option{Expression: expression})
+		assert.ErrorContains(t, err, "Namespace team-a-prod: failed to evaluate filter expression")
+
+		guarded, err := NewExpression("has(object.metadata.namespace) && object.metadata.namespace.startsWith('team-a')")
+		require.NoError(t, err)
+		selected, err := Select([]*unstructured.Unstructured{deployment, namespace}, &Option{Expression: guarded})
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+		assert.Equal(t, "Deployment", selected[0].GetKind())
+	})
+}
@@ -2,46 +2,123 @@
 package filter
 
 import (
+	"fmt"
 	"slices"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// IgnoreAnnotation marks a resource to be skipped entirely when set to "true"
+const IgnoreAnnotation = "k8s-manifest-diff/ignore"
+
 // Option controls the filtering behavior for Kubernetes resources
 type Option struct {
-	ExcludeKinds       []string          // List of Kinds to exclude from filtering
-	LabelSelector      map[string]string // Label selector to filter resources (exact match)
-	AnnotationSelector map[string]string // Annotation selector to filter resources (exact match)
+	ExcludeKinds              []string            // List of Kinds to exclude from filtering, optionally qualified by group, see MatchesKind
+	LabelSelector             map[string]string   // Label selector resources must match entirely, see MatchesSelector
+	AnnotationSelector        map[string]string   // Annotation selector resources must match entirely, see MatchesSelector
+	ExcludeLabelSelector      map[string][]string // Resources with a label matching any key and one of its values are excluded
+	ExcludeAnnotationSelector map[string][]string // Resources with an annotation matching any key and one of its values are excluded
+	Expression                *Expression         // CEL expression resources must satisfy; Select fails for resources it cannot evaluate (disabled when nil)
+	DisableIgnoreAnnotation   bool                // Do not skip resources annotated with IgnoreAnnotation (default: false)
 }
 
 // DefaultOption returns the default filtering options
 func DefaultOption() *Option {
 	return &Option{
-		ExcludeKinds:       nil,
-		LabelSelector:      nil,
-		AnnotationSelector: nil,
+		ExcludeKinds:            nil,
+		LabelSelector:           nil,
+		AnnotationSelector:      nil,
+		DisableIgnoreAnnotation: false,
+	}
+}
+
+// Validate returns an error if a selector contains an invalid regular expression
+func (o *Option) Validate() error {
+	if o == nil {
+		return nil
+	}
+	selectors := []map[string][]string{o.ExcludeLabelSelector, o.ExcludeAnnotationSelector}
+	for _, selector := range []map[string]string{o.LabelSelector, o.AnnotationSelector} {
+		entries := make(map[string][]string, len(selector))
+		for key, value := range selector {
+			entries[key] = []string{value}
+		}
+		selectors = append(selectors, entries)
+	}
+	for _, selector := range selectors {
+		for key, values := range selector {
+			for _, value := range values {
+				for _, pattern := range []string{key, value} {
+					if _, err := compilePattern(pattern); err != nil {
+						return fmt.Errorf("invalid selector %s=%s: %w", key, value, err)
+					}
+				}
+			}
+		}
 	}
+	return nil
 }
 
-// Resources removes resources based on the provided filter options
+// IsIgnored returns true if the object opts out of processing via IgnoreAnnotation
+func IsIgnored(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GetAnnotations()[IgnoreAnnotation] == "true"
+}
+
+// MatchesKind reports whether gvk matches a kind pattern. A plain "Kind" matches the kind in any group,
+// while "Kind.group" (e.g. "Workflow.argoproj.io") and "group/Kind" (e.g. "argoproj.io/Workflow") match only
+// that group. The core group is written as "core", e.g. "core/Secret".
+func MatchesKind(pattern string, gvk schema.GroupVersionKind) bool {
+	if group, kind, ok := strings.Cut(pattern, "/"); ok {
+		return kind == gvk.Kind && normalizeGroup(group) == gvk.Group
+	}
+	if kind, group, ok := strings.Cut(pattern, "."); ok {
+		return kind == gvk.Kind && normalizeGroup(group) == gvk.Group
+	}
+	return pattern == gvk.Kind
+}
+
+// normalizeGroup maps the "core" alias to the empty core API group
+func normalizeGroup(group string) string {
+	if group == "core" {
+		return ""
+	}
+	return group
+}
+
+// Resources removes resources based on the provided filter options. Objects the Expression fails to evaluate
+// for are removed too; use Select to detect them.
 func Resources(objs []*unstructured.Unstructured, opts *Option) []*unstructured.Unstructured {
+	filtered, _ := filterResources(objs, opts, false)
+	return filtered
+}
+
+// Select removes resources based on the provided filter options like Resources, but returns an error naming the
+// object if the Expression fails to evaluate for one, e.g. because it reads a field the object does not have
+func Select(objs []*unstructured.Unstructured, opts *Option) ([]*unstructured.Unstructured, error) {
+	return filterResources(objs, opts, true)
+}
+
+// filterResources removes resources based on the provided filter options, failing on expression errors if strict
+func filterResources(objs []*unstructured.Unstructured, opts *Option, strict bool) ([]*unstructured.Unstructured, error) {
 	if opts == nil {
 		opts = DefaultOption()
 	}
 
 	filtered := make([]*unstructured.Unstructured, 0, len(objs))
 
-	// Check if label selector is provided
-	hasLabelSelector := len(opts.LabelSelector) > 0
-	// Check if annotation selector is provided
-	hasAnnotationSelector := len(opts.AnnotationSelector) > 0
-
 	for _, obj := range objs {
 		if obj == nil {
 			continue
 		}
 
-		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		// Skip resources that opt out via annotation
+		if !opts.DisableIgnoreAnnotation && IsIgnored(obj) {
+			continue
+		}
+
+		gvk := obj.GetObjectKind().GroupVersionKind()
 
 		// Skip kinds in exclude list
 		var excludeKinds []string
@@ -53,41 +130,37 @@ func Resources(objs []*unstructured.Unstructured, opts *Option) []*unstructured.
 			excludeKinds = opts.ExcludeKinds
 		}
 
-		if slices.Contains(excludeKinds, kind) {
+		if slices.ContainsFunc(excludeKinds, func(pattern string) bool { return MatchesKind(pattern, gvk) }) {
 			continue
 		}
 
-		// Apply label selector filter if provided
-		if hasLabelSelector {
-			objLabels := obj.GetLabels()
-			match := true
-			for key, value := range opts.LabelSelector {
-				if objValue, exists := objLabels[key]; !exists || objValue != value {
-					match = false
-					break
-				}
-			}
-			if !match {
-				continue
-			}
+		// Apply label and annotation selectors
+		if !MatchesSelector(opts.LabelSelector, obj.GetLabels()) || !MatchesSelector(opts.AnnotationSelector, obj.GetAnnotations()) {
+			continue
+		}
+		if matchesAnySelector(opts.ExcludeLabelSelector, obj.GetLabels()) || matchesAnySelector(opts.ExcludeAnnotationSelector, obj.GetAnnotations()) {
+			continue
 		}
 
-		// Apply annotation selector filter if provided
-		if hasAnnotationSelector {
-			objAnnotations := obj.GetAnnotations()
-			match := true
-			for key, value := range opts.AnnotationSelector {
-				if objValue, exists := objAnnotations[key]; !exists || objValue != value {
-					match = false
-					break
-				}
+		if opts.Expression != nil {
+			matched, err := opts.Expression.Matches(obj)
+			if err != nil && strict {
+				return nil, fmt.Errorf("%s %s: %w", gvk.Kind, objectName(obj), err)
 			}
-			if !match {
+			if err != nil || !matched {
 				continue
 			}
 		}
 
 		filtered = append(filtered, obj)
 	}
-	return filtered
+	return filtered, nil
+}
+
+// objectName returns the namespace/name of an object, or its name if it has no namespace
+func objectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
 }
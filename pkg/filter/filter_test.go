@@ -588,3 +588,130 @@ func TestResources_ExcludeKinds(t *testing.T) {
 		})
 	}
 }
+
+func TestResources_IgnoreAnnotation(t *testing.T) {
+	ignoredObj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":      "ignored-config",
+				"namespace": "default",
+				"annotations": map[string]any{
+					IgnoreAnnotation: "true",
+				},
+			},
+		},
+	}
+
+	notIgnoredObj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":      "explicitly-included-config",
+				"namespace": "default",
+				"annotations": map[string]any{
+					IgnoreAnnotation: "false",
+				},
+			},
+		},
+	}
+
+	plainObj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":      "plain-config",
+				"namespace": "default",
+			},
+		},
+	}
+
+	objects := []*unstructured.Unstructured{ignoredObj, notIgnoredObj, plainObj}
+
+	tests := []struct {
+		name          string
+		opts          *Option
+		expectedNames []string
+	}{
+		{
+			name:          "nil options honor ignore annotation",
+			opts:          nil,
+			expectedNames: []string{"explicitly-included-config", "plain-config"},
+		},
+		{
+			name:          "default options honor ignore annotation",
+			opts:          DefaultOption(),
+			expectedNames: []string{"explicitly-included-config", "plain-config"},
+		},
+		{
+			name:          "disabled ignore annotation keeps all objects",
+			opts:          &Option{DisableIgnoreAnnotation: true},
+			expectedNames: []string{"ignored-config", "explicitly-included-config", "plain-config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := Resources(objects, tt.opts)
+
+			names := make([]string, len(filtered))
+			for i, obj := range filtered {
+				names[i] = obj.GetName()
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+
+	t.Run("IsIgnored", func(t *testing.T) {
+		assert.True(t, IsIgnored(ignoredObj))
+		assert.False(t, IsIgnored(notIgnoredObj))
+		assert.False(t, IsIgnored(plainObj))
+		assert.False(t, IsIgnored(nil))
+	})
+}
+
+func TestResources_ExcludeKindsWithGroup(t *testing.T) {
+	newObj := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]any{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"metadata": map[string]any{
+					"name":      name,
+					"namespace": "default",
+				},
+			},
+		}
+	}
+	objects := []*unstructured.Unstructured{
+		newObj("argoproj.io/v1alpha1", "Workflow", "argo"),
+		newObj("example.com/v1", "Workflow", "other"),
+		newObj("v1", "Secret", "secret"),
+	}
+
+	tests := []struct {
+		name          string
+		excludeKinds  []string
+		expectedNames []string
+	}{
+		{name: "plain kind matches every group", excludeKinds: []string{"Workflow"}, expectedNames: []string{"secret"}},
+		{name: "Kind.group", excludeKinds: []string{"Workflow.argoproj.io"}, expectedNames: []string{"other", "secret"}},
+		{name: "group/Kind", excludeKinds: []string{"example.com/Workflow"}, expectedNames: []string{"argo", "secret"}},
+		{name: "core group", excludeKinds: []string{"core/Secret"}, expectedNames: []string{"argo", "other"}},
+		{name: "group mismatch", excludeKinds: []string{"Secret.apps", "apps/Workflow"}, expectedNames: []string{"argo", "other", "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := Resources(objects, &Option{ExcludeKinds: tt.excludeKinds})
+			names := make([]string, len(filtered))
+			for i, obj := range filtered {
+				names[i] = obj.GetName()
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// regexPrefix marks a selector key or value as a regular expression, e.g. "app=~payments-(api|worker)"
+const regexPrefix = "~"
+
+// ParseSelectors parses selector strings of the forms key=value, key!=value and key, ignoring malformed entries.
+// A key without a value matches any value. It returns the entries resources must match and the negated
+// entries resources must not match, which belong in LabelSelector and ExcludeLabelSelector
+// (or AnnotationSelector and ExcludeAnnotationSelector). Negated entries keep every value of a repeated key,
+// e.g. both of "env!=dev" and "env!=test".
+func ParseSelectors(selectors []string) (matching map[string]string, negated map[string][]string) {
+	matching = make(map[string]string)
+	negated = make(map[string][]string)
+	for _, selector := range selectors {
+		key, value, isNegated, ok := parseSelector(selector)
+		switch {
+		case !ok:
+		case isNegated:
+			negated[key] = append(negated[key], value)
+		default:
+			matching[key] = value
+		}
+	}
+	return matching, negated
+}
+
+// ParseExclusions parses exclusion selector strings of the forms key=value and key, ignoring malformed entries,
+// into ExcludeLabelSelector or ExcludeAnnotationSelector entries, keeping every value of a repeated key
+func ParseExclusions(selectors []string) map[string][]string {
+	excluded := make(map[string][]string)
+	for _, selector := range selectors {
+		if key, value, isNegated, ok := parseSelector(selector); ok && !isNegated {
+			excluded[key] = append(excluded[key], value)
+		}
+	}
+	return excluded
+}
+
+// parseSelector splits a selector string into its key and value, reporting whether it is negated (key!=value)
+// and whether it is well-formed enough to use. A key without a value gets the value "*". A key whose name
+// starts with ".*" is read as the regular expression missing its "~" that ValidateSelector suggests,
+// so "fluxcd.io/.*=" becomes "~fluxcd.io/.*" with the value "*".
+func parseSelector(selector string) (key, value string, negated, ok bool) {
+	if key, value, found := strings.Cut(selector, "!="); found {
+		key = strings.TrimSpace(key)
+		return missingRegexPrefix(key), strings.TrimSpace(value), true, key != ""
+	}
+	if key, value, found := strings.Cut(selector, "="); found {
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if regexKey := missingRegexPrefix(key); regexKey != key {
+			if value == "" {
+				value = "*"
+			}
+			key = regexKey
+		}
+		return key, value, false, true
+	}
+	key = strings.TrimSpace(selector)
+	return missingRegexPrefix(key), "*", false, key != ""
+}
+
+// missingRegexPrefix adds the "~" prefix to a key whose name starts with ".*", which cannot be a literal key name
+func missingRegexPrefix(key string) string {
+	if !strings.HasPrefix(key, regexPrefix) && strings.HasPrefix(key[strings.LastIndex(key, "/")+1:], ".*") {
+		return regexPrefix + key
+	}
+	return key
+}
+
+// ValidateSelector returns an error explaining why selector is malformed, suggesting a fix where one is apparent.
+// ParseSelectors ignores, misreads or guesses the meaning of such selectors. Keys that are not patterns must be valid label or
+// annotation keys, and values that are not patterns must be valid label values if labelValues is set.
+func ValidateSelector(selector string, labelValues bool) error {
+	key, value, op := selector, "", ""
+	if k, v, ok := strings.Cut(selector, "!="); ok {
+		key, value, op = k, v, "!="
+	} else if k, v, ok := strings.Cut(selector, "="); ok {
+		key, value, op = k, v, "="
+	}
+	invalid := func(reason, suggestion string) error {
+		if suggestion != "" && suggestion != selector && ValidateSelector(suggestion, labelValues) == nil {
+			return fmt.Errorf("invalid selector %q: %s; did you mean %q?", selector, reason, suggestion)
+		}
+		return fmt.Errorf("invalid selector %q: %s (expected key=value, key!=value or key)", selector, reason)
+	}
+
+	switch trimmedKey := strings.TrimSpace(key); {
+	case strings.TrimSpace(selector) == "":
+		return invalid("empty selector", "")
+	case trimmedKey == "":
+		return invalid("missing key", "")
+	case trimmedKey != key || strings.ContainsAny(trimmedKey, " \t"):
+		return invalid("key contains whitespace", trimmedKey+op+strings.TrimSpace(value))
+	case op == "=" && strings.HasPrefix(value, "="):
+		return invalid(`value starts with "="`, key+"="+strings.TrimLeft(value, "="))
+	case op == "" && strings.Contains(key, ":"):
+		k, v, _ := strings.Cut(key, ":")
+		return invalid(`":" does not separate keys from values`, k+"="+strings.TrimSpace(v))
+	case missingRegexPrefix(key) != key:
+		// Key names cannot start with ".", so ".*" is a regular expression missing its "~", as in "fluxcd.io/.*="
+		suggestion := regexPrefix + key
+		if op != "=" || value != "" {
+			suggestion += op + value
+		}
+		return invalid(`".*" is a regular expression, which needs a "~" prefix`, suggestion)
+	}
+
+	for _, pattern := range []string{key, value} {
+		if _, err := compilePattern(pattern); err != nil {
+			return invalid(err.Error(), "")
+		}
+	}
+	if !isPattern(key) {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return invalid("invalid key: "+strings.Join(errs, "; "), "")
+		}
+	}
+	if labelValues && op != "" && !isPattern(value) {
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return invalid("invalid label value: "+strings.Join(errs, "; "), key+op+strings.TrimSpace(value))
+		}
+	}
+	return nil
+}
+
+// isPattern reports whether a selector key or value is a wildcard or "~" pattern rather than a plain string
+func isPattern(s string) bool {
+	return strings.HasPrefix(s, regexPrefix) || strings.ContainsAny(s, "*?")
+}
+
+// MatchesSelector reports whether values, the labels or annotations of a resource, match every entry of selector.
+// An entry matches if some key matching the key pattern has a value matching the value pattern.
+// Patterns match exactly unless they contain the wildcards "*" (any characters) or "?" (one character),
+// or start with "~", in which case the rest is an anchored regular expression.
+// An empty selector matches every resource.
+func MatchesSelector(selector, values map[string]string) bool {
+	for keyPattern, valuePattern := range selector {
+		if !matchesEntry(keyPattern, valuePattern, values) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAnySelector reports whether values match at least one entry of selector
+func matchesAnySelector(selector map[string][]string, values map[string]string) bool {
+	for keyPattern, valuePatterns := range selector {
+		for _, valuePattern := range valuePatterns {
+			if matchesEntry(keyPattern, valuePattern, values) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesEntry reports whether some key of values matching keyPattern has a value matching valuePattern
+func matchesEntry(keyPattern, valuePattern string, values map[string]string) bool {
+	keyRegexp, err := compilePattern(keyPattern)
+	if err != nil {
+		return false
+	}
+	valueRegexp, err := compilePattern(valuePattern)
+	if err != nil {
+		return false
+	}
+	for key, value := range values {
+		if matchesPattern(keyPattern, keyRegexp, key) && matchesPattern(valuePattern, valueRegexp, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPattern reports whether s matches pattern, using re when pattern is not a plain string
+func matchesPattern(pattern string, re *regexp.Regexp, s string) bool {
+	if re == nil {
+		return pattern == s
+	}
+	return re.MatchString(s)
+}
+
+// compilePattern returns the anchored regular expression of a wildcard or "~" pattern, or nil for a plain string
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		return regexp.Compile("^(?:" + expr + ")$")
+	}
+	if !strings.ContainsAny(pattern, "*?") {
+		return nil, nil
+	}
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
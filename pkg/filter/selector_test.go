@@ -0,0 +1,187 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMatchesSelector(t *testing.T) {
+	labels := map[string]string{
+		"app":                       "payments-api",
+		"tier":                      "backend",
+		"app.kubernetes.io/version": "1.2.0",
+	}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		expected bool
+	}{
+		{name: "empty selector", selector: nil, expected: true},
+		{name: "exact", selector: map[string]string{"app": "payments-api", "tier": "backend"}, expected: true},
+		{name: "exact mismatch", selector: map[string]string{"app": "payments"}, expected: false},
+		{name: "missing key", selector: map[string]string{"env": "prod"}, expected: false},
+		{name: "value wildcard", selector: map[string]string{"app": "payments-*"}, expected: true},
+		{name: "value single character wildcard", selector: map[string]string{"app.kubernetes.io/version": "1.?.0"}, expected: true},
+		{name: "value regex", selector: map[string]string{"app": "~payments-(api|worker)"}, expected: true},
+		{name: "value regex is anchored", selector: map[string]string{"app": "~payments"}, expected: false},
+		{name: "key wildcard", selector: map[string]string{"app.kubernetes.io/*": "1.2.0"}, expected: true},
+		{name: "key regex", selector: map[string]string{`~app\.kubernetes\.io/.*`: "*"}, expected: true},
+		{name: "dots are literal in wildcards", selector: map[string]string{"app.kubernetes.io/version": "1*2x0"}, expected: false},
+		{name: "every entry must match", selector: map[string]string{"app": "payments-*", "tier": "frontend"}, expected: false},
+		{name: "invalid regex never matches", selector: map[string]string{"app": "~payments-("}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchesSelector(tt.selector, labels))
+		})
+	}
+}
+
+func TestResources_ExcludeSelectors(t *testing.T) {
+	newObj := func(name string, labels, annotations map[string]any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]any{
+					"name":        name,
+					"namespace":   "default",
+					"labels":      labels,
+					"annotations": annotations,
+				},
+			},
+		}
+	}
+	objects := []*unstructured.Unstructured{
+		newObj("payments", map[string]any{"app": "payments-api"}, nil),
+		newObj("flux", map[string]any{"app": "web"}, map[string]any{"fluxcd.io/automated": "true"}),
+		newObj("web", map[string]any{"app": "web"}, nil),
+	}
+
+	tests := []struct {
+		name          string
+		opts          *Option
+		expectedNames []string
+	}{
+		{
+			name:          "exclude label",
+			opts:          &Option{ExcludeLabelSelector: map[string][]string{"app": {"payments-*"}}},
+			expectedNames: []string{"flux", "web"},
+		},
+		{
+			name:          "exclude annotation key with any value",
+			opts:          &Option{ExcludeAnnotationSelector: map[string][]string{"fluxcd.io/*": {"*"}}},
+			expectedNames: []string{"payments", "web"},
+		},
+		{
+			name:          "any exclude entry excludes",
+			opts:          &Option{ExcludeLabelSelector: map[string][]string{"app": {"payments-*"}, "missing": {"*"}}, ExcludeAnnotationSelector: map[string][]string{"fluxcd.io/*": {"*"}}},
+			expectedNames: []string{"web"},
+		},
+		{
+			name:          "include and exclude",
+			opts:          &Option{LabelSelector: map[string]string{"app": "web"}, ExcludeAnnotationSelector: map[string][]string{"fluxcd.io/*": {"*"}}},
+			expectedNames: []string{"web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := Resources(objects, tt.opts)
+			names := make([]string, len(filtered))
+			for i, obj := range filtered {
+				names[i] = obj.GetName()
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestOption_Validate(t *testing.T) {
+	assert.NoError(t, (*Option)(nil).Validate())
+	assert.NoError(t, (&Option{LabelSelector: map[string]string{"app": "~payments-(api|worker)"}}).Validate())
+	assert.ErrorContains(t, (&Option{ExcludeAnnotationSelector: map[string][]string{"~fluxcd.io/(": {"*"}}}).Validate(), "invalid selector ~fluxcd.io/(=*")
+}
+
+func TestParseSelectors(t *testing.T) {
+	matching, negated := ParseSelectors([]string{"app=nginx", " tier != test ", "fluxcd.io/*", "env=", "!=orphan", "", "tier!=dev", "helm.sh/.*="})
+	assert.Equal(t, map[string]string{"app": "nginx", "fluxcd.io/*": "*", "env": "", "~helm.sh/.*": "*"}, matching)
+	assert.Equal(t, map[string][]string{"tier": {"test", "dev"}}, negated)
+}
+
+func TestParseExclusions(t *testing.T) {
+	excluded := ParseExclusions([]string{"env=dev", "env=test", "fluxcd.io/*", "tier!=prod", "", "kustomize.toolkit.fluxcd.io/.*=true"})
+	assert.Equal(t, map[string][]string{"env": {"dev", "test"}, "fluxcd.io/*": {"*"}, "~kustomize.toolkit.fluxcd.io/.*": {"true"}}, excluded)
+}
+
+func TestResources_NegatedSelectors(t *testing.T) {
+	newObj := func(name string, labels map[string]any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]any{
+					"name":   name,
+					"labels": labels,
+				},
+			},
+		}
+	}
+	objects := []*unstructured.Unstructured{
+		newObj("prod-api", map[string]any{"app": "api", "tier": "prod"}),
+		newObj("test-api", map[string]any{"app": "api", "tier": "test"}),
+		newObj("unlabeled-api", map[string]any{"app": "api"}),
+		newObj("prod-web", map[string]any{"app": "web", "tier": "prod"}),
+		newObj("dev-api", map[string]any{"app": "api", "tier": "dev"}),
+	}
+
+	matching, negated := ParseSelectors([]string{"app=api", "tier!=test", "tier!=dev"})
+	filtered := Resources(objects, &Option{LabelSelector: matching, ExcludeLabelSelector: negated})
+	names := make([]string, len(filtered))
+	for i, obj := range filtered {
+		names[i] = obj.GetName()
+	}
+	assert.Equal(t, []string{"prod-api", "unlabeled-api"}, names)
+}
+
+func TestValidateSelector(t *testing.T) {
+	tests := []struct {
+		name        string
+		selector    string
+		labelValues bool
+		expectedErr string
+	}{
+		{name: "key and value", selector: "app=nginx", labelValues: true},
+		{name: "negated", selector: "tier!=test", labelValues: true},
+		{name: "key only", selector: "app.kubernetes.io/name", labelValues: true},
+		{name: "empty value", selector: "app=", labelValues: true},
+		{name: "patterns", selector: "app.kubernetes.io/*=~payments-(api|worker)", labelValues: true},
+		{name: "annotation value with spaces", selector: "description=owned by team a"},
+		{name: "empty", selector: "", expectedErr: "empty selector"},
+		{name: "missing key", selector: "=nginx", expectedErr: "missing key"},
+		{name: "whitespace around equals", selector: "app = nginx", labelValues: true, expectedErr: `key contains whitespace; did you mean "app=nginx"?`},
+		{name: "double equals", selector: "app==nginx", labelValues: true, expectedErr: `did you mean "app=nginx"?`},
+		{name: "colon separator", selector: "app:nginx", labelValues: true, expectedErr: `did you mean "app=nginx"?`},
+		{name: "invalid key", selector: "app!@=nginx", expectedErr: "invalid key"},
+		{name: "invalid label value", selector: "app=nginx web", labelValues: true, expectedErr: "invalid label value"},
+		{name: "invalid regex", selector: "app=~payments-(", expectedErr: "missing closing )"},
+		{name: "regex key", selector: `~fluxcd\.io/.*`},
+		{name: "regex key without prefix", selector: "fluxcd.io/.*=", expectedErr: `did you mean "~fluxcd.io/.*"?`},
+		{name: "regex key without prefix and value", selector: "fluxcd.io/.*=true", expectedErr: `did you mean "~fluxcd.io/.*=true"?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSelector(tt.selector, tt.labelValues)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
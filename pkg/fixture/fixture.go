@@ -0,0 +1,261 @@
+// Package fixture generates mutated copies of manifests, e.g. with bumped images, changed replicas or dropped
+// resources, so that test fixtures and demos of diffs don't have to be edited by hand.
+package fixture
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/workload"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MutationType is the change a mutation makes to the resources it matches
+type MutationType string
+
+const (
+	// MutationImage changes the images of workload containers
+	MutationImage MutationType = "image"
+	// MutationReplicas changes the replica count of workloads
+	MutationReplicas MutationType = "replicas"
+	// MutationDelete drops resources from the manifests
+	MutationDelete MutationType = "delete"
+)
+
+// Mutation changes the resources matching its kind, namespace and name. Empty fields match anything.
+type Mutation struct {
+	Type      MutationType `yaml:"type"`
+	Kind      string       `yaml:"kind,omitempty"`
+	Namespace string       `yaml:"namespace,omitempty"`
+	Name      string       `yaml:"name,omitempty"`
+	Container string       `yaml:"container,omitempty"` // image: only change this container (default: all containers)
+	Image     string       `yaml:"image,omitempty"`     // image: replace the image
+	Tag       string       `yaml:"tag,omitempty"`       // image: replace the tag, keeping the repository
+	Replicas  *int64       `yaml:"replicas,omitempty"`  // replicas: the new replica count
+}
+
+// Spec lists the mutations applied to the manifests, in order
+type Spec struct {
+	Mutations []Mutation `yaml:"mutations"`
+}
+
+// ReadSpec reads a mutation spec from YAML, e.g.
+//
+//	mutations:
+//	- type: image          # bump the last number of every image tag, nginx:1.25.3 -> nginx:1.25.4
+//	  kind: Deployment
+//	- type: image
+//	  name: api
+//	  container: api
+//	  tag: "2.0.0"
+//	- type: replicas
+//	  kind: Deployment
+//	  name: web
+//	  replicas: 3
+//	- type: delete
+//	  kind: ConfigMap
+//	  name: legacy-config
+func ReadSpec(r io.Reader) (*Spec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture spec: %w", err)
+	}
+
+	var spec Spec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture spec: %w", err)
+	}
+	for i, mutation := range spec.Mutations {
+		if err := mutation.validate(); err != nil {
+			return nil, fmt.Errorf("fixture spec mutation %d: %w", i+1, err)
+		}
+	}
+	return &spec, nil
+}
+
+// validate checks that the mutation has the fields of its type
+func (m Mutation) validate() error {
+	switch m.Type {
+	case MutationImage:
+		if m.Image != "" && m.Tag != "" {
+			return fmt.Errorf("image and tag are mutually exclusive")
+		}
+	case MutationReplicas:
+		if m.Replicas == nil || *m.Replicas < 0 {
+			return fmt.Errorf("replicas requires a replica count of at least 0")
+		}
+	case MutationDelete:
+	default:
+		return fmt.Errorf("unknown type %q (image|replicas|delete)", m.Type)
+	}
+	if m.Type != MutationImage && (m.Container != "" || m.Image != "" || m.Tag != "") {
+		return fmt.Errorf("container, image and tag only apply to image mutations")
+	}
+	if m.Type != MutationReplicas && m.Replicas != nil {
+		return fmt.Errorf("replicas only applies to replicas mutations")
+	}
+	return nil
+}
+
+// matches reports whether obj has the kind, namespace and name of the mutation
+func (m Mutation) matches(obj *unstructured.Unstructured) bool {
+	return (m.Kind == "" || m.Kind == obj.GetKind()) &&
+		(m.Namespace == "" || m.Namespace == obj.GetNamespace()) &&
+		(m.Name == "" || m.Name == obj.GetName())
+}
+
+// Apply returns copies of objs with the mutations of spec applied in order, looking up the pod spec and replica
+// count of workloads in policy (the built-in kinds when nil). The input objects are not modified. It fails if a
+// mutation changes no resource, so that specs don't silently go stale when the manifests change.
+func Apply(objs []*unstructured.Unstructured, spec *Spec, policy *workload.Policy) ([]*unstructured.Unstructured, error) {
+	mutated := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		mutated = append(mutated, obj.DeepCopy())
+	}
+
+	for i, mutation := range spec.Mutations {
+		var (
+			kept    []*unstructured.Unstructured
+			changed int
+		)
+		for _, obj := range mutated {
+			if !mutation.matches(obj) {
+				kept = append(kept, obj)
+				continue
+			}
+			ok, err := mutation.apply(obj, policy)
+			if err != nil {
+				return nil, fmt.Errorf("mutation %d: %s/%s: %w", i+1, obj.GetKind(), obj.GetName(), err)
+			}
+			if ok {
+				changed++
+			}
+			if mutation.Type != MutationDelete {
+				kept = append(kept, obj)
+			}
+		}
+		if changed == 0 {
+			return nil, fmt.Errorf("mutation %d (%s) changed no resources", i+1, mutation.Type)
+		}
+		mutated = kept
+	}
+	return mutated, nil
+}
+
+// apply changes obj in place and reports whether the mutation applied to it. Resources that are not workloads,
+// or have no replica count, are left alone by image and replicas mutations.
+func (m Mutation) apply(obj *unstructured.Unstructured, policy *workload.Policy) (bool, error) {
+	if m.Type == MutationDelete {
+		return true, nil
+	}
+	kind, ok := policy.Lookup(obj.GroupVersionKind().Group, obj.GetKind())
+	if !ok {
+		return false, nil
+	}
+
+	if m.Type == MutationReplicas {
+		path := kind.ReplicasPath()
+		if path == nil {
+			return false, nil
+		}
+		return true, unstructured.SetNestedField(obj.Object, *m.Replicas, path...)
+	}
+
+	changed := false
+	for _, field := range []string{"initContainers", "containers"} {
+		path := append(kind.PodSpecPath(), field)
+		containers, found, err := unstructured.NestedSlice(obj.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok || (m.Container != "" && container["name"] != m.Container) {
+				continue
+			}
+			image, _ := container["image"].(string)
+			newImage, err := m.mutateImage(image)
+			if err != nil {
+				return false, fmt.Errorf("container %v: %w", container["name"], err)
+			}
+			container["image"] = newImage
+			changed = true
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, containers, path...); err != nil {
+			return false, err
+		}
+	}
+	return changed, nil
+}
+
+// mutateImage returns the image replaced by the mutation, with its tag replaced, or with its tag bumped
+func (m Mutation) mutateImage(image string) (string, error) {
+	if m.Image != "" {
+		return m.Image, nil
+	}
+	repository, tag, digest := splitImage(image)
+	if m.Tag != "" {
+		return repository + ":" + m.Tag, nil
+	}
+	if digest != "" {
+		return "", fmt.Errorf("cannot bump image %q pinned by digest", image)
+	}
+	bumped, err := BumpTag(tag)
+	if err != nil {
+		return "", fmt.Errorf("cannot bump image %q: %w", image, err)
+	}
+	return repository + ":" + bumped, nil
+}
+
+// splitImage splits an image reference into its repository, tag and digest. A colon before the last slash
+// separates a registry port, not a tag.
+func splitImage(image string) (repository, tag, digest string) {
+	repository = image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository, digest = repository[:i], repository[i+1:]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	return repository, tag, digest
+}
+
+// BumpTag increments the last number of an image tag, e.g. "1.25.3" to "1.25.4" and "v2-alpine" to "v3-alpine"
+func BumpTag(tag string) (string, error) {
+	end := strings.LastIndexFunc(tag, isDigit) + 1
+	if end == 0 {
+		return "", fmt.Errorf("tag %q has no version number", tag)
+	}
+	start := strings.LastIndexFunc(tag[:end], func(r rune) bool { return !isDigit(r) }) + 1
+	number, err := strconv.ParseUint(tag[start:end], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("tag %q has no version number: %w", tag, err)
+	}
+	return tag[:start] + strconv.FormatUint(number+1, 10) + tag[end:], nil
+}
+
+// isDigit reports whether r is an ASCII digit
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// Write writes objs as a multi-document YAML stream
+func Write(w io.Writer, objs []*unstructured.Unstructured) error {
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		separator := "---\n"
+		if i == 0 {
+			separator = ""
+		}
+		if _, err := fmt.Fprintf(w, "%s%s", separator, data); err != nil {
+			return fmt.Errorf("failed to write fixture: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package fixture
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const manifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 1
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: registry.local:5000/migrate:v2-alpine
+      containers:
+      - name: web
+        image: nginx:1.25.3
+      - name: sidecar
+        image: envoy@sha256:abc
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: legacy-config
+  namespace: default
+data:
+  enabled: "true"
+`
+
+func parseManifests(t *testing.T) []*unstructured.Unstructured {
+	t.Helper()
+	objs, err := parser.ParseYAML(strings.NewReader(manifests))
+	require.NoError(t, err)
+	return objs
+}
+
+func containerImages(t *testing.T, obj *unstructured.Unstructured, field string) []string {
+	t.Helper()
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", field)
+	require.NoError(t, err)
+	var images []string
+	for _, c := range containers {
+		images = append(images, c.(map[string]interface{})["image"].(string))
+	}
+	return images
+}
+
+func TestReadSpec(t *testing.T) {
+	spec, err := ReadSpec(strings.NewReader("mutations:\n- type: replicas\n  kind: Deployment\n  replicas: 3\n- type: delete\n  name: legacy-config\n"))
+	require.NoError(t, err)
+	require.Len(t, spec.Mutations, 2)
+	assert.Equal(t, int64(3), *spec.Mutations[0].Replicas)
+	assert.Equal(t, MutationDelete, spec.Mutations[1].Type)
+
+	tests := []struct {
+		name     string
+		spec     string
+		expected string
+	}{
+		{name: "unknown type", spec: "mutations:\n- type: rename\n", expected: `mutation 1: unknown type "rename"`},
+		{name: "unknown field", spec: "mutations:\n- type: delete\n  labels: {}\n", expected: "failed to parse fixture spec"},
+		{name: "missing replicas", spec: "mutations:\n- type: replicas\n", expected: "replicas requires a replica count"},
+		{name: "image and tag", spec: "mutations:\n- type: image\n  image: nginx\n  tag: latest\n", expected: "mutually exclusive"},
+		{name: "image field of delete", spec: "mutations:\n- type: delete\n  tag: latest\n", expected: "only apply to image mutations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSpec(strings.NewReader(tt.spec))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	replicas := int64(3)
+
+	t.Run("bump images", func(t *testing.T) {
+		objs := parseManifests(t)
+		mutated, err := Apply(objs, &Spec{Mutations: []Mutation{{Type: MutationImage, Kind: "Deployment", Container: "web"}, {Type: MutationImage, Container: "migrate"}}}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"nginx:1.25.4", "envoy@sha256:abc"}, containerImages(t, mutated[0], "containers"))
+		assert.Equal(t, []string{"registry.local:5000/migrate:v3-alpine"}, containerImages(t, mutated[0], "initContainers"))
+		assert.Equal(t, []string{"nginx:1.25.3", "envoy@sha256:abc"}, containerImages(t, objs[0], "containers"), "the input is not modified")
+	})
+
+	t.Run("replace image and tag", func(t *testing.T) {
+		mutated, err := Apply(parseManifests(t), &Spec{Mutations: []Mutation{
+			{Type: MutationImage, Container: "web", Image: "httpd:2.4"},
+			{Type: MutationImage, Container: "sidecar", Tag: "v1.30"},
+		}}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"httpd:2.4", "envoy:v1.30"}, containerImages(t, mutated[0], "containers"))
+	})
+
+	t.Run("digest-pinned images are not bumped", func(t *testing.T) {
+		_, err := Apply(parseManifests(t), &Spec{Mutations: []Mutation{{Type: MutationImage, Container: "sidecar"}}}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pinned by digest")
+	})
+
+	t.Run("replicas and delete", func(t *testing.T) {
+		mutated, err := Apply(parseManifests(t), &Spec{Mutations: []Mutation{
+			{Type: MutationReplicas, Replicas: &replicas},
+			{Type: MutationDelete, Kind: "ConfigMap", Name: "legacy-config"},
+		}}, nil)
+		require.NoError(t, err)
+		require.Len(t, mutated, 1)
+		value, _, _ := unstructured.NestedInt64(mutated[0].Object, "spec", "replicas")
+		assert.Equal(t, int64(3), value)
+	})
+
+	t.Run("mutations changing nothing fail", func(t *testing.T) {
+		_, err := Apply(parseManifests(t), &Spec{Mutations: []Mutation{{Type: MutationReplicas, Kind: "ConfigMap", Replicas: &replicas}}}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mutation 1 (replicas) changed no resources")
+	})
+}
+
+func TestBumpTag(t *testing.T) {
+	tests := map[string]string{
+		"1.25.3":        "1.25.4",
+		"v2-alpine":     "v3-alpine",
+		"1.9":           "1.10",
+		"2024.09.30-r1": "2024.09.30-r2",
+	}
+	for tag, expected := range tests {
+		bumped, err := BumpTag(tag)
+		require.NoError(t, err)
+		assert.Equal(t, expected, bumped, tag)
+	}
+
+	_, err := BumpTag("latest")
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, parseManifests(t)))
+
+	objs, err := parser.ParseYAML(&buf)
+	require.NoError(t, err)
+	assert.Len(t, objs, 2)
+}
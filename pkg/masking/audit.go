@@ -0,0 +1,71 @@
+package masking
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AuditRecord describes a single masked value without revealing it
+type AuditRecord struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Side      string `json:"side,omitempty"` // Which input the object came from (e.g. base, head)
+	Field     string `json:"field"`          // Field containing the value (data or stringData, encryptedData of a SealedSecret or data of an ExternalSecret template)
+	Key       string `json:"key"`
+	ValueHash string `json:"valueHash"` // SHA-256 of the original value
+}
+
+// AuditRecords returns the records of values that MaskSecretData masks in obj, sorted by field and key.
+// It returns nil for objects without secret values, see SecretValuePaths.
+func AuditRecords(obj *unstructured.Unstructured, side string) []AuditRecord {
+	var records []AuditRecord
+	for _, path := range SecretValuePaths(obj) {
+		field := path[len(path)-1]
+		values, found, err := unstructured.NestedMap(obj.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		for key, value := range values {
+			strValue, ok := value.(string)
+			if !ok || !IsMaskedValue(obj, strValue) {
+				continue
+			}
+			sum := sha256.Sum256([]byte(strValue))
+			records = append(records, AuditRecord{
+				Kind:      obj.GetKind(),
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Side:      side,
+				Field:     field,
+				Key:       key,
+				ValueHash: "sha256:" + hex.EncodeToString(sum[:]),
+			})
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Field != records[j].Field {
+			return records[i].Field < records[j].Field
+		}
+		return records[i].Key < records[j].Key
+	})
+	return records
+}
+
+// WriteAuditRecords writes records to w as JSON lines
+func WriteAuditRecords(w io.Writer, records []AuditRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write masking audit record: %w", err)
+		}
+	}
+	return nil
+}
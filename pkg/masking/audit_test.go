@@ -0,0 +1,59 @@
+package masking
+
+// gitleaks:ignore-file
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestAuditRecords(t *testing.T) {
+	t.Run("non-secret object", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]any{"kind": "ConfigMap"}}
+		assert.Nil(t, AuditRecords(obj, "base"))
+		assert.Nil(t, AuditRecords(nil, "base"))
+	})
+
+	t.Run("secret with data and stringData", func(t *testing.T) {
+		obj := &unstructured.Unstructured{
+			Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]any{"name": "db", "namespace": "default"},
+				"data":       map[string]any{"password": "bXlwYXNzd29yZA==", "api-key": "dGVzdC1hcGkta2V5"},
+				"stringData": map[string]any{"token": "plain"},
+			},
+		}
+
+		records := AuditRecords(obj, "head")
+		require.Len(t, records, 3)
+		assert.Equal(t, "data", records[0].Field)
+		assert.Equal(t, "api-key", records[0].Key)
+		assert.Equal(t, "password", records[1].Key)
+		assert.Equal(t, "stringData", records[2].Field)
+		assert.Equal(t, "token", records[2].Key)
+		for _, record := range records {
+			assert.Equal(t, "Secret", record.Kind)
+			assert.Equal(t, "default", record.Namespace)
+			assert.Equal(t, "db", record.Name)
+			assert.Equal(t, "head", record.Side)
+			assert.True(t, strings.HasPrefix(record.ValueHash, "sha256:"))
+		}
+		// sha256("plain")
+		assert.Equal(t, "sha256:a116c9ed46d6207734a43317d30fd88f52ac8634c37d904bbf4e41d865f90475", records[2].ValueHash)
+	})
+}
+
+func TestWriteAuditRecords(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteAuditRecords(&buf, []AuditRecord{
+		{Kind: "Secret", Namespace: "default", Name: "db", Side: "base", Field: "data", Key: "password", ValueHash: "sha256:abc"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"kind":"Secret","namespace":"default","name":"db","side":"base","field":"data","key":"password","valueHash":"sha256:abc"}`+"\n", buf.String())
+	assert.NotContains(t, buf.String(), "bXlwYXNzd29yZA==")
+}
@@ -0,0 +1,119 @@
+package masking
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// fingerprintLength is the number of hex characters of the SHA-256 fingerprint shown for a certificate
+const fingerprintLength = 16
+
+// CertificateInfo holds the public metadata of an X.509 certificate
+type CertificateInfo struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	SANs         []string // Subject alternative names as "DNS:<name>", "IP:<address>", "URI:<uri>" or "email:<address>"
+	Fingerprint  string   // Hex SHA-256 of the DER encoding
+}
+
+// String renders the certificate metadata on a single line
+func (c CertificateInfo) String() string {
+	fingerprint := c.Fingerprint
+	if len(fingerprint) > fingerprintLength {
+		fingerprint = fingerprint[:fingerprintLength]
+	}
+	return fmt.Sprintf("<certificate subject=%q issuer=%q notBefore=%s notAfter=%s sha256=%s>",
+		c.Subject, c.Issuer, c.NotBefore.UTC().Format(time.RFC3339), c.NotAfter.UTC().Format(time.RFC3339), fingerprint)
+}
+
+// ParseCertificates returns the metadata of every CERTIFICATE block in PEM data.
+// Other blocks such as private keys are skipped and never parsed.
+// It returns no certificates and no error when data contains no certificate blocks.
+func ParseCertificates(data []byte) ([]CertificateInfo, error) {
+	var certs []CertificateInfo
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		sum := sha256.Sum256(cert.Raw)
+		certs = append(certs, CertificateInfo{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			SerialNumber: cert.SerialNumber.String(),
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+			SANs:         subjectAlternativeNames(cert),
+			Fingerprint:  hex.EncodeToString(sum[:]),
+		})
+	}
+	return certs, nil
+}
+
+// subjectAlternativeNames returns the subject alternative names of a certificate in a stable order
+func subjectAlternativeNames(cert *x509.Certificate) []string {
+	var sans []string
+	for _, name := range cert.DNSNames {
+		sans = append(sans, "DNS:"+name)
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, "IP:"+ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, "URI:"+uri.String())
+	}
+	for _, email := range cert.EmailAddresses {
+		sans = append(sans, "email:"+email)
+	}
+	return sans
+}
+
+// OtherPEMBlocks returns the types and the PEM encoding of the blocks of data that are not certificates, such as
+// the private key of a combined bundle. The blocks are not parsed.
+func OtherPEMBlocks(data []byte) ([]string, []byte) {
+	var types []string
+	var blocks []byte
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			continue
+		}
+		types = append(types, block.Type)
+		blocks = append(blocks, pem.EncodeToMemory(block)...)
+	}
+	return types, blocks
+}
+
+// certificateSummary renders the metadata of the certificates in PEM data.
+// It reports false when data holds no parsable certificate, so the value is masked instead.
+func certificateSummary(data []byte) (string, bool) {
+	certs, err := ParseCertificates(data)
+	if err != nil || len(certs) == 0 {
+		return "", false
+	}
+	summaries := make([]string, 0, len(certs))
+	for _, cert := range certs {
+		summaries = append(summaries, cert.String())
+	}
+	return strings.Join(summaries, " "), true
+}
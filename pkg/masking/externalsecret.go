@@ -0,0 +1,61 @@
+package masking
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// externalSecretGroup is the API group of External Secrets Operator resources
+const externalSecretGroup = "external-secrets.io"
+
+// externalSecretTemplateDataPath is the path of the template data an ExternalSecret renders into its Secret
+var externalSecretTemplateDataPath = []string{"spec", "target", "template", "data"}
+
+// IsExternalSecret checks if the unstructured object is an External Secrets Operator ExternalSecret
+func IsExternalSecret(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GetKind() == "ExternalSecret" && obj.GroupVersionKind().Group == externalSecretGroup
+}
+
+// isTemplated reports whether an ExternalSecret template value refers to fetched values, e.g. "{{ .password }}",
+// rather than being a literal value
+func isTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// IsMaskedValue reports whether MaskSecretData masks a value found at one of the SecretValuePaths of obj.
+// Only template values of ExternalSecrets that refer to fetched values and SOPS-encrypted Secret values are not masked.
+func IsMaskedValue(obj *unstructured.Unstructured, value string) bool {
+	if IsSecret(obj) {
+		return !IsEncryptedValue(value)
+	}
+	return !IsExternalSecret(obj) || !isTemplated(value)
+}
+
+// maskExternalSecretData creates a copy of the ExternalSecret object with the literal values of its target
+// template data masked. They end up in the generated Secret, so they are as sensitive as Secret data, while
+// templates only refer to values fetched from the provider and are shown as they are.
+func (m *Masker) maskExternalSecretData(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	masked := obj.DeepCopy()
+	values, found, err := unstructured.NestedMap(masked.Object, externalSecretTemplateDataPath...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template data field structure for ExternalSecret %s: %w", resourceIdentifier(obj), err)
+	}
+	if !found {
+		return masked, nil
+	}
+	for key, value := range values {
+		strValue, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid template data field for ExternalSecret %s: key '%s' has non-string value of type %T", resourceIdentifier(obj), key, value)
+		}
+		if !isTemplated(strValue) {
+			values[key] = m.maskValue(strValue, len(strValue))
+		}
+	}
+	if err := unstructured.SetNestedMap(masked.Object, values, externalSecretTemplateDataPath...); err != nil {
+		return nil, fmt.Errorf("failed to mask ExternalSecret %s: %w", resourceIdentifier(obj), err)
+	}
+	return masked, nil
+}
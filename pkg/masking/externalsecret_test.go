@@ -0,0 +1,35 @@
+package masking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMaskSecretData_ExternalSecret(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "external-secrets.io/v1beta1",
+		"kind":       "ExternalSecret",
+		"metadata":   map[string]any{"name": "db"},
+		"spec": map[string]any{
+			"target": map[string]any{"template": map[string]any{"data": map[string]any{
+				"url":    "postgres://app:{{ .password }}@db/app",
+				"apiKey": "sk-live-1234",
+			}}},
+		},
+	}}
+	require.True(t, HasSecretValues(obj))
+
+	masked, err := NewMasker().MaskSecretData(obj)
+	require.NoError(t, err)
+	data, _, err := unstructured.NestedStringMap(masked.Object, "spec", "target", "template", "data")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app:{{ .password }}@db/app", data["url"], "templates are not masked")
+	assert.NotEqual(t, "sk-live-1234", data["apiKey"])
+
+	records := AuditRecords(obj, "head")
+	require.Len(t, records, 1)
+	assert.Equal(t, "apiKey", records[0].Key)
+}
@@ -0,0 +1,115 @@
+package masking
+
+import (
+	"encoding/base64"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Finding describes a potential secret detected in text. The matched value is never included.
+type Finding struct {
+	Rule string // Name of the detection rule that matched
+	Line int    // 1-based line number of the match
+}
+
+// minSecretEntropy is the minimum Shannon entropy (bits per character) for a base64 string to be reported
+const minSecretEntropy = 4.2
+
+var (
+	base64Candidate = regexp.MustCompile(`[A-Za-z0-9+/]{32,}={0,2}`)
+	pemBegin        = regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]+)-----`)
+	pemEnd          = regexp.MustCompile(`-----END ([A-Z0-9 ]+)-----`)
+	tokenRules      = []struct {
+		name    string
+		pattern *regexp.Regexp
+	}{
+		{name: "aws-access-key-id", pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{name: "github-token", pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{name: "slack-token", pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	}
+)
+
+// FindPotentialSecrets scans text for strings that look like credentials or keys:
+// PEM private keys, well-known token formats, and high-entropy base64 strings.
+// Base64 that decodes to a PEM certificate or public key is not reported.
+func FindPotentialSecrets(text string) []Finding {
+	var findings []Finding
+	inPEMBlock := false
+
+	for i, line := range strings.Split(text, "\n") {
+		lineNumber := i + 1
+
+		if match := pemBegin.FindStringSubmatch(line); match != nil {
+			if strings.Contains(match[1], "PRIVATE KEY") {
+				findings = append(findings, Finding{Rule: "pem-private-key", Line: lineNumber})
+			}
+			inPEMBlock = !pemEnd.MatchString(line)
+			continue
+		}
+		if inPEMBlock {
+			// PEM bodies are reported once via their header
+			inPEMBlock = !pemEnd.MatchString(line)
+			continue
+		}
+
+		matched := false
+		for _, rule := range tokenRules {
+			if rule.pattern.MatchString(line) {
+				findings = append(findings, Finding{Rule: rule.name, Line: lineNumber})
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		for _, candidate := range base64Candidate.FindAllString(line, -1) {
+			if looksLikeSecret(candidate) {
+				findings = append(findings, Finding{Rule: "high-entropy-base64", Line: lineNumber})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// looksLikeSecret reports whether a base64 candidate has enough entropy and character variety to be a secret
+func looksLikeSecret(candidate string) bool {
+	if !strings.ContainsAny(candidate, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") ||
+		!strings.ContainsAny(candidate, "abcdefghijklmnopqrstuvwxyz") ||
+		!strings.ContainsAny(candidate, "0123456789") {
+		return false
+	}
+	if shannonEntropy(candidate) < minSecretEntropy {
+		return false
+	}
+
+	// Public material such as CA bundles is not sensitive
+	if decoded, err := base64.StdEncoding.DecodeString(candidate); err == nil {
+		if strings.Contains(string(decoded), "-----BEGIN CERTIFICATE-----") ||
+			strings.Contains(string(decoded), "-----BEGIN PUBLIC KEY-----") {
+			return false
+		}
+	}
+	return true
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	length := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
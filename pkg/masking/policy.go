@@ -0,0 +1,68 @@
+package masking
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy determines how the values of a Secret are masked
+type Policy string
+
+const (
+	// PolicyMask masks every value (default)
+	PolicyMask Policy = "mask"
+	// PolicyCertificateMetadata shows the subject, issuer, validity and fingerprint of PEM certificates
+	// and masks every other value, including private keys
+	PolicyCertificateMetadata Policy = "certificate-metadata"
+)
+
+// Validate returns an error if the policy is not supported. The empty policy means mask.
+func (p Policy) Validate() error {
+	switch p {
+	case "", PolicyMask, PolicyCertificateMetadata:
+		return nil
+	default:
+		return fmt.Errorf("invalid secret policy: %s (supported: %s, %s)", p, PolicyMask, PolicyCertificateMetadata)
+	}
+}
+
+// PolicyTable maps Secret types, e.g. "kubernetes.io/tls", to masking policies.
+// Secrets whose type is not in the table are fully masked.
+type PolicyTable map[string]Policy
+
+// For returns the policy for a Secret type. A missing type defaults to "Opaque" as in the Kubernetes API.
+func (t PolicyTable) For(secretType string) Policy {
+	if secretType == "" {
+		secretType = "Opaque"
+	}
+	if policy, ok := t[secretType]; ok && policy != "" {
+		return policy
+	}
+	return PolicyMask
+}
+
+// Validate returns an error if any policy in the table is not supported
+func (t PolicyTable) Validate() error {
+	for secretType, policy := range t {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("secret type %s: %w", secretType, err)
+		}
+	}
+	return nil
+}
+
+// ParsePolicyTable parses "type=policy" entries, e.g. "kubernetes.io/tls=certificate-metadata"
+func ParsePolicyTable(entries []string) (PolicyTable, error) {
+	table := make(PolicyTable, len(entries))
+	for _, entry := range entries {
+		secretType, policy, ok := strings.Cut(entry, "=")
+		if !ok || secretType == "" {
+			return nil, fmt.Errorf("invalid secret policy %q: expected type=policy", entry)
+		}
+		if err := Policy(policy).Validate(); err != nil {
+			return nil, err
+		}
+		table[secretType] = Policy(policy)
+	}
+	return table, nil
+}
@@ -0,0 +1,51 @@
+package masking
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sealedSecretGroup is the API group of Bitnami SealedSecrets
+const sealedSecretGroup = "bitnami.com"
+
+// sealedSecretDataPath is the path of the encrypted values of a SealedSecret
+var sealedSecretDataPath = []string{"spec", "encryptedData"}
+
+// IsSealedSecret checks if the unstructured object is a Bitnami SealedSecret
+func IsSealedSecret(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GetKind() == "SealedSecret" && obj.GroupVersionKind().Group == sealedSecretGroup
+}
+
+// maskSealedSecretData creates a copy of the SealedSecret object with its encrypted values masked like Secret
+// data. Ciphertext cannot be decrypted without the controller's key but changes on every sealing, so masking
+// keeps diffs free of it while identical values still get identical masks.
+func (m *Masker) maskSealedSecretData(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	masked := obj.DeepCopy()
+	values, found, err := unstructured.NestedMap(masked.Object, sealedSecretDataPath...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryptedData field structure for SealedSecret %s: %w", resourceIdentifier(obj), err)
+	}
+	if !found {
+		return masked, nil
+	}
+	for key, value := range values {
+		strValue, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid encryptedData field for SealedSecret %s: key '%s' has non-string value of type %T", resourceIdentifier(obj), key, value)
+		}
+		values[key] = m.maskValue(strValue, len(strValue))
+	}
+	if err := unstructured.SetNestedMap(masked.Object, values, sealedSecretDataPath...); err != nil {
+		return nil, fmt.Errorf("failed to mask SealedSecret %s: %w", resourceIdentifier(obj), err)
+	}
+	return masked, nil
+}
+
+// resourceIdentifier returns "namespace/name" of an object for error messages
+func resourceIdentifier(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,62 @@
+package masking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func sealedSecret(encryptedData map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "bitnami.com/v1alpha1",
+		"kind":       "SealedSecret",
+		"metadata":   map[string]any{"name": "db", "namespace": "default"},
+		"spec": map[string]any{
+			"encryptedData": encryptedData,
+			"template":      map[string]any{"type": "Opaque"},
+		},
+	}}
+}
+
+func TestIsSealedSecret(t *testing.T) {
+	assert.True(t, IsSealedSecret(sealedSecret(nil)))
+	assert.True(t, HasSecretValues(sealedSecret(nil)))
+
+	other := sealedSecret(nil)
+	other.SetAPIVersion("example.com/v1")
+	assert.False(t, IsSealedSecret(other), "kinds of other groups are not SealedSecrets")
+	assert.False(t, IsSealedSecret(nil))
+}
+
+func TestMaskSecretData_SealedSecret(t *testing.T) {
+	masker := NewMasker()
+	obj := sealedSecret(map[string]any{"password": "AgBy3i4OJSWK", "copy": "AgBy3i4OJSWK", "user": "AgCx9kQ2"})
+
+	masked, err := masker.MaskSecretData(obj)
+	require.NoError(t, err)
+	data, _, err := unstructured.NestedStringMap(masked.Object, "spec", "encryptedData")
+	require.NoError(t, err)
+	assert.Equal(t, data["password"], data["copy"], "identical ciphertext gets identical masks")
+	assert.NotEqual(t, data["password"], data["user"])
+	assert.NotContains(t, data["password"], "AgBy")
+
+	template, _, _ := unstructured.NestedString(masked.Object, "spec", "template", "type")
+	assert.Equal(t, "Opaque", template)
+	original, _, _ := unstructured.NestedString(obj.Object, "spec", "encryptedData", "password")
+	assert.Equal(t, "AgBy3i4OJSWK", original, "the original object is not modified")
+}
+
+func TestMaskSecretData_InvalidSealedSecret(t *testing.T) {
+	_, err := NewMasker().MaskSecretData(sealedSecret(map[string]any{"password": int64(42)}))
+	assert.ErrorContains(t, err, "invalid encryptedData field for SealedSecret default/db: key 'password' has non-string value of type int64")
+}
+
+func TestAuditRecords_SealedSecret(t *testing.T) {
+	records := AuditRecords(sealedSecret(map[string]any{"password": "AgBy3i4OJSWK"}), "head")
+	require.Len(t, records, 1)
+	assert.Equal(t, "SealedSecret", records[0].Kind)
+	assert.Equal(t, "encryptedData", records[0].Field)
+	assert.Equal(t, "password", records[0].Key)
+}
@@ -2,8 +2,11 @@
 package masking
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
@@ -11,19 +14,109 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+const (
+	// DefaultMaskToken is the token incremental masks repeat
+	DefaultMaskToken = "+"
+	// DefaultMaskMinLength is the length of the first incremental mask
+	DefaultMaskMinLength = 16
+)
+
+// encryptedValue matches a value encrypted by SOPS, e.g. "ENC[AES256_GCM,data:...,type:str]", or the "ENC[str]"
+// it is normalized to before comparison
+var encryptedValue = regexp.MustCompile(`^ENC\[[^\]]*\]$`)
+
+// IsEncryptedValue reports whether a Secret value is encrypted by SOPS. Such values are neither base64 nor
+// plaintext secrets, so they are not validated as base64 or masked.
+func IsEncryptedValue(value string) bool {
+	return encryptedValue.MatchString(value)
+}
+
 // Masker manages secret masking state and provides consistent value masking
 type Masker struct {
 	mu                 sync.RWMutex
+	strategy           Strategy
 	valueToReplacement map[string]string
 	currentReplacement string
+	bucketCounts       map[string]int
+	hashKey            []byte
+	token              string
+	minLength          int
 }
 
-// NewMasker creates a new Masker instance with fresh state
+// NewMasker creates a new Masker instance with fresh state using the incremental strategy
 func NewMasker() *Masker {
 	return &Masker{
+		strategy:           StrategyIncremental,
 		valueToReplacement: make(map[string]string),
-		currentReplacement: "++++++++++++++++",
+		currentReplacement: firstIncrementalMask(DefaultMaskToken, DefaultMaskMinLength),
+		bucketCounts:       make(map[string]int),
+		token:              DefaultMaskToken,
+		minLength:          DefaultMaskMinLength,
+	}
+}
+
+// NewMaskerWithStrategy creates a new Masker instance with fresh state using the given strategy
+func NewMaskerWithStrategy(strategy Strategy) (*Masker, error) {
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	m := NewMasker()
+	if strategy != "" {
+		m.strategy = strategy
 	}
+	return m, nil
+}
+
+// NewMaskerWithKey creates a new Masker instance like NewMaskerWithStrategy whose hash strategy masks are keyed
+// by key. Without a secret key, anyone can confirm a guess of a masked value by hashing it.
+func NewMaskerWithKey(strategy Strategy, key []byte) (*Masker, error) {
+	m, err := NewMaskerWithStrategy(strategy)
+	if err != nil {
+		return nil, err
+	}
+	m.hashKey = key
+	return m, nil
+}
+
+// NewMaskerWithToken creates a new Masker instance like NewMaskerWithKey whose incremental masks repeat token
+// instead of "+": the first masked value gets token repeated to at least minLength characters, and each further
+// distinct value one token more. The empty token and a minLength of 0 select DefaultMaskToken and
+// DefaultMaskMinLength, e.g. a token "*" with minLength 3 masks values as "***", "****", ...
+func NewMaskerWithToken(strategy Strategy, key []byte, token string, minLength int) (*Masker, error) {
+	if err := ValidateMaskToken(token, minLength); err != nil {
+		return nil, err
+	}
+	m, err := NewMaskerWithKey(strategy, key)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		m.token = token
+	}
+	if minLength != 0 {
+		m.minLength = minLength
+	}
+	m.currentReplacement = firstIncrementalMask(m.token, m.minLength)
+	return m, nil
+}
+
+// ValidateMaskToken returns an error if incremental masks cannot be built from token and minLength:
+// the token must fit on one line and the minimum length must not be negative
+func ValidateMaskToken(token string, minLength int) error {
+	if strings.ContainsAny(token, "\r\n") {
+		return fmt.Errorf("invalid mask token %q: must not contain line breaks", token)
+	}
+	if minLength < 0 {
+		return fmt.Errorf("invalid mask minimum length %d: must not be negative", minLength)
+	}
+	return nil
+}
+
+// firstIncrementalMask returns the mask of the first value under the incremental strategy: token repeated to at
+// least minLength characters, and at least once
+func firstIncrementalMask(token string, minLength int) string {
+	count := (minLength + len(token) - 1) / len(token)
+	return strings.Repeat(token, max(count, 1))
 }
 
 // Global default masker for backward compatibility
@@ -34,6 +127,28 @@ func IsSecret(obj *unstructured.Unstructured) bool {
 	return obj != nil && obj.GetKind() == "Secret"
 }
 
+// HasSecretValues checks if the unstructured object has values masked by MaskSecretData,
+// i.e. is a Secret, SealedSecret or ExternalSecret
+func HasSecretValues(obj *unstructured.Unstructured) bool {
+	return len(SecretValuePaths(obj)) > 0
+}
+
+// SecretValuePaths returns the paths of the maps whose values MaskSecretData masks in obj: data and stringData
+// of a Secret, encryptedData of a SealedSecret or the target template data of an ExternalSecret.
+// It returns nil for other objects.
+func SecretValuePaths(obj *unstructured.Unstructured) [][]string {
+	switch {
+	case IsSecret(obj):
+		return [][]string{{"data"}, {"stringData"}}
+	case IsSealedSecret(obj):
+		return [][]string{sealedSecretDataPath}
+	case IsExternalSecret(obj):
+		return [][]string{externalSecretTemplateDataPath}
+	default:
+		return nil
+	}
+}
+
 // ValidateSecret validates that the Secret object conforms to Kubernetes Secret specification
 // It ensures that both 'data' and 'stringData' fields contain only string values as required by K8s API
 func ValidateSecret(obj *unstructured.Unstructured) (err error) {
@@ -85,10 +200,20 @@ func ValidateSecret(obj *unstructured.Unstructured) (err error) {
 		}
 	}
 
-	// Additional validation: try to convert to structured Secret to catch other issues
-	// This uses a simpler approach that doesn't rely on encoding/decoding
+	// Additional validation: try to convert to structured Secret to catch other issues.
+	// Encrypted data values are not base64, so they are left out of the conversion.
+	converted := obj
+	if dataMap, found, _ := unstructured.NestedMap(obj.Object, "data"); found {
+		for key, value := range dataMap {
+			if IsEncryptedValue(value.(string)) {
+				delete(dataMap, key)
+			}
+		}
+		converted = obj.DeepCopy()
+		_ = unstructured.SetNestedMap(converted.Object, dataMap, "data")
+	}
 	secret := &corev1.Secret{}
-	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, secret); err != nil {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(converted.Object, secret); err != nil {
 		return fmt.Errorf("failed to convert Secret %s to structured format: %w", secretIdentifier, err)
 	}
 
@@ -97,6 +222,20 @@ func ValidateSecret(obj *unstructured.Unstructured) (err error) {
 
 // MaskSecretData creates a masked copy of the Secret object using the Masker instance
 func (m *Masker) MaskSecretData(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return m.MaskSecretDataWithPolicies(obj, nil)
+}
+
+// MaskSecretDataWithPolicies creates a masked copy of the Secret object, masking values
+// according to the policy for the Secret's type. A nil table masks every value.
+// The encryptedData of SealedSecrets and literal template data of ExternalSecrets are masked like Secret data
+// regardless of the policies.
+func (m *Masker) MaskSecretDataWithPolicies(obj *unstructured.Unstructured, policies PolicyTable) (*unstructured.Unstructured, error) {
+	if IsSealedSecret(obj) {
+		return m.maskSealedSecretData(obj)
+	}
+	if IsExternalSecret(obj) {
+		return m.maskExternalSecretData(obj)
+	}
 	if obj == nil || !IsSecret(obj) {
 		return obj, nil
 	}
@@ -108,14 +247,19 @@ func (m *Masker) MaskSecretData(obj *unstructured.Unstructured) (*unstructured.U
 
 	// Create a deep copy to avoid modifying the original
 	masked := obj.DeepCopy()
+	secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+	policy := policies.For(secretType)
 
 	// Process data field (base64 encoded values)
 	if dataMap, found, _ := unstructured.NestedMap(masked.Object, "data"); found {
 		for key, value := range dataMap {
-			if strValue, ok := value.(string); ok {
-				// Mask each value uniquely but consistently
-				maskedValue := m.MaskValue(strValue)
-				dataMap[key] = maskedValue
+			if strValue, ok := value.(string); ok && !IsEncryptedValue(strValue) {
+				// Mask each value uniquely but consistently, sized by its decoded length
+				decoded, err := base64.StdEncoding.DecodeString(strValue)
+				if err != nil {
+					decoded = []byte(strValue)
+				}
+				dataMap[key] = m.maskPolicyValue(strValue, decoded, policy)
 			}
 		}
 		if err := unstructured.SetNestedMap(masked.Object, dataMap, "data"); err != nil {
@@ -127,10 +271,9 @@ func (m *Masker) MaskSecretData(obj *unstructured.Unstructured) (*unstructured.U
 	// Process stringData field (plain text values)
 	if stringDataMap, found, _ := unstructured.NestedMap(masked.Object, "stringData"); found {
 		for key, value := range stringDataMap {
-			if strValue, ok := value.(string); ok {
+			if strValue, ok := value.(string); ok && !IsEncryptedValue(strValue) {
 				// Mask plain text values directly
-				maskedValue := m.MaskValue(strValue)
-				stringDataMap[key] = maskedValue
+				stringDataMap[key] = m.maskPolicyValue(strValue, []byte(strValue), policy)
 			}
 		}
 		if err := unstructured.SetNestedMap(masked.Object, stringDataMap, "stringData"); err != nil {
@@ -142,6 +285,25 @@ func (m *Masker) MaskSecretData(obj *unstructured.Unstructured) (*unstructured.U
 	return masked, nil
 }
 
+// maskPolicyValue returns the rendering of a Secret value under the policy, where decoded is the plain value.
+// Private keys and other blocks bundled with certificates are masked, so that their changes still show.
+func (m *Masker) maskPolicyValue(value string, decoded []byte, policy Policy) string {
+	if policy == PolicyCertificateMetadata {
+		if summary, ok := certificateSummary(decoded); ok {
+			if types, blocks := OtherPEMBlocks(decoded); len(blocks) > 0 {
+				summary += fmt.Sprintf(" <%s %s>", strings.Join(types, ","), m.maskValue(string(blocks), len(blocks)))
+			}
+			return summary
+		}
+	}
+	return m.maskValue(value, len(decoded))
+}
+
+// MaskSecretDataWithPolicies creates a masked copy of the Secret object using the default masker and policy table
+func MaskSecretDataWithPolicies(obj *unstructured.Unstructured, policies PolicyTable) (*unstructured.Unstructured, error) {
+	return defaultMasker.MaskSecretDataWithPolicies(obj, policies)
+}
+
 // MaskSecretData creates a masked copy of the Secret object using the default masker
 // Implementation based on ArgoCD gitops-engine's secret masking approach:
 // https://github.com/argoproj/gitops-engine/blob/v0.6.2/pkg/diff/diff.go
@@ -150,8 +312,13 @@ func MaskSecretData(obj *unstructured.Unstructured) (*unstructured.Unstructured,
 }
 
 // MaskValue returns a consistent mask for the same input value using the Masker instance
-// Same values get identical masks, different values get different length masks
+// Same values get identical masks, different values get different masks
 func (m *Masker) MaskValue(value string) string {
+	return m.maskValue(value, len(value))
+}
+
+// maskValue returns a consistent mask for the value, where size is the length of the value in bytes
+func (m *Masker) maskValue(value string, size int) string {
 	m.mu.RLock()
 	if replacement, exists := m.valueToReplacement[value]; exists {
 		m.mu.RUnlock()
@@ -167,10 +334,21 @@ func (m *Masker) MaskValue(value string) string {
 		return replacement
 	}
 
+	if m.strategy == StrategyLength {
+		replacement := m.lengthReplacement(size)
+		m.valueToReplacement[value] = replacement
+		return replacement
+	}
+	if m.strategy == StrategyHash {
+		replacement := m.hashReplacement(value)
+		m.valueToReplacement[value] = replacement
+		return replacement
+	}
+
 	// Create new replacement for this value
 	currentReplacement := m.currentReplacement
 	m.valueToReplacement[value] = currentReplacement
-	m.currentReplacement = m.currentReplacement + "+"
+	m.currentReplacement = m.currentReplacement + m.token
 
 	return currentReplacement
 }
@@ -180,7 +358,8 @@ func (m *Masker) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.valueToReplacement = make(map[string]string)
-	m.currentReplacement = "++++++++++++++++"
+	m.currentReplacement = firstIncrementalMask(m.token, m.minLength)
+	m.bucketCounts = make(map[string]int)
 }
 
 // MaskValue returns a consistent mask for the same input value using the default masker
@@ -304,6 +304,33 @@ func TestMaskSecretDataEdgeCases(t *testing.T) {
 	}
 }
 
+func TestMaskSecretDataSOPSEncrypted(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "db", "namespace": "default"},
+		"data": map[string]any{
+			"password": "ENC[AES256_GCM,data:Y2lwaGVy,iv:aXY=,tag:dGFn,type:str]",
+			"username": "YWRtaW4=",
+		},
+		"stringData": map[string]any{"token": "ENC[str]"},
+	}}
+
+	assert.NoError(t, ValidateSecret(obj))
+	masked, err := NewMasker().MaskSecretData(obj)
+	assert.NoError(t, err)
+	data, _, _ := unstructured.NestedStringMap(masked.Object, "data")
+	assert.Equal(t, "ENC[AES256_GCM,data:Y2lwaGVy,iv:aXY=,tag:dGFn,type:str]", data["password"])
+	assert.Equal(t, "++++++++++++++++", data["username"])
+	stringData, _, _ := unstructured.NestedStringMap(masked.Object, "stringData")
+	assert.Equal(t, "ENC[str]", stringData["token"])
+
+	records := AuditRecords(obj, "head")
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "username", records[0].Key)
+	}
+}
+
 func TestResetMaskingState(t *testing.T) {
 	// Add some values to the default masker state
 	MaskValue("test1")
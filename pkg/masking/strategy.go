@@ -0,0 +1,132 @@
+package masking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// Strategy determines how masked values are rendered
+type Strategy string
+
+const (
+	// StrategyIncremental renders masks as runs of "+" that grow by one for each distinct value (default).
+	// The token and the length of the first mask can be changed, see NewMaskerWithToken.
+	StrategyIncremental Strategy = "incremental"
+	// StrategyLength renders masks as "<masked, 9-16 bytes>" using power-of-two length buckets.
+	// Distinct values within the same bucket are numbered, e.g. "<masked (2), 9-16 bytes>".
+	StrategyLength Strategy = "length"
+	// StrategyHash renders masks as "<masked:1a2b3c4d5e6f>" derived from a keyed hash of the value, so masks do not
+	// depend on the order values are masked in and are the same across runs using the same key
+	StrategyHash Strategy = "hash"
+)
+
+// hashMaskLength is the number of hex digits of the keyed hash rendered by the hash strategy
+const hashMaskLength = 12
+
+// minLengthBucket is the upper bound of the smallest length bucket
+const minLengthBucket = 8
+
+// defaultMaskerKey identifies a process-wide masker
+type defaultMaskerKey struct {
+	strategy  Strategy
+	token     string
+	minLength int
+}
+
+var (
+	defaultMaskersMu sync.Mutex
+	defaultMaskers   = map[defaultMaskerKey]*Masker{}
+)
+
+// Validate returns an error if the strategy is not supported. The empty strategy means incremental.
+func (s Strategy) Validate() error {
+	switch s {
+	case "", StrategyIncremental, StrategyLength, StrategyHash:
+		return nil
+	default:
+		return fmt.Errorf("invalid mask strategy: %s (supported: %s, %s, %s)", s, StrategyIncremental, StrategyLength, StrategyHash)
+	}
+}
+
+// DefaultMaskerFor returns the process-wide masker for the strategy.
+// The incremental strategy shares state with MaskSecretData and MaskValue.
+func DefaultMaskerFor(strategy Strategy) (*Masker, error) {
+	return DefaultMaskerWithToken(strategy, "", 0)
+}
+
+// DefaultMaskerWithToken returns the process-wide masker for the strategy whose incremental masks are built from
+// token and minLength, see NewMaskerWithToken. With the default token and length, the incremental strategy shares
+// state with MaskSecretData and MaskValue.
+func DefaultMaskerWithToken(strategy Strategy, token string, minLength int) (*Masker, error) {
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateMaskToken(token, minLength); err != nil {
+		return nil, err
+	}
+	if token == DefaultMaskToken {
+		token = ""
+	}
+	if minLength == DefaultMaskMinLength {
+		minLength = 0
+	}
+	if strategy == "" {
+		strategy = StrategyIncremental
+	}
+	if strategy == StrategyIncremental && token == "" && minLength == 0 {
+		return defaultMasker, nil
+	}
+	if strategy != StrategyIncremental {
+		// Only incremental masks depend on the token
+		token, minLength = "", 0
+	}
+
+	defaultMaskersMu.Lock()
+	defer defaultMaskersMu.Unlock()
+	key := defaultMaskerKey{strategy: strategy, token: token, minLength: minLength}
+	if m, ok := defaultMaskers[key]; ok {
+		return m, nil
+	}
+	m, err := NewMaskerWithToken(strategy, nil, token, minLength)
+	if err != nil {
+		return nil, err
+	}
+	defaultMaskers[key] = m
+	return m, nil
+}
+
+// lengthReplacement returns the next mask for a value of the given size. Callers must hold the write lock.
+func (m *Masker) lengthReplacement(size int) string {
+	bucket := lengthBucket(size)
+	m.bucketCounts[bucket]++
+	if count := m.bucketCounts[bucket]; count > 1 {
+		return fmt.Sprintf("<masked (%d), %s>", count, bucket)
+	}
+	return fmt.Sprintf("<masked, %s>", bucket)
+}
+
+// hashReplacement returns the mask of a value under the hash strategy: a truncated HMAC-SHA256 of the value
+func (m *Masker) hashReplacement(value string) string {
+	mac := hmac.New(sha256.New, m.hashKey)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("<masked:%s>", hex.EncodeToString(mac.Sum(nil))[:hashMaskLength])
+}
+
+// lengthBucket returns the power-of-two length range containing size
+func lengthBucket(size int) string {
+	if size <= 0 {
+		return "0 bytes"
+	}
+	upper := minLengthBucket
+	for size > upper {
+		upper *= 2
+	}
+	lower := 1
+	if upper > minLengthBucket {
+		lower = upper/2 + 1
+	}
+	return fmt.Sprintf("%d-%d bytes", lower, upper)
+}
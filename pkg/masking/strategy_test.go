@@ -0,0 +1,169 @@
+package masking
+
+// gitleaks:ignore-file
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestLengthBucket(t *testing.T) {
+	tests := []struct {
+		size     int
+		expected string
+	}{
+		{size: 0, expected: "0 bytes"},
+		{size: 1, expected: "1-8 bytes"},
+		{size: 8, expected: "1-8 bytes"},
+		{size: 9, expected: "9-16 bytes"},
+		{size: 16, expected: "9-16 bytes"},
+		{size: 17, expected: "17-32 bytes"},
+		{size: 100, expected: "65-128 bytes"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, lengthBucket(tt.size), "size %d", tt.size)
+	}
+}
+
+func TestStrategyValidate(t *testing.T) {
+	assert.NoError(t, Strategy("").Validate())
+	assert.NoError(t, StrategyIncremental.Validate())
+	assert.NoError(t, StrategyLength.Validate())
+	assert.NoError(t, StrategyHash.Validate())
+	assert.Error(t, Strategy("random").Validate())
+
+	_, err := NewMaskerWithStrategy("random")
+	assert.Error(t, err)
+}
+
+func TestHashStrategyMaskValue(t *testing.T) {
+	m, err := NewMaskerWithKey(StrategyHash, []byte("key"))
+	require.NoError(t, err)
+
+	first := m.MaskValue("password")
+	assert.Regexp(t, `^<masked:[0-9a-f]{12}>$`, first)
+	assert.Equal(t, first, m.MaskValue("password"), "Same value should get the same mask")
+	assert.NotEqual(t, first, m.MaskValue("another"))
+
+	// Masks do not depend on the order values are masked in, nor on the masker
+	other, err := NewMaskerWithKey(StrategyHash, []byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, m.MaskValue("another"), other.MaskValue("another"))
+	assert.Equal(t, first, other.MaskValue("password"))
+
+	differentKey, err := NewMaskerWithKey(StrategyHash, []byte("other-key"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, differentKey.MaskValue("password"))
+}
+
+func TestLengthStrategyMaskValue(t *testing.T) {
+	m, err := NewMaskerWithStrategy(StrategyLength)
+	require.NoError(t, err)
+
+	first := m.MaskValue("password")
+	assert.Equal(t, "<masked, 1-8 bytes>", first)
+	assert.Equal(t, first, m.MaskValue("password"), "Same value should get the same mask")
+	assert.Equal(t, "<masked (2), 1-8 bytes>", m.MaskValue("another"), "Distinct values in the same bucket should be distinguishable")
+	assert.Equal(t, "<masked, 17-32 bytes>", m.MaskValue("a-much-longer-secret-value"))
+
+	m.Reset()
+	assert.Equal(t, "<masked, 1-8 bytes>", m.MaskValue("another"))
+}
+
+func TestLengthStrategyMaskSecretData(t *testing.T) {
+	m, err := NewMaskerWithStrategy(StrategyLength)
+	require.NoError(t, err)
+
+	secret := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": "test", "namespace": "default"},
+			"data": map[string]any{
+				"password": "bXlwYXNzd29yZA==", // "mypassword", 10 bytes decoded
+			},
+			"stringData": map[string]any{
+				"token": "plain",
+			},
+		},
+	}
+
+	masked, err := m.MaskSecretData(secret)
+	require.NoError(t, err)
+
+	data, _, _ := unstructured.NestedStringMap(masked.Object, "data")
+	assert.Equal(t, "<masked, 9-16 bytes>", data["password"], "Data values should be sized by their decoded length")
+	stringData, _, _ := unstructured.NestedStringMap(masked.Object, "stringData")
+	assert.Equal(t, "<masked, 1-8 bytes>", stringData["token"])
+}
+
+func TestDefaultMaskerFor(t *testing.T) {
+	m, err := DefaultMaskerFor(StrategyIncremental)
+	require.NoError(t, err)
+	assert.Same(t, defaultMasker, m)
+
+	lengthMasker, err := DefaultMaskerFor(StrategyLength)
+	require.NoError(t, err)
+	again, err := DefaultMaskerFor(StrategyLength)
+	require.NoError(t, err)
+	assert.Same(t, lengthMasker, again)
+
+	_, err = DefaultMaskerFor("random")
+	assert.Error(t, err)
+}
+
+func TestNewMaskerWithToken(t *testing.T) {
+	t.Run("custom token and length", func(t *testing.T) {
+		m, err := NewMaskerWithToken(StrategyIncremental, nil, "*", 3)
+		require.NoError(t, err)
+		assert.Equal(t, "***", m.MaskValue("a"))
+		assert.Equal(t, "****", m.MaskValue("b"))
+		assert.Equal(t, "***", m.MaskValue("a"))
+
+		m.Reset()
+		assert.Equal(t, "***", m.MaskValue("b"))
+	})
+
+	t.Run("multi-character token is repeated to the minimum length", func(t *testing.T) {
+		m, err := NewMaskerWithToken(StrategyIncremental, nil, "[x]", 4)
+		require.NoError(t, err)
+		assert.Equal(t, "[x][x]", m.MaskValue("a"))
+		assert.Equal(t, "[x][x][x]", m.MaskValue("b"))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		m, err := NewMaskerWithToken(StrategyIncremental, nil, "", 0)
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("+", DefaultMaskMinLength), m.MaskValue("a"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewMaskerWithToken(StrategyIncremental, nil, "*\n", 3)
+		assert.ErrorContains(t, err, "must not contain line breaks")
+		_, err = NewMaskerWithToken(StrategyIncremental, nil, "*", -1)
+		assert.ErrorContains(t, err, "must not be negative")
+	})
+}
+
+func TestDefaultMaskerWithToken(t *testing.T) {
+	m, err := DefaultMaskerWithToken(StrategyIncremental, DefaultMaskToken, DefaultMaskMinLength)
+	require.NoError(t, err)
+	assert.Same(t, defaultMasker, m)
+
+	starMasker, err := DefaultMaskerWithToken(StrategyIncremental, "*", 3)
+	require.NoError(t, err)
+	again, err := DefaultMaskerWithToken(StrategyIncremental, "*", 3)
+	require.NoError(t, err)
+	assert.Same(t, starMasker, again)
+	assert.NotSame(t, defaultMasker, starMasker)
+
+	lengthMasker, err := DefaultMaskerFor(StrategyLength)
+	require.NoError(t, err)
+	tokenLengthMasker, err := DefaultMaskerWithToken(StrategyLength, "*", 3)
+	require.NoError(t, err)
+	assert.Same(t, lengthMasker, tokenLengthMasker, "the token does not apply to the length strategy")
+}
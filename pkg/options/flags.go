@@ -0,0 +1,237 @@
+// Package options builds library options from the flags of the k8s-manifest-diff commands, so tools embedding
+// the commands, or registering the same flags, construct options exactly as the CLI does.
+package options
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+)
+
+// FromFlags returns the diff options given by the flags of cmd, such as --context, --exclude-kinds and --label.
+// Flags cmd does not define keep their defaults. With --profile, the options start from the profile, see
+// diff.Profile, and only flags that are set override it. Flags reading config files (--severity-config, --owners-config,
+// --conversion-config, --workload-config, --masking-audit) and --output-format are left to the caller.
+func FromFlags(cmd *cobra.Command) (*diff.Options, error) {
+	f := newFlagValues(cmd)
+	opts, err := diff.Profile(f.profile)
+	if err != nil {
+		return nil, err
+	}
+	filterOption, err := FilterFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	secretPolicies, err := masking.ParsePolicyTable(f.strings("secret-policy"))
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := nameMappings(f.strings("strip-name-prefix"), f.strings("strip-name-suffix"), f.strings("map-name-regex"))
+	if err != nil {
+		return nil, err
+	}
+	tracking, err := pruneTracking(f.strings("prune-tracking"))
+	if err != nil {
+		return nil, err
+	}
+
+	opts.WithFilterOption(filterOption).
+		WithContext(f.int("context", opts.Context)).
+		WithDisableMaskingSecrets(f.bool("disable-masking-secret", opts.DisableMaskingSecrets)).
+		WithDisableMaskingFor(f.strings("disable-masking-for")...).
+		WithSecretPolicies(secretPolicies).
+		WithCacheDir(f.string("cache-dir", opts.CacheDir)).
+		WithMinimumChangedLines(f.int("minimum-changed-lines", opts.MinimumChangedLines)).
+		WithPruneTracking(tracking).
+		WithMaskScope(diff.MaskScope(f.string("mask-scope", string(opts.MaskScope)))).
+		WithMaskStrategy(masking.Strategy(f.string("mask-strategy", string(opts.MaskStrategy)))).
+		WithMaskToken(f.string("mask-token", opts.MaskToken)).
+		WithMaskMinLength(f.int("mask-min-length", opts.MaskMinLength)).
+		WithOnly(f.strings("only")...).
+		WithUseLastApplied(f.bool("last-applied", opts.UseLastApplied)).
+		WithFieldManager(f.string("field-manager", opts.FieldManager)).
+		WithExpandBelowLines(f.int("expand-below-lines", opts.ExpandBelowLines)).
+		WithFullObjects(diff.FullObjectsMode(f.string("full-objects", string(opts.FullObjects)))).
+		WithUnnamedMatching(diff.UnnamedMatching(f.string("unnamed-matching", string(opts.UnnamedMatching)))).
+		WithRenameThreshold(f.float64("rename-threshold", opts.RenameThreshold)).
+		WithNameMappings(mappings...).
+		WithIgnoreEOL(f.bool("ignore-eol", opts.IgnoreEOL)).
+		WithIgnoreValueRegexes(f.strings("ignore-value-regex")...).
+		WithSummarizeStatus(f.bool("summarize-status", opts.SummarizeStatus)).
+		WithSummarizeSealedSecrets(f.bool("summarize-sealed-secrets", opts.SummarizeSealedSecrets)).
+		WithContinueOnError(f.bool("continue-on-error", opts.ContinueOnError)).
+		WithFailFast(f.bool("fail-fast", opts.FailFast))
+	if f.bool("normalize-known-kinds", opts.KindNormalizers != nil) {
+		opts.WithKindNormalizers(diff.DefaultKindNormalizers())
+	} else {
+		opts.WithKindNormalizers(nil)
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return opts, nil
+}
+
+// FilterFromFlags returns the filter options given by the flags of cmd: --exclude-kinds, --label, --annotation,
+// --exclude-label, --exclude-annotation, --filter-expr and --disable-ignore-annotation. Negated selectors
+// (key!=value) of --label and --annotation exclude resources. Flags cmd does not define are not applied, and with
+// --profile, --disable-ignore-annotation defaults to the profile's.
+func FilterFromFlags(cmd *cobra.Command) (*filter.Option, error) {
+	f := newFlagValues(cmd)
+	profile, err := diff.Profile(f.profile)
+	if err != nil {
+		return nil, err
+	}
+	labels, annotations := f.strings("label"), f.strings("annotation")
+	option := &filter.Option{
+		ExcludeKinds:              f.strings("exclude-kinds"),
+		LabelSelector:             matchingSelectors(labels),
+		AnnotationSelector:        matchingSelectors(annotations),
+		ExcludeLabelSelector:      exclusions(f.strings("exclude-label"), labels),
+		ExcludeAnnotationSelector: exclusions(f.strings("exclude-annotation"), annotations),
+		DisableIgnoreAnnotation:   f.bool("disable-ignore-annotation", profile.FilterOption.DisableIgnoreAnnotation),
+	}
+	if expr := f.string("filter-expr", ""); expr != "" {
+		expression, err := filter.NewExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+		option.Expression = expression
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return option, nil
+}
+
+// matchingSelectors converts key=value selector flags into a map, see filter.ParseSelectors
+func matchingSelectors(selectors []string) map[string]string {
+	matching, _ := filter.ParseSelectors(selectors)
+	return matching
+}
+
+// exclusions returns the selectors of exclusion flags together with the negated (key!=value) entries of selector flags
+func exclusions(exclusions, selectors []string) map[string][]string {
+	excluded := filter.ParseExclusions(exclusions)
+	_, negated := filter.ParseSelectors(selectors)
+	for key, values := range negated {
+		excluded[key] = append(excluded[key], values...)
+	}
+	return excluded
+}
+
+// nameMappings returns the name mappings given by --strip-name-prefix, --strip-name-suffix and --map-name-regex, in that order
+func nameMappings(prefixes, suffixes, expressions []string) ([]diff.NameMapping, error) {
+	var mappings []diff.NameMapping
+	for _, prefix := range prefixes {
+		mappings = append(mappings, diff.StripNamePrefix(prefix))
+	}
+	for _, suffix := range suffixes {
+		mappings = append(mappings, diff.StripNameSuffix(suffix))
+	}
+	for _, expr := range expressions {
+		mapping, err := diff.ParseNameMapping(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --map-name-regex: %w", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// pruneTracking parses --prune-tracking selectors, keeping every value of a repeated key. Negated selectors
+// (key!=value) cannot mark a resource as tracked and are rejected.
+func pruneTracking(selectors []string) (map[string][]string, error) {
+	for _, selector := range selectors {
+		if strings.Contains(selector, "!=") {
+			return nil, fmt.Errorf("invalid --prune-tracking %q: negated selectors (key!=value) are not supported", selector)
+		}
+	}
+	return filter.ParseExclusions(selectors), nil
+}
+
+// flagValues reads the flags of a command by name, returning a default for flags it does not define, or that are
+// not set when a profile provides the defaults. The first error, a flag of an unexpected type, is kept in err.
+type flagValues struct {
+	cmd     *cobra.Command
+	profile string // Profile given by --profile (none when empty)
+	err     error
+}
+
+// newFlagValues returns the flag values of cmd, reading --profile
+func newFlagValues(cmd *cobra.Command) *flagValues {
+	f := &flagValues{cmd: cmd}
+	f.profile = f.string("profile", "")
+	return f
+}
+
+// defined reports whether the value of the flag is read: the command defines it, and it is set if a profile
+// provides the defaults
+func (f *flagValues) defined(name string) bool {
+	flag := f.cmd.Flags().Lookup(name)
+	return flag != nil && (f.profile == "" || flag.Changed)
+}
+
+// record keeps the first error reading a flag
+func (f *flagValues) record(name string, err error) {
+	if err != nil && f.err == nil {
+		f.err = fmt.Errorf("failed to read --%s: %w", name, err)
+	}
+}
+
+func (f *flagValues) string(name, fallback string) string {
+	if !f.defined(name) {
+		return fallback
+	}
+	value, err := f.cmd.Flags().GetString(name)
+	f.record(name, err)
+	return value
+}
+
+func (f *flagValues) bool(name string, fallback bool) bool {
+	if !f.defined(name) {
+		return fallback
+	}
+	value, err := f.cmd.Flags().GetBool(name)
+	f.record(name, err)
+	return value
+}
+
+func (f *flagValues) int(name string, fallback int) int {
+	if !f.defined(name) {
+		return fallback
+	}
+	value, err := f.cmd.Flags().GetInt(name)
+	f.record(name, err)
+	return value
+}
+
+func (f *flagValues) float64(name string, fallback float64) float64 {
+	if !f.defined(name) {
+		return fallback
+	}
+	value, err := f.cmd.Flags().GetFloat64(name)
+	f.record(name, err)
+	return value
+}
+
+// strings reads a string slice flag, or a string array flag such as --map-name-regex whose values may contain commas
+func (f *flagValues) strings(name string) []string {
+	if !f.defined(name) {
+		return nil
+	}
+	flag := f.cmd.Flags().Lookup(name)
+	var value []string
+	var err error
+	if flag.Value.Type() == "stringArray" {
+		value, err = f.cmd.Flags().GetStringArray(name)
+	} else {
+		value, err = f.cmd.Flags().GetStringSlice(name)
+	}
+	f.record(name, err)
+	return value
+}
@@ -0,0 +1,139 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+)
+
+// newCommand returns a command defining a subset of the diff command flags, parsed from args
+func newCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringSlice("exclude-kinds", []string{}, "")
+	cmd.Flags().StringSlice("label", []string{}, "")
+	cmd.Flags().StringSlice("exclude-label", []string{}, "")
+	cmd.Flags().StringSlice("exclude-annotation", []string{}, "")
+	cmd.Flags().Int("context", 3, "")
+	cmd.Flags().String("mask-strategy", "incremental", "")
+	cmd.Flags().StringArray("map-name-regex", []string{}, "")
+	cmd.Flags().Bool("normalize-known-kinds", false, "")
+	cmd.Flags().Bool("ignore-eol", false, "")
+	cmd.Flags().StringArray("ignore-value-regex", []string{}, "")
+	cmd.Flags().Bool("fail-fast", false, "")
+	cmd.Flags().StringSlice("prune-tracking", []string{}, "")
+	cmd.Flags().String("profile", "", "")
+	cmd.Flags().Bool("disable-ignore-annotation", false, "")
+	cmd.Flags().Bool("continue-on-error", false, "")
+	require.NoError(t, cmd.ParseFlags(args))
+	return cmd
+}
+
+func TestFromFlags(t *testing.T) {
+	cmd := newCommand(t,
+		"--context", "5",
+		"--exclude-kinds", "Secret,ConfigMap",
+		"--label", "app=web", "--label", "tier!=test", "--label", "tier!=dev",
+		"--exclude-label", "tier=canary",
+		"--exclude-annotation", "fluxcd.io/*",
+		"--mask-strategy", "length",
+		"--map-name-regex", "s/-v[0-9]+$//",
+		"--ignore-eol",
+		"--ignore-value-regex", `^build-\d{1,6}$`,
+		"--fail-fast",
+		"--prune-tracking", "argocd.argoproj.io/tracking-id=web:*",
+		"--prune-tracking", "app.kubernetes.io/instance=web",
+		"--prune-tracking", "app.kubernetes.io/instance=api",
+	)
+
+	opts, err := FromFlags(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, 5, opts.Context)
+	assert.Equal(t, []string{"Secret", "ConfigMap"}, opts.FilterOption.ExcludeKinds)
+	assert.Equal(t, map[string]string{"app": "web"}, opts.FilterOption.LabelSelector)
+	assert.Equal(t, map[string][]string{"tier": {"canary", "test", "dev"}}, opts.FilterOption.ExcludeLabelSelector)
+	assert.Equal(t, map[string][]string{"fluxcd.io/*": {"*"}}, opts.FilterOption.ExcludeAnnotationSelector)
+	assert.Equal(t, masking.StrategyLength, opts.MaskStrategy)
+	assert.Len(t, opts.NameMappings, 1)
+	assert.Nil(t, opts.KindNormalizers)
+	assert.True(t, opts.IgnoreEOL)
+	assert.Equal(t, []string{`^build-\d{1,6}$`}, opts.IgnoreValueRegexes)
+	assert.True(t, opts.FailFast)
+	assert.Equal(t, map[string][]string{
+		"argocd.argoproj.io/tracking-id": {"web:*"},
+		"app.kubernetes.io/instance":     {"web", "api"},
+	}, opts.PruneTracking)
+}
+
+func TestFromFlags_NegatedPruneTracking(t *testing.T) {
+	_, err := FromFlags(newCommand(t, "--prune-tracking", "app.kubernetes.io/instance!=web"))
+	assert.ErrorContains(t, err, `invalid --prune-tracking "app.kubernetes.io/instance!=web": negated selectors (key!=value) are not supported`)
+}
+
+func TestFromFlags_UndefinedFlagsKeepDefaults(t *testing.T) {
+	opts, err := FromFlags(&cobra.Command{Use: "test"})
+	require.NoError(t, err)
+
+	defaults := diff.DefaultOptions()
+	assert.Equal(t, defaults.Context, opts.Context)
+	assert.Equal(t, defaults.MaskScope, opts.MaskScope)
+	assert.Equal(t, defaults.MaskStrategy, opts.MaskStrategy)
+	assert.Equal(t, defaults.UnnamedMatching, opts.UnnamedMatching)
+	assert.Nil(t, opts.FilterOption.Expression)
+}
+
+func TestFromFlags_Profile(t *testing.T) {
+	t.Run("profile defaults", func(t *testing.T) {
+		opts, err := FromFlags(newCommand(t, "--profile", diff.ProfileAudit))
+		require.NoError(t, err)
+		assert.True(t, opts.ContinueOnError)
+		assert.True(t, opts.FilterOption.DisableIgnoreAnnotation)
+		assert.Equal(t, diff.MaskScopeResource, opts.MaskScope)
+		assert.Equal(t, 3, opts.Context)
+	})
+
+	t.Run("set flags override the profile", func(t *testing.T) {
+		opts, err := FromFlags(newCommand(t, "--profile", diff.ProfileAudit, "--continue-on-error=false", "--disable-ignore-annotation=false", "--context", "1"))
+		require.NoError(t, err)
+		assert.False(t, opts.ContinueOnError)
+		assert.False(t, opts.FilterOption.DisableIgnoreAnnotation)
+		assert.Equal(t, 1, opts.Context)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := FromFlags(newCommand(t, "--profile", "lenient"))
+		assert.ErrorContains(t, err, `unknown profile "lenient" (audit|gitops|strict)`)
+	})
+}
+
+func TestFromFlags_Errors(t *testing.T) {
+	t.Run("invalid name mapping", func(t *testing.T) {
+		_, err := FromFlags(newCommand(t, "--map-name-regex", "s/(/x/"))
+		assert.ErrorContains(t, err, "invalid --map-name-regex")
+	})
+
+	t.Run("flag of another type", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().String("context", "3", "")
+		_, err := FromFlags(cmd)
+		assert.ErrorContains(t, err, "failed to read --context")
+	})
+}
+
+func TestFilterFromFlags_Expression(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("filter-expr", "", "")
+	require.NoError(t, cmd.ParseFlags([]string{"--filter-expr", "object.kind == 'Deployment'"}))
+
+	option, err := FilterFromFlags(cmd)
+	require.NoError(t, err)
+	assert.NotNil(t, option.Expression)
+
+	require.NoError(t, cmd.ParseFlags([]string{"--filter-expr", "object.kind =="}))
+	_, err = FilterFromFlags(cmd)
+	assert.Error(t, err)
+}
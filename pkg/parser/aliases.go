@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// AliasUseKind is the kind of YAML construct reported by FindAliases
+type AliasUseKind string
+
+const (
+	// AliasUseAnchor is an anchor (&name) defining a reusable node
+	AliasUseAnchor AliasUseKind = "anchor"
+	// AliasUseAlias is an alias (*name) referring to an anchored node
+	AliasUseAlias AliasUseKind = "alias"
+	// AliasUseMerge is a merge key (<<) merging mappings into the enclosing one
+	AliasUseMerge AliasUseKind = "merge"
+)
+
+// AliasUse is an anchor, alias or merge key found in a manifest.
+// ParseYAML resolves all of them, so the parsed objects contain the expanded values.
+type AliasUse struct {
+	Kind   AliasUseKind
+	Name   string // Anchor name; empty for merge keys
+	Line   int
+	Column int
+}
+
+// String returns a human-readable description of the use, e.g. "line 12: alias *defaults"
+func (u AliasUse) String() string {
+	switch u.Kind {
+	case AliasUseAnchor:
+		return fmt.Sprintf("line %d: anchor &%s", u.Line, u.Name)
+	case AliasUseAlias:
+		return fmt.Sprintf("line %d: alias *%s", u.Line, u.Name)
+	default:
+		return fmt.Sprintf("line %d: merge key <<", u.Line)
+	}
+}
+
+// FindAliases returns the anchors, aliases and merge keys used in a YAML stream, in the order they appear.
+// Manifests generated by some tools rely on them; FindAliases makes their use visible since the parsed objects don't.
+func FindAliases(reader io.Reader) ([]AliasUse, error) {
+	decoder := yamlv3.NewDecoder(reader)
+	var uses []AliasUse
+	for {
+		node := &yamlv3.Node{}
+		if err := decoder.Decode(node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to unmarshal manifest: %v", err)
+		}
+		uses = appendAliasUses(uses, node)
+	}
+	return uses, nil
+}
+
+// appendAliasUses appends the anchors, aliases and merge keys in node and its children to uses
+func appendAliasUses(uses []AliasUse, node *yamlv3.Node) []AliasUse {
+	if node.Anchor != "" {
+		uses = append(uses, AliasUse{Kind: AliasUseAnchor, Name: node.Anchor, Line: node.Line, Column: node.Column})
+	}
+	if node.Kind == yamlv3.AliasNode {
+		// The anchored node is reported where it is defined
+		return append(uses, AliasUse{Kind: AliasUseAlias, Name: node.Value, Line: node.Line, Column: node.Column})
+	}
+	for i, child := range node.Content {
+		if node.Kind == yamlv3.MappingNode && i%2 == 0 && isMergeKey(child) {
+			uses = append(uses, AliasUse{Kind: AliasUseMerge, Line: child.Line, Column: child.Column})
+			continue
+		}
+		uses = appendAliasUses(uses, child)
+	}
+	return uses
+}
+
+// isMergeKey reports whether a mapping key node is the merge key <<
+func isMergeKey(key *yamlv3.Node) bool {
+	return key.Kind == yamlv3.ScalarNode && key.ShortTag() == "!!merge"
+}
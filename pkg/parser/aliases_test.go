@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const aliasedManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: &labels
+    app: web
+    tier: frontend
+spec:
+  selector:
+    matchLabels: *labels
+  template:
+    metadata:
+      labels:
+        <<: *labels
+        version: v2
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        env:
+        - &debug
+          name: DEBUG
+          value: "false"
+        - *debug
+`
+
+func TestParseYAMLResolvesAliases(t *testing.T) {
+	objs, err := ParseYAML(strings.NewReader(aliasedManifest))
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+
+	selector, _, err := unstructured.NestedStringMap(objs[0].Object, "spec", "selector", "matchLabels")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web", "tier": "frontend"}, selector)
+
+	podLabels, _, err := unstructured.NestedStringMap(objs[0].Object, "spec", "template", "metadata", "labels")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web", "tier": "frontend", "version": "v2"}, podLabels)
+
+	containers, _, err := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	env := containers[0].(map[string]interface{})["env"].([]interface{})
+	require.Len(t, env, 2)
+	assert.Equal(t, env[0], env[1])
+}
+
+func TestFindAliases(t *testing.T) {
+	uses, err := FindAliases(strings.NewReader(aliasedManifest))
+	require.NoError(t, err)
+	assert.Equal(t, []AliasUse{
+		{Kind: AliasUseAnchor, Name: "labels", Line: 5, Column: 11},
+		{Kind: AliasUseAlias, Name: "labels", Line: 10, Column: 18},
+		{Kind: AliasUseMerge, Line: 14, Column: 9},
+		{Kind: AliasUseAlias, Name: "labels", Line: 14, Column: 13},
+		{Kind: AliasUseAnchor, Name: "debug", Line: 21, Column: 11},
+		{Kind: AliasUseAlias, Name: "debug", Line: 24, Column: 11},
+	}, uses)
+	assert.Equal(t, "line 14: merge key <<", uses[2].String())
+	assert.Equal(t, "line 10: alias *labels", uses[1].String())
+
+	uses, err = FindAliases(strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: plain\n"))
+	require.NoError(t, err)
+	assert.Empty(t, uses)
+
+	_, err = FindAliases(strings.NewReader("key: *undefined\n"))
+	assert.Error(t, err)
+}
+
+func TestYamlDocumentsMasksAliasedSecretData(t *testing.T) {
+	input := `apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  annotations: &shared
+    user: admin
+type: Opaque
+stringData:
+  <<: *shared
+  password: hunter2
+`
+	docs, err := YamlDocumentsString(input, DefaultOptions())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	output := docs.String()
+	assert.NotContains(t, output, "hunter2")
+	assert.NotContains(t, output, "<<")
+	// The anchored annotations are not secret values and stay as they are
+	assert.Contains(t, output, "annotations: &shared\n    user: admin")
+	stringData, _, err := unstructured.NestedStringMap(docs[0].Object.Object, "stringData")
+	require.NoError(t, err)
+	assert.Len(t, stringData, 2)
+	assert.NotEqual(t, "admin", stringData["user"])
+}
@@ -4,6 +4,7 @@ package parser
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
@@ -16,6 +17,7 @@ import (
 type Options struct {
 	FilterOption          *filter.Option // Filtering options
 	DisableMaskingSecrets bool           // Disable masking of secret values (default: false)
+	Limits                Limits         // Bounds on the size, documents, alias expansion and parse time of the input
 }
 
 // DefaultOptions returns the default parsing options
@@ -23,6 +25,7 @@ func DefaultOptions() *Options {
 	return &Options{
 		FilterOption:          filter.DefaultOption(),
 		DisableMaskingSecrets: false,
+		Limits:                DefaultLimits(),
 	}
 }
 
@@ -45,15 +48,17 @@ func (k ResourceKey) String() string {
 // Results represents a collection of resources
 type Results map[ResourceKey]*unstructured.Unstructured
 
-// String converts Results to YAML string representation
+// String converts Results to YAML, with documents sorted by kind, namespace and name so that the output is reproducible
 func (r Results) String() string {
 	if len(r) == 0 {
 		return ""
 	}
 
+	keys := r.sortedKeys()
+
 	// Create header with resource list as YAML comments
 	var resourceList []string
-	for key := range r {
+	for _, key := range keys {
 		if key.Namespace != "" {
 			resourceList = append(resourceList, fmt.Sprintf("# %s/%s %s/%s", key.Group, key.Kind, key.Namespace, key.Name))
 		} else {
@@ -63,17 +68,44 @@ func (r Results) String() string {
 	header := fmt.Sprintf("# Resources (%d)\n%s\n\n", len(r), strings.Join(resourceList, "\n"))
 
 	var yamlParts []string
-	for _, obj := range r {
-		yamlBytes, err := yaml.Marshal(obj.Object)
+	for _, key := range keys {
+		yamlBytes, err := yaml.Marshal(r[key].Object)
 		if err != nil {
 			// Return error information if marshaling fails
 			return fmt.Sprintf("Error marshaling object to YAML: %v", err)
 		}
-		yamlParts = append(yamlParts, strings.TrimSpace(string(yamlBytes)))
+		yamlParts = append(yamlParts, strings.TrimSpace(UnescapeUnicode(string(yamlBytes))))
 	}
 	return header + strings.Join(yamlParts, "\n---\n")
 }
 
+// sortedKeys returns the keys of the results sorted by kind, namespace, name and group
+func (r Results) sortedKeys() []ResourceKey {
+	keys := make([]ResourceKey, 0, len(r))
+	for key := range r {
+		keys = append(keys, key)
+	}
+	sortResourceKeys(keys)
+	return keys
+}
+
+// sortResourceKeys sorts keys by kind, namespace, name and group
+func sortResourceKeys(keys []ResourceKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Group < b.Group
+	})
+}
+
 // YamlString processes a YAML string and returns Results with optional masking
 func YamlString(yamlStr string, opts *Options) (Results, error) {
 	reader := strings.NewReader(yamlStr)
@@ -86,7 +118,7 @@ func Yaml(reader io.Reader, opts *Options) (Results, error) {
 		opts = DefaultOptions()
 	}
 
-	objects, err := ParseYAML(reader)
+	objects, err := ParseYAMLWithLimits(reader, opts.Limits)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
@@ -100,12 +132,18 @@ func Objects(objs []*unstructured.Unstructured, opts *Options) (Results, error)
 		opts = DefaultOptions()
 	}
 
+	if err := opts.FilterOption.Validate(); err != nil {
+		return nil, err
+	}
 	if objs == nil {
 		return make(Results), nil
 	}
 
 	// Apply filtering first
-	filteredObjs := filter.Resources(objs, opts.FilterOption)
+	filteredObjs, err := filter.Select(objs, opts.FilterOption)
+	if err != nil {
+		return nil, err
+	}
 
 	masker := masking.NewMasker()
 	results := make(Results)
@@ -120,7 +158,7 @@ func Objects(objs []*unstructured.Unstructured, opts *Options) (Results, error)
 		}
 
 		var processedObj *unstructured.Unstructured
-		if masking.IsSecret(obj) && !opts.DisableMaskingSecrets {
+		if masking.HasSecretValues(obj) && !opts.DisableMaskingSecrets {
 			maskedObj, err := masker.MaskSecretData(obj)
 			if err != nil {
 				return nil, fmt.Errorf("failed to mask secret: %w", err)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
 	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -940,3 +941,34 @@ data:
 		})
 	}
 }
+
+func TestResultsStringSorted(t *testing.T) {
+	input := `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: zeta
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: alpha
+  namespace: default
+`
+	result, err := YamlString(input, nil)
+	require.NoError(t, err)
+
+	expected := result.String()
+	assert.True(t, strings.HasPrefix(expected, "# Resources (3)\n# /ConfigMap default/alpha\n# /ConfigMap default/zeta\n# /Service default/web\n\n"))
+	alpha, zeta, web := strings.Index(expected, "name: alpha"), strings.Index(expected, "name: zeta"), strings.Index(expected, "name: web")
+	assert.True(t, alpha < zeta && zeta < web, "documents must be sorted by kind, namespace and name")
+	for range 10 {
+		assert.Equal(t, expected, result.String())
+	}
+}
@@ -0,0 +1,255 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Document is a parsed resource together with its source YAML, which retains comments and key order
+type Document struct {
+	Key    ResourceKey
+	Object *unstructured.Unstructured
+	node   *yamlv3.Node
+}
+
+// Documents represents resources in the order they appear in the input.
+// Unlike Results, rendering Documents keeps the comments and key order of the manifests.
+type Documents []Document
+
+// String converts Documents to YAML, preserving per-document comments and key order
+func (d Documents) String() string {
+	if len(d) == 0 {
+		return ""
+	}
+
+	var resourceList []string
+	for _, doc := range d {
+		key := doc.Key
+		if key.Namespace != "" {
+			resourceList = append(resourceList, fmt.Sprintf("# %s/%s %s/%s", key.Group, key.Kind, key.Namespace, key.Name))
+		} else {
+			resourceList = append(resourceList, fmt.Sprintf("# %s/%s %s", key.Group, key.Kind, key.Name))
+		}
+	}
+	header := fmt.Sprintf("# Resources (%d)\n%s\n\n", len(d), strings.Join(resourceList, "\n"))
+
+	var yamlParts []string
+	for _, doc := range d {
+		var buf bytes.Buffer
+		encoder := yamlv3.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc.node); err != nil {
+			return fmt.Sprintf("Error marshaling object to YAML: %v", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return fmt.Sprintf("Error marshaling object to YAML: %v", err)
+		}
+		yamlParts = append(yamlParts, strings.TrimSpace(UnescapeUnicode(buf.String())))
+	}
+	return header + strings.Join(yamlParts, "\n---\n")
+}
+
+// YamlDocumentsString processes a YAML string and returns Documents with optional masking
+func YamlDocumentsString(yamlStr string, opts *Options) (Documents, error) {
+	return YamlDocuments(strings.NewReader(yamlStr), opts)
+}
+
+// YamlDocuments processes YAML from an io.Reader and returns Documents with optional masking and filtering.
+// Masked Secret values replace the original values in place, so surrounding comments are kept.
+func YamlDocuments(reader io.Reader, opts *Options) (Documents, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+
+	if err := opts.FilterOption.Validate(); err != nil {
+		return nil, err
+	}
+	docs, err := withTimeout(opts.Limits.Timeout, func() ([]Document, error) {
+		return parseDocuments(reader, opts.Limits)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	objs := make([]*unstructured.Unstructured, 0, len(docs))
+	for _, doc := range docs {
+		objs = append(objs, doc.Object)
+	}
+	selected, err := filter.Select(objs, opts.FilterOption)
+	if err != nil {
+		return nil, err
+	}
+	kept := make(map[*unstructured.Unstructured]bool)
+	for _, obj := range selected {
+		kept[obj] = true
+	}
+
+	masker := masking.NewMasker()
+	results := make(Documents, 0, len(kept))
+	for _, doc := range docs {
+		if !kept[doc.Object] {
+			continue
+		}
+		if masking.HasSecretValues(doc.Object) && !opts.DisableMaskingSecrets {
+			maskedObj, err := masker.MaskSecretData(doc.Object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to mask secret: %w", err)
+			}
+			doc.Object = maskedObj
+			maskSecretNode(doc.node, maskedObj)
+		}
+		results = append(results, doc)
+	}
+	return results, nil
+}
+
+// parseDocuments decodes each YAML document into a node and an unstructured object.
+// Objects are decoded from the re-encoded node so that values are typed as by ParseYAML.
+func parseDocuments(reader io.Reader, limits Limits) ([]Document, error) {
+	data, err := normalizeLineEndings(reader, limits.MaxInputSize)
+	if err != nil {
+		return nil, err
+	}
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+	var docs []Document
+	for documents := 1; ; documents++ {
+		node := &yamlv3.Node{}
+		if err := decoder.Decode(node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to unmarshal manifest: %v", err)
+		}
+		if err := limits.checkDocuments(documents); err != nil {
+			return nil, err
+		}
+		if err := limits.checkNode(documents, node); err != nil {
+			return nil, err
+		}
+		if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		data, err := yamlv3.Marshal(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal manifest: %v", err)
+		}
+		// The node is within the limits already
+		objs, err := parseYAML(bytes.NewReader(data), Limits{})
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			docs = append(docs, Document{
+				Key: ResourceKey{
+					Name:      obj.GetName(),
+					Namespace: obj.GetNamespace(),
+					Group:     obj.GetObjectKind().GroupVersionKind().Group,
+					Kind:      obj.GetKind(),
+				},
+				Object: obj,
+				node:   node,
+			})
+		}
+	}
+	return docs, nil
+}
+
+// maskSecretNode replaces the secret values of a node, see masking.SecretValuePaths, with those of the masked object
+func maskSecretNode(node *yamlv3.Node, masked *unstructured.Unstructured) {
+	root := node
+	if root.Kind == yamlv3.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yamlv3.MappingNode {
+		return
+	}
+	for _, path := range masking.SecretValuePaths(masked) {
+		maskPathNode(root, masked, path, 1)
+	}
+}
+
+// maskPathNode replaces the values of the map at path below the mapping node parent, which is at path[:depth-1],
+// with those of the masked object
+func maskPathNode(parent *yamlv3.Node, masked *unstructured.Unstructured, path []string, depth int) {
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value != path[depth-1] {
+			continue
+		}
+		if depth == len(path) {
+			if values, found, _ := unstructured.NestedStringMap(masked.Object, path...); found {
+				maskValuesNode(parent, i+1, values)
+			}
+			continue
+		}
+		child := parent.Content[i+1]
+		if child.Kind != yamlv3.MappingNode || hasMergeKey(child) {
+			// The map cannot be located within merged or aliased mappings, so the masked field is written out in full
+			value, found, _ := unstructured.NestedFieldNoCopy(masked.Object, path[:depth]...)
+			encoded := &yamlv3.Node{}
+			if found && encoded.Encode(value) == nil {
+				parent.Content[i+1] = encoded
+			}
+			continue
+		}
+		maskPathNode(child, masked, path, depth+1)
+	}
+}
+
+// maskValuesNode replaces the values of the mapping node at index i of parent with the masked values
+func maskValuesNode(parent *yamlv3.Node, i int, values map[string]string) {
+	entries := parent.Content[i]
+	if entries.Kind != yamlv3.MappingNode || hasMergeKey(entries) {
+		// Values taken from an anchor are written out in full, so masking neither leaves them unmasked here
+		// nor changes the anchored node where it is defined
+		parent.Content[i] = stringMapNode(values)
+		return
+	}
+	for j := 0; j+1 < len(entries.Content); j += 2 {
+		maskedValue, ok := values[entries.Content[j].Value]
+		if !ok {
+			continue
+		}
+		value := entries.Content[j+1]
+		value.Kind = yamlv3.ScalarNode
+		value.Tag = "!!str"
+		value.Style = 0
+		value.Value = maskedValue
+	}
+}
+
+// hasMergeKey reports whether a mapping node merges in other mappings with <<
+func hasMergeKey(node *yamlv3.Node) bool {
+	for i := 0; i < len(node.Content); i += 2 {
+		if isMergeKey(node.Content[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringMapNode returns a mapping node of values with sorted keys
+func stringMapNode(values map[string]string) *yamlv3.Node {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	node := &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+	for _, key := range keys {
+		node.Content = append(node.Content,
+			&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key},
+			&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: values[key]},
+		)
+	}
+	return node
+}
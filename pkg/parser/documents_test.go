@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+)
+
+const commentedManifests = `# Frontend configuration, owned by team-a
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: frontend
+  namespace: default
+data:
+  # keep in sync with the backend
+  timeout: "30"
+  retries: "3" # bumped after incident
+---
+# Database credentials
+apiVersion: v1
+kind: Secret
+metadata:
+  namespace: default
+  name: db
+type: Opaque
+data:
+  # rotated monthly
+  password: cGFzc3dvcmQ=
+stringData:
+  user: admin # service account
+`
+
+func TestYamlDocumentsString(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             *Options
+		expectedKeys     []ResourceKey
+		expectedContains []string
+		expectedAbsent   []string
+	}{
+		{
+			name: "comments and key order are preserved",
+			opts: DefaultOptions(),
+			expectedKeys: []ResourceKey{
+				{Kind: "ConfigMap", Namespace: "default", Name: "frontend"},
+				{Kind: "Secret", Namespace: "default", Name: "db"},
+			},
+			expectedContains: []string{
+				"# Frontend configuration, owned by team-a\napiVersion: v1",
+				"  # keep in sync with the backend\n  timeout: \"30\"\n",
+				"retries: \"3\" # bumped after incident",
+				"# Database credentials\napiVersion: v1",
+				"metadata:\n  namespace: default\n  name: db\n",
+				"  # rotated monthly\n  password: ",
+				"# service account",
+			},
+			expectedAbsent: []string{"cGFzc3dvcmQ=", "admin"},
+		},
+		{
+			name: "masking disabled",
+			opts: &Options{DisableMaskingSecrets: true},
+			expectedKeys: []ResourceKey{
+				{Kind: "ConfigMap", Namespace: "default", Name: "frontend"},
+				{Kind: "Secret", Namespace: "default", Name: "db"},
+			},
+			expectedContains: []string{
+				"  # rotated monthly\n  password: cGFzc3dvcmQ=\n",
+				"user: admin # service account",
+			},
+		},
+		{
+			name: "filtered documents are dropped",
+			opts: &Options{FilterOption: &filter.Option{ExcludeKinds: []string{"Secret"}}},
+			expectedKeys: []ResourceKey{
+				{Kind: "ConfigMap", Namespace: "default", Name: "frontend"},
+			},
+			expectedAbsent: []string{"# Database credentials"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := YamlDocumentsString(commentedManifests, tt.opts)
+			require.NoError(t, err)
+
+			keys := make([]ResourceKey, 0, len(docs))
+			for _, doc := range docs {
+				keys = append(keys, doc.Key)
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+
+			output := docs.String()
+			for _, expected := range tt.expectedContains {
+				assert.Contains(t, output, expected)
+			}
+			for _, absent := range tt.expectedAbsent {
+				assert.NotContains(t, output, absent)
+			}
+		})
+	}
+}
+
+func TestYamlDocumentsMatchesYaml(t *testing.T) {
+	docs, err := YamlDocumentsString(commentedManifests, &Options{DisableMaskingSecrets: true})
+	require.NoError(t, err)
+	results, err := YamlString(commentedManifests, &Options{DisableMaskingSecrets: true})
+	require.NoError(t, err)
+
+	require.Len(t, docs, len(results))
+	for _, doc := range docs {
+		assert.Equal(t, results[doc.Key], doc.Object)
+	}
+}
+
+func TestYamlDocumentsEdgeCases(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedLen int
+		expectError bool
+	}{
+		{name: "empty input", input: "", expectedLen: 0},
+		{name: "comment only documents", input: "# nothing here\n---\n---\n", expectedLen: 0},
+		{name: "invalid yaml", input: "apiVersion: v1\nkind: [", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := YamlDocuments(strings.NewReader(tt.input), nil)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, docs, tt.expectedLen)
+			assert.Empty(t, docs.String())
+		})
+	}
+}
+
+func TestYamlDocumentsSealedSecret(t *testing.T) {
+	manifest := `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: db
+spec:
+  # sealed for the production cluster
+  encryptedData:
+    password: AgBy3i4OJSWK+PiTySYZZA9rO43cGDEq
+  template:
+    type: Opaque
+`
+	docs, err := YamlDocumentsString(manifest, DefaultOptions())
+	require.NoError(t, err)
+	output := docs.String()
+	assert.NotContains(t, output, "AgBy3i4OJSWK")
+	assert.Contains(t, output, "# sealed for the production cluster\n  encryptedData:\n    password: ++++")
+}
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Inventory summarizes the resources of manifests, to sanity-check inputs before diffing them
+type Inventory struct {
+	Kinds      map[string]int // Resources by kind, as Kind or Kind.group
+	Namespaces map[string]int // Resources by namespace, "" for resources without one
+	Excluded   int            // Resources excluded by the filter options
+	Warnings   []string       // Documents that are not Kubernetes objects or lack a name, e.g. "app.yaml:12: document 3 has no kind"
+	positions  map[ResourceKey][]string
+}
+
+// NewInventory returns an empty inventory
+func NewInventory() *Inventory {
+	return &Inventory{Kinds: make(map[string]int), Namespaces: make(map[string]int), positions: make(map[ResourceKey][]string)}
+}
+
+// ReadInventory adds the resources of the YAML or JSON manifests read from reader, named name in positions and
+// warnings, to a new inventory. The items of List objects are counted individually, and resources excluded by the
+// filter options of opts are only counted as Excluded. The input is bounded by the limits of opts.
+func ReadInventory(name string, reader io.Reader, opts *Options) (*Inventory, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if err := opts.FilterOption.Validate(); err != nil {
+		return nil, err
+	}
+	return withTimeout(opts.Limits.Timeout, func() (*Inventory, error) {
+		inventory := NewInventory()
+		if err := inventory.read(name, reader, opts); err != nil {
+			return nil, err
+		}
+		return inventory, nil
+	})
+}
+
+// read adds the resources of the manifests read from reader to the inventory
+func (i *Inventory) read(name string, reader io.Reader, opts *Options) error {
+	data, err := normalizeLineEndings(reader, opts.Limits.MaxInputSize)
+	if err != nil {
+		return err
+	}
+	if err := opts.Limits.checkExpansion(data); err != nil {
+		return err
+	}
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+	for document := 1; ; document++ {
+		node := &yamlv3.Node{}
+		if err := decoder.Decode(node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if err := opts.Limits.checkDocuments(document); err != nil {
+			return err
+		}
+		if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+			continue
+		}
+		root := node.Content[0]
+		if kind := mappingValue(root, "kind"); kind != nil && strings.HasSuffix(kind.Value, "List") && mappingValue(root, "items") != nil {
+			for _, item := range mappingValue(root, "items").Content {
+				i.add(name, document, item, opts.FilterOption)
+			}
+			continue
+		}
+		i.add(name, document, root, opts.FilterOption)
+	}
+}
+
+// add counts the object node of a document, or records a warning if it is not a Kubernetes object
+func (i *Inventory) add(name string, document int, node *yamlv3.Node, option *filter.Option) {
+	position := fmt.Sprintf("%s:%d", name, node.Line)
+	obj, err := decodeObject(node)
+	if err != nil {
+		i.Warnings = append(i.Warnings, fmt.Sprintf("%s: document %d %v", position, document, err))
+		return
+	}
+	selected, err := filter.Select([]*unstructured.Unstructured{obj}, option)
+	if err != nil {
+		i.Warnings = append(i.Warnings, fmt.Sprintf("%s: document %d excluded: %v", position, document, err))
+	}
+	if len(selected) == 0 {
+		i.Excluded++
+		return
+	}
+
+	gvk := obj.GroupVersionKind()
+	kind := gvk.Kind
+	if gvk.Group != "" {
+		kind += "." + gvk.Group
+	}
+	i.Kinds[kind]++
+	i.Namespaces[obj.GetNamespace()]++
+	if obj.GetName() == "" {
+		if obj.GetGenerateName() == "" {
+			i.Warnings = append(i.Warnings, fmt.Sprintf("%s: document %d %s has no name", position, document, kind))
+		}
+		return
+	}
+	key := ResourceKey{Name: obj.GetName(), Namespace: obj.GetNamespace(), Group: gvk.Group, Kind: gvk.Kind}
+	i.positions[key] = append(i.positions[key], position)
+}
+
+// decodeObject decodes an object node into a Kubernetes object, failing if it lacks an apiVersion or kind.
+// Errors describe the node without its values, which may be secret.
+func decodeObject(node *yamlv3.Node) (*unstructured.Unstructured, error) {
+	var object map[string]any
+	if node.Kind != yamlv3.MappingNode || node.Decode(&object) != nil {
+		return nil, errors.New("is not a mapping")
+	}
+	for _, field := range []string{"apiVersion", "kind"} {
+		if value, ok := object[field].(string); !ok || value == "" {
+			return nil, fmt.Errorf("has no %s", field)
+		}
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, errors.New("is not a Kubernetes object")
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, errors.New("is not a Kubernetes object")
+	}
+	return obj, nil
+}
+
+// Add adds the resources of other to the inventory, e.g. to summarize the files of a directory
+func (i *Inventory) Add(other *Inventory) {
+	for kind, count := range other.Kinds {
+		i.Kinds[kind] += count
+	}
+	for namespace, count := range other.Namespaces {
+		i.Namespaces[namespace] += count
+	}
+	i.Excluded += other.Excluded
+	i.Warnings = append(i.Warnings, other.Warnings...)
+	for key, positions := range other.positions {
+		i.positions[key] = append(i.positions[key], positions...)
+	}
+}
+
+// Total returns the number of resources in the inventory, not counting excluded ones
+func (i *Inventory) Total() int {
+	total := 0
+	for _, count := range i.Kinds {
+		total += count
+	}
+	return total
+}
+
+// Duplicates returns the positions of the resources defined more than once, which a diff would silently
+// collapse into the last definition
+func (i *Inventory) Duplicates() map[ResourceKey][]string {
+	duplicates := make(map[ResourceKey][]string)
+	for key, positions := range i.positions {
+		if len(positions) > 1 {
+			duplicates[key] = positions
+		}
+	}
+	return duplicates
+}
+
+// HasProblems reports whether the inventory has duplicate resources or warnings
+func (i *Inventory) HasProblems() bool {
+	return len(i.Duplicates()) > 0 || len(i.Warnings) > 0
+}
+
+// String returns the inventory as text: counts by kind and namespace, then duplicates and warnings
+func (i *Inventory) String() string {
+	var result strings.Builder
+	duplicates := i.Duplicates()
+	result.WriteString(fmt.Sprintf("# Inventory: %d resources, %d kinds, %d namespaces, %d excluded, %d duplicates, %d warnings\n",
+		i.Total(), len(i.Kinds), len(i.Namespaces), i.Excluded, len(duplicates), len(i.Warnings)))
+
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	writeCounts(w, "Kinds", i.Kinds)
+	writeCounts(w, "Namespaces", i.Namespaces)
+	if err := w.Flush(); err != nil {
+		return fmt.Sprintf("Error rendering inventory: %v", err)
+	}
+
+	if len(duplicates) > 0 {
+		keys := make([]ResourceKey, 0, len(duplicates))
+		for key := range duplicates {
+			keys = append(keys, key)
+		}
+		sortResourceKeys(keys)
+		result.WriteString("\nDuplicates:\n")
+		for _, key := range keys {
+			result.WriteString(fmt.Sprintf("  %s: %s\n", key, strings.Join(duplicates[key], ", ")))
+		}
+	}
+	if len(i.Warnings) > 0 {
+		result.WriteString("\nWarnings:\n")
+		for _, warning := range i.Warnings {
+			result.WriteString("  " + warning + "\n")
+		}
+	}
+	return result.String()
+}
+
+// writeCounts writes a section of counts sorted by name, showing the empty name as "(none)"
+func writeCounts(w io.Writer, heading string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "\n%s:\n", heading)
+	for _, name := range names {
+		label := name
+		if label == "" {
+			label = "(none)"
+		}
+		fmt.Fprintf(w, "  %s\t%d\n", label, counts[name])
+	}
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/filter"
+)
+
+const inventoryManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: prod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: prod
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod
+---
+metadata:
+  name: orphan
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  namespace: prod
+  labels:
+    skip: "true"
+---
+- not
+- an object
+---
+`
+
+func TestReadInventory(t *testing.T) {
+	inventory, err := ReadInventory("app.yaml", strings.NewReader(inventoryManifests), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"ConfigMap": 2, "Deployment.apps": 1, "Namespace": 1, "Pod": 1}, inventory.Kinds)
+	assert.Equal(t, map[string]int{"": 1, "prod": 4}, inventory.Namespaces)
+	assert.Equal(t, 5, inventory.Total())
+	assert.Equal(t, map[ResourceKey][]string{
+		{Kind: "ConfigMap", Namespace: "prod", Name: "config"}: {"app.yaml:7", "app.yaml:13"},
+	}, inventory.Duplicates())
+	assert.Equal(t, []string{
+		"app.yaml:24: document 5 has no apiVersion",
+		"app.yaml:27: document 6 Pod has no name",
+		"app.yaml:34: document 7 is not a mapping",
+	}, inventory.Warnings)
+	assert.True(t, inventory.HasProblems())
+
+	output := inventory.String()
+	assert.Contains(t, output, "# Inventory: 5 resources, 4 kinds, 2 namespaces, 0 excluded, 1 duplicates, 3 warnings")
+	assert.Contains(t, output, "  Deployment.apps  1\n")
+	assert.Contains(t, output, "  (none)  1\n")
+	assert.Contains(t, output, "Duplicates:\n  /ConfigMap/prod/config: app.yaml:7, app.yaml:13\n")
+}
+
+func TestReadInventory_Filter(t *testing.T) {
+	opts := DefaultOptions()
+	opts.FilterOption = &filter.Option{ExcludeKinds: []string{"ConfigMap", "Pod"}}
+	inventory, err := ReadInventory("app.yaml", strings.NewReader(inventoryManifests), opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"Deployment.apps": 1, "Namespace": 1}, inventory.Kinds)
+	assert.Equal(t, 3, inventory.Excluded)
+	assert.Empty(t, inventory.Duplicates())
+	assert.Len(t, inventory.Warnings, 2)
+}
+
+func TestReadInventory_List(t *testing.T) {
+	inventory, err := ReadInventory("list.json", strings.NewReader(`{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}
+]}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"ConfigMap": 2}, inventory.Kinds)
+	assert.Equal(t, map[ResourceKey][]string{{Kind: "ConfigMap", Name: "a"}: {"list.json:2", "list.json:3"}}, inventory.Duplicates())
+}
+
+func TestReadInventory_Errors(t *testing.T) {
+	_, err := ReadInventory("bad.yaml", strings.NewReader("kind: [\n"), nil)
+	assert.ErrorContains(t, err, "failed to parse bad.yaml")
+
+	opts := DefaultOptions()
+	opts.Limits.MaxDocuments = 1
+	_, err = ReadInventory("app.yaml", strings.NewReader(inventoryManifests), opts)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestInventory_Add(t *testing.T) {
+	first, err := ReadInventory("a.yaml", strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"), nil)
+	require.NoError(t, err)
+	second, err := ReadInventory("b.yaml", strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"), nil)
+	require.NoError(t, err)
+
+	first.Add(second)
+	assert.Equal(t, 2, first.Total())
+	assert.Equal(t, map[ResourceKey][]string{{Kind: "ConfigMap", Name: "config"}: {"a.yaml:1", "b.yaml:1"}}, first.Duplicates())
+}
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultMaxNodes is the default maximum number of nodes of a YAML document with its aliases expanded
+	DefaultMaxNodes = 1000000
+	// DefaultMaxDepth is the default maximum nesting depth of a YAML document
+	DefaultMaxDepth = 1000
+)
+
+// ErrLimitExceeded is returned, wrapped, when an input exceeds one of its Limits
+var ErrLimitExceeded = errors.New("input limit exceeded")
+
+// Limits bounds the memory and time spent parsing untrusted input, e.g. to reject YAML bombs.
+// Zero values are unlimited.
+type Limits struct {
+	MaxInputSize int64         // Maximum size of the input in bytes
+	MaxDocuments int           // Maximum number of YAML documents or JSON objects, including empty documents
+	Timeout      time.Duration // Maximum time to parse the input
+	MaxNodes     int           // Maximum number of nodes of a YAML document, counting aliased nodes at every use
+	MaxDepth     int           // Maximum nesting depth of a YAML document, following aliases
+}
+
+// DefaultLimits returns the limits of ParseYAML, which reject alias expansion ("billion laughs") attacks
+// while leaving the size, document count and parse time of the input unlimited
+func DefaultLimits() Limits {
+	return Limits{MaxNodes: DefaultMaxNodes, MaxDepth: DefaultMaxDepth}
+}
+
+// ExpansionError is returned when a YAML document exceeds Limits.MaxNodes or Limits.MaxDepth once its
+// aliases are expanded. It wraps ErrLimitExceeded.
+type ExpansionError struct {
+	Document int // Position of the document in the input, from 1
+	MaxNodes int // The exceeded node limit, or 0 if the depth limit was exceeded
+	MaxDepth int // The exceeded depth limit, or 0 if the node limit was exceeded
+}
+
+// Error describes the exceeded limit
+func (e *ExpansionError) Error() string {
+	if e.MaxNodes > 0 {
+		return fmt.Sprintf("%v: document %d expands to more than %d nodes", ErrLimitExceeded, e.Document, e.MaxNodes)
+	}
+	return fmt.Sprintf("%v: document %d is nested deeper than %d levels", ErrLimitExceeded, e.Document, e.MaxDepth)
+}
+
+// Unwrap returns ErrLimitExceeded
+func (e *ExpansionError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// readLimited reads all of reader, failing once more than maxSize bytes are read
+func readLimited(reader io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(reader)
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: input is larger than %d bytes", ErrLimitExceeded, maxSize)
+	}
+	return data, nil
+}
+
+// checkDocuments fails if the count of documents read so far exceeds the limit
+func (l Limits) checkDocuments(count int) error {
+	if l.MaxDocuments > 0 && count > l.MaxDocuments {
+		return fmt.Errorf("%w: input has more than %d documents", ErrLimitExceeded, l.MaxDocuments)
+	}
+	return nil
+}
+
+// withTimeout returns the result of parse, or an error if it does not return within the timeout.
+// The parse is abandoned rather than stopped, so it keeps running in the background until it returns;
+// MaxInputSize bounds how long that can be.
+func withTimeout[T any](timeout time.Duration, parse func() (T, error)) (T, error) {
+	if timeout <= 0 {
+		return parse()
+	}
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := parse()
+		done <- result{value: value, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		var zero T
+		return zero, fmt.Errorf("%w: parsing took longer than %s", ErrLimitExceeded, timeout)
+	}
+}
+
+// checkExpansion measures the YAML documents of data as nodes, without expanding aliases into memory.
+// JSON has no aliases and is not measured. Measuring stops at the first document that fails to decode,
+// whose error is left to the decoder of the objects.
+func (l Limits) checkExpansion(data []byte) error {
+	if l.MaxNodes <= 0 && l.MaxDepth <= 0 || bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return nil
+	}
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+	for document := 1; ; document++ {
+		node := &yamlv3.Node{}
+		if err := decoder.Decode(node); err != nil {
+			return nil
+		}
+		if err := l.checkNode(document, node); err != nil {
+			return err
+		}
+	}
+}
+
+// checkNode fails with an ExpansionError if the document node exceeds MaxNodes or MaxDepth with its aliases expanded
+func (l Limits) checkNode(document int, node *yamlv3.Node) error {
+	if l.MaxNodes <= 0 && l.MaxDepth <= 0 {
+		return nil
+	}
+	var size nodeSize
+	measured := make(map[*yamlv3.Node]nodeSize)
+	for _, child := range node.Content {
+		childSize := measureNode(child, measured)
+		size.nodes = saturatingAdd(size.nodes, childSize.nodes)
+		size.depth = max(size.depth, childSize.depth)
+	}
+	if l.MaxNodes > 0 && size.nodes > l.MaxNodes {
+		return &ExpansionError{Document: document, MaxNodes: l.MaxNodes}
+	}
+	if l.MaxDepth > 0 && size.depth > l.MaxDepth {
+		return &ExpansionError{Document: document, MaxDepth: l.MaxDepth}
+	}
+	return nil
+}
+
+// nodeSize is the number of nodes and nesting depth of a node with its aliases expanded
+type nodeSize struct {
+	nodes int
+	depth int
+}
+
+var (
+	// measuringNode marks an anchored node being measured, so that an alias within it is recognized as recursive
+	measuringNode = nodeSize{nodes: -1, depth: -1}
+	// unboundedNode is the size of a recursive alias, which expands without end
+	unboundedNode = nodeSize{nodes: math.MaxInt, depth: math.MaxInt}
+)
+
+// measureNode returns the size of node as if its aliases were expanded. Sizes of anchored nodes are kept in
+// measured, so each node is visited once however often it is aliased, and sizes saturate instead of overflowing.
+func measureNode(node *yamlv3.Node, measured map[*yamlv3.Node]nodeSize) nodeSize {
+	if node.Kind == yamlv3.AliasNode {
+		if node.Alias == nil {
+			return nodeSize{nodes: 1, depth: 1}
+		}
+		node = node.Alias
+	}
+	if node.Anchor != "" {
+		if size, ok := measured[node]; ok {
+			if size == measuringNode {
+				return unboundedNode
+			}
+			return size
+		}
+		measured[node] = measuringNode
+	}
+
+	size := nodeSize{nodes: 1, depth: 1}
+	for _, child := range node.Content {
+		childSize := measureNode(child, measured)
+		size.nodes = saturatingAdd(size.nodes, childSize.nodes)
+		size.depth = max(size.depth, saturatingAdd(childSize.depth, 1))
+	}
+	if node.Anchor != "" {
+		measured[node] = size
+	}
+	return size
+}
+
+// saturatingAdd returns a+b for non-negative a and b, or math.MaxInt if the sum overflows
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configMaps returns a stream of n ConfigMap documents
+func configMaps(n int) string {
+	docs := make([]string, n)
+	for i := range docs {
+		docs[i] = fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config-%d\n", i)
+	}
+	return strings.Join(docs, "---\n")
+}
+
+func TestParseYAMLWithLimits(t *testing.T) {
+	input := configMaps(3)
+
+	objs, err := ParseYAMLWithLimits(strings.NewReader(input), Limits{MaxInputSize: int64(len(input)), MaxDocuments: 3, Timeout: time.Minute})
+	require.NoError(t, err)
+	assert.Len(t, objs, 3)
+
+	_, err = ParseYAMLWithLimits(strings.NewReader(input), Limits{MaxInputSize: int64(len(input)) - 1})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, fmt.Sprintf("input is larger than %d bytes", len(input)-1))
+
+	_, err = ParseYAMLWithLimits(strings.NewReader(input), Limits{MaxDocuments: 2})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "input has more than 2 documents")
+
+	_, err = ParseYAMLWithLimits(strings.NewReader(configMaps(10000)), Limits{Timeout: time.Nanosecond})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "parsing took longer than 1ns")
+}
+
+func TestYamlDocuments_Limits(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Limits = Limits{MaxDocuments: 2}
+	_, err := YamlDocumentsString(configMaps(3), opts)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	_, err = YamlString(configMaps(3), opts)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+// laughs returns a ConfigMap whose data aliases each level of lists levels times in the next, so that it
+// expands to more than 10^levels nodes
+func laughs(levels int) string {
+	var b strings.Builder
+	b.WriteString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: lol\nlevels:\n  l0: &l0 lol\n")
+	for i := 1; i <= levels; i++ {
+		fmt.Fprintf(&b, "  l%d: &l%d [%s]\n", i, i, strings.TrimSuffix(strings.Repeat(fmt.Sprintf("*l%d,", i-1), 10), ","))
+	}
+	return b.String()
+}
+
+func TestParseYAML_AliasExpansion(t *testing.T) {
+	_, err := ParseYAML(strings.NewReader(configMaps(1) + "---\n" + laughs(9)))
+	var expansionErr *ExpansionError
+	require.ErrorAs(t, err, &expansionErr)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Equal(t, 2, expansionErr.Document)
+	assert.EqualError(t, err, fmt.Sprintf("input limit exceeded: document 2 expands to more than %d nodes", DefaultMaxNodes))
+
+	objs, err := ParseYAML(strings.NewReader(laughs(2)))
+	require.NoError(t, err)
+	assert.Len(t, objs, 1)
+
+	_, err = ParseYAMLWithLimits(strings.NewReader(laughs(2)), Limits{MaxNodes: 100})
+	assert.ErrorContains(t, err, "document 1 expands to more than 100 nodes")
+
+	_, err = YamlDocumentsString(laughs(9), DefaultOptions())
+	assert.ErrorAs(t, err, &expansionErr)
+}
+
+func TestParseYAML_NestingDepth(t *testing.T) {
+	input := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: deep\nnested: " + strings.Repeat("[", 20) + strings.Repeat("]", 20) + "\n"
+
+	_, err := ParseYAMLWithLimits(strings.NewReader(input), Limits{MaxDepth: 10})
+	assert.EqualError(t, err, "input limit exceeded: document 1 is nested deeper than 10 levels")
+
+	_, err = ParseYAMLWithLimits(strings.NewReader(input), Limits{MaxDepth: 30})
+	assert.NoError(t, err)
+}
+
+func TestParseYAML_RecursiveAlias(t *testing.T) {
+	_, err := ParseYAML(strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: loop\ndata: &loop\n  self: *loop\n"))
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
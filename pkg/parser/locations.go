@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Location is a range of lines of a manifest file, from 1
+type Location struct {
+	File      string `json:"file"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+}
+
+// SourceMap records where resources and their fields are in manifest files, by resource key and field path,
+// e.g. "spec.template.spec.containers[0].image" (see FieldPath). The path "" locates the whole resource.
+type SourceMap map[ResourceKey]map[string]Location
+
+// LocateFields returns the locations of the resources and fields of the YAML or JSON manifests in data,
+// read from file. Documents that are not Kubernetes objects are skipped, and the items of List objects are
+// located individually. Later resources with the same key replace earlier ones, as when parsing.
+func LocateFields(file string, data []byte) (SourceMap, error) {
+	sources := make(SourceMap)
+	decoder := yamlv3.NewDecoder(bytes.NewReader(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))))
+	for {
+		document := &yamlv3.Node{}
+		if err := decoder.Decode(document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return sources, nil
+			}
+			return nil, fmt.Errorf("failed to locate fields of %s: %w", file, err)
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+		root := document.Content[0]
+		if mappingValue(root, "kind") != nil && mappingValue(root, "kind").Value == "List" {
+			if items := mappingValue(root, "items"); items != nil {
+				for _, item := range items.Content {
+					sources.locateObject(file, item)
+				}
+			}
+			continue
+		}
+		sources.locateObject(file, root)
+	}
+}
+
+// Merge adds the locations of other, replacing those of resources located in both
+func (m SourceMap) Merge(other SourceMap) {
+	for key, fields := range other {
+		m[key] = fields
+	}
+}
+
+// Lookup returns the location of the field at path of the resource, or of its closest located ancestor,
+// e.g. of the parent mapping of a removed field
+func (m SourceMap) Lookup(key ResourceKey, path string) (Location, bool) {
+	fields, ok := m[key]
+	if !ok {
+		return Location{}, false
+	}
+	for {
+		if location, ok := fields[path]; ok {
+			return location, true
+		}
+		if path == "" {
+			return Location{}, false
+		}
+		path = parentFieldPath(path)
+	}
+}
+
+// FieldPath appends a mapping key to a field path. Keys containing dots or brackets, such as label keys,
+// are quoted, e.g. `metadata.labels["app.kubernetes.io/name"]`.
+func FieldPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]\"") {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// IndexPath appends a sequence index to a field path, e.g. "containers[0]"
+func IndexPath(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
+
+// parentFieldPath returns the path of the field containing the field at path
+func parentFieldPath(path string) string {
+	if strings.HasSuffix(path, "\"]") {
+		// A quoted key; quotes within it are escaped
+		for i := len(path) - 3; i > 0; i-- {
+			if path[i] == '"' && path[i-1] == '[' && !escapedQuote(path, i) {
+				return path[:i-1]
+			}
+		}
+		return ""
+	}
+	if strings.HasSuffix(path, "]") {
+		return path[:strings.LastIndexByte(path, '[')]
+	}
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// escapedQuote reports whether the quote at i of s is escaped by an odd number of backslashes
+func escapedQuote(s string, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// locateObject records the locations of an object node and its fields if it is a Kubernetes object
+func (m SourceMap) locateObject(file string, node *yamlv3.Node) {
+	if node.Kind != yamlv3.MappingNode {
+		return
+	}
+	apiVersion, kind := mappingValue(node, "apiVersion"), mappingValue(node, "kind")
+	if apiVersion == nil || kind == nil {
+		return
+	}
+	key := ResourceKey{Kind: kind.Value}
+	if group, _, found := strings.Cut(apiVersion.Value, "/"); found {
+		key.Group = group
+	}
+	if metadata := mappingValue(node, "metadata"); metadata != nil {
+		if name := mappingValue(metadata, "name"); name != nil {
+			key.Name = name.Value
+		}
+		if namespace := mappingValue(metadata, "namespace"); namespace != nil {
+			key.Namespace = namespace.Value
+		}
+	}
+
+	fields := map[string]Location{"": {File: file, StartLine: node.Line, EndLine: lastLine(node)}}
+	locateFields(file, "", node, fields)
+	m[key] = fields
+}
+
+// locateFields records the locations of the fields below node, whose path is path
+func locateFields(file, path string, node *yamlv3.Node, fields map[string]Location) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldPath := FieldPath(path, key.Value)
+			fields[fieldPath] = Location{File: file, StartLine: key.Line, EndLine: lastLine(value)}
+			locateFields(file, fieldPath, value, fields)
+		}
+	case yamlv3.SequenceNode:
+		for i, item := range node.Content {
+			itemPath := IndexPath(path, i)
+			fields[itemPath] = Location{File: file, StartLine: item.Line, EndLine: lastLine(item)}
+			locateFields(file, itemPath, item, fields)
+		}
+	}
+}
+
+// lastLine returns the last line of node, including the content lines of block scalars
+func lastLine(node *yamlv3.Node) int {
+	last := node.Line
+	switch node.Kind {
+	case yamlv3.ScalarNode:
+		if node.Style&(yamlv3.LiteralStyle|yamlv3.FoldedStyle) != 0 {
+			last += strings.Count(strings.TrimRight(node.Value, "\n"), "\n") + 1
+		}
+	case yamlv3.MappingNode, yamlv3.SequenceNode:
+		for _, child := range node.Content {
+			last = max(last, lastLine(child))
+		}
+	}
+	return last
+}
+
+// mappingValue returns the value of key in a mapping node, or nil if node is not a mapping or lacks the key
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
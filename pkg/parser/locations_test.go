@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocateFields(t *testing.T) {
+	data := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    app.kubernetes.io/name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+        args:
+        - --port=80
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  script: |
+    echo one
+    echo two
+  other: value
+`)
+	sources, err := LocateFields("manifests.yaml", data)
+	require.NoError(t, err)
+
+	deployment := ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "web"}
+	location, ok := sources.Lookup(deployment, "")
+	require.True(t, ok)
+	assert.Equal(t, Location{File: "manifests.yaml", StartLine: 1, EndLine: 15}, location)
+
+	location, ok = sources.Lookup(deployment, "spec.template.spec.containers[0].image")
+	require.True(t, ok)
+	assert.Equal(t, Location{File: "manifests.yaml", StartLine: 13, EndLine: 13}, location)
+
+	location, ok = sources.Lookup(deployment, "spec.template.spec.containers[0]")
+	require.True(t, ok)
+	assert.Equal(t, Location{File: "manifests.yaml", StartLine: 12, EndLine: 15}, location)
+
+	location, ok = sources.Lookup(deployment, `metadata.labels["app.kubernetes.io/name"]`)
+	require.True(t, ok)
+	assert.Equal(t, 7, location.StartLine)
+
+	configMap := ResourceKey{Kind: "ConfigMap", Name: "config"}
+	location, ok = sources.Lookup(configMap, "data.script")
+	require.True(t, ok)
+	assert.Equal(t, Location{File: "manifests.yaml", StartLine: 22, EndLine: 24}, location, "block scalars span their content")
+
+	location, ok = sources.Lookup(configMap, "data.removed")
+	require.True(t, ok, "missing fields fall back to their parent")
+	assert.Equal(t, Location{File: "manifests.yaml", StartLine: 21, EndLine: 25}, location)
+
+	_, ok = sources.Lookup(ResourceKey{Kind: "Secret", Name: "missing"}, "data")
+	assert.False(t, ok)
+}
+
+func TestLocateFields_List(t *testing.T) {
+	data := []byte(`{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}},
+    {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "b"}}
+  ]
+}
+`)
+	sources, err := LocateFields("list.json", data)
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+
+	location, ok := sources.Lookup(ResourceKey{Kind: "ConfigMap", Name: "b"}, "metadata.name")
+	require.True(t, ok)
+	assert.Equal(t, 6, location.StartLine)
+}
+
+func TestLocateFields_Invalid(t *testing.T) {
+	_, err := LocateFields("bad.yaml", []byte("kind: [\n"))
+	assert.ErrorContains(t, err, "failed to locate fields of bad.yaml")
+}
+
+func TestFieldPath(t *testing.T) {
+	path := FieldPath(FieldPath("", "metadata"), "annotations")
+	path = FieldPath(path, `example.com/a"b`)
+	assert.Equal(t, `metadata.annotations["example.com/a\"b"]`, path)
+	assert.Equal(t, "metadata.annotations", parentFieldPath(path))
+
+	path = IndexPath(FieldPath("spec", "containers"), 2)
+	assert.Equal(t, "spec.containers[2]", path)
+	assert.Equal(t, "spec.containers", parentFieldPath(path))
+	assert.Equal(t, "spec", parentFieldPath("spec.containers"))
+	assert.Equal(t, "", parentFieldPath("spec"))
+	assert.Equal(t, "spec.containers[0]", parentFieldPath("spec.containers[0].image"))
+}
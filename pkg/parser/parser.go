@@ -2,6 +2,8 @@
 package parser
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 
@@ -11,10 +13,32 @@ import (
 
 // ParseYAML reads a YAML or JSON stream and returns unstructured objects.
 // If the unmarshaller encounters an error, objects read up until the error are returned.
+// Documents exceeding DefaultLimits once their aliases are expanded are rejected with an ExpansionError.
 func ParseYAML(reader io.Reader) ([]*unstructured.Unstructured, error) {
-	d := kubeyaml.NewYAMLOrJSONDecoder(reader, 4096)
+	return ParseYAMLWithLimits(reader, DefaultLimits())
+}
+
+// ParseYAMLWithLimits reads a YAML or JSON stream like ParseYAML, failing with ErrLimitExceeded
+// if the stream exceeds limits. Only objects read up until a decoding error are returned.
+func ParseYAMLWithLimits(reader io.Reader, limits Limits) ([]*unstructured.Unstructured, error) {
+	return withTimeout(limits.Timeout, func() ([]*unstructured.Unstructured, error) {
+		return parseYAML(reader, limits)
+	})
+}
+
+// parseYAML decodes the objects of a YAML or JSON stream within the size, document and expansion limits.
+// Expansion is checked up front, as decoding into objects expands aliases in memory.
+func parseYAML(reader io.Reader, limits Limits) ([]*unstructured.Unstructured, error) {
+	data, err := normalizeLineEndings(reader, limits.MaxInputSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.checkExpansion(data); err != nil {
+		return nil, err
+	}
+	d := kubeyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
 	var objs []*unstructured.Unstructured
-	for {
+	for documents := 1; ; documents++ {
 		u := &unstructured.Unstructured{}
 		if err := d.Decode(&u); err != nil {
 			if err == io.EOF {
@@ -22,6 +46,9 @@ func ParseYAML(reader io.Reader) ([]*unstructured.Unstructured, error) {
 			}
 			return objs, fmt.Errorf("failed to unmarshal manifest: %v", err)
 		}
+		if err := limits.checkDocuments(documents); err != nil {
+			return nil, err
+		}
 		if u == nil {
 			continue
 		}
@@ -29,3 +56,17 @@ func ParseYAML(reader io.Reader) ([]*unstructured.Unstructured, error) {
 	}
 	return objs, nil
 }
+
+// normalizeLineEndings reads all of reader, at most maxSize bytes when positive, replacing CRLF line endings
+// by LF so that manifests edited on Windows parse the same as their LF counterparts, including the comments
+// kept by YamlDocuments
+func normalizeLineEndings(reader io.Reader, maxSize int64) ([]byte, error) {
+	data, err := readLimited(reader, maxSize)
+	if errors.Is(err, ErrLimitExceeded) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), nil
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -114,3 +115,37 @@ func TestParseYAMLJSON(t *testing.T) {
 	assert.Equal(t, "Pod", objs[0].GetKind())
 	assert.Equal(t, "nginx", objs[0].GetName())
 }
+
+func TestParseYAMLMixedLineEndings(t *testing.T) {
+	lf := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings # edited on Windows
+data:
+  script: |
+    echo one
+    echo two
+  json: "{\"a\": 1}"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+`
+	// Only the first document uses CRLF, as after concatenating files from different editors
+	documents := strings.SplitN(lf, "---\n", 2)
+	mixed := strings.ReplaceAll(documents[0], "\n", "\r\n") + "---\n" + documents[1]
+
+	expected, err := ParseYAML(strings.NewReader(lf))
+	assert.NoError(t, err)
+	objs, err := ParseYAML(strings.NewReader(mixed))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, objs)
+	assert.Equal(t, "echo one\necho two\n", objs[0].Object["data"].(map[string]any)["script"])
+
+	expectedDocs, err := YamlDocumentsString(lf, nil)
+	assert.NoError(t, err)
+	docs, err := YamlDocumentsString(mixed, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedDocs.String(), docs.String())
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// blockScalarStart matches lines ending with the indicator of a literal or folded block scalar
+var blockScalarStart = regexp.MustCompile(`(?:^\s*|[:-]\s+)[|>][-+0-9]*\s*$`)
+
+// UnescapeUnicode replaces the \UXXXXXXXX escapes YAML encoders write in double-quoted scalars for
+// characters outside the Basic Multilingual Plane, such as emoji, with the characters themselves so
+// that rendered YAML shows them as written. Escapes of characters that are not printable are kept, as
+// is text outside double-quoted scalars, e.g. in plain or block scalars where a backslash is literal.
+func UnescapeUnicode(s string) string {
+	if !strings.Contains(s, `\U`) {
+		return s
+	}
+	var result strings.Builder
+	result.Grow(len(s))
+	quoted := false   // Inside a double-quoted scalar continued from the previous line
+	blockIndent := -1 // Indentation of the line introducing the current block scalar (-1 outside block scalars)
+	for _, line := range strings.SplitAfter(s, "\n") {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			if strings.TrimSpace(line) == "" || indent > blockIndent {
+				result.WriteString(line)
+				continue
+			}
+			blockIndent = -1
+		}
+		var unescaped string
+		unescaped, quoted = unescapeLine(line, quoted)
+		result.WriteString(unescaped)
+		if !quoted && blockScalarStart.MatchString(strings.TrimRight(line, "\n")) {
+			blockIndent = indent
+		}
+	}
+	return result.String()
+}
+
+// unescapeLine unescapes a line of YAML, starting inside a double-quoted scalar if quoted is set.
+// It reports whether the line ends inside a double-quoted scalar.
+func unescapeLine(line string, quoted bool) (string, bool) {
+	var result strings.Builder
+	singleQuoted := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(line):
+			if r, ok := unicodeEscape(line[i:]); ok {
+				result.WriteRune(r)
+				i += 9
+				continue
+			}
+			// Keep the escape, including an escaped backslash that must not start another escape
+			result.WriteString(line[i : i+2])
+			i++
+			continue
+		case quoted && c == '"':
+			quoted = false
+		case singleQuoted && c == '\'':
+			if i+1 < len(line) && line[i+1] == '\'' {
+				result.WriteString("''")
+				i++
+				continue
+			}
+			singleQuoted = false
+		case quoted || singleQuoted:
+		case c == '#' && (i == 0 || line[i-1] == ' '):
+			// The rest of the line is a comment
+			result.WriteString(line[i:])
+			return result.String(), false
+		case c == '"' && startsToken(line, i):
+			quoted = true
+		case c == '\'' && startsToken(line, i):
+			singleQuoted = true
+		}
+		result.WriteByte(c)
+	}
+	return result.String(), quoted
+}
+
+// unicodeEscape decodes a \UXXXXXXXX escape of a printable character at the start of s
+func unicodeEscape(s string) (rune, bool) {
+	if len(s) < 10 || s[1] != 'U' {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(s[2:10], 16, 32)
+	if err != nil || code > unicode.MaxRune || !unicode.IsPrint(rune(code)) {
+		return 0, false
+	}
+	return rune(code), true
+}
+
+// startsToken reports whether the quote at position i of line starts a scalar, i.e. follows the
+// indentation, a mapping key, a sequence entry or a flow collection indicator
+func startsToken(line string, i int) bool {
+	before := strings.TrimRight(line[:i], " ")
+	if before == "" {
+		return true
+	}
+	switch before[len(before)-1] {
+	case '[', '{', ',':
+		return true
+	case ':', '?':
+		return len(before) < i
+	case '-':
+		rest := strings.TrimRight(before[:len(before)-1], " ")
+		return len(before) < i && (rest == "" || strings.HasSuffix(rest, "-") || strings.HasSuffix(rest, ":"))
+	default:
+		return false
+	}
+}
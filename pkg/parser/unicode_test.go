@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestUnescapeUnicode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "emoji", input: `description: "\U0001F680 リリース"`, expected: `description: "🚀 リリース"`},
+		{name: "escaped backslash", input: `path: "C:\\U0001F680"`, expected: `path: "C:\\U0001F680"`},
+		{name: "non-printable", input: `value: "\U000E0001"`, expected: `value: "\U000E0001"`},
+		{name: "other escapes", input: `value: "a\tb\u00e9\"c"`, expected: `value: "a\tb\u00e9\"c"`},
+		{name: "short escape", input: `value: "\U0001F6"`, expected: `value: "\U0001F6"`},
+		{name: "japanese", input: "description: 日本語の説明", expected: "description: 日本語の説明"},
+		{name: "plain scalar", input: `path: C:\U0001F680`, expected: `path: C:\U0001F680`},
+		{name: "single-quoted scalar", input: `path: 'it''s "\U0001F680"'`, expected: `path: 'it''s "\U0001F680"'`},
+		{name: "block scalar", input: "script: |\n  echo \"\\U0001F680\"\nnote: \"\\U0001F680\"\n", expected: "script: |\n  echo \"\\U0001F680\"\nnote: \"🚀\"\n"},
+		{name: "sequence entry", input: `- "\U0001F680"`, expected: `- "🚀"`},
+		{name: "continued line", input: "note: \"a\\\n  \\U0001F680 b\"\n", expected: "note: \"a\\\n  🚀 b\"\n"},
+		{name: "comment", input: `key: value # "\U0001F680"`, expected: `key: value # "\U0001F680"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, UnescapeUnicode(tt.input))
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		original := map[string]any{"note": "デプロイ 🚀 done ✅", "path": `C:\U0001F680`}
+		data, err := yaml.Marshal(original)
+		assert.NoError(t, err)
+		var parsed map[string]any
+		assert.NoError(t, yaml.Unmarshal([]byte(UnescapeUnicode(string(data))), &parsed))
+		assert.Equal(t, original, parsed)
+		assert.Contains(t, UnescapeUnicode(string(data)), "デプロイ 🚀 done ✅")
+	})
+}
@@ -0,0 +1,152 @@
+// Package snapshot captures live Kubernetes resources as normalized YAML bundles that can be compared with diff.
+package snapshot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/masking"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// volatileMetadata lists metadata fields set by the API server that change without a spec change
+var volatileMetadata = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "selfLink"}
+
+// DeploymentRevisionAnnotation is the annotation the Deployment controller sets to the revision of the rollout
+const DeploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// Options controls how resources are normalized
+type Options struct {
+	KeepStatus            bool   // Keep the status of resources (default: false)
+	DisableMaskingSecrets bool   // Keep Secret values instead of replacing them with hashes (default: false)
+	HashKey               []byte // Secret key of the HMAC Secret values are replaced with, required unless DisableMaskingSecrets is set
+	KeepManagedFields     bool   // Keep metadata.managedFields, e.g. to compare the fields of a field manager (default: false)
+	KeepLastApplied       bool   // Keep the last-applied-configuration annotation, e.g. to compare it (default: false)
+}
+
+// DefaultOptions returns the default snapshot options
+func DefaultOptions() *Options {
+	return &Options{
+		KeepStatus:            false,
+		DisableMaskingSecrets: false,
+	}
+}
+
+// Validate returns an error if Secret values are hashed without a key, since unkeyed hashes of short or
+// guessable values can be reversed by trying candidates
+func (o *Options) Validate() error {
+	if !o.DisableMaskingSecrets && len(o.HashKey) == 0 {
+		return errors.New("hashing secret values requires a hash key")
+	}
+	return nil
+}
+
+// Normalize returns a copy of obj without server-populated fields.
+// Secret values are replaced with their HMAC-SHA256 under opts.HashKey so that snapshots taken at
+// different times with the same key can be compared without storing the values.
+func Normalize(obj *unstructured.Unstructured, opts *Options) (*unstructured.Unstructured, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	normalized := obj.DeepCopy()
+	for _, field := range volatileMetadata {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", field)
+	}
+	if !opts.KeepManagedFields {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", "managedFields")
+	}
+	if !opts.KeepLastApplied {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", "annotations", diff.LastAppliedAnnotation)
+	}
+	unstructured.RemoveNestedField(normalized.Object, "metadata", "annotations", DeploymentRevisionAnnotation)
+	if annotations, found, _ := unstructured.NestedMap(normalized.Object, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", "annotations")
+	}
+	if !opts.KeepStatus {
+		unstructured.RemoveNestedField(normalized.Object, "status")
+	}
+	if !opts.DisableMaskingSecrets && masking.HasSecretValues(normalized) {
+		hashSecretValues(normalized, opts.HashKey)
+	}
+	return normalized, nil
+}
+
+// hashSecretValues replaces the secret values of obj, see masking.SecretValuePaths, with "hmac-sha256:<hex>" of
+// the original value under hashKey. Hashes in Secret data are base64 encoded so that the Secret stays valid.
+func hashSecretValues(obj *unstructured.Unstructured, hashKey []byte) {
+	for _, path := range masking.SecretValuePaths(obj) {
+		field, _, _ := unstructured.NestedFieldNoCopy(obj.Object, path...)
+		values, ok := field.(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, value := range values {
+			s, ok := value.(string)
+			if !ok || !masking.IsMaskedValue(obj, s) {
+				continue
+			}
+			mac := hmac.New(sha256.New, hashKey)
+			mac.Write([]byte(s))
+			hash := "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+			if masking.IsSecret(obj) && path[0] == "data" {
+				hash = base64.StdEncoding.EncodeToString([]byte(hash))
+			}
+			values[key] = hash
+		}
+	}
+}
+
+// Take normalizes objects and sorts them by group, kind, namespace and name
+func Take(objs []*unstructured.Unstructured, opts *Options) ([]*unstructured.Unstructured, error) {
+	normalized := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		n, err := Normalize(obj, opts)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, n)
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return sortKey(normalized[i]) < sortKey(normalized[j])
+	})
+	return normalized, nil
+}
+
+// sortKey returns the key resources are ordered by in a snapshot
+func sortKey(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return strings.Join([]string{gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName()}, "\x00")
+}
+
+// Write writes a snapshot as a multi-document YAML bundle with a header recording when it was taken
+func Write(w io.Writer, objs []*unstructured.Unstructured, takenAt time.Time) error {
+	if _, err := fmt.Fprintf(w, "# k8s-manifest-diff snapshot taken at %s (%d resources)\n", takenAt.UTC().Format(time.RFC3339), len(objs)); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+	return nil
+}
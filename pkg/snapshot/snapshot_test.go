@@ -0,0 +1,138 @@
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/parser"
+)
+
+const liveResources = `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+  uid: 6b1f0c1e-5a2b-4c3d-8e9f-0a1b2c3d4e5f
+  resourceVersion: "101"
+data:
+  password: cGFzc3dvcmQ=
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  generation: 4
+  creationTimestamp: "2026-01-01T00:00:00Z"
+  annotations:
+    deployment.kubernetes.io/revision: "3"
+    kubectl.kubernetes.io/last-applied-configuration: '{}'
+  managedFields:
+  - manager: kubectl
+spec:
+  replicas: 2
+status:
+  readyReplicas: 2
+`
+
+// testHashKey is the key Secret values are hashed with in tests
+var testHashKey = []byte("test-key")
+
+func TestTake(t *testing.T) {
+	objs, err := parser.ParseYAML(strings.NewReader(liveResources))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		opts     *Options
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "default",
+			opts:     &Options{HashKey: testHashKey},
+			contains: []string{"replicas: 2", "password: aG1hYy1zaGEyNTY6OWRhYzFlNWIwNzcxYmJjNTQ5MmY4OWMzNDcxYmFhYjVjNTY5ZmU5MTBjMjdiNjYxNmFmZWM0OWNiZjFhYzJhZg=="},
+			excludes: []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "last-applied-configuration", "deployment.kubernetes.io/revision", "annotations", "status", "cGFzc3dvcmQ="},
+		},
+		{
+			name:     "keep status",
+			opts:     &Options{KeepStatus: true, HashKey: testHashKey},
+			contains: []string{"readyReplicas: 2"},
+		},
+		{
+			name:     "keep managed fields and last-applied configuration",
+			opts:     &Options{KeepManagedFields: true, KeepLastApplied: true, HashKey: testHashKey},
+			contains: []string{"manager: kubectl", "last-applied-configuration"},
+			excludes: []string{"deployment.kubernetes.io/revision"},
+		},
+		{
+			name:     "masking disabled",
+			opts:     &Options{DisableMaskingSecrets: true},
+			contains: []string{"password: cGFzc3dvcmQ="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			resources, err := Take(objs, tt.opts)
+			require.NoError(t, err)
+			require.NoError(t, Write(&buf, resources, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+			output := buf.String()
+			assert.True(t, strings.HasPrefix(output, "# k8s-manifest-diff snapshot taken at 2026-03-01T12:00:00Z (2 resources)\n---\n"))
+			// Resources are sorted by group, so the core Secret comes before the apps Deployment
+			assert.Less(t, strings.Index(output, "kind: Secret"), strings.Index(output, "kind: Deployment"))
+			for _, s := range tt.contains {
+				assert.Contains(t, output, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, output, s)
+			}
+		})
+	}
+
+	t.Run("input objects are not modified", func(t *testing.T) {
+		_, err := Take(objs, &Options{HashKey: testHashKey})
+		require.NoError(t, err)
+		assert.Equal(t, "101", objs[0].GetResourceVersion())
+	})
+
+	t.Run("hashing requires a key", func(t *testing.T) {
+		_, err := Take(objs, DefaultOptions())
+		assert.ErrorContains(t, err, "hashing secret values requires a hash key")
+	})
+
+	t.Run("hashes depend on the key", func(t *testing.T) {
+		first, err := Take(objs, &Options{HashKey: testHashKey})
+		require.NoError(t, err)
+		second, err := Take(objs, &Options{HashKey: []byte("other-key")})
+		require.NoError(t, err)
+		assert.NotEqual(t, first[0].Object["data"], second[0].Object["data"])
+	})
+}
+
+func TestSnapshotsCanBeDiffed(t *testing.T) {
+	before, err := parser.ParseYAML(strings.NewReader(liveResources))
+	require.NoError(t, err)
+	after, err := parser.ParseYAML(strings.NewReader(strings.ReplaceAll(strings.Replace(liveResources, "replicas: 2", "replicas: 3", 1), `resourceVersion: "101"`, `resourceVersion: "202"`)))
+	require.NoError(t, err)
+
+	opts := &Options{HashKey: testHashKey}
+	beforeResources, err := Take(before, opts)
+	require.NoError(t, err)
+	afterResources, err := Take(after, opts)
+	require.NoError(t, err)
+	var beforeBuf, afterBuf bytes.Buffer
+	require.NoError(t, Write(&beforeBuf, beforeResources, time.Now()))
+	require.NoError(t, Write(&afterBuf, afterResources, time.Now()))
+
+	results, err := diff.YamlString(beforeBuf.String(), afterBuf.String(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, diff.Unchanged, results[diff.ResourceKey{Kind: "Secret", Namespace: "default", Name: "db"}].Type)
+	assert.Equal(t, diff.Changed, results[diff.ResourceKey{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "web"}].Type)
+}
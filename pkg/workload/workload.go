@@ -0,0 +1,121 @@
+// Package workload describes where workload kinds keep their pod spec and replica count, so that image changes
+// and capacity checks cover custom workloads such as Argo Rollouts and Knative Services alongside the built-in kinds.
+package workload
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Kind describes a workload kind by the dot-separated paths of its fields
+type Kind struct {
+	Group    string `yaml:"group"`
+	Kind     string `yaml:"kind"`
+	PodSpec  string `yaml:"podSpec"`            // Path of the pod spec, e.g. "spec.template.spec"
+	Replicas string `yaml:"replicas,omitempty"` // Path of the replica count, e.g. "spec.replicas"; empty when the number of pods is not fixed
+	Scalable bool   `yaml:"scalable,omitempty"` // Replicas are checked against PodDisruptionBudgets and HorizontalPodAutoscalers
+}
+
+// PodSpecPath returns the path of the pod spec as fields
+func (k Kind) PodSpecPath() []string {
+	return strings.Split(k.PodSpec, ".")
+}
+
+// ReplicasPath returns the path of the replica count as fields, or nil if the kind has none
+func (k Kind) ReplicasPath() []string {
+	if k.Replicas == "" {
+		return nil
+	}
+	return strings.Split(k.Replicas, ".")
+}
+
+// TemplateLabelsPath returns the path of the pod template labels, next to the pod spec, or nil for Pods
+func (k Kind) TemplateLabelsPath() []string {
+	path := k.PodSpecPath()
+	if len(path) < 2 {
+		return nil
+	}
+	return append(append([]string{}, path[:len(path)-1]...), "metadata", "labels")
+}
+
+// Policy lists the workload kinds known to the image and capacity analyses
+type Policy struct {
+	Kinds []Kind `yaml:"workloads"`
+}
+
+// builtinKinds are the workload kinds of the Kubernetes API.
+// DaemonSets and CronJobs have no replica count as their number of pods depends on the cluster and schedule.
+var builtinKinds = []Kind{
+	{Kind: "Pod", PodSpec: "spec"},
+	{Kind: "ReplicationController", PodSpec: "spec.template.spec", Replicas: "spec.replicas"},
+	{Group: "apps", Kind: "Deployment", PodSpec: "spec.template.spec", Replicas: "spec.replicas", Scalable: true},
+	{Group: "apps", Kind: "StatefulSet", PodSpec: "spec.template.spec", Replicas: "spec.replicas", Scalable: true},
+	{Group: "apps", Kind: "ReplicaSet", PodSpec: "spec.template.spec", Replicas: "spec.replicas", Scalable: true},
+	{Group: "apps", Kind: "DaemonSet", PodSpec: "spec.template.spec"},
+	{Group: "batch", Kind: "Job", PodSpec: "spec.template.spec", Replicas: "spec.parallelism"},
+	{Group: "batch", Kind: "CronJob", PodSpec: "spec.jobTemplate.spec.template.spec"},
+}
+
+// Builtin returns the policy of the workload kinds of the Kubernetes API
+func Builtin() *Policy {
+	return &Policy{Kinds: append([]Kind{}, builtinKinds...)}
+}
+
+// Read reads workload kinds from YAML and returns them together with the built-in kinds, e.g.
+//
+//	workloads:
+//	- group: argoproj.io
+//	  kind: Rollout
+//	  podSpec: spec.template.spec
+//	  replicas: spec.replicas
+//	  scalable: true
+//	- group: serving.knative.dev
+//	  kind: Service
+//	  podSpec: spec.template.spec
+//
+// A configured kind replaces the built-in kind of the same group and kind.
+func Read(r io.Reader) (*Policy, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workload policy: %w", err)
+	}
+
+	var configured Policy
+	if err := yaml.UnmarshalStrict(data, &configured); err != nil {
+		return nil, fmt.Errorf("failed to parse workload policy: %w", err)
+	}
+	for i, kind := range configured.Kinds {
+		if kind.Kind == "" || kind.PodSpec == "" {
+			return nil, fmt.Errorf("workload policy rule %d: kind and podSpec are required", i+1)
+		}
+		if kind.Scalable && kind.Replicas == "" {
+			return nil, fmt.Errorf("workload policy rule %d: scalable kinds require replicas", i+1)
+		}
+	}
+
+	policy := &Policy{}
+	for _, builtin := range builtinKinds {
+		if _, ok := configured.Lookup(builtin.Group, builtin.Kind); !ok {
+			policy.Kinds = append(policy.Kinds, builtin)
+		}
+	}
+	policy.Kinds = append(policy.Kinds, configured.Kinds...)
+	return policy, nil
+}
+
+// Lookup returns the workload kind of group and kind. A nil policy looks up the built-in kinds.
+func (p *Policy) Lookup(group, kind string) (Kind, bool) {
+	kinds := builtinKinds
+	if p != nil {
+		kinds = p.Kinds
+	}
+	for _, k := range kinds {
+		if k.Group == group && k.Kind == kind {
+			return k, true
+		}
+	}
+	return Kind{}, false
+}
@@ -0,0 +1,68 @@
+package workload
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	policy, err := Read(strings.NewReader(`
+workloads:
+- group: argoproj.io
+  kind: Rollout
+  podSpec: spec.template.spec
+  replicas: spec.replicas
+  scalable: true
+- group: apps
+  kind: DaemonSet
+  podSpec: spec.template.spec
+  replicas: status.desiredNumberScheduled
+`))
+	require.NoError(t, err)
+
+	rollout, ok := policy.Lookup("argoproj.io", "Rollout")
+	require.True(t, ok)
+	assert.Equal(t, []string{"spec", "template", "spec"}, rollout.PodSpecPath())
+	assert.Equal(t, []string{"spec", "replicas"}, rollout.ReplicasPath())
+	assert.Equal(t, []string{"spec", "template", "metadata", "labels"}, rollout.TemplateLabelsPath())
+	assert.True(t, rollout.Scalable)
+
+	daemonSet, ok := policy.Lookup("apps", "DaemonSet")
+	require.True(t, ok)
+	assert.Equal(t, "status.desiredNumberScheduled", daemonSet.Replicas)
+
+	_, ok = policy.Lookup("apps", "Deployment")
+	assert.True(t, ok, "built-in kinds are kept")
+}
+
+func TestRead_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "unknown field", input: "workloads:\n- kind: Rollout\n  template: spec\n", err: "failed to parse workload policy"},
+		{name: "missing podSpec", input: "workloads:\n- kind: Rollout\n", err: "rule 1: kind and podSpec are required"},
+		{name: "scalable without replicas", input: "workloads:\n- kind: Rollout\n  podSpec: spec.template.spec\n  scalable: true\n", err: "rule 1: scalable kinds require replicas"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.input))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestLookup_Builtin(t *testing.T) {
+	var policy *Policy
+	pod, ok := policy.Lookup("", "Pod")
+	require.True(t, ok)
+	assert.Nil(t, pod.ReplicasPath())
+	assert.Nil(t, pod.TemplateLabelsPath())
+
+	_, ok = policy.Lookup("argoproj.io", "Rollout")
+	assert.False(t, ok)
+}
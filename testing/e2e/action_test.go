@@ -0,0 +1,80 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionModeE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	runAction := func(t *testing.T, extraEnv ...string) (CommandResult, string, string) {
+		t.Helper()
+		dir := t.TempDir()
+		outputFile := filepath.Join(dir, "output")
+		summaryFile := filepath.Join(dir, "summary")
+		env := append([]string{
+			"GITHUB_OUTPUT=" + outputFile,
+			"GITHUB_STEP_SUMMARY=" + summaryFile,
+		}, extraEnv...)
+
+		result := runDiffCommandWithEnv(env, "--action")
+
+		outputs, _ := os.ReadFile(outputFile)
+		summary, _ := os.ReadFile(summaryFile)
+		return result, string(outputs), string(summary)
+	}
+
+	t.Run("changes are reported as outputs and step summary", func(t *testing.T) {
+		result, outputs, summary := runAction(t, "INPUT_BASE="+baseFile, "INPUT_HEAD="+headFile)
+
+		assert.Equal(t, 0, result.ExitCode, "Action mode should not fail on changes by default: %s", result.Output)
+		assertDiffOutput(t, result, []string{"# Summary:"})
+		assert.Contains(t, outputs, "has-changes=true\n")
+		assert.Contains(t, summary, "# Kubernetes Manifest Diff")
+		assert.Contains(t, summary, "```diff")
+	})
+
+	t.Run("options json", func(t *testing.T) {
+		result, _, summary := runAction(t,
+			"INPUT_BASE="+baseFile,
+			"INPUT_HEAD="+headFile,
+			`INPUT_OPTIONS_JSON={"summary": true, "context": 1, "exclude-kinds": ["ConfigMap"]}`,
+		)
+
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assert.NotContains(t, summary, "```diff")
+		assert.NotContains(t, summary, "ConfigMap")
+	})
+
+	t.Run("no differences", func(t *testing.T) {
+		result, outputs, summary := runAction(t, "INPUT_BASE="+baseFile, "INPUT_HEAD="+baseFile)
+
+		assert.Equal(t, 0, result.ExitCode)
+		assertDiffOutput(t, result, []string{"No differences found"})
+		assert.Contains(t, outputs, "has-changes=false\n")
+		assert.Contains(t, summary, "No differences found")
+	})
+
+	t.Run("fail on changes", func(t *testing.T) {
+		result, _, _ := runAction(t, "INPUT_BASE="+baseFile, "INPUT_HEAD="+headFile, "INPUT_FAIL_ON_CHANGES=true")
+		assert.Equal(t, 1, result.ExitCode)
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		result, _, _ := runAction(t, "INPUT_BASE="+baseFile, "INPUT_HEAD="+headFile, `INPUT_OPTIONS_JSON={"no-such-flag": true}`)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{`unknown option "no-such-flag"`})
+	})
+
+	t.Run("missing inputs", func(t *testing.T) {
+		result, _, _ := runAction(t)
+		require.Equal(t, 2, result.ExitCode)
+		assertDiffOutput(t, result, []string{"INPUT_BASE and INPUT_HEAD must be set"})
+	})
+}
@@ -127,6 +127,19 @@ func TestAnnotationSelectorE2E(t *testing.T) {
 				"No differences found",
 			},
 		},
+		{
+			name:       "regex key without tilde selects any value",
+			args:       []string{"diff", "fixtures/selectors/annotation-test-base.yaml", "fixtures/selectors/annotation-test-head.yaml", "--annotation=deployment.kubernetes.io/.*="},
+			expectDiff: true,
+			expectedOutput: []string{
+				"frontend-app",
+				"backend-app",
+			},
+			notExpected: []string{
+				"app-config",
+				"db-secret",
+			},
+		},
 		{
 			name:       "mixed label and annotation selectors",
 			args:       []string{"diff", "fixtures/selectors/annotation-test-base.yaml", "fixtures/selectors/annotation-test-head.yaml", "--label=tier=frontend", "--annotation=app.kubernetes.io/managed-by=helm"},
@@ -0,0 +1,144 @@
+package e2e
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readFixtureTree returns the files below a fixture directory by slash-separated relative path
+func readFixtureTree(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path) // #nosec G304 - test fixture path
+		files[filepath.ToSlash(rel)] = data
+		return err
+	})
+	require.NoError(t, err)
+	return files
+}
+
+// sortedNames returns the file names in lexical order so archives are reproducible
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tarGz packs files into a gzipped tarball under prefix
+func tarGz(t *testing.T, prefix string, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range sortedNames(files) {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: prefix + name, Mode: 0o600, Size: int64(len(files[name])), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(files[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// zipArchive packs files into a zip archive
+func zipArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range sortedNames(files) {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(files[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestArchiveInputs(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.tgz")
+	head := filepath.Join(dir, "head.zip")
+	nested := filepath.Join(dir, "nested.zip")
+	headFiles := readFixtureTree(t, getFixturePath("dirs", "head"))
+	require.NoError(t, os.WriteFile(base, tarGz(t, "./", readFixtureTree(t, getFixturePath("dirs", "base"))), 0o600))
+	require.NoError(t, os.WriteFile(head, zipArchive(t, headFiles), 0o600))
+	require.NoError(t, os.WriteFile(nested, zipArchive(t, map[string][]byte{"artifacts/head.tgz": tarGz(t, "", headFiles)}), 0o600))
+
+	tests := []struct {
+		name       string
+		args       []string
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:       "tarball and zip",
+			args:       []string{"diff", "--summary", "--show-unchanged", base, head},
+			expected:   []string{"Deployment/default/web", "ConfigMap/default/web-config", "ConfigMap/default/example"},
+			unexpected: []string{"redis-config"},
+		},
+		{
+			name:     "negated pattern re-includes a member below an excluded directory",
+			args:     []string{"diff", "--summary", "--show-unchanged", "--exclude-file-glob", "!charts/vendored/redis.yaml", base, head},
+			expected: []string{"ConfigMap/default/redis-config"},
+		},
+		{
+			name:       "exclude-file-glob applies to archive members",
+			args:       []string{"diff", "--summary", "--exclude-file-glob", "examples/**", base, head},
+			expected:   []string{"Deployment/default/web"},
+			unexpected: []string{"ConfigMap/default/example"},
+		},
+		{
+			name:     "nested archive",
+			args:     []string{"diff", "--summary", base, nested},
+			expected: []string{"Deployment/default/web", "ConfigMap/default/example"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runDiffCommand(tt.args...)
+			assertHasDiff(t, result)
+			assertDiffOutput(t, result, tt.expected)
+			assertNotInOutput(t, result, tt.unexpected)
+		})
+	}
+
+	t.Run("corrupt archive", func(t *testing.T) {
+		corrupt := filepath.Join(dir, "corrupt.tgz")
+		require.NoError(t, os.WriteFile(corrupt, []byte("not gzip"), 0o600))
+		result := runDiffCommand("diff", base, corrupt)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"failed to open archive"})
+	})
+
+	t.Run("extracted contents are bounded as a whole", func(t *testing.T) {
+		// Each member fits the limit, but together they exceed it
+		member := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: filler\ndata:\n  a: " + strings.Repeat("x", 600) + "\n")
+		bomb := filepath.Join(dir, "bomb.zip")
+		require.NoError(t, os.WriteFile(bomb, zipArchive(t, map[string][]byte{"a.yaml": member, "b.yaml": member}), 0o600))
+		result := runDiffCommand("diff", "--max-input-size", "1000", base, bomb)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"contents larger than --max-input-size 1000"})
+	})
+}
@@ -1,10 +1,13 @@
 package e2e
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBasicDiffE2E(t *testing.T) {
@@ -117,3 +120,77 @@ func TestDiffExitCodes(t *testing.T) {
 		})
 	}
 }
+
+func TestOnlyE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	t.Run("only selected resource", func(t *testing.T) {
+		result := runDiffCommand("diff", "--only", "ConfigMap/*/*", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"ConfigMap"})
+		assert.NotContains(t, result.Output, "Deployment")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		result := runDiffCommand("diff", "--only", "Service/*/*", baseFile, headFile)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No differences found"})
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		result := runDiffCommand("diff", "--only", "ConfigMap/[", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"invalid only pattern"})
+	})
+}
+
+func TestResultFilterE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	t.Run("printed output is filtered but saved results are complete", func(t *testing.T) {
+		resultsFile := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", "--summary", "--filter-kind", "ConfigMap", "--save", resultsFile, baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"ConfigMap/default/app-config"})
+		assert.NotContains(t, result.Output, "Deployment")
+
+		shown := runDiffCommand("show", "--summary", resultsFile)
+		assert.Equal(t, 1, shown.ExitCode, shown.Output)
+		assertDiffOutput(t, shown, []string{"ConfigMap/default/app-config", "Deployment/default/backend-app"})
+	})
+
+	t.Run("filters are combined", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--filter-namespace", "default", "--filter-name", "backend-app", "--filter-name", "app-config", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Deployment/default/backend-app", "ConfigMap/default/app-config"})
+		assert.NotContains(t, result.Output, "frontend-app")
+	})
+
+	t.Run("no matching changes", func(t *testing.T) {
+		result := runDiffCommand("diff", "--filter-change-type", "created", baseFile, headFile)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No differences found"})
+	})
+
+	t.Run("invalid change type", func(t *testing.T) {
+		result := runDiffCommand("diff", "--filter-change-type", "moved", baseFile, headFile)
+		assertError(t, result)
+	})
+}
+
+func TestEmitPruneScriptE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "secret-mixed-head.yaml")
+	scriptFile := filepath.Join(t.TempDir(), "prune.sh")
+
+	result := runDiffCommand("diff", "--summary", "--emit-prune-script", scriptFile, baseFile, headFile)
+	assert.Equal(t, 1, result.ExitCode, result.Output)
+
+	script, err := os.ReadFile(scriptFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "kubectl delete deployment.apps frontend-app --namespace default\n")
+	assert.Contains(t, string(script), "kubectl delete configmap app-config --namespace default\n")
+	assert.NotContains(t, string(script), "mixed-secret")
+}
@@ -0,0 +1,52 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaCheckE2E(t *testing.T) {
+	baseFile := getFixturePath("analyzer", "quota-base.yaml")
+	headFile := getFixturePath("analyzer", "quota-head.yaml")
+
+	t.Run("quota impact and warnings", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--checks", "quota", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{
+			"# Quota impact (1 namespaces):",
+			"#   team-a: cpu 1 -> 2500m (+1500m), memory 512Mi -> 1280Mi (+768Mi)",
+			"# Quota warnings (1):",
+			"#   ResourceQuota/team-a/compute: requested cpu 2500m exceeds requests.cpu 2",
+		})
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--checks", "quota", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"## Quota Impact", "## Quota Warnings (1)"})
+	})
+
+	t.Run("no checks by default", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", baseFile, headFile)
+		assert.NotContains(t, result.Output, "Quota impact")
+	})
+
+	t.Run("unknown check", func(t *testing.T) {
+		result := runDiffCommand("diff", "--checks", "nope", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"unknown check: nope"})
+	})
+}
+
+func TestConsistencyCheckE2E(t *testing.T) {
+	baseFile := getFixturePath("analyzer", "consistency-base.yaml")
+	headFile := getFixturePath("analyzer", "consistency-head.yaml")
+
+	result := runDiffCommand("diff", "--summary", "--checks", "consistency", baseFile, headFile)
+	assert.Equal(t, 1, result.ExitCode, result.Output)
+	assertDiffOutput(t, result, []string{
+		"# Consistency warnings (1):",
+		"#   Deployment/default/web: replicas 1 is below minAvailable 2 of PodDisruptionBudget/default/web",
+	})
+}
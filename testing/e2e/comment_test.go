@@ -0,0 +1,63 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentCommandE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+	dir := t.TempDir()
+
+	run := filepath.Join(dir, "results.json")
+	result := runDiffCommand("diff", "--save", run, baseFile, headFile)
+	require.Equal(t, 1, result.ExitCode, result.Output)
+
+	t.Run("report fits in one comment", func(t *testing.T) {
+		result := runDiffCommand("comment", run)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"# Kubernetes Manifest Diff", "```diff"})
+		assert.NotContains(t, result.Output, "Part 1 of")
+	})
+
+	t.Run("split into comment files", func(t *testing.T) {
+		outputDir := t.TempDir()
+		result := runDiffCommand("comment", "--max-length", "600", "--output-dir", outputDir, run)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+
+		first, err := os.ReadFile(filepath.Join(outputDir, "comment-1.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(first), "## Summary")
+		assert.Contains(t, string(first), "_Part 1 of ")
+		assert.NotContains(t, string(first), "```diff")
+
+		second, err := os.ReadFile(filepath.Join(outputDir, "comment-2.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(second), "## Resource Changes (continued)")
+		assert.LessOrEqual(t, len(second), 601)
+	})
+
+	t.Run("summary linking to an artifact", func(t *testing.T) {
+		outputDir := t.TempDir()
+		result := runDiffCommand("comment", "--max-length", "600", "--full-report", "artifact",
+			"--output-dir", outputDir, "--report-url", "https://example.com/runs/1", run)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+
+		comment, err := os.ReadFile(filepath.Join(outputDir, "comment-1.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(comment), "**Full report**: https://example.com/runs/1")
+		assert.NoFileExists(t, filepath.Join(outputDir, "comment-2.md"))
+		assert.FileExists(t, filepath.Join(outputDir, "report.md"))
+	})
+
+	t.Run("artifact requires a report url", func(t *testing.T) {
+		result := runDiffCommand("comment", "--max-length", "600", "--full-report", "artifact", run)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"requires --output-dir and --report-url"})
+	})
+}
@@ -136,3 +136,44 @@ func TestContextLinesValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandBelowLinesE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	t.Run("small resources are shown in full", func(t *testing.T) {
+		result := runDiffCommand("diff", "--context", "0", "--expand-below-lines", "1000", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"apiVersion: apps/v1", "kind: ConfigMap"})
+	})
+
+	t.Run("larger resources keep hunks", func(t *testing.T) {
+		result := runDiffCommand("diff", "--context", "0", "--expand-below-lines", "5", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assert.NotContains(t, result.Output, "apiVersion:")
+	})
+}
+
+func TestFullObjectsE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	t.Run("append", func(t *testing.T) {
+		result := runDiffCommand("diff", "--full-objects", "append", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"@@", "# base:\n  apiVersion: ", "# head:\n  apiVersion: "})
+	})
+
+	t.Run("only", func(t *testing.T) {
+		result := runDiffCommand("diff", "--full-objects", "only", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"# base:\n  apiVersion: ", "# head:\n  apiVersion: "})
+		assert.NotContains(t, result.Output, "@@")
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		result := runDiffCommand("diff", "--full-objects", "both", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"invalid full objects mode"})
+	})
+}
@@ -0,0 +1,55 @@
+package e2e
+
+import (
+	"testing"
+)
+
+func TestDirectoryInputs(t *testing.T) {
+	baseDir := getFixturePath("dirs", "base")
+	headDir := getFixturePath("dirs", "head")
+
+	tests := []struct {
+		name       string
+		args       []string
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:       "diffignore skips vendored charts",
+			args:       []string{"diff", "--summary", "--show-unchanged", baseDir, headDir},
+			expected:   []string{"Deployment/default/web", "ConfigMap/default/web-config", "ConfigMap/default/example"},
+			unexpected: []string{"redis-config"},
+		},
+		{
+			name:       "exclude-file-glob adds patterns",
+			args:       []string{"diff", "--summary", "--exclude-file-glob", "examples/**", baseDir, headDir},
+			expected:   []string{"Deployment/default/web"},
+			unexpected: []string{"redis-config", "ConfigMap/default/example"},
+		},
+		{
+			name:     "negated pattern re-includes a file",
+			args:     []string{"diff", "--summary", "--show-unchanged", "--exclude-file-glob", "**/*.yml", "--exclude-file-glob", "!apps/config.yml", baseDir, headDir},
+			expected: []string{"ConfigMap/default/web-config"},
+		},
+		{
+			name:     "negated pattern re-includes a file below an excluded directory",
+			args:     []string{"diff", "--summary", "--show-unchanged", "--exclude-file-glob", "!charts/vendored/redis.yaml", baseDir, headDir},
+			expected: []string{"ConfigMap/default/redis-config", "ConfigMap/default/web-config"},
+		},
+		{
+			name:       "exclude-file-glob matches at any depth",
+			args:       []string{"diff", "--summary", "--show-unchanged", "--exclude-file-glob", "**/*.yml", baseDir, headDir},
+			expected:   []string{"Deployment/default/web"},
+			unexpected: []string{"web-config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runDiffCommand(tt.args...)
+			assertHasDiff(t, result)
+			assertDiffOutput(t, result, tt.expected)
+			assertNotInOutput(t, result, tt.unexpected)
+		})
+	}
+}
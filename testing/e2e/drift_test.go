@@ -0,0 +1,198 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftE2E(t *testing.T) {
+	desired := getFixturePath("live", "desired.yaml")
+
+	t.Run("no drift", func(t *testing.T) {
+		result := runDiffCommand("drift", "--once", "--live-file", desired, desired)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No drift found"})
+	})
+
+	t.Run("drift from live file", func(t *testing.T) {
+		result := runDiffCommand("drift", "--once", "--last-applied",
+			"--live-file", getFixturePath("live", "live-export.yaml"), desired)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Deployment/default/web"})
+		assert.True(t, strings.HasSuffix(result.Output, "\n"), "the summary ends with a newline")
+	})
+
+	t.Run("server-populated fields are not drift", func(t *testing.T) {
+		manifests := filepath.Join(t.TempDir(), "manifests.yaml")
+		require.NoError(t, os.WriteFile(manifests, []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+data:
+  password: cGFzc3dvcmQ=
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 2
+`), 0o600))
+		result := runDiffCommand("drift", "--once", "--live-file", getFixturePath("live", "snapshot-before.yaml"), manifests)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No drift found"})
+	})
+
+	t.Run("drift from live command", func(t *testing.T) {
+		result := runDiffCommand("drift", "--once", "--field-manager", "argocd",
+			"--live-command", "cat "+getFixturePath("live", "managed-export.yaml"), desired)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Deployment/default/web"})
+	})
+
+	t.Run("live source is required", func(t *testing.T) {
+		result := runDiffCommand("drift", "--once", desired)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"exactly one of --live-file, --live-command or --live-resources is required"})
+	})
+
+	t.Run("failing live command", func(t *testing.T) {
+		result := runDiffCommand("drift", "--once", "--live-command", "exit 1", desired)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"failed to load live state"})
+	})
+}
+
+func TestDriftLiveResourcesE2E(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	desired := getFixturePath("live", "desired.yaml")
+
+	// The fake kubectl records its arguments and prints a live export
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\n" +
+		"echo \"$@\" > \"" + argsFile + "\"\n" +
+		"cat \"" + getFixturePath("live", "managed-export.yaml") + "\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755)) // #nosec G306 - test script must be executable
+	env := []string{"PATH=" + bin + string(os.PathListSeparator) + os.Getenv("PATH"), "K8S_MANIFEST_DIFF_MASK_KEY=snapshot-key"}
+
+	t.Run("cluster flags are passed to kubectl", func(t *testing.T) {
+		result := runDiffCommandWithEnv(env, "drift", "--once", "--field-manager", "argocd",
+			"--live-resources", "deploy,svc", "--live-namespace", "default",
+			"--kubeconfig", "/etc/kube/config", "--kube-context", "prod", "--as", "auditor", "--as-group", "viewers",
+			"--chunk-size", "100", "--request-timeout", "30s", desired)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Deployment/default/web"})
+
+		args, err := os.ReadFile(argsFile) // #nosec G304 - test file in a temp dir
+		require.NoError(t, err)
+		assert.Equal(t, "get deploy,svc --output yaml --namespace default --kubeconfig /etc/kube/config --context prod "+
+			"--as auditor --as-group viewers --chunk-size 100 --request-timeout 30s\n", string(args))
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		result := runDiffCommandWithEnv(env, "snapshot", "--live-resources", "deploy", "--live-all-namespaces")
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"name: web"})
+
+		args, err := os.ReadFile(argsFile) // #nosec G304 - test file in a temp dir
+		require.NoError(t, err)
+		assert.Equal(t, "get deploy --output yaml --all-namespaces --chunk-size 500\n", string(args))
+	})
+
+	t.Run("server-populated fields are not drift", func(t *testing.T) {
+		serverEnv := []string{"PATH=" + serverFieldsKubectl(t) + string(os.PathListSeparator) + os.Getenv("PATH")}
+		result := runDiffCommandWithEnv(serverEnv, "drift", "--once", "--live-resources", "deploy", desired)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No drift found"})
+
+		result = runDiffCommandWithEnv(serverEnv, "snapshot", "--disable-masking-secret", "--live-resources", "deploy")
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"name: web"})
+		assertNotInOutput(t, result, []string{"uid:", "resourceVersion:", "managedFields:", `creationTimestamp: "`, "status:", "deployment.kubernetes.io/revision"})
+	})
+
+	t.Run("server-defaulted fields are drift without normalization", func(t *testing.T) {
+		serverEnv := []string{"PATH=" + serverFieldsKubectl(t) + string(os.PathListSeparator) + os.Getenv("PATH")}
+		result := runDiffCommandWithEnv(serverEnv, "drift", "--once", "--normalize-known-kinds=false", "--live-resources", "deploy", desired)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Deployment/default/web"})
+	})
+
+	t.Run("cluster flags require live resources", func(t *testing.T) {
+		result := runDiffCommandWithEnv(env, "drift", "--once", "--kube-context", "prod", "--live-file", desired, desired)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--kube-context only applies to --live-resources"})
+	})
+
+	t.Run("impersonated groups require a user", func(t *testing.T) {
+		result := runDiffCommandWithEnv(env, "snapshot", "--live-resources", "deploy", "--as-group", "viewers")
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"impersonating groups requires a user to impersonate"})
+	})
+}
+
+func TestDriftLiveDiscoverE2E(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+	desired := getFixturePath("live", "desired.yaml")
+
+	// The fake kubectl records its arguments and prints the desired Deployment and a Deployment missing from the manifests
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	liveFile := filepath.Join(bin, "live.yaml")
+	manifest, err := os.ReadFile(desired) // #nosec G304 - test fixture
+	require.NoError(t, err)
+	orphan := strings.Replace(string(manifest), "name: web", "name: legacy", 1)
+	require.NoError(t, os.WriteFile(liveFile, []byte(string(manifest)+"\n---\n"+orphan), 0o600))
+	script := "#!/bin/sh\n" +
+		"echo \"$@\" > \"" + argsFile + "\"\n" +
+		"cat \"" + liveFile + "\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755)) // #nosec G306 - test script must be executable
+	env := []string{"PATH=" + bin + string(os.PathListSeparator) + os.Getenv("PATH")}
+
+	t.Run("live resources missing from manifests are prune candidates", func(t *testing.T) {
+		result := runDiffCommandWithEnv(env, "drift", "--once", "--live-discover", "--live-selector", "app=web", "--live-namespace", "default", desired)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Prune candidates (live but not in the manifests):", "apps/Deployment/default/legacy"})
+
+		args, err := os.ReadFile(argsFile) // #nosec G304 - test file in a temp dir
+		require.NoError(t, err)
+		assert.Equal(t, "get Deployment.apps --output yaml --namespace default --selector app=web --chunk-size 500\n", string(args))
+	})
+
+	t.Run("server-populated fields are not drift", func(t *testing.T) {
+		serverEnv := []string{"PATH=" + serverFieldsKubectl(t) + string(os.PathListSeparator) + os.Getenv("PATH")}
+		result := runDiffCommandWithEnv(serverEnv, "drift", "--once", "--live-discover", desired)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No drift found"})
+	})
+
+	t.Run("cannot be combined with a live file", func(t *testing.T) {
+		result := runDiffCommandWithEnv(env, "drift", "--once", "--live-discover", "--live-file", desired, desired)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--live-discover cannot be combined with --live-file or --live-command"})
+	})
+}
+
+// serverFieldsKubectl writes a fake kubectl printing the desired Deployment as the API server returns it, with
+// uid, resourceVersion, managedFields, status, defaulted spec fields and other server-populated fields, and
+// returns its directory
+func serverFieldsKubectl(t *testing.T) string {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncat \"" + getFixturePath("live", "server-export.yaml") + "\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "kubectl"), []byte(script), 0o755)) // #nosec G306 - test script must be executable
+	return bin
+}
@@ -0,0 +1,32 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvFlagsE2E(t *testing.T) {
+	baseFile := getFixturePath("kinds", "mixed-base.yaml")
+	headFile := getFixturePath("kinds", "mixed-head.yaml")
+
+	t.Run("environment sets flags", func(t *testing.T) {
+		result := runDiffCommandWithEnv([]string{"K8S_MANIFEST_DIFF_EXCLUDE_KINDS=Deployment,Service"}, "diff", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"test-workflow"})
+		assertNotInOutput(t, result, []string{"apps/Deployment", "/Service"})
+	})
+
+	t.Run("command line takes precedence", func(t *testing.T) {
+		result := runDiffCommandWithEnv([]string{"K8S_MANIFEST_DIFF_EXCLUDE_KINDS=Deployment"}, "diff", baseFile, headFile, "--exclude-kinds=Service")
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"apps/Deployment"})
+		assertNotInOutput(t, result, []string{"/Service"})
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		result := runDiffCommandWithEnv([]string{"K8S_MANIFEST_DIFF_CONTEXT=many"}, "diff", baseFile, headFile)
+		assert.NotEqual(t, 0, result.ExitCode)
+		assertDiffOutput(t, result, []string{"invalid K8S_MANIFEST_DIFF_CONTEXT"})
+	})
+}
@@ -0,0 +1,57 @@
+package e2e
+
+import (
+	"testing"
+)
+
+func TestFilterExpressionE2E(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectError    bool
+		expectedOutput []string
+		notExpected    []string
+	}{
+		{
+			name:           "kind and label expression",
+			args:           []string{"diff", "fixtures/basic/test-base.yaml", "fixtures/basic/test-head.yaml", "--filter-expr", "object.kind == 'Deployment' && object.metadata.labels.tier == 'backend'"},
+			expectedOutput: []string{"backend-app"},
+			notExpected:    []string{"frontend-app", "app-config"},
+		},
+		{
+			name:           "string functions",
+			args:           []string{"diff", "fixtures/basic/test-base.yaml", "fixtures/basic/test-head.yaml", "--filter-expr", "object.metadata.name.startsWith('app-')"},
+			expectedOutput: []string{"app-config"},
+			notExpected:    []string{"frontend-app", "backend-app"},
+		},
+		{
+			name:           "invalid expression",
+			args:           []string{"diff", "fixtures/basic/test-base.yaml", "fixtures/basic/test-head.yaml", "--filter-expr", "object.kind =="},
+			expectError:    true,
+			expectedOutput: []string{"invalid filter expression"},
+		},
+		{
+			name:           "evaluation error",
+			args:           []string{"diff", "fixtures/basic/test-base.yaml", "fixtures/basic/test-head.yaml", "--filter-expr", "object.metadata.annotations.owner == 'team-a'"},
+			expectError:    true,
+			expectedOutput: []string{"failed to filter base:", "failed to evaluate filter expression"},
+		},
+		{
+			name:           "parse command",
+			args:           []string{"parse", "fixtures/basic/test-head.yaml", "--filter-expr", "object.kind == 'ConfigMap'"},
+			expectedOutput: []string{"app-config"},
+			notExpected:    []string{"frontend-app", "backend-app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runDiffCommand(tt.args...)
+			if tt.expectError {
+				assertError(t, result)
+			}
+			assertDiffOutput(t, result, tt.expectedOutput)
+			assertNotInOutput(t, result, tt.notExpected)
+		})
+	}
+}
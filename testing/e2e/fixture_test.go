@@ -0,0 +1,45 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenFixtureE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "plan-base.yaml")
+	dir := t.TempDir()
+	specFile := filepath.Join(dir, "spec.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(`mutations:
+- type: replicas
+  kind: Deployment
+  name: web
+  replicas: 5
+- type: delete
+  kind: ConfigMap
+  name: legacy-config
+`), 0o600))
+
+	t.Run("mutated copy diffs against the manifest", func(t *testing.T) {
+		headFile := filepath.Join(dir, "head.yaml")
+		result := runDiffCommand("testing", "gen-fixture", "--spec", specFile, "--output", headFile, baseFile)
+		require.Equal(t, 0, result.ExitCode, result.Output)
+
+		result = runDiffCommand("diff", "--summary", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{
+			"Changed (1):\n  Deployment/default/web",
+			"Delete (1):\n  ConfigMap/default/legacy-config",
+		})
+	})
+
+	t.Run("mutation changing nothing", func(t *testing.T) {
+		staleSpec := filepath.Join(dir, "stale.yaml")
+		require.NoError(t, os.WriteFile(staleSpec, []byte("mutations:\n- type: delete\n  name: removed\n"), 0o600))
+		result := runDiffCommand("testing", "gen-fixture", "--spec", staleSpec, baseFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"mutation 1 (delete) changed no resources"})
+	})
+}
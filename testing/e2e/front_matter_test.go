@@ -0,0 +1,34 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrontMatterE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	t.Run("block in an html comment", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--front-matter", "yaml", baseFile, headFile)
+		assertHasDiff(t, result)
+		assert.True(t, strings.HasPrefix(result.Output, "<!-- k8s-manifest-diff\n"), result.Output)
+		assertDiffOutput(t, result, []string{"changed: 3\n", "severity: none\n", "-->\n\n"})
+	})
+
+	t.Run("block without changes", func(t *testing.T) {
+		identical := getFixturePath("basic", "identical.yaml")
+		result := runDiffCommand("diff", "--output-format", "markdown", "--front-matter", "json", identical, identical)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assert.True(t, strings.HasPrefix(result.Output, "<!-- k8s-manifest-diff\n{"), result.Output)
+		assertDiffOutput(t, result, []string{`"changed": 0`, "}\n-->\n\nNo differences found"})
+	})
+
+	t.Run("requires markdown output", func(t *testing.T) {
+		result := runDiffCommand("diff", "--front-matter", "yaml", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--front-matter requires --output-format markdown"})
+	})
+}
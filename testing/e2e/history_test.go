@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsHistoryE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+	dir := t.TempDir()
+
+	t.Run("json lines", func(t *testing.T) {
+		history := filepath.Join(dir, "history.jsonl")
+		for i := 0; i < 2; i++ {
+			assertHasDiff(t, runDiffCommand("diff", "--stats-history", history, baseFile, headFile))
+		}
+
+		data, err := os.ReadFile(history) // #nosec G304 - test file in a temp dir
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		require.Len(t, lines, 2)
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+		assert.Equal(t, baseFile, record["base"])
+		assert.Equal(t, headFile, record["head"])
+		assert.Greater(t, record["changed"], 0.0)
+		assert.NotEmpty(t, record["timestamp"])
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		history := filepath.Join(dir, "history.csv")
+		for i := 0; i < 2; i++ {
+			assertHasDiff(t, runDiffCommand("diff", "--summary", "--stats-history", history, baseFile, headFile))
+		}
+
+		data, err := os.ReadFile(history) // #nosec G304 - test file in a temp dir
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		require.Len(t, lines, 3, "the header is written once")
+		assert.Equal(t, "timestamp,base,head,total,changed,created,deleted,unchanged,errors", lines[0])
+		assert.Contains(t, lines[2], ","+baseFile+","+headFile+",")
+	})
+
+	t.Run("fail-fast", func(t *testing.T) {
+		result := runDiffCommand("diff", "--fail-fast", "--stats-history", filepath.Join(dir, "fail-fast.jsonl"), baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--fail-fast cannot be combined with"})
+	})
+}
+
+func TestStatsPushgatewayE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	result := runDiffCommand("diff", "--summary", "--stats-pushgateway", server.URL, baseFile, headFile)
+	assertHasDiff(t, result)
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/k8s-manifest-diff", path)
+	assert.Contains(t, body, `k8s_manifest_diff_resources{base="`+baseFile+`",head="`+headFile+`",type="changed"}`)
+
+	// An unreachable gateway does not fail the diff
+	server.Close()
+	result = runDiffCommand("diff", "--summary", "--stats-pushgateway", server.URL, baseFile, headFile)
+	assertHasDiff(t, result)
+	assertDiffOutput(t, result, []string{"Warning: statistics were not pushed"})
+}
@@ -0,0 +1,57 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/k8s-manifest-diff/pkg/diff"
+)
+
+func TestResultHooksE2E(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run with sh")
+	}
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	t.Run("on change", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", baseFile, headFile, "--on-change-exec", "cat > "+out, "--on-clean-exec", "echo clean >&2")
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assert.NotContains(t, result.Output, "clean")
+
+		f, err := os.Open(out)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		results, err := diff.ReadResults(f)
+		require.NoError(t, err)
+		assert.True(t, results.HasChanges())
+	})
+
+	t.Run("on clean", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", baseFile, baseFile, "--on-change-exec", "cat > "+out, "--on-clean-exec", "echo clean >&2")
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"clean", "No differences found"})
+		assert.NoFileExists(t, out)
+	})
+
+	t.Run("potential secrets are not given to the hook", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "results.json")
+		leakBase, leakHead := getFixturePath("basic", "secret-leak-base.yaml"), getFixturePath("basic", "secret-leak-head.yaml")
+		result := runDiffCommand("diff", "--disable-masking-secret", "--summary", leakBase, leakHead, "--on-change-exec", "cat > "+out)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"potential secrets detected"})
+		assert.NoFileExists(t, out)
+	})
+
+	t.Run("failing hook", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--on-change-exec", "exit 3")
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{`--on-change-exec command "exit 3" failed: exit status 3`})
+	})
+}
@@ -0,0 +1,34 @@
+package e2e
+
+import (
+	"testing"
+)
+
+func TestIgnoreValueRegex(t *testing.T) {
+	baseFile := getFixturePath("basic", "generated-base.yaml")
+	headFile := getFixturePath("basic", "generated-head.yaml")
+
+	t.Run("generated values differ by default", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"deployed-at", "checksum/config"})
+	})
+
+	t.Run("timestamps only", func(t *testing.T) {
+		result := runDiffCommand("diff", "--ignore-value-regex", `\d{4}-\d{2}-\d{2}T`, baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"checksum/config"})
+		assertNotInOutput(t, result, []string{"deployed-at"})
+	})
+
+	t.Run("timestamps and checksums", func(t *testing.T) {
+		result := runDiffCommand("diff", "--ignore-value-regex", `\d{4}-\d{2}-\d{2}T`, "--ignore-value-regex", `^[0-9a-f]{64}$`, baseFile, headFile)
+		assertNoDiff(t, result)
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		result := runDiffCommand("diff", "--ignore-value-regex", "(", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{`invalid ignore value regex "("`})
+	})
+}
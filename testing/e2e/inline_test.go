@@ -0,0 +1,44 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineOutputE2E(t *testing.T) {
+	t.Run("changed resources are shown as annotated head yaml", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "inline",
+			getFixturePath("basic", "test-base.yaml"), getFixturePath("basic", "test-head.yaml"))
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"===== apps/Deployment default/backend-app ======", "  # was: "})
+		assert.NotContains(t, result.Output, "@@")
+	})
+
+	t.Run("secret values stay masked", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "inline",
+			getFixturePath("basic", "secret-with-data-base.yaml"), getFixturePath("basic", "secret-with-data-head.yaml"))
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"password: ", "  # was: ", "new-secret: ", "  # added"})
+		assert.NotContains(t, result.Output, "bmV3cGFzc3dvcmQ=")
+		assert.NotContains(t, result.Output, "bXlwYXNzd29yZA==")
+	})
+}
+
+func TestReportOutputE2E(t *testing.T) {
+	result := runDiffCommand("diff", "--output-format", "report",
+		getFixturePath("basic", "test-base.yaml"), getFixturePath("basic", "test-head.yaml"))
+	assert.Equal(t, 1, result.ExitCode, result.Output)
+	assertDiffOutput(t, result, []string{
+		"===== apps/Deployment default/backend-app ======",
+		"spec.replicas\n  ± value change\n",
+		"spec.template.spec.containers[name=api].image\n  ± value change\n",
+	})
+	assert.NotContains(t, result.Output, "@@")
+
+	t.Run("not available for saved results", func(t *testing.T) {
+		result := runDiffCommand("show", "--output-format", "report", getFixturePath("basic", "test-base.yaml"))
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"report output cannot be rendered from saved results"})
+	})
+}
@@ -0,0 +1,58 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryE2E(t *testing.T) {
+	t.Run("counts resources", func(t *testing.T) {
+		result := runDiffCommand("inventory", getFixturePath("kinds", "mixed-base.yaml"))
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{
+			"# Inventory: 3 resources, 3 kinds, 1 namespaces, 0 excluded, 0 duplicates, 0 warnings",
+			"  Deployment.apps       1",
+			"  Workflow.argoproj.io  1",
+		})
+	})
+
+	t.Run("reports duplicates and warnings", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "app.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+metadata:
+  name: orphan
+`), 0o600))
+
+		result := runDiffCommand("inventory", file)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{
+			"1 duplicates, 1 warnings",
+			"/ConfigMap/config: " + file + ":1, " + file + ":6",
+			file + ":11: document 3 has no apiVersion",
+		})
+	})
+
+	t.Run("filters resources", func(t *testing.T) {
+		result := runDiffCommand("inventory", "--exclude-kinds", "Service,Workflow", getFixturePath("kinds", "mixed-base.yaml"))
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"# Inventory: 1 resources, 1 kinds, 1 namespaces, 2 excluded"})
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		result := runDiffCommand("inventory", "missing.yaml")
+		assertError(t, result)
+	})
+}
@@ -0,0 +1,35 @@
+package e2e
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLinesOutputE2E(t *testing.T) {
+	baseFile := getFixturePath("kinds", "mixed-base.yaml")
+	headFile := getFixturePath("kinds", "mixed-head.yaml")
+
+	t.Run("one line per result", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--output-format", "jsonl", "--filter-change-type", "changed")
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+
+		lines := strings.Split(strings.TrimSpace(result.Output), "\n")
+		require.NotEmpty(t, lines)
+		for _, line := range lines {
+			var resource map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &resource), line)
+			assert.Equal(t, "changed", resource["type"])
+			assert.NotEmpty(t, resource["diff"])
+		}
+	})
+
+	t.Run("rejects summary", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--output-format", "jsonl", "--summary")
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--output-format jsonl cannot be combined with --summary"})
+	})
+}
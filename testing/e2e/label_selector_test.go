@@ -158,11 +158,6 @@ func TestLabelSelectorValidation(t *testing.T) {
 			labelArgs:   []string{"--label=app=nginx", "--label=tier=frontend"},
 			expectError: false,
 		},
-		{
-			name:        "label without equals sign is ignored",
-			labelArgs:   []string{"--label=invalidlabel"},
-			expectError: false, // Should not error, just ignore invalid format
-		},
 		{
 			name:        "empty label value",
 			labelArgs:   []string{"--label=app="},
@@ -186,6 +181,19 @@ func TestLabelSelectorValidation(t *testing.T) {
 	}
 }
 
+func TestLabelSelectorWithoutValue(t *testing.T) {
+	t.Run("label without equals sign requires the label", func(t *testing.T) {
+		result := runDiffCommand("diff", "fixtures/basic/test-base.yaml", "fixtures/basic/test-head.yaml", "--label=invalidlabel")
+		assertNoDiff(t, result)
+	})
+
+	t.Run("label without equals sign selects any value", func(t *testing.T) {
+		result := runDiffCommand("diff", "fixtures/basic/test-base.yaml", "fixtures/basic/test-head.yaml", "--label=tier")
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"frontend-app", "backend-app", "app-config"})
+	})
+}
+
 // Test with different file structures
 func TestLabelSelectorWithVariousYAMLStructures(t *testing.T) {
 	// Create a temporary YAML file with no labels
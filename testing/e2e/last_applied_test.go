@@ -0,0 +1,51 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastAppliedE2E(t *testing.T) {
+	t.Run("full live object includes server-populated fields", func(t *testing.T) {
+		result := runDiffCommand("diff",
+			getFixturePath("live", "live-export.yaml"), getFixturePath("live", "desired.yaml"))
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"progressDeadlineSeconds", "readyReplicas"})
+	})
+
+	t.Run("last-applied configuration only shows applied changes", func(t *testing.T) {
+		result := runDiffCommand("diff", "--last-applied",
+			getFixturePath("live", "live-export.yaml"), getFixturePath("live", "desired.yaml"))
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"===== apps/Deployment default/web ======", "image: nginx:1.26", "image: nginx:1.25"})
+		assert.NotContains(t, result.Output, "progressDeadlineSeconds")
+		assert.NotContains(t, result.Output, "readyReplicas")
+		assert.NotContains(t, result.Output, "last-applied-configuration")
+	})
+}
+
+func TestFieldManagerE2E(t *testing.T) {
+	t.Run("full live object includes fields of other managers", func(t *testing.T) {
+		result := runDiffCommand("diff",
+			getFixturePath("live", "managed-export.yaml"), getFixturePath("live", "desired.yaml"))
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"replicas: 7", "managedFields"})
+	})
+
+	t.Run("only fields owned by the manager are compared", func(t *testing.T) {
+		result := runDiffCommand("diff", "--field-manager", "argocd",
+			getFixturePath("live", "managed-export.yaml"), getFixturePath("live", "desired.yaml"))
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"===== apps/Deployment default/web ======", "image: nginx:1.26", "image: nginx:1.25"})
+		assert.NotContains(t, result.Output, "replicas")
+		assert.NotContains(t, result.Output, "managedFields")
+		assert.NotContains(t, result.Output, "imagePullPolicy")
+	})
+
+	t.Run("cannot be combined with last-applied", func(t *testing.T) {
+		result := runDiffCommand("diff", "--field-manager", "argocd", "--last-applied",
+			getFixturePath("live", "managed-export.yaml"), getFixturePath("live", "desired.yaml"))
+		assertError(t, result)
+	})
+}
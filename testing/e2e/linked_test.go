@@ -0,0 +1,54 @@
+package e2e
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupLinked(t *testing.T) {
+	baseFile := getFixturePath("basic", "checksum-base.yaml")
+	headFile := getFixturePath("basic", "checksum-head.yaml")
+
+	t.Run("markdown grouped by change set", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--group-linked", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{
+			"## Change Set `Deployment/default/web`",
+			"### Changed Resources (1)\n- `ConfigMap/default/web-config`",
+			"## Change Set `Deployment/default/web` Changes",
+		})
+		assertNotInOutput(t, result, []string{"## Other Resources"})
+	})
+
+	t.Run("show saved results", func(t *testing.T) {
+		saved := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", "--save", saved, baseFile, headFile)
+		require.Equal(t, 1, result.ExitCode, result.Output)
+
+		result = runDiffCommand("show", "--output-format", "markdown", "--summary", "--group-linked", saved)
+		assert.Equal(t, 1, result.ExitCode)
+		assertDiffOutput(t, result, []string{"## Change Set `Deployment/default/web`"})
+	})
+
+	t.Run("requires markdown", func(t *testing.T) {
+		result := runDiffCommand("diff", "--group-linked", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--group-linked requires --output-format markdown"})
+
+		saved := filepath.Join(t.TempDir(), "results.json")
+		result = runDiffCommand("diff", "--save", saved, baseFile, headFile)
+		require.Equal(t, 1, result.ExitCode, result.Output)
+		result = runDiffCommand("show", "--summary", "--group-linked", saved)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--group-linked requires --output-format markdown"})
+	})
+
+	t.Run("cannot be combined with split scope", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--group-linked", "--split-scope", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--group-linked cannot be combined with --owners-config or --split-scope"})
+	})
+}
@@ -56,8 +56,29 @@ type CommandResult struct {
 
 // runDiffCommand executes the k8s-manifest-diff command with given args
 func runDiffCommand(args ...string) CommandResult {
-	cmd := exec.Command(binaryPath, args...)
-	cmd.Dir = "."
+	return runDiffCommandInDir(".", args...)
+}
+
+// runDiffCommandInDir executes the k8s-manifest-diff command with given args in the given directory
+func runDiffCommandInDir(dir string, args ...string) CommandResult {
+	return runBinaryInDir(binaryPath, dir, args...)
+}
+
+// runDiffCommandWithEnv executes the k8s-manifest-diff command with additional environment variables
+func runDiffCommandWithEnv(env []string, args ...string) CommandResult {
+	return runBinary(binaryPath, ".", env, args...)
+}
+
+// runBinaryInDir executes the given binary with args in the given directory
+func runBinaryInDir(binary, dir string, args ...string) CommandResult {
+	return runBinary(binary, dir, nil, args...)
+}
+
+// runBinary executes the given binary with args in the given directory, adding env to the environment
+func runBinary(binary, dir string, env []string, args ...string) CommandResult {
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
 
 	output, err := cmd.CombinedOutput()
 	exitCode := 0
@@ -0,0 +1,105 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixE2E(t *testing.T) {
+	prod := getFixturePath("matrix", "prod.yaml")
+	dev := getFixturePath("matrix", "dev.yaml")
+	stg := getFixturePath("matrix", "stg.yaml")
+
+	t.Run("differences against first environment", func(t *testing.T) {
+		result := runDiffCommand("matrix", prod, dev, stg)
+
+		assert.Equal(t, 1, result.ExitCode, "Expected exit code 1 when environments differ")
+		assertDiffOutput(t, result, []string{
+			"# Matrix: reference prod, 2 environments, 3 resources, 2 differing",
+			"Deployment/default/web",
+			"ConfigMap/default/debug-config",
+			"changed",
+			"created",
+		})
+	})
+
+	t.Run("explicit environment names and reference", func(t *testing.T) {
+		result := runDiffCommand("matrix", "--reference", "staging", "production="+prod, "staging="+stg)
+
+		assert.Equal(t, 0, result.ExitCode, "Expected exit code 0 when environments are identical")
+		assertDiffOutput(t, result, []string{"reference staging", "production"})
+	})
+
+	t.Run("markdown output", func(t *testing.T) {
+		result := runDiffCommand("matrix", "--output-format", "markdown", prod, dev)
+
+		assert.Equal(t, 1, result.ExitCode)
+		assertDiffOutput(t, result, []string{
+			"# Kubernetes Manifest Matrix",
+			"| Resource | dev |",
+			"| `Deployment/default/web` | changed |",
+		})
+	})
+
+	t.Run("n-way comparison", func(t *testing.T) {
+		result := runDiffCommand("matrix", "--n-way", prod, dev, stg)
+
+		assert.Equal(t, 1, result.ExitCode)
+		assertDiffOutput(t, result, []string{
+			"# N-way: 3 states, 3 resources, 2 differing",
+			"RESOURCE                        prod  dev  stg",
+			"ConfigMap/default/debug-config  -     A    -",
+			"Deployment/default/web          A     B    A",
+		})
+	})
+
+	t.Run("n-way rejects reference", func(t *testing.T) {
+		result := runDiffCommand("matrix", "--n-way", "--reference", "prod", prod, dev)
+		assertError(t, result)
+		assert.Contains(t, result.Output, "cannot be combined with --reference")
+	})
+
+	t.Run("unknown reference", func(t *testing.T) {
+		result := runDiffCommand("matrix", "--reference", "qa", prod, dev)
+		assertError(t, result)
+		assert.Contains(t, result.Output, "reference environment not found: qa")
+	})
+
+	t.Run("requires at least two environments", func(t *testing.T) {
+		result := runDiffCommand("matrix", prod)
+		assertError(t, result)
+	})
+}
+
+func TestMatrixDiffFlagsE2E(t *testing.T) {
+	prod := getFixturePath("matrix", "prod.yaml")
+	scratch := filepath.Join(t.TempDir(), "scratch.yaml")
+	require.NoError(t, os.WriteFile(scratch, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: scratch
+  namespace: default
+  annotations:
+    k8s-manifest-diff/ignore: "true"
+`), 0o600))
+
+	t.Run("ignore annotation", func(t *testing.T) {
+		result := runDiffCommand("matrix", "prod="+prod, "scratch="+scratch)
+		assertNotInOutput(t, result, []string{"ConfigMap/default/scratch"})
+	})
+
+	t.Run("disable ignore annotation", func(t *testing.T) {
+		result := runDiffCommand("matrix", "--disable-ignore-annotation", "prod="+prod, "scratch="+scratch)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"ConfigMap/default/scratch"})
+	})
+
+	t.Run("masking flags are validated", func(t *testing.T) {
+		result := runDiffCommand("matrix", "--mask-scope", "cluster", prod, prod)
+		assertError(t, result)
+	})
+}
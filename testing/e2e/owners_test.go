@@ -0,0 +1,54 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnersConfigE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+	dir := t.TempDir()
+
+	configFile := filepath.Join(dir, "owners.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`rules:
+- namespace: default
+  owners: ["@acme/platform"]
+- labels:
+    app: api
+  owners: ["@acme/api"]
+`), 0o600))
+
+	t.Run("markdown grouped by owner", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--owners-config", configFile, baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{
+			"## @acme/api\n",
+			"- `Deployment/default/backend-app`",
+			"## @acme/platform\n",
+			"## Changes for @acme/api",
+		})
+		assert.Less(t, strings.Index(result.Output, "## @acme/api"), strings.Index(result.Output, "## @acme/platform"))
+	})
+
+	t.Run("owners are saved", func(t *testing.T) {
+		savedFile := filepath.Join(dir, "results.json")
+		result := runDiffCommand("diff", "--summary", "--owners-config", configFile, "--save", savedFile, baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+
+		result = runDiffCommand("show", "--summary", "--output-format", "markdown", "--group-by-owner", savedFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"## @acme/api\n"})
+	})
+
+	t.Run("cannot combine with split scope", func(t *testing.T) {
+		result := runDiffCommand("diff", "--owners-config", configFile, "--split-scope", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--owners-config cannot be combined with --split-scope"})
+	})
+}
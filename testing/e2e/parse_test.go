@@ -0,0 +1,29 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePreserveComments(t *testing.T) {
+	file := getFixturePath("basic", "commented-manifests.yaml")
+
+	t.Run("comments are kept and secrets masked", func(t *testing.T) {
+		result := runDiffCommand("parse", "--preserve-comments", file)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{
+			"# Frontend configuration, owned by team-a\napiVersion: v1",
+			"  # keep in sync with the backend\n  timeout: \"30\"",
+			"# Database credentials\napiVersion: v1",
+			"# rotated monthly",
+		})
+		assertNotInOutput(t, result, []string{"cGFzc3dvcmQ="})
+	})
+
+	t.Run("comments are dropped by default", func(t *testing.T) {
+		result := runDiffCommand("parse", file)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertNotInOutput(t, result, []string{"owned by team-a", "rotated monthly", "cGFzc3dvcmQ="})
+	})
+}
@@ -0,0 +1,32 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanOutputE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "plan-base.yaml")
+	headFile := getFixturePath("basic", "plan-head.yaml")
+
+	t.Run("plan with diff", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "plan", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{
+			"  ~ update /ConfigMap default/web-config",
+			"      -    level: debug",
+			"-/+ replace apps/Deployment default/web (forces replacement: spec.selector)",
+			"  + create /Service default/web",
+			"  - destroy /ConfigMap default/legacy-config",
+			"Plan: 1 to create, 1 to update, 1 to replace, 1 to destroy.",
+		})
+	})
+
+	t.Run("plan summary", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "plan", "--summary", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Plan: 1 to create, 1 to update, 1 to replace, 1 to destroy."})
+		assert.NotContains(t, result.Output, "level: debug")
+	})
+}
@@ -0,0 +1,56 @@
+package e2e
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubectlPluginE2E(t *testing.T) {
+	pluginPath, err := filepath.Abs(filepath.Join(t.TempDir(), "kubectl-diff_manifest"))
+	require.NoError(t, err)
+	build := exec.Command("go", "build", "-o", pluginPath, "../../cmd/kubectl-diff_manifest")
+	require.NoError(t, build.Run())
+
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	runPlugin := func(args ...string) CommandResult {
+		return runBinaryInDir(pluginPath, ".", args...)
+	}
+
+	t.Run("files without subcommand run diff", func(t *testing.T) {
+		result := runPlugin(baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"====="})
+	})
+
+	t.Run("diff flags without subcommand", func(t *testing.T) {
+		result := runPlugin("--summary", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"# Summary:"})
+	})
+
+	t.Run("explicit subcommand", func(t *testing.T) {
+		result := runPlugin("diff", "--summary", baseFile, headFile)
+		assertHasDiff(t, result)
+	})
+
+	t.Run("kubeconfig flag belongs to cluster-facing commands", func(t *testing.T) {
+		result := runPlugin("drift", "--help")
+		assert.Equal(t, 0, result.ExitCode)
+		assertDiffOutput(t, result, []string{"--kubeconfig"})
+
+		result = runPlugin("--kubeconfig", "/nonexistent/config", baseFile, headFile)
+		assertError(t, result)
+	})
+
+	t.Run("help uses plugin name", func(t *testing.T) {
+		result := runPlugin("--help")
+		assert.Equal(t, 0, result.ExitCode)
+		assertDiffOutput(t, result, []string{"kubectl diff-manifest"})
+	})
+}
@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileE2E(t *testing.T) {
+	dir := t.TempDir()
+	baseFile := filepath.Join(dir, "base.yaml")
+	headFile := filepath.Join(dir, "head.yaml")
+	require.NoError(t, os.WriteFile(baseFile, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: scripts
+  namespace: default
+data:
+  run.sh: "echo start\r\n"
+`), 0o600))
+	require.NoError(t, os.WriteFile(headFile, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: scripts
+  namespace: default
+data:
+  run.sh: "echo start\n"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: scratch
+  namespace: default
+  annotations:
+    k8s-manifest-diff/ignore: "true"
+`), 0o600))
+
+	t.Run("default options", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertNotInOutput(t, result, []string{"ConfigMap/default/scratch"})
+	})
+
+	t.Run("gitops ignores line endings", func(t *testing.T) {
+		assertNoDiff(t, runDiffCommand("diff", "--profile", "gitops", baseFile, headFile))
+	})
+
+	t.Run("set flags override the profile", func(t *testing.T) {
+		assertHasDiff(t, runDiffCommand("diff", "--profile", "gitops", "--ignore-eol=false", baseFile, headFile))
+	})
+
+	t.Run("strict includes ignored resources", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--profile", "strict", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"Create (1):\n  ConfigMap/default/scratch"})
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		result := runDiffCommand("diff", "--profile", "lenient", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{`unknown profile "lenient" (audit|gitops|strict)`})
+	})
+}
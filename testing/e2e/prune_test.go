@@ -0,0 +1,46 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneTrackingE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "prune-base.yaml")
+	headFile := getFixturePath("basic", "prune-head.yaml")
+
+	t.Run("summary", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--prune-tracking", "app.kubernetes.io/instance=web", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{
+			"Delete (1):\n  ConfigMap/default/manual-config",
+			"Prune (1):\n  ConfigMap/default/legacy-config",
+		})
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "plan", "--prune-tracking", "app.kubernetes.io/instance=web", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"  - destroy /ConfigMap default/legacy-config (would be pruned)"})
+		assert.NotContains(t, result.Output, "manual-config (would be pruned)")
+	})
+
+	t.Run("repeated key keeps every value", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--prune-tracking", "app.kubernetes.io/instance=web", "--prune-tracking", "app.kubernetes.io/instance=api", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"Prune (1):\n  ConfigMap/default/legacy-config"})
+	})
+
+	t.Run("negated selectors are rejected", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--prune-tracking", "app.kubernetes.io/instance!=web", baseFile, headFile)
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"negated selectors (key!=value) are not supported"})
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertNotInOutput(t, result, []string{"Prune ("})
+	})
+}
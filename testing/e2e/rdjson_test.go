@@ -0,0 +1,48 @@
+package e2e
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRDJSONOutputE2E(t *testing.T) {
+	baseFile := getFixturePath("kinds", "mixed-base.yaml")
+	headFile := getFixturePath("kinds", "mixed-head.yaml")
+
+	t.Run("reports changed lines", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--output-format", "rdjson", "--filter-kind", "Service")
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+
+		var out struct {
+			Source struct {
+				Name string `json:"name"`
+			} `json:"source"`
+			Diagnostics []struct {
+				Message  string `json:"message"`
+				Location struct {
+					Path  string `json:"path"`
+					Range struct {
+						Start struct {
+							Line int `json:"line"`
+						} `json:"start"`
+					} `json:"range"`
+				} `json:"location"`
+			} `json:"diagnostics"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(result.Output), &out), result.Output)
+		assert.Equal(t, "k8s-manifest-diff", out.Source.Name)
+		require.Len(t, out.Diagnostics, 2)
+		assert.Equal(t, "Service/test-service: spec.ports[0].port changed", out.Diagnostics[0].Message)
+		assert.Equal(t, headFile, out.Diagnostics[0].Location.Path)
+		assert.Equal(t, 33, out.Diagnostics[0].Location.Range.Start.Line)
+	})
+
+	t.Run("rejects print options", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--output-format", "rdjson", "--print-options")
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--output-format rdjson cannot be combined with --summary or --print-options"})
+	})
+}
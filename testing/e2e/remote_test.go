@@ -0,0 +1,189 @@
+package e2e
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteURLInputs(t *testing.T) {
+	head, err := os.ReadFile(getFixturePath("basic", "test-head.yaml"))
+	require.NoError(t, err)
+	headArchive := tarGz(t, "", readFixtureTree(t, getFixturePath("dirs", "head")))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/public/head.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(head)
+	})
+	mux.HandleFunc("/bearer/head.yaml", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(head)
+	})
+	mux.HandleFunc("/basic/head.tgz", func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ci" || password != "pa55" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(headArchive)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(mux)
+	defer tlsServer.Close()
+
+	// The binary trusts the test server certificate through SSL_CERT_FILE, which Go only reads on Linux and BSDs
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, ca, 0o600))
+	trust := "SSL_CERT_FILE=" + caFile
+
+	baseFile := getFixturePath("basic", "test-base.yaml")
+
+	tests := []struct {
+		name     string
+		env      []string
+		args     []string
+		expected []string
+		wantErr  bool
+		tls      bool
+	}{
+		{
+			name:     "public url",
+			args:     []string{"diff", baseFile, server.URL + "/public/head.yaml"},
+			expected: []string{"===== "},
+		},
+		{
+			name:     "bearer token",
+			env:      []string{trust, "K8S_MANIFEST_DIFF_INPUT_TOKEN=s3cr3t"},
+			args:     []string{"diff", baseFile, tlsServer.URL + "/bearer/head.yaml"},
+			expected: []string{"===== "},
+			tls:      true,
+		},
+		{
+			name:     "token over plain http",
+			env:      []string{"K8S_MANIFEST_DIFF_INPUT_TOKEN=s3cr3t"},
+			args:     []string{"diff", baseFile, server.URL + "/bearer/head.yaml?signature=abc"},
+			expected: []string{"refusing to send credentials to " + server.URL + "/bearer/head.yaml over plain http"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing token",
+			args:     []string{"diff", baseFile, server.URL + "/bearer/head.yaml?signature=abc"},
+			expected: []string{"failed to fetch " + server.URL + "/bearer/head.yaml: 401 Unauthorized"},
+			wantErr:  true,
+		},
+		{
+			name:     "basic auth archive",
+			env:      []string{trust, "K8S_MANIFEST_DIFF_INPUT_USERNAME=ci", "K8S_MANIFEST_DIFF_INPUT_PASSWORD=pa55"},
+			args:     []string{"diff", "--summary", getFixturePath("dirs", "base"), tlsServer.URL + "/basic/head.tgz"},
+			expected: []string{"Deployment/default/web", "ConfigMap/default/example"},
+			tls:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.tls && runtime.GOOS != "linux" {
+				t.Skip("SSL_CERT_FILE is not honored on this platform")
+			}
+			result := runDiffCommandWithEnv(tt.env, tt.args...)
+			if tt.wantErr {
+				assertError(t, result)
+				assertNotInOutput(t, result, []string{"signature=abc"})
+			} else {
+				assertHasDiff(t, result)
+			}
+			assertDiffOutput(t, result, tt.expected)
+		})
+	}
+}
+
+func TestObjectStorageInputs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake CLIs are shell scripts")
+	}
+	headFile := getFixturePath("basic", "test-head.yaml")
+	baseFile := getFixturePath("basic", "test-base.yaml")
+
+	// Fake aws and gcloud CLIs print the head fixture for known objects and fail otherwise
+	bin := t.TempDir()
+	writeScript := func(name, known string) {
+		script := "#!/bin/sh\n" +
+			"for arg in \"$@\"; do\n" +
+			"  if [ \"$arg\" = \"" + known + "\" ]; then cat \"" + headFile + "\"; exit 0; fi\n" +
+			"done\n" +
+			"echo \"object not found\" >&2\n" +
+			"exit 1\n"
+		require.NoError(t, os.WriteFile(filepath.Join(bin, name), []byte(script), 0o755)) // #nosec G306 - test script must be executable
+	}
+	writeScript("aws", "s3://releases/v1/head.yaml")
+	writeScript("gcloud", "gs://releases/v1/head.yaml")
+	path := []string{"PATH=" + bin + string(os.PathListSeparator) + os.Getenv("PATH")}
+
+	// A hanging aws CLI must not block the run
+	hanging := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(hanging, "aws"), []byte("#!/bin/sh\nexec sleep 30\n"), 0o755)) // #nosec G306 - test script must be executable
+
+	tests := []struct {
+		name     string
+		env      []string
+		args     []string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "s3 object",
+			env:      path,
+			args:     []string{"diff", baseFile, "s3://releases/v1/head.yaml"},
+			expected: []string{"===== "},
+		},
+		{
+			name:     "gs object",
+			env:      path,
+			args:     []string{"diff", baseFile, "gs://releases/v1/head.yaml"},
+			expected: []string{"===== "},
+		},
+		{
+			name:     "missing object",
+			env:      path,
+			args:     []string{"diff", baseFile, "s3://releases/v2/head.yaml"},
+			expected: []string{"failed to fetch s3://releases/v2/head.yaml", "object not found"},
+			wantErr:  true,
+		},
+		{
+			name:     "hanging cli",
+			env:      []string{"PATH=" + hanging + string(os.PathListSeparator) + os.Getenv("PATH")},
+			args:     []string{"diff", "--parse-timeout", "1s", baseFile, "s3://releases/v1/head.yaml"},
+			expected: []string{"failed to fetch s3://releases/v1/head.yaml: timed out after 1s"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing cli",
+			env:      []string{"PATH=" + t.TempDir()},
+			args:     []string{"diff", baseFile, "gs://releases/v1/head.yaml"},
+			expected: []string{"gcloud CLI not found in PATH"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runDiffCommandWithEnv(tt.env, tt.args...)
+			if tt.wantErr {
+				assertError(t, result)
+			} else {
+				assertHasDiff(t, result)
+			}
+			assertDiffOutput(t, result, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package e2e
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxResourcesInReport(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	t.Run("limits resource sections", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--max-resources-in-report", "1", "--report-url", "https://example.com/report.md", baseFile, headFile)
+		assertHasDiff(t, result)
+		assert.Equal(t, 1, strings.Count(result.Output, "```diff"), result.Output)
+		assertDiffOutput(t, result, []string{
+			"- `Deployment/default/backend-app`",
+			"- `Deployment/default/frontend-app`",
+			"_... and 2 more resources; see the [full report](<https://example.com/report.md>)_",
+		})
+	})
+
+	t.Run("report within the limit", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--max-resources-in-report", "3", baseFile, headFile)
+		assertHasDiff(t, result)
+		assert.Equal(t, 3, strings.Count(result.Output, "```diff"))
+		assertNotInOutput(t, result, []string{"more resource"})
+	})
+
+	t.Run("requires markdown output", func(t *testing.T) {
+		result := runDiffCommand("diff", "--max-resources-in-report", "1", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--max-resources-in-report requires --output-format markdown"})
+	})
+
+	t.Run("report url requires the limit", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "markdown", "--report-url", "https://example.com/report.md", baseFile, headFile)
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"--report-url requires --max-resources-in-report"})
+	})
+
+	t.Run("show saved results", func(t *testing.T) {
+		saved := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", "--save", saved, baseFile, headFile)
+		require.Equal(t, 1, result.ExitCode, result.Output)
+
+		result = runDiffCommand("show", "--output-format", "markdown", "--max-resources-in-report", "2", saved)
+		assert.Equal(t, 1, result.ExitCode)
+		assert.Equal(t, 2, strings.Count(result.Output, "```diff"))
+		assertDiffOutput(t, result, []string{"_... and 1 more resource not shown_"})
+	})
+}
@@ -0,0 +1,39 @@
+package e2e
+
+import (
+	"testing"
+)
+
+func TestChecksumRestartOnly(t *testing.T) {
+	baseFile := getFixturePath("basic", "checksum-base.yaml")
+	headFile := getFixturePath("basic", "checksum-head.yaml")
+
+	t.Run("summary", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{
+			"Changed (1):\n  ConfigMap/default/web-config",
+			"Restart-only (config checksum) (1):\n  Deployment/default/web",
+		})
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		result := runDiffCommand("diff", "--output-format", "plan", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"update apps/Deployment default/web (restart-only (config checksum))"})
+	})
+
+	t.Run("config excluded", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--exclude-kinds", "ConfigMap", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"Changed (1):\n  Deployment/default/web"})
+		assertNotInOutput(t, result, []string{"Restart-only"})
+	})
+
+	t.Run("config filtered out", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--filter-kind", "Deployment", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"Changed (1):\n  Deployment/default/web"})
+		assertNotInOutput(t, result, []string{"Restart-only"})
+	})
+}
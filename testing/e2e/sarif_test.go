@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sarifOutput is the part of a SARIF log checked by the tests
+type sarifOutput struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Results []struct {
+			RuleID  string `json:"ruleId"`
+			Message struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine int `json:"startLine"`
+						EndLine   int `json:"endLine"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+func TestSARIFOutputE2E(t *testing.T) {
+	baseFile := getFixturePath("kinds", "mixed-base.yaml")
+	headFile := getFixturePath("kinds", "mixed-head.yaml")
+
+	t.Run("annotates changed lines", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--output-format", "sarif")
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+
+		var log sarifOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Output), &log), result.Output)
+		assert.Equal(t, "2.1.0", log.Version)
+		require.Len(t, log.Runs, 1)
+		require.NotEmpty(t, log.Runs[0].Results)
+
+		first := log.Runs[0].Results[0]
+		assert.Equal(t, "resource-changed", first.RuleID)
+		assert.Equal(t, "Deployment/test-app: spec.replicas changed", first.Message.Text)
+		require.Len(t, first.Locations, 1)
+		assert.Equal(t, headFile, first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		assert.Equal(t, 8, first.Locations[0].PhysicalLocation.Region.StartLine)
+		assert.Equal(t, 8, first.Locations[0].PhysicalLocation.Region.EndLine)
+	})
+
+	t.Run("prints an empty log without changes", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, baseFile, "--output-format", "sarif")
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+
+		var log sarifOutput
+		require.NoError(t, json.Unmarshal([]byte(result.Output), &log), result.Output)
+		require.Len(t, log.Runs, 1)
+		assert.Empty(t, log.Runs[0].Results)
+	})
+
+	t.Run("rejects summary", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--output-format", "sarif", "--summary")
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--output-format sarif cannot be combined with --summary"})
+	})
+}
+
+func TestJSONLinesAnnotationsE2E(t *testing.T) {
+	baseFile := getFixturePath("kinds", "mixed-base.yaml")
+	headFile := getFixturePath("kinds", "mixed-head.yaml")
+
+	result := runDiffCommand("diff", baseFile, headFile, "--output-format", "jsonl", "--filter-kind", "Service")
+	assert.Equal(t, 1, result.ExitCode, result.Output)
+	assertDiffOutput(t, result, []string{
+		`"annotations":[{"path":"spec.ports[0].port","file":"` + headFile + `","startLine":33,"endLine":33}`,
+	})
+}
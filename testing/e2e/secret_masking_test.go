@@ -1,7 +1,11 @@
 package e2e
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSecretMasking(t *testing.T) {
@@ -34,6 +38,38 @@ func TestSecretMasking(t *testing.T) {
 	})
 }
 
+func TestSecretMaskingCustomToken(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-with-data-base.yaml")
+	headFile := getFixturePath("basic", "secret-with-data-head.yaml")
+
+	result := runDiffCommand("diff", "--mask-token", "x", "--mask-min-length", "8", baseFile, headFile)
+	assertHasDiff(t, result)
+	assertDiffOutput(t, result, []string{"xxxxxxxx"})
+	assertNotInOutput(t, result, []string{"++++", "bXlwYXNzd29yZA=="})
+
+	result = runDiffCommand("diff", "--mask-min-length", "-1", baseFile, headFile)
+	assertError(t, result)
+	assertDiffOutput(t, result, []string{"must not be negative"})
+}
+
+func TestSecretMaskingHashStrategy(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-with-data-base.yaml")
+	headFile := getFixturePath("basic", "secret-with-data-head.yaml")
+
+	t.Run("keyed masks", func(t *testing.T) {
+		result := runDiffCommandWithEnv([]string{"K8S_MANIFEST_DIFF_MASK_KEY=test-key"}, "diff", "--mask-strategy", "hash", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"<masked:"})
+		assertNotInOutput(t, result, []string{"bXlwYXNzd29yZA=="})
+	})
+
+	t.Run("refused without a key", func(t *testing.T) {
+		result := runDiffCommandWithEnv([]string{"K8S_MANIFEST_DIFF_MASK_KEY="}, "diff", "--mask-strategy", "hash", baseFile, headFile)
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"--mask-strategy hash requires a secret key in K8S_MANIFEST_DIFF_MASK_KEY"})
+	})
+}
+
 func TestSecretMaskingWithStringData(t *testing.T) {
 	// Create test files with stringData
 	baseFile := getFixturePath("basic", "secret-with-stringdata-base.yaml")
@@ -218,3 +254,155 @@ func TestMaskingSecretEnabledByDefault(t *testing.T) {
 		"++++++++++++++++",
 	})
 }
+
+func TestPotentialSecretsGuard(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-leak-base.yaml")
+	headFile := getFixturePath("basic", "secret-leak-head.yaml")
+
+	t.Run("masked output is printed", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile)
+
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"===== /Secret default/leaky-secret ======"})
+	})
+
+	t.Run("unmasked output is refused", func(t *testing.T) {
+		result := runDiffCommand("diff", "--disable-masking-secret", baseFile, headFile)
+
+		assert.Equal(t, 2, result.ExitCode, "Expected exit code 2 when potential secrets are detected")
+		assertDiffOutput(t, result, []string{
+			"potential secrets detected",
+			"Secret/default/leaky-secret: high-entropy-base64",
+		})
+		assertNotInOutput(t, result, []string{"c2VjcmV0LXRva2VuLXZhbHVlLWZvci10ZXN0aW5nLW9ubHk="})
+	})
+
+	t.Run("unmasked output is printed when allowed", func(t *testing.T) {
+		result := runDiffCommand("diff", "--disable-masking-secret", "--allow-potential-secrets", baseFile, headFile)
+
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"c2VjcmV0LXRva2VuLXZhbHVlLWZvci10ZXN0aW5nLW9ubHk="})
+	})
+
+	t.Run("summary is not affected", func(t *testing.T) {
+		result := runDiffCommand("diff", "--disable-masking-secret", "--summary", baseFile, headFile)
+
+		assertHasDiff(t, result)
+	})
+
+	t.Run("saved results are refused", func(t *testing.T) {
+		saveFile := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", "--disable-masking-secret", "--summary", "--save", saveFile, baseFile, headFile)
+
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"potential secrets detected"})
+		assert.NoFileExists(t, saveFile)
+	})
+}
+
+func TestMaskingAuditFile(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-with-data-base.yaml")
+	headFile := getFixturePath("basic", "secret-with-data-head.yaml")
+	auditFile := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	result := runDiffCommand("diff", "--masking-audit", auditFile, baseFile, headFile)
+	assertHasDiff(t, result)
+
+	data, err := os.ReadFile(auditFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"name":"test-secret","side":"head","field":"data","key":"new-secret"`)
+	assert.Contains(t, string(data), `"valueHash":"sha256:`)
+	assert.NotContains(t, string(data), "bmV3c2VjcmV0")
+}
+
+func TestSecretMaskingDisabledForResource(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-with-data-base.yaml")
+	headFile := getFixturePath("basic", "secret-with-data-head.yaml")
+
+	t.Run("matching resource is unmasked", func(t *testing.T) {
+		result := runDiffCommand("diff", "--disable-masking-for", "Secret/default/test-*", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"bmV3cGFzc3dvcmQ="})
+	})
+
+	t.Run("other resources stay masked", func(t *testing.T) {
+		result := runDiffCommand("diff", "--disable-masking-for", "Secret/default/public-cert", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertNotInOutput(t, result, []string{"bmV3cGFzc3dvcmQ="})
+	})
+}
+
+func TestSecretPolicyCertificateMetadata(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-tls-base.yaml")
+	headFile := getFixturePath("basic", "secret-tls-head.yaml")
+
+	t.Run("certificate metadata is shown", func(t *testing.T) {
+		result := runDiffCommand("diff", "--secret-policy", "kubernetes.io/tls=certificate-metadata", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{
+			`tls.crt: <certificate subject="CN=app.example.com" issuer="CN=app.example.com"`,
+			"notAfter=2038-10-13T02:47:07Z",
+			"notAfter=2037-10-13T02:47:07Z",
+			"tls.key: ++++++++++++++++",
+		})
+		assertNotInOutput(t, result, []string{"LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t", "cGxhY2Vob2xkZXIta2V5"})
+	})
+
+	t.Run("certificates are masked by default", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertNotInOutput(t, result, []string{"<certificate", "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t"})
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		result := runDiffCommand("diff", "--secret-policy", "kubernetes.io/tls=show", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"invalid secret policy: show"})
+	})
+}
+
+func TestCertificateChangesE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-tls-base.yaml")
+	headFile := getFixturePath("basic", "secret-tls-head.yaml")
+
+	result := runDiffCommand("diff", baseFile, headFile)
+	assertHasDiff(t, result)
+	assertDiffOutput(t, result, []string{
+		"# Certificate changes:\n#   data.tls.crt: notAfter 2037-10-13T02:47:07Z -> 2038-10-13T02:47:07Z\n",
+	})
+	assertNotInOutput(t, result, []string{"LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t", "cGxhY2Vob2xkZXIta2V5"})
+}
+
+func TestRegistryChangesE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-dockerconfig-base.yaml")
+	headFile := getFixturePath("basic", "secret-dockerconfig-head.yaml")
+
+	result := runDiffCommand("diff", baseFile, headFile)
+	assertHasDiff(t, result)
+	assertDiffOutput(t, result, []string{
+		"# Registry changes:\n#   registry docker.io credentials changed\n#   registry ghcr.io added\n#   registry quay.io removed\n",
+	})
+	assertNotInOutput(t, result, []string{"dXNlcjpuZXc=", "eyJhdXRocyI6"})
+}
+
+func TestContinueOnErrorE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "secret-with-data-base.yaml")
+	headFile := getFixturePath("basic", "secret-invalid-list.yaml")
+
+	t.Run("aborts by default", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile)
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+		assertNotInOutput(t, result, []string{"# Errors"})
+	})
+
+	t.Run("reports failed resources", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--continue-on-error")
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{
+			"1 failed",
+			"# Errors (1):",
+			"#   Secret/default/test-secret: ",
+			"failed to diff 1 resource(s): /Secret/default/test-secret",
+		})
+	})
+}
@@ -0,0 +1,42 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeverityE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+
+	configFile := filepath.Join(t.TempDir(), "severity.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("default: low\nrules:\n- kind: Deployment\n  severity: high\n"), 0o600))
+
+	t.Run("severity shown in summary", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--severity-config", configFile, baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Deployment/default/backend-app [high]", "ConfigMap/default/app-config [low]"})
+	})
+
+	t.Run("changes below threshold do not fail", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--severity-config", configFile, "--fail-on-severity", "high",
+			"--exclude-kinds", "Deployment", baseFile, headFile)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"ConfigMap/default/app-config [low]"})
+	})
+
+	t.Run("changes at threshold fail", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--severity-config", configFile, "--fail-on-severity", "high", baseFile, headFile)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		result := runDiffCommand("diff", "--fail-on-severity", "critical", baseFile, headFile)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"invalid --fail-on-severity"})
+	})
+}
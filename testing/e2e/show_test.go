@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowCommandE2E(t *testing.T) {
+	baseFile := getFixturePath("basic", "test-base.yaml")
+	headFile := getFixturePath("basic", "test-head.yaml")
+	dir := t.TempDir()
+
+	changedRun := filepath.Join(dir, "changed.json")
+	result := runDiffCommand("diff", "--save", changedRun, baseFile, headFile)
+	require.Equal(t, 1, result.ExitCode, result.Output)
+
+	identicalRun := filepath.Join(dir, "identical.json")
+	result = runDiffCommand("diff", "--save", identicalRun, baseFile, baseFile)
+	require.Equal(t, 0, result.ExitCode, result.Output)
+
+	t.Run("re-render saved results", func(t *testing.T) {
+		result := runDiffCommand("show", changedRun)
+		assert.Equal(t, 1, result.ExitCode)
+		assertDiffOutput(t, result, []string{"# Summary:", "Deployment"})
+	})
+
+	t.Run("markdown output", func(t *testing.T) {
+		result := runDiffCommand("show", "--output-format", "markdown", changedRun)
+		assert.Equal(t, 1, result.ExitCode)
+		assertDiffOutput(t, result, []string{"```diff"})
+	})
+
+	t.Run("filter by kind", func(t *testing.T) {
+		result := runDiffCommand("show", "--kind", "ConfigMap", changedRun)
+		assert.Equal(t, 1, result.ExitCode)
+		assertDiffOutput(t, result, []string{"ConfigMap"})
+		assert.NotContains(t, result.Output, "Deployment")
+	})
+
+	t.Run("filter by type", func(t *testing.T) {
+		result := runDiffCommand("show", "--type", "created", changedRun)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No differences found"})
+	})
+
+	t.Run("failed resources exit like diff", func(t *testing.T) {
+		failedRun := filepath.Join(dir, "failed.json")
+		result := runDiffCommand("diff", "--continue-on-error", "--save", failedRun,
+			getFixturePath("basic", "secret-with-data-base.yaml"), getFixturePath("basic", "secret-invalid-list.yaml"))
+		require.Equal(t, 2, result.ExitCode, result.Output)
+
+		result = runDiffCommand("show", failedRun)
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"# Errors (1):", "failed to diff 1 resource(s): /Secret/default/test-secret"})
+
+		result = runDiffCommand("show", "--type", "error", failedRun)
+		assert.Equal(t, 2, result.ExitCode, result.Output)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		result := runDiffCommand("show", "--type", "renamed", changedRun)
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"unknown change type: renamed"})
+	})
+
+	t.Run("compare runs", func(t *testing.T) {
+		result := runDiffCommand("show", "--compare", identicalRun, changedRun)
+		assert.Equal(t, 1, result.ExitCode)
+		assertDiffOutput(t, result, []string{"# Run comparison:", "Added (", "Deployment/default/frontend-app"})
+
+		result = runDiffCommand("show", "--compare", changedRun, changedRun)
+		assert.Equal(t, 0, result.ExitCode)
+		assertDiffOutput(t, result, []string{"# Runs are identical"})
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		result := runDiffCommand("show", filepath.Join(dir, "missing.json"))
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"failed to open results file"})
+	})
+}
@@ -0,0 +1,56 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotE2E(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.yaml")
+	after := filepath.Join(dir, "after.yaml")
+	env := []string{"K8S_MANIFEST_DIFF_MASK_KEY=snapshot-key"}
+
+	result := runDiffCommandWithEnv(env, "snapshot", "--live-file", getFixturePath("live", "snapshot-before.yaml"), "--output", before)
+	require.Equal(t, 0, result.ExitCode, result.Output)
+	result = runDiffCommandWithEnv(env, "snapshot", "--live-command", "cat "+getFixturePath("live", "snapshot-after.yaml"), "--output", after)
+	require.Equal(t, 0, result.ExitCode, result.Output)
+
+	data, err := os.ReadFile(before)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# k8s-manifest-diff snapshot taken at ")
+	assert.Contains(t, string(data), "password: aG1hYy1zaGEyNTY6") // base64 of "hmac-sha256:"
+	assert.NotContains(t, string(data), "cGFzc3dvcmQ=")
+	assert.NotContains(t, string(data), "resourceVersion")
+	assert.NotContains(t, string(data), "readyReplicas")
+
+	t.Run("snapshots are compared with diff", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--show-unchanged", before, after)
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Changed (1):\n  Secret/default/db", "Unchanged (1):\n  Deployment/default/web"})
+	})
+
+	t.Run("status can be kept", func(t *testing.T) {
+		result := runDiffCommandWithEnv(env, "snapshot", "--keep-status", "--live-file", getFixturePath("live", "snapshot-before.yaml"))
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"readyReplicas: 2"})
+	})
+
+	t.Run("hashing secret values requires a key", func(t *testing.T) {
+		result := runDiffCommand("snapshot", "--live-file", getFixturePath("live", "snapshot-before.yaml"))
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"hashing secret values requires a hash key: set K8S_MANIFEST_DIFF_MASK_KEY or use --disable-masking-secret"})
+
+		result = runDiffCommand("snapshot", "--disable-masking-secret", "--live-file", getFixturePath("live", "snapshot-before.yaml"))
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+	})
+
+	t.Run("live source is required", func(t *testing.T) {
+		result := runDiffCommand("snapshot")
+		assertError(t, result)
+	})
+}
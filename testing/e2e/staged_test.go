@@ -0,0 +1,144 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stagedConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: default
+data:
+  level: %s
+`
+
+// setupGitRepo creates a git repository with a committed manifest and returns its path
+func setupGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "config", "user.email", "e2e@example.com")
+	runGit(t, dir, "config", "user.name", "e2e")
+	writeManifest(t, dir, "app.yaml", "info")
+	runGit(t, dir, "add", "app.yaml")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v failed: %s", args, output)
+}
+
+func writeManifest(t *testing.T, dir, name, level string) {
+	t.Helper()
+	content := []byte(fmt.Sprintf(stagedConfigMap, level))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0o600))
+}
+
+func TestStagedModeE2E(t *testing.T) {
+	t.Run("no staged changes", func(t *testing.T) {
+		dir := setupGitRepo(t)
+
+		result := runDiffCommandInDir(dir, "diff", "--staged")
+		assertNoDiff(t, result)
+	})
+
+	t.Run("staged change against HEAD", func(t *testing.T) {
+		dir := setupGitRepo(t)
+		writeManifest(t, dir, "app.yaml", "debug")
+		runGit(t, dir, "add", "app.yaml")
+
+		result := runDiffCommandInDir(dir, "diff", "--staged")
+		assert.Equal(t, 0, result.ExitCode, "Staged mode should not fail on differences")
+		assertDiffOutput(t, result, []string{"Changed (1):", "ConfigMap/default/app-config"})
+		assertNotInOutput(t, result, []string{"--- app-config-live.yaml"})
+	})
+
+	t.Run("newly added file is reported as created", func(t *testing.T) {
+		dir := setupGitRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new.yaml"), []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: new-service
+  namespace: default
+`), 0o600))
+		runGit(t, dir, "add", "new.yaml")
+
+		result := runDiffCommandInDir(dir, "diff", "--staged", "new.yaml")
+		assert.Equal(t, 0, result.ExitCode)
+		assertDiffOutput(t, result, []string{"Create (1):", "Service/default/new-service"})
+	})
+
+	t.Run("renamed file keeps its base version", func(t *testing.T) {
+		dir := setupGitRepo(t)
+		runGit(t, dir, "mv", "app.yaml", "config.yaml")
+		writeManifest(t, dir, "config.yaml", "debug")
+		runGit(t, dir, "add", "config.yaml")
+
+		result := runDiffCommandInDir(dir, "diff", "--staged")
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Changed (1):", "ConfigMap/default/app-config"})
+		assertNotInOutput(t, result, []string{"Create (1):"})
+	})
+
+	t.Run("unstaged change against worktree", func(t *testing.T) {
+		dir := setupGitRepo(t)
+		writeManifest(t, dir, "app.yaml", "warn")
+
+		result := runDiffCommandInDir(dir, "diff", "--staged", "--staged-against", "worktree", "app.yaml")
+		assert.Equal(t, 0, result.ExitCode)
+		assertDiffOutput(t, result, []string{"Changed (1):", "ConfigMap/default/app-config"})
+
+		result = runDiffCommandInDir(dir, "diff", "--staged", "app.yaml")
+		assertNoDiff(t, result)
+	})
+
+	t.Run("file staged before the first commit is reported as created", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not available")
+		}
+		dir := t.TempDir()
+		runGit(t, dir, "init", "-q")
+		writeManifest(t, dir, "app.yaml", "info")
+		runGit(t, dir, "add", "app.yaml")
+
+		result := runDiffCommandInDir(dir, "diff", "--staged", "app.yaml")
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"Create (1):", "ConfigMap/default/app-config"})
+	})
+
+	t.Run("git failures are reported", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not available")
+		}
+		dir := t.TempDir()
+		writeManifest(t, dir, "app.yaml", "info")
+
+		result := runDiffCommandInDir(dir, "diff", "--staged", "app.yaml")
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"not a git repository"})
+	})
+
+	t.Run("invalid comparison target", func(t *testing.T) {
+		dir := setupGitRepo(t)
+
+		result := runDiffCommandInDir(dir, "diff", "--staged", "--staged-against", "index")
+		assertError(t, result)
+	})
+}
@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -89,6 +90,60 @@ func TestSummaryFlagE2E(t *testing.T) {
 	}
 }
 
+func TestShowUnchangedE2E(t *testing.T) {
+	baseFile := getFixturePath("analyzer", "quota-base.yaml")
+	headFile := getFixturePath("analyzer", "quota-head.yaml")
+	unchanged := "# Unchanged: 1 resources\nUnchanged (1):\n  ResourceQuota/team-a/compute"
+
+	t.Run("unchanged resources are left out by default", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"# Summary: 2 total, 1 changed, 0 created, 0 deleted, 1 unchanged"})
+		assertNotInOutput(t, result, []string{"ResourceQuota/team-a/compute"})
+	})
+
+	t.Run("identical inputs list every examined resource", func(t *testing.T) {
+		examined := "Unchanged (2):\n  Deployment/team-a/web\n  ResourceQuota/team-a/compute"
+		for _, args := range [][]string{
+			{"diff", "--summary", "--show-unchanged", baseFile, baseFile},
+			{"diff", "--show-unchanged", baseFile, baseFile},
+		} {
+			result := runDiffCommand(args...)
+			assert.Equal(t, 0, result.ExitCode, result.Output)
+			assertDiffOutput(t, result, []string{"# Summary: 2 total, 0 changed, 0 created, 0 deleted, 2 unchanged", examined})
+			assertNotInOutput(t, result, []string{"No differences found"})
+		}
+
+		saved := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", "--save", saved, baseFile, baseFile)
+		assertNoDiff(t, result)
+		result = runDiffCommand("show", "--show-unchanged", saved)
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{examined})
+	})
+
+	t.Run("show-unchanged lists resources that were examined", func(t *testing.T) {
+		result := runDiffCommand("diff", "--summary", "--show-unchanged", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{unchanged})
+
+		result = runDiffCommand("diff", "--summary", "--show-unchanged", "--output-format", "markdown", baseFile, headFile)
+		assertHasDiff(t, result)
+		assertDiffOutput(t, result, []string{"`ResourceQuota/team-a/compute`"})
+	})
+
+	t.Run("show", func(t *testing.T) {
+		saved := filepath.Join(t.TempDir(), "results.json")
+		result := runDiffCommand("diff", "--save", saved, baseFile, headFile)
+		assertHasDiff(t, result)
+
+		result = runDiffCommand("show", "--summary", saved)
+		assertNotInOutput(t, result, []string{"ResourceQuota/team-a/compute"})
+		result = runDiffCommand("show", "--summary", "--show-unchanged", saved)
+		assertDiffOutput(t, result, []string{unchanged})
+	})
+}
+
 func TestSummaryFlagWithFiltersE2E(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -204,3 +259,28 @@ func TestSummaryFlagOutputFormat(t *testing.T) {
 	assert.Greater(t, fullLines, 10, "Full diff should have many lines")
 	assert.Equal(t, 7, summaryLines, "Summary should have exactly 7 lines (3 comment header lines + 1 section header + 3 changed resources)")
 }
+
+func TestFailFastE2E(t *testing.T) {
+	baseFile := getFixturePath("kinds", "mixed-base.yaml")
+	headFile := getFixturePath("kinds", "mixed-head.yaml")
+
+	t.Run("stops at the first change", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--fail-fast")
+		assert.Equal(t, 1, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"1 total, 1 changed", "Deployment/test-app"})
+		assert.NotContains(t, result.Output, "Service/test-service")
+		assert.NotContains(t, result.Output, "+++ test-app.yaml")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, baseFile, "--fail-fast")
+		assert.Equal(t, 0, result.ExitCode, result.Output)
+		assertDiffOutput(t, result, []string{"No differences found"})
+	})
+
+	t.Run("rejects save", func(t *testing.T) {
+		result := runDiffCommand("diff", baseFile, headFile, "--fail-fast", "--save", filepath.Join(t.TempDir(), "results.json"))
+		assertError(t, result)
+		assertDiffOutput(t, result, []string{"--fail-fast cannot be combined with --save, --emit-prune-script"})
+	})
+}
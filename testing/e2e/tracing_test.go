@@ -0,0 +1,39 @@
+package e2e
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTLPTracingE2E(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	var bodies strings.Builder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		bodies.Write(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Spans are batched, so they must be flushed even though the diff exits with 1
+	result := runDiffCommand("diff", "--summary", "--otlp-endpoint", server.URL, getFixturePath("basic", "test-base.yaml"), getFixturePath("basic", "test-head.yaml"))
+	assertHasDiff(t, result)
+	assertNotInOutput(t, result, []string{"changes found", "Usage:"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, paths, "/v1/traces")
+	for _, span := range []string{"k8s-manifest-diff diff", "cli.parse", "diff.filter", "diff.resource"} {
+		assert.Contains(t, bodies.String(), span)
+	}
+}